@@ -1,6 +1,7 @@
 package hyperview
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/hypergopher/hyperview/response"
@@ -27,3 +28,41 @@ type Adapter interface {
 	// RenderUnauthorized renders the unauthorized page.
 	RenderUnauthorized(w http.ResponseWriter, r *http.Request, opts *response.Response)
 }
+
+// LayoutResolver is implemented by adapters whose views can declare their own layout, so routing code doesn't need to
+// know which layout a page uses. HyperView only applies its base layout to a response without a layout when the
+// adapter does not report a declared layout for the view.
+type LayoutResolver interface {
+	// DeclaredLayout returns the layout declared by the view at the given path, if any.
+	DeclaredLayout(path string) (string, bool)
+}
+
+// Shutdowner is implemented by adapters holding resources that must be released on shutdown, such as the file watchers
+// of the template adapter. HyperView.Shutdown calls it once the renders in flight have completed.
+type Shutdowner interface {
+	// Shutdown releases the resources of the adapter, returning early with the context's error if ctx is done.
+	Shutdown(ctx context.Context) error
+}
+
+// Freezer is implemented by adapters that can be made immutable for production, trading the ability to reinitialize
+// them for lock-free lookups. HyperView.Freeze calls it on each adapter.
+type Freezer interface {
+	// Freeze makes the adapter immutable. Calling Init afterwards panics.
+	Freeze() error
+}
+
+// FreezePreparer is implemented by Freezers whose Freeze can fail, such as the template adapter compiling its pages,
+// so HyperView.Freeze can run the part of Freeze that fails for every adapter before freezing any.
+type FreezePreparer interface {
+	Freezer
+	// PrepareFreeze does the work of Freeze that can fail, without making the adapter immutable, so Freeze succeeds
+	// unless the adapter is reinitialized in between.
+	PrepareFreeze() error
+}
+
+// Validator is implemented by adapters that can check their templates ahead of time, beyond parsing, by executing
+// them with sample data. HyperView.Validate calls it on each adapter.
+type Validator interface {
+	// Validate executes the templates and returns the problems found.
+	Validate(opts ValidateOptions) error
+}
@@ -7,11 +7,26 @@ import (
 )
 
 // JSONAdapter is an adapter for rendering JSON responses.
-type JSONAdapter struct{}
+type JSONAdapter struct {
+	opts JSONAdapterOptions
+}
+
+// JSONAdapterOptions are the options for the JSONAdapter.
+type JSONAdapterOptions struct {
+	// Compact disables pretty-printing. By default, responses are indented with tabs.
+	Compact bool
+	// Bare renders the data of successful responses as is, without the Envelope. Failures are still enveloped, so
+	// clients can tell them apart.
+	Bare bool
+}
 
-// NewJSONViewAdapter creates a new JSON view adapter.
-func NewJSONViewAdapter() *JSONAdapter {
-	return &JSONAdapter{}
+// NewJSONViewAdapter creates a new JSON view adapter, with the default options unless options are given.
+func NewJSONViewAdapter(opts ...JSONAdapterOptions) *JSONAdapter {
+	adapter := &JSONAdapter{}
+	if len(opts) > 0 {
+		adapter.opts = opts[0]
+	}
+	return adapter
 }
 
 func (v *JSONAdapter) Init() error {
@@ -23,58 +38,73 @@ func (v *JSONAdapter) Render(w http.ResponseWriter, r *http.Request, resp *respo
 		resp.Status(http.StatusOK)
 	}
 
+	if err := resp.RunLoaders(r.Context(), response.DefaultLoaderConcurrency); err != nil {
+		v.RenderSystemError(w, r, err, resp)
+		return
+	}
+
 	if resp.StatusCode() > 299 {
-		err := JSONFailure(w, resp.ViewData(r).Data(), "Failure", resp.StatusCode(), resp.HTTPHeader())
+		err := v.failure(w, resp.ViewData(r).Data(), "Failure", resp.StatusCode(), resp.HTTPHeader())
 		if err != nil {
 			v.RenderSystemError(w, r, err, resp)
 		}
 		return
 	}
 
-	err := JSONSuccessWithStatus(w, resp.StatusCode(), resp.ViewData(r).Data(), resp.HTTPHeader())
+	var data any = resp.ViewData(r).Data()
+	if !v.opts.Bare {
+		data = Envelope{Status: "success", Code: resp.StatusCode(), Message: "Success", Data: data}
+	}
+
+	err := writeJSON(w, resp.StatusCode(), data, !v.opts.Compact, resp.HTTPHeader())
 	if err != nil {
 		v.RenderSystemError(w, r, err, resp)
 	}
 }
 
 func (v *JSONAdapter) RenderForbidden(w http.ResponseWriter, _ *http.Request, _ *response.Response) {
-	err := JSONFailure(w, nil, "Forbidden", http.StatusForbidden, nil)
+	err := v.failure(w, nil, "Forbidden", http.StatusForbidden, nil)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 }
 
 func (v *JSONAdapter) RenderMaintenance(w http.ResponseWriter, _ *http.Request, _ *response.Response) {
-	err := JSONFailure(w, nil, "Maintenance", http.StatusServiceUnavailable, nil)
+	err := v.failure(w, nil, "Maintenance", http.StatusServiceUnavailable, nil)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 }
 
 func (v *JSONAdapter) RenderMethodNotAllowed(w http.ResponseWriter, _ *http.Request, _ *response.Response) {
-	err := JSONFailure(w, nil, "Method not allowed", http.StatusMethodNotAllowed, nil)
+	err := v.failure(w, nil, "Method not allowed", http.StatusMethodNotAllowed, nil)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 }
 
 func (v *JSONAdapter) RenderNotFound(w http.ResponseWriter, _ *http.Request, _ *response.Response) {
-	err := JSONFailure(w, nil, "Not found", http.StatusNotFound, nil)
+	err := v.failure(w, nil, "Not found", http.StatusNotFound, nil)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 }
 
 func (v *JSONAdapter) RenderSystemError(w http.ResponseWriter, _ *http.Request, err error, _ *response.Response) {
-	e := JSONError(w, err.Error(), http.StatusInternalServerError, nil)
+	e := writeJSON(w, http.StatusInternalServerError, Envelope{Status: "error", Message: err.Error(), Code: http.StatusInternalServerError}, !v.opts.Compact)
 	if e != nil {
 		http.Error(w, e.Error(), http.StatusInternalServerError)
 	}
 }
 
 func (v *JSONAdapter) RenderUnauthorized(w http.ResponseWriter, _ *http.Request, _ *response.Response) {
-	err := JSONFailure(w, nil, "Unauthorized", http.StatusUnauthorized, nil)
+	err := v.failure(w, nil, "Unauthorized", http.StatusUnauthorized, nil)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 }
+
+// failure writes a failure envelope with the status.
+func (v *JSONAdapter) failure(w http.ResponseWriter, data any, message string, status int, header http.Header) error {
+	return writeJSON(w, status, Envelope{Status: "fail", Code: status, Message: message, Data: data}, !v.opts.Compact, header)
+}
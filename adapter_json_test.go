@@ -0,0 +1,53 @@
+package hyperview_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func TestJSONAdapter_Options(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []hyperview.JSONAdapterOptions
+		resp *response.Response
+		want string
+	}{
+		{
+			name: "default envelope, pretty-printed",
+			resp: response.NewResponse().Data(map[string]any{"name": "Ada"}),
+			want: "{\n\t\"status\": \"success\",\n\t\"message\": \"Success\",\n\t\"data\": {\n\t\t\"Error\": \"\",\n\t\t\"Errors\": {},\n\t\t\"View\": {},\n\t\t\"name\": \"Ada\"\n\t},\n\t\"code\": 200\n}\n",
+		},
+		{
+			name: "compact",
+			opts: []hyperview.JSONAdapterOptions{{Compact: true}},
+			resp: response.NewResponse().Data(map[string]any{"name": "Ada"}),
+			want: `{"status":"success","message":"Success","data":{"Error":"","Errors":{},"View":{},"name":"Ada"},"code":200}` + "\n",
+		},
+		{
+			name: "bare",
+			opts: []hyperview.JSONAdapterOptions{{Compact: true, Bare: true}},
+			resp: response.NewResponse().Data(map[string]any{"name": "Ada"}),
+			want: `{"Error":"","Errors":{},"View":{},"name":"Ada"}` + "\n",
+		},
+		{
+			name: "bare failures are enveloped",
+			opts: []hyperview.JSONAdapterOptions{{Compact: true, Bare: true}},
+			resp: response.NewResponse().StatusUnprocessable(),
+			want: `{"status":"fail","message":"Failure","data":{"Error":"","Errors":{},"View":{}},"code":422}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			hyperview.NewJSONViewAdapter(tt.opts...).Render(w, httptest.NewRequest(http.MethodGet, "/", nil), tt.resp)
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("unexpected body:\ngot  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
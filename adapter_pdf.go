@@ -0,0 +1,206 @@
+package hyperview
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/hypergopher/hyperview/response"
+)
+
+// PDFConverter converts an HTML page to a PDF document, for example with a headless browser.
+type PDFConverter interface {
+	// Convert writes the PDF document of the HTML page to w.
+	Convert(ctx context.Context, html []byte, w io.Writer) error
+}
+
+// PDFConverterFunc adapts a function to the PDFConverter interface.
+type PDFConverterFunc func(ctx context.Context, html []byte, w io.Writer) error
+
+// Convert calls f.
+func (f PDFConverterFunc) Convert(ctx context.Context, html []byte, w io.Writer) error {
+	return f(ctx, html, w)
+}
+
+// CommandPDFConverter converts pages with a command reading the HTML page on its standard input and writing the PDF
+// document on its standard output, such as wkhtmltopdf:
+//
+//	hyperview.CommandPDFConverter{Path: "wkhtmltopdf", Args: []string{"--quiet", "-", "-"}}
+type CommandPDFConverter struct {
+	// Path is the name or path of the command.
+	Path string
+	// Args are the arguments of the command.
+	Args []string
+}
+
+// Convert runs the command, which is killed if ctx is done before it completes.
+func (c CommandPDFConverter) Convert(ctx context.Context, html []byte, w io.Writer) error {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, c.Path, c.Args...)
+	cmd.Stdin = bytes.NewReader(html)
+	cmd.Stdout = w
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%s: %w: %s", c.Path, err, msg)
+		}
+		return fmt.Errorf("%s: %w", c.Path, err)
+	}
+	return nil
+}
+
+// PDFAdapter is an adapter rendering views of a TemplateAdapter as PDF documents, such as invoices and reports, so
+// they live in the same template tree as the pages. Register the adapter under the "pdf" key, so paths with a .pdf
+// extension are rendered with it, e.g. hv.Render(w, r, response.NewResponse().Path("invoices/show.pdf")).
+type PDFAdapter struct {
+	templates *TemplateAdapter
+	converter PDFConverter
+}
+
+// PDFAdapterOptions are the options for the PDFAdapter.
+type PDFAdapterOptions struct {
+	// Templates is the template adapter rendering the HTML pages, along with the system pages. Required.
+	Templates *TemplateAdapter
+	// Converter converts the HTML pages to PDF documents. Required.
+	Converter PDFConverter
+}
+
+// NewPDFViewAdapter creates a new PDFAdapter.
+func NewPDFViewAdapter(opts PDFAdapterOptions) *PDFAdapter {
+	return &PDFAdapter{
+		templates: opts.Templates,
+		converter: opts.Converter,
+	}
+}
+
+// Init checks the options of the adapter. The template adapter is initialized separately.
+func (a *PDFAdapter) Init() error {
+	if a.templates == nil {
+		return errors.New("pdf adapter: no template adapter to render the pages with")
+	}
+	if a.converter == nil {
+		return errors.New("pdf adapter: no converter")
+	}
+	return nil
+}
+
+// RenderPDF renders the view of the response and returns its PDF document, e.g. to attach it to an email or store it.
+func (a *PDFAdapter) RenderPDF(r *http.Request, resp *response.Response) ([]byte, error) {
+	page := a.renderPage(r, resp)
+	if !page.ok() {
+		return nil, fmt.Errorf("error rendering %s: %s", resp.TemplatePath(), strings.TrimSpace(page.body.String()))
+	}
+
+	var pdf bytes.Buffer
+	if err := a.converter.Convert(r.Context(), page.body.Bytes(), &pdf); err != nil {
+		return nil, fmt.Errorf("error converting %s to PDF: %w", resp.TemplatePath(), err)
+	}
+	return pdf.Bytes(), nil
+}
+
+// Render renders the view of the response as a PDF document, with the status and headers of the response. Set a
+// Content-Disposition header on the response to have browsers download the document. Views failing to render are
+// answered with the HTML error page of the template adapter.
+func (a *PDFAdapter) Render(w http.ResponseWriter, r *http.Request, resp *response.Response) {
+	page := a.renderPage(r, resp)
+	if !page.ok() {
+		page.writeTo(w)
+		return
+	}
+
+	var pdf bytes.Buffer
+	if err := a.converter.Convert(r.Context(), page.body.Bytes(), &pdf); err != nil {
+		a.templates.handleError(w, r, fmt.Errorf("error converting %s to PDF: %w", resp.TemplatePath(), err))
+		return
+	}
+
+	for key, values := range page.header {
+		if key != "Content-Type" && key != "Content-Length" && key != "Etag" {
+			w.Header()[key] = values
+		}
+	}
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Length", strconv.Itoa(pdf.Len()))
+	w.WriteHeader(page.status)
+	_, _ = w.Write(pdf.Bytes())
+}
+
+// renderPage renders the HTML page of the response. Conditional request headers are left out, as they apply to the
+// PDF document rather than the page.
+func (a *PDFAdapter) renderPage(r *http.Request, resp *response.Response) *capturedResponse {
+	r = r.Clone(r.Context())
+	r.Header.Del("If-None-Match")
+	r.Header.Del("If-Modified-Since")
+
+	page := &capturedResponse{header: make(http.Header), status: http.StatusOK}
+	a.templates.Render(page, r, resp)
+	return page
+}
+
+func (a *PDFAdapter) RenderForbidden(w http.ResponseWriter, r *http.Request, resp *response.Response) {
+	a.templates.RenderForbidden(w, r, resp)
+}
+
+func (a *PDFAdapter) RenderMaintenance(w http.ResponseWriter, r *http.Request, resp *response.Response) {
+	a.templates.RenderMaintenance(w, r, resp)
+}
+
+func (a *PDFAdapter) RenderMethodNotAllowed(w http.ResponseWriter, r *http.Request, resp *response.Response) {
+	a.templates.RenderMethodNotAllowed(w, r, resp)
+}
+
+func (a *PDFAdapter) RenderNotFound(w http.ResponseWriter, r *http.Request, resp *response.Response) {
+	a.templates.RenderNotFound(w, r, resp)
+}
+
+func (a *PDFAdapter) RenderSystemError(w http.ResponseWriter, r *http.Request, err error, resp *response.Response) {
+	a.templates.RenderSystemError(w, r, err, resp)
+}
+
+func (a *PDFAdapter) RenderUnauthorized(w http.ResponseWriter, r *http.Request, resp *response.Response) {
+	a.templates.RenderUnauthorized(w, r, resp)
+}
+
+// capturedResponse is an http.ResponseWriter capturing a response, so it can be converted before it is written.
+type capturedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (c *capturedResponse) Header() http.Header {
+	return c.header
+}
+
+// WriteHeader records the status of the response. Informational responses, such as early hints, are dropped.
+func (c *capturedResponse) WriteHeader(status int) {
+	if status >= 100 && status < 200 {
+		return
+	}
+	c.status = status
+}
+
+func (c *capturedResponse) Write(p []byte) (int, error) {
+	return c.body.Write(p)
+}
+
+// ok reports whether the response has a successful status.
+func (c *capturedResponse) ok() bool {
+	return c.status >= 200 && c.status < 300
+}
+
+// writeTo writes the captured response to w.
+func (c *capturedResponse) writeTo(w http.ResponseWriter) {
+	for key, values := range c.header {
+		w.Header()[key] = values
+	}
+	w.WriteHeader(c.status)
+	_, _ = w.Write(c.body.Bytes())
+}
@@ -0,0 +1,145 @@
+package hyperview_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/response"
+)
+
+// fakePDF is a converter wrapping the HTML page in a fake PDF document.
+var fakePDF = hyperview.PDFConverterFunc(func(_ context.Context, html []byte, w io.Writer) error {
+	_, err := io.WriteString(w, "%PDF "+string(html))
+	return err
+})
+
+func TestPDFAdapter_Render(t *testing.T) {
+	templates := newTestTemplateAdapter(t, fstest.MapFS{
+		"layouts/base.html":          {Data: []byte(`{{define "layout:base"}}<body>{{template "page:main" .}}</body>{{end}}`)},
+		"views/invoices/show.html":   {Data: []byte(`{{define "page:main"}}Invoice {{.Number}}{{end}}`)},
+		"views/invoices/broken.html": {Data: []byte(`{{define "page:main"}}{{.Number.Missing}}{{end}}`)},
+	}, hyperview.TemplateViewAdapterOptions{})
+
+	hv, err := hyperview.NewHyperView()
+	if err != nil {
+		t.Fatalf("error creating HyperView: %v", err)
+	}
+	if err := hv.RegisterAdapter("html", templates); err != nil {
+		t.Fatalf("error registering adapter: %v", err)
+	}
+
+	failing := hyperview.PDFConverterFunc(func(context.Context, []byte, io.Writer) error {
+		return errors.New("converter crashed")
+	})
+
+	tests := []struct {
+		name        string
+		converter   hyperview.PDFConverter
+		resp        *response.Response
+		wantStatus  int
+		wantType    string
+		wantBody    string
+		wantHeaders map[string]string
+	}{
+		{
+			name:      "converted page",
+			converter: fakePDF,
+			resp: response.NewResponse().Path("invoices/show.pdf").Data(map[string]any{"Number": 42}).
+				Header("Content-Disposition", `attachment; filename="invoice-42.pdf"`),
+			wantStatus:  http.StatusOK,
+			wantType:    "application/pdf",
+			wantBody:    "%PDF <body>Invoice 42</body>",
+			wantHeaders: map[string]string{"Content-Disposition": `attachment; filename="invoice-42.pdf"`},
+		},
+		{
+			name:       "render error",
+			converter:  fakePDF,
+			resp:       response.NewResponse().Path("invoices/broken.pdf").Data(map[string]any{"Number": 42}),
+			wantStatus: http.StatusInternalServerError,
+			wantType:   "text/plain",
+		},
+		{
+			name:       "converter error",
+			converter:  failing,
+			resp:       response.NewResponse().Path("invoices/show.pdf"),
+			wantStatus: http.StatusInternalServerError,
+			wantType:   "text/plain",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := hyperview.NewPDFViewAdapter(hyperview.PDFAdapterOptions{Templates: templates, Converter: tt.converter})
+			if err := hv.RegisterAdapter("pdf", adapter); err != nil {
+				t.Fatalf("error registering adapter: %v", err)
+			}
+
+			w := httptest.NewRecorder()
+			hv.Render(w, httptest.NewRequest(http.MethodGet, "/", nil), tt.resp)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("unexpected status: got %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, tt.wantType) {
+				t.Errorf("unexpected content type: got %q, want %q", got, tt.wantType)
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Errorf("unexpected body: got %q, want %q", w.Body.String(), tt.wantBody)
+			}
+			for key, want := range tt.wantHeaders {
+				if got := w.Header().Get(key); got != want {
+					t.Errorf("unexpected %s header: got %q, want %q", key, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPDFAdapter_RenderPDF(t *testing.T) {
+	templates := newTestTemplateAdapter(t, fstest.MapFS{
+		"layouts/base.html":   {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+		"views/report.html":   {Data: []byte(`{{define "page:main"}}Report{{end}}`)},
+		"views/invoices.html": {Data: []byte(`{{define "page:main"}}Invoices{{end}}`)},
+	}, hyperview.TemplateViewAdapterOptions{})
+	adapter := hyperview.NewPDFViewAdapter(hyperview.PDFAdapterOptions{Templates: templates, Converter: fakePDF})
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	pdf, err := adapter.RenderPDF(r, response.NewResponse().Layout("base").Path("report"))
+	if err != nil {
+		t.Fatalf("RenderPDF() error = %v", err)
+	}
+	if string(pdf) != "%PDF Report" {
+		t.Errorf("unexpected document: got %q, want %q", pdf, "%PDF Report")
+	}
+
+	if _, err := adapter.RenderPDF(r, response.NewResponse().Layout("base").Path("missing")); err == nil {
+		t.Error("RenderPDF() of a missing view: expected an error")
+	}
+}
+
+func TestCommandPDFConverter(t *testing.T) {
+	if _, err := exec.LookPath("cat"); err != nil {
+		t.Skip("cat is not available")
+	}
+
+	var out strings.Builder
+	converter := hyperview.CommandPDFConverter{Path: "cat"}
+	if err := converter.Convert(context.Background(), []byte("<p>page</p>"), &out); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if out.String() != "<p>page</p>" {
+		t.Errorf("unexpected output: got %q", out.String())
+	}
+
+	failing := hyperview.CommandPDFConverter{Path: "sh", Args: []string{"-c", "echo boom >&2; exit 1"}}
+	if err := failing.Convert(context.Background(), nil, io.Discard); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("Convert() error = %v, want it to contain the standard error", err)
+	}
+}
@@ -1,24 +1,123 @@
 package hyperview
 
 import (
+	"context"
 	"fmt"
 	"html/template"
 	"io/fs"
 	"log/slog"
-	"path/filepath"
+	"path"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/hypergopher/hyperview/constants"
 	"github.com/hypergopher/hyperview/funcs"
+	"github.com/hypergopher/hyperview/rendercache"
+	"github.com/hypergopher/hyperview/request"
+	"github.com/hypergopher/hyperview/response"
+	"github.com/hypergopher/hyperview/viewmodel"
 )
 
 // TemplateAdapter is a template adapter for the HyperView framework that uses the Go html/template package.
 type TemplateAdapter struct {
-	extension     string
-	fileSystemMap map[string]fs.FS
-	logger        *slog.Logger
-	funcMap       template.FuncMap
-	templates     map[string]*template.Template
+	templateConfig
+	templateState
+	reloadMu sync.Mutex   // serializes Init, Reload, AddFS, RemoveFS, Freeze and Validate, which read the templates while they run
+	initMu   sync.RWMutex // held while Init swaps the templates, and by renders looking up templates until frozen
+	mu       sync.RWMutex // protects layered, until frozen
+	frozen   atomic.Bool  // set by Freeze, after which layered is complete and read without locking
+	watchers loaderWatchers
+}
+
+// templateConfig holds the configuration of the adapter, set by NewTemplateViewAdapter and never changed, but for the
+// file systems and functions, which AddFS, RemoveFS and Mount replace while holding reloadMu.
+type templateConfig struct {
+	extension         string
+	fileSystemMap     map[string]fs.FS
+	fileSystemConfigs map[string]FileSystemConfig
+	loaders           map[string]Loader
+	logger            *slog.Logger
+	funcMap           template.FuncMap
+	memoFuncs         template.FuncMap
+	requestFuncs      template.FuncMap
+	requestHelpers    []string // names of the functions of request.Funcs bound to each request, see RequestHelpers
+	urlBuilder        *request.URLBuilder
+	viewModels        *viewmodel.Registry
+	loaderConcurrency int
+	onRender          RenderHook
+	localizedViews    bool
+	defaultLocale     string
+	tenantResolver    TenantResolver
+	variantSelector   VariantSelector
+	renderLimits      []*renderLimiter
+	renderTimeout     time.Duration
+	maxRenderSize     int64
+	renderCache       rendercache.Store
+	meta              response.Meta
+	lazy              bool
+	strict            bool
+	deprecatedFuncs   map[string]FuncDeprecation
+	deprecations      map[string]TemplateDeprecation // templates deprecated by the DeprecatedTemplates option
+	failOnDeprecated  bool
+	gc                *templateGC
+	flags             FlagProvider
+	sanitizer         Sanitizer
+	initCache         InitCacheOptions
+	pruneTemplateSets bool
+	pathCase          PathCase
+	viewFallbacks     []string
+	viewAliases       map[string]string
+	collisions        CollisionPolicy
+	warmUp            map[string]WarmUpFunc
+	dataValidators    map[string]DataValidator // validators of the DataValidators option, keyed by view name
+	criticalViews     []string
+	renderLog         RenderLogOptions
+	slowRenders       SlowRenderOptions
+	usage             *templateUsage
+	providerFuncs     map[string]string // ID of the view provider adding each function, see Mount
+	stringTemplates   map[string]string // sources of the templates added with ParseString, keyed by path
+	debugToolbar      DebugToolbar
+	devErrorPage      bool
+	debug             *debugBoundaries // nil unless the debug toolbar or the development error page is enabled
+	renderMiddleware  []RenderMiddleware
+	compressor        *compressor // nil unless compression is enabled
+	earlyHints        bool
+	hints             *pageHints
+	assetOptions      ComponentAssetsOptions
+}
+
+// templateState holds the templates built by Init. Init builds a new state and swaps it in once complete, so renders
+// never see a partially built state.
+type templateState struct {
+	deprecatedCalls []DeprecatedCall
+	deprecated      *templateDeprecations // deprecated templates, and their includes found at Init
+	templates       map[string]*template.Template
+	common          *template.Template            // partials and root layouts shared by all pages, never executed
+	commonBytes     int64                         // source size of the common templates
+	partials        []string                      // names of the templates defined in the partials directories
+	partialSet      *template.Template            // clone of the common templates to render partials in isolation
+	pages           map[string]templateFile       // page sources, used to compile pages with extending layouts
+	pageLayouts     map[string]string             // layouts declared by the pages themselves
+	pageVariants    map[string][]string           // template variants of each page, sorted
+	layouts         map[string]layoutFile         // layouts that extend another layout
+	layoutChains    map[string][]string           // ancestry of each extending layout, ending with a root layout
+	layered         map[string]*template.Template // pages compiled on first use, keyed by extending layout and page
+	clones          *scopedClones                 // clones of the page template sets executed with scoped functions
+	lazyPages       bool                          // pages are compiled on first use, in lazy mode or restored from the init cache
+	commonDeps      map[string]templateDeps       // templates rendered by each common template
+	templateFiles   map[string]string             // file defining each common template, keyed by template name
+	sources         map[string]string             // hash of the source of each template file, to detect changes
+	loadedAt        time.Time                     // time of the Init or reload that built the state
+	viewsLoadedAt   map[string]time.Time          // time each view was loaded, when kept from a previous state
+	aliases         map[string]string             // view rendered by each alias of the ViewAliases option
+	report          *InitReport                   // report of the Init or reload that built the state
+	foldedPages     map[string]string             // views and aliases keyed by lowercased name, under FoldCase
+	loadedFS        map[string]fs.FS              // file systems the state was built from, including those of the loaders
+	inlineContents  *InlineContents               // inline scripts and styles of the templates without actions
+	componentAssets *componentAssets              // assets colocated with the partials, nil unless enabled
 }
 
 // TemplateViewAdapterOptions are the options for the TemplateAdapter.
@@ -27,10 +126,184 @@ type TemplateViewAdapterOptions struct {
 	Extension string
 	// FileSystemMap is a map of file systems to use for the templates.
 	FileSystemMap map[string]fs.FS
+	// FileSystemConfigs override the conventions of the adapter for some of the file systems, keyed by file system ID
+	// like FileSystemMap, such as the extensions, directories and delimiters of a third-party view package. The file
+	// systems of AddFS and Mount use the configuration of their ID too.
+	FileSystemConfigs map[string]FileSystemConfig
+	// Collisions is how Init handles the templates defined by the layouts of several file systems, which the file
+	// system loaded last silently shadows otherwise. Default is CollisionWarn. See TemplateAdapter.InitReport.
+	Collisions CollisionPolicy
+	// Loaders load templates from stores other than a file system, such as a database or a CMS, keyed by file system
+	// ID like FileSystemMap. Loaders are called at each Init, and loaders implementing WatchingLoader trigger a reload
+	// when their templates change (see TemplateAdapter.WatchLoaders).
+	Loaders map[string]Loader
 	// Funcs is a map of functions to add to the template.FuncMap.
 	Funcs template.FuncMap
+	// LibraryFuncs are libraries of template functions, such as the Sprig functions of the contrib/sprig module, added
+	// beneath the built-in functions and Funcs, which take precedence over the library functions of the same name.
+	LibraryFuncs template.FuncMap
+	// MemoFuncs is a map of functions to add to the template.FuncMap whose results are cached for the duration of a
+	// single render, keyed by their arguments. This is useful for functions backed by a slow store, such as a settings
+	// lookup called from many partials. Note that memoized functions require the page templates to be cloned, once per
+	// concurrent render as the clones are reused.
+	MemoFuncs template.FuncMap
+	// RequestFuncs declares the request-scoped functions that can be supplied at render time, via Response.WithFuncs
+	// or ContextWithFuncs, along with the default implementation used when a render does not supply them.
+	// Functions must be declared up front because html/template resolves function names when parsing templates.
+	// Note that request-scoped functions require the page templates to be cloned, once per concurrent render as the
+	// clones are reused.
+	RequestFuncs template.FuncMap
+	// RequestHelpers declares the request functions of request.Funcs, currentPath, queryParam, isActive and fullURL,
+	// and binds them to the request of each render, so navigation partials highlight the active link without handlers
+	// computing it, e.g. {{if isActive "/admin"}}. Functions of RequestFuncs with the same names take precedence. Like
+	// RequestFuncs, the helpers require the page templates to be cloned.
+	RequestHelpers bool
+	// URLBuilder declares the absURL and canonicalURL functions of URLBuilder.Funcs and binds them to the request of
+	// each render, for canonical and Open Graph links, e.g. <link rel="canonical" href="{{canonicalURL}}">. Like
+	// RequestHelpers, functions of RequestFuncs with the same names take precedence, and the page templates are cloned.
+	URLBuilder *request.URLBuilder
 	// Logger is the logger to use for the adapter.
 	Logger *slog.Logger
+	// RenderLog configures the structured events logged with Logger: the templates loaded by Init and reloads, and the
+	// start and finish of renders, with their duration and size. Failed renders are logged at the error level.
+	RenderLog RenderLogOptions
+	// SlowRenders logs the renders slower than a threshold at the warning level, with the size of their view data and,
+	// when tracing, the time spent in each template, and calls a hook to alert. See SlowRenderOptions.
+	SlowRenders SlowRenderOptions
+	// LoaderConcurrency is the maximum number of data loaders run at the same time for a response.
+	// Default is response.DefaultLoaderConcurrency.
+	LoaderConcurrency int
+	// ViewModels maps domain types in the view data to their view models before rendering. In strict mode, renders
+	// with data containing raw domain types fail.
+	ViewModels *viewmodel.Registry
+	// OnRender is called after each render with a description of the render, for example to record metrics.
+	OnRender RenderHook
+	// LocalizedViews enables locale-specific view variants, such as views/home/index.de.html, which are rendered
+	// instead of views/home/index.html when the request context carries a matching locale (see the i18n package).
+	// Variants can also live in locale subtrees, such as views/de/home/index.html. Views without a variant for the
+	// locale fall back to the variant for DefaultLocale, then to the default view.
+	LocalizedViews bool
+	// DefaultLocale is the locale whose view variants are rendered when a view has no variant for the request locale,
+	// e.g. when all views live in locale subtrees. It requires LocalizedViews.
+	DefaultLocale string
+	// TenantResolver picks the tenant of each request, such as HostTenants or SubdomainTenants. A tenant is the ID of
+	// the file system holding its templates: views of the tenant's file system are rendered instead of the views with
+	// the same path in the root file system, which are the fallback. A tenant set with ContextWithTenant takes
+	// precedence.
+	TenantResolver TenantResolver
+	// VariantSelector chooses the template variant of pages with variants, such as views/home/index@b.html, for
+	// server-rendered experiments. Without a selector, variants are only rendered when assigned with Response.Variant.
+	// The selector declares the variant function, which returns the variant of an experiment assigned to the render,
+	// e.g. for partials tagging analytics events. Like the feature function, it requires the page templates to be
+	// cloned.
+	VariantSelector VariantSelector
+	// RenderLimits limit the number of concurrent renders of expensive views. The first limit matching a view
+	// applies.
+	RenderLimits []RenderLimit
+	// RenderTimeout is the maximum duration of a render, including its data loaders. Renders taking longer, such as
+	// templates ranging over an unexpectedly large dataset, are aborted at their next write and answered with 503
+	// Service Unavailable, reporting ErrRenderTimeout to the render hook. Renders are also aborted when the request
+	// context is canceled, e.g. when the client disconnects. Zero means no timeout.
+	RenderTimeout time.Duration
+	// MaxRenderSize is the maximum size of a rendered body, in bytes. Renders exceeding it, such as a template ranging
+	// over an unpaginated query result, are aborted instead of buffering the whole body: the view is logged, the
+	// render is answered with 500 Internal Server Error and ErrRenderTooLarge is reported to the render hook. Zero
+	// means no limit.
+	MaxRenderSize int64
+	// RenderCache is the store for cached renders. Pages are cached with Response.Cache and fragments with the
+	// cachedTemplate function. Without a store, nothing is cached.
+	RenderCache rendercache.Store
+	// Meta is the site-wide default of the meta tags rendered with the renderMeta function, such as the site name and
+	// the default share image. Pages set their own tags with Response.Meta.
+	Meta response.Meta
+	// LazyCompile compiles pages on first render instead of at Init, so processes with thousands of views, such as
+	// multi-tenant applications, start quickly and only hold the views they serve. Parse errors in views surface
+	// on first render rather than at Init.
+	LazyCompile bool
+	// TemplateGC evicts template sets compiled on first use that are rarely rendered, bounding the memory held by
+	// long-running processes. It is most useful with LazyCompile.
+	TemplateGC TemplateGCOptions
+	// InitCache records the views found at Init in a file, so the cold starts of serverless deployments and other
+	// short-lived processes restore them instead of walking and parsing every view.
+	InitCache InitCacheOptions
+	// WarmUp builds the responses views are warmed up with by TemplateAdapter.WarmUp, keyed by view path (e.g.
+	// "views/users/show"), such as responses with sample data and the cache keys of the pages the first requests hit.
+	WarmUp map[string]WarmUpFunc
+	// CriticalViews are the views TemplateAdapter.Ready requires, e.g. "home" or "views/system/500", so readiness
+	// probes fail for deployments missing them.
+	CriticalViews []string
+	// PruneTemplateSets compiles each page with the partials it renders, directly or through other partials, and the
+	// root layouts, instead of a clone of all the common templates. This cuts the memory held by applications with
+	// many pages and partials, as html/template copies every template of a clone. Partials rendered with a name only
+	// known at render time, e.g. {{cachedTemplate .Key "1h" .Name .}}, disable the pruning of the pages rendering them.
+	PruneTemplateSets bool
+	// PathCase is the case policy of view names. Views are named after their slash-separated paths on every platform,
+	// e.g. views/home/index, and the paths of responses are normalized the same way, so views\home\index renders
+	// views/home/index too. With LowerCase, views are also named after their lowercased paths, so lookups are
+	// case-insensitive. Default is PreserveCase.
+	PathCase PathCase
+	// ViewFallbacks are the suffixes tried in order when no view matches the path of a response exactly, e.g. "/index"
+	// renders views/users/index for Path("users") or Path("users/"), so handlers need not know which views are index
+	// files of a directory. Default is DefaultViewFallbacks. Set an empty, non-nil slice to only render exact matches.
+	ViewFallbacks []string
+	// ViewAliases render a view under another path, keyed by alias, e.g. "users/edit": "users/update", so views can be
+	// renamed or moved without updating every handler at once. Aliases must not shadow a view and must name an
+	// existing view, or Init fails. See TemplateAdapter.ViewAliases.
+	ViewAliases map[string]string
+	// StrictMode renders templates with html/template's missingkey=error option, so references to keys missing from
+	// the view data, such as typos in field names, fail the render with a *MissingKeyError instead of silently
+	// rendering nothing. Renders can override it with Response.Strict.
+	StrictMode bool
+	// DataValidators check the view data of renders before their templates are executed, keyed by view path (e.g.
+	// "views/users/show"), so renders missing data fail fast with an *InvalidDataError naming the problems, e.g.
+	// with validators built by DataContract.
+	DataValidators map[string]DataValidator
+	// DeprecatedFuncs marks functions of the function map as deprecated, keyed by name. Calls of deprecated functions
+	// are reported at Init, with their call sites (see TemplateAdapter.DeprecatedCalls), and logged on first use
+	// during a render.
+	DeprecatedFuncs map[string]FuncDeprecation
+	// DeprecatedTemplates marks templates as deprecated, keyed by template name, e.g. "card" or "@card", or by view
+	// path, e.g. "views/legacy/home". Template files can also mark themselves deprecated with a leading comment, e.g.
+	// <!-- deprecated: use @card instead -->. Includes of deprecated templates are reported at Init, with their
+	// callers (see TemplateAdapter.DeprecatedIncludes), and the first render of each is logged.
+	DeprecatedTemplates map[string]TemplateDeprecation
+	// FailOnDeprecated makes Init fail when templates call deprecated functions or include deprecated templates, e.g.
+	// in CI.
+	FailOnDeprecated bool
+	// DebugToolbar annotates the rendered pages with the templates that rendered them, with HTML comments and
+	// optionally an overlay. Enable it in development only. Default is DebugToolbarOff.
+	DebugToolbar DebugToolbar
+	// DevErrorPage answers failed renders with a development error page showing the error, the template source
+	// around the failing line, the templates executing when the render failed and a dump of the view data, instead of
+	// the terse error page. Enable it in development only, as the page exposes the templates and data.
+	DevErrorPage bool
+	// RenderMiddleware wraps the rendering of every response, the first middleware outermost. The middleware runs
+	// after the data loaders are registered and before the body is written, so it can change the response and
+	// transform the rendered body (see RenderMiddleware). The render cache stores the bodies before the middleware,
+	// which also runs on the renders served from the cache, with next returning the cached body.
+	RenderMiddleware []RenderMiddleware
+	// Compression compresses the rendered bodies with the encodings accepted by the clients, such as Brotli and gzip,
+	// with pooled encoders. The compressed variants of the renders cached with Response.Cache are cached too, and
+	// ETags differ by encoding. Default is no compression.
+	Compression CompressionOptions
+	// EarlyHints sends a 103 Early Hints response before rendering pages, with the resource hints registered by the
+	// handler with Response.Preload and Response.Preconnect, and those registered by the last render of the page, so
+	// browsers fetch critical assets while the page renders. The hints of a render are always sent as Link headers
+	// of the response. Enable it when the server and the middleware wrapping the http.ResponseWriter pass
+	// informational responses through, as net/http does.
+	EarlyHints bool
+	// Flags evaluates the feature flags of the feature template function, e.g. {{if feature "new-nav"}}, for the
+	// request, so templates roll out changes behind flags without the handler computing each flag. The flags evaluated
+	// by a render are reported to the render hook. Without a provider, templates cannot call feature.
+	Flags FlagProvider
+	// Sanitizer adds the sanitize, markdownSafe and rawIfTrusted template functions, rendering user-generated HTML and
+	// Markdown through its policies, e.g. hypersanitize.Default() of the contrib/sanitize module. Functions of the
+	// Funcs option with the same names take precedence.
+	Sanitizer Sanitizer
+	// ComponentAssets links the stylesheets and scripts colocated with the partials, e.g. partials/card.css next to
+	// partials/card.html, where layouts call {{componentAssets}}: the assets of the partials the page and its layout
+	// can render, de-duplicated, with fingerprinted URLs served by TemplateAdapter.ComponentAssetsHandler.
+	ComponentAssets ComponentAssetsOptions
 }
 
 // NewTemplateViewAdapter creates a new TemplateAdapter.
@@ -44,105 +317,414 @@ func NewTemplateViewAdapter(opts TemplateViewAdapterOptions) *TemplateAdapter {
 		opts.Extension = ".html"
 	}
 
-	return &TemplateAdapter{
-		extension:     opts.Extension,
-		fileSystemMap: opts.FileSystemMap,
-		funcMap:       funcs.FuncMap,
-		logger:        opts.Logger,
-		templates:     make(map[string]*template.Template),
+	funcMap := funcs.FuncMap
+	if len(opts.LibraryFuncs) > 0 {
+		funcMap = MergeFuncs(opts.LibraryFuncs, funcs.FuncMap)
 	}
+
+	return &TemplateAdapter{templateConfig: templateConfig{
+		extension:         opts.Extension,
+		fileSystemMap:     opts.FileSystemMap,
+		fileSystemConfigs: opts.FileSystemConfigs,
+		loaders:           opts.Loaders,
+		funcMap:           funcMap,
+		memoFuncs:         opts.MemoFuncs,
+		requestFuncs:      withRequestHelpers(opts),
+		requestHelpers:    requestHelpers(opts),
+		urlBuilder:        opts.URLBuilder,
+		viewModels:        opts.ViewModels,
+		loaderConcurrency: opts.LoaderConcurrency,
+		logger:            opts.Logger,
+		onRender:          opts.OnRender,
+		localizedViews:    opts.LocalizedViews,
+		defaultLocale:     opts.DefaultLocale,
+		tenantResolver:    opts.TenantResolver,
+		variantSelector:   opts.VariantSelector,
+		renderLimits:      newRenderLimiters(opts.RenderLimits),
+		renderTimeout:     opts.RenderTimeout,
+		maxRenderSize:     opts.MaxRenderSize,
+		renderCache:       opts.RenderCache,
+		meta:              opts.Meta,
+		lazy:              opts.LazyCompile,
+		strict:            opts.StrictMode,
+		dataValidators:    dataValidatorsByView(opts),
+		deprecatedFuncs:   opts.DeprecatedFuncs,
+		deprecations:      opts.DeprecatedTemplates,
+		failOnDeprecated:  opts.FailOnDeprecated,
+		gc:                newTemplateGC(opts.TemplateGC),
+		initCache:         opts.InitCache,
+		pruneTemplateSets: opts.PruneTemplateSets,
+		pathCase:          opts.PathCase,
+		viewFallbacks:     viewFallbacks(opts.ViewFallbacks),
+		viewAliases:       opts.ViewAliases,
+		collisions:        opts.Collisions,
+		warmUp:            opts.WarmUp,
+		criticalViews:     opts.CriticalViews,
+		renderLog:         opts.RenderLog,
+		slowRenders:       opts.SlowRenders,
+		usage:             newTemplateUsage(),
+		debugToolbar:      opts.DebugToolbar,
+		devErrorPage:      opts.DevErrorPage,
+		debug:             newDebugBoundaries(opts.DebugToolbar != DebugToolbarOff || opts.DevErrorPage, opts.SlowRenders.Trace),
+		renderMiddleware:  opts.RenderMiddleware,
+		compressor:        newCompressor(opts.Compression),
+		earlyHints:        opts.EarlyHints,
+		flags:             opts.Flags,
+		sanitizer:         opts.Sanitizer,
+		hints:             newPageHints(),
+		assetOptions:      opts.ComponentAssets,
+	}, templateState: templateState{
+		templates:  make(map[string]*template.Template),
+		clones:     &scopedClones{},
+		deprecated: newTemplateDeprecations(),
+	}}
 }
 
+// Init loads and compiles the templates. It can be called again to reload them, e.g. when they change, and is safe
+// to call concurrently with renders: the templates are rebuilt aside and swapped in once complete, so renders started
+// before the swap complete with the previous templates. If Init fails, the previous templates are kept. Once the
+// adapter is frozen, Init returns ErrFrozen.
 func (a *TemplateAdapter) Init() error {
-	// Reset the template cache
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+
+	if a.frozen.Load() {
+		return ErrFrozen
+	}
+
+	start := time.Now()
+	builder := &TemplateAdapter{templateConfig: a.templateConfig}
+	if _, err := builder.build(nil); err != nil {
+		return err
+	}
+
+	// Renders wait for the swap only, as they hold initMu while looking up templates
+	a.initMu.Lock()
+	a.mu.Lock()
+	a.templateState = builder.templateState
+	a.gc.reset()
+	a.mu.Unlock()
+	a.initMu.Unlock()
+
+	a.logTemplatesLoaded("init", start)
+	return nil
+}
+
+// build loads and compiles the templates into the state of the adapter, which must not be in use. When reloading,
+// the pages of the previous state unaffected by the changes are reused, and returned along with the reused pages.
+// Nothing is reused if the layouts changed or the partials define other templates.
+func (a *TemplateAdapter) build(previous *templateState) (*pageReuse, error) {
+	start := time.Now()
+	fileSystems, err := a.fileSystems(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
 	a.templates = make(map[string]*template.Template)
+	a.pages = make(map[string]templateFile)
+	a.pageLayouts = make(map[string]string)
+	a.pageVariants = make(map[string][]string)
+	a.layouts = make(map[string]layoutFile)
+	a.layered = make(map[string]*template.Template)
+	a.clones = &scopedClones{}
+	a.sources = make(map[string]string)
+	a.deprecated = newTemplateDeprecations()
+	a.lazyPages = a.lazy
+	a.loadedAt = time.Now()
+	a.viewsLoadedAt = make(map[string]time.Time)
+	a.loadedFS = fileSystems
+	a.report = &InitReport{}
+	a.componentAssets = newComponentAssets(a.assetOptions)
 
-	commonTemplates, err := a.loadCommonTemplates()
+	if a.inlineContents, err = a.collectInlineContents(fileSystems); err != nil {
+		return nil, err
+	}
+
+	commonTemplates, err := a.loadCommonTemplates(fileSystems)
 	if err != nil {
-		return fmt.Errorf("error loading partials. %w", err)
+		return nil, fmt.Errorf("error loading partials. %w", err)
+	}
+	if a.componentAssets != nil {
+		a.componentAssets.templateFiles = a.templateFiles
+	}
+	if err := a.reportCollisions(); err != nil {
+		return nil, err
+	}
+	a.common = commonTemplates
+	a.commonDeps = commonTemplateDeps(commonTemplates)
+
+	if a.layoutChains, err = a.resolveLayoutChains(commonTemplates); err != nil {
+		return nil, err
 	}
+	if err := a.resolveDeprecatedTemplates(); err != nil {
+		return nil, err
+	}
+
+	// Views restored from the init cache are compiled on first use, as they parsed at the Init that cached them
+	reuse := a.newPageReuse(previous)
+	cacheKey, restored := "", false
+	if previous == nil {
+		cacheKey, restored = a.restoreInitCache(fileSystems)
+	}
+	if !restored {
+		if err := a.loadViews(fileSystems, reuse); err != nil {
+			return nil, err
+		}
+		a.saveInitCache(cacheKey)
+	}
+
+	a.addContentPage()
+	if err := a.resolveViewAliases(); err != nil {
+		return nil, err
+	}
+
+	for _, variants := range a.pageVariants {
+		sort.Strings(variants)
+	}
+	if err := a.finishReport(fileSystems, start); err != nil {
+		return nil, err
+	}
+
+	return reuse, a.reportDeprecatedCalls()
+}
 
+// loadViews walks the views directories of the file systems, and compiles each view with the common templates unless
+// pages are compiled on first use or reused from the previous state.
+func (a *TemplateAdapter) loadViews(fileSystems map[string]fs.FS, reuse *pageReuse) error {
 	// Function to recursively process directories from all FileSystemMap
-	for fsID, fsys := range a.fileSystemMap {
+	for fsID, fsys := range fileSystems {
 		processDirectory := func(path string, dir fs.DirEntry, err error) error {
 			if err != nil {
 				return err
 			}
 
-			if !dir.IsDir() && filepath.Ext(path) == a.extension {
-				relPath, err := filepath.Rel("", path)
+			if !dir.IsDir() && a.hasExtension(path) {
+				pageName := a.viewName(fsID, path)
+
+				src, err := fs.ReadFile(fsys, path)
 				if err != nil {
 					return err
 				}
-				pageName := strings.TrimSuffix(relPath, filepath.Ext(relPath))
-				if fsID != constants.RootFSID {
-					pageName = fsID + ":" + pageName
+				if err := a.findDeprecatedCalls(path, string(src)); err != nil {
+					return err
 				}
 
-				// Clone the common templates and parse the page template, so we can reuse the common templates for variants
-				tmpl, err := template.Must(commonTemplates.Clone()).ParseFS(fsys, path)
+				file := templateFileKey(fsID, path)
+				a.sources[file] = sourceHash(src)
+				a.pages[pageName] = templateFile{fsys: fsys, path: path, size: int64(len(src))}
+				a.markDeprecatedView(pageName, string(src))
+				if err := a.findViewIncludes(pageName, file, string(src)); err != nil {
+					return err
+				}
+				if page, variant, ok := splitVariant(pageName); ok {
+					a.pageVariants[page] = append(a.pageVariants[page], variant)
+				}
 
+				tmpl, reused, err := reuse.page(a, pageName, file, string(src))
 				if err != nil {
 					return err
 				}
-				a.templates[pageName] = tmpl
+				if reused && tmpl != nil {
+					a.templates[pageName] = tmpl
+				}
+				if reused {
+					a.viewsLoadedAt[pageName] = reuse.previous.viewLoadedAt(pageName)
+				}
+
+				// Clone the common templates and parse the page template, so we can reuse the common templates for
+				// variants. In lazy mode, the page is compiled on first render instead.
+				if !reused && !a.lazyPages {
+					tmpl := a.pageTemplateSet(a.strict)
+					if err := a.parseTemplateSource(tmpl, fsID, path, string(src)); err != nil {
+						return err
+					}
+					if err := a.addCommonTemplates(tmpl); err != nil {
+						return err
+					}
+					a.templates[pageName] = tmpl.Funcs(a.templateFuncs(tmpl))
+				}
+
+				if layout := declaredLayout(string(src)); layout != "" {
+					a.pageLayouts[pageName] = layout
+				}
 			}
 			return nil
 		}
 
 		// If the "views" directory exists, parse it.
 		if _, err := fsys.Open(constants.ViewsDir); err == nil {
+			start := time.Now()
 			if err := fs.WalkDir(fsys, constants.ViewsDir, processDirectory); err != nil {
 				return err
 			}
+			a.report.addParseDuration(fsID, start)
 		}
 	}
 
-	// Uncomment to view the template names found
-	//a.printTemplateNames()
-
 	return nil
 }
 
-func (a *TemplateAdapter) loadCommonTemplates() (*template.Template, error) {
-	commonTemplates := template.New("_common_").Funcs(a.funcMap)
+// newTemplateSet returns an empty template set with the functions of the adapter.
+func (a *TemplateAdapter) newTemplateSet(strict bool) *template.Template {
+	return template.New("_common_").Funcs(a.adapterFuncs()).Funcs(a.funcMap).Funcs(a.deprecatedFuncWrappers()).Funcs(a.memoFuncs).Funcs(a.requestFuncs).Funcs(a.templateFuncs(nil)).
+		Option(missingKeyOption(strict))
+}
 
-	for _, fsys := range a.fileSystemMap {
-		processPartials := func(path string, d fs.DirEntry, err error) error {
+func (a *TemplateAdapter) loadCommonTemplates(fileSystems map[string]fs.FS) (*template.Template, error) {
+	commonTemplates := a.newTemplateSet(a.strict)
+	a.commonBytes = 0
+	a.partials = nil
+	a.templateFiles = make(map[string]string)
+
+	if err := a.addBuiltinPartials(commonTemplates); err != nil {
+		return nil, err
+	}
+
+	// File systems are loaded in a fixed order, the root file system first, so the layouts overriding the blocks of
+	// another file system's layouts do so deterministically
+	fsIDs := sortedFSIDs(fileSystems)
+
+	// Parse the layouts of all the file systems first, so partials can override any blocks they define
+	for _, fsID := range fsIDs {
+		fsys := fileSystems[fsID]
+		start := time.Now()
+		layouts, err := fs.Glob(fsys, constants.LayoutsDir+"/*"+a.extension)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, layout := range layouts {
+			src, err := fs.ReadFile(fsys, layout)
 			if err != nil {
-				return err
+				return nil, err
 			}
+			if err := a.findDeprecatedCalls(layout, string(src)); err != nil {
+				return nil, err
+			}
+			file := templateFileKey(fsID, layout)
+			a.sources[file] = sourceHash(src)
+			a.markDeprecatedFile(file, string(src))
 
-			if !d.IsDir() && filepath.Ext(path) == a.extension {
-				fullPath := path
+			// Layouts extending another layout override its blocks, so they are compiled separately for each page
+			if parent := layoutParent(string(src)); parent != "" {
+				name := strings.TrimSuffix(path.Base(layout), a.extension)
+				if previous, ok := a.layouts[name]; ok && pageFSID(previous.file) != fsID {
+					a.report.addCollision(name, previous.file, file)
+				}
+				a.layouts[name] = layoutFile{templateFile: templateFile{fsys: fsys, path: layout, size: int64(len(src))}, file: file, parent: parent}
+				continue
+			}
 
-				layoutPath := constants.LayoutsDir + "/*" + a.extension
-				_, err := commonTemplates.ParseFS(fsys, layoutPath, fullPath)
+			defined := definedTrees(commonTemplates)
+			if err := a.parseTemplateSource(commonTemplates, fsID, layout, string(src)); err != nil {
+				return nil, err
+			}
+			a.checkLayoutCollisions(commonTemplates, defined, file)
+			a.recordDefinitions(commonTemplates, defined, file)
+			a.commonBytes += int64(len(src))
+		}
+		a.report.addParseDuration(fsID, start)
+	}
+
+	for _, fsID := range fsIDs {
+		fsys := fileSystems[fsID]
+		processPartials := func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
 
+			if !d.IsDir() && a.hasExtension(path) {
+				src, err := fs.ReadFile(fsys, path)
 				if err != nil {
 					return err
 				}
+				if err := a.findDeprecatedCalls(path, string(src)); err != nil {
+					return err
+				}
+				file := templateFileKey(fsID, path)
+				a.sources[file] = sourceHash(src)
+				a.commonBytes += int64(len(src))
+				a.markDeprecatedFile(file, string(src))
+
+				// The content of the file outside of its definitions is a partial too, named by its path relative
+				// to the partials directory (e.g. forms/input for partials/forms/input.html)
+				name := partialName(path, a.extension)
+				defined := definedTrees(commonTemplates)
+				if err := a.parseTemplateAs(commonTemplates, name, fsID, path, string(src)); err != nil {
+					return err
+				}
+				if err := a.checkPartialCollisions(commonTemplates, defined, file); err != nil {
+					return err
+				}
+				for _, t := range commonTemplates.Templates() {
+					if _, ok := defined[t.Name()]; !ok && (t.Name() != name || !isEmptyTemplate(t)) {
+						a.partials = append(a.partials, t.Name())
+					}
+				}
+				a.recordDefinitions(commonTemplates, defined, file)
+				if err := a.componentAssets.load(fsys, fsID, path, a.extension); err != nil {
+					return err
+				}
 			}
 			return nil
 		}
 
 		// If the "partials" directory exists, parse it
 		if _, err := fsys.Open(constants.PartialsDir); err == nil {
+			start := time.Now()
 			if err := fs.WalkDir(fsys, constants.PartialsDir, processPartials); err != nil {
 				return nil, err
 			}
+			a.report.addParseDuration(fsID, start)
 		}
 	}
 
 	return commonTemplates, nil
 }
 
-func (a *TemplateAdapter) printTemplateNames() {
-	for name, tmpl := range a.templates {
-		fmt.Printf("Template: %s\n", name)
-		associatedTemplates := tmpl.Templates()
-		for _, tmpl := range associatedTemplates {
-			fmt.Printf("\tPartial/Child: %s\n", tmpl.Name())
+// log returns the logger of the adapter, or the default logger if none is configured.
+func (a *TemplateAdapter) log() *slog.Logger {
+	if a.logger == nil {
+		return slog.Default()
+	}
+	return a.logger
+}
+
+// adapterFuncs returns the built-in functions that depend on the options of the adapter. They are registered first,
+// so the functions of the function map take precedence.
+func (a *TemplateAdapter) adapterFuncs() template.FuncMap {
+	funcs := template.FuncMap{
+		"renderMeta": a.renderMeta,
+		"cacheKey":   cacheKey,
+	}
+	if a.flags != nil {
+		funcs["feature"] = featureFunc(nil, nil, nil)
+	}
+	if a.variantSelector != nil {
+		funcs["variant"] = variantFunc(nil)
+	}
+	if a.sanitizer != nil {
+		for name, fn := range sanitizerFuncs(a.sanitizer) {
+			funcs[name] = fn
 		}
 	}
+	if a.assetOptions.Prefix != "" {
+		funcs["componentAssets"] = componentAssetsFunc
+	}
+	if a.debug != nil {
+		for name, fn := range a.debug.funcs() {
+			funcs[name] = fn
+		}
+	}
+	return funcs
+}
+
+// templateFuncs returns the functions that need access to the template set they are executed in. They are
+// registered with a nil set at parse time and bound to the page template set once it is complete.
+func (a *TemplateAdapter) templateFuncs(tmpl *template.Template) template.FuncMap {
+	return template.FuncMap{
+		"renderComponent": renderComponentFunc(tmpl),
+		"cachedTemplate":  a.cachedTemplateFunc(tmpl, nil),
+		"deferred":        deferredFunc(tmpl),
+	}
 }
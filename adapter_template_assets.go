@@ -0,0 +1,266 @@
+package hyperview
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hypergopher/hyperview/constants"
+)
+
+// ComponentAssetsOptions configure the stylesheets and scripts colocated with the partials, such as
+// partials/card.css and partials/card.js next to partials/card.html, which the componentAssets function of the
+// layouts links for the partials a page renders.
+type ComponentAssetsOptions struct {
+	// Prefix is the URL path that TemplateAdapter.ComponentAssetsHandler is served under, e.g. /_components/.
+	// Component assets are disabled when empty.
+	Prefix string
+	// Bundle links a single stylesheet and a single script per page, concatenating the assets of its partials, rather
+	// than a file per partial.
+	Bundle bool
+}
+
+// componentAssetsMarker is the output of componentAssets, replaced with the tags linking the assets of the page once
+// the page is executed.
+const componentAssetsMarker = "<!--hyperview:component-assets-->"
+
+// componentAssets holds the assets colocated with the partials, loaded by Init, and serves them and their bundles by
+// fingerprinted URL.
+type componentAssets struct {
+	ComponentAssetsOptions
+	files         map[string][]*componentAsset // assets of each partial file, keyed like templateFiles
+	templateFiles map[string]string            // file defining each common template, set once they are loaded
+	served        sync.Map                     // path under the prefix -> *componentAsset, including the bundles
+
+	mu    sync.RWMutex
+	pages map[hintKey]pageAssets // assets of each page and layout, computed on their first render
+}
+
+// componentAsset is a stylesheet or script, of a partial or bundled.
+type componentAsset struct {
+	url     string
+	ext     string // .css or .js
+	content []byte
+}
+
+// pageAssets are the URLs of the assets of a page rendered with a layout.
+type pageAssets struct {
+	styles, scripts []string
+}
+
+// newComponentAssets returns the registry of the component assets, or nil if they are disabled.
+func newComponentAssets(opts ComponentAssetsOptions) *componentAssets {
+	if opts.Prefix == "" {
+		return nil
+	}
+	opts.Prefix = "/" + strings.Trim(opts.Prefix, "/") + "/"
+	return &componentAssets{
+		ComponentAssetsOptions: opts,
+		files:                  make(map[string][]*componentAsset),
+		pages:                  make(map[hintKey]pageAssets),
+	}
+}
+
+// load reads the stylesheet and the script colocated with the partial file at filePath, if any, e.g. card.css and
+// card.js for card.html.
+func (c *componentAssets) load(fsys fs.FS, fsID, filePath, extension string) error {
+	if c == nil {
+		return nil
+	}
+
+	base := strings.TrimSuffix(filePath, extension)
+	for _, ext := range []string{".css", ".js"} {
+		content, err := fs.ReadFile(fsys, base+ext)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		name := base
+		if fsID != constants.RootFSID {
+			name = fsID + "/" + base
+		}
+		asset := c.add(name, ext, content)
+		file := templateFileKey(fsID, filePath)
+		c.files[file] = append(c.files[file], asset)
+	}
+	return nil
+}
+
+// add registers the asset under a URL fingerprinted with the hash of its content, e.g. /_components/partials/card.
+// 3f2a1b9c.css.
+func (c *componentAssets) add(name, ext string, content []byte) *componentAsset {
+	sum := sha256.Sum256(content)
+	asset := &componentAsset{
+		url:     c.Prefix + name + "." + hex.EncodeToString(sum[:4]) + ext,
+		ext:     ext,
+		content: content,
+	}
+	actual, _ := c.served.LoadOrStore(strings.TrimPrefix(asset.url, c.Prefix), asset)
+	return actual.(*componentAsset)
+}
+
+// page returns the assets of the page rendered with the template set and the root layout, those of the partial files
+// defining the templates it can render, computed on the first render of the page with the layout of the response.
+func (c *componentAssets) page(tmpl *template.Template, rootLayout, pageName, layout string) pageAssets {
+	key := hintKey{page: pageName, layout: layout}
+	c.mu.RLock()
+	assets, ok := c.pages[key]
+	c.mu.RUnlock()
+	if ok {
+		return assets
+	}
+
+	var files []string
+	reached, all := reachableInSet(tmpl, rootLayout)
+	seen := make(map[string]bool)
+	for name, file := range c.templateFiles {
+		if (all || reached[name]) && !seen[file] && len(c.files[file]) > 0 {
+			seen[file] = true
+			files = append(files, file)
+		}
+	}
+	sort.Strings(files)
+
+	var styles, scripts []*componentAsset
+	for _, file := range files {
+		for _, asset := range c.files[file] {
+			if asset.ext == ".css" {
+				styles = append(styles, asset)
+			} else {
+				scripts = append(scripts, asset)
+			}
+		}
+	}
+	if c.Bundle {
+		styles, scripts = c.bundle(styles, ".css"), c.bundle(scripts, ".js")
+	}
+	for _, asset := range styles {
+		assets.styles = append(assets.styles, asset.url)
+	}
+	for _, asset := range scripts {
+		assets.scripts = append(assets.scripts, asset.url)
+	}
+
+	c.mu.Lock()
+	c.pages[key] = assets
+	c.mu.Unlock()
+	return assets
+}
+
+// bundle concatenates the assets into a single asset, or returns them as is if there are less than two.
+func (c *componentAssets) bundle(assets []*componentAsset, ext string) []*componentAsset {
+	if len(assets) < 2 {
+		return assets
+	}
+
+	var content bytes.Buffer
+	for _, asset := range assets {
+		content.Write(asset.content)
+		if !bytes.HasSuffix(asset.content, []byte("\n")) {
+			content.WriteByte('\n')
+		}
+		if ext == ".js" {
+			// Keep a missing semicolon from joining the last statement with the first of the next script
+			content.WriteString(";\n")
+		}
+	}
+	return []*componentAsset{c.add("bundle", ext, content.Bytes())}
+}
+
+// inject replaces the marker of componentAssets in the body with the tags linking the assets of the page, if the body
+// holds it. Scripts get the CSP nonce of the request, if any.
+func (c *componentAssets) inject(r *http.Request, body []byte, tmpl *template.Template, rootLayout, pageName, layout string) []byte {
+	if c == nil || !bytes.Contains(body, []byte(componentAssetsMarker)) {
+		return body
+	}
+
+	assets := c.page(tmpl, rootLayout, pageName, layout)
+	var tags strings.Builder
+	for _, url := range assets.styles {
+		tags.WriteString(`<link rel="stylesheet" href="` + template.HTMLEscapeString(url) + `">`)
+	}
+	nonce := nonceAttr(r)
+	for _, url := range assets.scripts {
+		tags.WriteString(`<script src="` + template.HTMLEscapeString(url) + `"` + nonce + ` defer></script>`)
+	}
+	return bytes.ReplaceAll(body, []byte(componentAssetsMarker), []byte(tags.String()))
+}
+
+// reachableInSet returns the names of the templates of the set that executing the named template can render, directly
+// or through other templates, and whether it renders a template whose name is only known at render time. Names are
+// those of the templates as parsed, without the suffixes html/template adds to the templates it escapes in another
+// context.
+func reachableInSet(tmpl *template.Template, name string) (map[string]bool, bool) {
+	reached := make(map[string]bool)
+	queue := []string{name}
+	for len(queue) > 0 {
+		name, _, _ := strings.Cut(queue[len(queue)-1], "$htmltemplate")
+		queue = queue[:len(queue)-1]
+
+		t := tmpl.Lookup(name)
+		if reached[name] || t == nil {
+			continue
+		}
+		reached[name] = true
+		deps := treeDeps(t.Tree)
+		if deps.dynamic {
+			return reached, true
+		}
+		queue = append(queue, deps.refs...)
+	}
+	return reached, false
+}
+
+// ComponentAssetsHandler serves the stylesheets and scripts colocated with the partials, and their bundles, under the
+// Prefix of the ComponentAssets option, with immutable caching as their URLs are fingerprinted. It serves nothing
+// when component assets are disabled.
+//
+//	mux.Handle("GET /_components/", adapter.ComponentAssetsHandler())
+func (a *TemplateAdapter) ComponentAssetsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assets := a.loadedComponentAssets()
+		if assets == nil || !strings.HasPrefix(r.URL.Path, assets.Prefix) {
+			http.NotFound(w, r)
+			return
+		}
+
+		value, ok := assets.served.Load(strings.TrimPrefix(r.URL.Path, assets.Prefix))
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		asset := value.(*componentAsset)
+		if asset.ext == ".css" {
+			w.Header().Set("Content-Type", "text/css; charset=utf-8")
+		} else {
+			w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+		}
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		_, _ = w.Write(asset.content)
+	})
+}
+
+// loadedComponentAssets returns the component assets loaded by the last Init, or nil if they are disabled.
+func (a *TemplateAdapter) loadedComponentAssets() *componentAssets {
+	if !a.frozen.Load() {
+		a.initMu.RLock()
+		defer a.initMu.RUnlock()
+	}
+	return a.componentAssets
+}
+
+// componentAssetsFunc is the componentAssets function, marking where the assets of the page are linked, e.g. in the
+// head of a layout.
+func componentAssetsFunc() template.HTML {
+	return componentAssetsMarker
+}
@@ -0,0 +1,102 @@
+package hyperview_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/hyperviewtest"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func newComponentAssetsAdapter(t *testing.T, bundle bool) *hyperview.TemplateAdapter {
+	t.Helper()
+
+	return hyperviewtest.NewAdapter(t, map[string]string{
+		"layouts/base.html":      `{{define "layout:base"}}<head>{{componentAssets}}</head>{{template "@nav" .}}{{template "page:main" .}}{{end}}`,
+		"partials/nav.html":      `{{define "@nav"}}<nav></nav>{{end}}`,
+		"partials/nav.css":       `nav { display: flex; }`,
+		"partials/card.html":     `{{define "@card"}}<div class="card">{{.Slot "default"}}</div>{{end}}`,
+		"partials/card.css":      `.card { padding: 1rem; }`,
+		"partials/card.js":       `customElements.define("x-card", class extends HTMLElement {})`,
+		"partials/modal.html":    `{{define "@modal"}}<dialog></dialog>{{end}}`,
+		"partials/modal.css":     `dialog { margin: auto; }`,
+		"views/posts/index.html": `{{define "page:main"}}{{component "@card"}}A{{end}}{{component "@card"}}B{{end}}{{end}}`,
+		"views/about.html":       `{{define "page:main"}}About{{end}}`,
+	}, hyperview.TemplateViewAdapterOptions{
+		ComponentAssets: hyperview.ComponentAssetsOptions{Prefix: "/_components/", Bundle: bundle},
+	})
+}
+
+var assetTags = regexp.MustCompile(`<link rel="stylesheet" href="([^"]+)">|<script src="([^"]+)" defer></script>`)
+
+// assetURLs returns the URLs of the stylesheets and scripts linked by the body.
+func assetURLs(body string) []string {
+	var urls []string
+	for _, m := range assetTags.FindAllStringSubmatch(body, -1) {
+		urls = append(urls, m[1]+m[2])
+	}
+	return urls
+}
+
+func TestTemplateAdapter_ComponentAssets(t *testing.T) {
+	adapter := newComponentAssetsAdapter(t, false)
+	handler := adapter.ComponentAssetsHandler()
+
+	// The assets of the partials the page and layout render are linked once each, but not those of other partials
+	w := renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("posts"))
+	urls := assetURLs(w.Body.String())
+	want := []string{`^/_components/partials/card\.[0-9a-f]{8}\.css$`, `^/_components/partials/nav\.[0-9a-f]{8}\.css$`, `^/_components/partials/card\.[0-9a-f]{8}\.js$`}
+	if len(urls) != len(want) {
+		t.Fatalf("unexpected assets: %v in %s", urls, w.Body.String())
+	}
+	for i, pattern := range want {
+		if !regexp.MustCompile(pattern).MatchString(urls[i]) {
+			t.Errorf("unexpected asset %d: %s", i, urls[i])
+		}
+	}
+	if strings.Contains(w.Body.String(), "hyperview:component-assets") {
+		t.Error("expected the marker to be replaced")
+	}
+
+	w = renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("about"))
+	if urls := assetURLs(w.Body.String()); len(urls) != 1 || !strings.Contains(urls[0], "/nav.") {
+		t.Errorf("expected the assets of the layout only, got %v", urls)
+	}
+
+	// The assets are served with immutable caching
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, assetURLs(renderTestTemplate(t, adapter,
+		response.NewResponse().Layout("base").Path("posts")).Body.String())[2], nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != `customElements.define("x-card", class extends HTMLElement {})` ||
+		rec.Header().Get("Content-Type") != "text/javascript; charset=utf-8" ||
+		!strings.Contains(rec.Header().Get("Cache-Control"), "immutable") {
+		t.Errorf("unexpected asset response: %d %v %s", rec.Code, rec.Header(), rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/_components/partials/card.00000000.css", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected a stale fingerprint to be not found, got %d", rec.Code)
+	}
+}
+
+func TestTemplateAdapter_ComponentAssets_Bundle(t *testing.T) {
+	adapter := newComponentAssetsAdapter(t, true)
+
+	w := renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("posts"))
+	urls := assetURLs(w.Body.String())
+	if len(urls) != 2 || !regexp.MustCompile(`^/_components/bundle\.[0-9a-f]{8}\.css$`).MatchString(urls[0]) ||
+		!regexp.MustCompile(`^/_components/partials/card\.[0-9a-f]{8}\.js$`).MatchString(urls[1]) {
+		t.Fatalf("expected a stylesheet bundle and the single script, got %v", urls)
+	}
+
+	rec := httptest.NewRecorder()
+	adapter.ComponentAssetsHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, urls[0], nil))
+	if want := ".card { padding: 1rem; }\nnav { display: flex; }\n"; rec.Body.String() != want {
+		t.Errorf("unexpected bundle: got %q, want %q", rec.Body.String(), want)
+	}
+}
@@ -0,0 +1,117 @@
+package hyperview_test
+
+import (
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/response"
+)
+
+// nestingDepth is the depth of the partials rendered by the nested benchmark page.
+const nestingDepth = 10
+
+// benchmarkFiles returns the templates of the benchmarks: a small page, a large page ranging over many items, a page
+// rendering deeply nested partials, and filler views so Init has some work to do.
+func benchmarkFiles() fstest.MapFS {
+	files := fstest.MapFS{
+		"layouts/base.html": {Data: []byte(`{{define "layout:base"}}<!DOCTYPE html><html><head><title>{{.View.Title}}</title>` +
+			`</head><body>{{template "page:main" .}}</body></html>{{end}}`)},
+		"partials/item.html": {Data: []byte(`{{define "item"}}<li class="{{if isEven .Index}}even{{else}}odd{{end}}">` +
+			`<a href="/items/{{.ID}}">{{.Name | upper}}</a> {{comma .Price}}</li>{{end}}`)},
+		"views/small.html":  {Data: []byte(`{{define "page:main"}}<h1>Hello, {{.Name}}</h1>{{end}}`)},
+		"views/large.html":  {Data: []byte(`{{define "page:main"}}<ul>{{range .Items}}{{template "item" .}}{{end}}</ul>{{end}}`)},
+		"views/nested.html": {Data: []byte(`{{define "page:main"}}{{template "level0" .}}{{end}}`)},
+	}
+	for n := range nestingDepth {
+		next := fmt.Sprintf(`{{template "level%d" .}}`, n+1)
+		if n == nestingDepth-1 {
+			next = `{{.Name}}`
+		}
+		files[fmt.Sprintf("partials/level%d.html", n)] = &fstest.MapFile{
+			Data: []byte(fmt.Sprintf(`{{define "level%d"}}<div class="level-%d">%s</div>{{end}}`, n, n, next)),
+		}
+	}
+	for n := range 50 {
+		files[fmt.Sprintf("views/filler/page%d.html", n)] = &fstest.MapFile{
+			Data: []byte(`{{define "page:main"}}<p>{{.Name}}</p>{{template "item" .}}{{end}}`),
+		}
+	}
+	return files
+}
+
+type benchmarkItem struct {
+	Index int
+	ID    int
+	Name  string
+	Price int
+}
+
+func BenchmarkTemplateAdapter_Init(b *testing.B) {
+	files := benchmarkFiles()
+	b.ReportAllocs()
+	for range b.N {
+		adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+			FileSystemMap: map[string]fs.FS{constants.RootFSID: files},
+		})
+		if err := adapter.Init(); err != nil {
+			b.Fatalf("error initializing adapter: %v", err)
+		}
+	}
+}
+
+func BenchmarkTemplateAdapter_Render(b *testing.B) {
+	items := make([]benchmarkItem, 1000)
+	for n := range items {
+		items[n] = benchmarkItem{Index: n, ID: n, Name: fmt.Sprintf("item %d", n), Price: n * 1000}
+	}
+
+	benchmarks := []struct {
+		name string
+		path string
+		data map[string]any
+	}{
+		{name: "small page", path: "small", data: map[string]any{"Name": "Ada"}},
+		{name: "large page", path: "large", data: map[string]any{"Items": items}},
+		{name: "deep partial nesting", path: "nested", data: map[string]any{"Name": "Ada"}},
+	}
+
+	adapters := []struct {
+		name string
+		opts hyperview.TemplateViewAdapterOptions
+	}{
+		{name: "plain"},
+		{name: "request funcs", opts: hyperview.TemplateViewAdapterOptions{
+			RequestFuncs: map[string]any{"user": func() string { return "" }},
+		}},
+	}
+
+	for _, ab := range adapters {
+		opts := ab.opts
+		opts.FileSystemMap = map[string]fs.FS{constants.RootFSID: benchmarkFiles()}
+		adapter := hyperview.NewTemplateViewAdapter(opts)
+		if err := adapter.Init(); err != nil {
+			b.Fatalf("error initializing adapter: %v", err)
+		}
+
+		for _, bb := range benchmarks {
+			b.Run(ab.name+"/"+bb.name, func(b *testing.B) {
+				r := httptest.NewRequest(http.MethodGet, "/", nil)
+				b.ReportAllocs()
+				for range b.N {
+					w := httptest.NewRecorder()
+					adapter.Render(w, r, response.NewResponse().Layout("base").Path(bb.path).Data(bb.data))
+					if w.Code != http.StatusOK || !strings.HasSuffix(w.Body.String(), "</html>") {
+						b.Fatalf("unexpected response %d: %s", w.Code, w.Body.String())
+					}
+				}
+			})
+		}
+	}
+}
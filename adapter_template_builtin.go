@@ -0,0 +1,30 @@
+package hyperview
+
+import (
+	"html/template"
+
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/pagination"
+	"github.com/hypergopher/hyperview/response"
+)
+
+// builtinPartials are the sources of the built-in partials, keyed by the name of their file.
+var builtinPartials = []struct {
+	name string
+	src  string
+}{
+	{"_breadcrumbs", response.BreadcrumbsTemplate},
+	{"_flashes", response.FlashesTemplate},
+	{"_pagination", pagination.Template},
+}
+
+// addBuiltinPartials parses the built-in partials, such as @pagination, into the common templates. They are parsed
+// before the layouts and partials, so applications can override them by defining partials with the same names.
+func (a *TemplateAdapter) addBuiltinPartials(common *template.Template) error {
+	for _, partial := range builtinPartials {
+		if err := a.parseTemplateSource(common, constants.RootFSID, partial.name+a.extension, partial.src); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package hyperview
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/response"
+)
+
+// cachedRender returns the body cached for the response, if it has a cache key and the adapter has a render cache.
+func (a *TemplateAdapter) cachedRender(r *http.Request, resp *response.Response) ([]byte, bool) {
+	if a.renderCache == nil || resp.CacheKey() == "" {
+		return nil, false
+	}
+
+	body, ok, err := a.renderCache.Get(r.Context(), resp.CacheKey())
+	if err != nil {
+		a.logCacheError("error reading render cache", resp.CacheKey(), err)
+		return nil, false
+	}
+	return body, ok
+}
+
+// cacheRender stores the rendered body of the response, if it has a cache key and the adapter has a render cache.
+// Bodies specific to the request they were rendered for, such as those holding its CSP nonce, are not cached, as later
+// requests would get the values of the first, and are logged as a warning instead.
+func (a *TemplateAdapter) cacheRender(r *http.Request, resp *response.Response, body []byte, data map[string]any) {
+	if a.renderCache == nil || resp.CacheKey() == "" {
+		return
+	}
+	if reason := requestSpecific(r, body, data); reason != "" {
+		a.log().Warn("Render not cached: the page is specific to the request", slog.String("key", resp.CacheKey()),
+			slog.String("template", resp.TemplatePath()), slog.String("reason", reason))
+		return
+	}
+
+	if err := a.renderCache.Set(r.Context(), resp.CacheKey(), bytes.Clone(body), resp.CacheTTL()); err != nil {
+		a.logCacheError("error writing render cache", resp.CacheKey(), err)
+	}
+}
+
+// requestSpecific returns what makes the body rendered with the view data specific to the request, or an empty string
+// if nothing does: the CSP nonce of the request, e.g. from cspNonce or scriptTag, which later requests would send in a
+// policy other than that of the cached body, blocking its scripts; the CSRF token of the visitor, e.g. from csrfField,
+// failing the forms of other visitors; or the flash messages of the request.
+func requestSpecific(r *http.Request, body []byte, data map[string]any) string {
+	if nonce, _ := r.Context().Value(constants.NonceContextKey).(string); nonce != "" && bytes.Contains(body, []byte(nonce)) {
+		return "CSP nonce"
+	}
+	if token := response.SlotsFromContext(r.Context()).CSRFToken; token != "" && bytes.Contains(body, []byte(token)) {
+		return "CSRF token"
+	}
+	if view, ok := data["View"].(*response.Data); ok && view.FlashesShown() {
+		return "flash messages"
+	}
+	return ""
+}
+
+// logCacheError logs a failure of the render cache store. Renders fall back to executing the template, so a store
+// outage degrades performance rather than failing requests.
+func (a *TemplateAdapter) logCacheError(msg, key string, err error) {
+	a.log().Warn(msg, slog.String("key", key), slog.String("err", err.Error()))
+}
+
+// cachedTemplateFunc returns the cachedTemplate function, which renders the named template from tmpl with data and
+// caches the output in the render cache under key for ttl. The ttl is a time.Duration, a duration string such as "5m"
+// or a number of seconds. Without a render cache, the template is rendered on every call.
+//
+// The function is bound to the request of each render by scopeTemplate, so fragments holding values specific to the
+// request, such as its CSP nonce or CSRF token, are not cached, like the pages of cacheRender. Outside of a request, r
+// is nil.
+//
+// Example:
+//
+//	{{cachedTemplate (print "sidebar:" .User.ID) "5m" "partial:sidebar" .}}
+func (a *TemplateAdapter) cachedTemplateFunc(tmpl *template.Template, r *http.Request) func(key string, ttl any, name string, data any) (template.HTML, error) {
+	store := a.renderCache
+	return func(key string, ttl any, name string, data any) (template.HTML, error) {
+		if tmpl == nil {
+			return "", fmt.Errorf("template %s rendered outside of a template set", name)
+		}
+
+		expiry, err := parseCacheTTL(ttl)
+		if err != nil {
+			return "", fmt.Errorf("error caching template %s: %w", name, err)
+		}
+
+		ctx := context.Background()
+		if r != nil {
+			ctx = r.Context()
+		}
+		if store != nil {
+			cached, ok, err := store.Get(ctx, key)
+			if err != nil {
+				a.logCacheError("error reading render cache", key, err)
+			} else if ok {
+				return template.HTML(cached), nil
+			}
+		}
+
+		view, _ := viewData(data)
+		flashesShown := view != nil && view.FlashesShown()
+
+		buf := new(bytes.Buffer)
+		if err := tmpl.ExecuteTemplate(buf, name, data); err != nil {
+			return "", fmt.Errorf("error rendering template %s: %w", name, err)
+		}
+		if store == nil {
+			return template.HTML(buf.String()), nil
+		}
+
+		reason := ""
+		if r != nil {
+			reason = requestSpecific(r, buf.Bytes(), nil)
+		}
+		if reason == "" && view != nil && !flashesShown && view.FlashesShown() {
+			reason = "flash messages"
+		}
+		if reason != "" {
+			a.log().Warn("Fragment not cached: the fragment is specific to the request", slog.String("key", key),
+				slog.String("template", name), slog.String("reason", reason))
+			return template.HTML(buf.String()), nil
+		}
+
+		if err := store.Set(ctx, key, bytes.Clone(buf.Bytes()), expiry); err != nil {
+			a.logCacheError("error writing render cache", key, err)
+		}
+		return template.HTML(buf.String()), nil
+	}
+}
+
+// viewData returns the view data of the data passed to a template, such as the dot of a page.
+func viewData(data any) (*response.Data, bool) {
+	switch data := data.(type) {
+	case *response.Data:
+		return data, true
+	case map[string]any:
+		view, ok := data["View"].(*response.Data)
+		return view, ok
+	}
+	return nil, false
+}
+
+func parseCacheTTL(ttl any) (time.Duration, error) {
+	switch ttl := ttl.(type) {
+	case time.Duration:
+		return ttl, nil
+	case string:
+		return time.ParseDuration(ttl)
+	case int:
+		return time.Duration(ttl) * time.Second, nil
+	default:
+		return 0, fmt.Errorf("ttl must be a time.Duration, a duration string or a number of seconds, got %T", ttl)
+	}
+}
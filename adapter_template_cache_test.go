@@ -0,0 +1,175 @@
+package hyperview_test
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/csp"
+	"github.com/hypergopher/hyperview/hyperviewtest"
+	"github.com/hypergopher/hyperview/rendercache"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func newCachingTestAdapter(t *testing.T, store rendercache.Store, calls *int) *hyperview.TemplateAdapter {
+	t.Helper()
+
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{
+			"layouts/base.html":     {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+			"partials/sidebar.html": {Data: []byte(`{{define "partial:sidebar"}}<aside>{{count_}}:{{.User}}</aside>{{end}}`)},
+			"views/home.html":       {Data: []byte(`{{define "page:main"}}{{count_}}{{cachedTemplate (print "sidebar:" .User) "5m" "partial:sidebar" .}}{{end}}`)},
+		}},
+		Funcs: map[string]any{
+			"count_": func() int {
+				*calls++
+				return *calls
+			},
+		},
+		RenderCache: store,
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+	return adapter
+}
+
+func TestTemplateAdapter_RenderCache(t *testing.T) {
+	store := rendercache.NewLRU(0)
+	calls := 0
+	adapter := newCachingTestAdapter(t, store, &calls)
+
+	render := func(user string, cache bool) string {
+		resp := response.NewResponse().Layout("base").Path("home").AddDataItem("User", user)
+		if cache {
+			resp.Cache("home:"+user, 0)
+		}
+		return renderTestTemplate(t, adapter, resp).Body.String()
+	}
+
+	// The sidebar fragment is cached per user, while the page is executed on every render
+	if got := render("ann", false); got != "1<aside>2:ann</aside>" {
+		t.Errorf("unexpected first render: %s", got)
+	}
+	if got := render("ann", false); got != "3<aside>2:ann</aside>" {
+		t.Errorf("expected the cached fragment, got %s", got)
+	}
+	if got := render("bob", false); got != "4<aside>5:bob</aside>" {
+		t.Errorf("expected a fragment per key, got %s", got)
+	}
+
+	// Cached pages are served without executing the template
+	first := render("ann", true)
+	if got := render("ann", true); got != first {
+		t.Errorf("expected the cached page %s, got %s", first, got)
+	}
+
+	// Invalidating the keys renders the page and fragment again
+	if err := store.Delete(context.Background(), "home:ann", "sidebar:ann"); err != nil {
+		t.Fatalf("error invalidating: %v", err)
+	}
+	if got := render("ann", true); got == first || !strings.Contains(got, ":ann</aside>") {
+		t.Errorf("expected a fresh render after invalidation, got %s", got)
+	}
+}
+
+func TestTemplateAdapter_RenderCacheDisabled(t *testing.T) {
+	calls := 0
+	adapter := newCachingTestAdapter(t, nil, &calls)
+
+	for _, want := range []string{"1<aside>2:ann</aside>", "3<aside>4:ann</aside>"} {
+		resp := response.NewResponse().Layout("base").Path("home").AddDataItem("User", "ann").Cache("home", 0)
+		if got := renderTestTemplate(t, adapter, resp).Body.String(); got != want {
+			t.Errorf("expected no caching without a store, got %s, want %s", got, want)
+		}
+	}
+}
+
+func TestTemplateAdapter_RenderCacheRequestSpecific(t *testing.T) {
+	store := rendercache.NewLRU(0)
+	adapter := hyperviewtest.NewAdapter(t, map[string]string{
+		"layouts/base.html": `{{define "layout:base"}}{{template "@flashes" .View}}{{template "page:main" .}}{{end}}`,
+		"views/nonce.html":  `{{define "page:main"}}<script nonce="{{cspNonce}}"></script>{{end}}`,
+		"views/plain.html":  `{{define "page:main"}}<p>plain</p>{{end}}`,
+	}, hyperview.TemplateViewAdapterOptions{
+		RequestFuncs: csp.Funcs(""),
+		RenderCache:  store,
+		Logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+
+	render := func(path string, ctx func(context.Context) context.Context) string {
+		var body string
+		csp.Middleware(csp.Options{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w2 := httptest.NewRecorder()
+			adapter.Render(w2, r.WithContext(ctx(r.Context())), response.NewResponse().Layout("base").Path(path).Cache(path, 0))
+			body = w2.Body.String()
+		})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		return body
+	}
+	same := func(ctx context.Context) context.Context { return ctx }
+	withFlash := func(ctx context.Context) context.Context {
+		return response.ContextWithFlashes(ctx, response.Flash{Kind: "success", Message: "Saved"})
+	}
+
+	// Pages holding the nonce of the request are rendered for every request
+	if first, second := render("nonce", same), render("nonce", same); first == second {
+		t.Errorf("expected a nonce per request, got %s twice", first)
+	}
+
+	// Pages showing the flash messages of the request are not cached for the next requests
+	if got := render("plain", withFlash); !strings.Contains(got, "Saved") {
+		t.Errorf("expected the flash message, got %s", got)
+	}
+	if got := render("plain", same); strings.Contains(got, "Saved") {
+		t.Errorf("expected the flash message of another request not to be served, got %s", got)
+	}
+	if _, ok, _ := store.Get(context.Background(), "plain"); !ok {
+		t.Error("expected the page without flash messages to be cached")
+	}
+}
+
+func TestTemplateAdapter_CachedFragmentRequestSpecific(t *testing.T) {
+	store := rendercache.NewLRU(0)
+	adapter := hyperviewtest.NewAdapter(t, map[string]string{
+		"layouts/base.html":    `{{define "layout:base"}}{{template "page:main" .}}{{end}}`,
+		"partials/script.html": `{{define "partial:script"}}<script nonce="{{cspNonce}}"></script>{{end}}`,
+		"views/home.html": `{{define "page:main"}}{{cachedTemplate "script" "5m" "partial:script" .}}` +
+			`{{cache "block" "5m"}}<style nonce="{{cspNonce}}"></style>{{end}}{{cache "plain" "5m"}}<p>plain</p>{{end}}{{end}}`,
+	}, hyperview.TemplateViewAdapterOptions{
+		RequestFuncs: csp.Funcs(""),
+		RenderCache:  store,
+		Logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+
+	render := func() (body, nonce string) {
+		csp.Middleware(csp.Options{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w2 := httptest.NewRecorder()
+			adapter.Render(w2, r, response.NewResponse().Layout("base").Path("home"))
+			body, nonce = w2.Body.String(), csp.NonceFromContext(r.Context())
+		})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		return body, nonce
+	}
+
+	// Each request gets the fragments with its own nonce, rather than those cached for the first request
+	for range 2 {
+		body, nonce := render()
+		if want := `<script nonce="` + nonce + `"></script><style nonce="` + nonce + `"></style><p>plain</p>`; body != want {
+			t.Errorf("expected the fragments with the nonce of the request:\n got %s\nwant %s", body, want)
+		}
+	}
+
+	if _, ok, _ := store.Get(context.Background(), "script"); ok {
+		t.Error("expected the fragment holding the nonce not to be cached")
+	}
+	if _, ok, _ := store.Get(context.Background(), "plain"); !ok {
+		t.Error("expected the fragment without request-specific values to be cached")
+	}
+}
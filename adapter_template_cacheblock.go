@@ -0,0 +1,200 @@
+package hyperview
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// cacheBlockPrefix prefixes the names of the templates hoisted out of cache blocks.
+const cacheBlockPrefix = "_cache:"
+
+// cacheKey returns the key of a cached fragment: the key followed by the values the fragment varies by, separated by
+// colons. Cache blocks are rewritten to calls to cachedTemplate with the key built by cacheKey.
+//
+// Example:
+//
+//	{{cachedTemplate (cacheKey "sidebar" .User.ID) "5m" "partial:sidebar" .}}
+func cacheKey(key any, vary ...any) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprint(key))
+	for _, v := range vary {
+		b.WriteString(":")
+		b.WriteString(fmt.Sprint(v))
+	}
+	return b.String()
+}
+
+// cacheFrame tracks an open block while preprocessing cache blocks.
+type cacheFrame struct {
+	keyword string           // "cache" or the keyword of another control structure
+	open    templateAction   // the action that opened the block
+	args    []string         // the operands of the cache action, for cache blocks
+	body    *strings.Builder // the collected content, for cache blocks
+}
+
+// preprocessCacheBlocks rewrites cache blocks in src into calls to cachedTemplate, so the output of the enclosed
+// fragment is cached in the render cache:
+//
+//	{{cache "sidebar" "5m" .User.ID}}...{{end}}
+//
+// The operands are the key, the ttl and the values the fragment varies by, which are appended to the key. Like the
+// slots of component blocks, the content of each cache block is hoisted into its own top-level template definition
+// at the end of the source, executed with the caller's dot but without the variables declared outside the block.
+// Cache blocks can be nested, so a cached page section can hold cached fragments invalidated independently.
+func preprocessCacheBlocks(src, name string) (string, lineMap, error) {
+	if !strings.Contains(src, "cache") {
+		return src, nil, nil
+	}
+
+	var out strings.Builder
+	hoisted := newHoister(src)
+	var stack []*cacheFrame
+	count := 0
+
+	sink := func() *strings.Builder {
+		for i := len(stack) - 1; i >= 0; i-- {
+			if stack[i].body != nil {
+				return stack[i].body
+			}
+		}
+		return &out
+	}
+
+	pos := 0
+	for {
+		act, ok, err := nextAction(src, pos)
+		if err != nil {
+			return "", nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if !ok {
+			break
+		}
+
+		sink().WriteString(src[pos:act.start])
+		pos = act.end
+		text := src[act.start:act.end]
+
+		switch act.keyword() {
+		case "cache":
+			args, err := splitOperands(act.args())
+			if err != nil || len(args) < 2 {
+				return "", nil, fmt.Errorf("%s: cache at line %d requires a key and a ttl", name, lineAt(src, act.start))
+			}
+			stack = append(stack, &cacheFrame{keyword: "cache", open: act, args: args, body: new(strings.Builder)})
+		case "if", "range", "with", "block", "define":
+			sink().WriteString(text)
+			stack = append(stack, &cacheFrame{keyword: act.keyword(), open: act})
+		case "end":
+			if len(stack) == 0 {
+				// Leave unbalanced ends for the template parser to report
+				sink().WriteString(text)
+				continue
+			}
+
+			frame := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if frame.keyword != "cache" {
+				sink().WriteString(text)
+				continue
+			}
+
+			count++
+			sink().WriteString(cacheBlockCall(src, name, count, frame, act, hoisted))
+		default:
+			sink().WriteString(text)
+		}
+	}
+
+	for i := len(stack) - 1; i >= 0; i-- {
+		if stack[i].body != nil {
+			return "", nil, fmt.Errorf("%s: unclosed cache block at line %d", name, lineAt(src, stack[i].open.start))
+		}
+	}
+
+	out.WriteString(src[pos:])
+	out.WriteString(hoisted.defs.String())
+
+	return out.String(), hoisted.lines, nil
+}
+
+// cacheBlockCall builds the cachedTemplate action replacing a cache block, and hoists the template definition of its
+// content.
+func cacheBlockCall(src, name string, count int, frame *cacheFrame, end templateAction, hoisted *hoister) string {
+	id := fmt.Sprintf("%s%s:%d", cacheBlockPrefix, name, count)
+	lines := lineMap{{line: 1, srcLine: lineAt(src, frame.open.end)}}
+	hoisted.define(id, frame.body.String(), lines, frame.open.rightTrim, end.leftTrim)
+
+	key := append([]string{frame.args[0]}, frame.args[2:]...)
+
+	var call strings.Builder
+	call.WriteString("{{")
+	if frame.open.leftTrim {
+		call.WriteString("- ")
+	}
+	call.WriteString("cachedTemplate (cacheKey " + strings.Join(key, " ") + ") " + frame.args[1] + " " + strconv.Quote(id) + " .")
+
+	// Keep the line count of the replaced block so errors in the rest of the file report the right line
+	removed := strings.Count(src[frame.open.start:end.end], "\n") - strings.Count(strings.Join(frame.args, " "), "\n")
+	call.WriteString(strings.Repeat("\n", removed))
+	if end.rightTrim {
+		call.WriteString(" -")
+	}
+	call.WriteString("}}")
+
+	return call.String()
+}
+
+// splitOperands splits the operands of an action, e.g. `"key" (print .A) .B` into `"key"`, `(print .A)` and `.B`.
+// Quoted strings, raw strings, character constants and parenthesized pipelines are kept whole.
+func splitOperands(s string) ([]string, error) {
+	var operands []string
+	start, depth := -1, 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if isSpace(c) && depth == 0 {
+			if start >= 0 {
+				operands = append(operands, s[start:i])
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("unexpected ) in %q", s)
+			}
+		case '"', '\'':
+			i++
+			for i < len(s) && s[i] != c {
+				if s[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			if i >= len(s) {
+				return nil, fmt.Errorf("unterminated quoted string in %q", s)
+			}
+		case '`':
+			closing := strings.IndexByte(s[i+1:], '`')
+			if closing < 0 {
+				return nil, fmt.Errorf("unterminated raw string in %q", s)
+			}
+			i += closing + 1
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("unclosed ( in %q", s)
+	}
+	if start >= 0 {
+		operands = append(operands, s[start:])
+	}
+	return operands, nil
+}
@@ -0,0 +1,164 @@
+package hyperview_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/hyperviewtest"
+	"github.com/hypergopher/hyperview/rendercache"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func newCacheBlockTestAdapter(t *testing.T, store rendercache.Store, view string) *hyperview.TemplateAdapter {
+	t.Helper()
+
+	return hyperviewtest.NewAdapter(t, map[string]string{
+		"layouts/base.html":  `{{define "layout:base"}}{{template "page:main" .}}{{end}}`,
+		"partials/card.html": `{{define "@card"}}<article>{{.Slot "default"}}</article>{{end}}`,
+		"views/home.html":    view,
+	}, hyperview.TemplateViewAdapterOptions{RenderCache: store})
+}
+
+func TestTemplateAdapter_CacheBlock(t *testing.T) {
+	type render struct {
+		data map[string]any
+		want string
+	}
+
+	tests := []struct {
+		name    string
+		view    string
+		renders []render
+		evict   []string
+	}{
+		{
+			name: "cached fragment",
+			view: `{{define "page:main"}}{{.N}}|{{cache "counter" "5m"}}{{.N}}{{end}}{{end}}`,
+			renders: []render{
+				{data: map[string]any{"N": 1}, want: "1|1"},
+				{data: map[string]any{"N": 2}, want: "2|1"},
+			},
+		},
+		{
+			name: "fragment per varying value",
+			view: `{{define "page:main"}}{{cache "user" 300 .User}}{{.User}}:{{.N}}{{end}}{{end}}`,
+			renders: []render{
+				{data: map[string]any{"User": "ann", "N": 1}, want: "ann:1"},
+				{data: map[string]any{"User": "bob", "N": 2}, want: "bob:2"},
+				{data: map[string]any{"User": "ann", "N": 3}, want: "ann:1"},
+			},
+		},
+		{
+			name: "nested fragments",
+			view: `{{define "page:main"}}{{cache "outer" "5m"}}[{{.N}}{{cache "inner" "5m"}}({{.N}}){{end}}]{{end}}{{end}}`,
+			renders: []render{
+				{data: map[string]any{"N": 1}, want: "[1(1)]"},
+				{data: map[string]any{"N": 2}, want: "[1(1)]"},
+			},
+			evict: []string{"outer"},
+		},
+		{
+			name: "fragment within control structures",
+			view: `{{define "page:main"}}{{range .Items}}{{cache "item" "5m" .}}{{if .}}[{{.}}]{{end}}{{end}}{{end}}{{end}}`,
+			renders: []render{
+				{data: map[string]any{"Items": []string{"a", "b"}}, want: "[a][b]"},
+			},
+		},
+		{
+			name: "component within a fragment",
+			view: `{{define "page:main"}}{{cache "card" "5m"}}{{component "@card"}}{{.N}}{{end}}{{end}}{{end}}`,
+			renders: []render{
+				{data: map[string]any{"N": 1}, want: "<article>1</article>"},
+				{data: map[string]any{"N": 2}, want: "<article>1</article>"},
+			},
+		},
+		{
+			name: "fragment within a component",
+			view: `{{define "page:main"}}{{component "@card"}}{{cache "slot" "5m"}}{{.N}}{{end}}{{end}}{{end}}`,
+			renders: []render{
+				{data: map[string]any{"N": 1}, want: "<article>1</article>"},
+				{data: map[string]any{"N": 2}, want: "<article>1</article>"},
+			},
+		},
+		{
+			name: "trim markers",
+			view: "{{define \"page:main\"}}a {{- cache \"trim\" \"5m\" -}} \n b \n {{- end -}} c{{end}}",
+			renders: []render{
+				{data: map[string]any{}, want: "abc"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := rendercache.NewLRU(0)
+			adapter := newCacheBlockTestAdapter(t, store, tt.view)
+
+			for _, r := range tt.renders {
+				w := renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("home").Data(r.data))
+				if got := w.Body.String(); got != r.want {
+					t.Errorf("unexpected body: got %q, want %q", got, r.want)
+				}
+			}
+
+			// Evicting an outer fragment renders it again, with the inner fragments still cached
+			for _, key := range tt.evict {
+				if err := store.Delete(context.Background(), key); err != nil {
+					t.Fatalf("error deleting %s: %v", key, err)
+				}
+				w := renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("home").Data(map[string]any{"N": 3}))
+				if got, want := w.Body.String(), "[3(1)]"; got != want {
+					t.Errorf("unexpected body after evicting %s: got %q, want %q", key, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestTemplateAdapter_CacheBlock_WithoutStore(t *testing.T) {
+	adapter := newCacheBlockTestAdapter(t, nil, `{{define "page:main"}}{{cache "counter" "5m"}}{{.N}}{{end}}{{end}}`)
+
+	// Without a render cache, the fragment is rendered every time
+	for _, want := range []string{"1", "2"} {
+		w := renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("home").AddDataItem("N", want))
+		if got := w.Body.String(); got != want {
+			t.Errorf("unexpected body: got %q, want %q", got, want)
+		}
+	}
+}
+
+func TestPreprocessTemplate_CacheBlocks(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		wantErr string
+	}{
+		{name: "missing ttl", src: `{{cache "key"}}x{{end}}`, wantErr: "requires a key and a ttl"},
+		{name: "unbalanced operands", src: `{{cache (print "key" "5m"}}x{{end}}`, wantErr: "requires a key and a ttl"},
+		{name: "unclosed block", src: "\n{{cache \"key\" \"5m\"}}x", wantErr: "unclosed cache block at line 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := hyperview.PreprocessTemplate("views/home.html", tt.src)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("unexpected error: got %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPreprocessTemplate_CacheBlockLines(t *testing.T) {
+	src := "{{define \"page:main\"}}\n{{cache \"key\" \"5m\"}}\nline 3\n{{end}}\n{{.Missing}}{{end}}"
+	out, err := hyperview.PreprocessTemplate("views/home.html", src)
+	if err != nil {
+		t.Fatalf("error preprocessing: %v", err)
+	}
+
+	// The rest of the file keeps its line numbers
+	if idx := strings.Index(out, "{{.Missing}}"); strings.Count(out[:idx], "\n") != 4 {
+		t.Errorf("unexpected line of the action after the block in %q", out)
+	}
+}
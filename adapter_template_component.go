@@ -0,0 +1,274 @@
+package hyperview
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strconv"
+	"strings"
+)
+
+// ComponentData is the data passed to a component template rendered with a component block.
+//
+// Example:
+//
+//	{{component "@card" (dict "Title" .Title)}}
+//		<p>This is the default slot.</p>
+//		{{slot "footer"}}<button>OK</button>{{end}}
+//	{{end}}
+//
+// Within the "@card" template, the props are available via .Props (or .Prop "Title") and the slot content via
+// .Slot "default" and .Slot "footer".
+type ComponentData struct {
+	// Props are the properties passed by the caller, usually built with the dict function.
+	Props map[string]any
+	// Slots are the rendered contents of the slots provided by the caller, keyed by slot name.
+	// Content outside any named slot is available as the "default" slot.
+	Slots map[string]template.HTML
+	// Parent is the data (dot) at the place where the component was called.
+	Parent any
+}
+
+// Prop returns the property with the given key, or nil if it was not passed.
+func (c *ComponentData) Prop(key string) any {
+	return c.Props[key]
+}
+
+// Slot returns the rendered content of the named slot, or an empty string if the caller did not provide it.
+func (c *ComponentData) Slot(name string) template.HTML {
+	return c.Slots[name]
+}
+
+// HasSlot returns true if the caller provided content for the named slot.
+func (c *ComponentData) HasSlot(name string) bool {
+	_, ok := c.Slots[name]
+	return ok
+}
+
+// renderComponentFunc returns the function that component blocks are rewritten to. It renders the slot templates
+// hoisted out of the block with the caller's data and then executes the component template from tmpl.
+func renderComponentFunc(tmpl *template.Template) func(id, slots string, dot any, name string, props ...any) (template.HTML, error) {
+	return func(id, slots string, dot any, name string, props ...any) (template.HTML, error) {
+		if tmpl == nil {
+			return "", fmt.Errorf("component %s rendered outside of a template set", name)
+		}
+
+		data := &ComponentData{
+			Props:  map[string]any{},
+			Slots:  map[string]template.HTML{},
+			Parent: dot,
+		}
+
+		switch len(props) {
+		case 0:
+		case 1:
+			m, ok := props[0].(map[string]any)
+			if !ok {
+				return "", fmt.Errorf("component %s expects props as a map (see dict), got %T", name, props[0])
+			}
+			if m != nil {
+				data.Props = m
+			}
+		default:
+			return "", fmt.Errorf("component %s expects a single props argument, got %d", name, len(props))
+		}
+
+		buf := new(bytes.Buffer)
+		for _, slot := range strings.Fields(slots) {
+			buf.Reset()
+			if err := tmpl.ExecuteTemplate(buf, id+":"+slot, dot); err != nil {
+				return "", fmt.Errorf("error rendering slot %s of component %s: %w", slot, name, err)
+			}
+			data.Slots[slot] = template.HTML(buf.String())
+		}
+
+		if tmpl.Lookup(name) == nil {
+			return "", fmt.Errorf("component not found: %s", name)
+		}
+
+		buf.Reset()
+		if err := tmpl.ExecuteTemplate(buf, name, data); err != nil {
+			return "", err
+		}
+
+		return template.HTML(buf.String()), nil
+	}
+}
+
+// componentFrame tracks an open block while preprocessing component blocks.
+type componentFrame struct {
+	keyword string           // "component", "slot" or the keyword of another block, including cache blocks
+	open    templateAction   // the action that opened the block
+	name    string           // the slot name, for slot blocks
+	body    *strings.Builder // the collected content, for component and slot blocks
+	lines   lineMap          // maps the lines of body to those of the source, for component and slot blocks
+	slots   []componentSlot  // the named slots, for component blocks
+}
+
+// componentSlot is the content of a slot collected from a component block.
+type componentSlot struct {
+	name      string
+	content   string
+	lines     lineMap // maps the lines of content to those of the source
+	rightTrim bool    // the opening action trims the whitespace after it
+	leftTrim  bool    // the closing action trims the whitespace before it
+}
+
+// preprocessComponents rewrites component blocks in src into calls to renderComponent.
+//
+// Go templates cannot pass block content to another template, so the content of each component block (and of each
+// slot block within it) is hoisted into its own top-level template definition at the end of the source. The block
+// itself is replaced with a single action, padded with newlines so line numbers in later errors stay accurate.
+// Because slot content is executed as a separate template, it has access to the caller's dot but not to variables
+// declared outside the block.
+func preprocessComponents(src, name string) (string, lineMap, error) {
+	if !strings.Contains(src, "component") {
+		return src, nil, nil
+	}
+
+	var out strings.Builder
+	hoisted := newHoister(src)
+	var stack []*componentFrame
+	count := 0
+
+	sink := func() *strings.Builder {
+		for i := len(stack) - 1; i >= 0; i-- {
+			if stack[i].body != nil {
+				return stack[i].body
+			}
+		}
+		return &out
+	}
+
+	pos := 0
+	for {
+		act, ok, err := nextAction(src, pos)
+		if err != nil {
+			return "", nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if !ok {
+			break
+		}
+
+		sink().WriteString(src[pos:act.start])
+		pos = act.end
+		text := src[act.start:act.end]
+
+		switch act.keyword() {
+		case "component":
+			if act.args() == "" {
+				return "", nil, fmt.Errorf("%s: component at line %d requires a template name", name, lineAt(src, act.start))
+			}
+			stack = append(stack, &componentFrame{keyword: "component", open: act, body: new(strings.Builder),
+				lines: lineMap{{line: 1, srcLine: lineAt(src, act.end)}}})
+		case "slot":
+			if len(stack) == 0 || stack[len(stack)-1].keyword != "component" {
+				return "", nil, fmt.Errorf("%s: slot at line %d must be placed directly inside a component block", name, lineAt(src, act.start))
+			}
+			slotName, err := strconv.Unquote(act.args())
+			if err != nil || strings.TrimSpace(slotName) == "" || strings.ContainsAny(slotName, " \t\r\n") {
+				return "", nil, fmt.Errorf("%s: slot at line %d requires a quoted name without spaces", name, lineAt(src, act.start))
+			}
+			stack = append(stack, &componentFrame{keyword: "slot", open: act, name: slotName, body: new(strings.Builder),
+				lines: lineMap{{line: 1, srcLine: lineAt(src, act.end)}}})
+		case "if", "range", "with", "block", "define", "cache":
+			sink().WriteString(text)
+			stack = append(stack, &componentFrame{keyword: act.keyword(), open: act})
+		case "end":
+			if len(stack) == 0 {
+				// Leave unbalanced ends for the template parser to report
+				sink().WriteString(text)
+				continue
+			}
+
+			frame := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+
+			switch frame.keyword {
+			case "slot":
+				parent := stack[len(stack)-1]
+				parent.slots = append(parent.slots, componentSlot{
+					name:      frame.name,
+					content:   frame.body.String(),
+					lines:     frame.lines,
+					rightTrim: frame.open.rightTrim,
+					leftTrim:  act.leftTrim,
+				})
+				// The default slot content resumes after the slot block
+				parent.lines = append(parent.lines, lineSegment{
+					line:    strings.Count(parent.body.String(), "\n") + 1,
+					srcLine: lineAt(src, act.end),
+				})
+			case "component":
+				count++
+				call, err := componentCall(src, name, count, frame, act, hoisted)
+				if err != nil {
+					return "", nil, err
+				}
+				sink().WriteString(call)
+			default:
+				sink().WriteString(text)
+			}
+		default:
+			sink().WriteString(text)
+		}
+	}
+
+	for i := len(stack) - 1; i >= 0; i-- {
+		if stack[i].body != nil {
+			return "", nil, fmt.Errorf("%s: unclosed %s block at line %d", name, stack[i].keyword, lineAt(src, stack[i].open.start))
+		}
+	}
+
+	out.WriteString(src[pos:])
+	out.WriteString(hoisted.defs.String())
+
+	return out.String(), hoisted.lines, nil
+}
+
+// componentCall builds the renderComponent action replacing a component block, and hoists the slot template
+// definitions.
+func componentCall(src, name string, count int, frame *componentFrame, end templateAction, hoisted *hoister) (string, error) {
+	id := fmt.Sprintf("_component:%s:%d", name, count)
+
+	slots := frame.slots
+	if content := frame.body.String(); strings.TrimSpace(content) != "" {
+		slots = append([]componentSlot{{
+			name:      "default",
+			content:   content,
+			lines:     frame.lines,
+			rightTrim: frame.open.rightTrim,
+			leftTrim:  end.leftTrim,
+		}}, slots...)
+	}
+
+	names := make([]string, 0, len(slots))
+	seen := make(map[string]bool, len(slots))
+	for _, slot := range slots {
+		if seen[slot.name] {
+			return "", fmt.Errorf("%s: duplicate slot %s in component at line %d", name, slot.name, lineAt(src, frame.open.start))
+		}
+		seen[slot.name] = true
+		names = append(names, slot.name)
+	}
+	for _, slot := range slots {
+		hoisted.define(id+":"+slot.name, slot.content, slot.lines, slot.rightTrim, slot.leftTrim)
+	}
+
+	var call strings.Builder
+	call.WriteString("{{")
+	if frame.open.leftTrim {
+		call.WriteString("- ")
+	}
+	call.WriteString("renderComponent " + strconv.Quote(id) + " " + strconv.Quote(strings.Join(names, " ")) + " . " + frame.open.args())
+
+	// Keep the line count of the replaced block so errors in the rest of the file report the right line
+	removed := strings.Count(src[frame.open.start:end.end], "\n") - strings.Count(frame.open.args(), "\n")
+	call.WriteString(strings.Repeat("\n", removed))
+	if end.rightTrim {
+		call.WriteString(" -")
+	}
+	call.WriteString("}}")
+
+	return call.String(), nil
+}
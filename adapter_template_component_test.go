@@ -0,0 +1,137 @@
+package hyperview_test
+
+import (
+	"io/fs"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/response"
+)
+
+// newTestTemplateAdapter returns an initialized adapter with the options, rendering files as its root file system,
+// next to the other file systems of the options.
+func newTestTemplateAdapter(t *testing.T, files fstest.MapFS, opts hyperview.TemplateViewAdapterOptions) *hyperview.TemplateAdapter {
+	t.Helper()
+
+	fileSystems := maps.Clone(opts.FileSystemMap)
+	if fileSystems == nil {
+		fileSystems = make(map[string]fs.FS, 1)
+	}
+	fileSystems[constants.RootFSID] = files
+	opts.FileSystemMap = fileSystems
+
+	adapter := hyperview.NewTemplateViewAdapter(opts)
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+
+	return adapter
+}
+
+func renderTestTemplate(t *testing.T, adapter hyperview.Adapter, resp *response.Response) *httptest.ResponseRecorder {
+	t.Helper()
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	adapter.Render(w, r, resp)
+
+	return w
+}
+
+func TestTemplateAdapter_Component(t *testing.T) {
+	files := fstest.MapFS{
+		"layouts/base.html": {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+		"partials/card.html": {Data: []byte(`{{define "@card"}}<div class="card"><h2>{{.Prop "Title"}}</h2>` +
+			`{{.Slot "default"}}{{if .HasSlot "footer"}}<footer>{{.Slot "footer"}}</footer>{{end}}</div>{{end}}`)},
+		"views/home.html": {Data: []byte(`{{define "page:main"}}` +
+			`{{component "@card" (dict "Title" .Title)}}<p>{{.Body}}</p>{{slot "footer"}}<b>{{.Title}}</b>{{end}}{{end}}` +
+			`{{range .Items}}{{- component "@card" (dict "Title" .) -}} {{.}} {{- end -}}{{end}}` +
+			`{{end}}`)},
+	}
+
+	adapter := newTestTemplateAdapter(t, files, hyperview.TemplateViewAdapterOptions{})
+
+	resp := response.NewResponse().Layout("base").Path("home").Data(map[string]any{
+		"Title": "Hello",
+		"Body":  "<script>",
+		"Items": []string{"a", "b"},
+	})
+	w := renderTestTemplate(t, adapter, resp)
+
+	want := `<div class="card"><h2>Hello</h2><p>&lt;script&gt;</p><footer><b>Hello</b></footer></div>` +
+		`<div class="card"><h2>a</h2>a</div><div class="card"><h2>b</h2>b</div>`
+	if got := w.Body.String(); got != want {
+		t.Errorf("unexpected body:\ngot  %s\nwant %s", got, want)
+	}
+}
+
+// TestTemplateAdapter_ComponentFileSystems checks the slots of component blocks in files at the same path of two file
+// systems are kept apart, as the templates hoisted out of them are named after the file and its file system.
+func TestTemplateAdapter_ComponentFileSystems(t *testing.T) {
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{
+			constants.RootFSID: fstest.MapFS{
+				"layouts/base.html":  {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+				"partials/box.html":  {Data: []byte(`{{define "@box"}}<div>{{.Slot "default"}}</div>{{end}}`)},
+				"partials/site.html": {Data: []byte(`{{define "@site"}}{{component "@box"}}site{{end}}{{end}}`)},
+				"views/home.html":    {Data: []byte(`{{define "page:main"}}{{template "@site" .}}{{template "@admin" .}}{{end}}`)},
+			},
+			"admin": fstest.MapFS{
+				"partials/site.html": {Data: []byte(`{{define "@admin"}}{{component "@box"}}admin{{end}}{{end}}`)},
+			},
+		},
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+
+	w := renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("home"))
+	if got, want := w.Body.String(), `<div>site</div><div>admin</div>`; got != want {
+		t.Errorf("unexpected body:\ngot  %s\nwant %s", got, want)
+	}
+}
+
+func TestTemplateAdapter_ComponentErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		page    string
+		wantErr string
+	}{
+		{
+			name:    "unclosed component",
+			page:    "{{define \"page:main\"}}{{end}}\n{{component \"@card\"}}",
+			wantErr: "unclosed component block at line 2",
+		},
+		{
+			name:    "slot outside component",
+			page:    `{{define "page:main"}}{{component "@card"}}{{if .}}{{slot "footer"}}{{end}}{{end}}{{end}}{{end}}`,
+			wantErr: "must be placed directly inside a component block",
+		},
+		{
+			name:    "duplicate slot",
+			page:    `{{define "page:main"}}{{component "@card"}}{{slot "a"}}{{end}}{{slot "a"}}{{end}}{{end}}{{end}}`,
+			wantErr: "duplicate slot a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+				FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{
+					"views/home.html": {Data: []byte(tt.page)},
+				}},
+			})
+
+			err := adapter.Init()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Init() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package hyperview
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/hypergopher/hyperview/response"
+)
+
+const (
+	// EncodingBrotli is the Brotli content coding, whose encoders are those of the contrib/brotli module.
+	EncodingBrotli = "br"
+	// EncodingGzip is the gzip content coding.
+	EncodingGzip = "gzip"
+	// DefaultCompressionMinSize is the default size of the smallest body compressed, in bytes. Smaller bodies barely
+	// shrink, if at all.
+	DefaultCompressionMinSize = 1024
+)
+
+// CompressionOptions configure the compression of rendered bodies, negotiated with the Accept-Encoding header of
+// each request.
+type CompressionOptions struct {
+	// Encodings are the content codings to compress with, in order of preference: EncodingGzip, or those of
+	// Encoders. The first one accepted by the client is used. Default is none, disabling compression.
+	Encodings []string
+	// MinSize is the size of the smallest body compressed, in bytes. Default is DefaultCompressionMinSize.
+	MinSize int
+	// GzipLevel is the gzip compression level. Default is gzip.DefaultCompression.
+	GzipLevel int
+	// Encoders create the encoders of the content codings other than gzip, keyed by coding, such as those of the
+	// contrib/brotli module for EncodingBrotli, so applications only depend on the compression libraries they use.
+	// An encoder for EncodingGzip replaces the built-in one.
+	Encoders map[string]func() Encoder
+}
+
+// Encoder is a compressing writer which can be reused, such as a *gzip.Writer: Reset discards its state and makes it
+// write to w.
+type Encoder interface {
+	io.WriteCloser
+	Reset(w io.Writer)
+}
+
+// compressor compresses rendered bodies with pooled encoders.
+type compressor struct {
+	encodings []string
+	minSize   int
+	pools     map[string]*sync.Pool
+}
+
+// newCompressor returns the compressor of the options, or nil if compression is disabled. It panics on encodings
+// without an encoder, as they are a programming error.
+func newCompressor(opts CompressionOptions) *compressor {
+	if len(opts.Encodings) == 0 {
+		return nil
+	}
+	if opts.MinSize == 0 {
+		opts.MinSize = DefaultCompressionMinSize
+	}
+	if opts.GzipLevel == 0 {
+		opts.GzipLevel = gzip.DefaultCompression
+	}
+
+	c := &compressor{encodings: opts.Encodings, minSize: opts.MinSize, pools: make(map[string]*sync.Pool)}
+	for _, encoding := range opts.Encodings {
+		if newEncoder, ok := opts.Encoders[encoding]; ok {
+			c.pools[encoding] = &sync.Pool{New: func() any { return newEncoder() }}
+			continue
+		}
+		switch encoding {
+		case EncodingGzip:
+			level := opts.GzipLevel
+			if _, err := gzip.NewWriterLevel(nil, level); err != nil {
+				panic(fmt.Sprintf("hyperview: invalid gzip compression level %d", level))
+			}
+			c.pools[encoding] = &sync.Pool{New: func() any {
+				w, _ := gzip.NewWriterLevel(nil, level)
+				return w
+			}}
+		default:
+			panic(fmt.Sprintf("hyperview: no encoder for the compression encoding %q, set one with the Encoders option",
+				encoding))
+		}
+	}
+	return c
+}
+
+// compressible reports whether the body of the response is compressed, whatever the client accepts: bodies of at
+// least the minimum size, with a textual content type, which the handler didn't encode itself.
+func (c *compressor) compressible(resp *response.Response, body []byte) bool {
+	if c == nil || len(body) < c.minSize || resp.StatusCode() == http.StatusNoContent {
+		return false
+	}
+
+	headers := resp.Headers()
+	if _, ok := headers["Content-Encoding"]; ok {
+		return false
+	}
+	contentType, ok := headers["Content-Type"]
+	if !ok {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(mediaType, "text/") || strings.HasSuffix(mediaType, "+xml") ||
+		strings.HasSuffix(mediaType, "+json") || mediaType == "application/json" ||
+		mediaType == "application/javascript" || mediaType == "application/xml"
+}
+
+// negotiate returns the encoding of the body for the request, or an empty string to send it as is.
+func (c *compressor) negotiate(r *http.Request, resp *response.Response, body []byte) string {
+	if !c.compressible(resp, body) {
+		return ""
+	}
+
+	accepted := r.Header.Get("Accept-Encoding")
+	for _, encoding := range c.encodings {
+		if acceptsEncoding(accepted, encoding) {
+			return encoding
+		}
+	}
+	return ""
+}
+
+// compress returns the body compressed with the encoding.
+func (c *compressor) compress(body []byte, encoding string) ([]byte, error) {
+	pool := c.pools[encoding]
+	enc := pool.Get().(Encoder)
+	defer pool.Put(enc)
+
+	var buf bytes.Buffer
+	buf.Grow(len(body) / 3)
+	enc.Reset(&buf)
+	if _, err := enc.Write(body); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// compressedBody returns the body of the response compressed with the encoding. Responses cached in the render cache
+// have their compressed variants cached too, under the cache key, the encoding and the hash of the body, so cache hits
+// skip the compression and variants never outlive the body they were compressed from. Like the bodies, variants
+// specific to the request are not cached.
+func (a *TemplateAdapter) compressedBody(r *http.Request, resp *response.Response, body []byte, encoding string) ([]byte, error) {
+	cached := a.renderCache != nil && resp.CacheKey() != "" && requestSpecific(r, body, nil) == ""
+	key := ""
+	if cached {
+		key = resp.CacheKey() + "|" + encoding + "|" + strings.Trim(bodyETag(body), `"`)
+	}
+	if cached {
+		if compressed, ok, err := a.renderCache.Get(r.Context(), key); err != nil {
+			a.logCacheError("error reading render cache", key, err)
+		} else if ok {
+			return compressed, nil
+		}
+	}
+
+	compressed, err := a.compressor.compress(body, encoding)
+	if err != nil {
+		return nil, fmt.Errorf("error compressing %s with %s: %w", resp.TemplatePath(), encoding, err)
+	}
+
+	if cached {
+		if err := a.renderCache.Set(r.Context(), key, compressed, resp.CacheTTL()); err != nil {
+			a.logCacheError("error writing render cache", key, err)
+		}
+	}
+	return compressed, nil
+}
@@ -0,0 +1,148 @@
+package hyperview_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/rendercache"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func TestTemplateAdapter_Compression(t *testing.T) {
+	page := strings.Repeat("<p>compressible</p>", 100)
+	store := rendercache.NewLRU(10)
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{
+			"layouts/base.html": {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+			"views/home.html":   {Data: []byte(`{{define "page:main"}}` + page + `{{end}}`)},
+			"views/small.html":  {Data: []byte(`{{define "page:main"}}small{{end}}`)},
+		}},
+		RenderCache: store,
+		Compression: hyperview.CompressionOptions{
+			Encodings: []string{"deflate", hyperview.EncodingGzip},
+			Encoders: map[string]func() hyperview.Encoder{"deflate": func() hyperview.Encoder {
+				w, _ := flate.NewWriter(nil, flate.DefaultCompression)
+				return w
+			}},
+		},
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+
+	decode := map[string]func(io.Reader) (io.Reader, error){
+		"":        func(r io.Reader) (io.Reader, error) { return r, nil },
+		"deflate": func(r io.Reader) (io.Reader, error) { return flate.NewReader(r), nil },
+		"gzip": func(r io.Reader) (io.Reader, error) {
+			return gzip.NewReader(r)
+		},
+	}
+
+	tests := []struct {
+		name         string
+		resp         func() *response.Response
+		accept       string
+		wantEncoding string
+		wantVary     bool
+		wantBody     string
+	}{
+		{name: "encoder preferred", resp: func() *response.Response { return response.NewResponse().Path("home") },
+			accept: "gzip, deflate, br", wantEncoding: "deflate", wantVary: true, wantBody: page},
+		{name: "gzip", resp: func() *response.Response { return response.NewResponse().Path("home") },
+			accept: "gzip, deflate;q=0", wantEncoding: "gzip", wantVary: true, wantBody: page},
+		{name: "no accepted encoding", resp: func() *response.Response { return response.NewResponse().Path("home") },
+			accept: "", wantVary: true, wantBody: page},
+		{name: "small body", resp: func() *response.Response { return response.NewResponse().Path("small") },
+			accept: "deflate", wantBody: "small"},
+		{name: "binary content type", resp: func() *response.Response {
+			return response.NewResponse().Path("home").Header("Content-Type", "image/svg")
+		}, accept: "deflate", wantBody: page},
+		{name: "cached render", resp: func() *response.Response { return response.NewResponse().Path("home").Cache("home", 0) },
+			accept: "gzip", wantEncoding: "gzip", wantVary: true, wantBody: page},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for range 2 {
+				r := httptest.NewRequest(http.MethodGet, "/", nil)
+				r.Header.Set("Accept-Encoding", tt.accept)
+				w := httptest.NewRecorder()
+				adapter.Render(w, r, tt.resp().Layout("base"))
+
+				if got := w.Header().Get("Content-Encoding"); got != tt.wantEncoding {
+					t.Fatalf("expected encoding %q, got %q", tt.wantEncoding, got)
+				}
+				if got := w.Header().Get("Vary") == "Accept-Encoding"; got != tt.wantVary {
+					t.Errorf("expected Vary %v, got %q", tt.wantVary, w.Header().Get("Vary"))
+				}
+				reader, err := decode[tt.wantEncoding](w.Body)
+				if err != nil {
+					t.Fatalf("error decoding body: %v", err)
+				}
+				body, err := io.ReadAll(reader)
+				if err != nil {
+					t.Fatalf("error decoding body: %v", err)
+				}
+				if string(body) != tt.wantBody {
+					t.Errorf("expected body %q, got %q", tt.wantBody, body)
+				}
+			}
+		})
+	}
+}
+
+func TestTemplateAdapter_CompressionETag(t *testing.T) {
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{
+			"layouts/base.html": {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+			"views/home.html":   {Data: []byte(`{{define "page:main"}}` + strings.Repeat("x", 2000) + `{{end}}`)},
+		}},
+		Compression: hyperview.CompressionOptions{Encodings: []string{hyperview.EncodingGzip}},
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+
+	render := func(accept, ifNoneMatch string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept-Encoding", accept)
+		r.Header.Set("If-None-Match", ifNoneMatch)
+		w := httptest.NewRecorder()
+		adapter.Render(w, r, response.NewResponse().Layout("base").Path("home"))
+		return w
+	}
+
+	plain, gzipped := render("", "").Header().Get("ETag"), render("gzip", "").Header().Get("ETag")
+	if plain == gzipped || !strings.HasSuffix(gzipped, `-gzip"`) {
+		t.Fatalf("expected distinct ETags by encoding, got %s and %s", plain, gzipped)
+	}
+	if w := render("gzip", gzipped); w.Code != http.StatusNotModified {
+		t.Errorf("expected 304 for the gzip ETag, got %d", w.Code)
+	}
+	if w := render("", gzipped); w.Code != http.StatusOK || bytes.Contains(w.Body.Bytes(), []byte{0x1f, 0x8b}) {
+		t.Errorf("expected the uncompressed body for the gzip ETag without gzip, got %d", w.Code)
+	}
+}
+
+func TestTemplateAdapter_CompressionNoEncoder(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(fmt.Sprint(r), "Encoders option") {
+			t.Errorf("expected a panic pointing to the Encoders option, got %v", r)
+		}
+	}()
+
+	hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		Compression: hyperview.CompressionOptions{Encodings: []string{hyperview.EncodingBrotli}},
+	})
+}
@@ -0,0 +1,65 @@
+package hyperview
+
+import (
+	"html/template"
+	"net/http"
+	"testing/fstest"
+	"time"
+
+	"github.com/hypergopher/hyperview/response"
+)
+
+// contentPage is the page wrapping content rendered by other adapters, such as the Markdown adapter, in the layouts.
+// Its main block outputs the Content value of the view data.
+const contentPage = "_content_"
+
+var contentFS = fstest.MapFS{
+	"_content.html": {Data: []byte(`{{define "page:main"}}{{.Content}}{{end}}`)},
+}
+
+// addContentPage registers the content page with the pages. Like pages in lazy mode, it is compiled with a layout on
+// first use, so adapters that never render content do not hold its template sets.
+func (a *TemplateAdapter) addContentPage() {
+	a.pages[contentPage] = templateFile{fsys: contentFS, path: "_content.html", size: int64(len(contentFS["_content.html"].Data))}
+}
+
+// RenderContent renders content produced outside of the templates, such as HTML converted from Markdown, as the main
+// block of the layout of the response, along with the view data of the response. The content is available to the
+// layouts as .Content.
+func (a *TemplateAdapter) RenderContent(w http.ResponseWriter, r *http.Request, resp *response.Response, content template.HTML) {
+	resp.AddDataItem("Content", content)
+
+	tmpl, layout, err := a.contentTemplate(resp)
+	if err != nil {
+		a.handleError(w, r, err)
+		return
+	}
+
+	tmpl, scoped, err := a.scopeTemplate(r, resp, tmpl)
+	if err != nil {
+		a.handleError(w, r, err)
+		return
+	}
+	defer scoped()
+
+	release, ok := a.limitRender(w, r, resp.TemplatePath())
+	if !ok {
+		resp.Status(http.StatusServiceUnavailable)
+		a.notifyRender(r, resp, time.Now(), 0, ErrRenderShed)
+		return
+	}
+	defer release()
+
+	a.execTemplate(w, r, resp, contentPage, tmpl, layout)
+}
+
+// contentTemplate looks up the template set of the content page with the layout of the response, waiting for Init to
+// swap the templates if it is swapping them.
+func (a *TemplateAdapter) contentTemplate(resp *response.Response) (*template.Template, string, error) {
+	if !a.frozen.Load() {
+		a.initMu.RLock()
+		defer a.initMu.RUnlock()
+	}
+
+	return a.lookupTemplate(contentPage, resp.TemplateLayout(), a.strictRender(resp))
+}
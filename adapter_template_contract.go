@@ -0,0 +1,138 @@
+package hyperview
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/hypergopher/hyperview/response"
+)
+
+// DataValidator checks the view data of a render before its templates are executed, and returns an error describing
+// the problems found, such as keys missing from the data or of the wrong type.
+type DataValidator func(data map[string]any) error
+
+// InvalidDataError is returned when the view data of a render fails the DataValidator of its view, before its
+// templates are executed.
+type InvalidDataError struct {
+	// View is the view rendered, e.g. "views/users/show".
+	View string
+	// Err is the error returned by the validator.
+	Err error
+}
+
+func (e *InvalidDataError) Error() string {
+	return fmt.Sprintf("invalid view data for %s: %v", e.View, e.Err)
+}
+
+func (e *InvalidDataError) Unwrap() error {
+	return e.Err
+}
+
+// DataContract returns a validator requiring the view data to hold a key per exported field of the struct T, with a
+// value assignable to the type of the field. Keys are named after the fields, unless a hyperview tag names them, and
+// the fields tagged optional may be missing, e.g.:
+//
+//	type UserShowData struct {
+//		User  *models.User
+//		Posts []models.Post `hyperview:"RecentPosts"`
+//		Tab   string        `hyperview:",optional"`
+//	}
+//
+// Fields of interface types accept any value implementing them, and nil for nilable types. DataContract panics if T
+// is not a struct.
+func DataContract[T any]() DataValidator {
+	t := reflect.TypeFor[T]()
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("hyperview: DataContract of %s, not a struct", t))
+	}
+
+	type field struct {
+		key      string
+		typ      reflect.Type
+		optional bool
+	}
+	var fields []field
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("hyperview"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, field{key: name, typ: f.Type, optional: opts == "optional"})
+	}
+
+	return func(data map[string]any) error {
+		var problems []string
+		for _, f := range fields {
+			value, ok := data[f.key]
+			switch {
+			case !ok:
+				if !f.optional {
+					problems = append(problems, fmt.Sprintf("missing key %q", f.key))
+				}
+			case value == nil:
+				if !isNilable(f.typ) {
+					problems = append(problems, fmt.Sprintf("key %q is nil, want %s", f.key, f.typ))
+				}
+			case !reflect.TypeOf(value).AssignableTo(f.typ):
+				problems = append(problems, fmt.Sprintf("key %q is %T, want %s", f.key, value, f.typ))
+			}
+		}
+		if len(problems) > 0 {
+			return errors.New(strings.Join(problems, "; "))
+		}
+		return nil
+	}
+}
+
+// isNilable reports whether nil is a value of the type.
+func isNilable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		return true
+	}
+	return false
+}
+
+// dataValidatorsByView returns the validators of the DataValidators option keyed by view name, e.g. views/users/show
+// for users/show.
+func dataValidatorsByView(opts TemplateViewAdapterOptions) map[string]DataValidator {
+	if len(opts.DataValidators) == 0 {
+		return nil
+	}
+	c := &templateConfig{pathCase: opts.PathCase}
+	byView := make(map[string]DataValidator, len(opts.DataValidators))
+	for name, validate := range opts.DataValidators {
+		byView[c.normalizeName(response.NewResponse().Path(name).TemplatePath())] = validate
+	}
+	return byView
+}
+
+// validateData checks the view data of the response with the validator of its view, if any: the view of its path, or
+// else the page rendered for it, e.g. the view its path falls back to.
+func (a *TemplateAdapter) validateData(resp *response.Response, pageName string, data map[string]any) error {
+	if a.dataValidators == nil {
+		return nil
+	}
+
+	view := a.normalizeName(resp.TemplatePath())
+	validate, ok := a.dataValidators[view]
+	if !ok {
+		view = pageName
+		if validate, ok = a.dataValidators[view]; !ok {
+			return nil
+		}
+	}
+	if err := validate(data); err != nil {
+		return &InvalidDataError{View: view, Err: err}
+	}
+	return nil
+}
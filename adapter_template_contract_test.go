@@ -0,0 +1,144 @@
+package hyperview_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/response"
+)
+
+type contractUser struct {
+	Name string
+}
+
+type userShowData struct {
+	User   *contractUser
+	Count  int           `hyperview:"PostCount"`
+	Tab    string        `hyperview:",optional"`
+	Status fmt.Stringer  `hyperview:",optional"`
+	Tags   []string      `hyperview:",optional"`
+	Skip   chan struct{} `hyperview:"-"`
+	hidden string
+}
+
+func TestDataContract(t *testing.T) {
+	validate := hyperview.DataContract[userShowData]()
+
+	tests := []struct {
+		name string
+		data map[string]any
+		want string
+	}{
+		{
+			name: "valid",
+			data: map[string]any{"User": &contractUser{}, "PostCount": 3, "Tags": nil},
+		},
+		{
+			name: "nil pointer",
+			data: map[string]any{"User": nil, "PostCount": 3},
+		},
+		{
+			name: "missing keys",
+			data: map[string]any{"Count": 3},
+			want: `missing key "User"; missing key "PostCount"`,
+		},
+		{
+			name: "wrong types",
+			data: map[string]any{"User": contractUser{}, "PostCount": nil, "Tab": 1, "Status": "ok"},
+			want: `key "User" is hyperview_test.contractUser, want *hyperview_test.contractUser; ` +
+				`key "PostCount" is nil, want int; key "Tab" is int, want string; key "Status" is string, want fmt.Stringer`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validate(tt.data)
+			got := ""
+			if err != nil {
+				got = err.Error()
+			}
+			if got != tt.want {
+				t.Errorf("unexpected error:\ngot  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDataContract_NotStruct(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected DataContract to panic for a non-struct type")
+		}
+	}()
+	hyperview.DataContract[map[string]any]()
+}
+
+func TestTemplateAdapter_DataValidators(t *testing.T) {
+	executed := false
+	var renderErr error
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{
+			"layouts/base.html":      {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+			"views/users/show.html":  {Data: []byte(`{{define "page:main"}}{{executed}}{{.User.Name}}: {{.PostCount}}{{end}}`)},
+			"views/users/index.html": {Data: []byte(`{{define "page:main"}}{{executed}}{{len .Users}}{{end}}`)},
+		}},
+		Funcs: map[string]any{
+			"executed": func() string {
+				executed = true
+				return ""
+			},
+		},
+		DataValidators: map[string]hyperview.DataValidator{
+			"users/show": hyperview.DataContract[userShowData](),
+			"views/users/index": func(data map[string]any) error {
+				if _, ok := data["Users"].([]string); !ok {
+					return errors.New("Users must be a []string")
+				}
+				return nil
+			},
+		},
+		OnRender: func(r *http.Request, event hyperview.RenderEvent) { renderErr = event.Err },
+		Logger:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+
+	w := renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("users/show").
+		AddDataItem("User", &contractUser{Name: "Ann"}).AddDataItem("PostCount", 2))
+	if w.Body.String() != "Ann: 2" {
+		t.Errorf("unexpected body: %s", w.Body.String())
+	}
+
+	// Invalid data fails before executing the templates, with the problems found
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	executed = false
+	adapter.Render(httptest.NewRecorder(), r, response.NewResponse().Layout("base").Path("users/show").
+		AddDataItem("User", &contractUser{}))
+
+	var invalid *hyperview.InvalidDataError
+	if !errors.As(renderErr, &invalid) || invalid.View != "views/users/show" ||
+		!strings.Contains(invalid.Error(), `invalid view data for views/users/show: missing key "PostCount"`) {
+		t.Errorf("expected an *InvalidDataError, got %v", renderErr)
+	}
+	if executed {
+		t.Error("expected the templates not to be executed")
+	}
+
+	// The validator of the view a path falls back to applies too
+	adapter.Render(httptest.NewRecorder(), r, response.NewResponse().Layout("base").Path("users").
+		AddDataItem("Users", []int{1}))
+	if !errors.As(renderErr, &invalid) || invalid.View != "views/users/index" {
+		t.Errorf("expected the validator of the fallback view to fail, got %v", renderErr)
+	}
+}
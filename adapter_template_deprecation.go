@@ -0,0 +1,487 @@
+package hyperview
+
+import (
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template/parse"
+
+	"github.com/hypergopher/hyperview/constants"
+)
+
+// FuncDeprecation describes a deprecated template function.
+type FuncDeprecation struct {
+	// Replacement is the function to use instead, if any.
+	Replacement string
+	// Message is an optional explanation, e.g. how to migrate the calls.
+	Message string
+}
+
+// hint returns the advice logged with the uses of the deprecated function.
+func (d FuncDeprecation) hint() string {
+	var parts []string
+	if d.Replacement != "" {
+		parts = append(parts, "use "+d.Replacement+" instead")
+	}
+	if d.Message != "" {
+		parts = append(parts, d.Message)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// DeprecatedCall is a call site of a deprecated template function.
+type DeprecatedCall struct {
+	// Func is the name of the deprecated function.
+	Func string
+	// Path is the path of the template file calling it.
+	Path string
+	// Line is the line of the call in the file.
+	Line int
+	// Deprecation describes the deprecation.
+	Deprecation FuncDeprecation
+}
+
+func (c DeprecatedCall) String() string {
+	s := fmt.Sprintf("%s:%d: %s is deprecated", c.Path, c.Line, c.Func)
+	if hint := c.Deprecation.hint(); hint != "" {
+		s += ": " + hint
+	}
+	return s
+}
+
+// DeprecatedCalls returns the call sites of deprecated functions found at Init, sorted by file and line.
+func (a *TemplateAdapter) DeprecatedCalls() []DeprecatedCall {
+	if !a.frozen.Load() {
+		a.initMu.RLock()
+		defer a.initMu.RUnlock()
+	}
+
+	return append([]DeprecatedCall(nil), a.deprecatedCalls...)
+}
+
+// findDeprecatedCalls records the calls of deprecated functions in the template source.
+func (a *TemplateAdapter) findDeprecatedCalls(filePath, src string) error {
+	if len(a.deprecatedFuncs) == 0 {
+		return nil
+	}
+
+	trees, err := parseSourceTrees(filePath, src)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range trees {
+		walkIdentifiers(t.Root, func(ident *parse.IdentifierNode) {
+			deprecation, ok := a.deprecatedFuncs[ident.Ident]
+			if !ok {
+				return
+			}
+			a.deprecatedCalls = append(a.deprecatedCalls, DeprecatedCall{
+				Func:        ident.Ident,
+				Path:        filePath,
+				Line:        nodeLine(t, ident),
+				Deprecation: deprecation,
+			})
+		})
+	}
+	return nil
+}
+
+// reportDeprecatedCalls sorts the call sites of deprecated functions and the includes of deprecated templates found at
+// Init, and logs them or, with FailOnDeprecated, returns them as an error.
+func (a *TemplateAdapter) reportDeprecatedCalls() error {
+	sort.Slice(a.deprecatedCalls, func(i, j int) bool {
+		if a.deprecatedCalls[i].Path != a.deprecatedCalls[j].Path {
+			return a.deprecatedCalls[i].Path < a.deprecatedCalls[j].Path
+		}
+		return a.deprecatedCalls[i].Line < a.deprecatedCalls[j].Line
+	})
+	sort.Slice(a.deprecated.includes, func(i, j int) bool {
+		if a.deprecated.includes[i].Path != a.deprecated.includes[j].Path {
+			return a.deprecated.includes[i].Path < a.deprecated.includes[j].Path
+		}
+		return a.deprecated.includes[i].Line < a.deprecated.includes[j].Line
+	})
+
+	if len(a.deprecatedCalls) == 0 && len(a.deprecated.includes) == 0 {
+		return nil
+	}
+
+	if a.failOnDeprecated {
+		var uses []string
+		for _, call := range a.deprecatedCalls {
+			uses = append(uses, call.String())
+		}
+		for _, include := range a.deprecated.includes {
+			uses = append(uses, include.String())
+		}
+		return fmt.Errorf("templates use deprecated functions or templates:\n%s", strings.Join(uses, "\n"))
+	}
+
+	for _, call := range a.deprecatedCalls {
+		a.log().Warn("Deprecated template function", slog.String("func", call.Func),
+			slog.String("call", call.Path+":"+strconv.Itoa(call.Line)), slog.String("hint", call.Deprecation.hint()))
+	}
+	for _, include := range a.deprecated.includes {
+		a.log().Warn("Deprecated template included", slog.String("template", include.Template),
+			slog.String("caller", include.Caller), slog.String("call", include.Path+":"+strconv.Itoa(include.Line)),
+			slog.String("hint", include.Deprecation.hint()))
+	}
+	return nil
+}
+
+// deprecatedFuncWrappers returns the deprecated functions wrapped to log their first call during a render, so
+// deprecated functions called from templates parsed after Init, or from other adapters sharing the function map,
+// are noticed too.
+func (a *TemplateAdapter) deprecatedFuncWrappers() map[string]any {
+	wrappers := make(map[string]any)
+	for name, deprecation := range a.deprecatedFuncs {
+		fn, ok := a.funcMap[name]
+		if !ok {
+			continue
+		}
+		wrappers[name] = a.logFirstCall(name, fn, deprecation)
+	}
+	return wrappers
+}
+
+// logFirstCall wraps fn, which must be a function, to log a warning the first time it is called.
+func (a *TemplateAdapter) logFirstCall(name string, fn any, deprecation FuncDeprecation) any {
+	fv := reflect.ValueOf(fn)
+	var once sync.Once
+
+	return reflect.MakeFunc(fv.Type(), func(args []reflect.Value) []reflect.Value {
+		once.Do(func() {
+			a.log().Warn("Deprecated template function called during render", slog.String("func", name),
+				slog.String("hint", deprecation.hint()))
+		})
+		if fv.Type().IsVariadic() {
+			return fv.CallSlice(args)
+		}
+		return fv.Call(args)
+	}).Interface()
+}
+
+// walkIdentifiers calls fn for each function identifier in the tree rooted at node.
+func walkIdentifiers(node parse.Node, fn func(*parse.IdentifierNode)) {
+	walkNodes(node, func(n parse.Node) {
+		if ident, ok := n.(*parse.IdentifierNode); ok {
+			fn(ident)
+		}
+	})
+}
+
+// walkNodes calls fn for each node of the tree rooted at node, parents first.
+func walkNodes(node parse.Node, fn func(parse.Node)) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			walkNodes(child, fn)
+		}
+		return
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+	}
+
+	fn(node)
+	switch n := node.(type) {
+	case *parse.ActionNode:
+		walkNodes(n.Pipe, fn)
+	case *parse.IfNode:
+		walkNodes(n.Pipe, fn)
+		walkNodes(n.List, fn)
+		walkNodes(n.ElseList, fn)
+	case *parse.RangeNode:
+		walkNodes(n.Pipe, fn)
+		walkNodes(n.List, fn)
+		walkNodes(n.ElseList, fn)
+	case *parse.WithNode:
+		walkNodes(n.Pipe, fn)
+		walkNodes(n.List, fn)
+		walkNodes(n.ElseList, fn)
+	case *parse.TemplateNode:
+		walkNodes(n.Pipe, fn)
+	case *parse.PipeNode:
+		for _, cmd := range n.Cmds {
+			walkNodes(cmd, fn)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			walkNodes(arg, fn)
+		}
+	case *parse.ChainNode:
+		walkNodes(n.Node, fn)
+	}
+}
+
+// nodeLine returns the line of node in the source of tree.
+func nodeLine(tree *parse.Tree, node parse.Node) int {
+	location, _ := tree.ErrorContext(node)
+	parts := strings.Split(location, ":")
+	if len(parts) < 2 {
+		return 0
+	}
+	line, _ := strconv.Atoi(parts[len(parts)-2])
+	return line
+}
+
+// deprecatedDirective matches the comment marking a template file as deprecated among its leading comments, e.g.
+// <!-- deprecated: use @card instead --> or {{/* deprecated */}}, with an optional message.
+var deprecatedDirective = regexp.MustCompile(`^(?:\s*(?:<!--.*?-->|{{-?\s*/\*.*?\*/\s*-?}}))*?\s*` +
+	`(?:<!--\s*deprecated(?:\s*:\s*(.*?))?\s*-->|{{-?\s*/\*\s*deprecated(?:\s*:\s*(.*?))?\s*\*/\s*-?}})`)
+
+// templateDeprecations are the deprecated templates of a template state.
+type templateDeprecations struct {
+	templates map[string]TemplateDeprecation // deprecated templates and views, keyed by name
+	files     map[string]TemplateDeprecation // template files marked deprecated by a comment
+	includes  []DeprecatedInclude            // includes of deprecated templates found at Init
+	logged    sync.Map                       // templates whose first deprecated render was logged
+}
+
+// newTemplateDeprecations returns empty template deprecations.
+func newTemplateDeprecations() *templateDeprecations {
+	return &templateDeprecations{
+		templates: make(map[string]TemplateDeprecation),
+		files:     make(map[string]TemplateDeprecation),
+	}
+}
+
+// TemplateDeprecation describes a deprecated template: a partial, a layout or a view.
+type TemplateDeprecation struct {
+	// Replacement is the template to use instead, if any.
+	Replacement string `json:"replacement,omitempty"`
+	// Message is an optional explanation, e.g. how to migrate the includes.
+	Message string `json:"message,omitempty"`
+}
+
+// hint returns the advice logged with the uses of the deprecated template.
+func (d TemplateDeprecation) hint() string {
+	return FuncDeprecation(d).hint()
+}
+
+// DeprecatedInclude is an include of a deprecated template, with the template action, renderComponent or
+// cachedTemplate.
+type DeprecatedInclude struct {
+	// Template is the name of the deprecated template.
+	Template string `json:"template"`
+	// Caller is the name of the template including it: the view for the templates of a view, e.g. views/home, and
+	// the template name for the templates of layouts and partials, e.g. @profile.
+	Caller string `json:"caller"`
+	// Path is the path of the template file including it.
+	Path string `json:"path"`
+	// Line is the line of the include in the file.
+	Line int `json:"line"`
+	// Deprecation describes the deprecation.
+	Deprecation TemplateDeprecation `json:"deprecation"`
+}
+
+func (i DeprecatedInclude) String() string {
+	s := fmt.Sprintf("%s:%d: %s includes deprecated template %s", i.Path, i.Line, i.Caller, i.Template)
+	if hint := i.Deprecation.hint(); hint != "" {
+		s += ": " + hint
+	}
+	return s
+}
+
+// DeprecatedIncludes returns the includes of deprecated templates found at Init, sorted by file and line.
+func (a *TemplateAdapter) DeprecatedIncludes() []DeprecatedInclude {
+	if !a.frozen.Load() {
+		a.initMu.RLock()
+		defer a.initMu.RUnlock()
+	}
+
+	return append([]DeprecatedInclude(nil), a.deprecated.includes...)
+}
+
+// DeprecatedTemplates returns the deprecated templates and views, marked with the DeprecatedTemplates option or a
+// deprecated comment, keyed by template or view name.
+func (a *TemplateAdapter) DeprecatedTemplates() map[string]TemplateDeprecation {
+	if !a.frozen.Load() {
+		a.initMu.RLock()
+		defer a.initMu.RUnlock()
+	}
+
+	return maps.Clone(a.deprecated.templates)
+}
+
+// templateDeprecation returns the deprecation declared by a deprecated comment of the template source, if any.
+func templateDeprecation(src string) (TemplateDeprecation, bool) {
+	match := deprecatedDirective.FindStringSubmatch(src)
+	if match == nil {
+		return TemplateDeprecation{}, false
+	}
+	return TemplateDeprecation{Message: match[1] + match[2]}, true
+}
+
+// markDeprecatedFile records the deprecation declared by the source of the template file, if any, for its templates.
+func (a *TemplateAdapter) markDeprecatedFile(file, src string) {
+	if deprecation, ok := templateDeprecation(src); ok {
+		a.deprecated.files[file] = deprecation
+	}
+}
+
+// markDeprecatedView records the deprecation declared by the view source, unless the DeprecatedTemplates option
+// deprecates the view already.
+func (a *TemplateAdapter) markDeprecatedView(pageName, src string) {
+	if _, ok := a.deprecated.templates[pageName]; ok {
+		return
+	}
+	if deprecation, ok := templateDeprecation(src); ok {
+		a.deprecated.templates[pageName] = deprecation
+	}
+}
+
+// resolveDeprecatedTemplates records the deprecated templates of the DeprecatedTemplates option and of the deprecated
+// layout and partial files, then the includes of deprecated templates by the layouts and partials. It must be called
+// once the common templates are loaded.
+func (a *TemplateAdapter) resolveDeprecatedTemplates() error {
+	for name, deprecation := range a.deprecations {
+		if _, viewPath, _ := cutFSID(name); strings.HasPrefix(viewPath, constants.ViewsDir+"/") {
+			name = a.normalizeName(name)
+		}
+		a.deprecated.templates[name] = deprecation
+	}
+	for name, file := range a.templateFiles {
+		if deprecation, ok := a.deprecated.files[file]; ok {
+			if _, set := a.deprecated.templates[name]; !set {
+				a.deprecated.templates[name] = deprecation
+			}
+		}
+	}
+	if !a.hasDeprecatedTemplates() {
+		return nil
+	}
+
+	for _, tmpl := range a.common.Templates() {
+		file := a.templateFiles[tmpl.Name()]
+		if file != "" && tmpl.Tree != nil {
+			a.findDeprecatedIncludes(tmpl.Tree, callerName(tmpl.Name(), file), file)
+		}
+	}
+
+	// Layouts extending another layout are compiled with each page, so their sources are parsed again
+	for _, layout := range a.layouts {
+		src, err := fs.ReadFile(layout.fsys, layout.path)
+		if err != nil {
+			return err
+		}
+		trees, err := parseSourceTrees(layout.path, string(src))
+		if err != nil {
+			return err
+		}
+		for _, tree := range trees {
+			a.findDeprecatedIncludes(tree, callerName(tree.Name, layout.file), layout.file)
+		}
+	}
+	return nil
+}
+
+// hasDeprecatedTemplates reports whether any template or view is deprecated.
+func (a *TemplateAdapter) hasDeprecatedTemplates() bool {
+	return len(a.deprecated.templates) > 0
+}
+
+// findViewIncludes records the includes of deprecated templates by the view source.
+func (a *TemplateAdapter) findViewIncludes(pageName, file, src string) error {
+	if !a.hasDeprecatedTemplates() {
+		return nil
+	}
+
+	_, filePath, _ := cutFSID(file)
+	trees, err := parseSourceTrees(filePath, src)
+	if err != nil {
+		return err
+	}
+	for _, tree := range trees {
+		a.findDeprecatedIncludes(tree, pageName, file)
+	}
+	return nil
+}
+
+// findDeprecatedIncludes records the includes of deprecated templates by the tree of the template file, but for the
+// includes of the templates the file defines itself.
+func (a *TemplateAdapter) findDeprecatedIncludes(tree *parse.Tree, caller, file string) {
+	walkTemplateRefs(tree, func(name string, node parse.Node) {
+		deprecation, ok := a.deprecated.templates[name]
+		if !ok || a.templateFiles[name] == file {
+			return
+		}
+		a.deprecated.includes = append(a.deprecated.includes, DeprecatedInclude{
+			Template:    name,
+			Caller:      caller,
+			Path:        file,
+			Line:        nodeLine(tree, node),
+			Deprecation: deprecation,
+		})
+	})
+}
+
+// callerName returns the name reported as the caller of the includes of a template of the file: the template name,
+// or the file for the templates hoisted out of component and cache blocks.
+func callerName(name, file string) string {
+	if strings.HasPrefix(name, "_component:") || strings.HasPrefix(name, cacheBlockPrefix) {
+		return file
+	}
+	return name
+}
+
+// walkTemplateRefs calls fn for each template the tree renders by a literal name: with the template action, and with
+// the renderComponent and cachedTemplate functions.
+func walkTemplateRefs(tree *parse.Tree, fn func(name string, node parse.Node)) {
+	walkNodes(tree.Root, func(node parse.Node) {
+		switch n := node.(type) {
+		case *parse.TemplateNode:
+			fn(n.Name, n)
+		case *parse.CommandNode:
+			if len(n.Args) == 0 {
+				return
+			}
+			ident, ok := n.Args[0].(*parse.IdentifierNode)
+			if !ok {
+				return
+			}
+			arg := map[string]int{"renderComponent": 4, "cachedTemplate": 3}[ident.Ident]
+			if arg == 0 || arg >= len(n.Args) {
+				return
+			}
+			if name, ok := n.Args[arg].(*parse.StringNode); ok {
+				fn(name.Text, n)
+			}
+		}
+	})
+}
+
+// logDeprecatedRender logs the first render of a deprecated template or view, and of each include of a deprecated
+// template by a rendered view, with the caller.
+func (a *TemplateAdapter) logDeprecatedRender(name string) {
+	if !a.hasDeprecatedTemplates() {
+		return
+	}
+	if _, logged := a.deprecated.logged.LoadOrStore(name, true); logged {
+		return
+	}
+
+	if deprecation, ok := a.deprecated.templates[name]; ok {
+		a.log().Warn("Deprecated template rendered", slog.String("template", name),
+			slog.String("hint", deprecation.hint()))
+	}
+	for _, include := range a.deprecated.includes {
+		if include.Caller == name {
+			a.log().Warn("Deprecated template included during render", slog.String("template", include.Template),
+				slog.String("caller", include.Caller), slog.String("call", include.Path+":"+strconv.Itoa(include.Line)),
+				slog.String("hint", include.Deprecation.hint()))
+		}
+	}
+}
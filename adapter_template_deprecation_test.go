@@ -0,0 +1,179 @@
+package hyperview_test
+
+import (
+	"bytes"
+	"io/fs"
+	"log/slog"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/response"
+)
+
+// newDeprecationTestAdapter returns an adapter rendering files with the base layout, which deprecates the oldDate_
+// function and the sidebar template.
+func newDeprecationTestAdapter(files map[string]string, failOnDeprecated bool, logs *bytes.Buffer) *hyperview.TemplateAdapter {
+	fsys := fstest.MapFS{
+		"layouts/base.html": {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+	}
+	for path, src := range files {
+		fsys[path] = &fstest.MapFile{Data: []byte(src)}
+	}
+
+	return hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: fsys},
+		Funcs: map[string]any{
+			"oldDate_": func(s string) string { return "old:" + s },
+			"newDate_": func(s string) string { return "new:" + s },
+		},
+		DeprecatedFuncs: map[string]hyperview.FuncDeprecation{
+			"oldDate_": {Replacement: "newDate_", Message: "it ignores the time zone"},
+		},
+		DeprecatedTemplates: map[string]hyperview.TemplateDeprecation{
+			"sidebar": {Replacement: "@nav"},
+		},
+		FailOnDeprecated: failOnDeprecated,
+		Logger:           slog.New(slog.NewTextHandler(logs, nil)),
+	})
+}
+
+// deprecatedFuncFiles call the deprecated oldDate_ function.
+var deprecatedFuncFiles = map[string]string{
+	"partials/date.html": `{{define "@date"}}{{oldDate_ .}}{{end}}`,
+	"views/home.html":    "{{define \"page:main\"}}\n{{oldDate_ \"x\"}}{{newDate_ \"y\"}}{{end}}",
+}
+
+// deprecatedTemplateFiles include templates deprecated by comments and by the sidebar deprecation.
+var deprecatedTemplateFiles = map[string]string{
+	"partials/legacy/card.html": "<!-- deprecated: use @card instead -->\n" +
+		`{{define "oldCard"}}old{{end}}{{define "oldBadge"}}{{template "oldCard"}}{{end}}`,
+	"partials/profile.html": "{{define \"@profile\"}}\n{{template \"oldCard\" .}}{{end}}",
+	"partials/sidebar.html": `{{define "sidebar"}}side{{end}}`,
+	"views/home.html": "{{define \"page:main\"}}{{template \"oldCard\" .}}\n" +
+		`{{template "sidebar" .}}{{template "@profile" .}}{{end}}`,
+	"views/legacy.html": "<!-- layout: base -->\n{{/* deprecated: gone */}}{{define \"page:main\"}}legacy{{end}}",
+}
+
+func TestTemplateAdapter_DeprecatedFuncs(t *testing.T) {
+	var logs bytes.Buffer
+	adapter := newDeprecationTestAdapter(deprecatedFuncFiles, false, &logs)
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+
+	calls := adapter.DeprecatedCalls()
+	want := []string{
+		"partials/date.html:1: oldDate_ is deprecated: use newDate_ instead; it ignores the time zone",
+		"views/home.html:2: oldDate_ is deprecated: use newDate_ instead; it ignores the time zone",
+	}
+	if len(calls) != len(want) {
+		t.Fatalf("expected %d call sites, got %v", len(want), calls)
+	}
+	for i, w := range want {
+		if calls[i].String() != w {
+			t.Errorf("unexpected call site %d:\ngot  %s\nwant %s", i, calls[i], w)
+		}
+	}
+	if !strings.Contains(logs.String(), "call=views/home.html:2") {
+		t.Errorf("expected the call sites to be logged at Init, got:\n%s", logs.String())
+	}
+
+	logs.Reset()
+	for range 2 {
+		w := renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("home"))
+		if got := w.Body.String(); got != "\nold:xnew:y" {
+			t.Errorf("expected deprecated functions to keep working, got %q", got)
+		}
+	}
+	if n := strings.Count(logs.String(), "Deprecated template function called during render"); n != 1 {
+		t.Errorf("expected the first call during a render to be logged once, got %d logs:\n%s", n, logs.String())
+	}
+}
+
+func TestTemplateAdapter_FailOnDeprecated(t *testing.T) {
+	var logs bytes.Buffer
+	err := newDeprecationTestAdapter(deprecatedFuncFiles, true, &logs).Init()
+	if err == nil || !strings.Contains(err.Error(), "views/home.html:2: oldDate_ is deprecated") {
+		t.Errorf("expected Init to fail with the call sites, got %v", err)
+	}
+}
+
+func TestTemplateAdapter_DeprecatedTemplates(t *testing.T) {
+	var logs bytes.Buffer
+	adapter := newDeprecationTestAdapter(deprecatedTemplateFiles, false, &logs)
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+
+	deprecated := adapter.DeprecatedTemplates()
+	for name, hint := range map[string]string{"oldCard": "use @card instead", "oldBadge": "use @card instead", "views/legacy": "gone"} {
+		if got := deprecated[name].Message; got != hint {
+			t.Errorf("expected %s to be deprecated with %q, got %q", name, hint, got)
+		}
+	}
+	if got := deprecated["sidebar"].Replacement; got != "@nav" {
+		t.Errorf("expected sidebar to be deprecated by the options, got %q", got)
+	}
+
+	// Includes within the deprecated file itself are not reported
+	includes := adapter.DeprecatedIncludes()
+	want := []string{
+		"partials/profile.html:2: @profile includes deprecated template oldCard: use @card instead",
+		"views/home.html:1: views/home includes deprecated template oldCard: use @card instead",
+		"views/home.html:2: views/home includes deprecated template sidebar: use @nav instead",
+	}
+	if len(includes) != len(want) {
+		t.Fatalf("expected %d includes, got %v", len(want), includes)
+	}
+	for i, w := range want {
+		if includes[i].String() != w {
+			t.Errorf("unexpected include %d:\ngot  %s\nwant %s", i, includes[i], w)
+		}
+	}
+	if !strings.Contains(logs.String(), "caller=@profile call=partials/profile.html:2") {
+		t.Errorf("expected the includes to be logged at Init, got:\n%s", logs.String())
+	}
+
+	logs.Reset()
+	for range 2 {
+		if w := renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("home")); w.Body.String() != "old\nside\nold" {
+			t.Errorf("expected deprecated templates to keep working, got %q", w.Body.String())
+		}
+		renderTestTemplate(t, adapter, response.NewResponse().Path("legacy"))
+	}
+	if n := strings.Count(logs.String(), `msg="Deprecated template included during render"`); n != 2 {
+		t.Errorf("expected the includes of the view to be logged once, got %d logs:\n%s", n, logs.String())
+	}
+	if n := strings.Count(logs.String(), `msg="Deprecated template rendered" template=views/legacy`); n != 1 {
+		t.Errorf("expected the deprecated view to be logged once, got %d logs:\n%s", n, logs.String())
+	}
+
+	inventory, err := adapter.Inventory()
+	if err != nil {
+		t.Fatalf("error getting inventory: %v", err)
+	}
+	if got := len(inventory.DeprecatedIncludes); got != len(want) {
+		t.Errorf("expected the includes in the inventory, got %d", got)
+	}
+	for _, partial := range inventory.FileSystems[0].Partials {
+		if partial.File == "partials/legacy/card.html" && strings.Join(partial.Deprecated, ",") != "legacy/card,oldBadge,oldCard" {
+			t.Errorf("unexpected deprecated templates of %s: %v", partial.File, partial.Deprecated)
+		}
+	}
+	for _, view := range inventory.FileSystems[0].Views {
+		if (view.Deprecated != nil) != (view.Name == "views/legacy") {
+			t.Errorf("unexpected deprecation of %s: %v", view.Name, view.Deprecated)
+		}
+	}
+}
+
+func TestTemplateAdapter_FailOnDeprecatedTemplates(t *testing.T) {
+	var logs bytes.Buffer
+	err := newDeprecationTestAdapter(deprecatedTemplateFiles, true, &logs).Init()
+	if err == nil || !strings.Contains(err.Error(), "views/home.html:2: views/home includes deprecated template sidebar") {
+		t.Errorf("expected Init to fail with the includes, got %v", err)
+	}
+}
@@ -0,0 +1,321 @@
+package hyperview
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"html/template"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"text/template/parse"
+	"time"
+
+	"github.com/hypergopher/hyperview/constants"
+)
+
+// DependencyGraph maps each view to the template files it depends on: its own file, the layouts it can be rendered
+// with, and the partials it renders, directly, through other partials or through the layouts. Files are named like
+// views, by their path prefixed with the ID of their file system unless it is the root file system, e.g.
+// partials/card.html or acme:partials/card.html.
+type DependencyGraph map[string][]string
+
+// Dependents returns the views depending on the template file, sorted.
+func (g DependencyGraph) Dependents(file string) []string {
+	var views []string
+	for view, files := range g {
+		for _, f := range files {
+			if f == file {
+				views = append(views, view)
+				break
+			}
+		}
+	}
+	sort.Strings(views)
+	return views
+}
+
+// Dependencies returns the dependency graph of the views, for tools such as editors and build scripts. The views are
+// read and parsed again to compute the graph.
+func (a *TemplateAdapter) Dependencies() (DependencyGraph, error) {
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+
+	return a.dependencies()
+}
+
+// dependencies returns the dependency graph of the views. The caller holds reloadMu.
+func (a *TemplateAdapter) dependencies() (DependencyGraph, error) {
+	var layouts []string
+	for file := range a.sources {
+		if isLayoutFile(file) {
+			layouts = append(layouts, file)
+		}
+	}
+
+	graph := make(DependencyGraph, len(a.pages))
+	for name, page := range a.pages {
+		if name == contentPage {
+			continue
+		}
+
+		src, err := fs.ReadFile(page.fsys, page.path)
+		if err != nil {
+			return nil, err
+		}
+		files, err := a.pageFiles(page.path, string(src))
+		if err != nil {
+			return nil, err
+		}
+
+		files[templateFileKey(pageFSID(name), page.path)] = true
+		for _, layout := range layouts {
+			files[layout] = true
+		}
+
+		deps := make([]string, 0, len(files))
+		for file := range files {
+			deps = append(deps, file)
+		}
+		sort.Strings(deps)
+		graph[name] = deps
+	}
+
+	return graph, nil
+}
+
+// Reload reloads the templates like Init, but only recompiles the pages affected by the changes since the last Init
+// or Reload: the pages whose files changed, and the pages rendering partials whose files changed or were added. Pages
+// compiled on first use are recompiled on their next render. Everything is recompiled if a layout was added, removed
+// or changed, or if partials were removed or define templates of other files. Like Init, Reload is safe to call
+// concurrently with renders, and keeps the previous templates if it fails.
+func (a *TemplateAdapter) Reload() error {
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+
+	if a.frozen.Load() {
+		panic("hyperview: Reload called on a frozen TemplateAdapter")
+	}
+
+	return a.reload(a.templateConfig)
+}
+
+// reload rebuilds the templates with the configuration, reusing the pages of the current state unaffected by the
+// changes, and swaps them in along with the file systems and functions of the configuration. The caller holds
+// reloadMu.
+func (a *TemplateAdapter) reload(config templateConfig) error {
+	start := time.Now()
+	builder := &TemplateAdapter{templateConfig: config}
+	reuse, err := builder.build(&a.templateState)
+	if err != nil {
+		return err
+	}
+
+	a.initMu.Lock()
+	a.mu.Lock()
+	if reuse == nil {
+		a.gc.reset()
+	} else {
+		// Keep the template sets compiled on first use for the pages reused, including those compiled since the build
+		var evicted []string
+		for key, tmpl := range a.layered {
+			if reuse.kept[strings.Split(key, "|")[1]] {
+				builder.layered[key] = tmpl
+			} else {
+				evicted = append(evicted, key)
+			}
+		}
+		a.gc.forget(evicted...)
+	}
+	a.templateState = builder.templateState
+	a.setMounts(config)
+	a.mu.Unlock()
+	a.initMu.Unlock()
+
+	a.logTemplatesLoaded("reload", start)
+	return nil
+}
+
+// pageReuse reuses the pages of the previous state unaffected by a reload.
+type pageReuse struct {
+	previous *templateState
+	changed  map[string]bool // partial files whose source changed
+	kept     map[string]bool // pages reused
+}
+
+// newPageReuse returns the reuse of the pages of the previous state, or nil if there is no previous state or nothing
+// can be reused: layouts were added, removed or changed, partials were removed, or they define templates of other
+// files. Partials added are treated as changed, so the pages rendering the templates they define, which were missing,
+// are recompiled. It must be called once the common templates are loaded.
+func (a *TemplateAdapter) newPageReuse(previous *templateState) *pageReuse {
+	if previous == nil || previous.lazyPages != a.lazyPages {
+		return nil
+	}
+	for name := range previous.templateFiles {
+		if _, ok := a.templateFiles[name]; !ok {
+			return nil
+		}
+	}
+	for name, file := range a.templateFiles {
+		if defining, ok := previous.templateFiles[name]; ok && defining != file {
+			return nil
+		}
+	}
+
+	reuse := &pageReuse{previous: previous, changed: make(map[string]bool), kept: map[string]bool{contentPage: true}}
+	for file, hash := range previous.sources {
+		if !isLayoutFile(file) && !isPartialFile(file) {
+			continue
+		}
+		switch current, ok := a.sources[file]; {
+		case !ok, current != hash && isLayoutFile(file):
+			return nil
+		case current != hash:
+			reuse.changed[file] = true
+		}
+	}
+	// The sources hold the common template files only, as the views are not loaded yet
+	for file := range a.sources {
+		if _, ok := previous.sources[file]; ok {
+			continue
+		}
+		if isLayoutFile(file) {
+			return nil
+		}
+		reuse.changed[file] = true
+	}
+
+	return reuse
+}
+
+// page returns the template set of the page in the previous state, if the page can be reused: its source is
+// unchanged, and it renders none of the partials that changed. The template set is nil for pages compiled on first
+// use.
+func (r *pageReuse) page(a *TemplateAdapter, pageName, file, src string) (*template.Template, bool, error) {
+	if r == nil || r.previous.sources[file] != sourceHash([]byte(src)) {
+		return nil, false, nil
+	}
+
+	tmpl, compiled := r.previous.templates[pageName]
+	if !compiled && !a.lazyPages {
+		return nil, false, nil
+	}
+
+	if len(r.changed) > 0 {
+		_, filePath, _ := cutFSID(file)
+		files, err := a.pageFiles(filePath, src)
+		if err != nil {
+			return nil, false, err
+		}
+		for changed := range r.changed {
+			if files[changed] {
+				return nil, false, nil
+			}
+		}
+	}
+
+	r.kept[pageName] = true
+	return tmpl, true, nil
+}
+
+// pageFiles returns the files of the common templates that the page source can render, directly, through other
+// common templates or through the root layouts.
+func (a *TemplateAdapter) pageFiles(filePath, src string) (map[string]bool, error) {
+	trees, err := parseSourceTrees(filePath, src)
+	if err != nil {
+		return nil, err
+	}
+
+	var refs []string
+	dynamic := false
+	for _, tree := range trees {
+		deps := treeDeps(tree)
+		dynamic = dynamic || deps.dynamic
+		refs = append(refs, deps.refs...)
+	}
+
+	reached, all := a.reachableTemplates(refs, dynamic)
+	files := make(map[string]bool)
+	for name, file := range a.templateFiles {
+		if all || reached[name] {
+			files[file] = true
+		}
+	}
+	return files, nil
+}
+
+// parseSourceTrees parses the template source with the source transformations of the adapter, without checking the
+// functions it calls, and returns the trees of the templates it defines.
+func parseSourceTrees(filePath, src string) (map[string]*parse.Tree, error) {
+	src, err := preprocessTemplate(src, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	trees := make(map[string]*parse.Tree)
+	tree := parse.New(path.Base(filePath))
+	tree.Mode = parse.SkipFuncCheck
+	if _, err := tree.Parse(src, "", "", trees); err != nil {
+		return nil, err
+	}
+	return trees, nil
+}
+
+// definedTrees returns the parse tree of each template of the set, to find the templates a file defines.
+func definedTrees(t *template.Template) map[string]*parse.Tree {
+	trees := make(map[string]*parse.Tree)
+	for _, tmpl := range t.Templates() {
+		trees[tmpl.Name()] = tmpl.Tree
+	}
+	return trees
+}
+
+// recordDefinitions records the file as the definition of the templates of the set it defined or redefined, given the
+// trees of the set before the file was parsed.
+func (a *TemplateAdapter) recordDefinitions(t *template.Template, before map[string]*parse.Tree, file string) {
+	for _, tmpl := range t.Templates() {
+		if tree, ok := before[tmpl.Name()]; !ok || tree != tmpl.Tree {
+			a.templateFiles[tmpl.Name()] = file
+		}
+	}
+}
+
+// templateFileKey names the template file at the path of a file system like views are named.
+func templateFileKey(fsID, filePath string) string {
+	if fsID == constants.RootFSID {
+		return filePath
+	}
+	return fsID + ":" + filePath
+}
+
+// cutFSID splits a view or template file name into the ID of its file system and its path.
+func cutFSID(name string) (string, string, bool) {
+	if fsID, rest, found := strings.Cut(name, ":"); found {
+		return fsID, rest, true
+	}
+	return constants.RootFSID, name, false
+}
+
+// pageFSID returns the ID of the file system of the page.
+func pageFSID(pageName string) string {
+	fsID, _, _ := cutFSID(pageName)
+	return fsID
+}
+
+// isLayoutFile reports whether the template file is a layout.
+func isLayoutFile(file string) bool {
+	_, filePath, _ := cutFSID(file)
+	return strings.HasPrefix(filePath, constants.LayoutsDir+"/")
+}
+
+// isPartialFile reports whether the template file is a partial.
+func isPartialFile(file string) bool {
+	_, filePath, _ := cutFSID(file)
+	return strings.HasPrefix(filePath, constants.PartialsDir+"/")
+}
+
+// sourceHash returns the hash of a template source.
+func sourceHash(src []byte) string {
+	sum := sha256.Sum256(src)
+	return hex.EncodeToString(sum[:])
+}
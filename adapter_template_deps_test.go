@@ -0,0 +1,110 @@
+package hyperview_test
+
+import (
+	"io/fs"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func depsTestFiles() fstest.MapFS {
+	return fstest.MapFS{
+		"layouts/base.html":    {Data: []byte(`{{define "layout:base"}}<main>{{template "page:main" .}}</main>{{template "nav"}}{{end}}`)},
+		"partials/nav.html":    {Data: []byte(`{{define "nav"}}<nav></nav>{{end}}`)},
+		"partials/card.html":   {Data: []byte(`{{define "@card"}}<div>{{.Slot "default"}}</div>{{end}}`)},
+		"partials/list.html":   {Data: []byte(`{{define "list"}}{{component "@card"}}item{{end}}{{end}}`)},
+		"partials/footer.html": {Data: []byte(`{{define "footer"}}footer{{end}}`)},
+		"views/home.html":      {Data: []byte(`{{define "page:main"}}{{template "list"}}{{end}}`)},
+		"views/about.html":     {Data: []byte(`{{define "page:main"}}{{template "footer"}}{{end}}`)},
+	}
+}
+
+func TestTemplateAdapter_Dependencies(t *testing.T) {
+	adapter := newTestTemplateAdapter(t, depsTestFiles(), hyperview.TemplateViewAdapterOptions{})
+
+	graph, err := adapter.Dependencies()
+	if err != nil {
+		t.Fatalf("error computing the dependencies: %v", err)
+	}
+
+	want := []string{"layouts/base.html", "partials/card.html", "partials/list.html", "partials/nav.html", "views/home.html"}
+	if got := graph["views/home"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected dependencies of views/home: %v", got)
+	}
+
+	tests := []struct {
+		file string
+		want []string
+	}{
+		{file: "partials/card.html", want: []string{"views/home"}},
+		{file: "partials/footer.html", want: []string{"views/about"}},
+		{file: "partials/nav.html", want: []string{"views/about", "views/home"}},
+		{file: "layouts/base.html", want: []string{"views/about", "views/home"}},
+	}
+	for _, tt := range tests {
+		if got := graph.Dependents(tt.file); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Dependents(%s): expected %v, got %v", tt.file, tt.want, got)
+		}
+	}
+}
+
+func TestTemplateAdapter_Reload(t *testing.T) {
+	tests := []struct {
+		name    string
+		changes map[string]string
+		kept    []string // pages whose template sets are kept
+		want    string   // body of views/about after the reload
+	}{
+		{name: "no changes", kept: []string{"views/about", "views/home"}, want: "<main>footer</main><nav></nav>"},
+		{name: "partial", changes: map[string]string{"partials/footer.html": `{{define "footer"}}new footer{{end}}`},
+			kept: []string{"views/home"}, want: "<main>new footer</main><nav></nav>"},
+		{name: "view", changes: map[string]string{"views/home.html": `{{define "page:main"}}home{{end}}`},
+			kept: []string{"views/about"}, want: "<main>footer</main><nav></nav>"},
+		{name: "partial rendered by the layout", changes: map[string]string{"partials/nav.html": `{{define "nav"}}<nav>new</nav>{{end}}`},
+			want: "<main>footer</main><nav>new</nav>"},
+		{name: "layout", changes: map[string]string{"layouts/base.html": `{{define "layout:base"}}{{template "page:main" .}}{{end}}`},
+			want: "footer"},
+		{name: "new partial", changes: map[string]string{"partials/header.html": `{{define "header"}}{{end}}`},
+			kept: []string{"views/about", "views/home"}, want: "<main>footer</main><nav></nav>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := depsTestFiles()
+			adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+				FileSystemMap: map[string]fs.FS{constants.RootFSID: files},
+				LazyCompile:   true,
+			})
+			if err := adapter.Init(); err != nil {
+				t.Fatalf("error initializing adapter: %v", err)
+			}
+			for _, view := range []string{"views/home", "views/about"} {
+				renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path(view))
+			}
+
+			for path, src := range tt.changes {
+				files[path] = &fstest.MapFile{Data: []byte(src)}
+			}
+			if err := adapter.Reload(); err != nil {
+				t.Fatalf("error reloading adapter: %v", err)
+			}
+
+			var kept []string
+			for _, set := range adapter.TemplateStats().Sets {
+				kept = append(kept, set.Page)
+			}
+			if !reflect.DeepEqual(kept, tt.kept) {
+				t.Errorf("expected the template sets of %v to be kept, got %v", tt.kept, kept)
+			}
+
+			w := renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("views/about"))
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
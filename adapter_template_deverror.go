@@ -0,0 +1,236 @@
+package hyperview
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/hypergopher/hyperview/response"
+)
+
+// errorLocation matches the location text/template reports in execution errors, e.g.
+// `template: home.html:3:12: executing "page:main" at <.User.Name>: ...`.
+var errorLocation = regexp.MustCompile(`^template: (.+?):(\d+)(?::(\d+))?: executing "(.+?)"`)
+
+// sourceContext is the number of lines shown around the failing line on the development error page.
+const sourceContext = 5
+
+// devErrorLocation is a template location of a failed render.
+type devErrorLocation struct {
+	File     string
+	Template string
+	Line     int
+	Column   int
+	Source   []devErrorLine
+}
+
+// devErrorLine is a line of the source shown around the failing line.
+type devErrorLine struct {
+	Number  int
+	Text    string
+	Current bool
+}
+
+// devErrorFrame is a template executing when a render failed.
+type devErrorFrame struct {
+	Name string
+	File string
+}
+
+// devErrorValue is a value of the view data, pretty-printed.
+type devErrorValue struct {
+	Key   string
+	Value string
+}
+
+// handleRenderError answers a failed render of the page with the development error page if it is enabled, or with
+// the terse error page otherwise. The partial body rendered before the failure, if any, tells the templates executing
+// when the render failed.
+func (a *TemplateAdapter) handleRenderError(w http.ResponseWriter, r *http.Request, resp *response.Response, pageName string, err error, partial []byte, data map[string]any) {
+	if !a.devErrorPage {
+		a.handleError(w, r, err)
+		return
+	}
+
+	var stack []devErrorFrame
+	if a.debug != nil {
+		for _, tmpl := range a.debug.openTemplates(partial) {
+			stack = append(stack, devErrorFrame{Name: tmpl.name, File: tmpl.file})
+		}
+	}
+
+	var buf bytes.Buffer
+	if execErr := devErrorPageTemplate.Execute(&buf, map[string]any{
+		"Page":      pageName,
+		"Layout":    resp.TemplateLayout(),
+		"Error":     err.Error(),
+		"Locations": a.errorLocations(pageName, err),
+		"Stack":     stack,
+		"Data":      dumpViewData(data),
+	}); execErr != nil {
+		a.handleError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusInternalServerError)
+	_, _ = w.Write(buf.Bytes())
+}
+
+// errorLocations returns the template locations reported by the execution errors wrapped by err, the innermost first,
+// with the source around them. Errors of templates rendered by functions, such as components, wrap the error of the
+// template calling the function.
+func (a *TemplateAdapter) errorLocations(pageName string, err error) []devErrorLocation {
+	if !a.frozen.Load() {
+		a.initMu.RLock()
+		defer a.initMu.RUnlock()
+	}
+
+	var locations []devErrorLocation
+	for err != nil {
+		var execErr texttemplate.ExecError
+		if !errors.As(err, &execErr) {
+			break
+		}
+
+		if match := errorLocation.FindStringSubmatch(execErr.Err.Error()); match != nil {
+			location := devErrorLocation{Template: match[4]}
+			location.Line, _ = strconv.Atoi(match[2])
+			location.Column, _ = strconv.Atoi(match[3])
+			location.File = match[1]
+			if file, src, ok := a.errorSource(pageName, match[1], match[4]); ok {
+				location.File = file
+				location.Source = sourceLines(src, location.Line)
+			}
+			locations = append([]devErrorLocation{location}, locations...)
+		}
+		err = errors.Unwrap(execErr.Err)
+	}
+	return locations
+}
+
+// errorSource returns the file and source of the template named in an execution error, given the name the file was
+// parsed under: the base name of views and layouts, or the partial name of partials. The source is preprocessed like
+// at parse time, so the lines match the error.
+func (a *TemplateAdapter) errorSource(pageName, parseName, templateName string) (string, string, bool) {
+	type candidate struct {
+		file string
+		fsys fs.FS
+		path string
+	}
+
+	var candidates []candidate
+	if page, ok := a.pages[pageName]; ok && path.Base(page.path) == parseName {
+		candidates = append(candidates, candidate{templateFileKey(pageFSID(pageName), page.path), page.fsys, page.path})
+	}
+	files := make([]string, 0, len(a.sources))
+	for file := range a.sources {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	if file, ok := a.templateFiles[templateName]; ok {
+		files = append([]string{file}, files...)
+	}
+	for _, file := range files {
+		fsID, filePath, _ := cutFSID(file)
+		if path.Base(filePath) == parseName || partialName(filePath, a.extension) == parseName {
+			candidates = append(candidates, candidate{file, a.loadedFS[fsID], filePath})
+		}
+	}
+
+	for _, c := range candidates {
+		if c.fsys == nil {
+			continue
+		}
+		src, err := fs.ReadFile(c.fsys, c.path)
+		if err != nil {
+			continue
+		}
+		processed, err := preprocessTemplate(string(src), c.file)
+		if err != nil {
+			continue
+		}
+		return c.file, processed, true
+	}
+	return "", "", false
+}
+
+// sourceLines returns the lines of the source around the line.
+func sourceLines(src string, line int) []devErrorLine {
+	lines := strings.Split(src, "\n")
+	first, last := max(line-sourceContext, 1), min(line+sourceContext, len(lines))
+
+	excerpt := make([]devErrorLine, 0, last-first+1)
+	for n := first; n <= last; n++ {
+		excerpt = append(excerpt, devErrorLine{Number: n, Text: lines[n-1], Current: n == line})
+	}
+	return excerpt
+}
+
+// dumpViewData pretty-prints the values of the view data, sorted by key, as JSON when possible. The View value, which
+// holds the view data itself, is left out.
+func dumpViewData(data map[string]any) []devErrorValue {
+	values := make([]devErrorValue, 0, len(data))
+	for key, value := range data {
+		if key == "View" {
+			continue
+		}
+
+		dump := fmt.Sprintf("%#v", value)
+		if b, err := json.MarshalIndent(value, "", "  "); err == nil {
+			dump = string(b)
+		}
+		values = append(values, devErrorValue{Key: key, Value: dump})
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i].Key < values[j].Key })
+	return values
+}
+
+var devErrorPageTemplate = template.Must(template.New("error").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>Render failed: {{.Page}}</title>
+<style>
+body{margin:2em;font:14px/1.5 system-ui,sans-serif;color:#222}
+pre{padding:.5em 1em;overflow:auto;background:#f6f6f6;font:13px/1.5 monospace}
+.current{background:#fdd;font-weight:bold}
+.error{padding:1em;background:#fee;border-left:4px solid #c00;white-space:pre-wrap}
+</style></head>
+<body>
+<h1>Render failed: {{.Page}}{{with .Layout}} <small>with layout {{.}}</small>{{end}}</h1>
+<pre class="error">{{.Error}}</pre>
+{{- range .Locations}}
+<h2>{{.File}}:{{.Line}}{{if .Column}}:{{.Column}}{{end}} <small>in {{.Template}}</small></h2>
+{{- if .Source}}
+<pre>
+{{- range .Source}}
+<span{{if .Current}} class="current"{{end}}>{{printf "%4d" .Number}} | {{.Text}}</span>
+{{- end}}
+</pre>
+{{- end}}
+{{- end}}
+{{- if .Stack}}
+<h2>Templates executing</h2>
+<ol>
+{{- range .Stack}}
+<li>{{.Name}} <small>{{.File}}</small></li>
+{{- end}}
+</ol>
+{{- end}}
+<h2>View data</h2>
+{{- range .Data}}
+<h3>{{.Key}}</h3>
+<pre>{{.Value}}</pre>
+{{- else}}
+<p>None</p>
+{{- end}}
+</body></html>
+`))
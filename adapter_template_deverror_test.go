@@ -0,0 +1,103 @@
+package hyperview_test
+
+import (
+	"io/fs"
+	"net/http"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func devErrorTestAdapter(t *testing.T, devErrorPage bool) *hyperview.TemplateAdapter {
+	t.Helper()
+
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{
+			"layouts/base.html": {Data: []byte(`{{define "layout:base"}}<body>{{template "page:main" .}}</body>{{end}}`)},
+			"partials/card.html": {Data: []byte(`{{define "card"}}
+<div class="card">
+  <h2>{{.Title}}</h2>
+  {{index .Tags 5}}
+</div>
+{{end}}`)},
+			"views/home.html": {Data: []byte(`{{define "page:main"}}<main>{{template "card" .Card}}</main>{{end}}`)},
+		}},
+		DevErrorPage: devErrorPage,
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+	return adapter
+}
+
+func TestTemplateAdapter_DevErrorPage(t *testing.T) {
+	adapter := devErrorTestAdapter(t, true)
+	card := map[string]any{"Title": "Hello", "Tags": []string{"a"}}
+
+	w := renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("views/home").Data(map[string]any{"Card": card}))
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status 500, got %d", w.Code)
+	}
+
+	body := w.Body.String()
+	for _, want := range []string{
+		`<h1>Render failed: views/home <small>with layout base</small></h1>`,
+		`<h2>partials/card.html:4:4 <small>in card</small></h2>`,
+		`<span>   3 |   &lt;h2&gt;{{.Title}}&lt;/h2&gt;</span>`,
+		`<span class="current">   4 |   {{index .Tags 5}}</span>`,
+		`<li>layout:base <small>layouts/base.html</small></li>`,
+		`<li>page:main <small>views/home.html</small></li>`,
+		`<li>card <small>partials/card.html</small></li>`,
+		`<h3>Card</h3>`,
+		`&#34;Title&#34;: &#34;Hello&#34;`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected the error page to contain %s, got:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "hvdebug") {
+		t.Errorf("expected the boundaries to be removed, got:\n%s", body)
+	}
+}
+
+func TestTemplateAdapter_DevErrorPageNotFound(t *testing.T) {
+	adapter := devErrorTestAdapter(t, true)
+
+	w := renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("views/missing"))
+	if body := w.Body.String(); w.Code != http.StatusInternalServerError || !strings.Contains(body, "template not found: views/missing") ||
+		!strings.Contains(body, "<h1>Render failed: views/missing") {
+		t.Errorf("expected the error page of the missing view, got %d:\n%s", w.Code, body)
+	}
+}
+
+func TestTemplateAdapter_DevErrorPageDisabled(t *testing.T) {
+	tests := []struct {
+		name         string
+		devErrorPage bool
+		path         string
+	}{
+		{name: "failed render", path: "views/home"},
+		{name: "successful render with the error page enabled", devErrorPage: true, path: "views/home"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := devErrorTestAdapter(t, tt.devErrorPage)
+			tags := []string{"a"}
+			if tt.devErrorPage {
+				tags = []string{"a", "b", "c", "d", "e", "f"}
+			}
+			w := renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path(tt.path).
+				Data(map[string]any{"Card": map[string]any{"Title": "Hello", "Tags": tags}}))
+
+			body := w.Body.String()
+			if strings.Contains(body, "Render failed") || strings.Contains(body, "hvdebug") {
+				t.Errorf("expected no error page, got:\n%s", body)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package hyperview
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/hypergopher/hyperview/response"
+)
+
+// conditionalRender sets a strong ETag of the rendered body on the response and reports whether the request's
+// If-None-Match header matches it, in which case the body must not be sent. ETags are only computed for successful
+// GET and HEAD requests that have not opted out with Response.NoETag. An ETag set on the response by the handler is
+// kept and used for the comparison. The ETags of bodies sent with a content coding end with the coding, as each
+// encoding is a distinct representation.
+func conditionalRender(w http.ResponseWriter, r *http.Request, resp *response.Response, body []byte, encoding string) bool {
+	if resp.ETagDisabled() || resp.StatusCode() != http.StatusOK {
+		return false
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		etag = bodyETag(body)
+		if encoding != "" {
+			etag = strings.TrimSuffix(etag, `"`) + "-" + encoding + `"`
+		}
+		w.Header().Set("ETag", etag)
+	}
+
+	return etagMatches(r.Header.Get("If-None-Match"), etag)
+}
+
+// bodyETag returns a strong ETag of the body.
+func bodyETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches reports whether the If-None-Match header value matches the ETag, using the weak comparison required
+// for If-None-Match.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
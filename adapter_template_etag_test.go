@@ -0,0 +1,62 @@
+package hyperview_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func TestTemplateAdapter_ETag(t *testing.T) {
+	adapter := newTestTemplateAdapter(t, fstest.MapFS{
+		"layouts/base.html": {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+		"views/home.html":   {Data: []byte(`{{define "page:main"}}home{{end}}`)},
+	}, hyperview.TemplateViewAdapterOptions{})
+
+	render := func(method, ifNoneMatch string, resp *response.Response) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/", nil)
+		if ifNoneMatch != "" {
+			r.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		adapter.Render(w, r, resp.Layout("base").Path("home"))
+		return w
+	}
+
+	etag := render(http.MethodGet, "", response.NewResponse()).Header().Get("ETag")
+	if len(etag) != 34 || etag[0] != '"' {
+		t.Fatalf("expected a strong ETag, got %q", etag)
+	}
+
+	tests := []struct {
+		name        string
+		method      string
+		ifNoneMatch string
+		resp        *response.Response
+		wantStatus  int
+		wantBody    string
+		wantETag    string
+	}{
+		{"no condition", http.MethodGet, "", response.NewResponse(), http.StatusOK, "home", etag},
+		{"match", http.MethodGet, etag, response.NewResponse(), http.StatusNotModified, "", etag},
+		{"weak match in list", http.MethodGet, `"other", W/` + etag, response.NewResponse(), http.StatusNotModified, "", etag},
+		{"wildcard", http.MethodHead, "*", response.NewResponse(), http.StatusNotModified, "", etag},
+		{"no match", http.MethodGet, `"other"`, response.NewResponse(), http.StatusOK, "home", etag},
+		{"post", http.MethodPost, etag, response.NewResponse(), http.StatusOK, "home", ""},
+		{"error status", http.MethodGet, etag, response.NewResponse().StatusNotFound(), http.StatusNotFound, "home", ""},
+		{"opt out", http.MethodGet, etag, response.NewResponse().NoETag(), http.StatusOK, "home", ""},
+		{"handler etag", http.MethodGet, `"v1"`, response.NewResponse().Header("ETag", `"v1"`), http.StatusNotModified, "", `"v1"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := render(tt.method, tt.ifNoneMatch, tt.resp)
+			if w.Code != tt.wantStatus || w.Body.String() != tt.wantBody || w.Header().Get("ETag") != tt.wantETag {
+				t.Errorf("got %d %q with ETag %q, want %d %q with ETag %q",
+					w.Code, w.Body.String(), w.Header().Get("ETag"), tt.wantStatus, tt.wantBody, tt.wantETag)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package hyperview
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/hypergopher/hyperview/response"
+)
+
+// FlagProvider evaluates feature flags, such as a flag service client or a static configuration.
+type FlagProvider interface {
+	// Enabled reports whether the flag is enabled for the request of ctx, e.g. for its user. Unknown flags are
+	// disabled.
+	Enabled(ctx context.Context, flag string) bool
+}
+
+// FlagProviderFunc is a function implementing FlagProvider.
+type FlagProviderFunc func(ctx context.Context, flag string) bool
+
+// Enabled calls f(ctx, flag).
+func (f FlagProviderFunc) Enabled(ctx context.Context, flag string) bool {
+	return f(ctx, flag)
+}
+
+// StaticFlags is a FlagProvider of flags enabled for every request, keyed by name, useful in development and tests.
+type StaticFlags map[string]bool
+
+// Enabled reports whether the flag is enabled.
+func (f StaticFlags) Enabled(_ context.Context, flag string) bool {
+	return f[flag]
+}
+
+// featureFunc returns the feature function of a render, evaluating the flags with provider for the request. Each flag
+// is evaluated once per render and recorded on the response, so a page renders consistently with the state reported
+// to the render hook, and flags recorded by the handler take precedence. With a nil provider, it is the placeholder
+// the templates are parsed with.
+func featureFunc(r *http.Request, resp *response.Response, provider FlagProvider) func(string) bool {
+	return func(flag string) bool {
+		if provider == nil {
+			return false
+		}
+		if enabled, ok := resp.Flags()[flag]; ok {
+			return enabled
+		}
+
+		enabled := provider.Enabled(r.Context(), flag)
+		resp.Flag(flag, enabled)
+		return enabled
+	}
+}
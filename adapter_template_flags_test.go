@@ -0,0 +1,89 @@
+package hyperview_test
+
+import (
+	"context"
+	"io/fs"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func TestTemplateAdapter_Flags(t *testing.T) {
+	var (
+		evaluations int
+		event       hyperview.RenderEvent
+	)
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{
+			"layouts/base.html": {Data: []byte(`{{define "layout:base"}}{{if feature "new-nav"}}new{{else}}old{{end}}|` +
+				`{{template "page:main" .}}{{end}}`)},
+			"views/home.html": {Data: []byte(`{{define "page:main"}}{{if feature "new-nav"}}nav{{end}}` +
+				`{{if feature "banner"}}banner{{end}}{{end}}`)},
+		}},
+		Flags: hyperview.FlagProviderFunc(func(_ context.Context, flag string) bool {
+			evaluations++
+			return flag == "new-nav"
+		}),
+		OnRender: func(_ *http.Request, e hyperview.RenderEvent) { event = e },
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+
+	tests := []struct {
+		name            string
+		resp            *response.Response
+		want            string
+		wantFlags       map[string]bool
+		wantEvaluations int
+	}{
+		{
+			name:            "flags of the provider",
+			resp:            response.NewResponse(),
+			want:            "new|nav",
+			wantFlags:       map[string]bool{"new-nav": true, "banner": false},
+			wantEvaluations: 2,
+		},
+		{
+			name:            "flags recorded by the handler",
+			resp:            response.NewResponse().Flag("new-nav", false).Flag("banner", true),
+			want:            "old|banner",
+			wantFlags:       map[string]bool{"new-nav": false, "banner": true},
+			wantEvaluations: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evaluations = 0
+			w := renderTestTemplate(t, adapter, tt.resp.Layout("base").Path("home"))
+
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("expected body %q, got %q", tt.want, got)
+			}
+			if !reflect.DeepEqual(event.Flags, tt.wantFlags) {
+				t.Errorf("expected the render hook to report flags %v, got %v", tt.wantFlags, event.Flags)
+			}
+			if evaluations != tt.wantEvaluations {
+				t.Errorf("expected %d evaluations, once per flag, got %d", tt.wantEvaluations, evaluations)
+			}
+		})
+	}
+}
+
+func TestTemplateAdapter_FlagsWithoutProvider(t *testing.T) {
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{
+			"views/home.html": {Data: []byte(`{{define "page:main"}}{{if feature "new-nav"}}nav{{end}}{{end}}`)},
+		}},
+	})
+	if err := adapter.Init(); err == nil || !strings.Contains(err.Error(), `"feature" not defined`) {
+		t.Errorf("expected templates calling feature to fail without a provider, got %v", err)
+	}
+}
@@ -0,0 +1,181 @@
+package hyperview
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview/constants"
+)
+
+// FileSystemConfig overrides the conventions of the adapter for a single file system, such as a third-party view
+// package laid out differently. The templates of the file system are read with its conventions and loaded as if they
+// followed the adapter's, so they are rendered and reference each other like any other templates: a view at
+// pages/users.tmpl is rendered with the path views/users.
+type FileSystemConfig struct {
+	// Extensions are the file extensions of the templates, e.g. ".tmpl" and ".gohtml". Default is the extension of
+	// the adapter.
+	Extensions []string
+	// ViewsDir is the directory of the views. Default is "views".
+	ViewsDir string
+	// LayoutsDir is the directory of the layouts. Default is "layouts".
+	LayoutsDir string
+	// PartialsDir is the directory of the partials. Default is "partials".
+	PartialsDir string
+	// LeftDelim and RightDelim are the action delimiters of the templates, e.g. "[[" and "]]" for templates holding
+	// the {{ }} syntax of a client-side framework. Default is "{{" and "}}".
+	LeftDelim  string
+	RightDelim string
+}
+
+// withDefaults returns the configuration with the adapter's conventions for the fields left empty.
+func (c FileSystemConfig) withDefaults(extension string) FileSystemConfig {
+	if len(c.Extensions) == 0 {
+		c.Extensions = []string{extension}
+	}
+	if c.ViewsDir == "" {
+		c.ViewsDir = constants.ViewsDir
+	}
+	if c.LayoutsDir == "" {
+		c.LayoutsDir = constants.LayoutsDir
+	}
+	if c.PartialsDir == "" {
+		c.PartialsDir = constants.PartialsDir
+	}
+	if c.LeftDelim == "" {
+		c.LeftDelim = "{{"
+	}
+	if c.RightDelim == "" {
+		c.RightDelim = "}}"
+	}
+	return c
+}
+
+// conventionalFS returns the templates of the file system laid out with the conventions of the adapter: the
+// directories of the configuration are renamed to the adapter's, the template extensions to the adapter's extension,
+// and the sources are translated to the default delimiters.
+func (a *TemplateAdapter) conventionalFS(fsys fs.FS, config FileSystemConfig) (fstest.MapFS, error) {
+	config = config.withDefaults(a.extension)
+	files := make(fstest.MapFS)
+
+	dirs := map[string]string{
+		config.LayoutsDir:  constants.LayoutsDir,
+		config.PartialsDir: constants.PartialsDir,
+		config.ViewsDir:    constants.ViewsDir,
+	}
+	for dir, conventional := range dirs {
+		if _, err := fs.Stat(fsys, dir); err != nil {
+			continue
+		}
+
+		err := fs.WalkDir(fsys, dir, func(filePath string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+
+			ext := templateExtension(filePath, config.Extensions)
+			if ext == "" {
+				return nil
+			}
+			rel := strings.TrimPrefix(strings.TrimSuffix(filePath, ext), dir+"/")
+			name := conventional + "/" + rel + a.extension
+			if _, ok := files[name]; ok {
+				return fmt.Errorf("%s: another template has the same name %s", filePath, rel)
+			}
+
+			src, err := fs.ReadFile(fsys, filePath)
+			if err != nil {
+				return err
+			}
+			if config.LeftDelim != "{{" || config.RightDelim != "}}" {
+				translated, err := translateDelims(string(src), config.LeftDelim, config.RightDelim)
+				if err != nil {
+					return fmt.Errorf("%s: %w", filePath, err)
+				}
+				src = []byte(translated)
+			}
+
+			files[name] = &fstest.MapFile{Data: src}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return files, nil
+}
+
+// templateExtension returns the extension of the template file among the extensions, the longest first, so .tmpl.html
+// is matched before .html. It returns an empty string if the file is not a template.
+func templateExtension(filePath string, extensions []string) string {
+	match := ""
+	for _, ext := range extensions {
+		if strings.HasSuffix(path.Base(filePath), ext) && len(ext) > len(match) {
+			match = ext
+		}
+	}
+	return match
+}
+
+// translateDelims translates a template source with the left and right delimiters to the default {{ }} delimiters.
+// The {{ of the text outside of the actions are escaped, so they are rendered as they are. Quoted strings, raw strings
+// and comments in the actions are skipped, so delimiters inside them are not mistaken for the end of the action.
+func translateDelims(src, left, right string) (string, error) {
+	var out strings.Builder
+	pos := 0
+	for {
+		idx := strings.Index(src[pos:], left)
+		if idx == -1 {
+			out.WriteString(strings.ReplaceAll(src[pos:], "{{", `{{"{{"}}`))
+			return out.String(), nil
+		}
+
+		start := pos + idx
+		out.WriteString(strings.ReplaceAll(src[pos:start], "{{", `{{"{{"}}`))
+
+		i := start + len(left)
+		body := i
+		for {
+			if i >= len(src) {
+				return "", fmt.Errorf("unclosed action at line %d", lineAt(src, start))
+			}
+			switch {
+			case strings.HasPrefix(src[i:], "/*"):
+				closing := strings.Index(src[i+2:], "*/")
+				if closing == -1 {
+					return "", fmt.Errorf("unclosed comment at line %d", lineAt(src, start))
+				}
+				i += closing + 4
+				continue
+			case src[i] == '"' || src[i] == '\'':
+				quote := src[i]
+				i++
+				for i < len(src) && src[i] != quote {
+					if src[i] == '\\' {
+						i++
+					}
+					i++
+				}
+				i++
+				continue
+			case src[i] == '`':
+				closing := strings.IndexByte(src[i+1:], '`')
+				if closing == -1 {
+					return "", fmt.Errorf("unterminated raw string at line %d", lineAt(src, start))
+				}
+				i += closing + 2
+				continue
+			case !strings.HasPrefix(src[i:], right):
+				i++
+				continue
+			}
+			break
+		}
+
+		out.WriteString("{{" + src[body:i] + "}}")
+		pos = i + len(right)
+	}
+}
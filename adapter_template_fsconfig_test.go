@@ -0,0 +1,104 @@
+package hyperview_test
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func TestTemplateAdapter_FileSystemConfigs(t *testing.T) {
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{
+			constants.RootFSID: fstest.MapFS{
+				"layouts/base.html":  {Data: []byte(`{{define "layout:base"}}<main>{{template "page:main" .}}</main>{{end}}`)},
+				"partials/nav.html":  {Data: []byte(`{{define "nav"}}<nav></nav>{{end}}`)},
+				"views/home.html":    {Data: []byte(`{{define "page:main"}}home{{end}}`)},
+				"pages/ignored.tmpl": {Data: []byte(`[[define "page:main"]]ignored[[end]]`)},
+			},
+			"shop": fstest.MapFS{
+				"tpl/frames/shop.tmpl":      {Data: []byte(`[[define "layout:shop"]]<div>[[template "page:main" .]]</div>[[end]]`)},
+				"tpl/bits/price.gohtml":     {Data: []byte(`[[define "@price"]]<b>[[.]]</b>[[end]]`)},
+				"tpl/pages/cart.tmpl":       {Data: []byte(`[[define "page:main"]]<p v-if="{{ open }}">[[template "@price" "9.99"]][[ "]]" ]]</p>[[end]]`)},
+				"tpl/pages/nav.tmpl":        {Data: []byte(`[[define "page:main"]][[- template "nav" ]][[/* plain comment */]][[end]]`)},
+				"tpl/pages/readme.md":       {Data: []byte(`not a template`)},
+				"tpl/pages/checkout.gohtml": {Data: []byte(`[[define "page:main"]]checkout[[end]]`)},
+			},
+		},
+		FileSystemConfigs: map[string]hyperview.FileSystemConfig{
+			"shop": {
+				Extensions:  []string{".tmpl", ".gohtml"},
+				ViewsDir:    "tpl/pages",
+				LayoutsDir:  "tpl/frames",
+				PartialsDir: "tpl/bits",
+				LeftDelim:   "[[",
+				RightDelim:  "]]",
+			},
+		},
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		layout string
+		path   string
+		want   string
+	}{
+		{name: "root view", layout: "base", path: "views/home", want: "<main>home</main>"},
+		{name: "literal delimiters", layout: "shop", path: "shop:views/cart", want: `<div><p v-if="{{ open }}"><b>9.99</b>]]</p></div>`},
+		{name: "partial of another file system", layout: "base", path: "shop:views/nav", want: "<main><nav></nav></main>"},
+		{name: "second extension", layout: "base", path: "shop:views/checkout", want: "<main>checkout</main>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := renderTestTemplate(t, adapter, response.NewResponse().Layout(tt.layout).Path(tt.path))
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTemplateAdapter_FileSystemConfigErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   fstest.MapFS
+		wantErr string
+	}{
+		{
+			name:    "unclosed action",
+			files:   fstest.MapFS{"pages/home.tmpl": {Data: []byte("\n[[define \"page:main\"]]home[[end")}},
+			wantErr: "pages/home.tmpl: unclosed action at line 2",
+		},
+		{
+			name: "same name with two extensions",
+			files: fstest.MapFS{
+				"pages/home.gohtml": {Data: []byte(`[[define "page:main"]]a[[end]]`)},
+				"pages/home.tmpl":   {Data: []byte(`[[define "page:main"]]b[[end]]`)},
+			},
+			wantErr: "another template has the same name home",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+				FileSystemMap: map[string]fs.FS{"shop": tt.files},
+				FileSystemConfigs: map[string]hyperview.FileSystemConfig{
+					"shop": {Extensions: []string{".gohtml", ".tmpl"}, ViewsDir: "pages", LeftDelim: "[[", RightDelim: "]]"},
+				},
+			})
+
+			err := adapter.Init()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
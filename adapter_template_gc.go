@@ -0,0 +1,209 @@
+package hyperview
+
+import (
+	"container/list"
+	"html/template"
+	"sync"
+	"time"
+)
+
+// TemplateGCOptions bound the template sets the adapter compiles on first use: all pages in lazy mode (see
+// TemplateViewAdapterOptions.LazyCompile), and pages rendered with a layout extending another layout. Evicted
+// template sets are compiled again on their next render. The zero value keeps every compiled template set.
+type TemplateGCOptions struct {
+	// MaxIdle evicts template sets not rendered within the window. Idle template sets are collected during template
+	// lookups, at most once per half window, or when calling TemplateAdapter.CollectTemplates.
+	MaxIdle time.Duration
+	// MaxTemplates is the maximum number of compiled template sets, evicting the least recently used ones.
+	MaxTemplates int
+	// MaxBytes is the memory budget of the compiled template sets, evicting the least recently used ones. The size of
+	// a template set is estimated from the size of its parsed templates.
+	MaxBytes int64
+}
+
+// templateGC tracks the use of the template sets compiled on first use and decides which ones to evict.
+type templateGC struct {
+	TemplateGCOptions
+	mu        sync.Mutex
+	entries   map[string]*list.Element
+	order     *list.List // front is the most recently used
+	size      int64
+	lastSweep time.Time
+	paused    bool // set while freezing, so the template sets compiled ahead of time are all kept
+}
+
+type gcEntry struct {
+	key      string
+	cost     int64
+	lastUsed time.Time
+}
+
+// newTemplateGC returns the collector for the options, or nil if they do not bound the compiled template sets.
+func newTemplateGC(opts TemplateGCOptions) *templateGC {
+	if opts.MaxIdle <= 0 && opts.MaxTemplates <= 0 && opts.MaxBytes <= 0 {
+		return nil
+	}
+
+	return &templateGC{
+		TemplateGCOptions: opts,
+		entries:           make(map[string]*list.Element),
+		order:             list.New(),
+		lastSweep:         time.Now(),
+	}
+}
+
+// touch records a render of the template set.
+func (gc *templateGC) touch(key string) {
+	if gc == nil {
+		return
+	}
+
+	gc.mu.Lock()
+	defer gc.mu.Unlock()
+
+	if elem, ok := gc.entries[key]; ok {
+		elem.Value.(*gcEntry).lastUsed = time.Now()
+		gc.order.MoveToFront(elem)
+	}
+}
+
+// add records a newly compiled template set and returns the keys of the template sets to evict to stay within the
+// limits. The new template set itself is never evicted.
+func (gc *templateGC) add(key string, tmpl *template.Template) []string {
+	if gc == nil {
+		return nil
+	}
+
+	gc.mu.Lock()
+	defer gc.mu.Unlock()
+
+	entry := &gcEntry{key: key, cost: templateCost(tmpl), lastUsed: time.Now()}
+	gc.entries[key] = gc.order.PushFront(entry)
+	gc.size += entry.cost
+
+	var evicted []string
+	for !gc.paused && gc.order.Len() > 1 && gc.overBudget() {
+		evicted = append(evicted, gc.remove(gc.order.Back()))
+	}
+	return evicted
+}
+
+func (gc *templateGC) overBudget() bool {
+	return (gc.MaxTemplates > 0 && gc.order.Len() > gc.MaxTemplates) || (gc.MaxBytes > 0 && gc.size > gc.MaxBytes)
+}
+
+// sweepDue reports whether idle template sets should be collected.
+func (gc *templateGC) sweepDue() bool {
+	if gc == nil || gc.MaxIdle <= 0 {
+		return false
+	}
+
+	gc.mu.Lock()
+	defer gc.mu.Unlock()
+
+	return time.Since(gc.lastSweep) >= gc.MaxIdle/2
+}
+
+// sweep returns the keys of the template sets not rendered within the idle window, forgetting them.
+func (gc *templateGC) sweep() []string {
+	if gc == nil || gc.MaxIdle <= 0 {
+		return nil
+	}
+
+	gc.mu.Lock()
+	defer gc.mu.Unlock()
+
+	now := time.Now()
+	gc.lastSweep = now
+	if gc.paused {
+		return nil
+	}
+
+	var evicted []string
+	for elem := gc.order.Back(); elem != nil; elem = gc.order.Back() {
+		if now.Sub(elem.Value.(*gcEntry).lastUsed) < gc.MaxIdle {
+			break
+		}
+		evicted = append(evicted, gc.remove(elem))
+	}
+	return evicted
+}
+
+// setPaused stops or resumes evicting template sets.
+func (gc *templateGC) setPaused(paused bool) {
+	if gc == nil {
+		return
+	}
+
+	gc.mu.Lock()
+	defer gc.mu.Unlock()
+
+	gc.paused = paused
+}
+
+// reset forgets all template sets, e.g. when the adapter is reinitialized.
+func (gc *templateGC) reset() {
+	if gc == nil {
+		return
+	}
+
+	gc.mu.Lock()
+	defer gc.mu.Unlock()
+
+	gc.entries = make(map[string]*list.Element)
+	gc.order.Init()
+	gc.size = 0
+}
+
+// forget forgets the template sets, e.g. when a reload recompiles their pages.
+func (gc *templateGC) forget(keys ...string) {
+	if gc == nil {
+		return
+	}
+
+	gc.mu.Lock()
+	defer gc.mu.Unlock()
+
+	for _, key := range keys {
+		if elem, ok := gc.entries[key]; ok {
+			gc.remove(elem)
+		}
+	}
+}
+
+func (gc *templateGC) remove(elem *list.Element) string {
+	entry := elem.Value.(*gcEntry)
+	gc.order.Remove(elem)
+	delete(gc.entries, entry.key)
+	gc.size -= entry.cost
+	return entry.key
+}
+
+// templateCost estimates the memory held by a template set from the size of its parsed templates.
+func templateCost(tmpl *template.Template) int64 {
+	var cost int64
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil && t.Tree.Root != nil {
+			cost += int64(len(t.Tree.Root.String()))
+		}
+	}
+	return cost
+}
+
+// CollectTemplates evicts the compiled template sets not rendered within the idle window of the TemplateGC option,
+// returning the number of evicted template sets. It does nothing once the adapter is frozen.
+func (a *TemplateAdapter) CollectTemplates() int {
+	if a.frozen.Load() || a.gc == nil {
+		return 0
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	evicted := a.gc.sweep()
+	for _, key := range evicted {
+		a.clones.forget(a.layered[key])
+		delete(a.layered, key)
+	}
+	return len(evicted)
+}
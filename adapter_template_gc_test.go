@@ -0,0 +1,89 @@
+package hyperview_test
+
+import (
+	"net/http"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func lazyTestFS() fstest.MapFS {
+	return fstest.MapFS{
+		"layouts/base.html": {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+		"views/a.html":      {Data: []byte(`{{define "page:main"}}a{{end}}`)},
+		"views/b.html":      {Data: []byte(`{{define "page:main"}}b{{end}}`)},
+		"views/broken.html": {Data: []byte(`{{define "page:main"}}{{if}}{{end}}`)},
+	}
+}
+
+func TestTemplateAdapter_LazyCompile(t *testing.T) {
+	adapter := newTestTemplateAdapter(t, lazyTestFS(), hyperview.TemplateViewAdapterOptions{LazyCompile: true})
+
+	if w := renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("a")); w.Body.String() != "a" {
+		t.Errorf("unexpected body: %s", w.Body.String())
+	}
+	if w := renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("broken")); w.Code != http.StatusInternalServerError {
+		t.Errorf("expected the parse error on first render, got %d", w.Code)
+	}
+	if err := adapter.Freeze(); err == nil {
+		t.Error("expected Freeze to compile all views and report the parse error")
+	}
+}
+
+func TestTemplateAdapter_TemplateGCMaxTemplates(t *testing.T) {
+	files := lazyTestFS()
+	adapter := newTestTemplateAdapter(t, files, hyperview.TemplateViewAdapterOptions{
+		LazyCompile: true,
+		TemplateGC:  hyperview.TemplateGCOptions{MaxTemplates: 1},
+	})
+
+	render := func(path string) string {
+		return renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path(path)).Body.String()
+	}
+
+	render("a")
+	files["views/a.html"] = &fstest.MapFile{Data: []byte(`{{define "page:main"}}a2{{end}}`)}
+	if got := render("a"); got != "a" {
+		t.Errorf("expected the compiled template to be kept, got %s", got)
+	}
+
+	// Compiling b evicts a, which is compiled again from the updated source
+	render("b")
+	if got := render("a"); got != "a2" {
+		t.Errorf("expected the evicted template to be recompiled, got %s", got)
+	}
+}
+
+func TestTemplateAdapter_TemplateGCMaxIdle(t *testing.T) {
+	files := lazyTestFS()
+	adapter := newTestTemplateAdapter(t, files, hyperview.TemplateViewAdapterOptions{
+		LazyCompile: true,
+		TemplateGC:  hyperview.TemplateGCOptions{MaxIdle: 20 * time.Millisecond},
+	})
+
+	render := func(path string) string {
+		return renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path(path)).Body.String()
+	}
+
+	render("a")
+	render("b")
+	if n := adapter.CollectTemplates(); n != 0 {
+		t.Errorf("expected recently rendered templates to be kept, evicted %d", n)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	render("b")
+	if n := adapter.CollectTemplates(); n != 0 {
+		t.Errorf("expected the idle template to be collected during the lookup, evicted %d", n)
+	}
+
+	// a was idle and is compiled again from the updated source, while b was just rendered and is kept
+	files["views/a.html"] = &fstest.MapFile{Data: []byte(`{{define "page:main"}}a2{{end}}`)}
+	files["views/b.html"] = &fstest.MapFile{Data: []byte(`{{define "page:main"}}b2{{end}}`)}
+	if got := render("a") + render("b"); got != "a2b" {
+		t.Errorf("unexpected renders after collection: %s", got)
+	}
+}
@@ -0,0 +1,101 @@
+package hyperview
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/hypergopher/hyperview/htmx"
+	"github.com/hypergopher/hyperview/response"
+)
+
+// pageHints remembers the resource hints registered by the last render of each page with each layout, so the renders served from the
+// render cache send them too, and early hints can be sent before the next render of the page.
+type pageHints struct {
+	mu    sync.RWMutex
+	pages map[hintKey][]response.Hint
+}
+
+// hintKey identifies the renders of a page with a layout.
+type hintKey struct {
+	page   string
+	layout string
+}
+
+// newPageHints returns an empty page hints registry.
+func newPageHints() *pageHints {
+	return &pageHints{pages: make(map[hintKey][]response.Hint)}
+}
+
+// hints returns the hints of the last render of the page with the layout of the response.
+func (h *pageHints) hints(resp *response.Response, pageName string) []response.Hint {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.pages[hintKey{page: pageName, layout: resp.TemplateLayout()}]
+}
+
+// record records the hints of a render of the page with the layout of the response.
+func (h *pageHints) record(resp *response.Response, pageName string) {
+	key, hints := hintKey{page: pageName, layout: resp.TemplateLayout()}, resp.Hints()
+	h.mu.RLock()
+	same := slices.Equal(h.pages[key], hints)
+	h.mu.RUnlock()
+	if same {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(hints) == 0 {
+		delete(h.pages, key)
+		return
+	}
+	h.pages[key] = append([]response.Hint(nil), hints...)
+}
+
+// sendEarlyHints sends a 103 Early Hints response with the hints known before rendering the page: those registered by
+// the handler, and those of the last render of the page. HTMX requests, whose pages already loaded their assets, get
+// none.
+func (a *TemplateAdapter) sendEarlyHints(w http.ResponseWriter, r *http.Request, resp *response.Response, pageName string) {
+	if !a.earlyHints || (htmx.IsHtmxRequest(r) && !htmx.IsBoostedRequest(r)) {
+		return
+	}
+
+	hints := mergeHints(resp.Hints(), a.hints.hints(resp, pageName))
+	if len(hints) == 0 {
+		return
+	}
+	w.Header().Set("Link", linkHeader(hints))
+	w.WriteHeader(http.StatusEarlyHints)
+}
+
+// renderHints returns the hints of a render of the page, and records them for the next renders. Renders served from
+// the render cache register no hints, so those of the last render of the page are used.
+func (a *TemplateAdapter) renderHints(resp *response.Response, pageName string, cached bool) []response.Hint {
+	if cached {
+		return mergeHints(resp.Hints(), a.hints.hints(resp, pageName))
+	}
+	a.hints.record(resp, pageName)
+	return resp.Hints()
+}
+
+// linkHeader returns the Link header of the hints.
+func linkHeader(hints []response.Hint) string {
+	links := make([]string, len(hints))
+	for i, hint := range hints {
+		links[i] = hint.String()
+	}
+	return strings.Join(links, ", ")
+}
+
+// mergeHints returns the hints of both lists, without duplicates.
+func mergeHints(hints, more []response.Hint) []response.Hint {
+	merged := append([]response.Hint(nil), hints...)
+	for _, hint := range more {
+		if !slices.Contains(merged, hint) {
+			merged = append(merged, hint)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,125 @@
+package hyperview_test
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"net/textproto"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/rendercache"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func hintsTestFiles() fstest.MapFS {
+	return fstest.MapFS{
+		"layouts/base.html": {Data: []byte(`{{define "layout:base"}}{{.View.Preload "/css/app.css" "style"}}` +
+			`{{.View.Preload "/fonts/inter.woff2" "font"}}{{template "page:main" .}}{{end}}`)},
+		"views/home.html": {Data: []byte(`{{define "page:main"}}{{.View.Preload "/css/app.css" "style"}}home{{end}}`)},
+	}
+}
+
+func TestTemplateAdapter_ResourceHints(t *testing.T) {
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: hintsTestFiles()},
+		RenderCache:   rendercache.NewLRU(10),
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		resp     *response.Response
+		wantLink string
+	}{
+		{name: "hints of the templates", resp: response.NewResponse(),
+			wantLink: "</css/app.css>; rel=preload; as=style, </fonts/inter.woff2>; rel=preload; as=font; crossorigin"},
+		{name: "hints of the handler", resp: response.NewResponse().Preconnect("https://cdn.example.com").Header("Link", "</next>; rel=next"),
+			wantLink: "</next>; rel=next, <https://cdn.example.com>; rel=preconnect, </css/app.css>; rel=preload; as=style, " +
+				"</fonts/inter.woff2>; rel=preload; as=font; crossorigin"},
+		{name: "render cache", resp: response.NewResponse().Cache("home", 0),
+			wantLink: "</css/app.css>; rel=preload; as=style, </fonts/inter.woff2>; rel=preload; as=font; crossorigin"},
+		{name: "cached render", resp: response.NewResponse().Cache("home", 0),
+			wantLink: "</css/app.css>; rel=preload; as=style, </fonts/inter.woff2>; rel=preload; as=font; crossorigin"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := renderTestTemplate(t, adapter, tt.resp.Layout("base").Path("home"))
+			if got := w.Header().Get("Link"); got != tt.wantLink {
+				t.Errorf("expected Link %q, got %q", tt.wantLink, got)
+			}
+		})
+	}
+}
+
+func TestTemplateAdapter_EarlyHints(t *testing.T) {
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: hintsTestFiles()},
+		EarlyHints:    true,
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		adapter.Render(w, r, response.NewResponse().Layout("base").Path("home"))
+	}))
+	defer server.Close()
+
+	earlyHints := func() []string {
+		var links []string
+		trace := &httptrace.ClientTrace{Got1xxResponse: func(code int, header textproto.MIMEHeader) error {
+			if code == http.StatusEarlyHints {
+				links = append(links, header.Get("Link"))
+			}
+			return nil
+		}}
+		req, _ := http.NewRequestWithContext(httptrace.WithClientTrace(context.Background(), trace), http.MethodGet, server.URL, nil)
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("error requesting page: %v", err)
+		}
+		defer res.Body.Close()
+		body, _ := io.ReadAll(res.Body)
+		if res.StatusCode != http.StatusOK || string(body) != "home" {
+			t.Fatalf("expected the page, got %d: %s", res.StatusCode, body)
+		}
+		return links
+	}
+
+	if links := earlyHints(); len(links) != 0 {
+		t.Errorf("expected no early hints before the page rendered, got %v", links)
+	}
+	want := "</css/app.css>; rel=preload; as=style, </fonts/inter.woff2>; rel=preload; as=font; crossorigin"
+	if links := earlyHints(); len(links) != 1 || links[0] != want {
+		t.Errorf("expected early hints %q, got %v", want, links)
+	}
+}
+
+func TestRenderTo_EarlyHints(t *testing.T) {
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: hintsTestFiles()},
+		EarlyHints:    true,
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+
+	for range 2 {
+		w := httptest.NewRecorder()
+		resp := response.NewResponse().Layout("base").Path("home")
+		if err := hyperview.RenderTo(w.Body, nil, httptest.NewRequest(http.MethodGet, "/", nil), adapter, resp); err != nil {
+			t.Fatalf("error rendering: %v", err)
+		}
+		if w.Body.String() != "home" {
+			t.Errorf("expected the page, got %q", w.Body.String())
+		}
+	}
+}
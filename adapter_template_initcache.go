@@ -0,0 +1,237 @@
+package hyperview
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hypergopher/hyperview/constants"
+)
+
+// initCacheVersion is the version of the init cache format, part of the cache key so caches written by other versions
+// are ignored.
+const initCacheVersion = "1"
+
+// initCachePrefix is the prefix of the init cache files.
+const initCachePrefix = "hyperview-init-"
+
+// InitCacheOptions configure the init cache, which records the views found at Init in a file, so the next cold start
+// restores them instead of walking and parsing the views. Restored views are compiled on first render, as in lazy
+// mode, without the risk of surfacing parse errors then: the views parsed at the Init that wrote the cache.
+//
+// Layouts and partials are parsed at every Init, as html/template cannot serialize parsed templates.
+type InitCacheOptions struct {
+	// Dir is the directory holding the cache file, e.g. a directory baked into the deployment image or a persistent
+	// volume. Each adapter needs its own directory, as the files of other keys are removed. The cache is disabled
+	// when empty.
+	Dir string
+	// Key identifies the version of the views, e.g. the build ID of a binary embedding them. When set, Init trusts the
+	// cache written with the same key and skips walking the views altogether, so the key must change whenever they
+	// do. When empty, the key is a hash of the sources of the views, so Init still reads them but skips parsing them.
+	Key string
+}
+
+// initCache is the content of an init cache file.
+type initCache struct {
+	Views              []cachedView        `json:"views"`
+	DeprecatedCalls    []DeprecatedCall    `json:"deprecatedCalls"`
+	DeprecatedIncludes []DeprecatedInclude `json:"deprecatedIncludes,omitempty"` // includes by the views
+}
+
+// cachedView is a view found at Init.
+type cachedView struct {
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	Layout string `json:"layout,omitempty"`
+	// Deprecated is the deprecation declared by the view source, if any.
+	Deprecated *TemplateDeprecation `json:"deprecated,omitempty"`
+}
+
+// restoreInitCache restores the views from the init cache, if it holds the views of the file systems. It returns the
+// cache key, or an empty key if the cache is disabled or the key cannot be computed.
+func (a *TemplateAdapter) restoreInitCache(fileSystems map[string]fs.FS) (string, bool) {
+	if a.initCache.Dir == "" {
+		return "", false
+	}
+
+	key, err := a.initCacheKey(fileSystems)
+	if err != nil {
+		a.log().Warn("Error computing the init cache key", slog.String("err", err.Error()))
+		return "", false
+	}
+
+	src, err := os.ReadFile(a.initCachePath(key))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			a.log().Warn("Error reading the init cache", slog.String("err", err.Error()))
+		}
+		return key, false
+	}
+
+	var cache initCache
+	if err := json.Unmarshal(src, &cache); err != nil {
+		a.log().Warn("Error decoding the init cache", slog.String("err", err.Error()))
+		return key, false
+	}
+
+	pages := make(map[string]templateFile, len(cache.Views))
+	for _, view := range cache.Views {
+		fsID := pageFSID(view.Name)
+		fsys, ok := fileSystems[fsID]
+		if !ok {
+			a.log().Warn("Ignoring the init cache, which holds views of an unknown file system", slog.String("fsID", fsID))
+			return key, false
+		}
+		pages[view.Name] = templateFile{fsys: fsys, path: view.Path, size: view.Size}
+	}
+
+	a.pages = pages
+	for _, view := range cache.Views {
+		if page, variant, ok := splitVariant(view.Name); ok {
+			a.pageVariants[page] = append(a.pageVariants[page], variant)
+		}
+		if view.Layout != "" {
+			a.pageLayouts[view.Name] = view.Layout
+		}
+		if _, ok := a.deprecated.templates[view.Name]; !ok && view.Deprecated != nil {
+			a.deprecated.templates[view.Name] = *view.Deprecated
+		}
+	}
+
+	for _, call := range cache.DeprecatedCalls {
+		call.Deprecation = a.deprecatedFuncs[call.Func]
+		a.deprecatedCalls = append(a.deprecatedCalls, call)
+	}
+	// The templates deprecated by the partials may have changed since, as the key only covers the views
+	for _, include := range cache.DeprecatedIncludes {
+		if deprecation, ok := a.deprecated.templates[include.Template]; ok {
+			include.Deprecation = deprecation
+			a.deprecated.includes = append(a.deprecated.includes, include)
+		}
+	}
+
+	a.lazyPages = true
+	return key, true
+}
+
+// saveInitCache writes the views found at Init to the init cache under the key, and removes the cache files of other
+// keys. Errors are logged, as the cache only speeds up the next Init.
+func (a *TemplateAdapter) saveInitCache(key string) {
+	if key == "" {
+		return
+	}
+
+	cache := initCache{Views: make([]cachedView, 0, len(a.pages)), DeprecatedCalls: a.deprecatedCalls}
+	for name, page := range a.pages {
+		view := cachedView{Name: name, Path: page.path, Size: page.size, Layout: a.pageLayouts[name]}
+		if deprecation, ok := a.deprecated.templates[name]; ok {
+			view.Deprecated = &deprecation
+		}
+		cache.Views = append(cache.Views, view)
+	}
+	for _, include := range a.deprecated.includes {
+		if !isLayoutFile(include.Path) && !isPartialFile(include.Path) {
+			cache.DeprecatedIncludes = append(cache.DeprecatedIncludes, include)
+		}
+	}
+	sort.Slice(cache.Views, func(i, j int) bool { return cache.Views[i].Name < cache.Views[j].Name })
+
+	src, err := json.Marshal(cache)
+	if err != nil {
+		a.log().Warn("Error encoding the init cache", slog.String("err", err.Error()))
+		return
+	}
+
+	if err := writeFileAtomic(a.initCachePath(key), src); err != nil {
+		a.log().Warn("Error writing the init cache", slog.String("err", err.Error()))
+		return
+	}
+
+	stale, _ := filepath.Glob(filepath.Join(a.initCache.Dir, initCachePrefix+"*.json"))
+	for _, path := range stale {
+		if path != a.initCachePath(key) {
+			_ = os.Remove(path)
+		}
+	}
+}
+
+// initCacheKey returns the key of the init cache for the views of the file systems.
+func (a *TemplateAdapter) initCacheKey(fileSystems map[string]fs.FS) (string, error) {
+	h := sha256.New()
+	h.Write([]byte(fmt.Sprintf("%s\x00%s\x00%d\x00", initCacheVersion, a.extension, a.pathCase)))
+
+	// Deprecated calls are recorded in the cache, so they depend on the deprecated functions too
+	funcs := make([]string, 0, len(a.deprecatedFuncs))
+	for name := range a.deprecatedFuncs {
+		funcs = append(funcs, name)
+	}
+	sort.Strings(funcs)
+	h.Write([]byte(strings.Join(funcs, ",") + "\x00"))
+
+	if a.initCache.Key != "" {
+		h.Write([]byte(a.initCache.Key))
+		return hex.EncodeToString(h.Sum(nil)[:16]), nil
+	}
+
+	for _, fsID := range sortedFSIDs(fileSystems) {
+		fsys := fileSystems[fsID]
+		if _, err := fs.Stat(fsys, constants.ViewsDir); err != nil {
+			continue
+		}
+
+		err := fs.WalkDir(fsys, constants.ViewsDir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !a.hasExtension(path) {
+				return err
+			}
+
+			src, err := fs.ReadFile(fsys, path)
+			if err != nil {
+				return err
+			}
+			h.Write([]byte(fsID + "\x00" + path + "\x00"))
+			h.Write(src)
+			h.Write([]byte{0})
+			return nil
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)[:16]), nil
+}
+
+func (a *TemplateAdapter) initCachePath(key string) string {
+	return filepath.Join(a.initCache.Dir, initCachePrefix+key+".json")
+}
+
+// writeFileAtomic writes the file through a temporary file renamed into place, so concurrent cold starts never read a
+// partially written file.
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
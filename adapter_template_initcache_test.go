@@ -0,0 +1,75 @@
+package hyperview_test
+
+import (
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/response"
+)
+
+// initCacheTestOptions returns the options of the adapters caching their Init with cache.
+func initCacheTestOptions(cache hyperview.InitCacheOptions) hyperview.TemplateViewAdapterOptions {
+	return hyperview.TemplateViewAdapterOptions{
+		InitCache:       cache,
+		DeprecatedFuncs: map[string]hyperview.FuncDeprecation{"lower": {Replacement: "upper"}},
+	}
+}
+
+func initCacheTestFiles(home string) fstest.MapFS {
+	return fstest.MapFS{
+		"layouts/base.html": {Data: []byte(`{{define "layout:base"}}<main>{{template "page:main" .}}</main>{{end}}`)},
+		"layouts/wide.html": {Data: []byte(`{{define "layout:wide"}}<div>{{template "page:main" .}}</div>{{end}}`)},
+		"views/home.html":   {Data: []byte(home)},
+		"views/about.html":  {Data: []byte(`{{define "layout"}}wide{{end}}{{define "page:main"}}{{lower "ABOUT"}}{{end}}`)},
+	}
+}
+
+func TestTemplateAdapter_InitCache(t *testing.T) {
+	tests := []struct {
+		name     string
+		key      string
+		second   string // source of views/home.html at the second Init
+		restored bool
+		want     string
+	}{
+		{name: "unchanged views", second: `{{define "page:main"}}home{{end}}`, restored: true, want: "<main>home</main>"},
+		{name: "changed views", second: `{{define "page:main"}}new home{{end}}`, restored: false, want: "<main>new home</main>"},
+		{name: "same key", key: "build-1", second: `{{define "page:main"}}home{{end}}`, restored: true, want: "<main>home</main>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := hyperview.InitCacheOptions{Dir: t.TempDir(), Key: tt.key}
+			newTestTemplateAdapter(t, initCacheTestFiles(`{{define "page:main"}}home{{end}}`), initCacheTestOptions(cache))
+
+			adapter := newTestTemplateAdapter(t, initCacheTestFiles(tt.second), initCacheTestOptions(cache))
+
+			// Restored views are compiled on first render, so no template set is compiled yet
+			if restored := len(adapter.TemplateStats().Sets) == 0; restored != tt.restored {
+				t.Errorf("expected the views to be restored: %v, got %v", tt.restored, restored)
+			}
+			if layout, ok := adapter.DeclaredLayout("views/about"); !ok || layout != "wide" {
+				t.Errorf("expected the declared layout of views/about to be wide, got %q", layout)
+			}
+			if calls := adapter.DeprecatedCalls(); len(calls) != 1 || calls[0].Deprecation.Replacement != "upper" {
+				t.Errorf("expected the deprecated call of views/about, got %v", calls)
+			}
+
+			w := renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("views/home"))
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+			w = renderTestTemplate(t, adapter, response.NewResponse().Path("views/about"))
+			if got := w.Body.String(); got != "<div>about</div>" {
+				t.Errorf("expected the about view with its declared layout, got %q", got)
+			}
+
+			files, err := filepath.Glob(filepath.Join(cache.Dir, "*.json"))
+			if err != nil || len(files) != 1 {
+				t.Errorf("expected a single cache file, got %v", files)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package hyperview
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"net/http"
+	"strings"
+
+	xhtml "golang.org/x/net/html"
+
+	"github.com/hypergopher/hyperview/constants"
+)
+
+// InlineContents are the contents of the inline script and style elements of the templates of an adapter which hold
+// template text only, without actions, as html/template renders them. Their content is the same on every render and
+// is known at Init, unlike that of the elements holding actions or inserted by the data of a render, e.g. through
+// safeHTML, so policies allowing inline scripts by their hashes, such as that of csp.HashMiddleware, only allow these.
+type InlineContents struct {
+	scripts map[string]bool
+	styles  map[string]bool
+}
+
+// Script reports whether the content is that of an inline script of the templates without actions, with the line
+// endings normalized to \n.
+func (c *InlineContents) Script(content string) bool {
+	return c != nil && c.scripts[content]
+}
+
+// Style reports whether the content is that of an inline style of the templates without actions, with the line
+// endings normalized to \n.
+func (c *InlineContents) Style(content string) bool {
+	return c != nil && c.styles[content]
+}
+
+type inlineContentsKey struct{}
+
+// InlineContentsFromContext returns the inline contents of the templates of the adapter rendering the request, for
+// the render middleware, or nil outside of the render middleware of a TemplateAdapter.
+func InlineContentsFromContext(ctx context.Context) *InlineContents {
+	contents, _ := ctx.Value(inlineContentsKey{}).(*InlineContents)
+	return contents
+}
+
+// middlewareRequest returns the request passed to the render middleware, with the inline contents of the templates.
+// Without render middleware, the request is returned as is.
+func (a *TemplateAdapter) middlewareRequest(r *http.Request) *http.Request {
+	if len(a.renderMiddleware) == 0 {
+		return r
+	}
+	if !a.frozen.Load() {
+		a.initMu.RLock()
+		defer a.initMu.RUnlock()
+	}
+	return r.WithContext(context.WithValue(r.Context(), inlineContentsKey{}, a.inlineContents))
+}
+
+// collectInlineContents returns the inline contents of the template files of the file systems. Elements whose
+// content holds an action are left out, as their content depends on the data of each render.
+func (a *TemplateAdapter) collectInlineContents(fileSystems map[string]fs.FS) (*InlineContents, error) {
+	contents := &InlineContents{scripts: make(map[string]bool), styles: make(map[string]bool)}
+	if len(a.renderMiddleware) == 0 {
+		return contents, nil
+	}
+
+	for fsID, fsys := range fileSystems {
+		for _, dir := range []string{constants.LayoutsDir, constants.PartialsDir, constants.ViewsDir} {
+			if _, err := fs.Stat(fsys, dir); err != nil {
+				continue
+			}
+			err := fs.WalkDir(fsys, dir, func(filePath string, d fs.DirEntry, err error) error {
+				if err != nil || d.IsDir() || !a.hasExtension(filePath) {
+					return err
+				}
+				src, err := fs.ReadFile(fsys, filePath)
+				if err != nil {
+					return err
+				}
+				if err := contents.add(src); err != nil {
+					return fmt.Errorf("error reading the inline scripts of %s: %w", templateFileKey(fsID, filePath), err)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return contents, nil
+}
+
+// add adds the contents of the inline script and style elements of the template source without actions.
+func (c *InlineContents) add(src []byte) error {
+	if !bytes.Contains(src, []byte("<script")) && !bytes.Contains(src, []byte("<style")) {
+		return nil
+	}
+
+	z := xhtml.NewTokenizer(bytes.NewReader(src))
+	for {
+		switch z.Next() {
+		case xhtml.ErrorToken:
+			if errors.Is(z.Err(), io.EOF) {
+				return nil
+			}
+			return z.Err()
+		case xhtml.StartTagToken:
+			name, _ := z.TagName()
+			tag := string(name)
+			if tag != "script" && tag != "style" {
+				continue
+			}
+			content := ""
+			if z.Next() == xhtml.TextToken {
+				content = string(z.Raw())
+			}
+			if strings.Contains(content, "{{") {
+				continue
+			}
+
+			// Contents html/template refuses, e.g. with an unterminated JavaScript string, are left unhashed
+			rendered, err := renderInline(tag, content)
+			if err != nil {
+				continue
+			}
+			if tag == "script" {
+				c.scripts[rendered] = true
+			} else {
+				c.styles[rendered] = true
+			}
+		}
+	}
+}
+
+// renderInline returns the content of the script or style element as html/template renders it, which replaces the
+// comments of scripts and styles by a space, with the line endings normalized like browsers do before hashing it.
+func renderInline(tag, content string) (string, error) {
+	open, end := "<"+tag+">", "</"+tag+">"
+	tmpl, err := template.New(tag).Parse(open + content + end)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, nil); err != nil {
+		return "", err
+	}
+	rendered := strings.TrimSuffix(strings.TrimPrefix(b.String(), open), end)
+	return strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(rendered), nil
+}
@@ -0,0 +1,191 @@
+package hyperview
+
+import (
+	"sort"
+	"time"
+)
+
+// TemplateInventory describes the templates loaded by a TemplateAdapter, for admin and debugging tools such as the
+// admin package.
+type TemplateInventory struct {
+	// LoadedAt is the time of the last Init or reload.
+	LoadedAt time.Time `json:"loadedAt"`
+	// FileSystems are the file systems of the adapter, the root file system first, then the others by ID.
+	FileSystems []FileSystemInventory `json:"fileSystems"`
+	// DeprecatedIncludes are the includes of deprecated templates, sorted by file and line.
+	DeprecatedIncludes []DeprecatedInclude `json:"deprecatedIncludes"`
+}
+
+// FileSystemInventory describes the templates loaded from a file system.
+type FileSystemInventory struct {
+	// ID is the ID of the file system.
+	ID string `json:"id"`
+	// Layouts are the layout files, sorted.
+	Layouts []TemplateFileInfo `json:"layouts"`
+	// Partials are the partial files, sorted.
+	Partials []TemplateFileInfo `json:"partials"`
+	// Views are the views, sorted.
+	Views []ViewInfo `json:"views"`
+}
+
+// TemplateFileInfo describes a layout or partial file.
+type TemplateFileInfo struct {
+	// File is the name of the file, like in the DependencyGraph, e.g. partials/card.html.
+	File string `json:"file"`
+	// Templates are the templates the file defines, sorted. Layouts extending another layout define none, as they are
+	// compiled with each page instead.
+	Templates []string `json:"templates"`
+	// Deprecated are the deprecated templates the file defines, sorted.
+	Deprecated []string `json:"deprecated,omitempty"`
+}
+
+// ViewInfo describes a view.
+type ViewInfo struct {
+	// Name is the name of the view, e.g. views/home.
+	Name string `json:"name"`
+	// File is the name of the file of the view, e.g. views/home.html.
+	File string `json:"file"`
+	// Layout is the layout declared by the view, if any.
+	Layout string `json:"layout,omitempty"`
+	// LoadedAt is the time the view was last loaded: the last Init, or the reload that found it changed.
+	LoadedAt time.Time `json:"loadedAt"`
+	// Dependencies are the layout and partial files the view can render, sorted.
+	Dependencies []string `json:"dependencies"`
+	// Usage is the number of renders of the view.
+	Usage TemplateUsage `json:"usage"`
+	// Deprecated describes the deprecation of the view, if it is deprecated.
+	Deprecated *TemplateDeprecation `json:"deprecated,omitempty"`
+	// Aliases are the aliases rendering the view, sorted (see TemplateViewAdapterOptions.ViewAliases).
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+// Inventory returns the templates loaded by the adapter, with the partials and layouts each view can render, when its
+// template was loaded and how often it was rendered. Like Dependencies, the views are read and parsed again to find
+// the partials they render.
+func (a *TemplateAdapter) Inventory() (TemplateInventory, error) {
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+
+	graph, err := a.dependencies()
+	if err != nil {
+		return TemplateInventory{}, err
+	}
+
+	defined := make(map[string][]string)
+	for name, file := range a.templateFiles {
+		defined[file] = append(defined[file], name)
+	}
+
+	byFS := make(map[string]*FileSystemInventory)
+	fileSystem := func(fsID string) *FileSystemInventory {
+		if byFS[fsID] == nil {
+			byFS[fsID] = &FileSystemInventory{ID: fsID, Layouts: []TemplateFileInfo{}, Partials: []TemplateFileInfo{}, Views: []ViewInfo{}}
+		}
+		return byFS[fsID]
+	}
+
+	for file := range a.sources {
+		fsID, _, _ := cutFSID(file)
+		templates := append([]string{}, defined[file]...)
+		sort.Strings(templates)
+		info := TemplateFileInfo{File: file, Templates: templates}
+		for _, name := range templates {
+			if _, ok := a.deprecated.templates[name]; ok {
+				info.Deprecated = append(info.Deprecated, name)
+			}
+		}
+		switch {
+		case isLayoutFile(file):
+			fileSystem(fsID).Layouts = append(fileSystem(fsID).Layouts, info)
+		case isPartialFile(file):
+			fileSystem(fsID).Partials = append(fileSystem(fsID).Partials, info)
+		}
+	}
+	for name, page := range a.pages {
+		if name == contentPage {
+			continue
+		}
+		view := ViewInfo{
+			Name:         name,
+			File:         templateFileKey(pageFSID(name), page.path),
+			Layout:       a.pageLayouts[name],
+			LoadedAt:     a.viewLoadedAt(name),
+			Dependencies: withoutFile(graph[name], templateFileKey(pageFSID(name), page.path)),
+			Usage:        a.usage.usage(name),
+		}
+		if deprecation, ok := a.deprecated.templates[name]; ok {
+			view.Deprecated = &deprecation
+		}
+		view.Aliases = a.aliasesOf(name)
+		fileSystem(pageFSID(name)).Views = append(fileSystem(pageFSID(name)).Views, view)
+	}
+
+	for fsID := range a.fileSystemMap {
+		fileSystem(fsID)
+	}
+
+	inventory := TemplateInventory{
+		LoadedAt:           a.loadedAt,
+		FileSystems:        []FileSystemInventory{},
+		DeprecatedIncludes: append([]DeprecatedInclude{}, a.deprecated.includes...),
+	}
+	for _, fsID := range sortedFSIDs(byFS) {
+		fsys := byFS[fsID]
+		sort.Slice(fsys.Layouts, func(i, j int) bool { return fsys.Layouts[i].File < fsys.Layouts[j].File })
+		sort.Slice(fsys.Partials, func(i, j int) bool { return fsys.Partials[i].File < fsys.Partials[j].File })
+		sort.Slice(fsys.Views, func(i, j int) bool { return fsys.Views[i].Name < fsys.Views[j].Name })
+		inventory.FileSystems = append(inventory.FileSystems, *fsys)
+	}
+
+	return inventory, nil
+}
+
+// Views returns the names of the views, sorted, e.g. views/home/index. This includes the template and locale variants
+// of views, and the views of the file systems other than the root one, prefixed with their ID.
+func (a *TemplateAdapter) Views() []string {
+	if !a.frozen.Load() {
+		a.initMu.RLock()
+		defer a.initMu.RUnlock()
+	}
+
+	views := make([]string, 0, len(a.pages))
+	for name := range a.pages {
+		if name != contentPage {
+			views = append(views, name)
+		}
+	}
+	sort.Strings(views)
+	return views
+}
+
+// viewLoadedAt returns the time the view was last loaded.
+func (s *templateState) viewLoadedAt(pageName string) time.Time {
+	if loadedAt, ok := s.viewsLoadedAt[pageName]; ok {
+		return loadedAt
+	}
+	return s.loadedAt
+}
+
+// withoutFile returns the files but file.
+func withoutFile(files []string, file string) []string {
+	others := make([]string, 0, len(files))
+	for _, f := range files {
+		if f != file {
+			others = append(others, f)
+		}
+	}
+	return others
+}
+
+// aliasesOf returns the sorted aliases of the view. Aliases of views rendered through a view fallback, e.g. of
+// views/users for views/users/index, are listed with the fallback view.
+func (a *TemplateAdapter) aliasesOf(view string) []string {
+	var names []string
+	for alias, target := range a.aliases {
+		if resolved, _ := a.fallbackView(target); resolved == view {
+			names = append(names, alias)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
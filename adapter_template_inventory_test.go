@@ -0,0 +1,57 @@
+package hyperview_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func TestTemplateAdapter_Inventory(t *testing.T) {
+	adapter := newTestTemplateAdapter(t, usageTestFiles(), hyperview.TemplateViewAdapterOptions{})
+	renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("views/home"))
+
+	inventory, err := adapter.Inventory()
+	if err != nil {
+		t.Fatalf("error listing the templates: %v", err)
+	}
+	if len(inventory.FileSystems) != 1 || inventory.FileSystems[0].ID != constants.RootFSID {
+		t.Fatalf("expected the root file system, got %+v", inventory.FileSystems)
+	}
+	root := inventory.FileSystems[0]
+
+	if len(root.Layouts) != 1 || root.Layouts[0].File != "layouts/base.html" {
+		t.Errorf("unexpected layouts: %+v", root.Layouts)
+	}
+	var partials []string
+	for _, partial := range root.Partials {
+		partials = append(partials, partial.File+"="+partial.Templates[0])
+	}
+	if want := []string{"partials/card.html=card", "partials/footer.html=footer", "partials/legacy.html=legacy"}; !reflect.DeepEqual(partials, want) {
+		t.Errorf("expected partials %v, got %v", want, partials)
+	}
+
+	if len(root.Views) != 3 {
+		t.Fatalf("expected 3 views, got %+v", root.Views)
+	}
+	home := root.Views[1]
+	if home.Name != "views/home" || home.File != "views/home.html" || home.Usage.Renders != 1 {
+		t.Errorf("unexpected home view: %+v", home)
+	}
+	if want := []string{"layouts/base.html", "partials/card.html"}; !reflect.DeepEqual(home.Dependencies, want) {
+		t.Errorf("expected home to depend on %v, got %v", want, home.Dependencies)
+	}
+	if home.LoadedAt.IsZero() || !home.LoadedAt.Equal(inventory.LoadedAt) {
+		t.Errorf("expected home to be loaded with the adapter, got %v and %v", home.LoadedAt, inventory.LoadedAt)
+	}
+}
+
+func TestTemplateAdapter_Views(t *testing.T) {
+	adapter := newTestTemplateAdapter(t, usageTestFiles(), hyperview.TemplateViewAdapterOptions{})
+
+	if want, got := []string{"views/about", "views/home", "views/old"}, adapter.Views(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected views %v, got %v", want, got)
+	}
+}
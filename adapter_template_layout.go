@@ -0,0 +1,202 @@
+package hyperview
+
+import (
+	"fmt"
+	"html/template"
+	"io/fs"
+	"regexp"
+	"strings"
+)
+
+// extendsDirective matches the comment a layout uses to declare its parent layout, e.g. {{/* extends "base" */}}.
+var extendsDirective = regexp.MustCompile(`^\s*{{-?\s*/\*\s*extends\s+"([^"]+)"\s*\*/\s*-?}}`)
+
+// frontMatterLayout matches a layout declared in a leading HTML comment of a view, e.g. <!-- layout: marketing -->.
+var frontMatterLayout = regexp.MustCompile(`^\s*<!--\s*layout:\s*([^\s{}]+)\s*-->`)
+
+// defineLayout matches a layout declared in a "layout" template definition of a view, e.g.
+// {{define "layout"}}marketing{{end}}.
+var defineLayout = regexp.MustCompile(`{{-?\s*define\s+"layout"\s*-?}}\s*([^\s{}]+)\s*{{-?\s*end\s*-?}}`)
+
+// templateFile is the location of a template source file.
+type templateFile struct {
+	fsys fs.FS
+	path string
+	size int64 // source size in bytes
+}
+
+// layoutFile is a layout that extends another layout.
+type layoutFile struct {
+	templateFile
+	file   string // name of the file, like in the DependencyGraph
+	parent string
+}
+
+// layoutParent returns the name of the layout extended by the layout source, or an empty string if it does not
+// extend another layout.
+func layoutParent(src string) string {
+	match := extendsDirective.FindStringSubmatch(src)
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+// declaredLayout returns the layout declared by a view source, either in a leading front matter comment or in a
+// "layout" template definition. It returns an empty string if the view does not declare a layout.
+func declaredLayout(src string) string {
+	if match := frontMatterLayout.FindStringSubmatch(src); match != nil {
+		return match[1]
+	}
+
+	if match := defineLayout.FindStringSubmatch(src); match != nil {
+		return match[1]
+	}
+
+	return ""
+}
+
+// DeclaredLayout returns the layout declared by the view template at the given path (e.g. "views/home"), if any.
+// The view is rendered with this layout whenever the response does not set a layout explicitly.
+func (a *TemplateAdapter) DeclaredLayout(path string) (string, bool) {
+	if !a.frozen.Load() {
+		a.initMu.RLock()
+		defer a.initMu.RUnlock()
+	}
+
+	layout, ok := a.pageLayouts[a.lookupPage(a.normalizeName(path))]
+	return layout, ok
+}
+
+// resolveLayoutChains computes the ancestry of each layout that extends another layout, from the layout itself up to
+// the root layout, which is an ordinary layout in the common templates.
+func (a *TemplateAdapter) resolveLayoutChains(commonTemplates *template.Template) (map[string][]string, error) {
+	chains := make(map[string][]string, len(a.layouts))
+
+	for name := range a.layouts {
+		chain := []string{name}
+		seen := map[string]bool{name: true}
+
+		for current := a.layouts[name]; ; {
+			parent := current.parent
+			if seen[parent] {
+				return nil, fmt.Errorf("layout %s has a circular extends chain: %s -> %s", name, strings.Join(chain, " -> "), parent)
+			}
+			seen[parent] = true
+			chain = append(chain, parent)
+
+			next, ok := a.layouts[parent]
+			if !ok {
+				if commonTemplates.Lookup("layout:"+parent) == nil {
+					return nil, fmt.Errorf("layout %s extends unknown layout %s", name, parent)
+				}
+				break
+			}
+			current = next
+		}
+
+		chains[name] = chain
+	}
+
+	return chains, nil
+}
+
+// lookupTemplate returns the template set to render the page with the given layout, along with the name of the layout
+// template to execute.
+//
+// Pages rendered with a root layout use the page template set built at Init. Layouts that extend another layout
+// override the blocks of their ancestors, so they cannot share a template set with the other layouts. Instead, the
+// page is compiled with the layout chain on first use and cached. In lazy mode, and for views restored from the init
+// cache, pages rendered with a root layout are compiled on first use too, as are pages rendered with a strict mode
+// other than the adapter's and the content page.
+func (a *TemplateAdapter) lookupTemplate(pageName, layout string, strict bool) (*template.Template, string, error) {
+	if _, ok := a.pages[pageName]; !ok {
+		return nil, "", fmt.Errorf("template not found: %s", pageName)
+	}
+
+	chain, extended := a.layoutChains[layout]
+	override := strict != a.strict
+	if !extended && !a.lazyPages && !override && pageName != contentPage {
+		return a.templates[pageName], "layout:" + layout, nil
+	}
+
+	rootLayout := "layout:" + layout
+	key := "|" + pageName
+	if extended {
+		rootLayout = "layout:" + chain[len(chain)-1]
+		key = layout + "|" + pageName
+	}
+	if override {
+		key += "|" + missingKeyOption(strict)
+	}
+
+	if a.frozen.Load() {
+		// Freeze compiled every page with every extending layout
+		return a.layered[key], rootLayout, nil
+	}
+
+	tmpl, err := a.compiledTemplate(key, pageName, chain, strict)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Collect idle template sets after the lookup, so the template set just rendered is never evicted
+	if a.gc.sweepDue() {
+		a.CollectTemplates()
+	}
+	return tmpl, rootLayout, nil
+}
+
+// compiledTemplate returns the template set compiled on first use under key, compiling the page with the layout
+// chain if needed.
+func (a *TemplateAdapter) compiledTemplate(key, pageName string, chain []string, strict bool) (*template.Template, error) {
+	a.mu.RLock()
+	tmpl, ok := a.layered[key]
+	a.mu.RUnlock()
+	if ok {
+		a.gc.touch(key)
+		return tmpl, nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if tmpl, ok := a.layered[key]; ok {
+		return tmpl, nil
+	}
+
+	tmpl, err := a.compilePage(pageName, chain, strict)
+	if err != nil {
+		return nil, err
+	}
+
+	a.layered[key] = tmpl
+	for _, evicted := range a.gc.add(key, tmpl) {
+		a.clones.forget(a.layered[evicted])
+		delete(a.layered, evicted)
+	}
+	return tmpl, nil
+}
+
+// compilePage compiles the page with the layouts of an extending layout chain, or with the common templates only if
+// the chain is empty.
+func (a *TemplateAdapter) compilePage(pageName string, chain []string, strict bool) (*template.Template, error) {
+	tmpl := a.pageTemplateSet(strict)
+
+	// Parse from the outermost extending layout down to the requested one, so each layer overrides its parent's blocks
+	for i := len(chain) - 2; i >= 0; i-- {
+		lf := a.layouts[chain[i]]
+		if err := a.parseTemplateFile(tmpl, lf.fsys, pageFSID(lf.file), lf.path); err != nil {
+			return nil, fmt.Errorf("error parsing layout %s: %w", chain[i], err)
+		}
+	}
+
+	page := a.pages[pageName]
+	if err := a.parseTemplateFile(tmpl, page.fsys, pageFSID(pageName), page.path); err != nil {
+		return nil, err
+	}
+	if err := a.addCommonTemplates(tmpl); err != nil {
+		return nil, err
+	}
+
+	return tmpl.Funcs(a.templateFuncs(tmpl)), nil
+}
@@ -0,0 +1,127 @@
+package hyperview_test
+
+import (
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func TestTemplateAdapter_NestedLayouts(t *testing.T) {
+	files := fstest.MapFS{
+		"layouts/base.html": {Data: []byte(`{{define "layout:base"}}<title>{{block "title" .}}Site{{end}}</title>` +
+			`<body>{{block "body" .}}{{template "page:main" .}}{{end}}</body>{{end}}`)},
+		"layouts/admin.html": {Data: []byte(`{{/* extends "base" */}}` +
+			`{{define "title"}}Admin{{end}}{{define "body"}}<nav>admin</nav>{{block "admin:body" .}}{{template "page:main" .}}{{end}}{{end}}`)},
+		"layouts/super.html": {Data: []byte(`{{/* extends "admin" */}}` +
+			`{{define "admin:body"}}<nav>super</nav>{{template "page:main" .}}{{end}}`)},
+		"views/home.html":  {Data: []byte(`{{define "page:main"}}home{{end}}`)},
+		"views/users.html": {Data: []byte(`{{define "title"}}Users{{end}}{{define "page:main"}}users{{end}}`)},
+	}
+
+	adapter := newTestTemplateAdapter(t, files, hyperview.TemplateViewAdapterOptions{})
+
+	tests := []struct {
+		name   string
+		layout string
+		path   string
+		want   string
+	}{
+		{"root layout", "base", "home", `<title>Site</title><body>home</body>`},
+		{"extending layout", "admin", "home", `<title>Admin</title><body><nav>admin</nav>home</body>`},
+		{"page overrides layout block", "admin", "users", `<title>Users</title><body><nav>admin</nav>users</body>`},
+		{"multi-level layout", "super", "home", `<title>Admin</title><body><nav>admin</nav><nav>super</nav>home</body>`},
+		{"root layout unaffected by extending layouts", "base", "home", `<title>Site</title><body>home</body>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := renderTestTemplate(t, adapter, response.NewResponse().Layout(tt.layout).Path(tt.path))
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("unexpected body:\ngot  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplateAdapter_NestedLayoutErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   fstest.MapFS
+		wantErr string
+	}{
+		{
+			name: "unknown parent",
+			files: fstest.MapFS{
+				"layouts/admin.html": {Data: []byte(`{{/* extends "missing" */}}`)},
+			},
+			wantErr: "layout admin extends unknown layout missing",
+		},
+		{
+			name: "circular chain",
+			files: fstest.MapFS{
+				"layouts/a.html": {Data: []byte(`{{/* extends "b" */}}`)},
+				"layouts/b.html": {Data: []byte(`{{/* extends "a" */}}`)},
+			},
+			wantErr: "circular extends chain",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+				FileSystemMap: map[string]fs.FS{constants.RootFSID: tt.files},
+			})
+
+			err := adapter.Init()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Init() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTemplateAdapter_DeclaredLayout(t *testing.T) {
+	files := fstest.MapFS{
+		"layouts/base.html":      {Data: []byte(`{{define "layout:base"}}base:{{template "page:main" .}}{{end}}`)},
+		"layouts/marketing.html": {Data: []byte(`{{define "layout:marketing"}}marketing:{{template "page:main" .}}{{end}}`)},
+		"views/home.html":        {Data: []byte("<!-- layout: marketing -->\n" + `{{define "page:main"}}home{{end}}`)},
+		"views/pricing.html":     {Data: []byte(`{{define "layout"}}marketing{{end}}{{define "page:main"}}pricing{{end}}`)},
+		"views/about.html":       {Data: []byte(`{{define "page:main"}}about{{end}}`)},
+	}
+
+	hv, err := hyperview.NewHyperView()
+	if err != nil {
+		t.Fatalf("error creating HyperView: %v", err)
+	}
+	if err := hv.RegisterAdapter("html", newTestTemplateAdapter(t, files, hyperview.TemplateViewAdapterOptions{})); err != nil {
+		t.Fatalf("error registering adapter: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		resp *response.Response
+		want string
+	}{
+		{"front matter", response.NewResponse().Path("home"), "marketing:home"},
+		{"layout define", response.NewResponse().Path("pricing"), "marketing:pricing"},
+		{"no declaration uses base layout", response.NewResponse().Path("about"), "base:about"},
+		{"explicit layout wins", response.NewResponse().Layout("base").Path("home"), "base:home"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			hv.Render(w, httptest.NewRequest(http.MethodGet, "/", nil), tt.resp)
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("unexpected body: got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package hyperview
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"sort"
+	"sync"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview/constants"
+)
+
+// Loader loads template sources from a store other than a file system, such as a database or a CMS where users edit
+// their own templates. The sources are laid out like a file system, with the layouts, partials and views directories.
+type Loader interface {
+	// Load returns the template sources, keyed by path (e.g. views/home.html).
+	Load(ctx context.Context) (map[string]string, error)
+}
+
+// WatchingLoader is a Loader that notifies the adapter when its templates change, for example when an editor saves a
+// template, so the adapter reloads them (see TemplateAdapter.WatchLoaders).
+type WatchingLoader interface {
+	Loader
+	// Watch calls changed whenever the templates change, until ctx is done.
+	Watch(ctx context.Context, changed func()) error
+}
+
+// LoaderFunc adapts a function to the Loader interface.
+type LoaderFunc func(ctx context.Context) (map[string]string, error)
+
+// Load calls f.
+func (f LoaderFunc) Load(ctx context.Context) (map[string]string, error) {
+	return f(ctx)
+}
+
+// fileSystems returns the file systems of the adapter, laid out with the conventions of the adapter if they have a
+// FileSystemConfig, along with the file systems holding the sources of its loaders, keyed by file system ID. The
+// templates added with ParseString are overlaid on the root file system.
+func (a *TemplateAdapter) fileSystems(ctx context.Context) (map[string]fs.FS, error) {
+	if len(a.loaders) == 0 && len(a.fileSystemConfigs) == 0 && len(a.stringTemplates) == 0 {
+		return a.fileSystemMap, nil
+	}
+
+	fileSystems := make(map[string]fs.FS, len(a.fileSystemMap)+len(a.loaders))
+	for fsID, fsys := range a.fileSystemMap {
+		if config, ok := a.fileSystemConfigs[fsID]; ok {
+			conventional, err := a.conventionalFS(fsys, config)
+			if err != nil {
+				return nil, fmt.Errorf("error loading templates of %s: %w", fsID, err)
+			}
+			fsys = conventional
+		}
+		fileSystems[fsID] = fsys
+	}
+
+	for fsID, loader := range a.loaders {
+		if _, ok := fileSystems[fsID]; ok {
+			return nil, fmt.Errorf("loader %s: file system ID is already used by FileSystemMap", fsID)
+		}
+
+		sources, err := loader.Load(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error loading templates of %s: %w", fsID, err)
+		}
+
+		fsys := make(fstest.MapFS, len(sources))
+		for path, src := range sources {
+			if !fs.ValidPath(path) {
+				return nil, fmt.Errorf("loader %s: invalid template path %q", fsID, path)
+			}
+			fsys[path] = &fstest.MapFile{Data: []byte(src)}
+		}
+		fileSystems[fsID] = fsys
+	}
+
+	// The templates added with ParseString are files of the root file system, overriding those of the same path
+	if len(a.stringTemplates) > 0 {
+		files := make(fstest.MapFS, len(a.stringTemplates))
+		for path, src := range a.stringTemplates {
+			files[path] = &fstest.MapFile{Data: []byte(src)}
+		}
+		if root, ok := fileSystems[constants.RootFSID]; ok && root != nil {
+			fileSystems[constants.RootFSID] = NewOverlayFS(root, files)
+		} else {
+			fileSystems[constants.RootFSID] = files
+		}
+	}
+
+	return fileSystems, nil
+}
+
+// sortedFSIDs returns the IDs of the file systems in the order their templates are loaded: the root file system first,
+// then the others sorted by ID.
+func sortedFSIDs[V any](fileSystems map[string]V) []string {
+	fsIDs := make([]string, 0, len(fileSystems))
+	for fsID := range fileSystems {
+		fsIDs = append(fsIDs, fsID)
+	}
+	sort.Slice(fsIDs, func(i, j int) bool {
+		if fsIDs[i] == constants.RootFSID || fsIDs[j] == constants.RootFSID {
+			return fsIDs[i] == constants.RootFSID
+		}
+		return fsIDs[i] < fsIDs[j]
+	})
+	return fsIDs
+}
+
+// WatchLoaders watches the loaders implementing WatchingLoader until ctx is done or the adapter is shut down,
+// reloading the templates affected whenever their templates change (see Reload). It returns immediately. Reloads that
+// fail are logged, and the previous templates are rendered until the next change.
+func (a *TemplateAdapter) WatchLoaders(ctx context.Context) {
+	watchers := make(map[string]WatchingLoader)
+	for fsID, loader := range a.loaders {
+		if watcher, ok := loader.(WatchingLoader); ok {
+			watchers[fsID] = watcher
+		}
+	}
+
+	ctx, ok := a.watchers.start(ctx, len(watchers))
+	if !ok {
+		return
+	}
+
+	for fsID, watcher := range watchers {
+		go func() {
+			defer a.watchers.wg.Done()
+
+			err := watcher.Watch(ctx, func() {
+				if a.frozen.Load() {
+					a.log().Warn("Ignoring template changes of a frozen adapter", slog.String("fsID", fsID))
+					return
+				}
+				if err := a.Reload(); err != nil {
+					a.log().Error("Error reloading templates", slog.String("fsID", fsID), slog.String("err", err.Error()))
+				}
+			})
+			if err != nil && ctx.Err() == nil {
+				a.log().Error("Error watching templates", slog.String("fsID", fsID), slog.String("err", err.Error()))
+			}
+		}()
+	}
+}
+
+// Shutdown stops the loader watchers started by WatchLoaders and waits for them to return, or for ctx to be done. It
+// implements Shutdowner, so HyperView.Shutdown stops the watchers. Later calls to WatchLoaders do nothing.
+func (a *TemplateAdapter) Shutdown(ctx context.Context) error {
+	return a.watchers.stop(ctx)
+}
+
+// loaderWatchers tracks the goroutines started by WatchLoaders, so Shutdown can stop them.
+type loaderWatchers struct {
+	mu      sync.Mutex
+	cancels []context.CancelFunc
+	closed  bool
+	wg      sync.WaitGroup
+}
+
+// start returns the context of n new watchers, cancelled by stop, each calling w.wg.Done when it returns. It returns
+// false once stopped.
+func (w *loaderWatchers) start(ctx context.Context, n int) (context.Context, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed || n == 0 {
+		return nil, false
+	}
+	w.wg.Add(n)
+	ctx, cancel := context.WithCancel(ctx)
+	w.cancels = append(w.cancels, cancel)
+	return ctx, true
+}
+
+// stop cancels the watchers and waits for them to return, or for ctx to be done.
+func (w *loaderWatchers) stop(ctx context.Context) error {
+	w.mu.Lock()
+	w.closed = true
+	for _, cancel := range w.cancels {
+		cancel()
+	}
+	w.cancels = nil
+	w.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("error waiting for the loader watchers: %w", ctx.Err())
+	}
+}
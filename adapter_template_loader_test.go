@@ -0,0 +1,164 @@
+package hyperview_test
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/response"
+)
+
+// memoryLoader is a WatchingLoader holding templates in memory, like a CMS would in a database.
+type memoryLoader struct {
+	mu      sync.Mutex
+	sources map[string]string
+	changes chan struct{}
+}
+
+func (l *memoryLoader) Load(context.Context) (map[string]string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	sources := make(map[string]string, len(l.sources))
+	for path, src := range l.sources {
+		sources[path] = src
+	}
+	return sources, nil
+}
+
+func (l *memoryLoader) Watch(ctx context.Context, changed func()) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-l.changes:
+			changed()
+		}
+	}
+}
+
+func (l *memoryLoader) save(path, src string) {
+	l.mu.Lock()
+	l.sources[path] = src
+	l.mu.Unlock()
+	l.changes <- struct{}{}
+}
+
+func TestTemplateAdapter_Loaders(t *testing.T) {
+	loader := &memoryLoader{
+		sources: map[string]string{"views/home.html": `{{define "page:main"}}acme v1{{end}}`},
+		changes: make(chan struct{}),
+	}
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{
+			"layouts/base.html": {Data: []byte(`{{define "layout:base"}}<main>{{template "page:main" .}}</main>{{end}}`)},
+		}},
+		Loaders: map[string]hyperview.Loader{"acme": loader},
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+
+	resp := func() *response.Response { return response.NewResponse().Layout("base").Path("acme:home") }
+	if got := renderTestTemplate(t, adapter, resp()).Body.String(); got != "<main>acme v1</main>" {
+		t.Fatalf("unexpected body from the loader: %q", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	adapter.WatchLoaders(ctx)
+
+	// Render concurrently with the reload, which must keep serving the previous templates until it completes
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for range 20 {
+			renderTestTemplate(t, adapter, resp())
+		}
+	}()
+	loader.save("views/home.html", `{{define "page:main"}}acme v2{{end}}`)
+	wg.Wait()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		got := renderTestTemplate(t, adapter, resp()).Body.String()
+		if got == "<main>acme v2</main>" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the changed template to be reloaded, got %q", got)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestTemplateAdapter_ShutdownWatchers(t *testing.T) {
+	loader := &memoryLoader{
+		sources: map[string]string{"views/home.html": `{{define "page:main"}}acme{{end}}`},
+		changes: make(chan struct{}),
+	}
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{}},
+		Loaders:       map[string]hyperview.Loader{"acme": loader},
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+	adapter.WatchLoaders(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := adapter.Shutdown(ctx); err != nil {
+		t.Fatalf("expected the watchers to stop, got %v", err)
+	}
+
+	// The watchers of later calls are not started, so nothing receives the change
+	adapter.WatchLoaders(context.Background())
+	select {
+	case loader.changes <- struct{}{}:
+		t.Error("expected no watcher after shutdown")
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestTemplateAdapter_LoaderErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		loader hyperview.Loader
+		want   string
+	}{
+		{
+			name: "load error",
+			loader: hyperview.LoaderFunc(func(context.Context) (map[string]string, error) {
+				return nil, errors.New("database is down")
+			}),
+			want: "error loading templates of acme: database is down",
+		},
+		{
+			name: "invalid path",
+			loader: hyperview.LoaderFunc(func(context.Context) (map[string]string, error) {
+				return map[string]string{"/views/home.html": "home"}, nil
+			}),
+			want: `invalid template path "/views/home.html"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+				Loaders: map[string]hyperview.Loader{"acme": tt.loader},
+			})
+			err := adapter.Init()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package hyperview
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/hypergopher/hyperview/constants"
+)
+
+// localizedPage returns the locale-specific variant of the page for the locale in the request context, if the
+// adapter has localized views enabled and such a variant exists. Variants are either named after the page with the
+// locale before the extension (e.g. views/home/index.de.html) or placed in a locale subtree of the views directory
+// (e.g. views/de/home/index.html), the former taking precedence. The base language is tried when there is no variant
+// for the full locale (de-AT -> de), then the default locale, if any. Otherwise, the page itself is returned.
+func (a *TemplateAdapter) localizedPage(r *http.Request, pageName string) string {
+	if !a.localizedViews {
+		return pageName
+	}
+
+	var candidates []string
+	if locale, _ := r.Context().Value(constants.LocaleContextKey).(string); locale != "" {
+		candidates = append(candidates, locale)
+		if base, _, found := strings.Cut(locale, "-"); found {
+			candidates = append(candidates, base)
+		}
+	}
+	if a.defaultLocale != "" {
+		candidates = append(candidates, a.defaultLocale)
+	}
+
+	for _, candidate := range candidates {
+		variants := []string{a.normalizeName(pageName + "." + candidate), a.normalizeName(localeSubtreePage(pageName, candidate))}
+		for _, variant := range variants {
+			if _, ok := a.pages[variant]; ok {
+				return variant
+			}
+		}
+	}
+
+	return pageName
+}
+
+// localeSubtreePage returns the page in the locale subtree of the views directory, e.g. views/home -> views/de/home,
+// keeping the fsID: prefix of the page, if any.
+func localeSubtreePage(pageName, locale string) string {
+	prefix, page := "", pageName
+	if i := strings.Index(pageName, ":"); i != -1 {
+		prefix, page = pageName[:i+1], pageName[i+1:]
+	}
+
+	rest, ok := strings.CutPrefix(page, constants.ViewsDir+"/")
+	if !ok {
+		return pageName
+	}
+	return prefix + constants.ViewsDir + "/" + locale + "/" + rest
+}
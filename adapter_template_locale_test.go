@@ -0,0 +1,108 @@
+package hyperview_test
+
+import (
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/i18n"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func TestTemplateAdapter_LocalizedViews(t *testing.T) {
+	bundle := i18n.NewBundle("en")
+	bundle.AddMessages("en", map[string]string{"title": "Welcome"})
+	bundle.AddMessages("de", map[string]string{"title": "Willkommen"})
+
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{
+			"layouts/base.html":  {Data: []byte(`{{define "layout:base"}}<html lang="{{locale}}">{{template "page:main" .}}{{end}}`)},
+			"views/home.html":    {Data: []byte(`{{define "page:main"}}{{t "title"}}{{end}}`)},
+			"views/home.de.html": {Data: []byte(`{{define "page:main"}}de:{{t "title"}}{{end}}`)},
+		}},
+		RequestFuncs:   bundle.Funcs(bundle.DefaultLocale()),
+		LocalizedViews: true,
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+
+	handler := bundle.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		adapter.Render(w, r, response.NewResponse().Layout("base").Path("home"))
+	}))
+
+	tests := []struct {
+		name   string
+		accept string
+		want   string
+	}{
+		{"default view", "en", `<html lang="en">Welcome`},
+		{"localized variant", "de", `<html lang="de">de:Willkommen`},
+		{"base language variant", "de-CH", `<html lang="de">de:Willkommen`},
+		{"unsupported locale", "fr", `<html lang="en">Welcome`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Accept-Language", tt.accept)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("unexpected body: got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplateAdapter_LocaleSubtrees(t *testing.T) {
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{
+			"layouts/base.html":         {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+			"views/en/about.html":       {Data: []byte(`{{define "page:main"}}en about{{end}}`)},
+			"views/fr/about.html":       {Data: []byte(`{{define "page:main"}}fr about{{end}}`)},
+			"views/fr/contact.html":     {Data: []byte(`{{define "page:main"}}fr contact{{end}}`)},
+			"views/contact.html":        {Data: []byte(`{{define "page:main"}}contact{{end}}`)},
+			"views/contact.fr.html":     {Data: []byte(`{{define "page:main"}}fr suffixed contact{{end}}`)},
+			"views/pricing.html":        {Data: []byte(`{{define "page:main"}}pricing{{end}}`)},
+			"views/en/legal/terms.html": {Data: []byte(`{{define "page:main"}}en terms{{end}}`)},
+		}},
+		LocalizedViews: true,
+		DefaultLocale:  "en",
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		locale string
+		path   string
+		want   string
+	}{
+		{name: "locale subtree", locale: "fr", path: "about", want: "fr about"},
+		{name: "base language subtree", locale: "fr-CA", path: "about", want: "fr about"},
+		{name: "default locale subtree", locale: "de", path: "about", want: "en about"},
+		{name: "no locale", path: "legal/terms", want: "en terms"},
+		{name: "suffix takes precedence", locale: "fr", path: "contact", want: "fr suffixed contact"},
+		{name: "default view", locale: "de", path: "pricing", want: "pricing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.locale != "" {
+				r = r.WithContext(i18n.ContextWithLocale(r.Context(), tt.locale))
+			}
+			w := httptest.NewRecorder()
+			adapter.Render(w, r, response.NewResponse().Layout("base").Path(tt.path))
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("unexpected body: got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package hyperview
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/hypergopher/hyperview/response"
+)
+
+// RenderLogOptions configure the structured events the adapter logs with its logger: the templates loaded by each
+// Init and reload, and the start and finish of each render.
+type RenderLogOptions struct {
+	// Level is the level of the events. Failed renders are logged at the error level whatever the level. Default is
+	// slog.LevelDebug, so the events are only logged by loggers enabling debug logs.
+	Level slog.Leveler
+	// SampleRate is the fraction of the renders logged, e.g. 0.01 for one render in a hundred, bounding the volume of
+	// logs of busy servers. Failed renders are always logged. Zero logs every render.
+	SampleRate float64
+}
+
+// level returns the level of the events.
+func (o RenderLogOptions) level() slog.Level {
+	if o.Level == nil {
+		return slog.LevelDebug
+	}
+	return o.Level.Level()
+}
+
+// sampled reports whether the render started at start is logged. The decision is derived from the start time, so the
+// start and finish events of a render are logged together.
+func (o RenderLogOptions) sampled(start time.Time) bool {
+	if o.SampleRate <= 0 || o.SampleRate >= 1 {
+		return true
+	}
+
+	// Mix the bits of the start time, whose low bits are often zero on platforms with a coarse clock
+	x := uint64(start.UnixNano())
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return float64(x>>11)/math.Exp2(53) < o.SampleRate
+}
+
+// logTemplatesLoaded logs the templates loaded by an Init or reload, counted by file system.
+func (a *TemplateAdapter) logTemplatesLoaded(op string, start time.Time) {
+	ctx := context.Background()
+	level := a.renderLog.level()
+	if !a.log().Enabled(ctx, level) {
+		return
+	}
+
+	type counts struct{ layouts, partials, views int }
+	byFS := make(map[string]*counts)
+	count := func(fsID string) *counts {
+		if byFS[fsID] == nil {
+			byFS[fsID] = &counts{}
+		}
+		return byFS[fsID]
+	}
+	for file := range a.sources {
+		fsID, _, _ := cutFSID(file)
+		switch {
+		case isLayoutFile(file):
+			count(fsID).layouts++
+		case isPartialFile(file):
+			count(fsID).partials++
+		}
+	}
+	for name := range a.pages {
+		if name != contentPage {
+			count(pageFSID(name)).views++
+		}
+	}
+
+	fsIDs := make([]string, 0, len(byFS))
+	for fsID := range byFS {
+		fsIDs = append(fsIDs, fsID)
+	}
+	sort.Strings(fsIDs)
+
+	attrs := []slog.Attr{slog.String("op", op), slog.Duration("duration", time.Since(start)), slog.Int("views", len(a.pages)-1)}
+	for _, fsID := range fsIDs {
+		c := byFS[fsID]
+		attrs = append(attrs, slog.Group(fsID, slog.Int("layouts", c.layouts), slog.Int("partials", c.partials), slog.Int("views", c.views)))
+	}
+	a.log().LogAttrs(ctx, level, "Templates loaded", attrs...)
+}
+
+// logRenderStart logs the start of a render.
+func (a *TemplateAdapter) logRenderStart(r *http.Request, resp *response.Response, start time.Time) {
+	if !a.renderLog.sampled(start) {
+		return
+	}
+	a.log().LogAttrs(r.Context(), a.renderLog.level(), "Render started",
+		slog.String("template", resp.TemplatePath()), slog.String("layout", resp.TemplateLayout()))
+}
+
+// logRenderFinish logs the end of a render, at the error level if it failed.
+func (a *TemplateAdapter) logRenderFinish(r *http.Request, resp *response.Response, start time.Time, status, size int, err error) {
+	if err == nil && !a.renderLog.sampled(start) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("template", resp.TemplatePath()),
+		slog.String("layout", resp.TemplateLayout()),
+		slog.Int("status", status),
+		slog.Duration("duration", time.Since(start)),
+		slog.Int("bytes", size),
+	}
+	if err == nil {
+		a.log().LogAttrs(r.Context(), a.renderLog.level(), "Render finished", attrs...)
+		return
+	}
+
+	level := slog.LevelError
+	if errors.Is(err, ErrRenderShed) {
+		// Shed renders are the render limits working as intended
+		level = slog.LevelWarn
+	}
+	a.log().LogAttrs(r.Context(), level, "Render failed", append(attrs, slog.String("err", err.Error()))...)
+}
@@ -0,0 +1,147 @@
+package hyperview_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/fs"
+	"log/slog"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func logTestFiles() fstest.MapFS {
+	return fstest.MapFS{
+		"layouts/base.html": {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+		"partials/nav.html": {Data: []byte(`{{define "nav"}}{{end}}`)},
+		"views/home.html":   {Data: []byte(`{{define "page:main"}}home{{end}}`)},
+		"views/broken.html": {Data: []byte(`{{define "page:main"}}{{template "missing"}}{{end}}`)},
+	}
+}
+
+// logTestOptions returns the options of the adapters logging JSON events at the level into the buffer, with the
+// views of the acme file system.
+func logTestOptions(buf *bytes.Buffer, level slog.Level, opts hyperview.RenderLogOptions) hyperview.TemplateViewAdapterOptions {
+	return hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{
+			"acme": fstest.MapFS{
+				"views/pricing.html": {Data: []byte(`{{define "page:main"}}pricing{{end}}`)},
+			},
+		},
+		Logger:    slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: level})),
+		RenderLog: opts,
+	}
+}
+
+// logEvents decodes the JSON events of the buffer.
+func logEvents(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+
+	var events []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var event map[string]any
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("error decoding log event %q: %v", line, err)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestTemplateAdapter_RenderLog(t *testing.T) {
+	var buf bytes.Buffer
+	adapter := newTestTemplateAdapter(t, logTestFiles(), logTestOptions(&buf, slog.LevelDebug, hyperview.RenderLogOptions{}))
+
+	renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("views/home"))
+	renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("views/broken"))
+
+	events := logEvents(t, &buf)
+	var msgs []string
+	for _, event := range events {
+		msgs = append(msgs, event["msg"].(string))
+	}
+	want := []string{"Templates loaded", "Render started", "Render finished", "Render started", "Render failed"}
+	if strings.Join(msgs, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected events %v, got %v", want, msgs)
+	}
+
+	loaded := events[0]
+	if loaded["op"] != "init" || loaded["views"] != float64(3) {
+		t.Errorf("unexpected init event: %v", loaded)
+	}
+	if root, _ := loaded[constants.RootFSID].(map[string]any); root["layouts"] != float64(1) || root["partials"] != float64(1) || root["views"] != float64(2) {
+		t.Errorf("unexpected counts of the root file system: %v", loaded[constants.RootFSID])
+	}
+
+	finished := events[2]
+	if finished["template"] != "views/home" || finished["layout"] != "base" || finished["bytes"] != float64(4) || finished["status"] != float64(200) {
+		t.Errorf("unexpected render event: %v", finished)
+	}
+
+	if failed := events[4]; failed["level"] != "ERROR" || failed["err"] == nil {
+		t.Errorf("unexpected failed render event: %v", failed)
+	}
+}
+
+func TestTemplateAdapter_RenderLogLevel(t *testing.T) {
+	tests := []struct {
+		name   string
+		level  slog.Leveler
+		events int
+	}{
+		{name: "debug events filtered by the logger", events: 0},
+		{name: "info events", level: slog.LevelInfo, events: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			adapter := newTestTemplateAdapter(t, logTestFiles(), logTestOptions(&buf, slog.LevelInfo, hyperview.RenderLogOptions{Level: tt.level}))
+			renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("views/home"))
+
+			if got := len(logEvents(t, &buf)); got != tt.events {
+				t.Errorf("expected %d events, got %d", tt.events, got)
+			}
+		})
+	}
+}
+
+func TestTemplateAdapter_RenderLogSampling(t *testing.T) {
+	var buf bytes.Buffer
+	adapter := newTestTemplateAdapter(t, logTestFiles(), logTestOptions(&buf, slog.LevelDebug, hyperview.RenderLogOptions{SampleRate: 0.2}))
+	buf.Reset()
+
+	const renders = 500
+	for range renders {
+		renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("views/home"))
+	}
+	renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("views/broken"))
+
+	started, finished, failed := 0, 0, 0
+	for _, event := range logEvents(t, &buf) {
+		switch event["msg"] {
+		case "Render started":
+			started++
+		case "Render finished":
+			finished++
+		case "Render failed":
+			failed++
+		}
+	}
+	if finished == 0 || finished > renders/2 {
+		t.Errorf("expected about %d sampled renders, got %d", renders/5, finished)
+	}
+	if failed != 1 {
+		t.Errorf("expected the failed render to be logged, got %d events", failed)
+	}
+	if started < finished {
+		t.Errorf("expected the finished renders to be logged with their start, got %d starts for %d", started, finished)
+	}
+}
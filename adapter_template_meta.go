@@ -0,0 +1,32 @@
+package hyperview
+
+import (
+	"fmt"
+	"html/template"
+
+	"github.com/hypergopher/hyperview/request"
+	"github.com/hypergopher/hyperview/response"
+)
+
+// renderMeta renders the meta tags of the page whose render data is data, the dot of the page or its .View, with the
+// site-wide defaults of the adapter. The page title is the default title, before the site-wide title.
+func (a *TemplateAdapter) renderMeta(data any) (template.HTML, error) {
+	var view *response.Data
+	switch d := data.(type) {
+	case *response.Data:
+		view = d
+	case map[string]any:
+		view, _ = d["View"].(*response.Data)
+	}
+	if view == nil {
+		return "", fmt.Errorf("renderMeta: expected the render data, got %T", data)
+	}
+
+	meta := view.Meta().Merge(response.Meta{Title: view.Title()}).Merge(a.meta)
+
+	baseURL := ""
+	if r := view.Request(); r != nil {
+		baseURL = request.BaseURL(r)
+	}
+	return meta.Tags(baseURL), nil
+}
@@ -0,0 +1,39 @@
+package hyperview
+
+import (
+	"net/http"
+
+	"github.com/hypergopher/hyperview/response"
+)
+
+// RenderFunc renders the body of a response. The body is buffered, so it is only written once the whole chain of
+// render middleware returned it. The buffer is reused once the response is written, so middleware keeping the body,
+// e.g. in a cache, must copy it.
+type RenderFunc func(r *http.Request, resp *response.Response) ([]byte, error)
+
+// RenderMiddleware wraps the rendering of responses by a TemplateAdapter, to layer cross-cutting concerns such as
+// minification, caching, instrumentation or HTML rewriting without forking the adapter.
+//
+// Middleware can change the response before calling next, e.g. to add data or headers, transform the body next
+// returns, or skip next and return a body of its own. Errors fail the render like template errors do.
+//
+// Example:
+//
+//	func Minify(next hyperview.RenderFunc) hyperview.RenderFunc {
+//		return func(r *http.Request, resp *response.Response) ([]byte, error) {
+//			body, err := next(r, resp)
+//			if err != nil {
+//				return nil, err
+//			}
+//			return minify.HTML(body), nil
+//		}
+//	}
+type RenderMiddleware func(next RenderFunc) RenderFunc
+
+// chainRender wraps render with the render middleware of the adapter, the first middleware outermost.
+func (a *TemplateAdapter) chainRender(render RenderFunc) RenderFunc {
+	for i := len(a.renderMiddleware) - 1; i >= 0; i-- {
+		render = a.renderMiddleware[i](render)
+	}
+	return render
+}
@@ -0,0 +1,103 @@
+package hyperview_test
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/response"
+)
+
+// wrapBody is a render middleware wrapping the body in tags named after name.
+func wrapBody(name string) hyperview.RenderMiddleware {
+	return func(next hyperview.RenderFunc) hyperview.RenderFunc {
+		return func(r *http.Request, resp *response.Response) ([]byte, error) {
+			body, err := next(r, resp)
+			if err != nil {
+				return nil, err
+			}
+			return []byte("<" + name + ">" + string(body) + "</" + name + ">"), nil
+		}
+	}
+}
+
+func TestTemplateAdapter_RenderMiddleware(t *testing.T) {
+	files := fstest.MapFS{
+		"layouts/base.html": {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+		"views/home.html":   {Data: []byte(`{{define "page:main"}}<p>Hello {{.Name}}</p>{{end}}`)},
+		"views/broken.html": {Data: []byte(`{{define "page:main"}}{{template "missing"}}{{end}}`)},
+	}
+
+	setName := func(next hyperview.RenderFunc) hyperview.RenderFunc {
+		return func(r *http.Request, resp *response.Response) ([]byte, error) {
+			resp.AddDataItem("Name", "Ann").Header("X-Rendered-By", "middleware")
+			return next(r, resp)
+		}
+	}
+	var seen error
+	recordError := func(next hyperview.RenderFunc) hyperview.RenderFunc {
+		return func(r *http.Request, resp *response.Response) ([]byte, error) {
+			body, err := next(r, resp)
+			seen = err
+			return body, err
+		}
+	}
+
+	tests := []struct {
+		name       string
+		middleware []hyperview.RenderMiddleware
+		path       string
+		wantStatus int
+		wantBody   string
+		wantHeader string
+	}{
+		{name: "no middleware", path: "home", wantStatus: http.StatusOK, wantBody: "<p>Hello </p>"},
+		{name: "first middleware outermost", middleware: []hyperview.RenderMiddleware{wrapBody("outer"), wrapBody("inner")},
+			path: "home", wantStatus: http.StatusOK, wantBody: "<outer><inner><p>Hello </p></inner></outer>"},
+		{name: "changes the response before rendering", middleware: []hyperview.RenderMiddleware{setName},
+			path: "home", wantStatus: http.StatusOK, wantBody: "<p>Hello Ann</p>", wantHeader: "middleware"},
+		{name: "replaces the body", middleware: []hyperview.RenderMiddleware{func(hyperview.RenderFunc) hyperview.RenderFunc {
+			return func(*http.Request, *response.Response) ([]byte, error) { return []byte("cached"), nil }
+		}}, path: "home", wantStatus: http.StatusOK, wantBody: "cached"},
+		{name: "fails the render", middleware: []hyperview.RenderMiddleware{func(hyperview.RenderFunc) hyperview.RenderFunc {
+			return func(*http.Request, *response.Response) ([]byte, error) { return nil, errors.New("middleware failed") }
+		}}, path: "home", wantStatus: http.StatusInternalServerError, wantBody: "middleware failed"},
+		{name: "sees template errors", middleware: []hyperview.RenderMiddleware{recordError, wrapBody("outer")},
+			path: "broken", wantStatus: http.StatusInternalServerError, wantBody: "error executing template"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seen = nil
+			adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+				FileSystemMap:    map[string]fs.FS{constants.RootFSID: files},
+				RenderMiddleware: tt.middleware,
+			})
+			if err := adapter.Init(); err != nil {
+				t.Fatalf("error initializing adapter: %v", err)
+			}
+
+			w := renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path(tt.path))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantStatus == http.StatusOK && w.Body.String() != tt.wantBody {
+				t.Errorf("expected body %q, got %q", tt.wantBody, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK && !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("expected body to contain %q, got %q", tt.wantBody, w.Body.String())
+			}
+			if got := w.Header().Get("X-Rendered-By"); got != tt.wantHeader {
+				t.Errorf("expected header %q, got %q", tt.wantHeader, got)
+			}
+			if tt.path == "broken" && (seen == nil || !strings.Contains(seen.Error(), "missing")) {
+				t.Errorf("expected the middleware to see the template error, got %v", seen)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package hyperview
+
+import (
+	"fmt"
+	"io/fs"
+)
+
+// AddFS mounts the file system under the ID, merging its layouts, partials and views with those of the other file
+// systems, e.g. when a plugin is enabled at runtime. Like Reload, only the pages affected are compiled: the views of
+// the file system, and the pages rendering the partials it adds. Everything is recompiled if the file system has
+// layouts, or partials overriding the templates of other file systems. AddFS is safe to call concurrently with
+// renders, and leaves the templates unchanged if the file system fails to load. Before Init, the file system is only
+// registered, and loaded by Init.
+func (a *TemplateAdapter) AddFS(id string, fsys fs.FS) error {
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+
+	if a.frozen.Load() {
+		panic("hyperview: AddFS called on a frozen TemplateAdapter")
+	}
+	if _, ok := a.fileSystemMap[id]; ok {
+		return fmt.Errorf("file system %s is already mounted", id)
+	}
+	if _, ok := a.loaders[id]; ok {
+		return fmt.Errorf("file system ID %s is already used by a loader", id)
+	}
+
+	config := a.templateConfig
+	config.fileSystemMap = make(map[string]fs.FS, len(a.fileSystemMap)+1)
+	for fsID, mounted := range a.fileSystemMap {
+		config.fileSystemMap[fsID] = mounted
+	}
+	config.fileSystemMap[id] = fsys
+
+	return a.mount(config)
+}
+
+// RemoveFS unmounts the file system with the ID, dropping its layouts, partials and views, e.g. when a plugin is
+// disabled at runtime. The pages of the other file systems are kept, unless the file system had layouts or partials,
+// which the pages may render. Like AddFS, RemoveFS is safe to call concurrently with renders.
+func (a *TemplateAdapter) RemoveFS(id string) error {
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+
+	if a.frozen.Load() {
+		panic("hyperview: RemoveFS called on a frozen TemplateAdapter")
+	}
+	if _, ok := a.fileSystemMap[id]; !ok {
+		return fmt.Errorf("file system %s is not mounted", id)
+	}
+
+	config := a.templateConfig
+	config.fileSystemMap = make(map[string]fs.FS, len(a.fileSystemMap)-1)
+	for fsID, mounted := range a.fileSystemMap {
+		if fsID != id {
+			config.fileSystemMap[fsID] = mounted
+		}
+	}
+
+	return a.mount(config)
+}
+
+// mount replaces the file systems and functions of the adapter with those of the configuration, reloading the
+// templates if Init has loaded them. The caller holds reloadMu.
+func (a *TemplateAdapter) mount(config templateConfig) error {
+	if a.common == nil {
+		a.setMounts(config)
+		return nil
+	}
+	return a.reload(config)
+}
+
+// setMounts sets the file systems, functions and string templates of the configuration, the only parts of the configuration changing
+// after NewTemplateViewAdapter. The caller holds reloadMu, and mu once Init has loaded the templates, as pages compiled
+// on first use are parsed with the functions.
+func (a *TemplateAdapter) setMounts(config templateConfig) {
+	a.fileSystemMap = config.fileSystemMap
+	a.funcMap = config.funcMap
+	a.providerFuncs = config.providerFuncs
+	a.stringTemplates = config.stringTemplates
+}
@@ -0,0 +1,149 @@
+package hyperview_test
+
+import (
+	"io/fs"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func mountTestAdapter(t *testing.T) *hyperview.TemplateAdapter {
+	t.Helper()
+
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{
+			"layouts/base.html": {Data: []byte(`{{define "layout:base"}}<main>{{template "page:main" .}}</main>{{end}}`)},
+			"views/home.html":   {Data: []byte(`{{define "page:main"}}home{{end}}`)},
+		}},
+		LazyCompile: true,
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+	return adapter
+}
+
+func blogFS() fstest.MapFS {
+	return fstest.MapFS{
+		"partials/blog/card.html": {Data: []byte(`<article>{{.}}</article>`)},
+		"views/posts.html":        {Data: []byte(`{{define "page:main"}}{{template "blog/card" "post"}}{{end}}`)},
+	}
+}
+
+func TestTemplateAdapter_AddFS(t *testing.T) {
+	adapter := mountTestAdapter(t)
+	renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("views/home"))
+
+	if err := adapter.AddFS("blog", blogFS()); err != nil {
+		t.Fatalf("error adding file system: %v", err)
+	}
+
+	w := renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("blog:views/posts"))
+	if got, want := w.Body.String(), "<main><article>post</article></main>"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	// The pages of the other file systems are kept, as they do not render the partials added
+	var kept []string
+	for _, set := range adapter.TemplateStats().Sets {
+		kept = append(kept, set.Page)
+	}
+	if want := []string{"blog:views/posts", "views/home"}; !reflect.DeepEqual(kept, want) {
+		t.Errorf("expected the template sets of %v, got %v", want, kept)
+	}
+
+	if err := adapter.AddFS("blog", blogFS()); err == nil {
+		t.Error("expected an error mounting a file system ID twice")
+	}
+}
+
+func TestTemplateAdapter_AddFSFailure(t *testing.T) {
+	adapter := mountTestAdapter(t)
+
+	broken := fstest.MapFS{"partials/broken.html": {Data: []byte(`{{define "broken"}}`)}}
+	if err := adapter.AddFS("broken", broken); err == nil {
+		t.Fatal("expected an error adding a file system with broken templates")
+	}
+
+	// The file system is not mounted, so it can be fixed and added again
+	broken["partials/broken.html"] = &fstest.MapFile{Data: []byte(`{{define "broken"}}{{end}}`)}
+	if err := adapter.AddFS("broken", broken); err != nil {
+		t.Errorf("error adding the fixed file system: %v", err)
+	}
+}
+
+func TestTemplateAdapter_RemoveFS(t *testing.T) {
+	adapter := mountTestAdapter(t)
+	if err := adapter.AddFS("blog", blogFS()); err != nil {
+		t.Fatalf("error adding file system: %v", err)
+	}
+
+	if err := adapter.RemoveFS("blog"); err != nil {
+		t.Fatalf("error removing file system: %v", err)
+	}
+
+	if w := renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("blog:views/posts")); w.Code == http.StatusOK {
+		t.Errorf("expected the views of the removed file system not to be found, got %q", w.Body.String())
+	}
+	if w := renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("views/home")); w.Body.String() != "<main>home</main>" {
+		t.Errorf("unexpected body of views/home: %q", w.Body.String())
+	}
+
+	if err := adapter.RemoveFS("blog"); err == nil {
+		t.Error("expected an error removing a file system that is not mounted")
+	}
+}
+
+func TestTemplateAdapter_AddFSBeforeInit(t *testing.T) {
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{
+			"layouts/base.html": {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+		}},
+	})
+	if err := adapter.AddFS("blog", blogFS()); err != nil {
+		t.Fatalf("error adding file system: %v", err)
+	}
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+
+	w := renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("blog:views/posts"))
+	if got, want := w.Body.String(), "<article>post</article>"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestTemplateAdapter_AddFSConcurrentRenders(t *testing.T) {
+	adapter := mountTestAdapter(t)
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				w := renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("views/home"))
+				if w.Code != http.StatusOK {
+					t.Errorf("unexpected status %d rendering while mounting: %s", w.Code, w.Body.String())
+					return
+				}
+			}
+		}()
+	}
+
+	for range 10 {
+		if err := adapter.AddFS("blog", blogFS()); err != nil {
+			t.Fatalf("error adding file system: %v", err)
+		}
+		if err := adapter.RemoveFS("blog"); err != nil {
+			t.Fatalf("error removing file system: %v", err)
+		}
+	}
+	wg.Wait()
+}
@@ -0,0 +1,233 @@
+package hyperview
+
+import (
+	"fmt"
+	"html/template"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// parseTemplateFile reads the template file at filePath from fsys, the file system of ID fsID, applies the source
+// transformations supported by the adapter (such as component blocks) and parses the result into t. Like
+// template.ParseFS, the template is named after the base name of the file.
+func (a *TemplateAdapter) parseTemplateFile(t *template.Template, fsys fs.FS, fsID, filePath string) error {
+	b, err := fs.ReadFile(fsys, filePath)
+	if err != nil {
+		return err
+	}
+
+	return a.parseTemplateSource(t, fsID, filePath, string(b))
+}
+
+// parseTemplateSource applies the source transformations supported by the adapter to src, the source of the file at
+// filePath of the file system of ID fsID, and parses the result into t, naming the template after the base name of
+// filePath.
+func (a *TemplateAdapter) parseTemplateSource(t *template.Template, fsID, filePath, src string) error {
+	return a.parseTemplateAs(t, path.Base(filePath), fsID, filePath, src)
+}
+
+// parseTemplateAs is like parseTemplateSource, naming the template name. The templates hoisted out of component and
+// cache blocks are named after the file and its file system, so files at the same path of two file systems, such as
+// partials, don't redefine each other's.
+func (a *TemplateAdapter) parseTemplateAs(t *template.Template, name, fsID, filePath, src string) error {
+	src, err := preprocessTemplate(src, templateFileKey(fsID, filePath))
+	if err != nil {
+		return err
+	}
+
+	tmpl := t
+	if name != t.Name() {
+		tmpl = t.New(name)
+	}
+
+	if _, err := tmpl.Parse(src); err != nil {
+		return err
+	}
+
+	if a.debug != nil {
+		return a.debug.annotate(t, name, filePath)
+	}
+	return nil
+}
+
+// PreprocessTemplate applies the source transformations supported by the template adapter, such as component and
+// cache blocks, to the source of the template file at filePath. The result is plain html/template syntax, so tools
+// can parse templates with text/template/parse the same way the adapter does.
+func PreprocessTemplate(filePath, src string) (string, error) {
+	return preprocessTemplate(src, filePath)
+}
+
+// PreprocessTemplateLines is like PreprocessTemplate, and also returns the function mapping the lines of the result
+// to the lines of src. The content of component, slot and cache blocks is moved to the end of the result, so tools
+// reporting lines, such as linters, map them back to the lines of the file.
+func PreprocessTemplateLines(filePath, src string) (string, func(line int) int, error) {
+	processed, components, err := preprocessComponents(src, filePath)
+	if err != nil {
+		return "", nil, err
+	}
+	processed, caches, err := preprocessCacheBlocks(processed, filePath)
+	if err != nil {
+		return "", nil, err
+	}
+	return processed, func(line int) int { return components.srcLine(caches.srcLine(line)) }, nil
+}
+
+// preprocessTemplate rewrites the component blocks, then the cache blocks, of the source of the template file at
+// filePath, prefixed with the ID of its file system unless it is the root file system (see templateFileKey).
+func preprocessTemplate(src, filePath string) (string, error) {
+	src, _, err := preprocessComponents(src, filePath)
+	if err != nil {
+		return "", err
+	}
+	src, _, err = preprocessCacheBlocks(src, filePath)
+	return src, err
+}
+
+// hoister collects the template definitions hoisted to the end of a preprocessed source, out of the blocks of the
+// source, along with the lines of the source their content comes from.
+type hoister struct {
+	defs  strings.Builder
+	line  int // line of the preprocessed source the definitions written so far end on
+	lines lineMap
+}
+
+// newHoister returns a hoister of the definitions of src. The blocks of src are replaced by actions padded with
+// newlines, so the definitions start on the last line of src.
+func newHoister(src string) *hoister {
+	return &hoister{line: strings.Count(src, "\n") + 1}
+}
+
+// define hoists the definition of the template named id, whose lines map to those of the source with lines. Each
+// definition starts on a line of its own, with a newline inside its define action, so the lines of its content are
+// those of lines shifted by the line the definition starts on.
+func (h *hoister) define(id, content string, lines lineMap, rightTrim, leftTrim bool) {
+	h.defs.WriteString("{{\ndefine " + strconv.Quote(id))
+	h.line++
+	for _, segment := range lines {
+		h.lines = append(h.lines, lineSegment{line: h.line + segment.line - 1, srcLine: segment.srcLine})
+	}
+	if rightTrim {
+		h.defs.WriteString(" -")
+	}
+	h.defs.WriteString("}}" + content + "{{")
+	if leftTrim {
+		h.defs.WriteString("- ")
+	}
+	h.defs.WriteString("end}}")
+	h.line += strings.Count(content, "\n")
+}
+
+// lineMap maps the lines of a preprocessed source to the lines of the source. Each segment maps a line of the
+// preprocessed source, and the lines following it one to one, up to the next segment. The lines before the first
+// segment are those of the source.
+type lineMap []lineSegment
+
+// lineSegment is a segment of a lineMap.
+type lineSegment struct {
+	line    int // line of the preprocessed source
+	srcLine int // line of the source
+}
+
+// srcLine returns the line of the source of the line of the preprocessed source.
+func (m lineMap) srcLine(line int) int {
+	srcLine := line
+	for _, segment := range m {
+		if segment.line > line {
+			break
+		}
+		srcLine = segment.srcLine + line - segment.line
+	}
+	return srcLine
+}
+
+// templateAction is a single {{ }} action found in a template source.
+type templateAction struct {
+	start     int    // offset of the opening delimiter
+	end       int    // offset just after the closing delimiter
+	leftTrim  bool   // the action starts with a "{{- " trim marker
+	rightTrim bool   // the action ends with a " -}}" trim marker
+	body      string // the action text without delimiters, trim markers and surrounding spaces
+}
+
+// keyword returns the first word of the action, which identifies control structures such as "if" or "end".
+func (act templateAction) keyword() string {
+	fields := strings.Fields(act.body)
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
+
+// args returns the action text following the keyword.
+func (act templateAction) args() string {
+	return strings.TrimSpace(strings.TrimPrefix(act.body, act.keyword()))
+}
+
+// nextAction finds the first action in src at or after offset from. It returns false if there are no more actions.
+// Quoted strings, raw strings, character constants and comments are skipped, so delimiters inside them are not
+// mistaken for the end of the action.
+func nextAction(src string, from int) (templateAction, bool, error) {
+	idx := strings.Index(src[from:], "{{")
+	if idx == -1 {
+		return templateAction{}, false, nil
+	}
+
+	act := templateAction{start: from + idx}
+	i := act.start + 2
+	if strings.HasPrefix(src[i:], "- ") {
+		act.leftTrim = true
+		i += 2
+	}
+	bodyStart := i
+
+	for i < len(src) {
+		switch {
+		case strings.HasPrefix(src[i:], "/*"):
+			closing := strings.Index(src[i+2:], "*/")
+			if closing == -1 {
+				return act, false, fmt.Errorf("unclosed comment at line %d", lineAt(src, act.start))
+			}
+			i += closing + 4
+		case src[i] == '"' || src[i] == '\'':
+			quote := src[i]
+			i++
+			for i < len(src) && src[i] != quote {
+				if src[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			i++
+		case src[i] == '`':
+			closing := strings.IndexByte(src[i+1:], '`')
+			if closing == -1 {
+				return act, false, fmt.Errorf("unterminated raw string at line %d", lineAt(src, act.start))
+			}
+			i += closing + 2
+		case strings.HasPrefix(src[i:], "}}"):
+			bodyEnd := i
+			if i-2 >= bodyStart && src[i-1] == '-' && isSpace(src[i-2]) {
+				act.rightTrim = true
+				bodyEnd -= 2
+			}
+			act.end = i + 2
+			act.body = strings.TrimSpace(src[bodyStart:bodyEnd])
+			return act, true, nil
+		default:
+			i++
+		}
+	}
+
+	return act, false, fmt.Errorf("unclosed action at line %d", lineAt(src, act.start))
+}
+
+// lineAt returns the 1-based line number of the given offset in src.
+func lineAt(src string, offset int) int {
+	return strings.Count(src[:offset], "\n") + 1
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
@@ -0,0 +1,90 @@
+package hyperview
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"text/template/parse"
+
+	"github.com/hypergopher/hyperview/constants"
+)
+
+// partialName returns the name of the partial holding the content of the partial file outside of its definitions: its
+// path relative to the partials directory, without the extension.
+func partialName(filePath, extension string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(filePath, constants.PartialsDir+"/"), extension)
+}
+
+// isEmptyTemplate reports whether the template renders nothing but whitespace, e.g. the content of a file outside of
+// its definitions.
+func isEmptyTemplate(t *template.Template) bool {
+	return t.Tree == nil || parse.IsEmptyTree(t.Tree.Root)
+}
+
+// checkPartialCollisions returns an error if the partial file redefined a partial of another partial file, given the
+// trees of the common templates before the file was parsed. Partials can override the blocks of layouts and the
+// built-in partials, but partials with the same name in two files, such as the partials of two file systems, would
+// otherwise depend on the order the files are loaded in.
+func (a *TemplateAdapter) checkPartialCollisions(t *template.Template, before map[string]*parse.Tree, file string) error {
+	for _, tmpl := range t.Templates() {
+		tree, ok := before[tmpl.Name()]
+		if !ok || tree == tmpl.Tree || tree == nil || parse.IsEmptyTree(tree.Root) {
+			continue
+		}
+		if previous, ok := a.templateFiles[tmpl.Name()]; ok && !isLayoutFile(previous) {
+			return fmt.Errorf("partial %s is defined in both %s and %s", tmpl.Name(), previous, file)
+		}
+	}
+	return nil
+}
+
+// Partials returns the names of the templates defined in the partials directories, sorted by name. This includes
+// components, which are partials too.
+func (a *TemplateAdapter) Partials() []string {
+	if !a.frozen.Load() {
+		a.initMu.RLock()
+		defer a.initMu.RUnlock()
+	}
+
+	partials := append([]string(nil), a.partials...)
+	sort.Strings(partials)
+	return partials
+}
+
+// RenderPartial renders the named partial in isolation with data, without a page or layout. Components expect a
+// *ComponentData value. This is meant for tools such as the gallery package, and takes a lock.
+func (a *TemplateAdapter) RenderPartial(w io.Writer, name string, data any) error {
+	a.mu.Lock()
+	if a.partialSet == nil {
+		// The common templates are cloned for each page, so they must never be executed themselves
+		clone, err := a.common.Clone()
+		if err != nil {
+			a.mu.Unlock()
+			return fmt.Errorf("error cloning template: %w", err)
+		}
+		a.partialSet = clone.Funcs(a.templateFuncs(clone))
+	}
+	tmpl := a.partialSet
+	a.logDeprecatedRender(name)
+	a.mu.Unlock()
+
+	if tmpl.Lookup(name) == nil {
+		return fmt.Errorf("partial not found: %s", name)
+	}
+
+	if a.debug == nil {
+		return tmpl.ExecuteTemplate(w, name, data)
+	}
+
+	// The tokens marking the templates are replaced with comments once the partial is complete
+	buf := new(bytes.Buffer)
+	if err := tmpl.ExecuteTemplate(buf, name, data); err != nil {
+		return err
+	}
+	body, _ := a.debugBody(buf.Bytes())
+	_, err := w.Write(body)
+	return err
+}
@@ -0,0 +1,95 @@
+package hyperview_test
+
+import (
+	"io/fs"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func TestTemplateAdapter_NestedPartials(t *testing.T) {
+	adapter := newTestTemplateAdapter(t, fstest.MapFS{
+		"layouts/base.html":          {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+		"partials/forms/input.html":  {Data: []byte(`<input name="{{.}}">`)},
+		"partials/search/input.html": {Data: []byte(`<input type="search" name="{{.}}">`)},
+		"partials/nav/sidebar.html":  {Data: []byte(`{{define "@nav/sidebar"}}<aside></aside>{{end}}`)},
+		"views/home.html":            {Data: []byte(`{{define "page:main"}}{{template "forms/input" "q"}}{{template "search/input" "s"}}{{template "@nav/sidebar"}}{{end}}`)},
+	}, hyperview.TemplateViewAdapterOptions{})
+
+	w := renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("views/home"))
+	if got, want := w.Body.String(), `<input name="q"><input type="search" name="s"><aside></aside>`; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	// Files holding definitions only are not partials themselves
+	if got, want := adapter.Partials(), []string{"@nav/sidebar", "forms/input", "search/input"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected partials %v, got %v", want, got)
+	}
+}
+
+func TestTemplateAdapter_PartialCollisions(t *testing.T) {
+	layout := &fstest.MapFile{Data: []byte(`{{define "layout:base"}}{{block "title" .}}Site{{end}}{{template "page:main" .}}{{end}}`)}
+
+	tests := []struct {
+		name    string
+		root    fstest.MapFS
+		acme    fstest.MapFS
+		wantErr string
+	}{
+		{
+			name:    "same partial in two file systems",
+			root:    fstest.MapFS{"partials/card.html": {Data: []byte(`{{define "@card"}}root{{end}}`)}},
+			acme:    fstest.MapFS{"partials/card.html": {Data: []byte(`{{define "@card"}}acme{{end}}`)}},
+			wantErr: "partial @card is defined in both partials/card.html and acme:partials/card.html",
+		},
+		{
+			name: "same partial in two files",
+			root: fstest.MapFS{
+				"partials/a.html": {Data: []byte(`{{define "@card"}}a{{end}}`)},
+				"partials/b.html": {Data: []byte(`{{define "@card"}}b{{end}}`)},
+			},
+			wantErr: "partial @card is defined in both partials/a.html and partials/b.html",
+		},
+		{
+			name:    "same file content in two file systems",
+			root:    fstest.MapFS{"partials/forms/input.html": {Data: []byte(`<input>`)}},
+			acme:    fstest.MapFS{"partials/forms/input.html": {Data: []byte(`<input class="acme">`)}},
+			wantErr: "partial forms/input is defined in both partials/forms/input.html and acme:partials/forms/input.html",
+		},
+		{
+			name: "definitions only in two file systems",
+			root: fstest.MapFS{"partials/card.html": {Data: []byte("{{define \"@card\"}}root{{end}}\n")}},
+			acme: fstest.MapFS{"partials/card.html": {Data: []byte("{{define \"@acme/card\"}}acme{{end}}\n")}},
+		},
+		{
+			name: "layout block and built-in partial",
+			root: fstest.MapFS{
+				"partials/title.html":      {Data: []byte(`{{define "title"}}Title{{end}}`)},
+				"partials/pagination.html": {Data: []byte(`{{define "@pagination"}}pages{{end}}`)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.root["layouts/base.html"] = layout
+			fsMap := map[string]fs.FS{constants.RootFSID: tt.root}
+			if tt.acme != nil {
+				fsMap["acme"] = tt.acme
+			}
+
+			err := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{FileSystemMap: fsMap}).Init()
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("expected error %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package hyperview
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+)
+
+// PathCase is the case policy of template names, set with TemplateViewAdapterOptions.PathCase.
+type PathCase int
+
+const (
+	// PreserveCase names the views after their paths as they are, so lookups are case-sensitive. This is the default.
+	PreserveCase PathCase = iota
+	// LowerCase names the views after their lowercased paths, and lowercases the paths looked up, so lookups are
+	// case-insensitive. This matches the case-insensitive file systems of Windows and macOS, where views/Home.html and
+	// views/home.html are the same file.
+	LowerCase
+	// FoldCase names the views after their paths as they are, like PreserveCase, but looks up the paths matching no
+	// view exactly ignoring case, so Path("Users/Edit") renders views/users/edit. Views whose paths differ only by case
+	// fail Init.
+	FoldCase
+)
+
+// normalizePath returns the slash-separated, cleaned form of a template path, whatever the platform it was built on:
+// views\home\index becomes views/home/index. Leading slashes and ./ elements are removed, as fs.FS paths are
+// unrooted.
+func normalizePath(p string) string {
+	p = path.Clean(strings.ReplaceAll(p, `\`, "/"))
+	p = strings.TrimLeft(p, "/")
+	if p == "." {
+		return ""
+	}
+	return p
+}
+
+// normalizeName returns the normalized form of a view name, e.g. a path set with Response.Path: its path is
+// normalized and follows the case policy of the adapter, while its fsID: prefix, if any, is kept as is, as file system
+// IDs are not paths.
+func (c *templateConfig) normalizeName(name string) string {
+	fsID, rest, prefixed := cutFSID(name)
+	p := normalizePath(rest)
+	if c.pathCase == LowerCase {
+		p = strings.ToLower(p)
+	}
+	switch {
+	case !prefixed:
+		return p
+	case p == rest:
+		return name
+	}
+	return fsID + ":" + p
+}
+
+// viewName returns the name of the view at the path of a file system: its normalized path without the extension,
+// prefixed with the ID of its file system unless it is the root file system.
+func (c *templateConfig) viewName(fsID, filePath string) string {
+	return c.normalizeName(templateFileKey(fsID, strings.TrimSuffix(filePath, path.Ext(filePath))))
+}
+
+// hasExtension reports whether the file at the path is a template, ignoring the case of the extension under the
+// LowerCase policy.
+func (c *templateConfig) hasExtension(filePath string) bool {
+	if c.pathCase == LowerCase {
+		return strings.EqualFold(path.Ext(filePath), c.extension)
+	}
+	return path.Ext(filePath) == c.extension
+}
+
+// DefaultViewFallbacks are the default view fallbacks of the template adapter: a path matching no view renders the
+// index view of the directory of the same name.
+var DefaultViewFallbacks = []string{"/index"}
+
+// viewFallbacks returns the view fallbacks of the options, or the default ones if they are nil.
+func viewFallbacks(fallbacks []string) []string {
+	if fallbacks == nil {
+		return DefaultViewFallbacks
+	}
+	return fallbacks
+}
+
+// fallbackPage returns the page resolved for the tenant of the request: the page itself if it exists, or else the
+// first of the view fallbacks that exists, e.g. views/users/index for views/users. Pages matching no view are
+// returned as is, for the lookup to report them.
+func (a *TemplateAdapter) fallbackPage(r *http.Request, pageName string) string {
+	pageName = a.lookupPage(pageName)
+	resolved := a.tenantPage(r, pageName)
+	if _, ok := a.pages[resolved]; ok {
+		return resolved
+	}
+
+	for _, suffix := range a.viewFallbacks {
+		candidate := a.tenantPage(r, a.lookupPage(a.normalizeName(pageName+suffix)))
+		if _, ok := a.pages[candidate]; ok {
+			return candidate
+		}
+	}
+	return resolved
+}
+
+// fallbackView returns the view rendered for a view name, ignoring the tenant, variant and locale of the requests: the
+// view it refers to, or else the first of its view fallbacks that exists. It reports whether the view exists.
+func (a *TemplateAdapter) fallbackView(view string) (string, bool) {
+	resolved := a.lookupPage(view)
+	if _, ok := a.pages[resolved]; ok {
+		return resolved, true
+	}
+	for _, suffix := range a.viewFallbacks {
+		candidate := a.lookupPage(a.normalizeName(view + suffix))
+		if _, ok := a.pages[candidate]; ok {
+			return candidate, true
+		}
+	}
+	return view, false
+}
+
+// lookupPage returns the view a normalized view name refers to: the view itself if it exists, or else the view it is
+// an alias of, or the view matching it ignoring case under the FoldCase policy. Names matching no view are returned
+// as is.
+func (a *TemplateAdapter) lookupPage(pageName string) string {
+	if _, ok := a.pages[pageName]; ok {
+		return pageName
+	}
+	if target, ok := a.aliases[pageName]; ok {
+		return target
+	}
+	if a.pathCase == FoldCase {
+		if folded, ok := a.foldedPages[strings.ToLower(pageName)]; ok {
+			return folded
+		}
+	}
+	return pageName
+}
+
+// resolveViewAliases resolves the aliases of the ViewAliases option, and the views looked up ignoring case under the
+// FoldCase policy, once the views are loaded. Aliases must not shadow a view, and must name an existing view.
+func (a *TemplateAdapter) resolveViewAliases() error {
+	a.aliases = make(map[string]string, len(a.viewAliases))
+	for alias, target := range a.viewAliases {
+		alias, target = a.viewKey(alias), a.viewKey(target)
+		if _, ok := a.pages[alias]; ok {
+			return fmt.Errorf("view alias %s shadows the view of the same name", alias)
+		}
+		a.aliases[alias] = target
+	}
+
+	a.foldedPages = nil
+	if a.pathCase == FoldCase {
+		if err := a.foldPages(); err != nil {
+			return err
+		}
+	}
+
+	for alias, target := range a.aliases {
+		if _, ok := a.aliases[target]; ok {
+			return fmt.Errorf("view alias %s: %s is an alias too", alias, target)
+		}
+		if !a.hasView(target) {
+			return fmt.Errorf("view alias %s: view %s not found", alias, target)
+		}
+	}
+	return nil
+}
+
+// foldPages indexes the views and aliases by lowercased name, for the lookups of the FoldCase policy.
+func (a *TemplateAdapter) foldPages() error {
+	names := make([]string, 0, len(a.pages)+len(a.aliases))
+	for name := range a.pages {
+		names = append(names, name)
+	}
+	for alias := range a.aliases {
+		names = append(names, alias)
+	}
+	sort.Strings(names)
+
+	a.foldedPages = make(map[string]string, len(names))
+	seen := make(map[string]string, len(names))
+	for _, name := range names {
+		folded := strings.ToLower(name)
+		if other, ok := seen[folded]; ok {
+			return fmt.Errorf("views %s and %s differ only by case", other, name)
+		}
+		seen[folded] = name
+		if target, ok := a.aliases[name]; ok {
+			a.foldedPages[folded] = target
+		} else {
+			a.foldedPages[folded] = name
+		}
+	}
+	return nil
+}
+
+// ViewAliases returns the resolved aliases of the ViewAliases option, mapping each alias to the view it renders, e.g.
+// views/users/edit to views/users/update.
+func (a *TemplateAdapter) ViewAliases() map[string]string {
+	if !a.frozen.Load() {
+		a.initMu.RLock()
+		defer a.initMu.RUnlock()
+	}
+	return maps.Clone(a.aliases)
+}
+
+// ViewName returns the name of the view at the path of a file system, e.g. views/docs/intro for views/docs/intro.md of
+// the root file system, following the case policy of the adapter. Adapters rendering other files in the layouts of
+// the template adapter, such as Markdown files, name their pages with it, so they are looked up like views.
+func (a *TemplateAdapter) ViewName(fsID, filePath string) string {
+	return a.viewName(fsID, filePath)
+}
+
+// NormalizeName returns the normalized form of a view name, e.g. a path set with Response.Path, as looked up by the
+// adapter: its path cleaned and following the case policy of the adapter.
+func (a *TemplateAdapter) NormalizeName(name string) string {
+	return a.normalizeName(name)
+}
@@ -0,0 +1,246 @@
+package hyperview_test
+
+import (
+	"io"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func pathTestAdapter(t *testing.T, pathCase hyperview.PathCase) *hyperview.TemplateAdapter {
+	t.Helper()
+
+	layout := &fstest.MapFile{Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)}
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{
+			constants.RootFSID: fstest.MapFS{
+				"layouts/base.html":       layout,
+				"views/Home/Index.html":   {Data: []byte(`{{define "layout"}}wide{{end}}{{define "page:main"}}home{{end}}`)},
+				"views/docs/Guide.HTML":   {Data: []byte(`{{define "page:main"}}guide{{end}}`)},
+				"views/system/about.html": {Data: []byte(`{{define "page:main"}}about{{end}}`)},
+			},
+			"acme": fstest.MapFS{
+				"layouts/base.html":  layout,
+				"views/Pricing.html": {Data: []byte(`{{define "page:main"}}acme pricing{{end}}`)},
+			},
+		},
+		PathCase: pathCase,
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+	return adapter
+}
+
+func TestTemplateAdapter_PathNormalization(t *testing.T) {
+	tests := []struct {
+		name     string
+		pathCase hyperview.PathCase
+		path     string
+		want     string // empty if the view is not found
+	}{
+		{name: "slash-separated", path: "views/Home/Index", want: "home"},
+		{name: "backslash-separated", path: `views\Home\Index`, want: "home"},
+		{name: "mixed separators", path: `views\system/about`, want: "about"},
+		{name: "rooted and dotted", path: "/views/./system//about", want: "about"},
+		{name: "fsID prefix with backslashes", path: `acme:views\Pricing`, want: "acme pricing"},
+		{name: "case preserved", path: "views/home/index"},
+		{name: "extension case preserved", path: "views/docs/Guide"},
+		{name: "lowercase", pathCase: hyperview.LowerCase, path: "views/home/index", want: "home"},
+		{name: "lowercase with other case", pathCase: hyperview.LowerCase, path: `views\HOME\index`, want: "home"},
+		{name: "lowercase extension", pathCase: hyperview.LowerCase, path: "views/docs/guide", want: "guide"},
+		{name: "lowercase keeps the fsID", pathCase: hyperview.LowerCase, path: "acme:views/PRICING", want: "acme pricing"},
+		{name: "fold case", pathCase: hyperview.FoldCase, path: "views/home/index", want: "home"},
+		{name: "fold case with exact case", pathCase: hyperview.FoldCase, path: "views/Home/Index", want: "home"},
+		{name: "fold case with backslashes", pathCase: hyperview.FoldCase, path: `views\HOME\index`, want: "home"},
+		{name: "fold case of a file system", pathCase: hyperview.FoldCase, path: "acme:views/pricing", want: "acme pricing"},
+		{name: "fold case extension preserved", pathCase: hyperview.FoldCase, path: "views/docs/guide"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := pathTestAdapter(t, tt.pathCase)
+
+			if layout, _ := adapter.DeclaredLayout(tt.path); (layout == "wide") != (tt.want == "home") {
+				t.Errorf("unexpected layout declared by %q: %q", tt.path, layout)
+			}
+
+			w := renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path(tt.path))
+			if tt.want == "" {
+				if w.Code == http.StatusOK {
+					t.Errorf("expected %q not to be found, got %q", tt.path, w.Body.String())
+				}
+				return
+			}
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTemplateAdapter_ViewFallbacks(t *testing.T) {
+	tests := []struct {
+		name      string
+		fallbacks []string
+		path      string
+		want      string // empty if the view is not found
+	}{
+		{name: "exact match", path: "users", want: "users"},
+		{name: "index of a directory", path: "teams", want: "teams index"},
+		{name: "trailing slash", path: "teams/", want: "teams index"},
+		{name: "root index", path: "", want: "home"},
+		{name: "file system prefix", path: "acme:docs", want: "acme docs"},
+		{name: "exact match over the index", path: "users/index", want: "users index"},
+		{name: "no fallback", path: "projects"},
+		{name: "custom fallbacks", fallbacks: []string{"/index", "/list"}, path: "projects", want: "projects list"},
+		{name: "fallbacks in order", fallbacks: []string{"/list", "/index"}, path: "teams", want: "teams index"},
+		{name: "disabled", fallbacks: []string{}, path: "teams/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layout := &fstest.MapFile{Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)}
+			adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+				FileSystemMap: map[string]fs.FS{
+					constants.RootFSID: fstest.MapFS{
+						"layouts/base.html":        layout,
+						"views/index.html":         {Data: []byte(`{{define "page:main"}}home{{end}}`)},
+						"views/users.html":         {Data: []byte(`{{define "page:main"}}users{{end}}`)},
+						"views/users/index.html":   {Data: []byte(`{{define "page:main"}}users index{{end}}`)},
+						"views/teams/index.html":   {Data: []byte(`{{define "page:main"}}teams index{{end}}`)},
+						"views/projects/list.html": {Data: []byte(`{{define "page:main"}}projects list{{end}}`)},
+					},
+					"acme": fstest.MapFS{
+						"layouts/base.html":     layout,
+						"views/docs/index.html": {Data: []byte(`{{define "page:main"}}acme docs{{end}}`)},
+					},
+				},
+				ViewFallbacks: tt.fallbacks,
+				Logger:        slog.New(slog.NewTextHandler(io.Discard, nil)),
+			})
+			if err := adapter.Init(); err != nil {
+				t.Fatalf("error initializing adapter: %v", err)
+			}
+
+			w := renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path(tt.path))
+			if tt.want == "" {
+				if w.Code == http.StatusOK {
+					t.Errorf("expected %q not to be found, got %q", tt.path, w.Body.String())
+				}
+				return
+			}
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTemplateAdapter_ViewAliases(t *testing.T) {
+	tests := []struct {
+		name     string
+		aliases  map[string]string
+		pathCase hyperview.PathCase
+		path     string
+		want     string // empty if the view is not found
+		initErr  string // non-empty if Init fails
+	}{
+		{name: "alias", aliases: map[string]string{"users/edit": "users/update"}, path: "users/edit", want: "update"},
+		{name: "target", aliases: map[string]string{"users/edit": "users/update"}, path: "users/update", want: "update"},
+		{name: "normalized", aliases: map[string]string{`/views\users\edit`: "views/users/update"}, path: "users/edit", want: "update"},
+		{name: "fallback target", aliases: map[string]string{"people": "users"}, path: "people", want: "users index"},
+		{name: "case preserved", aliases: map[string]string{"users/edit": "users/update"}, path: "Users/Edit"},
+		{name: "fold case", aliases: map[string]string{"users/edit": "users/update"}, pathCase: hyperview.FoldCase, path: "Users/Edit", want: "update"},
+		{name: "shadowing a view", aliases: map[string]string{"users/update": "users/index"}, initErr: "view alias views/users/update shadows"},
+		{name: "missing target", aliases: map[string]string{"users/edit": "users/missing"}, initErr: "view views/users/missing not found"},
+		{name: "alias of an alias", aliases: map[string]string{"a": "b", "b": "users/update"}, initErr: "views/b is an alias too"},
+		{name: "fold case collision", aliases: map[string]string{"Users/Update": "users/index"}, pathCase: hyperview.FoldCase, initErr: "differ only by case"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+				FileSystemMap: map[string]fs.FS{
+					constants.RootFSID: fstest.MapFS{
+						"layouts/base.html":       {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+						"views/users/index.html":  {Data: []byte(`{{define "page:main"}}users index{{end}}`)},
+						"views/users/update.html": {Data: []byte(`{{define "page:main"}}update{{end}}`)},
+					},
+				},
+				ViewAliases: tt.aliases,
+				PathCase:    tt.pathCase,
+				Logger:      slog.New(slog.NewTextHandler(io.Discard, nil)),
+			})
+			err := adapter.Init()
+			if tt.initErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.initErr) {
+					t.Fatalf("expected Init error containing %q, got %v", tt.initErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error initializing adapter: %v", err)
+			}
+
+			w := renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path(tt.path))
+			if tt.want == "" {
+				if w.Code == http.StatusOK {
+					t.Errorf("expected %q not to be found, got %q", tt.path, w.Body.String())
+				}
+				return
+			}
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTemplateAdapter_ViewAliasesInventory(t *testing.T) {
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{
+			constants.RootFSID: fstest.MapFS{
+				"views/users/index.html":  {Data: []byte(`users`)},
+				"views/users/update.html": {Data: []byte(`update`)},
+			},
+		},
+		ViewAliases: map[string]string{"users/edit": "users/update", "users/modify": "users/update", "people": "users"},
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+
+	want := map[string]string{
+		"views/users/edit":   "views/users/update",
+		"views/users/modify": "views/users/update",
+		"views/people":       "views/users",
+	}
+	if got := adapter.ViewAliases(); !maps.Equal(got, want) {
+		t.Errorf("expected aliases %v, got %v", want, got)
+	}
+
+	inventory, err := adapter.Inventory()
+	if err != nil {
+		t.Fatalf("error getting inventory: %v", err)
+	}
+	aliases := make(map[string][]string)
+	for _, view := range inventory.FileSystems[0].Views {
+		aliases[view.Name] = view.Aliases
+	}
+	if got := aliases["views/users/update"]; !slices.Equal(got, []string{"views/users/edit", "views/users/modify"}) {
+		t.Errorf("unexpected aliases of views/users/update: %v", got)
+	}
+	if got := aliases["views/users/index"]; !slices.Equal(got, []string{"views/people"}) {
+		t.Errorf("unexpected aliases of views/users/index: %v", got)
+	}
+}
@@ -0,0 +1,84 @@
+package hyperview
+
+import (
+	"fmt"
+	"html/template"
+	"io/fs"
+)
+
+// ViewProvider is a reusable feature package, such as authentication screens or an admin UI, shipping its templates
+// and the functions they call as a unit. Its file system is laid out like the other file systems of the adapter, with
+// layouts, partials and views directories, and is mounted under the provider's ID, so its views are rendered with
+// paths such as admin:users.
+type ViewProvider interface {
+	// FSID returns the ID of the file system of the provider, e.g. "admin".
+	FSID() string
+	// Views returns the file system holding the templates of the provider.
+	Views() fs.FS
+	// Funcs returns the functions called by the templates of the provider, if any. They are added to the function map
+	// of the adapter, so their names must not clash with the other functions: prefix them with the ID of the provider,
+	// e.g. adminUserRole.
+	Funcs() template.FuncMap
+}
+
+// Mount mounts the file systems of the view providers, like AddFS, and adds their functions to the function map of
+// the adapter, in a single reload. It returns an error, mounting none of the providers, if an ID is already mounted
+// or a function name is already used by the adapter or another provider. The functions stay registered once the file
+// system of a provider is removed with RemoveFS, as the remaining templates may call them, and a provider can be
+// mounted again.
+func (a *TemplateAdapter) Mount(providers ...ViewProvider) error {
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+
+	if a.frozen.Load() {
+		panic("hyperview: Mount called on a frozen TemplateAdapter")
+	}
+
+	config := a.templateConfig
+	config.fileSystemMap = make(map[string]fs.FS, len(a.fileSystemMap)+len(providers))
+	for fsID, mounted := range a.fileSystemMap {
+		config.fileSystemMap[fsID] = mounted
+	}
+	config.funcMap = make(template.FuncMap, len(a.funcMap))
+	for name, fn := range a.funcMap {
+		config.funcMap[name] = fn
+	}
+	config.providerFuncs = make(map[string]string, len(a.providerFuncs))
+	for name, id := range a.providerFuncs {
+		config.providerFuncs[name] = id
+	}
+
+	for _, provider := range providers {
+		id := provider.FSID()
+		if _, ok := config.fileSystemMap[id]; ok {
+			return fmt.Errorf("view provider %s: file system %s is already mounted", id, id)
+		}
+		if _, ok := a.loaders[id]; ok {
+			return fmt.Errorf("view provider %s: file system ID is already used by a loader", id)
+		}
+		config.fileSystemMap[id] = provider.Views()
+
+		for name, fn := range provider.Funcs() {
+			if owner, ok := config.providerFuncs[name]; ok && owner != id {
+				return fmt.Errorf("view provider %s: function %s is already added by view provider %s", id, name, owner)
+			}
+			if _, ok := config.providerFuncs[name]; !ok && a.hasFunc(name) {
+				return fmt.Errorf("view provider %s: function %s is already defined", id, name)
+			}
+			config.funcMap[name] = fn
+			config.providerFuncs[name] = id
+		}
+	}
+
+	return a.mount(config)
+}
+
+// hasFunc reports whether templates can call the function, as one of the functions of the adapter.
+func (a *TemplateAdapter) hasFunc(name string) bool {
+	for _, funcs := range []template.FuncMap{a.adapterFuncs(), a.funcMap, a.memoFuncs, a.requestFuncs, a.templateFuncs(nil)} {
+		if _, ok := funcs[name]; ok {
+			return true
+		}
+	}
+	return false
+}
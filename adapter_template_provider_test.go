@@ -0,0 +1,93 @@
+package hyperview_test
+
+import (
+	"html/template"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview/response"
+)
+
+// testProvider is a view provider shipping an admin UI.
+type testProvider struct {
+	id    string
+	funcs template.FuncMap
+}
+
+func (p testProvider) FSID() string { return p.id }
+
+func (p testProvider) Views() fs.FS {
+	return fstest.MapFS{
+		"partials/admin/badge.html": {Data: []byte(`<span>{{adminRole .}}</span>`)},
+		"views/users.html":          {Data: []byte(`{{define "page:main"}}{{template "admin/badge" "owner"}}{{end}}`)},
+	}
+}
+
+func (p testProvider) Funcs() template.FuncMap { return p.funcs }
+
+func TestTemplateAdapter_Mount(t *testing.T) {
+	adapter := mountTestAdapter(t)
+	admin := testProvider{id: "admin", funcs: template.FuncMap{"adminRole": strings.ToUpper}}
+
+	if err := adapter.Mount(admin); err != nil {
+		t.Fatalf("error mounting view provider: %v", err)
+	}
+
+	w := renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("admin:views/users"))
+	if got, want := w.Body.String(), "<main><span>OWNER</span></main>"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	// The provider can be mounted again once removed, with its functions still registered
+	if err := adapter.RemoveFS("admin"); err != nil {
+		t.Fatalf("error removing file system: %v", err)
+	}
+	if err := adapter.Mount(admin); err != nil {
+		t.Errorf("error mounting the view provider again: %v", err)
+	}
+}
+
+func TestTemplateAdapter_MountConflicts(t *testing.T) {
+	tests := []struct {
+		name      string
+		providers []testProvider
+		wantErr   string
+	}{
+		{
+			name:      "mounted file system",
+			providers: []testProvider{{id: "admin", funcs: template.FuncMap{"adminRole": strings.ToUpper}}, {id: "admin"}},
+			wantErr:   "file system admin is already mounted",
+		},
+		{
+			name:      "adapter function",
+			providers: []testProvider{{id: "admin", funcs: template.FuncMap{"adminRole": strings.ToUpper, "upper": strings.ToUpper}}},
+			wantErr:   "function upper is already defined",
+		},
+		{
+			name: "function of another provider",
+			providers: []testProvider{
+				{id: "admin", funcs: template.FuncMap{"adminRole": strings.ToUpper}},
+				{id: "billing", funcs: template.FuncMap{"adminRole": strings.ToLower}},
+			},
+			wantErr: "function adminRole is already added by view provider admin",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := mountTestAdapter(t)
+
+			var err error
+			for _, provider := range tt.providers {
+				if err = adapter.Mount(provider); err != nil {
+					break
+				}
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
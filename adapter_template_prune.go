@@ -0,0 +1,165 @@
+package hyperview
+
+import (
+	"html/template"
+	"strings"
+	"text/template/parse"
+)
+
+// templateDeps describes the templates rendered by a template.
+type templateDeps struct {
+	refs    []string // names of the templates rendered
+	dynamic bool     // renders templates whose name is only known at render time
+}
+
+// treeDeps returns the templates rendered by the tree: with the template action, and with the renderComponent and
+// cachedTemplate functions, whose names are usually literals.
+func treeDeps(tree *parse.Tree) templateDeps {
+	var deps templateDeps
+	if tree == nil {
+		return deps
+	}
+
+	literal := func(args []parse.Node, i int) (string, bool) {
+		if i >= len(args) {
+			return "", false
+		}
+		s, ok := args[i].(*parse.StringNode)
+		if !ok {
+			return "", false
+		}
+		return s.Text, true
+	}
+
+	walkNodes(tree.Root, func(node parse.Node) {
+		switch n := node.(type) {
+		case *parse.TemplateNode:
+			deps.refs = append(deps.refs, n.Name)
+		case *parse.CommandNode:
+			if len(n.Args) == 0 {
+				return
+			}
+			ident, ok := n.Args[0].(*parse.IdentifierNode)
+			if !ok {
+				return
+			}
+			switch ident.Ident {
+			case "renderComponent":
+				// renderComponent id slots dot name props, rendering the slots as id:slot
+				id, okID := literal(n.Args, 1)
+				slots, okSlots := literal(n.Args, 2)
+				name, okName := literal(n.Args, 4)
+				if !okID || !okSlots || !okName {
+					deps.dynamic = true
+					return
+				}
+				deps.refs = append(deps.refs, name)
+				for _, slot := range strings.Fields(slots) {
+					deps.refs = append(deps.refs, id+":"+slot)
+				}
+			case "cachedTemplate":
+				// cachedTemplate key ttl name data
+				name, ok := literal(n.Args, 3)
+				if !ok {
+					deps.dynamic = true
+					return
+				}
+				deps.refs = append(deps.refs, name)
+			case "deferred":
+				// deferred key data, rendering deferred:key, or its deferred:key:loading placeholder
+				key, ok := literal(n.Args, 1)
+				if !ok {
+					deps.dynamic = true
+					return
+				}
+				deps.refs = append(deps.refs, deferredPrefix+key, deferredPrefix+key+":loading")
+			}
+		}
+	})
+
+	return deps
+}
+
+// commonTemplateDeps returns the templates rendered by each of the common templates.
+func commonTemplateDeps(common *template.Template) map[string]templateDeps {
+	deps := make(map[string]templateDeps)
+	for _, t := range common.Templates() {
+		if t.Tree != nil {
+			deps[t.Name()] = treeDeps(t.Tree)
+		}
+	}
+	return deps
+}
+
+// reachableTemplates returns the common templates that the given references can render, directly or through other
+// common templates, along with the root layouts and the templates they can render. It reports whether all the common
+// templates can be rendered, because one of the references or the templates reached renders a template whose name is
+// only known at render time.
+func (a *TemplateAdapter) reachableTemplates(refs []string, dynamic bool) (map[string]bool, bool) {
+	queue := append([]string(nil), refs...)
+	for name := range a.commonDeps {
+		if strings.HasPrefix(name, "layout:") {
+			queue = append(queue, name)
+		}
+	}
+
+	reached := make(map[string]bool)
+	for len(queue) > 0 && !dynamic {
+		name := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+
+		deps, ok := a.commonDeps[name]
+		if !ok || reached[name] {
+			continue
+		}
+		reached[name] = true
+		dynamic = deps.dynamic
+		queue = append(queue, deps.refs...)
+	}
+
+	return reached, dynamic
+}
+
+// pageTemplateSet returns the template set to compile a page into: a clone of the common templates or, when template
+// sets are pruned, an empty set completed by addCommonTemplates once the page is parsed.
+func (a *TemplateAdapter) pageTemplateSet(strict bool) *template.Template {
+	if !a.pruneTemplateSets {
+		return template.Must(a.common.Clone()).Option(missingKeyOption(strict))
+	}
+	return a.newTemplateSet(strict)
+}
+
+// addCommonTemplates adds the common templates that the templates of the page set can render, directly or through
+// other common templates, along with the root layouts, which the page can be rendered with. Templates defined by the
+// page set override the common templates, as if the page was parsed after them. All the common templates are added
+// if one of them renders a template whose name is only known at render time. Nothing is added unless template sets
+// are pruned.
+func (a *TemplateAdapter) addCommonTemplates(tmpl *template.Template) error {
+	if !a.pruneTemplateSets {
+		return nil
+	}
+
+	var refs []string
+	dynamic := false
+	for _, t := range tmpl.Templates() {
+		deps := treeDeps(t.Tree)
+		dynamic = dynamic || deps.dynamic
+		refs = append(refs, deps.refs...)
+	}
+	needed, all := a.reachableTemplates(refs, dynamic)
+
+	for _, t := range a.common.Templates() {
+		if t.Tree == nil || !all && !needed[t.Name()] {
+			continue
+		}
+		if own := tmpl.Lookup(t.Name()); own != nil && own.Tree != nil && !parse.IsEmptyTree(own.Tree.Root) {
+			continue
+		}
+		// The tree is copied, as html/template rewrites the trees of a set when escaping it
+		if _, err := tmpl.AddParseTree(t.Name(), t.Tree.Copy()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
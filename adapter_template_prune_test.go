@@ -0,0 +1,79 @@
+package hyperview_test
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func TestTemplateAdapter_PruneTemplateSets(t *testing.T) {
+	files := fstest.MapFS{
+		"layouts/base.html": {Data: []byte(`{{define "layout:base"}}<title>{{block "title" .}}Site{{end}}</title>{{template "page:main" .}}{{end}}`)},
+		"layouts/docs.html": {Data: []byte(`{{/* extends "base" */}}{{define "title"}}Docs{{end}}`)},
+		"partials/card.html": {Data: []byte(`{{define "@card"}}<div>{{.Slot "default"}}{{template "icon"}}</div>{{end}}` +
+			`{{define "icon"}}*{{end}}`)},
+		"partials/list.html":   {Data: []byte(`{{define "list"}}{{component "@card"}}item{{end}}{{end}}`)},
+		"partials/footer.html": {Data: []byte(`{{define "footer"}}footer{{end}}`)},
+		"partials/unused.html": {Data: []byte(`{{define "unused"}}unused{{end}}{{define "unused:nested"}}{{end}}`)},
+		"views/home.html":      {Data: []byte(`{{define "title"}}Home{{end}}{{define "page:main"}}{{template "list"}}{{template "footer"}}{{end}}`)},
+		"views/dynamic.html":   {Data: []byte(`{{define "page:main"}}{{cachedTemplate "k" "1m" .Name .}}{{end}}`)},
+	}
+
+	tests := []struct {
+		name   string
+		view   string
+		layout string
+		data   map[string]any
+		want   string
+	}{
+		{name: "nested partials and components", view: "views/home", layout: "base", want: "<title>Home</title><div>item*</div>footer"},
+		{name: "extending layout", view: "views/home", layout: "docs", want: "<title>Home</title><div>item*</div>footer"},
+		{name: "dynamic name", view: "views/dynamic", layout: "base", data: map[string]any{"Name": "unused"}, want: "<title>Site</title>unused"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+				FileSystemMap:     map[string]fs.FS{constants.RootFSID: files},
+				PruneTemplateSets: true,
+			})
+			if err := adapter.Init(); err != nil {
+				t.Fatalf("error initializing adapter: %v", err)
+			}
+
+			w := renderTestTemplate(t, adapter, response.NewResponse().Layout(tt.layout).Path(tt.view).Data(tt.data))
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTemplateAdapter_PruneTemplateSetsStats(t *testing.T) {
+	files := fstest.MapFS{
+		"layouts/base.html":    {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+		"partials/footer.html": {Data: []byte(`{{define "footer"}}footer{{end}}`)},
+		"partials/unused.html": {Data: []byte(`{{define "unused"}}unused{{end}}`)},
+		"views/home.html":      {Data: []byte(`{{define "page:main"}}{{template "footer"}}{{end}}`)},
+	}
+
+	templates := func(prune bool) int {
+		adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+			FileSystemMap:     map[string]fs.FS{constants.RootFSID: files},
+			PruneTemplateSets: prune,
+		})
+		if err := adapter.Init(); err != nil {
+			t.Fatalf("error initializing adapter: %v", err)
+		}
+		return adapter.TemplateStats().Sets[0].Templates
+	}
+
+	full, pruned := templates(false), templates(true)
+	if pruned >= full {
+		t.Errorf("expected the pruned template set to hold fewer templates than the clone, got %d and %d", pruned, full)
+	}
+}
@@ -0,0 +1,103 @@
+package hyperview_test
+
+import (
+	"fmt"
+	"io/fs"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/response"
+)
+
+// swappableFS is a file system whose files can be replaced while it is read.
+type swappableFS struct {
+	files atomic.Pointer[fstest.MapFS]
+}
+
+func (s *swappableFS) Open(name string) (fs.File, error) {
+	return s.files.Load().Open(name)
+}
+
+func (s *swappableFS) set(files fstest.MapFS) {
+	s.files.Store(&files)
+}
+
+func reloadTestFiles(version int) fstest.MapFS {
+	return fstest.MapFS{
+		"layouts/base.html": {Data: []byte(`{{define "layout:base"}}<main>{{template "page:main" .}}</main>{{end}}`)},
+		"views/home.html":   {Data: []byte(fmt.Sprintf(`{{define "page:main"}}v%d{{end}}`, version))},
+		"views/about.html":  {Data: []byte(fmt.Sprintf(`{{define "page:main"}}about v%d{{end}}`, version))},
+	}
+}
+
+func TestTemplateAdapter_InitConcurrentRenders(t *testing.T) {
+	for _, lazy := range []bool{false, true} {
+		t.Run(fmt.Sprintf("lazy=%v", lazy), func(t *testing.T) {
+			files := &swappableFS{}
+			files.set(reloadTestFiles(0))
+
+			adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+				FileSystemMap: map[string]fs.FS{constants.RootFSID: files},
+				LazyCompile:   lazy,
+			})
+			if err := adapter.Init(); err != nil {
+				t.Fatalf("error initializing adapter: %v", err)
+			}
+
+			var wg sync.WaitGroup
+			var failures atomic.Int32
+			for range 4 {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for range 50 {
+						w := renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("views/home"))
+						if w.Code != http.StatusOK {
+							failures.Add(1)
+						}
+					}
+				}()
+			}
+
+			for version := 1; version <= 20; version++ {
+				files.set(reloadTestFiles(version))
+				if err := adapter.Init(); err != nil {
+					t.Fatalf("error reinitializing adapter: %v", err)
+				}
+			}
+			wg.Wait()
+
+			if n := failures.Load(); n > 0 {
+				t.Errorf("expected every render during the reloads to succeed, %d failed", n)
+			}
+			w := renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("views/home"))
+			if got := w.Body.String(); got != "<main>v20</main>" {
+				t.Errorf("expected the last templates after the reloads, got %q", got)
+			}
+		})
+	}
+}
+
+func TestTemplateAdapter_InitFailureKeepsTemplates(t *testing.T) {
+	files := reloadTestFiles(1)
+	adapter := newTestTemplateAdapter(t, files, hyperview.TemplateViewAdapterOptions{})
+
+	files["views/home.html"] = &fstest.MapFile{Data: []byte(`{{define "page:main"}}{{end}`)}
+	if err := adapter.Init(); err == nil {
+		t.Fatal("expected Init to fail for the broken view")
+	}
+
+	w := renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("views/home"))
+	if got := w.Body.String(); got != "<main>v1</main>" {
+		t.Errorf("expected the previous templates after the failed Init, got %q", got)
+	}
+	w = renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("views/about"))
+	if got := w.Body.String(); got != "<main>about v1</main>" {
+		t.Errorf("expected the previous templates of the other views after the failed Init, got %q", got)
+	}
+}
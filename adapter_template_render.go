@@ -2,30 +2,96 @@ package hyperview
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"html/template"
 	"log/slog"
 	"net/http"
 	"runtime/debug"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/hypergopher/hyperview/constants"
 	"github.com/hypergopher/hyperview/response"
 )
 
 func (a *TemplateAdapter) Render(w http.ResponseWriter, r *http.Request, resp *response.Response) {
-	tmpl, ok := a.templates[resp.TemplatePath()]
+	if resp.Kind() != response.KindView {
+		start := time.Now()
+		err := resp.WriteKind(w, r)
+		a.notifyRender(r, resp, start, 0, err)
+		return
+	}
+
+	pageName, tmpl, layout, scoped, err := a.renderTemplate(r, resp)
+	if err != nil {
+		a.logRenderFinish(r, resp, time.Now(), http.StatusInternalServerError, 0, err)
+		a.handleRenderError(w, r, resp, a.normalizeName(resp.TemplatePath()), err, nil, resp.ViewData(r).Data())
+		return
+	}
+	defer scoped()
+	a.usage.record(pageName, time.Now())
+
+	release, ok := a.limitRender(w, r, pageName)
 	if !ok {
-		a.handleError(w, r, fmt.Errorf("template not found: %s", resp.TemplatePath()))
+		resp.Status(http.StatusServiceUnavailable)
+		a.notifyRender(r, resp, time.Now(), 0, ErrRenderShed)
 		return
 	}
+	defer release()
+
+	a.execTemplate(w, r, resp, pageName, tmpl, layout)
+}
+
+// renderTemplate looks up the template set rendering the response, waiting for Init to swap the templates if it is
+// swapping them.
+// It returns the page name, resolved for the tenant, experiment variant and locale of the request, the template set and the name of the layout to execute,
+// and the func releasing the template set once executed.
+func (a *TemplateAdapter) renderTemplate(r *http.Request, resp *response.Response) (string, *template.Template, string, func(), error) {
+	if !a.frozen.Load() {
+		a.initMu.RLock()
+		defer a.initMu.RUnlock()
+	}
+
+	pageName := a.localizedPage(r, a.variantPage(r, resp, a.fallbackPage(r, a.normalizeName(resp.TemplatePath()))))
+
+	if resp.TemplateLayout() == "" {
+		if layout, ok := a.pageLayouts[pageName]; ok {
+			resp.Layout(layout)
+		}
+	}
+
+	tmpl, layout, err := a.lookupTemplate(pageName, resp.TemplateLayout(), a.strictRender(resp))
+	if err != nil {
+		return "", nil, "", nil, err
+	}
 
-	a.execTemplate(w, r, resp, tmpl)
+	tmpl, scoped, err := a.scopeTemplate(r, resp, tmpl)
+	if err != nil {
+		return "", nil, "", nil, err
+	}
+
+	a.logDeprecatedRender(pageName)
+	return pageName, tmpl, layout, scoped, nil
+}
+
+// hasPage reports whether the page exists for the request, waiting for Init to swap the templates if it is swapping
+// them.
+func (a *TemplateAdapter) hasPage(r *http.Request, pageName string) bool {
+	if !a.frozen.Load() {
+		a.initMu.RLock()
+		defer a.initMu.RUnlock()
+	}
+
+	_, ok := a.pages[a.resolvePage(r, a.normalizeName(pageName))]
+	return ok
 }
 
 func (a *TemplateAdapter) RenderForbidden(w http.ResponseWriter, r *http.Request, resp *response.Response) {
 	path := a.viewsPath(constants.SystemDir, "403")
-	if _, ok := a.templates[path]; ok {
+	if a.hasPage(r, path) {
 		a.Render(w, r, resp.Path(path))
 		return
 	}
@@ -34,7 +100,7 @@ func (a *TemplateAdapter) RenderForbidden(w http.ResponseWriter, r *http.Request
 
 func (a *TemplateAdapter) RenderMaintenance(w http.ResponseWriter, r *http.Request, resp *response.Response) {
 	path := a.viewsPath(constants.SystemDir, "503")
-	if _, ok := a.templates[path]; ok {
+	if a.hasPage(r, path) {
 		a.Render(w, r, resp.Path(path))
 		return
 	}
@@ -43,7 +109,7 @@ func (a *TemplateAdapter) RenderMaintenance(w http.ResponseWriter, r *http.Reque
 
 func (a *TemplateAdapter) RenderMethodNotAllowed(w http.ResponseWriter, r *http.Request, resp *response.Response) {
 	path := a.viewsPath(constants.SystemDir, "405")
-	if _, ok := a.templates[path]; ok {
+	if a.hasPage(r, path) {
 		a.Render(w, r, resp.Path(path))
 		return
 	}
@@ -52,7 +118,7 @@ func (a *TemplateAdapter) RenderMethodNotAllowed(w http.ResponseWriter, r *http.
 
 func (a *TemplateAdapter) RenderNotFound(w http.ResponseWriter, r *http.Request, resp *response.Response) {
 	path := a.viewsPath(constants.SystemDir, "404")
-	if _, ok := a.templates[path]; ok {
+	if a.hasPage(r, path) {
 		a.Render(w, r, resp.Path(path))
 		return
 	}
@@ -73,7 +139,7 @@ func (a *TemplateAdapter) RenderSystemError(w http.ResponseWriter, r *http.Reque
 
 	// If there is a template with the name "system/server_error" in the template cache, use it
 	path := a.viewsPath(constants.SystemDir, "500")
-	if _, ok := a.templates[path]; ok {
+	if a.hasPage(r, path) {
 		resp.Path(path).
 			Errors(err.Error(), map[string]string{"LineErrors": lineErrors}).
 			StatusError()
@@ -86,7 +152,7 @@ func (a *TemplateAdapter) RenderSystemError(w http.ResponseWriter, r *http.Reque
 
 func (a *TemplateAdapter) RenderUnauthorized(w http.ResponseWriter, r *http.Request, resp *response.Response) {
 	path := a.viewsPath(constants.SystemDir, "401")
-	if _, ok := a.templates[path]; ok {
+	if a.hasPage(r, path) {
 		a.Render(w, r, resp.Path(path))
 		return
 	}
@@ -101,38 +167,240 @@ func (a *TemplateAdapter) handleError(w http.ResponseWriter, r *http.Request, er
 	}
 }
 
-func (a *TemplateAdapter) execTemplate(w http.ResponseWriter, r *http.Request, resp *response.Response, tmpl *template.Template) {
-	// Creating a buffer, so we can capture write errors before we write to the header
-	// Note that layouts are always defined with the same name as the layout file without the extension (e.g. base.html -> base)
-	buf := new(bytes.Buffer)
-	layout := fmt.Sprintf("layout:%s", resp.TemplateLayout())
-	err := tmpl.ExecuteTemplate(buf, layout, resp.ViewData(r).Data())
-	if err != nil {
-		path := a.viewsPath(constants.SystemDir, "server-error")
-		if resp.TemplatePath() == path {
-			http.Error(w, fmt.Errorf("error executing template: %w", err).Error(), http.StatusInternalServerError)
+// execTemplate executes the template set of the page with the layout and writes the response.
+func (a *TemplateAdapter) execTemplate(w http.ResponseWriter, r *http.Request, resp *response.Response, pageName string, tmpl *template.Template, layout string) {
+	start := time.Now()
+	a.logRenderStart(r, resp, start)
+	ctx, cancel := a.renderContext(r)
+	defer cancel()
+
+	// Serve the body from the render cache, skipping the loaders and template execution. The cached body is that of the
+	// template, before the render middleware, which runs on every render, e.g. to set the headers of the response.
+	if cached, ok := a.cachedRender(r, resp); ok {
+		body, err := a.chainRender(func(*http.Request, *response.Response) ([]byte, error) {
+			return cached, nil
+		})(a.middlewareRequest(r), resp)
+		if err != nil {
+			a.handleExecError(ctx, w, r, resp, pageName, start, err, nil, nil)
+			return
+		}
+		err = a.writeBody(w, r, resp, body, a.renderHints(resp, pageName, true))
+		a.notifyRender(r, resp, start, len(body), err)
+		return
+	}
+
+	a.sendEarlyHints(w, r, resp, pageName)
+
+	if a.streamable(w, r, resp) && a.streamTemplate(ctx, w, r, resp, tmpl, layout, pageName, start) {
+		return
+	}
+
+	// The template is rendered through the render middleware, which sees the errors of the loaders and templates
+	var (
+		data     map[string]any
+		partial  []byte
+		rendered []renderedTemplate
+		pooled   *bytes.Buffer
+		page     []byte // body of the template, before the render middleware
+	)
+	defer func() {
+		if pooled != nil {
+			putRenderBuffer(pooled)
+		}
+	}()
+	render := func(r *http.Request, resp *response.Response) ([]byte, error) {
+		// Run any data loaders registered on the response before executing the template
+		if err := resp.RunLoaders(ctx, a.loaderConcurrency); err != nil {
+			return nil, err
+		}
+
+		data = resp.ViewData(r).Data()
+		if err := a.mapViewModels(data); err != nil {
+			return nil, err
+		}
+		if err := a.validateData(resp, pageName, data); err != nil {
+			return nil, err
+		}
+
+		// Creating a buffer, so we can capture write errors before we write to the header. The first render uses a pooled
+		// buffer, released once the response is written, as middleware may render more than once.
+		// Note that layouts are always defined with the same name as the layout file without the extension (e.g. base.html -> base)
+		var buf *bytes.Buffer
+		if pooled == nil {
+			pooled = getRenderBuffer()
+			buf = pooled
 		} else {
-			a.handleError(w, r, fmt.Errorf("error executing template: %w", err))
+			buf = new(bytes.Buffer)
+		}
+		if err := tmpl.ExecuteTemplate(renderWriter(ctx, limitWriter(buf, a.maxRenderSize)), layout, data); err != nil {
+			partial = buf.Bytes()
+			if renderAborted(ctx, resp, err) != nil || errors.Is(err, ErrRenderTooLarge) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("error executing template: %w", missingKeyError(err))
 		}
+
+		injected := a.loadedComponentAssets().inject(r, buf.Bytes(), tmpl, layout, pageName, resp.TemplateLayout())
+		page, rendered = a.debugBody(annotateVariants(injected, resp.Variants()))
+		return page, nil
+	}
+
+	body, err := a.chainRender(render)(a.middlewareRequest(r), resp)
+	if err != nil {
+		a.handleExecError(ctx, w, r, resp, pageName, start, err, partial, data)
+		return
+	}
+
+	if page != nil {
+		a.cacheRender(r, resp, page, data)
+	}
+	body = a.debugOverlay(body, resp, data, rendered, time.Since(start))
+
+	err = a.writeBody(w, r, resp, body, a.renderHints(resp, pageName, false))
+	a.notifyRender(r, resp, start, len(body), err)
+	if err == nil {
+		a.reportSlowRender(r, resp, start, len(body), data, rendered)
+	}
+}
+
+// handleExecError answers a render whose loaders, render middleware or templates failed with err, and reports it.
+func (a *TemplateAdapter) handleExecError(ctx context.Context, w http.ResponseWriter, r *http.Request, resp *response.Response, pageName string, start time.Time, err error, partial []byte, data map[string]any) {
+	if aborted := renderAborted(ctx, resp, err); aborted != nil {
+		a.handleAborted(w, resp, start, aborted)
+		a.notifyRender(r, resp, start, 0, aborted)
+		return
+	}
+	if errors.Is(err, ErrRenderTooLarge) {
+		a.notifyRender(r, resp, start, 0, a.handleTooLarge(w, resp))
 		return
 	}
 
+	if data == nil {
+		data = resp.ViewData(r).Data()
+	}
+	if resp.TemplatePath() == a.viewsPath(constants.SystemDir, "server-error") {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	} else {
+		a.handleRenderError(w, r, resp, pageName, err, partial, data)
+	}
+	a.notifyRender(r, resp, start, 0, err)
+}
+
+// writeBody writes the headers, resource hints, status code and rendered body of the response, compressed with the
+// encoding negotiated with the request, or 304 Not Modified if the request is a conditional request matching the ETag
+// of the body.
+func (a *TemplateAdapter) writeBody(w http.ResponseWriter, r *http.Request, resp *response.Response, body []byte, hints []response.Hint) error {
 	// Add any additional headers
 	for key, value := range resp.Headers() {
 		w.Header().Set(key, value)
 	}
+	if len(hints) > 0 {
+		links := linkHeader(hints)
+		if link := resp.Headers()["Link"]; link != "" {
+			links = link + ", " + links
+		}
+		w.Header().Set("Link", links)
+	}
+
+	encoding := a.compressor.negotiate(r, resp, body)
+	if a.compressor.compressible(resp, body) {
+		w.Header().Add("Vary", "Accept-Encoding")
+	}
+
+	if conditionalRender(w, r, resp, body, encoding) {
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+
+	if encoding != "" {
+		compressed, err := a.compressedBody(r, resp, body, encoding)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return err
+		}
+		w.Header().Set("Content-Encoding", encoding)
+		body = compressed
+	}
 
 	// Set the status code
 	w.WriteHeader(resp.StatusCode())
 
 	// Write the buffer to the response
-	_, err = buf.WriteTo(w)
+	_, err := w.Write(body)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
+	return err
+}
+
+// mapViewModels converts the values of the view data to their view models, if a view model registry is configured.
+// The data is updated in place, so the values returned by .View.Get are converted too.
+func (a *TemplateAdapter) mapViewModels(data map[string]any) error {
+	if a.viewModels == nil {
+		return nil
+	}
+
+	for key, value := range data {
+		if key == "View" {
+			continue
+		}
+
+		mapped, err := a.viewModels.Map(value)
+		if err != nil {
+			return fmt.Errorf("error mapping view data %s: %w", key, err)
+		}
+		data[key] = mapped
+	}
+
+	return nil
+}
+
+// notifyRender logs a completed render and reports it to the render hook, if one is configured.
+func (a *TemplateAdapter) notifyRender(r *http.Request, resp *response.Response, start time.Time, size int, err error) {
+	status := resp.StatusCode()
+	if err != nil && !errors.Is(err, ErrRenderShed) && !errors.Is(err, ErrRenderTimeout) {
+		status = http.StatusInternalServerError
+	}
+
+	a.logRenderFinish(r, resp, start, status, size, err)
+	if a.onRender == nil {
+		return
+	}
+
+	a.onRender(r, RenderEvent{
+		Template: resp.TemplatePath(),
+		Layout:   resp.TemplateLayout(),
+		Status:   status,
+		Duration: time.Since(start),
+		Size:     size,
+		Variants: resp.Variants(),
+		Flags:    resp.Flags(),
+		Err:      err,
+	})
 }
 
 func (a *TemplateAdapter) viewsPath(path ...string) string {
 	// For each path, append to the ViewsDir, separated by a slash
 	return fmt.Sprintf("%s/%s", constants.ViewsDir, strings.Join(path, "/"))
 }
+
+// maxPooledRenderBuffer is the capacity of the largest render buffer pooled, so a few huge pages do not hold on to
+// their memory.
+const maxPooledRenderBuffer = 1 << 20
+
+// renderBuffers pools the buffers the pages are rendered to.
+var renderBuffers = sync.Pool{New: func() any { return new(bytes.Buffer) }}
+
+// getRenderBuffer returns an empty render buffer from the pool.
+func getRenderBuffer() *bytes.Buffer {
+	return renderBuffers.Get().(*bytes.Buffer)
+}
+
+// putRenderBuffer returns a render buffer to the pool, unless it grew too large.
+func putRenderBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledRenderBuffer {
+		return
+	}
+	buf.Reset()
+	renderBuffers.Put(buf)
+}
@@ -0,0 +1,197 @@
+package hyperview
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"log/slog"
+	"sort"
+	"strings"
+	"text/template/parse"
+	"time"
+
+	"github.com/hypergopher/hyperview/constants"
+)
+
+// CollisionPolicy is how Init handles templates of a file system shadowing those of another, set with
+// TemplateViewAdapterOptions.Collisions.
+type CollisionPolicy int
+
+const (
+	// CollisionWarn logs a warning for each collision, and loads the templates of the last file system. This is the
+	// default.
+	CollisionWarn CollisionPolicy = iota
+	// CollisionError makes Init fail on collisions.
+	CollisionError
+)
+
+// InitReport describes the templates loaded by the last Init or reload, by file system.
+type InitReport struct {
+	// LoadedAt is the time of the Init or reload.
+	LoadedAt time.Time `json:"loadedAt"`
+	// Duration is the time the Init or reload took.
+	Duration time.Duration `json:"duration"`
+	// FileSystems are the file systems of the adapter, the root file system first, then the others by ID.
+	FileSystems []FileSystemReport `json:"fileSystems"`
+	// Collisions are the templates defined by the layouts of several file systems, in load order.
+	Collisions []TemplateCollision `json:"collisions"`
+}
+
+// FileSystemReport describes the templates loaded from a file system.
+type FileSystemReport struct {
+	// ID is the ID of the file system.
+	ID string `json:"id"`
+	// Templates are the template files found, named like in the DependencyGraph, e.g. acme:views/home.html, sorted.
+	Templates []string `json:"templates"`
+	// Skipped are the files of the layouts, partials and views directories skipped as they lack the template
+	// extension, sorted, e.g. a views/home.htm typo.
+	Skipped []string `json:"skipped"`
+	// ParseDuration is the time spent reading and parsing the templates of the file system. Views compiled on first
+	// use, in lazy mode or when restored from the init cache, are not parsed at Init.
+	ParseDuration time.Duration `json:"parseDuration"`
+}
+
+// TemplateCollision is a template defined by the layouts of several file systems, where the templates of the file
+// system loaded last silently shadow the others.
+type TemplateCollision struct {
+	// Name is the name of the template, e.g. "layout:base", or of the extending layout, e.g. "admin".
+	Name string `json:"name"`
+	// Files are the files defining the template, in load order, so the last one is in effect.
+	Files []string `json:"files"`
+}
+
+func (c TemplateCollision) String() string {
+	return fmt.Sprintf("template %s is defined in %s, shadowing %s", c.Name, c.Files[len(c.Files)-1],
+		strings.Join(c.Files[:len(c.Files)-1], ", "))
+}
+
+// InitReport returns the report of the last Init or reload: the templates found and skipped in each file system, the
+// time spent parsing them, and the collisions between file systems.
+func (a *TemplateAdapter) InitReport() InitReport {
+	if !a.frozen.Load() {
+		a.initMu.RLock()
+		defer a.initMu.RUnlock()
+	}
+	if a.report == nil {
+		return InitReport{FileSystems: []FileSystemReport{}, Collisions: []TemplateCollision{}}
+	}
+	return *a.report
+}
+
+// addParseDuration adds the time spent since start parsing the templates of the file system to the report.
+func (r *InitReport) addParseDuration(fsID string, start time.Time) {
+	for i := range r.FileSystems {
+		if r.FileSystems[i].ID == fsID {
+			r.FileSystems[i].ParseDuration += time.Since(start)
+			return
+		}
+	}
+	r.FileSystems = append(r.FileSystems, FileSystemReport{ID: fsID, ParseDuration: time.Since(start)})
+}
+
+// addCollision records that the file defines a template another file defined already.
+func (r *InitReport) addCollision(name, previous, file string) {
+	for i := range r.Collisions {
+		if r.Collisions[i].Name == name {
+			r.Collisions[i].Files = append(r.Collisions[i].Files, file)
+			return
+		}
+	}
+	r.Collisions = append(r.Collisions, TemplateCollision{Name: name, Files: []string{previous, file}})
+}
+
+// checkLayoutCollisions records the templates the layout file defines that the layouts of another file system
+// defined already, before recordDefinitions records the file as theirs. Empty templates, such as default blocks, are
+// not collisions.
+func (a *TemplateAdapter) checkLayoutCollisions(t *template.Template, before map[string]*parse.Tree, file string) {
+	for _, tmpl := range t.Templates() {
+		tree, ok := before[tmpl.Name()]
+		if !ok || tree == tmpl.Tree || tree == nil || parse.IsEmptyTree(tree.Root) {
+			continue
+		}
+		if previous, ok := a.templateFiles[tmpl.Name()]; ok && isLayoutFile(previous) && pageFSID(previous) != pageFSID(file) {
+			a.report.addCollision(tmpl.Name(), previous, file)
+		}
+	}
+}
+
+// reportCollisions logs the collisions between file systems, or returns them under the CollisionError policy.
+func (a *TemplateAdapter) reportCollisions() error {
+	if len(a.report.Collisions) == 0 {
+		return nil
+	}
+	if a.collisions == CollisionError {
+		errs := make([]error, 0, len(a.report.Collisions))
+		for _, c := range a.report.Collisions {
+			errs = append(errs, errors.New(c.String()))
+		}
+		return fmt.Errorf("templates collide across file systems:\n%w", errors.Join(errs...))
+	}
+	for _, c := range a.report.Collisions {
+		a.log().Warn("Template collision", slog.String("template", c.Name), slog.Any("files", c.Files))
+	}
+	return nil
+}
+
+// finishReport completes the report of the Init or reload started at start with the templates found and skipped in
+// each file system.
+func (a *TemplateAdapter) finishReport(fileSystems map[string]fs.FS, start time.Time) error {
+	byFS := make(map[string]*FileSystemReport, len(fileSystems))
+	for fsID := range fileSystems {
+		byFS[fsID] = &FileSystemReport{ID: fsID, Templates: []string{}, Skipped: []string{}}
+	}
+	for _, fsys := range a.report.FileSystems {
+		if byFS[fsys.ID] != nil {
+			byFS[fsys.ID].ParseDuration = fsys.ParseDuration
+		}
+	}
+
+	// Views restored from the init cache have no sources
+	files := make(map[string]bool, len(a.sources)+len(a.pages))
+	for file := range a.sources {
+		files[file] = true
+	}
+	for name, page := range a.pages {
+		if name != contentPage {
+			files[templateFileKey(pageFSID(name), page.path)] = true
+		}
+	}
+	for file := range files {
+		if fsys := byFS[pageFSID(file)]; fsys != nil {
+			fsys.Templates = append(fsys.Templates, file)
+		}
+	}
+
+	for fsID, fsys := range fileSystems {
+		for _, dir := range []string{constants.LayoutsDir, constants.PartialsDir, constants.ViewsDir} {
+			if _, err := fs.Stat(fsys, dir); err != nil {
+				continue
+			}
+			err := fs.WalkDir(fsys, dir, func(filePath string, d fs.DirEntry, err error) error {
+				if err != nil || d.IsDir() || a.hasExtension(filePath) {
+					return err
+				}
+				byFS[fsID].Skipped = append(byFS[fsID].Skipped, templateFileKey(fsID, filePath))
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	a.report.FileSystems = make([]FileSystemReport, 0, len(byFS))
+	for _, fsID := range sortedFSIDs(byFS) {
+		fsys := byFS[fsID]
+		sort.Strings(fsys.Templates)
+		sort.Strings(fsys.Skipped)
+		a.report.FileSystems = append(a.report.FileSystems, *fsys)
+	}
+	if a.report.Collisions == nil {
+		a.report.Collisions = []TemplateCollision{}
+	}
+	a.report.LoadedAt = a.loadedAt
+	a.report.Duration = time.Since(start)
+	return nil
+}
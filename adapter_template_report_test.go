@@ -0,0 +1,139 @@
+package hyperview_test
+
+import (
+	"io"
+	"io/fs"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+)
+
+func TestTemplateAdapter_InitReport(t *testing.T) {
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{
+			constants.RootFSID: fstest.MapFS{
+				"layouts/base.html":    {Data: []byte(`{{define "layout:base"}}root{{block "title" .}}{{end}}{{end}}`)},
+				"partials/card.html":   {Data: []byte(`{{define "@card"}}card{{end}}`)},
+				"partials/README.md":   {Data: []byte(`# Partials`)},
+				"views/home.html":      {Data: []byte(`home`)},
+				"views/about.htm":      {Data: []byte(`about`)},
+				"static/app.css":       {Data: []byte(`body {}`)},
+				"views/users/new.html": {Data: []byte(`new`)},
+			},
+			"acme": fstest.MapFS{
+				"layouts/base.html": {Data: []byte(`{{define "layout:base"}}acme{{block "title" .}}{{end}}{{end}}`)},
+				"views/home.html":   {Data: []byte(`acme home`)},
+			},
+		},
+		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+
+	report := adapter.InitReport()
+	if report.LoadedAt.IsZero() || report.Duration <= 0 {
+		t.Errorf("expected the time and duration of the Init, got %v and %v", report.LoadedAt, report.Duration)
+	}
+	if len(report.FileSystems) != 2 {
+		t.Fatalf("expected 2 file systems, got %+v", report.FileSystems)
+	}
+
+	root, acme := report.FileSystems[0], report.FileSystems[1]
+	if root.ID != constants.RootFSID || acme.ID != "acme" {
+		t.Fatalf("expected the root file system first, got %s and %s", root.ID, acme.ID)
+	}
+	if want := []string{"layouts/base.html", "partials/card.html", "views/home.html", "views/users/new.html"}; !slices.Equal(root.Templates, want) {
+		t.Errorf("expected root templates %v, got %v", want, root.Templates)
+	}
+	if want := []string{"partials/README.md", "views/about.htm"}; !slices.Equal(root.Skipped, want) {
+		t.Errorf("expected root skipped files %v, got %v", want, root.Skipped)
+	}
+	if want := []string{"acme:layouts/base.html", "acme:views/home.html"}; !slices.Equal(acme.Templates, want) {
+		t.Errorf("expected acme templates %v, got %v", want, acme.Templates)
+	}
+	if root.ParseDuration <= 0 || acme.ParseDuration <= 0 {
+		t.Errorf("expected parse durations, got %v and %v", root.ParseDuration, acme.ParseDuration)
+	}
+
+	// The empty default title block is not a collision
+	want := []hyperview.TemplateCollision{{Name: "layout:base", Files: []string{"layouts/base.html", "acme:layouts/base.html"}}}
+	if got := report.Collisions; len(got) != 1 || got[0].Name != want[0].Name || !slices.Equal(got[0].Files, want[0].Files) {
+		t.Errorf("expected collisions %v, got %v", want, got)
+	}
+}
+
+func TestTemplateAdapter_Collisions(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  hyperview.CollisionPolicy
+		acme    fstest.MapFS
+		wantErr string // empty if Init succeeds
+		want    []string
+	}{
+		{
+			name: "root layouts",
+			acme: fstest.MapFS{"layouts/base.html": {Data: []byte(`{{define "layout:base"}}acme{{end}}`)}},
+			want: []string{"template layout:base is defined in acme:layouts/base.html, shadowing layouts/base.html"},
+		},
+		{
+			name: "extending layouts",
+			acme: fstest.MapFS{"layouts/admin.html": {Data: []byte(`{{/* extends "base" */}}{{define "main"}}acme{{end}}`)}},
+			want: []string{"template admin is defined in acme:layouts/admin.html, shadowing layouts/admin.html"},
+		},
+		{
+			name: "other templates",
+			acme: fstest.MapFS{"layouts/wide.html": {Data: []byte(`{{define "layout:wide"}}acme{{end}}`)}},
+		},
+		{
+			name:    "error",
+			policy:  hyperview.CollisionError,
+			acme:    fstest.MapFS{"layouts/base.html": {Data: []byte(`{{define "layout:base"}}acme{{end}}`)}},
+			wantErr: "templates collide across file systems:\ntemplate layout:base is defined in acme:layouts/base.html",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs strings.Builder
+			adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+				FileSystemMap: map[string]fs.FS{
+					constants.RootFSID: fstest.MapFS{
+						"layouts/base.html":  {Data: []byte(`{{define "layout:base"}}root{{block "main" .}}{{end}}{{end}}`)},
+						"layouts/admin.html": {Data: []byte(`{{/* extends "base" */}}{{define "main"}}admin{{end}}`)},
+						"views/home.html":    {Data: []byte(`home`)},
+					},
+					"acme": tt.acme,
+				},
+				Collisions: tt.policy,
+				Logger:     slog.New(slog.NewTextHandler(&logs, nil)),
+			})
+			err := adapter.Init()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected Init error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error initializing adapter: %v", err)
+			}
+
+			var got []string
+			for _, c := range adapter.InitReport().Collisions {
+				got = append(got, c.String())
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("expected collisions %v, got %v", tt.want, got)
+			}
+			if warned := strings.Contains(logs.String(), "Template collision"); warned != (len(tt.want) > 0) {
+				t.Errorf("unexpected collision warnings: %s", logs.String())
+			}
+		})
+	}
+}
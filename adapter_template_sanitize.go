@@ -0,0 +1,24 @@
+package hyperview
+
+import "html/template"
+
+// Sanitizer renders user-generated HTML safely through named policies, such as the Sanitizer of the
+// github.com/hypergopher/hyperview/contrib/sanitize module. Without a policy name, its default policy is used.
+type Sanitizer interface {
+	// Sanitize sanitizes the HTML of input with the policy.
+	Sanitize(input any, policy ...string) (template.HTML, error)
+	// MarkdownSafe converts the Markdown of input to HTML and sanitizes it with the policy.
+	MarkdownSafe(input any, policy ...string) (template.HTML, error)
+	// RawIfTrusted returns input as is if the application marked it as trusted, and sanitizes it with the policy
+	// otherwise.
+	RawIfTrusted(input any, policy ...string) (template.HTML, error)
+}
+
+// sanitizerFuncs returns the sanitize, markdownSafe and rawIfTrusted functions of the sanitizer.
+func sanitizerFuncs(s Sanitizer) template.FuncMap {
+	return template.FuncMap{
+		"sanitize":     s.Sanitize,
+		"markdownSafe": s.MarkdownSafe,
+		"rawIfTrusted": s.RawIfTrusted,
+	}
+}
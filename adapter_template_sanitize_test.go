@@ -0,0 +1,69 @@
+package hyperview_test
+
+import (
+	"fmt"
+	"html/template"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/response"
+)
+
+// prefixSanitizer marks what each function of the sanitizer rendered, with the policy.
+type prefixSanitizer struct{}
+
+func (prefixSanitizer) Sanitize(input any, policy ...string) (template.HTML, error) {
+	return template.HTML(fmt.Sprintf("<sanitize %v>%v", policy, input)), nil
+}
+
+func (prefixSanitizer) MarkdownSafe(input any, policy ...string) (template.HTML, error) {
+	return template.HTML(fmt.Sprintf("<markdown %v>%v", policy, input)), nil
+}
+
+func (prefixSanitizer) RawIfTrusted(input any, policy ...string) (template.HTML, error) {
+	return template.HTML(fmt.Sprintf("<raw %v>%v", policy, input)), nil
+}
+
+func TestTemplateAdapter_Sanitizer(t *testing.T) {
+	tests := []struct {
+		name  string
+		funcs template.FuncMap
+		want  string
+	}{
+		{
+			name: "sanitizer functions",
+			want: `<sanitize []>a|<sanitize [strict]>b|<markdown []>c|<raw []>d`,
+		},
+		{
+			name:  "funcs take precedence",
+			funcs: template.FuncMap{"sanitize": func(input any, _ ...string) string { return "custom" }},
+			want:  `custom|custom|<markdown []>c|<raw []>d`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+				FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{
+					"layouts/base.html": {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+					"views/home.html": {Data: []byte(`{{define "page:main"}}{{sanitize "a"}}|{{sanitize "b" "strict"}}|` +
+						`{{markdownSafe "c"}}|{{rawIfTrusted "d"}}{{end}}`)},
+				}},
+				Funcs:     tt.funcs,
+				Sanitizer: prefixSanitizer{},
+			})
+			if err := adapter.Init(); err != nil {
+				t.Fatalf("error initializing adapter: %v", err)
+			}
+
+			w := renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("home"))
+			if got := strings.TrimSpace(w.Body.String()); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
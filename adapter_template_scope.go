@@ -0,0 +1,200 @@
+package hyperview
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hypergopher/hyperview/request"
+	"github.com/hypergopher/hyperview/response"
+)
+
+// scopeTemplate returns the template set to execute for a single render, and the func releasing it once executed.
+//
+// Functions scoped to a render, such as memoized and request-scoped functions, the feature and variant functions and
+// the cachedTemplate function of the render cache, must not share state between concurrent renders. When the adapter has any, the page template set is cloned and the
+// scoped functions are bound to the clone. Because html/template cannot clone a template set once it has been
+// executed, the page template sets are never executed directly in that case. Clones are pooled by page template set
+// and their scoped functions rebound for each render, so a page is cloned once per concurrent render rather than on
+// every render. Without scoped functions, the page template set is executed as is.
+func (a *TemplateAdapter) scopeTemplate(r *http.Request, resp *response.Response, tmpl *template.Template) (*template.Template, func(), error) {
+	for name := range resp.Funcs() {
+		if _, ok := a.requestFuncs[name]; !ok {
+			return nil, nil, fmt.Errorf("request-scoped function %s is not declared in the adapter's RequestFuncs option", name)
+		}
+	}
+
+	if len(a.memoFuncs) == 0 && len(a.requestFuncs) == 0 && a.flags == nil && a.variantSelector == nil && a.renderCache == nil {
+		return tmpl, func() {}, nil
+	}
+
+	clones := a.clones
+	clone, err := clones.get(tmpl, a.templateFuncs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error cloning template: %w", err)
+	}
+
+	funcs := make(template.FuncMap, len(a.memoFuncs)+len(a.requestFuncs)+3)
+	cache := &memoCache{results: make(map[string][]reflect.Value)}
+	for name, fn := range a.memoFuncs {
+		funcs[name] = memoize(name, fn, cache)
+	}
+	if a.flags != nil {
+		funcs["feature"] = featureFunc(r, resp, a.flags)
+	}
+	if a.variantSelector != nil {
+		funcs["variant"] = variantFunc(resp)
+	}
+	if a.renderCache != nil {
+		funcs["cachedTemplate"] = a.cachedTemplateFunc(clone, r)
+	}
+
+	// Request-scoped functions from the context (e.g. set by middleware) override the defaults, and are in turn
+	// overridden by the functions set on the response. Context functions this adapter doesn't declare are ignored, as
+	// middleware may provide functions for other adapters too.
+	for name, fn := range a.requestFuncs {
+		funcs[name] = fn
+	}
+	if len(a.requestHelpers) > 0 {
+		helpers := helperFuncs(r, a.urlBuilder)
+		for _, name := range a.requestHelpers {
+			funcs[name] = helpers[name]
+		}
+	}
+	for _, scoped := range []template.FuncMap{FuncsFromContext(r.Context()), resp.Funcs()} {
+		for name, fn := range scoped {
+			if _, ok := a.requestFuncs[name]; ok {
+				funcs[name] = fn
+			}
+		}
+	}
+
+	return clone.Funcs(funcs), func() { clones.put(tmpl, clone) }, nil
+}
+
+// scopedClones pools the clones of the page template sets executed with functions scoped to a render, keyed by page
+// template set. A clone is in use by a single render at a time.
+type scopedClones struct {
+	pools sync.Map // *template.Template -> *sync.Pool of clones
+}
+
+// get returns a clone of tmpl from the pool, or a new clone with the functions of funcs bound to it.
+func (c *scopedClones) get(tmpl *template.Template, funcs func(*template.Template) template.FuncMap) (*template.Template, error) {
+	if pool, ok := c.pools.Load(tmpl); ok {
+		if clone, ok := pool.(*sync.Pool).Get().(*template.Template); ok {
+			return clone, nil
+		}
+	}
+
+	clone, err := tmpl.Clone()
+	if err != nil {
+		return nil, err
+	}
+	return clone.Funcs(funcs(clone)), nil
+}
+
+// put returns a clone of tmpl to the pool once its render completed.
+func (c *scopedClones) put(tmpl, clone *template.Template) {
+	pool, _ := c.pools.LoadOrStore(tmpl, &sync.Pool{})
+	pool.(*sync.Pool).Put(clone)
+}
+
+// forget drops the clones of tmpl, when it is evicted.
+func (c *scopedClones) forget(tmpl *template.Template) {
+	c.pools.Delete(tmpl)
+}
+
+// memoCache holds the results of memoized functions for a single render.
+type memoCache struct {
+	mu      sync.Mutex
+	results map[string][]reflect.Value
+}
+
+// memoize wraps fn, which must be a function, so its results are cached in cache keyed by the function name and
+// arguments. Calls that return a non-nil error are not cached.
+func memoize(name string, fn any, cache *memoCache) any {
+	fv := reflect.ValueOf(fn)
+	ft := fv.Type()
+
+	return reflect.MakeFunc(ft, func(args []reflect.Value) []reflect.Value {
+		key := memoKey(name, args)
+
+		cache.mu.Lock()
+		results, ok := cache.results[key]
+		cache.mu.Unlock()
+		if ok {
+			return results
+		}
+
+		if ft.IsVariadic() {
+			results = fv.CallSlice(args)
+		} else {
+			results = fv.Call(args)
+		}
+
+		if n := len(results); n > 0 && ft.Out(n-1) == errorType && !results[n-1].IsNil() {
+			return results
+		}
+
+		cache.mu.Lock()
+		cache.results[key] = results
+		cache.mu.Unlock()
+
+		return results
+	}).Interface()
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// memoKey builds the cache key for a call of the named function with the given arguments.
+func memoKey(name string, args []reflect.Value) string {
+	var key strings.Builder
+	key.WriteString(name)
+	for _, arg := range args {
+		key.WriteByte(0)
+		fmt.Fprintf(&key, "%#v", arg.Interface())
+	}
+	return key.String()
+}
+
+// withRequestHelpers returns the request-scoped functions of the options, along with the request helpers when the
+// RequestHelpers option is set and the URL functions when the URLBuilder option is.
+func withRequestHelpers(opts TemplateViewAdapterOptions) template.FuncMap {
+	var helpers template.FuncMap
+	if opts.RequestHelpers {
+		helpers = request.Funcs(nil)
+	}
+	if opts.URLBuilder != nil {
+		helpers = MergeFuncs(helpers, opts.URLBuilder.Funcs(nil))
+	}
+	if helpers == nil {
+		return opts.RequestFuncs
+	}
+	return MergeFuncs(helpers, opts.RequestFuncs)
+}
+
+// requestHelpers returns the names of the request helpers and URL functions bound to each request, those RequestFuncs
+// does not override, sorted.
+func requestHelpers(opts TemplateViewAdapterOptions) []string {
+	var names []string
+	for name := range withRequestHelpers(opts) {
+		if _, ok := opts.RequestFuncs[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// helperFuncs returns the request helpers of request.Funcs and the functions of the URL builder, if any, bound to the
+// request.
+func helperFuncs(r *http.Request, urls *request.URLBuilder) template.FuncMap {
+	if urls == nil {
+		return request.Funcs(r)
+	}
+	return MergeFuncs(request.Funcs(r), urls.Funcs(r))
+}
@@ -0,0 +1,222 @@
+package hyperview_test
+
+import (
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/request"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func TestTemplateAdapter_MemoFuncs(t *testing.T) {
+	calls := map[string]int{}
+	settings := func(key string) (string, error) {
+		calls[key]++
+		return "value:" + key, nil
+	}
+
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{
+			"layouts/base.html":    {Data: []byte(`{{define "layout:base"}}{{settings "name"}}|{{template "@footer" .}}|{{template "page:main" .}}{{end}}`)},
+			"partials/footer.html": {Data: []byte(`{{define "@footer"}}{{settings "name"}}{{end}}`)},
+			"views/home.html":      {Data: []byte(`{{define "page:main"}}{{settings "name"}}{{settings "other"}}{{end}}`)},
+		}},
+		MemoFuncs: template.FuncMap{"settings": settings},
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		w := renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("home"))
+		if got, want := w.Body.String(), "value:name|value:name|value:namevalue:other"; got != want {
+			t.Fatalf("unexpected body: got %q, want %q", got, want)
+		}
+	}
+
+	// Each key is looked up once per render
+	if calls["name"] != 2 || calls["other"] != 2 {
+		t.Errorf("unexpected calls: %v", calls)
+	}
+}
+
+func TestTemplateAdapter_RequestFuncs(t *testing.T) {
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{
+			"layouts/base.html": {Data: []byte(`{{define "layout:base"}}{{currentUser}}|{{csrfToken}}{{end}}`)},
+			"views/home.html":   {Data: []byte(`{{define "page:main"}}{{end}}`)},
+		}},
+		RequestFuncs: template.FuncMap{
+			"currentUser": func() string { return "guest" },
+			"csrfToken":   func() string { return "" },
+		},
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		ctx     template.FuncMap
+		resp    template.FuncMap
+		want    string
+		wantErr bool
+	}{
+		{name: "defaults", want: "guest|"},
+		{
+			name: "context funcs",
+			ctx:  template.FuncMap{"csrfToken": func() string { return "ctx-token" }, "other": func() string { return "" }},
+			want: "guest|ctx-token",
+		},
+		{
+			name: "response funcs override context funcs",
+			ctx:  template.FuncMap{"currentUser": func() string { return "ctx-user" }},
+			resp: template.FuncMap{"currentUser": func() string { return "alice" }},
+			want: "alice|",
+		},
+		{
+			name:    "undeclared response func",
+			resp:    template.FuncMap{"unknown": func() string { return "" }},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.ctx != nil {
+				r = r.WithContext(hyperview.ContextWithFuncs(r.Context(), tt.ctx))
+			}
+			w := httptest.NewRecorder()
+			adapter.Render(w, r, response.NewResponse().Layout("base").Path("home").WithFuncs(tt.resp))
+
+			if tt.wantErr {
+				if w.Code != http.StatusInternalServerError {
+					t.Errorf("expected an error response, got %d", w.Code)
+				}
+				return
+			}
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("unexpected body: got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplateAdapter_RequestHelpers(t *testing.T) {
+	nav := `{{define "@nav"}}<a href="/admin"{{if isActive "/admin"}} class="active"{{end}}>Admin</a>{{end}}`
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{
+			"layouts/base.html": {Data: []byte(`{{define "layout:base"}}{{template "@nav" .}}|{{template "page:main" .}}{{end}}`)},
+			"partials/nav.html": {Data: []byte(nav)},
+			"views/home.html":   {Data: []byte(`{{define "page:main"}}{{currentPath}}|{{queryParam "page"}}|{{fullURL}}{{end}}`)},
+		}},
+		RequestHelpers: true,
+		RequestFuncs:   template.FuncMap{"fullURL": func() string { return "canonical" }},
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{target: "/admin/users?page=2", want: `<a href="/admin" class="active">Admin</a>|/admin/users|2|canonical`},
+		{target: "/administrators", want: `<a href="/admin">Admin</a>|/administrators||canonical`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			adapter.Render(w, httptest.NewRequest(http.MethodGet, tt.target, nil), response.NewResponse().Layout("base").Path("home"))
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("unexpected body: got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplateAdapter_URLBuilder(t *testing.T) {
+	urls, err := request.NewURLBuilder(request.URLOptions{TrustedProxies: []string{"192.0.2.0/24"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{
+			"layouts/base.html": {Data: []byte(`{{define "layout:base"}}<link rel="canonical" href="{{canonicalURL}}">{{template "page:main" .}}{{end}}`)},
+			"views/home.html":   {Data: []byte(`{{define "page:main"}}<meta property="og:image" content="{{absURL "/cover.png"}}">{{end}}`)},
+		}},
+		URLBuilder: urls,
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "http://internal/posts?utm_source=x", nil)
+	r.Header.Set("X-Forwarded-Proto", "https")
+	r.Header.Set("X-Forwarded-Host", "example.com")
+	w := httptest.NewRecorder()
+	adapter.Render(w, r, response.NewResponse().Layout("base").Path("home"))
+
+	want := `<link rel="canonical" href="https://example.com/posts"><meta property="og:image" content="https://example.com/cover.png">`
+	if got := w.Body.String(); got != want {
+		t.Errorf("unexpected body: got %q, want %q", got, want)
+	}
+}
+
+func TestTemplateAdapter_RequestFuncs_Concurrent(t *testing.T) {
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{
+			"layouts/base.html":  {Data: []byte(`{{define "layout:base"}}{{template "@user" .}}|{{template "page:main" .}}{{end}}`)},
+			"partials/user.html": {Data: []byte(`{{define "@user"}}{{currentUser}}{{end}}`)},
+			"views/home.html":    {Data: []byte(`{{define "page:main"}}{{currentUser}}{{end}}`)},
+		}},
+		RequestFuncs: template.FuncMap{"currentUser": func() string { return "guest" }},
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+
+	// The clones of the page template set are reused across renders, so each render must see its own functions
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 10 {
+				user := fmt.Sprintf("user-%d-%d", i, j)
+				r := httptest.NewRequest(http.MethodGet, "/", nil)
+				r = r.WithContext(hyperview.ContextWithFuncs(r.Context(), template.FuncMap{"currentUser": func() string { return user }}))
+				w := httptest.NewRecorder()
+				adapter.Render(w, r, response.NewResponse().Layout("base").Path("home"))
+				if got, want := w.Body.String(), user+"|"+user; got != want {
+					t.Errorf("unexpected body: got %q, want %q", got, want)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestMergeFuncs(t *testing.T) {
+	merged := hyperview.MergeFuncs(
+		template.FuncMap{"a": func() string { return "a1" }, "b": func() string { return "b" }},
+		template.FuncMap{"a": func() string { return "a2" }},
+	)
+
+	if len(merged) != 2 {
+		t.Fatalf("expected 2 functions, got %d", len(merged))
+	}
+	if got := merged["a"].(func() string)(); got != "a2" {
+		t.Errorf("expected later maps to take precedence, got %s", got)
+	}
+}
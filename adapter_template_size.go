@@ -0,0 +1,48 @@
+package hyperview
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/hypergopher/hyperview/response"
+)
+
+// ErrRenderTooLarge is returned by renders aborted because their body exceeded the maximum render size of the adapter
+// (see TemplateViewAdapterOptions.MaxRenderSize). It is reported to the render hook, wrapped with the name of the
+// view.
+var ErrRenderTooLarge = errors.New("render too large")
+
+// sizeLimitWriter is a writer failing with ErrRenderTooLarge once more than its limit has been written, which aborts
+// the execution of templates writing to it.
+type sizeLimitWriter struct {
+	w         io.Writer
+	remaining int64
+}
+
+// limitWriter returns w, failing once more than limit bytes have been written if limit is positive.
+func limitWriter(w io.Writer, limit int64) io.Writer {
+	if limit <= 0 {
+		return w
+	}
+	return &sizeLimitWriter{w: w, remaining: limit}
+}
+
+func (s *sizeLimitWriter) Write(p []byte) (int, error) {
+	if int64(len(p)) > s.remaining {
+		return 0, ErrRenderTooLarge
+	}
+	s.remaining -= int64(len(p))
+	return s.w.Write(p)
+}
+
+// handleTooLarge logs a render aborted for exceeding the maximum render size and answers it with a server error. It
+// returns the error reported to the render hook.
+func (a *TemplateAdapter) handleTooLarge(w http.ResponseWriter, resp *response.Response) error {
+	a.log().Error("Render too large", slog.String("template", resp.TemplatePath()), slog.String("layout", resp.TemplateLayout()),
+		slog.Int64("limit", a.maxRenderSize))
+	http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	return fmt.Errorf("%w: %s exceeds %d bytes", ErrRenderTooLarge, resp.TemplatePath(), a.maxRenderSize)
+}
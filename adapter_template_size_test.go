@@ -0,0 +1,56 @@
+package hyperview_test
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func TestTemplateAdapter_MaxRenderSize(t *testing.T) {
+	var events []hyperview.RenderEvent
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{
+			"layouts/base.html": {Data: []byte(`{{define "layout:base"}}<ul>{{template "page:main" .}}</ul>{{end}}`)},
+			"views/items.html":  {Data: []byte(`{{define "page:main"}}{{range .Items}}<li>{{.}}</li>{{end}}{{end}}`)},
+		}},
+		MaxRenderSize: 1024,
+		OnRender: func(r *http.Request, event hyperview.RenderEvent) {
+			events = append(events, event)
+		},
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		items      int
+		wantStatus int
+		wantErr    error
+	}{
+		{"within the limit", 10, http.StatusOK, nil},
+		{"over the limit", 1000, http.StatusInternalServerError, hyperview.ErrRenderTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events = nil
+			w := renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("items").Data(map[string]any{"Items": make([]int, tt.items)}))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("unexpected status: got %d, want %d", w.Code, tt.wantStatus)
+			}
+			if w.Body.Len() > 1024 {
+				t.Errorf("body exceeds the limit: %d bytes", w.Body.Len())
+			}
+			if len(events) != 1 || !errors.Is(events[0].Err, tt.wantErr) {
+				t.Errorf("unexpected render events: %+v", events)
+			}
+		})
+	}
+}
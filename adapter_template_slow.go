@@ -0,0 +1,148 @@
+package hyperview
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/hypergopher/hyperview/response"
+)
+
+// SlowRenderOptions configure the reporting of the renders slower than a threshold, logged at the warning level with
+// the logger of the adapter whatever the sampling of the render log.
+type SlowRenderOptions struct {
+	// Threshold is the duration of the slowest render not reported, from the start of the render to the body being
+	// written, loaders included. Slow renders are not reported when zero.
+	Threshold time.Duration
+	// Trace times each template executed by the renders, so slow renders report the time spent in each partial. Like
+	// the debug toolbar, tracing marks the boundaries of the templates in the rendered markup, which costs a few
+	// percent of render time on pages with many partials.
+	Trace bool
+	// OnSlowRender is called after each slow render, e.g. to alert or to record the render in an error tracker.
+	OnSlowRender func(r *http.Request, render SlowRender)
+}
+
+// SlowRender describes a render slower than the threshold of the SlowRenders option.
+type SlowRender struct {
+	// Template is the path of the rendered view template (e.g. "views/home").
+	Template string
+	// Layout is the layout the view was rendered with.
+	Layout string
+	// Duration is the time spent loading data, executing the template and writing the response.
+	Duration time.Duration
+	// Size is the number of bytes in the rendered body.
+	Size int
+	// DataSize is the size of the view data encoded as JSON, in bytes, or -1 if it cannot be encoded.
+	DataSize int
+	// Templates is the time spent in each template, the slowest first, when tracing is enabled.
+	Templates []TemplateTiming
+}
+
+// TemplateTiming is the time spent executing a template during a render.
+type TemplateTiming struct {
+	// Name is the name of the template, e.g. @card or page:main.
+	Name string
+	// File is the file defining the template, e.g. partials/card.html.
+	File string
+	// Calls is the number of times the template was executed.
+	Calls int
+	// Total is the time spent in the template, including the templates it executed.
+	Total time.Duration
+	// Self is the time spent in the template itself, excluding the templates it executed.
+	Self time.Duration
+}
+
+// slowRenderLogTemplates is the number of templates logged with a slow render, the slowest.
+const slowRenderLogTemplates = 5
+
+// reportSlowRender reports the render started at start if it is slower than the threshold of the SlowRenders option,
+// with the time spent in the templates rendered when tracing is enabled.
+func (a *TemplateAdapter) reportSlowRender(r *http.Request, resp *response.Response, start time.Time, size int, data map[string]any, rendered []renderedTemplate) {
+	duration := time.Since(start)
+	if a.slowRenders.Threshold <= 0 || duration <= a.slowRenders.Threshold {
+		return
+	}
+
+	render := SlowRender{
+		Template: resp.TemplatePath(),
+		Layout:   resp.TemplateLayout(),
+		Duration: duration,
+		Size:     size,
+		DataSize: -1,
+	}
+	if encoded, err := json.Marshal(data); err == nil {
+		render.DataSize = len(encoded)
+	}
+	if a.slowRenders.Trace && a.debug != nil {
+		render.Templates = templateTimings(rendered, a.debug.offset(start))
+	}
+
+	attrs := []slog.Attr{
+		slog.String("template", render.Template),
+		slog.String("layout", render.Layout),
+		slog.Duration("duration", render.Duration),
+		slog.Duration("threshold", a.slowRenders.Threshold),
+		slog.Int("bytes", render.Size),
+		slog.Int("data_bytes", render.DataSize),
+	}
+	if len(render.Templates) > 0 {
+		var slowest []any
+		for _, timing := range render.Templates[:min(len(render.Templates), slowRenderLogTemplates)] {
+			slowest = append(slowest, slog.Group(timing.Name, slog.Duration("self", timing.Self),
+				slog.Duration("total", timing.Total), slog.Int("calls", timing.Calls)))
+		}
+		attrs = append(attrs, slog.Group("templates", slowest...))
+	}
+	a.log().LogAttrs(r.Context(), slog.LevelWarn, "Slow render", attrs...)
+
+	if a.slowRenders.OnSlowRender != nil {
+		a.slowRenders.OnSlowRender(r, render)
+	}
+}
+
+// templateTimings aggregates the times of the templates rendered by name, the slowest first, by time spent in the
+// template itself. Templates begun before the render started, replayed from the cached blocks of earlier renders, are
+// left out, and so is the time they took from the templates executing them.
+func templateTimings(rendered []renderedTemplate, renderStart time.Duration) []TemplateTiming {
+	children := make([]time.Duration, len(rendered))
+	var stack []int
+	for i, t := range rendered {
+		for len(stack) > 0 && rendered[stack[len(stack)-1]].depth >= t.depth {
+			stack = stack[:len(stack)-1]
+		}
+		live := t.timed && t.begin >= renderStart
+		if live && len(stack) > 0 {
+			children[stack[len(stack)-1]] += t.elapsed
+		}
+		stack = append(stack, i)
+	}
+
+	byName := make(map[debugTemplate]*TemplateTiming)
+	for i, t := range rendered {
+		if !t.timed || t.begin < renderStart {
+			continue
+		}
+		timing, ok := byName[t.debugTemplate]
+		if !ok {
+			timing = &TemplateTiming{Name: t.name, File: t.file}
+			byName[t.debugTemplate] = timing
+		}
+		timing.Calls++
+		timing.Total += t.elapsed
+		timing.Self += t.elapsed - children[i]
+	}
+
+	timings := make([]TemplateTiming, 0, len(byName))
+	for _, timing := range byName {
+		timings = append(timings, *timing)
+	}
+	sort.Slice(timings, func(i, j int) bool {
+		if timings[i].Self != timings[j].Self {
+			return timings[i].Self > timings[j].Self
+		}
+		return timings[i].Name < timings[j].Name
+	})
+	return timings
+}
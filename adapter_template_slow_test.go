@@ -0,0 +1,83 @@
+package hyperview_test
+
+import (
+	"bytes"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func TestTemplateAdapter_SlowRenders(t *testing.T) {
+	var logs bytes.Buffer
+	var reports []hyperview.SlowRender
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{
+			"layouts/base.html":    {Data: []byte(`{{define "layout:base"}}<body>{{template "page:main" .}}</body>{{end}}`)},
+			"partials/chart.html":  {Data: []byte(`{{define "@chart"}}<svg>{{slowRenderWork .}}</svg>{{end}}`)},
+			"partials/footer.html": {Data: []byte(`{{define "@footer"}}<footer></footer>{{end}}`)},
+			"views/dashboard.html": {Data: []byte(`{{define "page:main"}}{{template "@chart" .Delay}}{{template "@footer"}}{{end}}`)},
+		}},
+		Funcs: map[string]any{
+			"slowRenderWork": func(delay time.Duration) string {
+				time.Sleep(delay)
+				return ""
+			},
+		},
+		SlowRenders: hyperview.SlowRenderOptions{
+			Threshold:    10 * time.Millisecond,
+			Trace:        true,
+			OnSlowRender: func(r *http.Request, render hyperview.SlowRender) { reports = append(reports, render) },
+		},
+		Logger: slog.New(slog.NewTextHandler(&logs, nil)),
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+
+	// Fast renders are not reported, and tracing leaves no marks in the markup
+	w := renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("dashboard").AddDataItem("Delay", time.Duration(0)))
+	if got := w.Body.String(); got != "<body><svg></svg><footer></footer></body>" {
+		t.Errorf("unexpected body: %s", got)
+	}
+	if len(reports) != 0 {
+		t.Fatalf("expected no slow render, got %+v", reports)
+	}
+
+	renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("dashboard").AddDataItem("Delay", 20*time.Millisecond))
+	if len(reports) != 1 {
+		t.Fatalf("expected a slow render, got %d", len(reports))
+	}
+	report := reports[0]
+	if report.Template != "views/dashboard" || report.Layout != "base" || report.Duration < 20*time.Millisecond ||
+		report.DataSize < len(`{"Delay":20000000}`) {
+		t.Errorf("unexpected report: %+v", report)
+	}
+
+	// The partial responsible comes first, with the time spent in it excluded from the templates executing it
+	timings := map[string]hyperview.TemplateTiming{}
+	for _, timing := range report.Templates {
+		timings[timing.Name] = timing
+	}
+	if len(report.Templates) != 4 || report.Templates[0].Name != "@chart" || report.Templates[0].File != "partials/chart.html" {
+		t.Fatalf("expected the chart partial to be the slowest, got %+v", report.Templates)
+	}
+	if chart := timings["@chart"]; chart.Calls != 1 || chart.Self < 20*time.Millisecond || chart.Self != chart.Total {
+		t.Errorf("unexpected chart timing: %+v", chart)
+	}
+	if main := timings["page:main"]; main.Total < 20*time.Millisecond || main.Self >= 20*time.Millisecond {
+		t.Errorf("unexpected page timing: %+v", main)
+	}
+
+	if log := logs.String(); !strings.Contains(log, `level=WARN msg="Slow render" template=views/dashboard`) ||
+		!strings.Contains(log, "templates.@chart.self=") {
+		t.Errorf("unexpected logs: %s", log)
+	}
+}
@@ -0,0 +1,261 @@
+package hyperview
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"maps"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/htmx"
+	"github.com/hypergopher/hyperview/response"
+)
+
+// deferredPrefix prefixes the names of the templates rendering the deferred sections of a page.
+const deferredPrefix = "deferred:"
+
+// deferredFunc returns the deferred function, rendering the deferred section of the key with dot, the view data:
+//
+//	{{deferred "stats" .}}
+//
+// The section is the template named deferred:<key>, executed with the view data holding the result of the deferred
+// loader under the key. When the result is not loaded yet, as the page is streamed, deferred renders a placeholder
+// instead, holding the template named deferred:<key>:loading, if any, which the section replaces once streamed.
+func deferredFunc(tmpl *template.Template) func(key string, dot any) (template.HTML, error) {
+	return func(key string, dot any) (template.HTML, error) {
+		if tmpl == nil {
+			return "", fmt.Errorf("deferred section %s rendered outside of a template set", key)
+		}
+
+		buf := new(bytes.Buffer)
+		if data, ok := dot.(map[string]any); ok {
+			if _, loaded := data[key]; loaded {
+				if err := tmpl.ExecuteTemplate(buf, deferredPrefix+key, data); err != nil {
+					return "", fmt.Errorf("error rendering deferred section %s: %w", key, err)
+				}
+				return template.HTML(buf.String()), nil
+			}
+		}
+
+		id := template.HTMLEscapeString(deferredID(key))
+		buf.WriteString(`<div id="` + id + `" data-hv-deferred>`)
+		if loading := tmpl.Lookup(deferredPrefix + key + ":loading"); loading != nil {
+			if err := loading.Execute(buf, dot); err != nil {
+				return "", fmt.Errorf("error rendering deferred section %s: %w", key, err)
+			}
+		}
+		buf.WriteString(`</div>`)
+		return template.HTML(buf.String()), nil
+	}
+}
+
+// deferredID returns the ID of the placeholder of the deferred section of the key.
+func deferredID(key string) string {
+	return "hv-deferred-" + key
+}
+
+// streamable reports whether the response is streamed: it has deferred loaders, is not cached and is not for HTMX,
+// which swaps responses once complete, and the writer can flush.
+func (a *TemplateAdapter) streamable(w http.ResponseWriter, r *http.Request, resp *response.Response) bool {
+	if len(resp.DeferredKeys()) == 0 || resp.CacheKey() != "" || htmx.IsAnyHtmxRequest(r) {
+		return false
+	}
+	_, ok := w.(http.Flusher)
+	return ok
+}
+
+// deferredScript defines the function moving a streamed section into its placeholder, written before the first one.
+const deferredScript = `window.hvDeferred=function(k){var t=document.getElementById("hv-deferred-"+k+"-content"),` +
+	`p=document.getElementById("hv-deferred-"+k);if(t&&p){p.replaceChildren(t.content);p.removeAttribute("data-hv-deferred");` +
+	`if(window.htmx)htmx.process(p)}if(t)t.remove()};`
+
+// streamTemplate renders the page with deferred sections, flushing the page without them as soon as the other loaders
+// returned, then streaming each section before the closing body tag as its deferred loader returns, in the order they
+// return. The page goes through the render middleware, but not the sections, so the page is neither compressed nor
+// conditional. The sections are rendered with the render context, so the render timeout still applies once the page
+// is flushed.
+//
+// The status is sent with the page, so the sections failing to load or render keep their placeholder, and are logged.
+//
+// The sections are moved into their placeholders by inline scripts carrying the CSP nonce of the request, which the
+// Content-Security-Policy set on the response by the handler or the render middleware, such as the hash policy of
+// csp.HashMiddleware, may not allow. The page is then not streamed: streamTemplate waits for the deferred loaders, adds
+// their results to the view data and returns false, so the page is rendered with the sections in place.
+func (a *TemplateAdapter) streamTemplate(ctx context.Context, w http.ResponseWriter, r *http.Request, resp *response.Response, tmpl *template.Template, layout, pageName string, start time.Time) bool {
+	// The deferred loaders start first, so they run along with the others
+	results := resp.RunDeferred(ctx, a.loaderConcurrency)
+
+	var (
+		data     map[string]any
+		partial  []byte
+		rendered []renderedTemplate
+	)
+	render := func(r *http.Request, resp *response.Response) ([]byte, error) {
+		if err := resp.RunLoaders(ctx, a.loaderConcurrency); err != nil {
+			return nil, err
+		}
+
+		data = resp.ViewData(r).Data()
+		if err := a.mapViewModels(data); err != nil {
+			return nil, err
+		}
+		if err := a.validateData(resp, pageName, data); err != nil {
+			return nil, err
+		}
+
+		buf := new(bytes.Buffer)
+		if err := tmpl.ExecuteTemplate(renderWriter(ctx, limitWriter(buf, a.maxRenderSize)), layout, data); err != nil {
+			partial = buf.Bytes()
+			if renderAborted(ctx, resp, err) != nil || errors.Is(err, ErrRenderTooLarge) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("error executing template: %w", missingKeyError(err))
+		}
+
+		shell := a.loadedComponentAssets().inject(r, buf.Bytes(), tmpl, layout, pageName, resp.TemplateLayout())
+		var body []byte
+		body, rendered = a.debugBody(annotateVariants(shell, resp.Variants()))
+		return body, nil
+	}
+
+	headers := maps.Clone(resp.Headers())
+	body, err := a.chainRender(render)(a.middlewareRequest(r), resp)
+	if err != nil {
+		a.handleExecError(ctx, w, r, resp, pageName, start, err, partial, data)
+		return true
+	}
+
+	if !streamScriptsAllowed(w, r, resp) {
+		// The headers set by the render middleware are dropped, as it runs again on the page with its sections
+		maps.DeleteFunc(resp.Headers(), func(key, _ string) bool { return true })
+		maps.Copy(resp.Headers(), headers)
+		for result := range results {
+			if result.Err != nil {
+				a.handleExecError(ctx, w, r, resp, pageName, start, result.Err, nil, data)
+				return true
+			}
+			resp.AddDataItem(result.Key, result.Value)
+		}
+		return false
+	}
+
+	head, tail := body, []byte(nil)
+	if i := bytes.LastIndex(body, []byte("</body>")); i >= 0 {
+		head, tail = body[:i], body[i:]
+	}
+
+	for key, value := range resp.Headers() {
+		w.Header().Set(key, value)
+	}
+	// Proxies such as nginx buffer responses unless told otherwise
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(resp.StatusCode())
+
+	flusher := w.(http.Flusher)
+	size, err := w.Write(head)
+	if err != nil {
+		a.notifyRender(r, resp, start, size, err)
+		return true
+	}
+	flusher.Flush()
+
+	nonce := nonceAttr(r)
+
+	script := deferredScript
+	for result := range results {
+		section, err := a.renderSection(ctx, tmpl, data, result)
+		if err != nil {
+			a.log().Error("Deferred section failed", slog.String("template", resp.TemplatePath()),
+				slog.String("section", result.Key), slog.String("err", err.Error()))
+			// Past the render timeout, the other sections are left with their placeholder
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+
+		id := deferredID(result.Key)
+		chunk := `<template id="` + template.HTMLEscapeString(id) + `-content">` + section + `</template><script` + nonce +
+			`>` + script + `hvDeferred("` + template.JSEscapeString(result.Key) + `")</script>`
+		script = ""
+
+		n, err := w.Write([]byte(chunk))
+		size += n
+		if err != nil {
+			a.notifyRender(r, resp, start, size, err)
+			return true
+		}
+		flusher.Flush()
+	}
+
+	n, err := w.Write(tail)
+	a.notifyRender(r, resp, start, size+n, err)
+	if err == nil {
+		a.reportSlowRender(r, resp, start, size+n, data, rendered)
+	}
+	return true
+}
+
+// streamScriptsAllowed reports whether the Content-Security-Policy headers set on the response, if any, allow the
+// scripts carrying the CSP nonce of the request, which move the streamed sections into their placeholders. Both the
+// headers of the response and those already set on w, e.g. by an HTTP middleware such as csp.Middleware, are checked.
+func streamScriptsAllowed(w http.ResponseWriter, r *http.Request, resp *response.Response) bool {
+	nonce, _ := r.Context().Value(constants.NonceContextKey).(string)
+	allows := func(policy string) bool {
+		return nonce != "" && strings.Contains(policy, "'nonce-"+nonce+"'")
+	}
+	for _, header := range []string{"Content-Security-Policy", "Content-Security-Policy-Report-Only"} {
+		if policy, ok := resp.Headers()[header]; ok && !allows(policy) {
+			return false
+		}
+		for _, policy := range w.Header().Values(header) {
+			if !allows(policy) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// renderSection renders the deferred section of the result with the view data of the page, until ctx is done.
+func (a *TemplateAdapter) renderSection(ctx context.Context, tmpl *template.Template, data map[string]any, result response.DeferredResult) (string, error) {
+	if result.Err != nil {
+		return "", result.Err
+	}
+
+	value := result.Value
+	if a.viewModels != nil {
+		mapped, err := a.viewModels.Map(value)
+		if err != nil {
+			return "", fmt.Errorf("error mapping view data %s: %w", result.Key, err)
+		}
+		value = mapped
+	}
+
+	sectionData := maps.Clone(data)
+	if sectionData == nil {
+		sectionData = make(map[string]any, 1)
+	}
+	sectionData[result.Key] = value
+	buf := new(bytes.Buffer)
+	if err := tmpl.ExecuteTemplate(renderWriter(ctx, limitWriter(buf, a.maxRenderSize)), deferredPrefix+result.Key, sectionData); err != nil {
+		return "", fmt.Errorf("error rendering deferred section %s: %w", result.Key, missingKeyError(err))
+	}
+	section, _ := a.debugBody(buf.Bytes())
+	return string(section), nil
+}
+
+// nonceAttr returns the nonce attribute of the scripts added to the body of a render, with the CSP nonce of the
+// request set by the csp middleware, or an empty string without one.
+func nonceAttr(r *http.Request) string {
+	if fn, ok := FuncsFromContext(r.Context())["cspNonce"].(func() string); ok {
+		return ` nonce="` + template.HTMLEscapeString(fn()) + `"`
+	}
+	return ""
+}
@@ -0,0 +1,181 @@
+package hyperview_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/csp"
+	"github.com/hypergopher/hyperview/hyperviewtest"
+	"github.com/hypergopher/hyperview/response"
+)
+
+// flushRecorder is a ResponseRecorder sending the body written so far on each flush.
+type flushRecorder struct {
+	*httptest.ResponseRecorder
+	flushes chan string
+}
+
+func (f *flushRecorder) Flush() {
+	f.flushes <- f.Body.String()
+}
+
+func newStreamingTestAdapter(t *testing.T) *hyperview.TemplateAdapter {
+	t.Helper()
+
+	return hyperviewtest.NewAdapter(t, map[string]string{
+		"layouts/base.html": `{{define "layout:base"}}<html><body>{{template "page:main" .}}</body></html>{{end}}`,
+		"views/dashboard.html": `{{define "page:main"}}<h1>{{.Title}}</h1>{{deferred "stats" .}}{{end}}` +
+			`{{define "deferred:stats"}}<p>{{.Title}}: {{.stats}}</p>{{end}}` +
+			`{{define "deferred:stats:loading"}}Loading{{end}}`,
+	}, hyperview.TemplateViewAdapterOptions{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+}
+
+func TestTemplateAdapter_Stream(t *testing.T) {
+	adapter := newStreamingTestAdapter(t)
+
+	release := make(chan struct{})
+	resp := response.NewResponse().Layout("base").Path("dashboard").AddDataItem("Title", "Sales").
+		Defer("stats", func(ctx context.Context) (any, error) {
+			<-release
+			return 42, nil
+		})
+
+	w := &flushRecorder{ResponseRecorder: httptest.NewRecorder(), flushes: make(chan string, 2)}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		adapter.Render(w, httptest.NewRequest(http.MethodGet, "/", nil), resp)
+	}()
+
+	// The page is flushed with the placeholder before the deferred loader returns
+	if got, want := <-w.flushes, `<html><body><h1>Sales</h1><div id="hv-deferred-stats" data-hv-deferred>Loading</div>`; got != want {
+		t.Errorf("unexpected first flush:\ngot  %s\nwant %s", got, want)
+	}
+
+	close(release)
+	<-done
+
+	body := w.Body.String()
+	if !strings.Contains(body, `<template id="hv-deferred-stats-content"><p>Sales: 42</p></template><script>window.hvDeferred=`) ||
+		!strings.HasSuffix(body, `hvDeferred("stats")</script></body></html>`) {
+		t.Errorf("expected the section to be streamed before the closing body tag, got %s", body)
+	}
+	if w.Code != http.StatusOK || w.Header().Get("X-Accel-Buffering") != "no" {
+		t.Errorf("unexpected status %d and headers %v", w.Code, w.Header())
+	}
+}
+
+func TestTemplateAdapter_StreamInline(t *testing.T) {
+	adapter := newStreamingTestAdapter(t)
+
+	// HTMX requests wait for the deferred loaders and render the sections in place
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("HX-Request", "true")
+	w := httptest.NewRecorder()
+	adapter.Render(w, r, response.NewResponse().Layout("base").Path("dashboard").AddDataItem("Title", "Sales").
+		Defer("stats", func(ctx context.Context) (any, error) { return 42, nil }))
+
+	if got, want := w.Body.String(), `<html><body><h1>Sales</h1><p>Sales: 42</p></body></html>`; got != want {
+		t.Errorf("unexpected body:\ngot  %s\nwant %s", got, want)
+	}
+}
+
+func TestTemplateAdapter_StreamSectionError(t *testing.T) {
+	adapter := newStreamingTestAdapter(t)
+
+	resp := response.NewResponse().Layout("base").Path("dashboard").AddDataItem("Title", "Sales").
+		Defer("stats", func(ctx context.Context) (any, error) { return nil, errors.New("boom") })
+	w := renderTestTemplate(t, adapter, resp)
+
+	// The status is sent with the page, so the failed section keeps its placeholder
+	want := `<html><body><h1>Sales</h1><div id="hv-deferred-stats" data-hv-deferred>Loading</div></body></html>`
+	if w.Code != http.StatusOK || w.Body.String() != want {
+		t.Errorf("unexpected response %d:\ngot  %s\nwant %s", w.Code, w.Body.String(), want)
+	}
+}
+
+func TestTemplateAdapter_StreamSectionTimeout(t *testing.T) {
+	adapter := hyperviewtest.NewAdapter(t, map[string]string{
+		"layouts/base.html": `{{define "layout:base"}}<html><body>{{template "page:main" .}}</body></html>{{end}}`,
+		"views/dashboard.html": `{{define "page:main"}}{{deferred "stats" .}}{{end}}` +
+			`{{define "deferred:stats"}}{{sleep_}}<p>{{.stats}}</p>{{end}}`,
+	}, hyperview.TemplateViewAdapterOptions{
+		Funcs:         map[string]any{"sleep_": func() string { time.Sleep(50 * time.Millisecond); return "" }},
+		RenderTimeout: 20 * time.Millisecond,
+		Logger:        slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+
+	w := &flushRecorder{ResponseRecorder: httptest.NewRecorder(), flushes: make(chan string, 2)}
+	adapter.Render(w, httptest.NewRequest(http.MethodGet, "/", nil), response.NewResponse().Layout("base").Path("dashboard").
+		Defer("stats", func(ctx context.Context) (any, error) { return 42, nil }))
+
+	// The render timeout applies to the sections streamed after the page
+	want := `<html><body><div id="hv-deferred-stats" data-hv-deferred></div></body></html>`
+	if got := w.Body.String(); got != want {
+		t.Errorf("expected the section past the render timeout to keep its placeholder:\ngot  %s\nwant %s", got, want)
+	}
+}
+
+func TestTemplateAdapter_StreamHashPolicy(t *testing.T) {
+	adapter := hyperviewtest.NewAdapter(t, map[string]string{
+		"layouts/base.html": `{{define "layout:base"}}<html><body>{{template "page:main" .}}</body></html>{{end}}`,
+		"views/dashboard.html": `{{define "page:main"}}<script>init()</script>{{deferred "stats" .}}{{end}}` +
+			`{{define "deferred:stats"}}<p>{{.stats}}</p><style>p{}</style>{{end}}`,
+	}, hyperview.TemplateViewAdapterOptions{
+		RenderMiddleware: []hyperview.RenderMiddleware{csp.HashMiddleware(csp.HashOptions{Policy: "script-src {script-hashes}; style-src {style-hashes}"})},
+	})
+
+	w := &flushRecorder{ResponseRecorder: httptest.NewRecorder(), flushes: make(chan string, 2)}
+	adapter.Render(w, httptest.NewRequest(http.MethodGet, "/", nil), response.NewResponse().Layout("base").Path("dashboard").
+		Defer("stats", func(ctx context.Context) (any, error) { return 42, nil }))
+
+	// The hash policy would block the scripts moving streamed sections, so the sections are rendered in place, and
+	// hashed with the page
+	if got, want := w.Body.String(), `<html><body><script>init()</script><p>42</p><style>p{}</style></body></html>`; got != want {
+		t.Errorf("expected the page with its section in place:\ngot  %s\nwant %s", got, want)
+	}
+	if got, want := w.Header().Get("Content-Security-Policy"), "script-src "+csp.Hash("init()")+"; style-src "+csp.Hash("p{}"); got != want {
+		t.Errorf("unexpected policy:\ngot  %s\nwant %s", got, want)
+	}
+}
+
+func TestTemplateAdapter_StreamMiddlewarePolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+		stream bool
+	}{
+		{name: "policy allowing the nonce", policy: csp.DefaultPolicy, stream: true},
+		{name: "policy without the nonce", policy: "script-src 'self'", stream: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := newStreamingTestAdapter(t)
+			handler := csp.Middleware(csp.Options{Policy: tt.policy})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				adapter.Render(w, r, response.NewResponse().Layout("base").Path("dashboard").AddDataItem("Title", "Sales").
+					Defer("stats", func(ctx context.Context) (any, error) { return 42, nil }))
+			}))
+
+			w := &flushRecorder{ResponseRecorder: httptest.NewRecorder(), flushes: make(chan string, 2)}
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			// The policy set on the writer by the HTTP middleware would block the scripts moving the sections
+			body := w.Body.String()
+			if streamed := strings.Contains(body, `hvDeferred("stats")`); streamed != tt.stream {
+				t.Errorf("expected streamed %t, got %s", tt.stream, body)
+			}
+			if !tt.stream && body != `<html><body><h1>Sales</h1><p>Sales: 42</p></body></html>` {
+				t.Errorf("expected the section in place, got %s", body)
+			}
+		})
+	}
+}
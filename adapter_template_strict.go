@@ -0,0 +1,70 @@
+package hyperview
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"text/template"
+
+	"github.com/hypergopher/hyperview/response"
+)
+
+// MissingKeyError is returned when a template rendered in strict mode references a key missing from the view data.
+type MissingKeyError struct {
+	// Template is the template referencing the key, with its file location, e.g. `home.html:3:12: "page:main"`.
+	Template string
+	// Path is the field path referencing the key, e.g. ".User.Name".
+	Path string
+	// Key is the missing key, e.g. "Name".
+	Key string
+	// Err is the execution error reported by html/template.
+	Err error
+}
+
+func (e *MissingKeyError) Error() string {
+	return fmt.Sprintf("missing key %q at <%s> in template %s", e.Key, e.Path, e.Template)
+}
+
+func (e *MissingKeyError) Unwrap() error {
+	return e.Err
+}
+
+// missingKeyMessage matches the execution error text/template reports for a missing map key, e.g.
+// `template: home.html:3:12: executing "page:main" at <.User.Name>: map has no entry for key "Name"`.
+var missingKeyMessage = regexp.MustCompile(`^template: (.+?): executing "(.+?)" at <(.+?)>: map has no entry for key "(.+?)"`)
+
+// missingKeyError returns err as a *MissingKeyError if it reports a missing map key, or err itself otherwise.
+func missingKeyError(err error) error {
+	var execErr template.ExecError
+	if !errors.As(err, &execErr) {
+		return err
+	}
+
+	match := missingKeyMessage.FindStringSubmatch(execErr.Err.Error())
+	if match == nil {
+		return err
+	}
+	return &MissingKeyError{
+		Template: fmt.Sprintf("%s: %q", match[1], match[2]),
+		Path:     match[3],
+		Key:      match[4],
+		Err:      err,
+	}
+}
+
+// missingKeyOption returns the html/template option for the strict mode.
+func missingKeyOption(strict bool) string {
+	if strict {
+		return "missingkey=error"
+	}
+	return "missingkey=default"
+}
+
+// strictRender returns whether the response is rendered in strict mode, which is the adapter's StrictMode unless the
+// response overrides it. Frozen adapters only hold the template sets compiled by Freeze, so the override is ignored.
+func (a *TemplateAdapter) strictRender(resp *response.Response) bool {
+	if strict, ok := resp.StrictMode(); ok && !a.frozen.Load() {
+		return strict
+	}
+	return a.strict
+}
@@ -0,0 +1,68 @@
+package hyperview_test
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func TestTemplateAdapter_StrictMode(t *testing.T) {
+	files := fstest.MapFS{
+		"layouts/base.html": {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+		"views/home.html":   {Data: []byte(`{{define "page:main"}}Hello {{.User.Nmae}}{{end}}`)},
+	}
+
+	tests := []struct {
+		name       string
+		strict     bool
+		resp       *response.Response
+		wantStatus int
+	}{
+		{"lenient", false, response.NewResponse(), http.StatusOK},
+		{"lenient with strict render", false, response.NewResponse().Strict(true), http.StatusInternalServerError},
+		{"strict", true, response.NewResponse(), http.StatusInternalServerError},
+		{"strict with lenient render", true, response.NewResponse().Strict(false), http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var renderErr error
+			adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+				FileSystemMap: map[string]fs.FS{constants.RootFSID: files},
+				StrictMode:    tt.strict,
+				OnRender: func(_ *http.Request, event hyperview.RenderEvent) {
+					renderErr = event.Err
+				},
+			})
+			if err := adapter.Init(); err != nil {
+				t.Fatalf("error initializing adapter: %v", err)
+			}
+
+			resp := tt.resp.Layout("base").Path("home").AddDataItem("User", map[string]any{"Name": "Ann"})
+			w := renderTestTemplate(t, adapter, resp)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantStatus == http.StatusOK {
+				return
+			}
+
+			var missing *hyperview.MissingKeyError
+			if !errors.As(renderErr, &missing) {
+				t.Fatalf("expected a MissingKeyError, got %v", renderErr)
+			}
+			if missing.Key != "Nmae" || missing.Path != ".User.Nmae" || !strings.Contains(missing.Template, "home.html") {
+				t.Errorf("unexpected missing key error: %+v", missing)
+			}
+			if !strings.Contains(w.Body.String(), `missing key "Nmae" at <.User.Nmae> in template home.html`) {
+				t.Errorf("expected the error to name the template and key path, got %s", w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package hyperview
+
+import (
+	"fmt"
+	"io/fs"
+	"maps"
+	"strings"
+
+	"github.com/hypergopher/hyperview/constants"
+)
+
+// ParseString adds a template compiled from content, as if it were a file of the root file system, so tests and
+// dynamic features, such as pages assembled at runtime, need no template files. The template resolves layouts and
+// partials like the templates of the file systems, and overrides the file of the same name, if any.
+//
+// The name is the path of the template without the extension, under the layouts, partials or views directory, e.g.
+// partials/card; names outside of these directories are views, e.g. promo for views/promo. The layout is the layout
+// declared by a view, if any, like a {{define "layout"}} definition.
+//
+// Like AddFS, ParseString is safe to call concurrently with renders, only compiles the pages affected, and leaves the
+// templates unchanged if the template fails to compile. Before Init, the template is only registered, and compiled by
+// Init.
+func (a *TemplateAdapter) ParseString(name, layout, content string) error {
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+
+	if a.frozen.Load() {
+		panic("hyperview: ParseString called on a frozen TemplateAdapter")
+	}
+
+	filePath, err := a.stringTemplatePath(name)
+	if err != nil {
+		return err
+	}
+	if layout != "" {
+		if !strings.HasPrefix(filePath, constants.ViewsDir+"/") {
+			return fmt.Errorf("template %s: only views declare a layout", name)
+		}
+		if strings.ContainsAny(layout, " \t\r\n{}") {
+			return fmt.Errorf("template %s: invalid layout name %q", name, layout)
+		}
+		content += `{{define "layout"}}` + layout + `{{end}}`
+	}
+
+	config := a.templateConfig
+	config.stringTemplates = maps.Clone(a.stringTemplates)
+	if config.stringTemplates == nil {
+		config.stringTemplates = make(map[string]string, 1)
+	}
+	config.stringTemplates[filePath] = content
+
+	return a.mount(config)
+}
+
+// stringTemplatePath returns the path of the file of the template added with ParseString under the name.
+func (a *TemplateAdapter) stringTemplatePath(name string) (string, error) {
+	if strings.Contains(name, ":") {
+		return "", fmt.Errorf("template %s: string templates belong to the root file system", name)
+	}
+
+	p := a.normalizeName(name)
+	if p == "" || !fs.ValidPath(p) {
+		return "", fmt.Errorf("invalid template name %q", name)
+	}
+	switch {
+	case strings.HasPrefix(p, constants.LayoutsDir+"/"), strings.HasPrefix(p, constants.PartialsDir+"/"),
+		strings.HasPrefix(p, constants.ViewsDir+"/"):
+	default:
+		p = constants.ViewsDir + "/" + p
+	}
+	return p + a.extension, nil
+}
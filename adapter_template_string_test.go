@@ -0,0 +1,134 @@
+package hyperview_test
+
+import (
+	"testing"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/hyperviewtest"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func TestTemplateAdapter_ParseString(t *testing.T) {
+	tests := []struct {
+		name  string
+		parse func(t *testing.T, adapter *hyperview.TemplateAdapter)
+		resp  *response.Response
+		want  string
+	}{
+		{
+			name: "view with a declared layout",
+			parse: func(t *testing.T, adapter *hyperview.TemplateAdapter) {
+				mustParseString(t, adapter, "promo", "base", `{{define "page:main"}}promo{{end}}`)
+			},
+			resp: response.NewResponse().Path("promo"),
+			want: "<main>promo</main>",
+		},
+		{
+			name: "view rendering a string partial",
+			parse: func(t *testing.T, adapter *hyperview.TemplateAdapter) {
+				mustParseString(t, adapter, "partials/badge", "", `<b>{{.}}</b>`)
+				mustParseString(t, adapter, "views/sale", "", `{{define "page:main"}}{{template "badge" "sale"}}{{end}}`)
+			},
+			resp: response.NewResponse().Layout("base").Path("sale"),
+			want: "<main><b>sale</b></main>",
+		},
+		{
+			name: "file view rendering a string partial",
+			parse: func(t *testing.T, adapter *hyperview.TemplateAdapter) {
+				mustParseString(t, adapter, "partials/badge", "", `<b>{{.}}</b>`)
+			},
+			resp: response.NewResponse().Layout("base").Path("badged"),
+			want: "<main><b>new</b></main>",
+		},
+		{
+			name: "view overriding a file",
+			parse: func(t *testing.T, adapter *hyperview.TemplateAdapter) {
+				mustParseString(t, adapter, "home", "", `{{define "page:main"}}string home{{end}}`)
+			},
+			resp: response.NewResponse().Layout("base").Path("home"),
+			want: "<main>string home</main>",
+		},
+		{
+			name: "replaced view",
+			parse: func(t *testing.T, adapter *hyperview.TemplateAdapter) {
+				mustParseString(t, adapter, "promo", "", `{{define "page:main"}}first{{end}}`)
+				mustParseString(t, adapter, "promo", "", `{{define "page:main"}}second{{end}}`)
+			},
+			resp: response.NewResponse().Layout("base").Path("promo"),
+			want: "<main>second</main>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := stringTestAdapter(t)
+			tt.parse(t, adapter)
+
+			w := renderTestTemplate(t, adapter, tt.resp)
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("unexpected body: got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplateAdapter_ParseString_BeforeInit(t *testing.T) {
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{})
+	mustParseString(t, adapter, "layouts/base", "", `{{define "layout:base"}}<main>{{template "page:main" .}}</main>{{end}}`)
+	mustParseString(t, adapter, "home", "base", `{{define "page:main"}}home{{end}}`)
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+
+	w := renderTestTemplate(t, adapter, response.NewResponse().Path("home"))
+	if got, want := w.Body.String(), "<main>home</main>"; got != want {
+		t.Errorf("unexpected body: got %q, want %q", got, want)
+	}
+}
+
+func TestTemplateAdapter_ParseString_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		tmpl    string
+		layout  string
+		content string
+	}{
+		{name: "layout of a partial", tmpl: "partials/badge", layout: "base", content: `<b>{{.}}</b>`},
+		{name: "invalid layout name", tmpl: "promo", layout: "{{base}}", content: `{{define "page:main"}}{{end}}`},
+		{name: "file system prefix", tmpl: "blog:promo", content: `{{define "page:main"}}{{end}}`},
+		{name: "invalid name", tmpl: "../promo", content: `{{define "page:main"}}{{end}}`},
+		{name: "invalid template", tmpl: "home", content: `{{define "page:main"}}{{if}}{{end}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := stringTestAdapter(t)
+			if err := adapter.ParseString(tt.tmpl, tt.layout, tt.content); err == nil {
+				t.Fatal("expected an error")
+			}
+
+			// The previous templates are kept
+			w := renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("home"))
+			if got, want := w.Body.String(), "<main>home</main>"; got != want {
+				t.Errorf("unexpected body: got %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func stringTestAdapter(t *testing.T) *hyperview.TemplateAdapter {
+	t.Helper()
+
+	return hyperviewtest.NewAdapter(t, map[string]string{
+		"layouts/base.html": `{{define "layout:base"}}<main>{{template "page:main" .}}</main>{{end}}`,
+		"views/home.html":   `{{define "page:main"}}home{{end}}`,
+		"views/badged.html": `{{define "page:main"}}{{template "badge" "new"}}{{end}}`,
+	}, hyperview.TemplateViewAdapterOptions{})
+}
+
+func mustParseString(t *testing.T, adapter *hyperview.TemplateAdapter, name, layout, content string) {
+	t.Helper()
+	if err := adapter.ParseString(name, layout, content); err != nil {
+		t.Fatalf("error parsing %s: %v", name, err)
+	}
+}
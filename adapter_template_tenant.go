@@ -0,0 +1,90 @@
+package hyperview
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/hypergopher/hyperview/constants"
+)
+
+// TenantResolver returns the tenant of a request, which is the ID of the file system holding the tenant's templates
+// (the fsID: prefix of page names), or an empty string for the root templates.
+type TenantResolver func(r *http.Request) string
+
+// ContextWithTenant returns a copy of ctx carrying the tenant, e.g. from authentication middleware. The tenant in the
+// context takes precedence over the adapter's TenantResolver.
+func ContextWithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, constants.TenantContextKey, tenant)
+}
+
+// TenantFromContext returns the tenant carried by ctx, if any.
+func TenantFromContext(ctx context.Context) string {
+	tenant, _ := ctx.Value(constants.TenantContextKey).(string)
+	return tenant
+}
+
+// HostTenants returns a resolver mapping request hosts to tenants, e.g. {"shop.acme.com": "acme"}. Hosts are matched
+// without their port, ignoring case.
+func HostTenants(hosts map[string]string) TenantResolver {
+	tenants := make(map[string]string, len(hosts))
+	for host, tenant := range hosts {
+		tenants[strings.ToLower(host)] = tenant
+	}
+
+	return func(r *http.Request) string {
+		return tenants[requestHost(r)]
+	}
+}
+
+// SubdomainTenants returns a resolver using the subdomain of domain in the request host as the tenant, e.g. acme for
+// acme.example.com with the domain example.com. Hosts outside the domain have no tenant.
+func SubdomainTenants(domain string) TenantResolver {
+	suffix := "." + strings.ToLower(strings.TrimPrefix(domain, "."))
+
+	return func(r *http.Request) string {
+		host := requestHost(r)
+		if !strings.HasSuffix(host, suffix) {
+			return ""
+		}
+		return strings.TrimSuffix(host, suffix)
+	}
+}
+
+// requestHost returns the host of the request, without its port and in lower case.
+func requestHost(r *http.Request) string {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(host)
+}
+
+// tenant returns the tenant of the request: the tenant in the request context, or the one returned by the resolver.
+func (a *TemplateAdapter) tenant(r *http.Request) string {
+	if tenant := TenantFromContext(r.Context()); tenant != "" {
+		return tenant
+	}
+	if a.tenantResolver != nil {
+		return a.tenantResolver(r)
+	}
+	return ""
+}
+
+// tenantPage returns the tenant's own version of the page for the request, if it has one, or else the page itself.
+// Pages with an explicit fsID: prefix are not resolved.
+func (a *TemplateAdapter) tenantPage(r *http.Request, pageName string) string {
+	if tenant := a.tenant(r); tenant != "" && !strings.Contains(pageName, ":") {
+		tenantPage := tenant + ":" + pageName
+		if _, ok := a.pages[tenantPage]; ok {
+			return tenantPage
+		}
+	}
+	return pageName
+}
+
+// resolvePage returns the page rendered for the request, resolved for its tenant and the view fallbacks, and localized.
+func (a *TemplateAdapter) resolvePage(r *http.Request, pageName string) string {
+	return a.localizedPage(r, a.fallbackPage(r, pageName))
+}
@@ -0,0 +1,92 @@
+package hyperview_test
+
+import (
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func TestTemplateAdapter_Tenants(t *testing.T) {
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{
+			constants.RootFSID: fstest.MapFS{
+				"layouts/base.html":     {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+				"views/home.html":       {Data: []byte(`{{define "page:main"}}root home{{end}}`)},
+				"views/about.html":      {Data: []byte(`{{define "page:main"}}root about{{end}}`)},
+				"views/system/404.html": {Data: []byte(`{{define "page:main"}}root not found{{end}}`)},
+			},
+			"acme": fstest.MapFS{
+				"views/home.html":       {Data: []byte(`{{define "page:main"}}acme home{{end}}`)},
+				"views/system/404.html": {Data: []byte(`{{define "page:main"}}acme not found{{end}}`)},
+			},
+		},
+		TenantResolver: hyperview.HostTenants(map[string]string{"Shop.Acme.com": "acme"}),
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		host   string
+		tenant string
+		path   string
+		want   string
+	}{
+		{name: "tenant page", host: "shop.acme.com:8080", path: "home", want: "acme home"},
+		{name: "fallback to root", host: "shop.acme.com", path: "about", want: "root about"},
+		{name: "no tenant", host: "example.com", path: "home", want: "root home"},
+		{name: "explicit fsID", host: "example.com", path: "acme:home", want: "acme home"},
+		{name: "context tenant", host: "example.com", tenant: "acme", path: "home", want: "acme home"},
+		{name: "unknown tenant", host: "example.com", tenant: "globex", path: "home", want: "root home"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Host = tt.host
+			if tt.tenant != "" {
+				r = r.WithContext(hyperview.ContextWithTenant(r.Context(), tt.tenant))
+			}
+			w := httptest.NewRecorder()
+			adapter.Render(w, r, response.NewResponse().Layout("base").Path(tt.path))
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Host = "shop.acme.com"
+	w := httptest.NewRecorder()
+	adapter.RenderNotFound(w, r, response.NewResponse().Layout("base"))
+	if got := w.Body.String(); got != "acme not found" {
+		t.Errorf("expected the tenant's system page, got %q", got)
+	}
+}
+
+func TestSubdomainTenants(t *testing.T) {
+	resolve := hyperview.SubdomainTenants("example.com")
+
+	tests := []struct {
+		host string
+		want string
+	}{
+		{"acme.example.com", "acme"},
+		{"ACME.example.com:443", "acme"},
+		{"example.com", ""},
+		{"acme.example.org", ""},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Host = tt.host
+		if got := resolve(r); got != tt.want {
+			t.Errorf("SubdomainTenants(%q) = %q, want %q", tt.host, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package hyperview_test
+
+import (
+	"html/template"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/pagination"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func TestTemplateAdapter_LibraryFuncs(t *testing.T) {
+	files := fstest.MapFS{
+		"layouts/base.html": {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+		"views/home.html":   {Data: []byte(`{{define "page:main"}}{{shout .name}} {{join (split "a,b" ",") "-"}}{{end}}`)},
+	}
+	fsMap := map[string]fs.FS{constants.RootFSID: files}
+
+	without := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{FileSystemMap: fsMap})
+	if err := without.Init(); err == nil {
+		t.Error("Init() without the library: expected an error for the undefined functions")
+	}
+
+	// The built-in join takes precedence over that of the library, whose arguments are in another order
+	library := template.FuncMap{
+		"shout": strings.ToUpper,
+		"join":  func(sep string, items []string) string { return "library" },
+	}
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{FileSystemMap: fsMap, LibraryFuncs: library})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	w := renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("home").Data(map[string]any{"name": "hyper <view>"}))
+	if got, want := w.Body.String(), "HYPER &lt;VIEW&gt; a-b"; got != want {
+		t.Errorf("unexpected body: got %q, want %q", got, want)
+	}
+}
+
+func TestTemplateAdapter_FormHelpers(t *testing.T) {
+	adapter := newTestTemplateAdapter(t, fstest.MapFS{
+		"layouts/base.html": {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+		"views/signup.html": {Data: []byte(`{{define "page:main"}}<form>{{input . "email" "type" "email"}}{{errorsFor .View "email"}}</form>{{end}}`)},
+	}, hyperview.TemplateViewAdapterOptions{})
+
+	resp := response.NewResponse().Layout("base").Path("signup").
+		Errors("Please fix the errors", map[string]string{"email": "Email is taken"}).
+		OldInput(url.Values{"email": {"ada@example.com"}})
+	w := renderTestTemplate(t, adapter, resp)
+
+	want := `<form><input type="email" id="email" name="email" aria-invalid="true" aria-describedby="email-error" value="ada@example.com">` +
+		`<p id="email-error" class="field-error" role="alert">Email is taken</p>` +
+		`<p id="email-error" class="field-error" role="alert">Email is taken</p></form>`
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("unexpected status: got %d, want %d", w.Code, http.StatusUnprocessableEntity)
+	}
+	if got := w.Body.String(); got != want {
+		t.Errorf("unexpected body:\ngot  %s\nwant %s", got, want)
+	}
+}
+
+func TestTemplateAdapter_PaginationPartial(t *testing.T) {
+	files := fstest.MapFS{
+		"layouts/base.html": {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+		"views/posts.html":  {Data: []byte(`{{define "page:main"}}{{template "@pagination" .Pagination}}{{end}}`)},
+	}
+	r := httptest.NewRequest(http.MethodGet, "/posts?sort=new&page=2", nil)
+	resp := func() *response.Response {
+		return response.NewResponse().Layout("base").Path("posts").Data(map[string]any{"Pagination": pagination.FromRequest(r, 10, 30)})
+	}
+
+	w := renderTestTemplate(t, newTestTemplateAdapter(t, files, hyperview.TemplateViewAdapterOptions{}), resp())
+	body := w.Body.String()
+	for _, want := range []string{
+		`<nav class="pagination" aria-label="Pagination">`,
+		`<a href="/posts?sort=new" rel="prev">Previous</a>`,
+		`<a href="/posts?page=2&amp;sort=new" aria-current="page">2</a>`,
+		`<a href="/posts?page=3&amp;sort=new" rel="next">Next</a>`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body does not contain %s:\n%s", want, body)
+		}
+	}
+
+	files["partials/pagination.html"] = &fstest.MapFile{Data: []byte(`{{define "@pagination"}}page {{.Page}} of {{.Pages}}{{end}}`)}
+	w = renderTestTemplate(t, newTestTemplateAdapter(t, files, hyperview.TemplateViewAdapterOptions{}), resp())
+	if got, want := w.Body.String(), "page 2 of 3"; got != want {
+		t.Errorf("unexpected body with an overriding partial: got %q, want %q", got, want)
+	}
+}
+
+func TestTemplateAdapter_RenderMeta(t *testing.T) {
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{
+			"layouts/base.html": {Data: []byte(`{{define "layout:base"}}{{renderMeta .}}{{end}}`)},
+			"views/post.html":   {Data: []byte(`{{define "page:main"}}{{end}}`)},
+		}},
+		Meta: response.Meta{SiteName: "Acme", Image: "/img/default.png", Description: "The Acme blog", TwitterSite: "@acme"},
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	resp := response.NewResponse().Layout("base").Path("post").Title("Tom & Jerry").
+		Meta(response.Meta{Description: `A "classic"`, Canonical: "/posts/tom-and-jerry", Type: "article"})
+	w := renderTestTemplate(t, adapter, resp)
+
+	want := `<meta name="description" content="A &#34;classic&#34;">
+<link rel="canonical" href="http://example.com/posts/tom-and-jerry">
+<meta property="og:title" content="Tom &amp; Jerry">
+<meta property="og:description" content="A &#34;classic&#34;">
+<meta property="og:type" content="article">
+<meta property="og:url" content="http://example.com/posts/tom-and-jerry">
+<meta property="og:site_name" content="Acme">
+<meta property="og:image" content="http://example.com/img/default.png">
+<meta name="twitter:card" content="summary_large_image">
+<meta name="twitter:site" content="@acme">
+<meta name="twitter:title" content="Tom &amp; Jerry">
+<meta name="twitter:description" content="A &#34;classic&#34;">
+<meta name="twitter:image" content="http://example.com/img/default.png">
+`
+	if got := w.Body.String(); got != want {
+		t.Errorf("unexpected body:\ngot  %s\nwant %s", got, want)
+	}
+}
+
+func TestTemplateAdapter_BreadcrumbsPartial(t *testing.T) {
+	adapter := newTestTemplateAdapter(t, fstest.MapFS{
+		"layouts/base.html": {Data: []byte(`{{define "layout:base"}}{{template "@breadcrumbs" .View}}{{end}}`)},
+		"views/post.html":   {Data: []byte(`{{define "page:main"}}{{end}}`)},
+	}, hyperview.TemplateViewAdapterOptions{})
+
+	resp := response.NewResponse().Layout("base").Path("post").
+		Breadcrumb("Home", "/").
+		Breadcrumb("Posts & News", "/posts").
+		Breadcrumb("</script>", "")
+	w := renderTestTemplate(t, adapter, resp)
+
+	want := `<nav class="breadcrumbs" aria-label="Breadcrumb">
+<ol>
+<li><a href="/">Home</a></li>
+<li><a href="/posts">Posts &amp; News</a></li>
+<li><span aria-current="page">&lt;/script&gt;</span></li>
+</ol>
+</nav>
+<script type="application/ld+json">{"@context":"https://schema.org","@type":"BreadcrumbList","itemListElement":[` +
+		`{"@type":"ListItem","position":1,"name":"Home","item":"http://example.com/"},` +
+		`{"@type":"ListItem","position":2,"name":"Posts \u0026 News","item":"http://example.com/posts"},` +
+		`{"@type":"ListItem","position":3,"name":"\u003c/script\u003e"}]}</script>`
+	if got := w.Body.String(); got != want {
+		t.Errorf("unexpected body:\ngot  %s\nwant %s", got, want)
+	}
+
+	w = renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("post"))
+	if got := w.Body.String(); got != "" {
+		t.Errorf("unexpected body without breadcrumbs: %q", got)
+	}
+}
+
+func TestTemplateAdapter_ViewDataSlots(t *testing.T) {
+	adapter := newTestTemplateAdapter(t, fstest.MapFS{
+		"layouts/base.html": {Data: []byte(`{{define "layout:base"}}{{with .View.CurrentUser}}{{.}}|{{end}}` +
+			`{{.View.CSRFToken}}|{{template "@flashes" .View}}{{end}}`)},
+		"views/home.html": {Data: []byte(`{{define "page:main"}}{{end}}`)},
+	}, hyperview.TemplateViewAdapterOptions{})
+
+	populate := response.SlotsMiddleware(func(r *http.Request) response.Slots {
+		return response.Slots{CurrentUser: "ada", Flashes: []response.Flash{{Kind: "success", Message: "Saved"}}}
+	})
+
+	tests := []struct {
+		name string
+		resp *response.Response
+		want string
+	}{
+		{
+			name: "slots of the request",
+			resp: response.NewResponse(),
+			want: "ada|token|<div class=\"flashes\" role=\"status\">\n<p class=\"flash flash-success\">Saved</p>\n</div>",
+		},
+		{
+			name: "slots of the response",
+			resp: response.NewResponse().CurrentUser("grace").Flash("error", "<Failed>"),
+			want: "grace|token|<div class=\"flashes\" role=\"status\">\n<p class=\"flash flash-success\">Saved</p>\n" +
+				"<p class=\"flash flash-error\">&lt;Failed&gt;</p>\n</div>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := populate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r = r.WithContext(response.ContextWithCSRFToken(r.Context(), "token"))
+				adapter.Render(w, r, tt.resp.Layout("base").Path("home"))
+			}))
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("unexpected body:\ngot  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+
+	w := renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("home"))
+	if got := w.Body.String(); got != "|" {
+		t.Errorf("unexpected body without slots: %q", got)
+	}
+}
@@ -0,0 +1,75 @@
+package hyperview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/hypergopher/hyperview/response"
+)
+
+// ErrRenderTimeout is returned by renders aborted because they took longer than the render timeout of the adapter
+// (see TemplateViewAdapterOptions.RenderTimeout). It is reported to the render hook, wrapped with the name of the view.
+var ErrRenderTimeout = errors.New("render timeout")
+
+// renderContext returns the context of a render of r, with the render timeout of the adapter if there is one.
+func (a *TemplateAdapter) renderContext(r *http.Request) (context.Context, context.CancelFunc) {
+	if a.renderTimeout <= 0 {
+		return r.Context(), func() {}
+	}
+	return context.WithTimeout(r.Context(), a.renderTimeout)
+}
+
+// renderAborted returns ErrRenderTimeout or the cancellation error of ctx, wrapped with the view name, if err was
+// caused by the end of the render context. Otherwise, it returns nil.
+func renderAborted(ctx context.Context, resp *response.Response, err error) error {
+	switch {
+	case ctx.Err() == nil:
+		return nil
+	case errors.Is(ctx.Err(), context.DeadlineExceeded) && errors.Is(err, context.DeadlineExceeded):
+		return fmt.Errorf("%w: %s", ErrRenderTimeout, resp.TemplatePath())
+	case errors.Is(err, context.Canceled):
+		return fmt.Errorf("render of %s canceled: %w", resp.TemplatePath(), err)
+	}
+	return nil
+}
+
+// handleAborted answers a render aborted by the end of its context: timeouts with 503 Service Unavailable, and
+// canceled renders, whose client is gone, with nothing.
+func (a *TemplateAdapter) handleAborted(w http.ResponseWriter, resp *response.Response, start time.Time, err error) {
+	if !errors.Is(err, ErrRenderTimeout) {
+		return
+	}
+
+	a.log().Warn("Render timeout", slog.String("template", resp.TemplatePath()), slog.Duration("elapsed", time.Since(start)))
+	resp.Status(http.StatusServiceUnavailable)
+	http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+}
+
+// contextWriter is a writer failing with the error of its context once the context is done, which aborts the
+// execution of templates writing to it at their next write.
+type contextWriter struct {
+	ctx context.Context
+	w   io.Writer
+}
+
+// renderWriter returns w, failing once ctx is done if ctx can be canceled.
+func renderWriter(ctx context.Context, w io.Writer) io.Writer {
+	if ctx.Done() == nil {
+		return w
+	}
+	return &contextWriter{ctx: ctx, w: w}
+}
+
+func (c *contextWriter) Write(p []byte) (int, error) {
+	select {
+	case <-c.ctx.Done():
+		return 0, c.ctx.Err()
+	default:
+		return c.w.Write(p)
+	}
+}
@@ -0,0 +1,103 @@
+package hyperview_test
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/response"
+)
+
+// slowItem is an item taking a while to render, like a row of a huge unpaginated query result.
+type slowItem struct{}
+
+func (slowItem) Name() string {
+	time.Sleep(time.Millisecond)
+	return "item"
+}
+
+func TestTemplateAdapter_RenderTimeout(t *testing.T) {
+	var events []hyperview.RenderEvent
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{
+			"layouts/base.html": {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+			"views/items.html":  {Data: []byte(`{{define "page:main"}}{{range .Items}}<li>{{.Name}}</li>{{end}}{{end}}`)},
+		}},
+		RenderTimeout: 20 * time.Millisecond,
+		OnRender: func(r *http.Request, event hyperview.RenderEvent) {
+			events = append(events, event)
+		},
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		resp       *response.Response
+		wantStatus int
+		wantErr    error
+	}{
+		{
+			name:       "fast render",
+			resp:       response.NewResponse().Layout("base").Path("items").Data(map[string]any{"Items": make([]slowItem, 2)}),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "slow template",
+			resp:       response.NewResponse().Layout("base").Path("items").Data(map[string]any{"Items": make([]slowItem, 10000)}),
+			wantStatus: http.StatusServiceUnavailable,
+			wantErr:    hyperview.ErrRenderTimeout,
+		},
+		{
+			name: "slow loader",
+			resp: response.NewResponse().Layout("base").Path("items").Load("Items", func(ctx context.Context) (any, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}),
+			wantStatus: http.StatusServiceUnavailable,
+			wantErr:    hyperview.ErrRenderTimeout,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events = nil
+			start := time.Now()
+			w := renderTestTemplate(t, adapter, tt.resp)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("unexpected status: got %d, want %d", w.Code, tt.wantStatus)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("render was not aborted: took %v", elapsed)
+			}
+			if len(events) != 1 || !errors.Is(events[0].Err, tt.wantErr) || events[0].Status != tt.wantStatus {
+				t.Errorf("unexpected render events: %+v", events)
+			}
+		})
+	}
+}
+
+func TestTemplateAdapter_RenderCanceled(t *testing.T) {
+	adapter := newTestTemplateAdapter(t, fstest.MapFS{
+		"layouts/base.html": {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+		"views/items.html":  {Data: []byte(`{{define "page:main"}}{{range .Items}}<li>{{.Name}}</li>{{end}}{{end}}`)},
+	}, hyperview.TemplateViewAdapterOptions{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+	adapter.Render(w, r, response.NewResponse().Layout("base").Path("items").Data(map[string]any{"Items": make([]slowItem, 10000)}))
+
+	if w.Body.Len() != 0 {
+		t.Errorf("unexpected body for a canceled render: %q", w.Body.String())
+	}
+}
@@ -0,0 +1,413 @@
+package hyperview
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template/parse"
+	"time"
+
+	"github.com/hypergopher/hyperview/response"
+)
+
+// DebugToolbar is the debug annotation of rendered pages, set with TemplateViewAdapterOptions.DebugToolbar. The
+// annotations expose the names and files of the templates, so they are meant for development only.
+type DebugToolbar int
+
+const (
+	// DebugToolbarOff renders the templates as they are. This is the default.
+	DebugToolbarOff DebugToolbar = iota
+	// DebugToolbarComments wraps the markup rendered by each template with HTML comments naming the template and its
+	// file, e.g. <!-- begin card (partials/card.html) -->...<!-- end card -->.
+	DebugToolbarComments
+	// DebugToolbarOverlay adds an overlay to the comments, at the end of full pages, showing the view, layout, render
+	// duration, keys of the view data and the tree of templates rendered.
+	DebugToolbarOverlay
+)
+
+// debugBoundaryFunc is the function marking the start and end of each template when the debug toolbar is enabled.
+const debugBoundaryFunc = "_hyperviewDebugBoundary"
+
+// debugTemplate is a template annotated for the debug toolbar.
+type debugTemplate struct {
+	name string
+	file string
+}
+
+// debugBoundaries marks the boundaries of the templates in the rendered markup. The start and end of each template
+// render a token made of letters and digits, which no context of html/template escapes, so the tokens mark the
+// markup of templates rendered within other templates too, such as components and cached blocks. The tokens are
+// replaced with comments once the render completes. The templates are numbered for the lifetime of the adapter, so
+// the tokens of cached blocks survive reloads. When timed, the tokens also hold the time they were rendered at, to
+// time the templates of slow renders.
+type debugBoundaries struct {
+	prefix    string
+	tokens    *regexp.Regexp
+	timed     bool
+	epoch     time.Time // origin of the times of the tokens
+	mu        sync.RWMutex
+	templates []debugTemplate
+	ids       map[debugTemplate]int
+}
+
+// newDebugBoundaries returns the boundaries of the templates if enabled, for the debug toolbar, the development
+// error page or the tracing of slow renders, or nil.
+func newDebugBoundaries(enabled, timed bool) *debugBoundaries {
+	if !enabled && !timed {
+		return nil
+	}
+
+	// The prefix is random, so the markup of the templates never matches the tokens
+	b := make([]byte, 6)
+	_, _ = rand.Read(b)
+	prefix := "hvdebug" + hex.EncodeToString(b)
+
+	return &debugBoundaries{
+		prefix: prefix,
+		tokens: regexp.MustCompile(prefix + `([be])(\d+)(?:t(\d+))?z`),
+		timed:  timed,
+		epoch:  time.Now(),
+		ids:    make(map[debugTemplate]int),
+	}
+}
+
+// funcs returns the function rendering the tokens.
+func (d *debugBoundaries) funcs() template.FuncMap {
+	return template.FuncMap{
+		debugBoundaryFunc: func(id int, begin bool) template.JS {
+			kind := "e"
+			if begin {
+				kind = "b"
+			}
+			if d.timed {
+				return template.JS(d.prefix + kind + strconv.Itoa(id) + "t" + strconv.FormatInt(int64(d.since()), 10) + "z")
+			}
+			return template.JS(d.prefix + kind + strconv.Itoa(id) + "z")
+		},
+	}
+}
+
+// since returns the time elapsed since the epoch of the tokens.
+func (d *debugBoundaries) since() time.Duration {
+	return time.Since(d.epoch)
+}
+
+// offset returns the time of t, since the epoch of the tokens.
+func (d *debugBoundaries) offset(t time.Time) time.Duration {
+	return t.Sub(d.epoch)
+}
+
+// annotate marks the boundaries of the templates parsed from the file into the set under name, whose trees are
+// named after it. Empty templates are left as they are, as they render nothing and html/template ignores empty
+// redefinitions.
+func (d *debugBoundaries) annotate(t *template.Template, name, filePath string) error {
+	for _, tmpl := range t.Templates() {
+		if tmpl.Tree == nil || tmpl.Tree.ParseName != name || parse.IsEmptyTree(tmpl.Tree.Root) || isDebugAnnotated(tmpl.Tree) {
+			continue
+		}
+
+		id := d.id(debugTemplate{name: tmpl.Name(), file: filePath})
+		begin, err := debugBoundaryNode(id, true)
+		if err != nil {
+			return err
+		}
+		end, err := debugBoundaryNode(id, false)
+		if err != nil {
+			return err
+		}
+
+		root := tmpl.Tree.Root
+		root.Nodes = append(append([]parse.Node{begin}, root.Nodes...), end)
+	}
+	return nil
+}
+
+// id returns the number of the template.
+func (d *debugBoundaries) id(tmpl debugTemplate) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if id, ok := d.ids[tmpl]; ok {
+		return id
+	}
+	d.templates = append(d.templates, tmpl)
+	d.ids[tmpl] = len(d.templates) - 1
+	return len(d.templates) - 1
+}
+
+// template returns the template numbered id.
+func (d *debugBoundaries) template(id int) (debugTemplate, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if id < 0 || id >= len(d.templates) {
+		return debugTemplate{}, false
+	}
+	return d.templates[id], true
+}
+
+// debugBoundaryNode returns the action rendering the token of the boundary.
+func debugBoundaryNode(id int, begin bool) (parse.Node, error) {
+	src := fmt.Sprintf("{{%s %d %t}}", debugBoundaryFunc, id, begin)
+	trees, err := parse.Parse("boundary", src, "", "", map[string]any{debugBoundaryFunc: true})
+	if err != nil {
+		return nil, err
+	}
+	return trees["boundary"].Root.Nodes[0], nil
+}
+
+// isDebugAnnotated reports whether the tree starts with a boundary.
+func isDebugAnnotated(tree *parse.Tree) bool {
+	if len(tree.Root.Nodes) == 0 {
+		return false
+	}
+	action, ok := tree.Root.Nodes[0].(*parse.ActionNode)
+	if !ok || len(action.Pipe.Cmds) == 0 || len(action.Pipe.Cmds[0].Args) == 0 {
+		return false
+	}
+	ident, ok := action.Pipe.Cmds[0].Args[0].(*parse.IdentifierNode)
+	return ok && ident.Ident == debugBoundaryFunc
+}
+
+// renderedTemplate is a template found in the rendered markup, at the depth it was rendered at. When the boundaries
+// are timed, begin is the time the template started at, since the epoch of the tokens, and elapsed the time it took.
+type renderedTemplate struct {
+	debugTemplate
+	depth   int
+	begin   time.Duration
+	elapsed time.Duration
+	timed   bool
+}
+
+// comments replaces the tokens of the body with comments, or removes them where comments would change the markup,
+// such as in attributes or scripts, or if withComments is false. It returns the templates rendered, in order.
+func (d *debugBoundaries) comments(body []byte, withComments bool) ([]byte, []renderedTemplate) {
+	matches := d.tokens.FindAllSubmatchIndex(body, -1)
+	if len(matches) == 0 {
+		return body, nil
+	}
+
+	var rendered []renderedTemplate
+	var open []int // indexes of the templates begun but not ended
+	scanner := &htmlScanner{body: body}
+	out := make([]byte, 0, len(body)+len(matches)*32)
+	last, depth := 0, 0
+	for _, m := range matches {
+		out = append(out, body[last:m[0]]...)
+		last = m[1]
+
+		id, _ := strconv.Atoi(string(body[m[4]:m[5]]))
+		tmpl, ok := d.template(id)
+		if !ok {
+			continue
+		}
+
+		begin := body[m[2]] == 'b'
+		at, timed := time.Duration(0), m[6] >= 0
+		if timed {
+			nanos, _ := strconv.ParseInt(string(body[m[6]:m[7]]), 10, 64)
+			at = time.Duration(nanos)
+		}
+		if begin {
+			rendered = append(rendered, renderedTemplate{debugTemplate: tmpl, depth: depth, begin: at, timed: timed})
+			open = append(open, len(rendered)-1)
+			depth++
+		} else if depth > 0 {
+			depth--
+			i := open[len(open)-1]
+			open = open[:len(open)-1]
+			if timed && rendered[i].timed {
+				rendered[i].elapsed = at - rendered[i].begin
+			} else {
+				rendered[i].timed = false
+			}
+		}
+
+		if !withComments || !scanner.inText(m[0]) {
+			continue
+		}
+		if begin {
+			out = append(out, "<!-- begin "+commentText(tmpl.name)+" ("+commentText(tmpl.file)+") -->"...)
+		} else {
+			out = append(out, "<!-- end "+commentText(tmpl.name)+" -->"...)
+		}
+	}
+
+	return append(out, body[last:]...), rendered
+}
+
+// openTemplates returns the templates started but not ended in the partial body of a failed render, the outermost
+// first: the templates executing when the render failed.
+func (d *debugBoundaries) openTemplates(partial []byte) []debugTemplate {
+	var open []debugTemplate
+	for _, m := range d.tokens.FindAllSubmatchIndex(partial, -1) {
+		id, _ := strconv.Atoi(string(partial[m[4]:m[5]]))
+		tmpl, ok := d.template(id)
+		switch {
+		case !ok:
+		case partial[m[2]] == 'b':
+			open = append(open, tmpl)
+		case len(open) > 0:
+			open = open[:len(open)-1]
+		}
+	}
+	return open
+}
+
+// commentText returns s safe to write in a comment.
+func commentText(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "--", "- -"), ">", "&gt;")
+}
+
+// htmlScanner tracks the state of an HTML document up to an offset, to tell whether the offset is in text.
+type htmlScanner struct {
+	body  []byte
+	pos   int
+	state int
+	quote byte
+	tag   string // name of the tag being scanned
+	raw   string // name of the raw text element being scanned, such as script
+}
+
+const (
+	htmlText = iota
+	htmlTag
+	htmlComment
+	htmlRawText
+)
+
+// rawTextElements are the elements whose content is not markup.
+var rawTextElements = map[string]bool{"script": true, "style": true, "textarea": true, "title": true}
+
+// inText scans the body up to the offset, which must not be before the previous one, and reports whether the offset
+// is in text, where comments can be added.
+func (s *htmlScanner) inText(offset int) bool {
+	for s.pos < offset {
+		c := s.body[s.pos]
+		switch s.state {
+		case htmlText:
+			switch {
+			case bytes.HasPrefix(s.body[s.pos:], []byte("<!--")):
+				s.state = htmlComment
+				s.pos += 3
+			case c == '<' && s.pos+1 < len(s.body) && isTagStart(s.body[s.pos+1]):
+				s.state, s.tag = htmlTag, tagName(s.body[s.pos+1:])
+			}
+		case htmlTag:
+			switch {
+			case s.quote != 0:
+				if c == s.quote {
+					s.quote = 0
+				}
+			case c == '"' || c == '\'':
+				s.quote = c
+			case c == '>':
+				s.state = htmlText
+				if rawTextElements[s.tag] {
+					s.state, s.raw = htmlRawText, s.tag
+				}
+			}
+		case htmlComment:
+			if bytes.HasPrefix(s.body[s.pos:], []byte("-->")) {
+				s.state = htmlText
+				s.pos += 2
+			}
+		case htmlRawText:
+			if c == '<' && strings.EqualFold(tagName(s.body[s.pos+1:]), "/"+s.raw) {
+				s.state, s.tag = htmlTag, ""
+			}
+		}
+		s.pos++
+	}
+	return s.state == htmlText
+}
+
+// isTagStart reports whether c can follow the < of a tag, start tag or end tag, or a declaration.
+func isTagStart(c byte) bool {
+	return c == '/' || c == '!' || c == '?' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+// tagName returns the lowercased name of the tag at the start of b, with the / of end tags.
+func tagName(b []byte) string {
+	end := 0
+	for end < len(b) && (b[end] == '/' && end == 0 || isTagNameByte(b[end])) {
+		end++
+	}
+	return strings.ToLower(string(b[:end]))
+}
+
+func isTagNameByte(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') || c == '-'
+}
+
+// debugBody replaces the tokens of the body of a completed render with comments when the debug toolbar is enabled, or
+// removes them when they only mark the templates for the development error page. It returns the templates rendered,
+// for the overlay.
+func (a *TemplateAdapter) debugBody(body []byte) ([]byte, []renderedTemplate) {
+	if a.debug == nil {
+		return body, nil
+	}
+	return a.debug.comments(body, a.debugToolbar != DebugToolbarOff)
+}
+
+// debugOverlay adds the overlay of the render before the closing body tag of full pages, in overlay mode. It is added
+// after the body is cached, so the renders served from the render cache keep the comments, but not the overlay, whose
+// duration would be stale.
+func (a *TemplateAdapter) debugOverlay(body []byte, resp *response.Response, data map[string]any, rendered []renderedTemplate, duration time.Duration) []byte {
+	if a.debugToolbar != DebugToolbarOverlay {
+		return body
+	}
+
+	idx := bytes.LastIndex(body, []byte("</body>"))
+	if idx == -1 {
+		return body
+	}
+
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	templates := make([]map[string]any, 0, len(rendered))
+	for _, tmpl := range rendered {
+		templates = append(templates, map[string]any{"Name": tmpl.name, "File": tmpl.file, "Depth": tmpl.depth})
+	}
+
+	var overlay bytes.Buffer
+	if err := debugOverlayTemplate.Execute(&overlay, map[string]any{
+		"View":      resp.TemplatePath(),
+		"Layout":    resp.TemplateLayout(),
+		"Duration":  duration.Round(time.Microsecond).String(),
+		"Keys":      keys,
+		"Templates": templates,
+	}); err != nil {
+		a.log().Warn("Error rendering the debug toolbar", slog.String("err", err.Error()))
+		return body
+	}
+
+	annotated := make([]byte, 0, len(body)+overlay.Len())
+	annotated = append(annotated, body[:idx]...)
+	annotated = append(annotated, overlay.Bytes()...)
+	return append(annotated, body[idx:]...)
+}
+
+var debugOverlayTemplate = template.Must(template.New("overlay").Parse(`<div id="hyperview-debug" style="position:fixed;right:0;bottom:0;z-index:2147483647;max-width:40em;max-height:60vh;overflow:auto;padding:4px 8px;background:#1e1e1e;color:#eee;font:12px/1.5 monospace;opacity:.92">
+<details><summary>{{.View}}{{with .Layout}} · {{.}}{{end}} · {{.Duration}}</summary>
+<div>Data: {{range $i, $key := .Keys}}{{if $i}}, {{end}}{{$key}}{{else}}none{{end}}</div>
+<ul style="margin:0;padding:0;list-style:none">
+{{- range .Templates}}
+<li style="padding-left:{{.Depth}}em">{{.Name}} <span style="color:#999">{{.File}}</span></li>
+{{- end}}
+</ul>
+</details>
+</div>
+`))
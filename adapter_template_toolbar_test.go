@@ -0,0 +1,100 @@
+package hyperview_test
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func toolbarTestFiles() fstest.MapFS {
+	return fstest.MapFS{
+		"layouts/base.html": {Data: []byte(`{{define "layout:base"}}<html><head><title>{{template "title" .}}</title>` +
+			`<script>var page = "{{template "title" .}}";</script></head><body>{{template "page:main" .}}</body></html>{{end}}`)},
+		"layouts/fragment.html": {Data: []byte(`{{define "layout:fragment"}}{{template "page:main" .}}{{end}}`)},
+		"partials/card.html":    {Data: []byte(`{{define "card"}}<div class="{{template "cardClass"}}">{{.}}</div>{{end}}`)},
+		"partials/class.html":   {Data: []byte(`{{define "cardClass"}}card{{end}}`)},
+		"views/home.html":       {Data: []byte(`{{define "title"}}Home{{end}}{{define "page:main"}}{{template "card" .Title}}{{end}}`)},
+	}
+}
+
+func TestTemplateAdapter_DebugToolbar(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    hyperview.DebugToolbar
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "off",
+			mode: hyperview.DebugToolbarOff,
+			want: []string{`<title>Home</title><script>var page = "Home";</script></head><body><div class="card">Welcome</div></body>`},
+		},
+		{
+			name: "comments",
+			mode: hyperview.DebugToolbarComments,
+			want: []string{
+				`<!-- begin layout:base (layouts/base.html) --><html><head><title>Home</title><script>var page = "Home";</script></head><body>`,
+				`<!-- begin page:main (views/home.html) --><!-- begin card (partials/card.html) --><div class="card">Welcome</div><!-- end card --><!-- end page:main -->`,
+				`</body></html><!-- end layout:base -->`,
+			},
+			notWant: []string{"hvdebug", "hyperview-debug"},
+		},
+		{
+			name: "overlay",
+			mode: hyperview.DebugToolbarOverlay,
+			want: []string{
+				`<!-- begin card (partials/card.html) -->`,
+				`<summary>views/home · base · `,
+				`<div>Data: Error, Errors, Title, View</div>`,
+				`<li style="padding-left:0em">layout:base <span style="color:#999">layouts/base.html</span></li>`,
+				`<li style="padding-left:2em">card <span style="color:#999">partials/card.html</span></li>`,
+				`<li style="padding-left:3em">cardClass <span style="color:#999">partials/class.html</span></li>`,
+				"</div>\n</body></html>",
+			},
+			notWant: []string{"hvdebug"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := newTestTemplateAdapter(t, toolbarTestFiles(), hyperview.TemplateViewAdapterOptions{DebugToolbar: tt.mode})
+			w := renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("views/home").Data(map[string]any{"Title": "Welcome"}))
+			body := w.Body.String()
+
+			for _, want := range tt.want {
+				if !strings.Contains(body, want) {
+					t.Errorf("expected the body to contain %s, got:\n%s", want, body)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(body, notWant) {
+					t.Errorf("expected the body not to contain %s, got:\n%s", notWant, body)
+				}
+			}
+		})
+	}
+}
+
+func TestTemplateAdapter_DebugToolbarPartial(t *testing.T) {
+	adapter := newTestTemplateAdapter(t, toolbarTestFiles(), hyperview.TemplateViewAdapterOptions{DebugToolbar: hyperview.DebugToolbarOverlay})
+
+	var buf strings.Builder
+	if err := adapter.RenderPartial(&buf, "card", "Hello"); err != nil {
+		t.Fatalf("error rendering the partial: %v", err)
+	}
+	if want := `<!-- begin card (partials/card.html) --><div class="card">Hello</div><!-- end card -->`; buf.String() != want {
+		t.Errorf("expected %s, got %s", want, buf.String())
+	}
+}
+
+func TestTemplateAdapter_DebugToolbarFragment(t *testing.T) {
+	adapter := newTestTemplateAdapter(t, toolbarTestFiles(), hyperview.TemplateViewAdapterOptions{DebugToolbar: hyperview.DebugToolbarOverlay})
+
+	w := renderTestTemplate(t, adapter, response.NewResponse().Layout("fragment").Path("views/home").Data(map[string]any{"Title": "Welcome"}))
+	if body := w.Body.String(); strings.Contains(body, "hyperview-debug") || !strings.Contains(body, "<!-- begin card") {
+		t.Errorf("expected fragments to be annotated without an overlay, got:\n%s", body)
+	}
+}
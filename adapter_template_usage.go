@@ -0,0 +1,128 @@
+package hyperview
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TemplateUsage is the number of renders of a view since the adapter was created.
+type TemplateUsage struct {
+	// Template is the name of the view, e.g. "views/home" or "admin:views/users".
+	Template string `json:"template"`
+	// Renders is the number of renders of the view.
+	Renders int64 `json:"renders"`
+	// LastRendered is the time of the last render of the view, zero if it was never rendered.
+	LastRendered time.Time `json:"lastRendered"`
+}
+
+// UnusedTemplates lists the templates that were not rendered over a time window, see TemplateAdapter.UnusedTemplates.
+type UnusedTemplates struct {
+	// Since is the start of the window, or the creation of the adapter if it is more recent.
+	Since time.Time `json:"since"`
+	// Views are the views not rendered since, sorted.
+	Views []string `json:"views"`
+	// Partials are the partial files that none of the views rendered since can render, sorted. Files are named like
+	// in the DependencyGraph, e.g. partials/card.html.
+	Partials []string `json:"partials"`
+}
+
+// usageCounter counts the renders of a view.
+type usageCounter struct {
+	renders atomic.Int64
+	last    atomic.Int64 // time of the last render, in Unix nanoseconds
+}
+
+// templateUsage counts the renders of each view. It outlives Init and reloads, so the counts cover the lifetime of
+// the adapter.
+type templateUsage struct {
+	since    time.Time
+	counters sync.Map // view name -> *usageCounter
+}
+
+func newTemplateUsage() *templateUsage {
+	return &templateUsage{since: time.Now()}
+}
+
+// record counts a render of the view.
+func (u *templateUsage) record(page string, at time.Time) {
+	counter, ok := u.counters.Load(page)
+	if !ok {
+		counter, _ = u.counters.LoadOrStore(page, &usageCounter{})
+	}
+	counter.(*usageCounter).renders.Add(1)
+	counter.(*usageCounter).last.Store(at.UnixNano())
+}
+
+// usage returns the usage of the view.
+func (u *templateUsage) usage(page string) TemplateUsage {
+	usage := TemplateUsage{Template: page}
+	if counter, ok := u.counters.Load(page); ok {
+		usage.Renders = counter.(*usageCounter).renders.Load()
+		usage.LastRendered = time.Unix(0, counter.(*usageCounter).last.Load())
+	}
+	return usage
+}
+
+// TemplateUsage returns the number of renders of each view since the adapter was created, sorted by view, including
+// the views never rendered. Renders served from the render cache are counted too.
+func (a *TemplateAdapter) TemplateUsage() []TemplateUsage {
+	if !a.frozen.Load() {
+		a.initMu.RLock()
+		defer a.initMu.RUnlock()
+	}
+
+	return a.viewUsage()
+}
+
+// viewUsage returns the usage of each view, sorted. The caller holds initMu or reloadMu, unless frozen.
+func (a *TemplateAdapter) viewUsage() []TemplateUsage {
+	usage := make([]TemplateUsage, 0, len(a.pages))
+	for page := range a.pages {
+		if page != contentPage {
+			usage = append(usage, a.usage.usage(page))
+		}
+	}
+	sort.Slice(usage, func(i, j int) bool { return usage[i].Template < usage[j].Template })
+	return usage
+}
+
+// UnusedTemplates reports the views not rendered over the last window of time, along with the partials that none of
+// the views rendered can render, e.g. to find the dead templates of a large application after a week of traffic. The
+// window is cut to the lifetime of the adapter, as renders are only counted from its creation. Like Dependencies, the
+// views are read and parsed again to find the partials they render.
+func (a *TemplateAdapter) UnusedTemplates(window time.Duration) (UnusedTemplates, error) {
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+
+	report := UnusedTemplates{Since: time.Now().Add(-window), Views: []string{}, Partials: []string{}}
+	if report.Since.Before(a.usage.since) {
+		report.Since = a.usage.since
+	}
+
+	graph, err := a.dependencies()
+	if err != nil {
+		return UnusedTemplates{}, err
+	}
+
+	used := make(map[string]bool)
+	for _, usage := range a.viewUsage() {
+		if usage.Renders == 0 || usage.LastRendered.Before(report.Since) {
+			report.Views = append(report.Views, usage.Template)
+			continue
+		}
+		for _, file := range graph[usage.Template] {
+			used[file] = true
+		}
+	}
+
+	for file := range a.sources {
+		if isPartialFile(file) && !used[file] {
+			report.Partials = append(report.Partials, file)
+		}
+	}
+	sort.Strings(report.Partials)
+
+	return report, nil
+}
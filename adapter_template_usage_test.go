@@ -0,0 +1,79 @@
+package hyperview_test
+
+import (
+	"reflect"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func usageTestFiles() fstest.MapFS {
+	return fstest.MapFS{
+		"layouts/base.html":    {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+		"partials/card.html":   {Data: []byte(`{{define "card"}}card{{end}}`)},
+		"partials/footer.html": {Data: []byte(`{{define "footer"}}footer{{end}}`)},
+		"partials/legacy.html": {Data: []byte(`{{define "legacy"}}legacy{{end}}`)},
+		"views/home.html":      {Data: []byte(`{{define "page:main"}}{{template "card"}}{{end}}`)},
+		"views/about.html":     {Data: []byte(`{{define "page:main"}}{{template "footer"}}{{end}}`)},
+		"views/old.html":       {Data: []byte(`{{define "page:main"}}{{template "legacy"}}{{end}}`)},
+	}
+}
+
+func TestTemplateAdapter_TemplateUsage(t *testing.T) {
+	adapter := newTestTemplateAdapter(t, usageTestFiles(), hyperview.TemplateViewAdapterOptions{})
+
+	for range 3 {
+		renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("views/home"))
+	}
+	renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("views/about"))
+
+	renders := make(map[string]int64)
+	for _, usage := range adapter.TemplateUsage() {
+		renders[usage.Template] = usage.Renders
+		if usage.Renders > 0 && usage.LastRendered.IsZero() {
+			t.Errorf("expected the last render of %s to be recorded", usage.Template)
+		}
+	}
+	if want := map[string]int64{"views/about": 1, "views/home": 3, "views/old": 0}; !reflect.DeepEqual(renders, want) {
+		t.Errorf("expected renders %v, got %v", want, renders)
+	}
+
+	if got := adapter.TemplateStats().Usage; len(got) != 3 {
+		t.Errorf("expected the template stats to report the usage of 3 views, got %v", got)
+	}
+}
+
+func TestTemplateAdapter_UnusedTemplates(t *testing.T) {
+	adapter := newTestTemplateAdapter(t, usageTestFiles(), hyperview.TemplateViewAdapterOptions{})
+	renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("views/home"))
+	renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("views/about"))
+
+	tests := []struct {
+		name         string
+		window       time.Duration
+		wantViews    []string
+		wantPartials []string
+	}{
+		{name: "views rendered in the window", window: time.Hour, wantViews: []string{"views/old"}, wantPartials: []string{"partials/legacy.html"}},
+		{name: "views rendered before the window", window: 0, wantViews: []string{"views/about", "views/home", "views/old"},
+			wantPartials: []string{"partials/card.html", "partials/footer.html", "partials/legacy.html"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := adapter.UnusedTemplates(tt.window)
+			if err != nil {
+				t.Fatalf("error reporting the unused templates: %v", err)
+			}
+			if !reflect.DeepEqual(report.Views, tt.wantViews) {
+				t.Errorf("expected unused views %v, got %v", tt.wantViews, report.Views)
+			}
+			if !reflect.DeepEqual(report.Partials, tt.wantPartials) {
+				t.Errorf("expected unused partials %v, got %v", tt.wantPartials, report.Partials)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package hyperview
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/response"
+)
+
+// DefaultVariant is the variant reported for a page rendered without a template variant, i.e. the control.
+const DefaultVariant = "default"
+
+// VariantSelector chooses the template variant of a page for a request, among the variants of the page, such as "b"
+// for views/home/index@b.html. It returns DefaultVariant or an empty string to render the page itself. The page is
+// the page name, e.g. views/home/index.
+type VariantSelector func(r *http.Request, page string, variants []string) string
+
+// splitVariant splits a page name into the page and its template variant, e.g. views/home/index@b -> views/home/index
+// and b. Names without a variant, or whose variant has a locale (e.g. views/home/index@b.de), return false.
+func splitVariant(pageName string) (string, string, bool) {
+	i := strings.LastIndex(pageName, "@")
+	if i == -1 || i < strings.LastIndex(pageName, "/") {
+		return "", "", false
+	}
+
+	page, variant := pageName[:i], pageName[i+1:]
+	if variant == "" || strings.Contains(variant, ".") || strings.HasSuffix(page, "/") {
+		return "", "", false
+	}
+	return page, variant, true
+}
+
+// variantPage returns the template variant of the page to render for the response. The experiment is named after the
+// page, without the views directory (e.g. home/index). A variant already assigned to the response with
+// Response.Variant is rendered if the page has it; otherwise the adapter's VariantSelector chooses one. The variant
+// rendered is recorded on the response, so it is annotated on the page and reported to the render hook.
+func (a *TemplateAdapter) variantPage(r *http.Request, resp *response.Response, page string) string {
+	variants := a.pageVariants[page]
+	if len(variants) == 0 {
+		return page
+	}
+
+	experiment := strings.TrimPrefix(page, constants.ViewsDir+"/")
+	variant, assigned := resp.Variants()[experiment]
+	if !assigned || (variant != DefaultVariant && !slices.Contains(variants, variant)) {
+		variant = ""
+		if a.variantSelector != nil {
+			variant = a.variantSelector(r, page, append([]string(nil), variants...))
+		}
+	}
+
+	if variant == "" || variant == DefaultVariant || !slices.Contains(variants, variant) {
+		resp.Variant(experiment, DefaultVariant)
+		return page
+	}
+
+	resp.Variant(experiment, variant)
+	return page + "@" + variant
+}
+
+// variantFunc returns the variant function of a render, returning the variant of the named experiment assigned to the
+// response, e.g. {{if eq (variant "home/index") "b"}}, or an empty string if none is. Unlike .View.Variant, it is
+// available to partials executed with another dot. With a nil response, it is the placeholder the templates are
+// parsed with.
+func variantFunc(resp *response.Response) func(string) string {
+	return func(experiment string) string {
+		if resp == nil {
+			return ""
+		}
+		return resp.Variants()[experiment]
+	}
+}
+
+// annotateVariants adds a meta tag for each experiment variant to the head of the rendered page, so client-side
+// analytics report the same variant that was rendered on the server. For example:
+//
+//	<meta name="experiment:homepage-hero" content="b">
+//
+// Bodies without a closing head tag, such as HTMX fragments, are returned unchanged.
+func annotateVariants(body []byte, variants map[string]string) []byte {
+	if len(variants) == 0 {
+		return body
+	}
+
+	idx := bytes.Index(body, []byte("</head>"))
+	if idx == -1 {
+		return body
+	}
+
+	experiments := make([]string, 0, len(variants))
+	for experiment := range variants {
+		experiments = append(experiments, experiment)
+	}
+	sort.Strings(experiments)
+
+	var meta bytes.Buffer
+	for _, experiment := range experiments {
+		meta.WriteString(`<meta name="experiment:` + template.HTMLEscapeString(experiment) +
+			`" content="` + template.HTMLEscapeString(variants[experiment]) + `">`)
+	}
+
+	annotated := make([]byte, 0, len(body)+meta.Len())
+	annotated = append(annotated, body[:idx]...)
+	annotated = append(annotated, meta.Bytes()...)
+	return append(annotated, body[idx:]...)
+}
@@ -0,0 +1,143 @@
+package hyperview_test
+
+import (
+	"context"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func TestTemplateAdapter_VariantAnnotation(t *testing.T) {
+	var event hyperview.RenderEvent
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{
+			"layouts/base.html": {Data: []byte(`{{define "layout:base"}}<html><head></head><body>{{template "page:main" .}}</body></html>{{end}}`)},
+			"views/home.html":   {Data: []byte(`{{define "page:main"}}{{.View.Variant "hero"}}{{end}}`)},
+		}},
+		OnRender: func(_ *http.Request, ev hyperview.RenderEvent) {
+			event = ev
+		},
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+
+	w := renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("home").
+		Variant("hero", "b").Variant("cta", `"x"`))
+
+	want := `<html><head><meta name="experiment:cta" content="&#34;x&#34;"><meta name="experiment:hero" content="b"></head><body>b</body></html>`
+	if got := w.Body.String(); got != want {
+		t.Errorf("unexpected body:\ngot  %s\nwant %s", got, want)
+	}
+
+	if event.Template != "views/home" || event.Variants["hero"] != "b" || event.Size != len(want) || event.Err != nil {
+		t.Errorf("unexpected render event: %+v", event)
+	}
+}
+
+func TestTemplateAdapter_VariantTemplates(t *testing.T) {
+	var event hyperview.RenderEvent
+	var offered []string
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{
+			"layouts/base.html":          {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+			"views/home/index.html":      {Data: []byte(`{{define "page:main"}}control{{end}}`)},
+			"views/home/index@b.html":    {Data: []byte(`{{define "page:main"}}variant b{{end}}`)},
+			"views/home/index@c.html":    {Data: []byte(`{{define "page:main"}}variant c{{end}}`)},
+			"views/home/index@b.de.html": {Data: []byte(`{{define "page:main"}}variante b{{end}}`)},
+		}},
+		LocalizedViews: true,
+		VariantSelector: func(r *http.Request, page string, variants []string) string {
+			offered = variants
+			return r.URL.Query().Get("variant")
+		},
+		OnRender: func(_ *http.Request, ev hyperview.RenderEvent) {
+			event = ev
+		},
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		query       string
+		locale      string
+		assigned    string
+		want        string
+		wantVariant string
+	}{
+		{name: "selected variant", query: "?variant=b", want: "variant b", wantVariant: "b"},
+		{name: "control", query: "", want: "control", wantVariant: hyperview.DefaultVariant},
+		{name: "unknown variant", query: "?variant=z", want: "control", wantVariant: hyperview.DefaultVariant},
+		{name: "assigned variant", query: "?variant=b", assigned: "c", want: "variant c", wantVariant: "c"},
+		{name: "localized variant", query: "?variant=b", locale: "de", want: "variante b", wantVariant: "b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/"+tt.query, nil)
+			if tt.locale != "" {
+				r = r.WithContext(context.WithValue(r.Context(), constants.LocaleContextKey, tt.locale))
+			}
+			resp := response.NewResponse().Layout("base").Path("home/index")
+			if tt.assigned != "" {
+				resp.Variant("home/index", tt.assigned)
+			}
+
+			w := httptest.NewRecorder()
+			adapter.Render(w, r, resp)
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+			if got := event.Variants["home/index"]; got != tt.wantVariant {
+				t.Errorf("expected the render hook to report variant %q, got %q", tt.wantVariant, got)
+			}
+		})
+	}
+
+	if len(offered) != 2 || offered[0] != "b" || offered[1] != "c" {
+		t.Errorf("expected the selector to be offered the variants b and c, got %v", offered)
+	}
+}
+
+func TestTemplateAdapter_VariantFunc(t *testing.T) {
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{
+			"layouts/base.html":       {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+			"partials/cta.html":       {Data: []byte(`{{define "@cta"}}<a data-variant="{{variant "home/index"}}" data-hero="{{variant "hero"}}">{{.}}</a>{{end}}`)},
+			"views/home/index.html":   {Data: []byte(`{{define "page:main"}}{{template "@cta" "Sign up"}}{{end}}`)},
+			"views/home/index@b.html": {Data: []byte(`{{define "page:main"}}{{template "@cta" "Join"}}{{end}}`)},
+		}},
+		VariantSelector: func(r *http.Request, page string, variants []string) string {
+			return r.URL.Query().Get("variant")
+		},
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{name: "variant", query: "?variant=b", want: `<a data-variant="b" data-hero="x">Join</a>`},
+		{name: "control", query: "", want: `<a data-variant="default" data-hero="x">Sign up</a>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/"+tt.query, nil)
+			adapter.Render(w, r, response.NewResponse().Layout("base").Path("home/index").Variant("hero", "x"))
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
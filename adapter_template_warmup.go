@@ -0,0 +1,130 @@
+package hyperview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+
+	"github.com/hypergopher/hyperview/response"
+)
+
+// ErrNotInitialized is returned by Ready and WarmUp before the templates of the adapter are loaded by Init.
+var ErrNotInitialized = errors.New("hyperview: templates not initialized")
+
+// WarmUpFunc builds the response a view is warmed up with for the warm-up request r, e.g. with sample data, or with
+// the cache key of the page so the warm-up fills the render cache entry the first requests hit. The path of the
+// response defaults to the view.
+type WarmUpFunc func(r *http.Request) *response.Response
+
+// WarmUp renders the named views, e.g. "home" or "views/users/show", so the first requests after a deploy find them
+// compiled, with their scoped function clones pooled and their cached pages and fragments in the render cache.
+// Views are rendered through the render middleware with the responses of the WarmUp option, or else with a response
+// rendering the view without data, with its declared layout. Without names, the views of the WarmUp option and the
+// critical views are warmed up.
+//
+// The renders failing with a server error are reported in the joined error, and the others are still warmed up.
+// WarmUp stops at the first view after ctx is done, which is also the context of the warm-up requests.
+func (a *TemplateAdapter) WarmUp(ctx context.Context, names ...string) error {
+	if !a.initialized() {
+		return ErrNotInitialized
+	}
+
+	samples := make(map[string]WarmUpFunc, len(a.warmUp))
+	for name, fn := range a.warmUp {
+		samples[a.viewKey(name)] = fn
+	}
+
+	if len(names) == 0 {
+		for name := range samples {
+			names = append(names, name)
+		}
+		names = append(names, a.criticalViews...)
+		sort.Strings(names)
+	}
+
+	var errs []error
+	warmed := make(map[string]bool, len(names))
+	for _, name := range names {
+		if err := ctx.Err(); err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+
+		view := a.viewKey(name)
+		if warmed[view] {
+			continue
+		}
+		warmed[view] = true
+
+		r, err := http.NewRequestWithContext(ctx, http.MethodGet, "/", nil)
+		if err != nil {
+			return err
+		}
+		resp := response.NewResponse()
+		if fn, ok := samples[view]; ok {
+			resp = fn(r)
+		}
+		if resp.TemplatePath() == "" {
+			resp.Path(name)
+		}
+
+		if err := RenderTo(io.Discard, nil, r, a, resp); err != nil {
+			errs = append(errs, fmt.Errorf("error warming up %s: %w", view, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Ready reports whether the adapter is ready to serve: it returns ErrNotInitialized until Init loaded the templates,
+// and an error naming the critical views missing from them, if any. It suits readiness probes, such as the readiness
+// probes of Kubernetes, which keep traffic away from instances until they are ready:
+//
+//	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+//		if err := adapter.Ready(); err != nil {
+//			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+//		}
+//	})
+func (a *TemplateAdapter) Ready() error {
+	if !a.frozen.Load() {
+		a.initMu.RLock()
+		defer a.initMu.RUnlock()
+	}
+
+	if a.pages == nil {
+		return ErrNotInitialized
+	}
+
+	var missing []string
+	for _, name := range a.criticalViews {
+		if !a.hasView(a.viewKey(name)) {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("hyperview: critical views not found: %v", missing)
+	}
+	return nil
+}
+
+// initialized reports whether Init loaded the templates.
+func (a *TemplateAdapter) initialized() bool {
+	if !a.frozen.Load() {
+		a.initMu.RLock()
+		defer a.initMu.RUnlock()
+	}
+	return a.pages != nil
+}
+
+// viewKey returns the name of the view rendered for the path of a response, e.g. views/home for home.
+func (a *TemplateAdapter) viewKey(name string) string {
+	return a.normalizeName(response.NewResponse().Path(name).TemplatePath())
+}
+
+// hasView reports whether the view exists, or one of its view fallbacks, whatever the tenant, variant and locale of
+// the requests.
+func (a *TemplateAdapter) hasView(view string) bool {
+	_, ok := a.fallbackView(view)
+	return ok
+}
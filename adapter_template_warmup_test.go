@@ -0,0 +1,126 @@
+package hyperview_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/rendercache"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func warmUpTestAdapter(t *testing.T, opts hyperview.TemplateViewAdapterOptions) *hyperview.TemplateAdapter {
+	t.Helper()
+
+	opts.FileSystemMap = map[string]fs.FS{constants.RootFSID: fstest.MapFS{
+		"layouts/base.html":      {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+		"views/home.html":        {Data: []byte(`<!-- layout: base -->{{define "page:main"}}home{{end}}`)},
+		"views/users/index.html": {Data: []byte(`<!-- layout: base -->{{define "page:main"}}users{{end}}`)},
+		"views/users/show.html":  {Data: []byte(`{{define "page:main"}}{{.User.Name}}{{end}}`)},
+	}}
+	opts.LazyCompile = true
+	opts.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	return hyperview.NewTemplateViewAdapter(opts)
+}
+
+func TestTemplateAdapter_Ready(t *testing.T) {
+	tests := []struct {
+		name     string
+		critical []string
+		init     bool
+		want     string // empty if ready
+	}{
+		{name: "not initialized", want: hyperview.ErrNotInitialized.Error()},
+		{name: "initialized", init: true},
+		{name: "critical views", critical: []string{"home", "views/users/show", "users"}, init: true},
+		{name: "missing critical views", critical: []string{"home", "about", "system/500"}, init: true, want: "[about system/500]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := warmUpTestAdapter(t, hyperview.TemplateViewAdapterOptions{CriticalViews: tt.critical})
+			if tt.init {
+				if err := adapter.Init(); err != nil {
+					t.Fatalf("error initializing adapter: %v", err)
+				}
+			}
+
+			err := adapter.Ready()
+			switch {
+			case tt.want == "" && err != nil:
+				t.Errorf("expected the adapter to be ready, got %v", err)
+			case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+				t.Errorf("expected an error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestTemplateAdapter_WarmUp(t *testing.T) {
+	store := rendercache.NewLRU(0)
+	var warmed []string
+	adapter := warmUpTestAdapter(t, hyperview.TemplateViewAdapterOptions{
+		RenderCache: store,
+		WarmUp: map[string]hyperview.WarmUpFunc{
+			"users/show": func(r *http.Request) *response.Response {
+				return response.NewResponse().Layout("base").Cache("user:1", 0).
+					AddDataItem("User", map[string]any{"Name": "Ann"})
+			},
+		},
+		CriticalViews: []string{"home"},
+		OnRender: func(r *http.Request, ev hyperview.RenderEvent) {
+			warmed = append(warmed, ev.Template)
+		},
+	})
+
+	if err := adapter.WarmUp(context.Background()); !errors.Is(err, hyperview.ErrNotInitialized) {
+		t.Fatalf("expected ErrNotInitialized before Init, got %v", err)
+	}
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+
+	// Without names, the views of the options are warmed up
+	if err := adapter.WarmUp(context.Background()); err != nil {
+		t.Fatalf("error warming up: %v", err)
+	}
+	if got := strings.Join(warmed, ","); got != "views/home,views/users/show" {
+		t.Errorf("unexpected warmed up views: %s", got)
+	}
+	if body, ok, _ := store.Get(context.Background(), "user:1"); !ok || string(body) != "Ann" {
+		t.Errorf("expected the page in the render cache, got %q", body)
+	}
+
+	// Named views are warmed up once, with their view fallbacks
+	warmed = nil
+	if err := adapter.WarmUp(context.Background(), "users", "views/users"); err != nil {
+		t.Fatalf("error warming up: %v", err)
+	}
+	if got := strings.Join(warmed, ","); got != "views/users" {
+		t.Errorf("unexpected warmed up views: %s", got)
+	}
+
+	// Failed renders are reported, and the other views still warmed up
+	warmed = nil
+	err := adapter.WarmUp(context.Background(), "missing", "home")
+	if err == nil || !strings.Contains(err.Error(), "error warming up views/missing") {
+		t.Errorf("expected an error for the missing view, got %v", err)
+	}
+	if got := strings.Join(warmed, ","); got != "views/home" {
+		t.Errorf("unexpected warmed up views: %s", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := adapter.WarmUp(ctx, "home"); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the context error, got %v", err)
+	}
+}
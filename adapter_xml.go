@@ -0,0 +1,206 @@
+package hyperview
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+
+	"github.com/hypergopher/hyperview/response"
+)
+
+// XMLAdapter is an adapter for rendering XML responses, with the same data and envelope as the JSONAdapter. Maps of
+// the data are rendered as elements named after their keys, in key order, and lists as item elements. Other values
+// are marshaled with encoding/xml.
+type XMLAdapter struct {
+	opts XMLAdapterOptions
+}
+
+// XMLAdapterOptions are the options for the XMLAdapter.
+type XMLAdapterOptions struct {
+	// Compact disables pretty-printing. By default, responses are indented with tabs.
+	Compact bool
+	// Bare renders the data of successful responses in the root element, without the envelope elements. Failures are
+	// still enveloped, so clients can tell them apart.
+	Bare bool
+	// Root is the name of the root element. Default is "response".
+	Root string
+}
+
+// NewXMLViewAdapter creates a new XML view adapter, with the default options unless options are given.
+func NewXMLViewAdapter(opts ...XMLAdapterOptions) *XMLAdapter {
+	adapter := &XMLAdapter{}
+	if len(opts) > 0 {
+		adapter.opts = opts[0]
+	}
+	if adapter.opts.Root == "" {
+		adapter.opts.Root = "response"
+	}
+	return adapter
+}
+
+func (v *XMLAdapter) Init() error {
+	return nil
+}
+
+func (v *XMLAdapter) Render(w http.ResponseWriter, r *http.Request, resp *response.Response) {
+	if err := resp.RunLoaders(r.Context(), response.DefaultLoaderConcurrency); err != nil {
+		v.RenderSystemError(w, r, err, resp)
+		return
+	}
+
+	// The view data is the data of the templates, without the accessor of the view itself
+	data := make(map[string]any)
+	for key, value := range resp.ViewData(r).Data() {
+		if key != "View" {
+			data[key] = value
+		}
+	}
+
+	var err error
+	switch {
+	case resp.StatusCode() > 299:
+		err = v.write(w, resp.StatusCode(), Envelope{Status: "fail", Code: resp.StatusCode(), Message: "Failure", Data: data}, resp.HTTPHeader())
+	case v.opts.Bare:
+		err = v.write(w, resp.StatusCode(), data, resp.HTTPHeader())
+	default:
+		err = v.write(w, resp.StatusCode(), Envelope{Status: "success", Code: resp.StatusCode(), Message: "Success", Data: data}, resp.HTTPHeader())
+	}
+	if err != nil {
+		v.RenderSystemError(w, r, err, resp)
+	}
+}
+
+func (v *XMLAdapter) RenderForbidden(w http.ResponseWriter, _ *http.Request, _ *response.Response) {
+	v.failure(w, "Forbidden", http.StatusForbidden)
+}
+
+func (v *XMLAdapter) RenderMaintenance(w http.ResponseWriter, _ *http.Request, _ *response.Response) {
+	v.failure(w, "Maintenance", http.StatusServiceUnavailable)
+}
+
+func (v *XMLAdapter) RenderMethodNotAllowed(w http.ResponseWriter, _ *http.Request, _ *response.Response) {
+	v.failure(w, "Method not allowed", http.StatusMethodNotAllowed)
+}
+
+func (v *XMLAdapter) RenderNotFound(w http.ResponseWriter, _ *http.Request, _ *response.Response) {
+	v.failure(w, "Not found", http.StatusNotFound)
+}
+
+func (v *XMLAdapter) RenderSystemError(w http.ResponseWriter, _ *http.Request, err error, _ *response.Response) {
+	e := v.write(w, http.StatusInternalServerError, Envelope{Status: "error", Message: err.Error(), Code: http.StatusInternalServerError}, nil)
+	if e != nil {
+		http.Error(w, e.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (v *XMLAdapter) RenderUnauthorized(w http.ResponseWriter, _ *http.Request, _ *response.Response) {
+	v.failure(w, "Unauthorized", http.StatusUnauthorized)
+}
+
+// failure writes a failure envelope without data.
+func (v *XMLAdapter) failure(w http.ResponseWriter, message string, status int) {
+	if err := v.write(w, status, Envelope{Status: "fail", Code: status, Message: message}, nil); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// write writes data, an Envelope or the bare data, as an XML document with the status and headers.
+func (v *XMLAdapter) write(w http.ResponseWriter, status int, data any, header http.Header) error {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+
+	enc := xml.NewEncoder(&buf)
+	if !v.opts.Compact {
+		enc.Indent("", "\t")
+	}
+
+	root := xml.StartElement{Name: xml.Name{Local: v.opts.Root}}
+	var err error
+	if envelope, ok := data.(Envelope); ok {
+		err = enc.EncodeElement(xmlEnvelope{
+			Status:  envelope.Status,
+			Message: envelope.Message,
+			Code:    envelope.Code,
+			Data:    xmlValue{envelope.Data},
+		}, root)
+	} else {
+		err = enc.EncodeElement(xmlValue{data}, root)
+	}
+	if err == nil {
+		err = enc.Close()
+	}
+	if err != nil {
+		return err
+	}
+	buf.WriteByte('\n')
+
+	for key, value := range header {
+		w.Header()[key] = value
+	}
+	w.Header().Set("Content-Type", "application/xml; charset=UTF-8")
+	w.WriteHeader(status)
+	_, _ = w.Write(buf.Bytes())
+
+	return nil
+}
+
+// xmlEnvelope is the XML form of an Envelope.
+type xmlEnvelope struct {
+	Status  string   `xml:"status"`
+	Message string   `xml:"message"`
+	Code    int      `xml:"code,omitempty"`
+	Data    xmlValue `xml:"data"`
+}
+
+// xmlValue marshals maps and lists, which encoding/xml does not support, as elements.
+type xmlValue struct {
+	value any
+}
+
+func (x xmlValue) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if x.value == nil {
+		return e.EncodeElement("", start)
+	}
+
+	rv := reflect.ValueOf(x.value)
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return e.EncodeElement("", start)
+		}
+		rv = rv.Elem()
+	}
+
+	switch {
+	case rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String:
+		if err := e.EncodeToken(start); err != nil {
+			return err
+		}
+		keys := make([]string, 0, rv.Len())
+		for _, key := range rv.MapKeys() {
+			keys = append(keys, key.String())
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value := rv.MapIndex(reflect.ValueOf(key).Convert(rv.Type().Key())).Interface()
+			if err := e.EncodeElement(xmlValue{value}, xml.StartElement{Name: xml.Name{Local: key}}); err != nil {
+				return fmt.Errorf("error encoding %s: %w", key, err)
+			}
+		}
+		return e.EncodeToken(start.End())
+	case (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array) && rv.Type().Elem().Kind() != reflect.Uint8:
+		if err := e.EncodeToken(start); err != nil {
+			return err
+		}
+		for i := 0; i < rv.Len(); i++ {
+			if err := e.EncodeElement(xmlValue{rv.Index(i).Interface()}, xml.StartElement{Name: xml.Name{Local: "item"}}); err != nil {
+				return err
+			}
+		}
+		return e.EncodeToken(start.End())
+	}
+
+	return e.EncodeElement(x.value, start)
+}
@@ -0,0 +1,81 @@
+package hyperview_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func TestXMLAdapter_Render(t *testing.T) {
+	type user struct {
+		Name  string `xml:"name"`
+		Admin bool   `xml:"admin,attr"`
+	}
+
+	tests := []struct {
+		name       string
+		opts       []hyperview.XMLAdapterOptions
+		resp       *response.Response
+		wantStatus int
+		want       string
+	}{
+		{
+			name: "envelope",
+			opts: []hyperview.XMLAdapterOptions{{Compact: true}},
+			resp: response.NewResponse().Data(map[string]any{
+				"users": []user{{Name: "Ada", Admin: true}, {Name: "Alan"}},
+				"total": 2,
+			}),
+			wantStatus: http.StatusOK,
+			want: `<response><status>success</status><message>Success</message><code>200</code><data>` +
+				`<Error></Error><Errors></Errors><total>2</total>` +
+				`<users><item admin="true"><name>Ada</name></item><item admin="false"><name>Alan</name></item></users>` +
+				`</data></response>`,
+		},
+		{
+			name:       "bare with root",
+			opts:       []hyperview.XMLAdapterOptions{{Compact: true, Bare: true, Root: "feed"}},
+			resp:       response.NewResponse().Data(map[string]any{"title": "News & views", "tags": []string{"a", "b"}}),
+			wantStatus: http.StatusOK,
+			want:       `<feed><Error></Error><Errors></Errors><tags><item>a</item><item>b</item></tags><title>News &amp; views</title></feed>`,
+		},
+		{
+			name:       "failure",
+			opts:       []hyperview.XMLAdapterOptions{{Compact: true, Bare: true}},
+			resp:       response.NewResponse().StatusNotFound(),
+			wantStatus: http.StatusNotFound,
+			want:       `<response><status>fail</status><message>Failure</message><code>404</code><data><Error></Error><Errors></Errors></data></response>`,
+		},
+		{
+			name:       "pretty-printed",
+			resp:       response.NewResponse().Data(map[string]any{"total": 1}),
+			wantStatus: http.StatusOK,
+			want: "<response>\n\t<status>success</status>\n\t<message>Success</message>\n\t<code>200</code>\n\t<data>\n" +
+				"\t\t<Error></Error>\n\t\t<Errors></Errors>\n\t\t<total>1</total>\n\t</data>\n</response>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			hyperview.NewXMLViewAdapter(tt.opts...).Render(w, httptest.NewRequest(http.MethodGet, "/", nil), tt.resp)
+			if w.Code != tt.wantStatus {
+				t.Errorf("unexpected status: got %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("Content-Type"); got != "application/xml; charset=UTF-8" {
+				t.Errorf("unexpected content type: %q", got)
+			}
+			body, ok := strings.CutPrefix(w.Body.String(), `<?xml version="1.0" encoding="UTF-8"?>`+"\n")
+			if !ok {
+				t.Fatalf("missing XML declaration: %s", w.Body.String())
+			}
+			if got := strings.TrimSuffix(body, "\n"); got != tt.want {
+				t.Errorf("unexpected body:\ngot  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
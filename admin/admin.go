@@ -0,0 +1,232 @@
+// Package admin provides an admin handler inspecting the templates of a template adapter: the layouts, partials and
+// views of each file system, the partials and layouts each view can render, when they were loaded and how often they
+// were rendered, with a button reloading the templates.
+//
+// The handler exposes the names of all the templates and reloads them on demand, so it should only be mounted in
+// development or behind the authentication of an admin area.
+package admin
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/hypergopher/hyperview"
+)
+
+// Inspector lists and reloads templates. It is implemented by *hyperview.TemplateAdapter.
+type Inspector interface {
+	// Inventory returns the templates loaded.
+	Inventory() (hyperview.TemplateInventory, error)
+	// Init reloads the templates, or returns hyperview.ErrFrozen if they are frozen.
+	Init() error
+	// Frozen reports whether the templates are frozen.
+	Frozen() bool
+}
+
+// Options are the options for the admin handler.
+type Options struct {
+	// Title is the title of the admin page. Default is "Templates".
+	Title string
+}
+
+// Handler returns the handler serving the templates of inspector. The page posts to itself, so the handler can be
+// mounted at any path, e.g. adminMux.Handle("/admin/templates", admin.Handler(adapter, admin.Options{})).
+//
+//   - GET serves the templates as an HTML page, or as JSON with ?format=json.
+//   - POST reloads the templates with Init, then redirects to the page, reporting the error of a failed reload.
+//     Cross-origin posts are rejected, and so are posts to frozen templates, with 409 Conflict.
+//
+// The error of a failed reload is kept by the handler for a minute, and only a random token referring to it is passed
+// in the query, so links cannot make the page display errors of their own.
+func Handler(inspector Inspector, opts Options) http.Handler {
+	if opts.Title == "" {
+		opts.Title = "Templates"
+	}
+	errs := &reloadErrors{errs: make(map[string]reloadError)}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+			serveInventory(w, r, inspector, opts, errs)
+		case http.MethodPost:
+			serveReload(w, r, inspector, errs)
+		default:
+			w.Header().Set("Allow", "GET, HEAD, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+func serveInventory(w http.ResponseWriter, r *http.Request, inspector Inspector, opts Options, errs *reloadErrors) {
+	inventory, err := inspector.Inventory()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if r.URL.Query().Get("format") == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(inventory)
+		return
+	}
+
+	buf := new(bytes.Buffer)
+	data := map[string]any{
+		"Title":     opts.Title,
+		"Inventory": inventory,
+		"Reloaded":  r.URL.Query().Get("reloaded") != "",
+		"Error":     errs.get(r.URL.Query().Get("error")),
+		"Frozen":    inspector.Frozen(),
+	}
+	if err := pageTemplate.Execute(buf, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(buf.Bytes())
+}
+
+func serveReload(w http.ResponseWriter, r *http.Request, inspector Inspector, errs *reloadErrors) {
+	// Browsers send the Origin of posts, so forms posted from other sites are rejected
+	if origin := r.Header.Get("Origin"); origin != "" {
+		if u, err := url.Parse(origin); err != nil || u.Host != r.Host {
+			http.Error(w, "cross-origin reload rejected", http.StatusForbidden)
+			return
+		}
+	}
+
+	query := url.Values{"reloaded": {"1"}}
+	if err := inspector.Init(); errors.Is(err, hyperview.ErrFrozen) {
+		http.Error(w, "templates are frozen and cannot be reloaded", http.StatusConflict)
+		return
+	} else if err != nil {
+		query = url.Values{"error": {errs.add(err.Error())}}
+	}
+	http.Redirect(w, r, "?"+query.Encode(), http.StatusSeeOther)
+}
+
+// reloadErrorTTL is how long the error of a failed reload can be displayed.
+const reloadErrorTTL = time.Minute
+
+// reloadErrors keeps the errors of failed reloads by random token, until they expire.
+type reloadErrors struct {
+	mu   sync.Mutex
+	errs map[string]reloadError
+}
+
+type reloadError struct {
+	message string
+	expires time.Time
+}
+
+// add keeps the error message and returns its token, removing the expired errors.
+func (e *reloadErrors) add(message string) string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	token := hex.EncodeToString(b)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := time.Now()
+	for t, err := range e.errs {
+		if now.After(err.expires) {
+			delete(e.errs, t)
+		}
+	}
+	e.errs[token] = reloadError{message: message, expires: now.Add(reloadErrorTTL)}
+	return token
+}
+
+// get returns the error message of the token, or "" if it is unknown or expired.
+func (e *reloadErrors) get(token string) string {
+	if token == "" {
+		return ""
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	err, ok := e.errs[token]
+	if !ok || time.Now().After(err.expires) {
+		return ""
+	}
+	return err.message
+}
+
+var funcs = template.FuncMap{
+	"ago": func(t time.Time) string {
+		if t.IsZero() {
+			return "never"
+		}
+		return time.Since(t).Round(time.Second).String() + " ago"
+	},
+}
+
+var pageTemplate = template.Must(template.New("page").Funcs(funcs).Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>{{.Title}}</title></head>
+<body>
+<h1>{{.Title}}</h1>
+{{- if .Reloaded}}
+<p role="status">Templates reloaded.</p>
+{{- end}}
+{{- if .Error}}
+<p role="alert">Reload failed: {{.Error}}</p>
+{{- end}}
+{{- if .Frozen}}
+<form method="post"><button type="submit" disabled>Reload templates</button> Templates are frozen.</form>
+{{- else}}
+<form method="post"><button type="submit">Reload templates</button></form>
+{{- end}}
+<p>Loaded {{ago .Inventory.LoadedAt}} ({{.Inventory.LoadedAt.Format "2006-01-02 15:04:05"}}). <a href="?format=json">JSON</a></p>
+{{- range .Inventory.FileSystems}}
+<section>
+<h2>{{.ID}}</h2>
+<h3>Layouts</h3>
+<ul>
+{{- range .Layouts}}
+<li>{{.File}}{{if .Templates}}: {{range $i, $t := .Templates}}{{if $i}}, {{end}}{{$t}}{{end}}{{end}}</li>
+{{- else}}
+<li>None</li>
+{{- end}}
+</ul>
+<h3>Partials</h3>
+<ul>
+{{- range .Partials}}
+<li>{{.File}}{{if .Templates}}: {{range $i, $t := .Templates}}{{if $i}}, {{end}}{{$t}}{{end}}{{end}}{{if .Deprecated}} (deprecated: {{range $i, $t := .Deprecated}}{{if $i}}, {{end}}{{$t}}{{end}}){{end}}</li>
+{{- else}}
+<li>None</li>
+{{- end}}
+</ul>
+<h3>Views</h3>
+<table>
+<thead><tr><th>View</th><th>Layout</th><th>Renders</th><th>Last rendered</th><th>Loaded</th><th>Dependencies</th></tr></thead>
+<tbody>
+{{- range .Views}}
+<tr><td>{{.Name}}{{if .Deprecated}} (deprecated){{end}}{{if .Aliases}} (aliases: {{range $i, $a := .Aliases}}{{if $i}}, {{end}}{{$a}}{{end}}){{end}}</td><td>{{.Layout}}</td><td>{{.Usage.Renders}}</td><td>{{ago .Usage.LastRendered}}</td><td>{{ago .LoadedAt}}</td><td>{{range $i, $d := .Dependencies}}{{if $i}}, {{end}}{{$d}}{{end}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+</section>
+{{- end}}
+{{- with .Inventory.DeprecatedIncludes}}
+<section>
+<h2>Deprecated includes</h2>
+<ul>
+{{- range .}}
+<li>{{.}}</li>
+{{- end}}
+</ul>
+</section>
+{{- end}}
+</body></html>
+`))
@@ -0,0 +1,206 @@
+package admin_test
+
+import (
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/admin"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func newTestAdmin(t *testing.T) (*hyperview.TemplateAdapter, http.Handler) {
+	t.Helper()
+
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{
+			constants.RootFSID: fstest.MapFS{
+				"layouts/base.html":  {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+				"partials/card.html": {Data: []byte(`{{define "card"}}card{{end}}`)},
+				"views/home.html":    {Data: []byte(`{{define "page:main"}}{{template "card"}}{{end}}`)},
+			},
+			"blog": fstest.MapFS{
+				"views/post.html": {Data: []byte(`{{define "page:main"}}post{{end}}`)},
+			},
+		},
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+
+	return adapter, admin.Handler(adapter, admin.Options{})
+}
+
+func serve(t *testing.T, handler http.Handler, req *http.Request) *httptest.ResponseRecorder {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+func TestHandler_Page(t *testing.T) {
+	adapter, handler := newTestAdmin(t)
+	w := httptest.NewRecorder()
+	adapter.Render(w, httptest.NewRequest(http.MethodGet, "/", nil), response.NewResponse().Layout("base").Path("views/home"))
+
+	body := serve(t, handler, httptest.NewRequest(http.MethodGet, "/admin/templates", nil)).Body.String()
+	for _, want := range []string{
+		`<h2>` + constants.RootFSID + `</h2>`,
+		`<li>layouts/base.html: base.html, layout:base</li>`,
+		`<li>partials/card.html: card</li>`,
+		`<tr><td>views/home</td><td></td><td>1</td>`,
+		`<h2>blog</h2>`,
+		`<tr><td>blog:views/post</td><td></td><td>0</td><td>never</td>`,
+		`<form method="post"><button type="submit">Reload templates</button></form>`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected the page to contain %s, got:\n%s", want, body)
+		}
+	}
+}
+
+func TestHandler_JSON(t *testing.T) {
+	_, handler := newTestAdmin(t)
+
+	w := serve(t, handler, httptest.NewRequest(http.MethodGet, "/admin/templates?format=json", nil))
+	if got := w.Header().Get("Content-Type"); got != "application/json" {
+		t.Fatalf("expected JSON, got %s", got)
+	}
+
+	var inventory hyperview.TemplateInventory
+	if err := json.NewDecoder(w.Body).Decode(&inventory); err != nil {
+		t.Fatalf("error decoding the inventory: %v", err)
+	}
+	if len(inventory.FileSystems) != 2 || inventory.FileSystems[0].ID != constants.RootFSID {
+		t.Fatalf("expected the root and blog file systems, got %+v", inventory.FileSystems)
+	}
+	if views := inventory.FileSystems[0].Views; len(views) != 1 || strings.Join(views[0].Dependencies, ",") != "layouts/base.html,partials/card.html" {
+		t.Errorf("expected home to depend on the card, got %+v", views)
+	}
+}
+
+func TestHandler_Reload(t *testing.T) {
+	tests := []struct {
+		name         string
+		origin       string
+		wantStatus   int
+		wantLocation string
+	}{
+		{name: "same origin", origin: "http://example.com", wantStatus: http.StatusSeeOther, wantLocation: "?reloaded=1"},
+		{name: "no origin", wantStatus: http.StatusSeeOther, wantLocation: "?reloaded=1"},
+		{name: "cross origin", origin: "http://evil.example", wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter, handler := newTestAdmin(t)
+			before, err := adapter.Inventory()
+			if err != nil {
+				t.Fatalf("error listing the templates: %v", err)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "http://example.com/admin/templates", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			w := serve(t, handler, req)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+			if got := w.Header().Get("Location"); tt.wantLocation != "" && !strings.HasSuffix(got, tt.wantLocation) {
+				t.Errorf("expected a redirect to %s, got %s", tt.wantLocation, got)
+			}
+
+			after, _ := adapter.Inventory()
+			if reloaded := after.LoadedAt.After(before.LoadedAt); reloaded != (tt.wantStatus == http.StatusSeeOther) {
+				t.Errorf("unexpected reload: %v", reloaded)
+			}
+		})
+	}
+}
+
+func TestHandler_ReloadFrozen(t *testing.T) {
+	adapter, handler := newTestAdmin(t)
+	if err := adapter.Freeze(); err != nil {
+		t.Fatalf("error freezing the adapter: %v", err)
+	}
+
+	body := serve(t, handler, httptest.NewRequest(http.MethodGet, "/admin/templates", nil)).Body.String()
+	if !strings.Contains(body, `<button type="submit" disabled>Reload templates</button>`) {
+		t.Errorf("expected the reload button to be disabled, got:\n%s", body)
+	}
+
+	w := serve(t, handler, httptest.NewRequest(http.MethodPost, "http://example.com/admin/templates", nil))
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected status %d, got %d", http.StatusConflict, w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "frozen") {
+		t.Errorf("expected the response to explain the templates are frozen, got %q", w.Body.String())
+	}
+}
+
+// frozenInspector reports templates not frozen, as if Freeze was called between the check and the reload.
+type frozenInspector struct {
+	*hyperview.TemplateAdapter
+}
+
+func (frozenInspector) Frozen() bool {
+	return false
+}
+
+func TestHandler_ReloadFrozenConcurrently(t *testing.T) {
+	adapter, _ := newTestAdmin(t)
+	if err := adapter.Freeze(); err != nil {
+		t.Fatalf("error freezing the adapter: %v", err)
+	}
+	handler := admin.Handler(frozenInspector{adapter}, admin.Options{})
+
+	w := serve(t, handler, httptest.NewRequest(http.MethodPost, "http://example.com/admin/templates", nil))
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected status %d, got %d", http.StatusConflict, w.Code)
+	}
+}
+
+func TestHandler_ReloadError(t *testing.T) {
+	files := fstest.MapFS{
+		"layouts/base.html": {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+		"views/home.html":   {Data: []byte(`{{define "page:main"}}home{{end}}`)},
+	}
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: files},
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+	handler := admin.Handler(adapter, admin.Options{})
+
+	home := files["views/home.html"]
+	files["views/home.html"] = &fstest.MapFile{Data: []byte(`{{define "page:main"}}{{if}}{{end}}`)}
+	w := serve(t, handler, httptest.NewRequest(http.MethodPost, "http://example.com/admin/templates", nil))
+	// The inventory parses the files too, so the view is restored before the page is served
+	files["views/home.html"] = home
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("expected status %d, got %d", http.StatusSeeOther, w.Code)
+	}
+	_, query, _ := strings.Cut(w.Header().Get("Location"), "?")
+	if !strings.HasPrefix(query, "error=") || strings.Contains(query, "missing") {
+		t.Fatalf("expected a redirect with an error token, got %s", query)
+	}
+
+	body := serve(t, handler, httptest.NewRequest(http.MethodGet, "/admin/templates?"+query, nil)).Body.String()
+	if !strings.Contains(body, `<p role="alert">Reload failed: `) || !strings.Contains(body, "missing value for if") {
+		t.Errorf("expected the page to report the reload error, got:\n%s", body)
+	}
+
+	body = serve(t, handler, httptest.NewRequest(http.MethodGet, "/admin/templates?error=Your+session+expired", nil)).Body.String()
+	if strings.Contains(body, "Reload failed") || strings.Contains(body, "session expired") {
+		t.Errorf("expected the page to ignore errors passed in the query, got:\n%s", body)
+	}
+}
@@ -0,0 +1,182 @@
+// Package analysis parses HyperView template files without executing them, for tools such as the fixture generator
+// of the hyperview command.
+//
+// Templates are parsed with text/template/parse, so function names are not checked and no function map is needed.
+package analysis
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"text/template/parse"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+)
+
+// Template is a parsed template file.
+type Template struct {
+	// Path is the path of the file in its file system.
+	Path string
+	// Source is the source of the file, before preprocessing.
+	Source string
+	// Trees are the templates defined by the file, keyed by name. The file itself is named after its base name,
+	// like html/template does.
+	Trees map[string]*parse.Tree
+
+	processed string        // the source after preprocessing, which the positions of the nodes of Trees refer to
+	lines     func(int) int // maps the lines of processed to those of Source
+}
+
+// IsHoisted reports whether the named template was hoisted out of a component or cache block of its file by
+// preprocessing, rather than defined by the file.
+func IsHoisted(name string) bool {
+	return strings.HasPrefix(name, "_component:") || strings.HasPrefix(name, "_cache:")
+}
+
+// ParseTemplate parses the template file at filePath with the given source.
+func ParseTemplate(filePath, src string) (*Template, error) {
+	processed, lines, err := hyperview.PreprocessTemplateLines(filePath, src)
+	if err != nil {
+		return nil, err
+	}
+
+	trees := make(map[string]*parse.Tree)
+	tree := parse.New(path.Base(filePath))
+	tree.Mode = parse.SkipFuncCheck | parse.ParseComments
+	if _, err := tree.Parse(processed, "", "", trees); err != nil {
+		return nil, err
+	}
+
+	return &Template{Path: filePath, Source: src, Trees: trees, processed: processed, lines: lines}, nil
+}
+
+// Line returns the line of the source of the file a node of Trees comes from, including the nodes of the templates
+// hoisted out of component and cache blocks, which preprocessing moves to the end of the file.
+func (t *Template) Line(node parse.Node) int {
+	pos := int(node.Position())
+	if pos > len(t.processed) {
+		return 0
+	}
+	line := strings.Count(t.processed[:pos], "\n") + 1
+	if t.lines == nil {
+		return line
+	}
+	return t.lines(line)
+}
+
+// Doc returns the doc comment of the template file: the text of a comment at the start of the file, before any other
+// content, e.g. {{/* Card shows a product summary. */}}.
+func (t *Template) Doc() string {
+	// Definitions are moved out of the file's tree when parsing, so the source tells whether the comment comes first
+	src := strings.TrimPrefix(strings.TrimLeft(t.Source, " \t\r\n"), "{{")
+	src = strings.TrimLeft(strings.TrimPrefix(src, "-"), " \t\r\n")
+	if !strings.HasPrefix(src, "/*") {
+		return ""
+	}
+
+	tree, ok := t.Trees[path.Base(t.Path)]
+	if !ok || tree.Root == nil {
+		return ""
+	}
+
+	for _, node := range tree.Root.Nodes {
+		switch n := node.(type) {
+		case *parse.TextNode:
+			if len(strings.TrimSpace(string(n.Text))) > 0 {
+				return ""
+			}
+		case *parse.CommentNode:
+			text := strings.TrimSuffix(strings.TrimPrefix(n.Text, "/*"), "*/")
+			return strings.TrimSpace(text)
+		default:
+			return ""
+		}
+	}
+	return ""
+}
+
+// Set is the parsed templates of a file system laid out like the template adapter expects.
+type Set struct {
+	// Common are the layouts and partials, shared by all views.
+	Common []*Template
+	// Views are the views, keyed by page name (e.g. views/home).
+	Views map[string]*Template
+}
+
+// Load parses the layouts, partials and views of fsys with the given template file extension.
+func Load(fsys fs.FS, ext string) (*Set, error) {
+	set := &Set{Views: make(map[string]*Template)}
+
+	for _, dir := range []string{constants.LayoutsDir, constants.PartialsDir, constants.ViewsDir} {
+		if _, err := fs.Stat(fsys, dir); err != nil {
+			continue
+		}
+
+		err := fs.WalkDir(fsys, dir, func(filePath string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || path.Ext(filePath) != ext {
+				return err
+			}
+
+			src, err := fs.ReadFile(fsys, filePath)
+			if err != nil {
+				return err
+			}
+
+			tmpl, err := ParseTemplate(filePath, string(src))
+			if err != nil {
+				return fmt.Errorf("error parsing %s: %w", filePath, err)
+			}
+
+			if dir == constants.ViewsDir {
+				set.Views[strings.TrimSuffix(filePath, ext)] = tmpl
+			} else {
+				set.Common = append(set.Common, tmpl)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return set, nil
+}
+
+// Templates returns all parsed template files, common templates first and views sorted by page name.
+func (s *Set) Templates() []*Template {
+	names := make([]string, 0, len(s.Views))
+	for name := range s.Views {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	templates := append([]*Template(nil), s.Common...)
+	for _, name := range names {
+		templates = append(templates, s.Views[name])
+	}
+	return templates
+}
+
+// Trees returns the templates available when rendering the view with the given page name: the common templates and
+// the view's own templates, which take precedence.
+func (s *Set) Trees(view string) (map[string]*parse.Tree, error) {
+	page, ok := s.Views[view]
+	if !ok {
+		return nil, fmt.Errorf("view not found: %s", view)
+	}
+
+	trees := make(map[string]*parse.Tree)
+	for _, tmpl := range s.Common {
+		for name, tree := range tmpl.Trees {
+			trees[name] = tree
+		}
+	}
+	for name, tree := range page.Trees {
+		trees[name] = tree
+	}
+
+	return trees, nil
+}
@@ -0,0 +1,32 @@
+package analysis_test
+
+import (
+	"testing"
+
+	"github.com/hypergopher/hyperview/analysis"
+)
+
+func TestTemplate_Doc(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{name: "leading comment", src: "{{/* Card shows a product. */}}\n{{define \"@card\"}}card{{end}}", want: "Card shows a product."},
+		{name: "trimmed comment", src: "\n{{- /* Trimmed. */ -}}\ncard", want: "Trimmed."},
+		{name: "comment after content", src: "card {{/* Not a doc. */}}", want: ""},
+		{name: "comment after definition", src: `{{define "@card"}}card{{end}}{{/* Not a doc. */}}`, want: ""},
+		{name: "no comment", src: "card", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := analysis.ParseTemplate("partials/card.html", tt.src)
+			if err != nil {
+				t.Fatalf("error parsing template: %v", err)
+			}
+			if got := tmpl.Doc(); got != tt.want {
+				t.Errorf("Doc() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
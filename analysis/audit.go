@@ -0,0 +1,405 @@
+package analysis
+
+import (
+	"fmt"
+	"html/template"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"text/template/parse"
+)
+
+const (
+	// RuleUnsafeCast reports data passed to a function casting strings to trusted content without escaping them, such
+	// as safeHTML, which lets user data inject markup and scripts.
+	RuleUnsafeCast Rule = "unsafe-cast"
+	// RuleTrustedHelper reports data passed to a helper returning trusted content, such as template.HTML, built from
+	// its arguments. The helper must escape them, as html/template no longer does.
+	RuleTrustedHelper Rule = "trusted-helper"
+	// RuleUnescapedAttr reports trusted content inserted in a tag, an event handler, a style or a URL attribute, where
+	// html/template trusts it as attributes, JavaScript, CSS or URLs, so a javascript: URL or an onclick gets through.
+	RuleUnescapedAttr Rule = "unescaped-attr"
+	// RuleURLQuery reports urlquery used outside the query string of a URL attribute. html/template escapes for the
+	// context already, so urlquery elsewhere is escaped twice, and it never filters javascript: URLs.
+	RuleURLQuery Rule = "urlquery"
+)
+
+// AuditRules are all audit rules, with their default severity.
+var AuditRules = map[Rule]Severity{
+	RuleUnsafeCast:    SeverityError,
+	RuleTrustedHelper: SeverityWarning,
+	RuleUnescapedAttr: SeverityError,
+	RuleURLQuery:      SeverityWarning,
+}
+
+// DefaultUnsafeFuncs are the functions of the funcs package casting strings to trusted content without escaping them.
+var DefaultUnsafeFuncs = []string{"safeAttr", "safeCSS", "safeHTML", "safeJS", "safeURL"}
+
+// AuditOptions are the options for auditing templates.
+type AuditOptions struct {
+	// UnsafeFuncs are the functions casting strings to trusted content without escaping them. Default is
+	// DefaultUnsafeFuncs.
+	UnsafeFuncs []string
+	// Helpers are the functions returning trusted content built from their arguments, such as the custom helpers
+	// returning template.HTML, e.g. listed by TrustedFuncs.
+	Helpers []string
+	// Severities overrides the default severity of rules. Rules set to SeverityOff are not checked.
+	Severities map[Rule]Severity
+}
+
+// TrustedFuncs returns the names of the functions of the function map returning trusted content, i.e. one of the
+// string types of html/template such as template.HTML or template.URL, sorted. They suit AuditOptions.Helpers.
+func TrustedFuncs(funcs map[string]any) []string {
+	var names []string
+	for name, fn := range funcs {
+		t := reflect.TypeOf(fn)
+		if t == nil || t.Kind() != reflect.Func || t.NumOut() == 0 {
+			continue
+		}
+		if slices.Contains(trustedTypes, t.Out(0)) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+var trustedTypes = []reflect.Type{
+	reflect.TypeFor[template.HTML](),
+	reflect.TypeFor[template.HTMLAttr](),
+	reflect.TypeFor[template.CSS](),
+	reflect.TypeFor[template.JS](),
+	reflect.TypeFor[template.JSStr](),
+	reflect.TypeFor[template.URL](),
+	reflect.TypeFor[template.Srcset](),
+}
+
+// Audit statically checks the templates of the set for patterns prone to XSS, which html/template cannot prevent
+// since they opt out of its escaping, and returns the issues found, sorted by path and line. The HTML context of
+// each action is inferred from the text before it, so actions following conditional markup may be misplaced.
+func (s *Set) Audit(opts AuditOptions) []Issue {
+	if opts.UnsafeFuncs == nil {
+		opts.UnsafeFuncs = DefaultUnsafeFuncs
+	}
+
+	a := &auditor{opts: opts}
+	for _, tmpl := range s.Templates() {
+		names := make([]string, 0, len(tmpl.Trees))
+		for name := range tmpl.Trees {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			a.path = tmpl.Path
+			a.tmpl = tmpl
+			a.html = htmlScanner{}
+			a.walk(tmpl.Trees[name].Root)
+		}
+	}
+
+	sort.SliceStable(a.issues, func(i, j int) bool {
+		if a.issues[i].Path != a.issues[j].Path {
+			return a.issues[i].Path < a.issues[j].Path
+		}
+		return a.issues[i].Line < a.issues[j].Line
+	})
+
+	return a.issues
+}
+
+type auditor struct {
+	opts   AuditOptions
+	path   string
+	tmpl   *Template
+	html   htmlScanner
+	issues []Issue
+}
+
+func (a *auditor) report(node parse.Node, rule Rule, format string, args ...any) {
+	severity := AuditRules[rule]
+	if s, ok := a.opts.Severities[rule]; ok {
+		severity = s
+	}
+	if severity == SeverityOff {
+		return
+	}
+
+	a.issues = append(a.issues, Issue{
+		Path:     a.path,
+		Line:     a.tmpl.Line(node),
+		Rule:     rule,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// walk checks node, feeding the text of the template to the HTML scanner in source order.
+func (a *auditor) walk(node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			a.walk(child)
+		}
+	case *parse.TextNode:
+		a.html.feed(string(n.Text))
+	case *parse.ActionNode:
+		ctx := a.html.context()
+		if len(n.Pipe.Decl) > 0 {
+			// Declarations print nothing
+			ctx = htmlContext{}
+		}
+		a.pipe(n.Pipe, ctx)
+		if len(n.Pipe.Decl) == 0 {
+			a.html.feed("x")
+		}
+	case *parse.IfNode:
+		a.branch(n.Pipe, n.List, n.ElseList)
+	case *parse.WithNode:
+		a.branch(n.Pipe, n.List, n.ElseList)
+	case *parse.RangeNode:
+		a.branch(n.Pipe, n.List, n.ElseList)
+	case *parse.TemplateNode:
+		a.pipe(n.Pipe, htmlContext{})
+		a.html.feed("x")
+	}
+}
+
+func (a *auditor) branch(pipe *parse.PipeNode, list, elseList *parse.ListNode) {
+	a.pipe(pipe, htmlContext{})
+	a.walk(list)
+	a.walk(elseList)
+}
+
+// pipe checks the function calls of the pipeline, which prints in the HTML context.
+func (a *auditor) pipe(p *parse.PipeNode, ctx htmlContext) {
+	if p == nil {
+		return
+	}
+
+	for i, cmd := range p.Cmds {
+		for _, arg := range cmd.Args {
+			if nested, ok := arg.(*parse.PipeNode); ok {
+				a.pipe(nested, ctx)
+			}
+		}
+
+		fn, ok := cmd.Args[0].(*parse.IdentifierNode)
+		if !ok {
+			continue
+		}
+		data := i > 0 && !isConstant(p.Cmds[i-1])
+		for _, arg := range cmd.Args[1:] {
+			data = data || !isLiteral(arg)
+		}
+
+		switch {
+		case fn.Ident == "urlquery":
+			if !ctx.isURL() || !strings.Contains(ctx.value, "?") {
+				a.report(fn, RuleURLQuery, "urlquery is used %s, outside a URL query string", ctx)
+			}
+		case !data || !a.isTrusted(fn.Ident):
+		case ctx.isTrusting():
+			a.report(fn, RuleUnescapedAttr, "%s output is inserted %s without escaping", fn.Ident, ctx)
+		case slices.Contains(a.opts.UnsafeFuncs, fn.Ident):
+			a.report(fn, RuleUnsafeCast, "data is passed to %s, which does not escape it", fn.Ident)
+		default:
+			a.report(fn, RuleTrustedHelper, "data is passed to %s, which returns trusted content", fn.Ident)
+		}
+	}
+}
+
+// isTrusted reports whether the function returns trusted content: an unsafe function or a helper.
+func (a *auditor) isTrusted(name string) bool {
+	return slices.Contains(a.opts.UnsafeFuncs, name) || slices.Contains(a.opts.Helpers, name)
+}
+
+// isConstant reports whether the command is a literal, such as "<br>" in {{"<br>" | safeHTML}}.
+func isConstant(cmd *parse.CommandNode) bool {
+	return len(cmd.Args) == 1 && isLiteral(cmd.Args[0])
+}
+
+func isLiteral(node parse.Node) bool {
+	switch node.(type) {
+	case *parse.StringNode, *parse.NumberNode, *parse.BoolNode, *parse.NilNode:
+		return true
+	}
+	return false
+}
+
+// htmlContext is the HTML context of an action.
+type htmlContext struct {
+	// tag is the name of the tag the action is in, if any.
+	tag string
+	// attr is the name of the attribute whose value the action is in, if any, lowercased.
+	attr string
+	// value is the text of the attribute value before the action.
+	value string
+}
+
+// isTrusting reports whether html/template trusts the content of the matching type as is in the context: attributes
+// in a tag, JavaScript in an event handler, CSS in a style attribute and URLs in a URL attribute.
+func (c htmlContext) isTrusting() bool {
+	return c.tag != "" && (c.attr == "" || strings.HasPrefix(c.attr, "on") || c.attr == "style" || c.isURL())
+}
+
+// isURL reports whether the context is a URL attribute, like html/template infers them.
+func (c htmlContext) isURL() bool {
+	if c.attr == "" {
+		return false
+	}
+	_, name, _ := strings.Cut(c.attr, ":")
+	if name == "" {
+		name = c.attr
+	}
+	if slices.Contains(urlAttrs, name) {
+		return true
+	}
+	return strings.Contains(name, "src") || strings.Contains(name, "uri") || strings.Contains(name, "url")
+}
+
+var urlAttrs = []string{"action", "archive", "background", "cite", "classid", "codebase", "data", "formaction",
+	"href", "icon", "longdesc", "manifest", "poster", "profile", "usemap"}
+
+func (c htmlContext) String() string {
+	switch {
+	case c.tag == "":
+		return "in text"
+	case c.attr == "":
+		return fmt.Sprintf("in the <%s> tag", c.tag)
+	}
+	return fmt.Sprintf("in the %s attribute of <%s>", c.attr, c.tag)
+}
+
+type scanState int
+
+const (
+	stateText scanState = iota
+	stateTagOpen
+	stateTagName
+	stateTag
+	stateAttrName
+	stateAfterAttrName
+	stateBeforeValue
+	stateValue
+	stateDeclaration
+)
+
+// htmlScanner follows the tags and attributes of the text of a template, fed in chunks, well enough to tell the
+// context of its actions.
+type htmlScanner struct {
+	state scanState
+	tag   strings.Builder
+	attr  strings.Builder
+	value strings.Builder
+	quote rune
+}
+
+func (s *htmlScanner) feed(text string) {
+	for _, c := range text {
+		switch s.state {
+		case stateText:
+			if c == '<' {
+				s.state = stateTagOpen
+			}
+		case stateTagOpen:
+			switch {
+			case c == '!' || c == '?':
+				s.state = stateDeclaration
+			case c == '/' || isLetter(c):
+				s.tag.Reset()
+				if c != '/' {
+					s.tag.WriteRune(c)
+				}
+				s.state = stateTagName
+			default:
+				s.state = stateText
+			}
+		case stateTagName:
+			switch {
+			case c == '>':
+				s.state = stateText
+			case isSpace(c) || c == '/':
+				s.state = stateTag
+			default:
+				s.tag.WriteRune(c)
+			}
+		case stateTag, stateAfterAttrName:
+			switch {
+			case c == '>':
+				s.state = stateText
+			case c == '=' && s.state == stateAfterAttrName:
+				s.state = stateBeforeValue
+			case isSpace(c) || c == '/':
+			default:
+				s.attr.Reset()
+				s.attr.WriteRune(c)
+				s.state = stateAttrName
+			}
+		case stateAttrName:
+			switch {
+			case c == '>':
+				s.state = stateText
+			case c == '=':
+				s.state = stateBeforeValue
+			case isSpace(c):
+				s.state = stateAfterAttrName
+			default:
+				s.attr.WriteRune(c)
+			}
+		case stateBeforeValue:
+			switch {
+			case c == '>':
+				s.state = stateText
+			case isSpace(c):
+			default:
+				s.value.Reset()
+				s.quote = 0
+				if c == '"' || c == '\'' {
+					s.quote = c
+				} else {
+					s.value.WriteRune(c)
+				}
+				s.state = stateValue
+			}
+		case stateValue:
+			switch {
+			case s.quote != 0 && c == s.quote, s.quote == 0 && isSpace(c):
+				s.state = stateTag
+			case s.quote == 0 && c == '>':
+				s.state = stateText
+			default:
+				s.value.WriteRune(c)
+			}
+		case stateDeclaration:
+			if c == '>' {
+				s.state = stateText
+			}
+		}
+	}
+}
+
+// context returns the context of an action at the current position.
+func (s *htmlScanner) context() htmlContext {
+	switch s.state {
+	case stateTagName, stateTag, stateAttrName, stateAfterAttrName:
+		return htmlContext{tag: s.tag.String()}
+	case stateBeforeValue:
+		return htmlContext{tag: s.tag.String(), attr: strings.ToLower(s.attr.String())}
+	case stateValue:
+		return htmlContext{tag: s.tag.String(), attr: strings.ToLower(s.attr.String()), value: s.value.String()}
+	}
+	return htmlContext{}
+}
+
+func isLetter(c rune) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isSpace(c rune) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
@@ -0,0 +1,88 @@
+package analysis_test
+
+import (
+	"html/template"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview/analysis"
+)
+
+func TestSet_Audit(t *testing.T) {
+	set := newTestSet(t, fstest.MapFS{
+		"views/post.html": {Data: []byte(`{{define "page:main"}}
+<article>{{safeHTML .Body}}</article>
+<p>{{"<br>" | safeHTML}}{{safeHTML "<hr>"}}</p>
+<a href="{{safeURL .Link}}" class="{{safeHTML .Class}}">{{.Title}}</a>
+<button onclick='{{.Handler | safeJS}}'>Go</button>
+<div {{safeAttr .Attrs}}>{{icon .Name}}</div>
+<a href="/search?q={{.Query | urlquery}}">Search</a>
+<a href="{{urlquery .Next}}">Next</a>
+{{$html := safeHTML .Raw}}
+{{end}}`)},
+	})
+
+	tests := []struct {
+		name string
+		opts analysis.AuditOptions
+		want []string
+	}{
+		{
+			name: "default rules",
+			want: []string{
+				"views/post.html:2: error: data is passed to safeHTML, which does not escape it (unsafe-cast)",
+				"views/post.html:4: error: safeURL output is inserted in the href attribute of <a> without escaping (unescaped-attr)",
+				"views/post.html:4: error: data is passed to safeHTML, which does not escape it (unsafe-cast)",
+				"views/post.html:5: error: safeJS output is inserted in the onclick attribute of <button> without escaping (unescaped-attr)",
+				"views/post.html:6: error: safeAttr output is inserted in the <div> tag without escaping (unescaped-attr)",
+				"views/post.html:8: warning: urlquery is used in the href attribute of <a>, outside a URL query string (urlquery)",
+				"views/post.html:9: error: data is passed to safeHTML, which does not escape it (unsafe-cast)",
+			},
+		},
+		{
+			name: "helpers and severity overrides",
+			opts: analysis.AuditOptions{
+				UnsafeFuncs: []string{"safeURL"},
+				Helpers:     []string{"icon"},
+				Severities: map[analysis.Rule]analysis.Severity{
+					analysis.RuleURLQuery:      analysis.SeverityOff,
+					analysis.RuleUnescapedAttr: analysis.SeverityWarning,
+				},
+			},
+			want: []string{
+				"views/post.html:4: warning: safeURL output is inserted in the href attribute of <a> without escaping (unescaped-attr)",
+				"views/post.html:6: warning: data is passed to icon, which returns trusted content (trusted-helper)",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := set.Audit(tt.opts)
+
+			got := make([]string, len(issues))
+			for i, issue := range issues {
+				got[i] = issue.String()
+			}
+
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("unexpected issues:\ngot\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestTrustedFuncs(t *testing.T) {
+	funcs := map[string]any{
+		"icon":   func(name string) template.HTML { return "" },
+		"link":   func(href string) (template.URL, error) { return "", nil },
+		"upper":  strings.ToUpper,
+		"notFun": "value",
+	}
+
+	if got, want := analysis.TrustedFuncs(funcs), []string{"icon", "link"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,357 @@
+package analysis
+
+import (
+	"sort"
+	"strings"
+	"text/template/parse"
+)
+
+// frameworkFields are the view data keys set by the response itself, which are left out of inferred data.
+var frameworkFields = map[string]bool{"View": true, "Error": true, "Errors": true}
+
+// Field is a value of the view data accessed by templates, along with the fields accessed on it.
+type Field struct {
+	// Name is the name of the field, or an empty string for the root and list elements.
+	Name string
+	// Fields are the fields accessed on the value, keyed by name.
+	Fields map[string]*Field
+	// Elem is the element of the value, if it is ranged over.
+	Elem *Field
+
+	value     bool      // the field is output or passed to a function
+	condition bool      // the field is tested in an if or with action
+	kind      valueKind // the kind of value suggested by the built-in functions the field is passed to
+}
+
+// valueKind is the kind of a value suggested by the built-in functions it is passed to.
+type valueKind int
+
+const (
+	kindUnknown    valueKind = iota
+	kindInt                  // compared to an integer literal
+	kindFloat                // compared to a floating-point literal
+	kindString               // compared to a string literal
+	kindBool                 // compared to a boolean literal
+	kindMap                  // indexed with a string key
+	kindCollection           // passed to len or indexed with a number
+	kindAny                  // suggested conflicting kinds
+)
+
+// suggest records a kind suggested for the value. Conflicting suggestions leave the kind of the value open.
+func (f *Field) suggest(kind valueKind) {
+	if f.kind != kindUnknown && f.kind != kind {
+		kind = kindAny
+	}
+	f.kind = kind
+}
+
+func newField(name string) *Field {
+	return &Field{Name: name, Fields: make(map[string]*Field)}
+}
+
+// IsList reports whether the value is ranged over.
+func (f *Field) IsList() bool {
+	return f.Elem != nil
+}
+
+// IsObject reports whether fields are accessed on the value.
+func (f *Field) IsObject() bool {
+	return len(f.Fields) > 0
+}
+
+// IsBool reports whether the value is only used as a condition, which suggests it is a boolean.
+func (f *Field) IsBool() bool {
+	return f.condition && !f.value && !f.IsList() && !f.IsObject()
+}
+
+// SortedFields returns the fields accessed on the value, sorted by name.
+func (f *Field) SortedFields() []*Field {
+	fields := make([]*Field, 0, len(f.Fields))
+	for _, field := range f.Fields {
+		fields = append(fields, field)
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].Name < fields[j].Name
+	})
+	return fields
+}
+
+func (f *Field) child(name string) *Field {
+	child, ok := f.Fields[name]
+	if !ok {
+		child = newField(name)
+		f.Fields[name] = child
+	}
+	return child
+}
+
+func (f *Field) elem() *Field {
+	if f.Elem == nil {
+		f.Elem = newField("")
+	}
+	return f.Elem
+}
+
+// InferView infers the view data accessed when rendering the view with the given page name (e.g. views/home). The
+// templates defined by the view are walked with the view data as dot, following the templates they call, including
+// partials and component slots.
+func (s *Set) InferView(view string) (*Field, error) {
+	trees, err := s.Trees(view)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []string
+	for name := range s.Views[view].Trees {
+		if !IsHoisted(name) && name != "layout" {
+			entries = append(entries, name)
+		}
+	}
+	sort.Strings(entries)
+
+	root := InferData(trees, entries...)
+	for name := range frameworkFields {
+		delete(root.Fields, name)
+	}
+
+	return root, nil
+}
+
+// InferData infers the data accessed by the entry templates of trees, which are executed with the data as dot.
+func InferData(trees map[string]*parse.Tree, entries ...string) *Field {
+	root := newField("")
+	w := &walker{trees: trees, visited: make(map[visit]bool)}
+
+	for _, name := range entries {
+		w.template(name, root)
+	}
+
+	return root
+}
+
+type visit struct {
+	template string
+	dot      *Field
+}
+
+type walker struct {
+	trees   map[string]*parse.Tree
+	visited map[visit]bool
+}
+
+// scope is the evaluation state of a template: the value of dot and the declared variables. A nil field is a value
+// that is not part of the view data, such as a function result.
+type scope struct {
+	dot  *Field
+	vars map[string]*Field
+}
+
+func (s scope) with(dot *Field) scope {
+	vars := make(map[string]*Field, len(s.vars))
+	for name, field := range s.vars {
+		vars[name] = field
+	}
+	return scope{dot: dot, vars: vars}
+}
+
+// template walks the named template with dot, once per distinct dot.
+func (w *walker) template(name string, dot *Field) {
+	tree, ok := w.trees[name]
+	if !ok || tree.Root == nil || dot == nil || w.visited[visit{name, dot}] {
+		return
+	}
+	w.visited[visit{name, dot}] = true
+
+	w.walk(tree.Root, scope{dot: dot, vars: map[string]*Field{"$": dot}})
+}
+
+func (w *walker) walk(node parse.Node, s scope) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			w.walk(child, s)
+		}
+	case *parse.ActionNode:
+		if field := w.pipe(n.Pipe, s); field != nil && len(n.Pipe.Decl) == 0 {
+			field.value = true
+		}
+	case *parse.IfNode:
+		if field := w.pipe(n.Pipe, s); field != nil {
+			field.condition = true
+		}
+		w.walk(n.List, s.with(s.dot))
+		w.walk(n.ElseList, s.with(s.dot))
+	case *parse.WithNode:
+		field := w.pipe(n.Pipe, s)
+		if field != nil {
+			field.condition = true
+		}
+		w.walk(n.List, s.with(field))
+		w.walk(n.ElseList, s.with(s.dot))
+	case *parse.RangeNode:
+		inner := s.with(s.dot)
+		field := w.pipe(n.Pipe, inner)
+		inner.dot = nil
+		if field != nil {
+			inner.dot = field.elem()
+			switch len(n.Pipe.Decl) {
+			case 1:
+				inner.vars[n.Pipe.Decl[0].Ident[0]] = inner.dot
+			case 2:
+				inner.vars[n.Pipe.Decl[0].Ident[0]] = nil
+				inner.vars[n.Pipe.Decl[1].Ident[0]] = inner.dot
+			}
+		}
+		w.walk(n.List, inner)
+		w.walk(n.ElseList, s.with(s.dot))
+	case *parse.TemplateNode:
+		var dot *Field
+		if n.Pipe != nil {
+			dot = w.pipe(n.Pipe, s)
+		}
+		w.template(n.Name, dot)
+	}
+}
+
+// pipe evaluates a pipeline, recording the fields it accesses, and returns the field it evaluates to, if any.
+// Variables declared by the pipeline are added to the scope.
+func (w *walker) pipe(p *parse.PipeNode, s scope) *Field {
+	if p == nil {
+		return nil
+	}
+
+	var result *Field
+	for i, cmd := range p.Cmds {
+		result = w.command(cmd, s, i > 0)
+	}
+
+	// In range actions, the declared variables are set by the caller
+	if len(p.Decl) == 1 {
+		s.vars[p.Decl[0].Ident[0]] = result
+	}
+
+	return result
+}
+
+// command evaluates a command of a pipeline. Commands receiving the result of a previous command are function calls.
+func (w *walker) command(cmd *parse.CommandNode, s scope, piped bool) *Field {
+	if len(cmd.Args) == 1 && !piped {
+		return w.arg(cmd.Args[0], s)
+	}
+
+	// Component blocks are rewritten to renderComponent calls; their slots are executed with the caller's dot
+	if ident, ok := cmd.Args[0].(*parse.IdentifierNode); ok && ident.Ident == "renderComponent" && len(cmd.Args) >= 3 {
+		id, idOK := cmd.Args[1].(*parse.StringNode)
+		slots, slotsOK := cmd.Args[2].(*parse.StringNode)
+		if idOK && slotsOK {
+			for _, slot := range strings.Fields(slots.Text) {
+				w.template(id.Text+":"+slot, s.dot)
+			}
+		}
+	}
+
+	// Cache blocks, like other cached fragments, render a template with the data passed to cachedTemplate
+	if ident, ok := cmd.Args[0].(*parse.IdentifierNode); ok && ident.Ident == "cachedTemplate" && len(cmd.Args) >= 5 {
+		if name, ok := cmd.Args[3].(*parse.StringNode); ok {
+			w.template(name.Text, w.arg(cmd.Args[4], s))
+		}
+	}
+
+	fields := make([]*Field, len(cmd.Args))
+	for i, arg := range cmd.Args {
+		if field := w.arg(arg, s); field != nil {
+			field.value = true
+			fields[i] = field
+		}
+	}
+	if ident, ok := cmd.Args[0].(*parse.IdentifierNode); ok {
+		suggestKinds(ident.Ident, cmd.Args[1:], fields[1:])
+	}
+
+	return nil
+}
+
+// suggestKinds records the kinds of the fields passed to the built-in functions whose arguments reveal them: index
+// takes a map or a slice, len a collection, and comparisons values of the type of the literal they compare to.
+func suggestKinds(fn string, args []parse.Node, fields []*Field) {
+	switch fn {
+	case "index":
+		if len(args) < 2 || fields[0] == nil {
+			return
+		}
+		if _, ok := args[1].(*parse.StringNode); ok {
+			fields[0].suggest(kindMap)
+		} else {
+			fields[0].suggest(kindCollection)
+		}
+	case "len":
+		if len(fields) == 1 && fields[0] != nil {
+			fields[0].suggest(kindCollection)
+		}
+	case "eq", "ne", "lt", "le", "gt", "ge":
+		kind := kindUnknown
+		for _, arg := range args {
+			if k := literalKind(arg); k != kindUnknown {
+				kind = k
+			}
+		}
+		if kind == kindUnknown {
+			return
+		}
+		for _, field := range fields {
+			if field != nil {
+				field.suggest(kind)
+			}
+		}
+	}
+}
+
+// literalKind returns the kind of a literal argument, or kindUnknown if the argument is not a literal.
+func literalKind(node parse.Node) valueKind {
+	switch n := node.(type) {
+	case *parse.StringNode:
+		return kindString
+	case *parse.BoolNode:
+		return kindBool
+	case *parse.NumberNode:
+		// Integral floats, such as 1.0, are integers as well for the parser
+		if n.IsFloat && strings.ContainsAny(n.Text, ".eE") {
+			return kindFloat
+		}
+		if n.IsInt {
+			return kindInt
+		}
+	}
+	return kindUnknown
+}
+
+// arg evaluates a command argument, recording the fields it accesses, and returns the field it evaluates to, if any.
+func (w *walker) arg(node parse.Node, s scope) *Field {
+	switch n := node.(type) {
+	case *parse.DotNode:
+		return s.dot
+	case *parse.FieldNode:
+		return resolve(s.dot, n.Ident)
+	case *parse.VariableNode:
+		return resolve(s.vars[n.Ident[0]], n.Ident[1:])
+	case *parse.ChainNode:
+		return resolve(w.arg(n.Node, s), n.Field)
+	case *parse.PipeNode:
+		return w.pipe(n, s.with(s.dot))
+	}
+
+	return nil
+}
+
+func resolve(field *Field, idents []string) *Field {
+	for _, ident := range idents {
+		if field == nil {
+			return nil
+		}
+		field = field.child(ident)
+	}
+	return field
+}
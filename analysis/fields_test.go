@@ -0,0 +1,166 @@
+package analysis_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview/analysis"
+)
+
+func newTestSet(t *testing.T, files fstest.MapFS) *analysis.Set {
+	t.Helper()
+
+	set, err := analysis.Load(files, ".html")
+	if err != nil {
+		t.Fatalf("error loading templates: %v", err)
+	}
+	return set
+}
+
+func TestSet_InferView(t *testing.T) {
+	set := newTestSet(t, fstest.MapFS{
+		"layouts/base.html":  {Data: []byte(`{{define "layout:base"}}{{.View.Title}}{{template "page:main" .}}{{end}}`)},
+		"partials/user.html": {Data: []byte(`{{define "user"}}{{.Name}} <{{.Email}}>{{end}}`)},
+		"partials/card.html": {Data: []byte(`{{define "@card"}}{{.Prop "Title"}}{{.Slot "default"}}{{end}}`)},
+		"views/home.html": {Data: []byte(`{{define "page:main"}}
+			<h1>{{.Title}}</h1>
+			{{if .ShowBanner}}banner{{end}}
+			{{with .Author}}{{template "user" .}}{{end}}
+			{{range $i, $post := .Posts}}{{$post.Title}}{{range .Tags}}{{.}}{{end}}{{$.SiteName}}{{end}}
+			{{$count := len .Comments}}{{$count}}
+			{{printf "%s" .Footer.Text | html}}
+			{{component "@card" (dict "Title" .Card.Title)}}{{.Card.Body}}{{end}}
+			{{cache "sidebar" "5m" .Sidebar.ID}}{{.Sidebar.Title}}{{end}}
+		{{end}}`)},
+	})
+
+	root, err := set.InferView("views/home")
+	if err != nil {
+		t.Fatalf("error inferring view data: %v", err)
+	}
+
+	want := `map[string]any{
+	"Author": map[string]any{
+		"Email": "",
+		"Name": "",
+	},
+	"Card": map[string]any{
+		"Body": "",
+		"Title": "",
+	},
+	"Comments": []any{},
+	"Footer": map[string]any{
+		"Text": "",
+	},
+	"Posts": []map[string]any{
+		{
+			"Tags": []string{
+				"",
+			},
+			"Title": "",
+		},
+	},
+	"ShowBanner": false,
+	"Sidebar": map[string]any{
+		"ID": "",
+		"Title": "",
+	},
+	"SiteName": "",
+	"Title": "",
+}`
+	if got := root.GoMap(); got != want {
+		t.Errorf("unexpected map skeleton:\ngot\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestField_GoStruct(t *testing.T) {
+	set := newTestSet(t, fstest.MapFS{
+		"views/list.html": {Data: []byte(`{{.Title}}{{if .Empty}}-{{end}}{{range .Items}}{{.Name}}{{range .Tags}}{{.}}{{end}}{{end}}`)},
+	})
+
+	root, err := set.InferView("views/list")
+	if err != nil {
+		t.Fatalf("error inferring view data: %v", err)
+	}
+
+	want := `type ListData struct {
+	Empty bool
+	Items []struct {
+		Name string
+		Tags []string
+	}
+	Title string
+}
+`
+	if got := root.GoStruct("ListData"); got != want {
+		t.Errorf("unexpected struct skeleton:\ngot\n%s\nwant\n%s", got, want)
+	}
+
+	json, err := root.JSON()
+	if err != nil {
+		t.Fatalf("error encoding JSON: %v", err)
+	}
+	wantJSON := `{
+  "Empty": false,
+  "Items": [
+    {
+      "Name": "",
+      "Tags": [
+        ""
+      ]
+    }
+  ],
+  "Title": ""
+}`
+	if json != wantJSON {
+		t.Errorf("unexpected JSON skeleton:\ngot\n%s\nwant\n%s", json, wantJSON)
+	}
+}
+
+func TestField_GoTypeKinds(t *testing.T) {
+	set := newTestSet(t, fstest.MapFS{
+		"views/stats.html": {Data: []byte(`{{index .Meta "title"}}{{index .Slides 0}}{{len .Tags}}` +
+			`{{if gt .Count 0}}{{.Count}}{{end}}{{if lt .Ratio 0.5}}low{{end}}{{if eq .Status "open"}}open{{end}}` +
+			`{{if ne .Pinned true}}-{{end}}{{if eq .Mixed 1}}{{end}}{{if eq .Mixed "one"}}{{end}}` +
+			`{{if .Admin}}admin{{end}}{{.Title}}{{printf "%v" .Date}}`)},
+	})
+
+	root, err := set.InferView("views/stats")
+	if err != nil {
+		t.Fatalf("error inferring view data: %v", err)
+	}
+
+	want := `struct {
+	Admin bool
+	Count int
+	Date any
+	Meta any
+	Mixed any
+	Pinned bool
+	Ratio float64
+	Slides any
+	Status string
+	Tags any
+	Title any
+}`
+	if got := root.GoType(); got != want {
+		t.Errorf("unexpected type:\ngot\n%s\nwant\n%s", got, want)
+	}
+
+	fixture, _ := root.Fixture().(map[string]any)
+	for name, want := range map[string]any{"Count": 0, "Ratio": 0.0, "Status": "", "Pinned": false, "Title": ""} {
+		if fixture[name] != want {
+			t.Errorf("expected fixture %s to be %#v, got %#v", name, want, fixture[name])
+		}
+	}
+	if _, ok := fixture["Meta"].(map[string]any); !ok {
+		t.Errorf("expected fixture Meta to be a map, got %#v", fixture["Meta"])
+	}
+}
+
+func TestSet_InferViewNotFound(t *testing.T) {
+	set := newTestSet(t, fstest.MapFS{})
+	if _, err := set.InferView("views/missing"); err == nil {
+		t.Error("expected an error for a missing view")
+	}
+}
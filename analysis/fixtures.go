@@ -0,0 +1,181 @@
+package analysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// GoMap returns a Go expression of a map[string]any skeleton holding the fields of f, with zero values as
+// placeholders.
+func (f *Field) GoMap() string {
+	var b strings.Builder
+	writeGoMapValue(&b, f, 0, false)
+	return b.String()
+}
+
+// writeGoMapValue writes the Go expression of f. Composite literals of list elements have their type elided.
+func writeGoMapValue(b *strings.Builder, f *Field, depth int, elided bool) {
+	switch {
+	case f.IsList():
+		if !elided {
+			b.WriteString(goMapType(f))
+		}
+		b.WriteString("{\n")
+		writeIndent(b, depth+1)
+		writeGoMapValue(b, f.Elem, depth+1, true)
+		b.WriteString(",\n")
+		writeIndent(b, depth)
+		b.WriteString("}")
+	case f.IsObject():
+		if !elided {
+			b.WriteString(goMapType(f))
+		}
+		b.WriteString("{\n")
+		for _, field := range f.SortedFields() {
+			writeIndent(b, depth+1)
+			b.WriteString(strconv.Quote(field.Name) + ": ")
+			writeGoMapValue(b, field, depth+1, false)
+			b.WriteString(",\n")
+		}
+		writeIndent(b, depth)
+		b.WriteString("}")
+	default:
+		b.WriteString(leafGoValue(f))
+	}
+}
+
+func goMapType(f *Field) string {
+	switch {
+	case f.IsList():
+		return "[]" + goMapType(f.Elem)
+	case f.IsObject():
+		return "map[string]any"
+	}
+	return leafGoType(f, "string")
+}
+
+// leafGoType returns the Go type of a value that is neither ranged over nor has fields accessed on it: the type
+// suggested by the built-in functions it is passed to, bool for conditions, or unknown.
+func leafGoType(f *Field, unknown string) string {
+	switch f.kind {
+	case kindInt:
+		return "int"
+	case kindFloat:
+		return "float64"
+	case kindString:
+		return "string"
+	case kindBool:
+		return "bool"
+	case kindMap, kindCollection, kindAny:
+		return "any"
+	}
+	if f.IsBool() {
+		return "bool"
+	}
+	return unknown
+}
+
+// leafGoValue returns the Go expression of the zero value placeholder of a leaf value, in a map[string]any.
+func leafGoValue(f *Field) string {
+	switch {
+	case f.kind == kindInt:
+		return "0"
+	case f.kind == kindFloat:
+		return "0.0"
+	case f.kind == kindMap:
+		return "map[string]any{}"
+	case f.kind == kindCollection:
+		return "[]any{}"
+	case leafGoType(f, "string") == "bool":
+		return "false"
+	}
+	return `""`
+}
+
+// GoStruct returns a Go type declaration of a struct skeleton with the given name holding the fields of f. Nested
+// values are declared as anonymous structs, and values whose type cannot be inferred are strings, to be edited.
+func (f *Field) GoStruct(name string) string {
+	var b strings.Builder
+	b.WriteString("type " + name + " ")
+	writeGoStructType(&b, f, 0, "string")
+	b.WriteString("\n")
+	return b.String()
+}
+
+// GoType returns the Go type expression of f, with nested values declared as anonymous structs. Unlike GoStruct,
+// values whose type cannot be inferred are of type any, so the type accepts any data the templates can render.
+func (f *Field) GoType() string {
+	var b strings.Builder
+	writeGoStructType(&b, f, 0, "any")
+	return b.String()
+}
+
+// writeGoStructType writes the Go type of f, with unknown as the type of values whose type cannot be inferred.
+func writeGoStructType(b *strings.Builder, f *Field, depth int, unknown string) {
+	switch {
+	case f.IsList():
+		b.WriteString("[]")
+		writeGoStructType(b, f.Elem, depth, unknown)
+	case f.IsObject():
+		b.WriteString("struct {\n")
+		for _, field := range f.SortedFields() {
+			writeIndent(b, depth+1)
+			b.WriteString(field.Name + " ")
+			writeGoStructType(b, field, depth+1, unknown)
+			b.WriteString("\n")
+		}
+		writeIndent(b, depth)
+		b.WriteString("}")
+	default:
+		b.WriteString(leafGoType(f, unknown))
+	}
+}
+
+// JSON returns an indented JSON skeleton holding the fields of f, with zero values as placeholders.
+func (f *Field) JSON() (string, error) {
+	b, err := json.MarshalIndent(jsonValue(f), "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("error encoding fixture: %w", err)
+	}
+	return string(b), nil
+}
+
+// Fixture returns a minimal value holding the fields of f, with zero values as placeholders: lists hold a single
+// element, objects are maps and other fields are zero values of the type suggested by their use, such as 0 for
+// values compared to numbers, or empty strings. It is the value JSON encodes.
+func (f *Field) Fixture() any {
+	return jsonValue(f)
+}
+
+func jsonValue(f *Field) any {
+	switch {
+	case f.IsList():
+		return []any{jsonValue(f.Elem)}
+	case f.IsObject():
+		values := make(map[string]any, len(f.Fields))
+		for name, field := range f.Fields {
+			values[name] = jsonValue(field)
+		}
+		return values
+	}
+	switch f.kind {
+	case kindInt:
+		return 0
+	case kindFloat:
+		return 0.0
+	case kindMap:
+		return map[string]any{}
+	case kindCollection:
+		return []any{}
+	}
+	if leafGoType(f, "string") == "bool" {
+		return false
+	}
+	return ""
+}
+
+func writeIndent(b *strings.Builder, depth int) {
+	b.WriteString(strings.Repeat("\t", depth))
+}
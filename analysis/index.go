@@ -0,0 +1,221 @@
+package analysis
+
+import (
+	"sort"
+	"strings"
+	"text/template/parse"
+)
+
+// RefKind is the kind of a reference found in a template.
+type RefKind string
+
+const (
+	// RefDefine is a template definition, e.g. {{define "page:main"}}.
+	RefDefine RefKind = "define"
+	// RefTemplate is a call of a template or component, e.g. {{template "@card" .}}.
+	RefTemplate RefKind = "template"
+	// RefFunc is a function call, e.g. {{formatDate .Date}}.
+	RefFunc RefKind = "func"
+	// RefField is a field access, e.g. {{.Product.Price}}, recorded with its full path.
+	RefField RefKind = "field"
+	// RefText is static text content.
+	RefText RefKind = "text"
+)
+
+// Reference is a definition, call, field access or text found in a template file.
+type Reference struct {
+	// Kind is the kind of reference.
+	Kind RefKind `json:"kind"`
+	// Name is the template or function name, the field path (e.g. ".Product.Price" or "$item.Price") or the text.
+	Name string `json:"name"`
+	// Path is the path of the template file.
+	Path string `json:"path"`
+	// Line is the line of the reference in the file.
+	Line int `json:"line"`
+	// Template is the name of the template the reference is in.
+	Template string `json:"template"`
+}
+
+// Index is a search index of the references in template files, for editors and refactoring tools.
+type Index struct {
+	refs []Reference
+}
+
+// Index indexes the template files of the set.
+func (s *Set) Index() *Index {
+	idx := &Index{}
+	for _, tmpl := range s.Templates() {
+		var names, slots []string
+		for name := range tmpl.Trees {
+			if IsHoisted(name) {
+				slots = append(slots, name)
+			} else {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		sort.Strings(slots)
+
+		calls := make(map[string]componentCall)
+		for _, name := range names {
+			tree := tmpl.Trees[name]
+			ix := &indexer{idx: idx, tmpl: tmpl, template: name, calls: calls}
+			if name != tree.ParseName {
+				ix.add(RefDefine, name, tree.Root)
+			}
+			ix.walk(tree.Root)
+		}
+
+		// Slot contents are hoisted out of component blocks to the end of the file when preprocessing, so their
+		// references are attributed to the template of the component block. So are cache blocks.
+		for _, name := range slots {
+			call, ok := calls[name]
+			if !ok {
+				call = calls[name[:strings.LastIndex(name, ":")]]
+			}
+			ix := &indexer{idx: idx, tmpl: tmpl, template: call.template, calls: calls}
+			ix.walk(tmpl.Trees[name].Root)
+		}
+	}
+
+	sort.SliceStable(idx.refs, func(i, j int) bool {
+		if idx.refs[i].Path != idx.refs[j].Path {
+			return idx.refs[i].Path < idx.refs[j].Path
+		}
+		return idx.refs[i].Line < idx.refs[j].Line
+	})
+	return idx
+}
+
+// References returns all references, sorted by file and line.
+func (idx *Index) References() []Reference {
+	return append([]Reference(nil), idx.refs...)
+}
+
+// Search returns the references of the kind matching name, sorted by file and line.
+//
+//   - Definitions, template calls and function calls match by name, e.g. "formatDate".
+//   - Fields match the end of the field path, so ".Price" or "Price" matches .Price, .Product.Price and $item.Price,
+//     and "Product.Price" matches .Product.Price only.
+//   - Text matches any text containing name, ignoring case.
+func (idx *Index) Search(kind RefKind, name string) []Reference {
+	var matches []Reference
+	for _, ref := range idx.refs {
+		if ref.Kind == kind && ref.matches(name) {
+			matches = append(matches, ref)
+		}
+	}
+	return matches
+}
+
+func (r Reference) matches(name string) bool {
+	switch r.Kind {
+	case RefField:
+		name = "." + strings.TrimPrefix(name, ".")
+		return r.Name == name || strings.HasSuffix(r.Name, name)
+	case RefText:
+		return strings.Contains(strings.ToLower(r.Name), strings.ToLower(name))
+	default:
+		return r.Name == name
+	}
+}
+
+// indexer records the references of a template tree.
+type indexer struct {
+	idx      *Index
+	tmpl     *Template
+	template string                   // name of the template references are attributed to
+	calls    map[string]componentCall // component calls of the file, keyed by component id, and cache blocks by name
+}
+
+// componentCall is the template a component or cache block is in.
+type componentCall struct {
+	template string
+}
+
+func (ix *indexer) add(kind RefKind, name string, node parse.Node) {
+	ix.idx.refs = append(ix.idx.refs, Reference{
+		Kind:     kind,
+		Name:     name,
+		Path:     ix.tmpl.Path,
+		Line:     ix.tmpl.Line(node),
+		Template: ix.template,
+	})
+}
+
+func (ix *indexer) walk(node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			ix.walk(child)
+		}
+	case *parse.TextNode:
+		if text := strings.TrimSpace(string(n.Text)); text != "" {
+			ix.add(RefText, text, n)
+		}
+	case *parse.ActionNode:
+		ix.pipe(n.Pipe)
+	case *parse.IfNode:
+		ix.branch(&n.BranchNode)
+	case *parse.RangeNode:
+		ix.branch(&n.BranchNode)
+	case *parse.WithNode:
+		ix.branch(&n.BranchNode)
+	case *parse.TemplateNode:
+		ix.add(RefTemplate, n.Name, n)
+		ix.pipe(n.Pipe)
+	}
+}
+
+func (ix *indexer) branch(n *parse.BranchNode) {
+	ix.pipe(n.Pipe)
+	ix.walk(n.List)
+	ix.walk(n.ElseList)
+}
+
+func (ix *indexer) pipe(p *parse.PipeNode) {
+	if p == nil {
+		return
+	}
+
+	for _, cmd := range p.Cmds {
+		for i, arg := range cmd.Args {
+			switch a := arg.(type) {
+			case *parse.IdentifierNode:
+				// Component blocks are rewritten to renderComponent calls, whose fourth argument is the component name
+				if a.Ident == "renderComponent" && i == 0 && len(cmd.Args) >= 5 {
+					if name, ok := cmd.Args[4].(*parse.StringNode); ok {
+						if id, ok := cmd.Args[1].(*parse.StringNode); ok {
+							ix.calls[id.Text] = componentCall{template: ix.template}
+						}
+						ix.add(RefTemplate, name.Text, a)
+						continue
+					}
+				}
+				// Cache blocks are rewritten to cachedTemplate calls, whose third argument is the hoisted block
+				if a.Ident == "cachedTemplate" && i == 0 && len(cmd.Args) >= 4 {
+					if name, ok := cmd.Args[3].(*parse.StringNode); ok && IsHoisted(name.Text) {
+						ix.calls[name.Text] = componentCall{template: ix.template}
+					}
+				}
+				ix.add(RefFunc, a.Ident, a)
+			case *parse.FieldNode:
+				ix.add(RefField, "."+strings.Join(a.Ident, "."), a)
+			case *parse.VariableNode:
+				if len(a.Ident) > 1 {
+					ix.add(RefField, strings.Join(a.Ident, "."), a)
+				}
+			case *parse.ChainNode:
+				if pipe, ok := a.Node.(*parse.PipeNode); ok {
+					ix.pipe(pipe)
+				}
+				ix.add(RefField, "(...)."+strings.Join(a.Field, "."), a)
+			case *parse.PipeNode:
+				ix.pipe(a)
+			}
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package analysis_test
+
+import (
+	"fmt"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview/analysis"
+)
+
+func TestIndex_Search(t *testing.T) {
+	set, err := analysis.Load(fstest.MapFS{
+		"partials/price.html": {Data: []byte(`{{define "@price"}}{{formatMoney .Price}}{{end}}`)},
+		"views/product.html": {Data: []byte(`{{define "page:main"}}
+<h1>{{.Product.Name}}</h1>
+{{range $item := .Items}}{{$item.Price}}{{end}}
+{{template "@price" .Product}}
+{{component "@card" (dict "Title" "Specs")}}Out of stock{{end}}
+{{cache "specs" "5m"}}{{.Specs}}{{end}}
+{{end}}`)},
+	}, ".html")
+	if err != nil {
+		t.Fatalf("error loading templates: %v", err)
+	}
+	idx := set.Index()
+
+	tests := []struct {
+		kind analysis.RefKind
+		name string
+		want []string
+	}{
+		{analysis.RefField, ".Price", []string{"partials/price.html:1:.Price", "views/product.html:3:$item.Price"}},
+		{analysis.RefField, "Product.Name", []string{"views/product.html:2:.Product.Name"}},
+		{analysis.RefFunc, "formatMoney", []string{"partials/price.html:1:formatMoney"}},
+		{analysis.RefTemplate, "@price", []string{"views/product.html:4:@price"}},
+		{analysis.RefTemplate, "@card", []string{"views/product.html:5:@card"}},
+		{analysis.RefDefine, "page:main", []string{"views/product.html:1:page:main"}},
+		{analysis.RefText, "out of STOCK", []string{"views/product.html:5:Out of stock"}},
+		{analysis.RefField, ".Specs", []string{"views/product.html:6:.Specs"}},
+		{analysis.RefFunc, "renderComponent", nil},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s %s", tt.kind, tt.name), func(t *testing.T) {
+			var got []string
+			for _, ref := range idx.Search(tt.kind, tt.name) {
+				got = append(got, fmt.Sprintf("%s:%d:%s", ref.Path, ref.Line, ref.Name))
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if refs := idx.Search(analysis.RefText, "stock"); len(refs) != 1 || refs[0].Template != "page:main" {
+		t.Errorf("expected slot content to be attributed to the template of the component block, got %+v", refs)
+	}
+}
@@ -0,0 +1,221 @@
+package analysis
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/template/parse"
+)
+
+// Severity is the severity of a lint issue.
+type Severity int
+
+const (
+	// SeverityOff disables a rule.
+	SeverityOff Severity = iota
+	SeverityInfo
+	SeverityWarning
+	SeverityError
+)
+
+// String returns the name of the severity.
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	}
+	return "off"
+}
+
+// ParseSeverity parses the name of a severity.
+func ParseSeverity(name string) (Severity, error) {
+	for _, s := range []Severity{SeverityOff, SeverityInfo, SeverityWarning, SeverityError} {
+		if s.String() == name {
+			return s, nil
+		}
+	}
+	return SeverityOff, fmt.Errorf("unknown severity %q", name)
+}
+
+// Rule identifies a lint rule.
+type Rule string
+
+const (
+	// RuleNestedRange reports ranges nested deeper than LintOptions.MaxRangeDepth, whose cost grows multiplicatively.
+	RuleNestedRange Rule = "nested-range"
+	// RuleIncludeInLoop reports templates and components rendered inside a range, which are executed for every
+	// element and are easily overlooked when the loop grows.
+	RuleIncludeInLoop Rule = "include-in-loop"
+	// RulePrintfInLoop reports printf, print and println called inside a range, which allocate on every iteration.
+	// They are usually better replaced by a formatting function or by formatting in the view model.
+	RulePrintfInLoop Rule = "printf-in-loop"
+	// RuleLargeTemplate reports template files with more lines than LintOptions.MaxLines, which are slow to parse and
+	// usually better split into partials.
+	RuleLargeTemplate Rule = "large-template"
+)
+
+// Rules are all lint rules, with their default severity.
+var Rules = map[Rule]Severity{
+	RuleNestedRange:   SeverityWarning,
+	RuleIncludeInLoop: SeverityInfo,
+	RulePrintfInLoop:  SeverityWarning,
+	RuleLargeTemplate: SeverityWarning,
+}
+
+// LintOptions are the options for linting templates.
+type LintOptions struct {
+	// MaxRangeDepth is the maximum nesting depth of ranges. Default is 2.
+	MaxRangeDepth int
+	// MaxLines is the maximum number of lines of a template file. Default is 500.
+	MaxLines int
+	// Severities overrides the default severity of rules. Rules set to SeverityOff are not checked.
+	Severities map[Rule]Severity
+}
+
+// Issue is a problem found by a lint rule.
+type Issue struct {
+	Path     string
+	Line     int
+	Rule     Rule
+	Severity Severity
+	Message  string
+}
+
+// String formats the issue as path:line: severity: message (rule).
+func (i Issue) String() string {
+	return fmt.Sprintf("%s:%d: %s: %s (%s)", i.Path, i.Line, i.Severity, i.Message, i.Rule)
+}
+
+// Lint checks the templates of the set against the lint rules and returns the issues found, sorted by path and line.
+func (s *Set) Lint(opts LintOptions) []Issue {
+	if opts.MaxRangeDepth == 0 {
+		opts.MaxRangeDepth = 2
+	}
+	if opts.MaxLines == 0 {
+		opts.MaxLines = 500
+	}
+
+	l := &linter{opts: opts}
+	for _, tmpl := range s.Templates() {
+		if lines := strings.Count(tmpl.Source, "\n") + 1; lines > opts.MaxLines {
+			l.report(tmpl.Path, 1, RuleLargeTemplate, "template has %d lines, more than the maximum of %d", lines, opts.MaxLines)
+		}
+
+		for _, tree := range tmpl.Trees {
+			l.path = tmpl.Path
+			l.tmpl = tmpl
+			l.walk(tree.Root, 0)
+		}
+	}
+
+	sort.SliceStable(l.issues, func(i, j int) bool {
+		if l.issues[i].Path != l.issues[j].Path {
+			return l.issues[i].Path < l.issues[j].Path
+		}
+		return l.issues[i].Line < l.issues[j].Line
+	})
+
+	return l.issues
+}
+
+type linter struct {
+	opts   LintOptions
+	path   string
+	tmpl   *Template
+	issues []Issue
+}
+
+func (l *linter) severity(rule Rule) Severity {
+	if severity, ok := l.opts.Severities[rule]; ok {
+		return severity
+	}
+	return Rules[rule]
+}
+
+func (l *linter) report(path string, line int, rule Rule, format string, args ...any) {
+	severity := l.severity(rule)
+	if severity == SeverityOff {
+		return
+	}
+
+	l.issues = append(l.issues, Issue{
+		Path:     path,
+		Line:     line,
+		Rule:     rule,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// line returns the line of node in the template source.
+func (l *linter) line(node parse.Node) int {
+	return l.tmpl.Line(node)
+}
+
+// walk checks node, which is nested in depth ranges.
+func (l *linter) walk(node parse.Node, depth int) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			l.walk(child, depth)
+		}
+	case *parse.ActionNode:
+		l.pipe(n.Pipe, depth)
+	case *parse.IfNode:
+		l.pipe(n.Pipe, depth)
+		l.walk(n.List, depth)
+		l.walk(n.ElseList, depth)
+	case *parse.WithNode:
+		l.pipe(n.Pipe, depth)
+		l.walk(n.List, depth)
+		l.walk(n.ElseList, depth)
+	case *parse.RangeNode:
+		l.pipe(n.Pipe, depth)
+		if depth+1 > l.opts.MaxRangeDepth {
+			l.report(l.path, l.line(n), RuleNestedRange, "range nested %d levels deep, more than the maximum of %d", depth+1, l.opts.MaxRangeDepth)
+		}
+		l.walk(n.List, depth+1)
+		l.walk(n.ElseList, depth)
+	case *parse.TemplateNode:
+		if depth > 0 {
+			l.report(l.path, l.line(n), RuleIncludeInLoop, "template %q is rendered inside a range", n.Name)
+		}
+		l.pipe(n.Pipe, depth)
+	}
+}
+
+func (l *linter) pipe(p *parse.PipeNode, depth int) {
+	if p == nil {
+		return
+	}
+
+	for _, cmd := range p.Cmds {
+		for _, arg := range cmd.Args {
+			switch a := arg.(type) {
+			case *parse.IdentifierNode:
+				if depth == 0 {
+					continue
+				}
+				switch a.Ident {
+				case "printf", "print", "println":
+					l.report(l.path, l.line(a), RulePrintfInLoop, "%s is called inside a range", a.Ident)
+				case "renderComponent":
+					if len(cmd.Args) >= 5 {
+						if name, ok := cmd.Args[4].(*parse.StringNode); ok {
+							l.report(l.path, l.line(a), RuleIncludeInLoop, "component %q is rendered inside a range", name.Text)
+						}
+					}
+				}
+			case *parse.PipeNode:
+				l.pipe(a, depth)
+			}
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package analysis_test
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview/analysis"
+)
+
+func TestSet_Lint(t *testing.T) {
+	set := newTestSet(t, fstest.MapFS{
+		"partials/row.html": {Data: []byte(`{{define "row"}}{{.}}{{end}}`)},
+		"views/report.html": {Data: []byte(`{{define "page:main"}}
+{{range .Groups}}
+	{{range .Rows}}
+		{{range .Cells}}{{printf "%v" .}}{{end}}
+		{{template "row" .}}
+	{{end}}
+{{end}}
+{{printf "%d" .Total}}
+{{end}}`)},
+		"views/large.html": {Data: []byte(strings.Repeat("<p>line</p>\n", 20))},
+	})
+
+	tests := []struct {
+		name string
+		opts analysis.LintOptions
+		want []string
+	}{
+		{
+			name: "default rules",
+			opts: analysis.LintOptions{MaxLines: 10},
+			want: []string{
+				"views/large.html:1: warning: template has 21 lines, more than the maximum of 10 (large-template)",
+				"views/report.html:4: warning: range nested 3 levels deep, more than the maximum of 2 (nested-range)",
+				"views/report.html:4: warning: printf is called inside a range (printf-in-loop)",
+				`views/report.html:5: info: template "row" is rendered inside a range (include-in-loop)`,
+			},
+		},
+		{
+			name: "severity overrides",
+			opts: analysis.LintOptions{
+				MaxRangeDepth: 3,
+				Severities: map[analysis.Rule]analysis.Severity{
+					analysis.RuleIncludeInLoop: analysis.SeverityOff,
+					analysis.RulePrintfInLoop:  analysis.SeverityError,
+				},
+			},
+			want: []string{
+				"views/report.html:4: error: printf is called inside a range (printf-in-loop)",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := set.Lint(tt.opts)
+
+			got := make([]string, len(issues))
+			for i, issue := range issues {
+				got[i] = issue.String()
+			}
+
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("unexpected issues:\ngot\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestSet_LintComponentInLoop(t *testing.T) {
+	set := newTestSet(t, fstest.MapFS{
+		"views/list.html": {Data: []byte(`{{range .Items}}{{component "@card" (dict "Title" .)}}{{end}}{{end}}`)},
+	})
+
+	issues := set.Lint(analysis.LintOptions{})
+	if len(issues) != 1 || issues[0].Message != `component "@card" is rendered inside a range` {
+		t.Errorf("unexpected issues: %v", issues)
+	}
+}
+
+func TestSet_LintHoistedLines(t *testing.T) {
+	set := newTestSet(t, fstest.MapFS{
+		"views/list.html": {Data: []byte(`{{define "page:main"}}
+{{component "@card"}}
+	{{slot "header"}}
+		<h2>{{.Title}}</h2>
+	{{end}}
+	{{range .Items}}
+		{{printf "%v" .}}
+	{{end}}
+{{end}}
+{{cache "footer" "5m"}}
+	<ul>
+	{{range .Links}}
+		{{template "link" .}}
+	{{end}}
+	</ul>
+{{end}}
+{{end}}`)},
+	})
+
+	var got []string
+	for _, issue := range set.Lint(analysis.LintOptions{}) {
+		got = append(got, issue.String())
+	}
+	want := []string{
+		"views/list.html:7: warning: printf is called inside a range (printf-in-loop)",
+		`views/list.html:13: info: template "link" is rendered inside a range (include-in-loop)`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected issues:\ngot\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	if s, err := analysis.ParseSeverity("warning"); err != nil || s != analysis.SeverityWarning {
+		t.Errorf("ParseSeverity() = %v, %v", s, err)
+	}
+	if _, err := analysis.ParseSeverity("fatal"); err == nil {
+		t.Error("expected an error for an unknown severity")
+	}
+}
@@ -0,0 +1,159 @@
+// Package assets fingerprints static assets for cache busting.
+//
+// A Manifest maps the logical path of each asset (e.g. css/app.css) to a fingerprinted path containing a hash of its
+// content (e.g. css/app.3f2a1b9c.css). Templates use the asset function to link to the fingerprinted URL, and the
+// manifest's handler serves those URLs with far-future cache headers, as their content never changes.
+package assets
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// DefaultPrefix is the default URL prefix of the assets.
+const DefaultPrefix = "/static/"
+
+// immutableCacheControl is the Cache-Control header of fingerprinted assets.
+const immutableCacheControl = "public, max-age=31536000, immutable"
+
+// Options are the options for a Manifest.
+type Options struct {
+	// Prefix is the URL prefix the assets are served under. Default is DefaultPrefix.
+	Prefix string
+	// ManifestFile is the path of a JSON manifest in the file system, mapping logical paths to fingerprinted paths,
+	// as generated by a frontend build tool. If empty, the files are hashed when the manifest is created.
+	ManifestFile string
+}
+
+// Manifest maps logical asset paths to fingerprinted paths.
+type Manifest struct {
+	fsys    fs.FS
+	prefix  string
+	paths   map[string]string // logical path -> fingerprinted path
+	sources map[string]string // fingerprinted path -> file path in fsys
+}
+
+// New creates a Manifest for the assets in fsys.
+func New(fsys fs.FS, opts Options) (*Manifest, error) {
+	if opts.Prefix == "" {
+		opts.Prefix = DefaultPrefix
+	}
+
+	m := &Manifest{
+		fsys:    fsys,
+		prefix:  "/" + strings.Trim(opts.Prefix, "/") + "/",
+		paths:   make(map[string]string),
+		sources: make(map[string]string),
+	}
+	if m.prefix == "//" {
+		m.prefix = "/"
+	}
+
+	if opts.ManifestFile != "" {
+		if err := m.loadManifest(opts.ManifestFile); err != nil {
+			return nil, fmt.Errorf("error loading asset manifest %s: %w", opts.ManifestFile, err)
+		}
+		return m, nil
+	}
+
+	if err := m.hashFiles(); err != nil {
+		return nil, fmt.Errorf("error fingerprinting assets: %w", err)
+	}
+
+	return m, nil
+}
+
+func (m *Manifest) loadManifest(name string) error {
+	data, err := fs.ReadFile(m.fsys, name)
+	if err != nil {
+		return err
+	}
+
+	var paths map[string]string
+	if err := json.Unmarshal(data, &paths); err != nil {
+		return err
+	}
+
+	for logical, fingerprinted := range paths {
+		logical = strings.TrimPrefix(logical, "/")
+		fingerprinted = strings.TrimPrefix(fingerprinted, "/")
+		m.paths[logical] = fingerprinted
+		m.sources[fingerprinted] = fingerprinted
+	}
+
+	return nil
+}
+
+func (m *Manifest) hashFiles() error {
+	return fs.WalkDir(m.fsys, ".", func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		data, err := fs.ReadFile(m.fsys, filePath)
+		if err != nil {
+			return err
+		}
+
+		sum := sha256.Sum256(data)
+		fingerprinted := Fingerprint(filePath, hex.EncodeToString(sum[:4]))
+		m.paths[filePath] = fingerprinted
+		m.sources[fingerprinted] = filePath
+
+		return nil
+	})
+}
+
+// Fingerprint inserts the hash into the file name of filePath, before its extension.
+// Example: Fingerprint("css/app.css", "3f2a1b9c") returns "css/app.3f2a1b9c.css".
+func Fingerprint(filePath, hash string) string {
+	ext := path.Ext(filePath)
+	return strings.TrimSuffix(filePath, ext) + "." + hash + ext
+}
+
+// URL returns the fingerprinted URL of the asset with the given logical path.
+func (m *Manifest) URL(name string) (string, error) {
+	fingerprinted, ok := m.paths[strings.TrimPrefix(name, "/")]
+	if !ok {
+		return "", fmt.Errorf("asset not found: %s", name)
+	}
+	return m.prefix + fingerprinted, nil
+}
+
+// Funcs returns the template functions of the manifest, to add to the template adapter's Funcs option:
+//
+//   - asset: returns the fingerprinted URL of an asset, e.g. <link rel="stylesheet" href="{{asset "css/app.css"}}">
+func (m *Manifest) Funcs() template.FuncMap {
+	return template.FuncMap{
+		"asset": m.URL,
+	}
+}
+
+// Handler returns an http.Handler serving the assets under the manifest's prefix. Fingerprinted URLs are served with
+// far-future cache headers. Assets requested by their logical path are served too, without those headers.
+func (m *Manifest) Handler() http.Handler {
+	return http.StripPrefix(strings.TrimSuffix(m.prefix, "/"), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(r.URL.Path, "/")
+
+		if source, ok := m.sources[name]; ok {
+			w.Header().Set("Cache-Control", immutableCacheControl)
+			http.ServeFileFS(w, r, m.fsys, source)
+			return
+		}
+
+		if _, ok := m.paths[name]; ok {
+			w.Header().Set("Cache-Control", "no-cache")
+			http.ServeFileFS(w, r, m.fsys, name)
+			return
+		}
+
+		http.NotFound(w, r)
+	}))
+}
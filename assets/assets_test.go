@@ -0,0 +1,93 @@
+package assets_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview/assets"
+)
+
+func TestManifest_HashedFiles(t *testing.T) {
+	files := fstest.MapFS{
+		"css/app.css": {Data: []byte("body { color: red; }")},
+		"js/app.js":   {Data: []byte("console.log('hello')")},
+	}
+
+	m, err := assets.New(files, assets.Options{})
+	if err != nil {
+		t.Fatalf("error creating manifest: %v", err)
+	}
+
+	url, err := m.URL("css/app.css")
+	if err != nil {
+		t.Fatalf("error getting asset URL: %v", err)
+	}
+	if !regexp.MustCompile(`^/static/css/app\.[0-9a-f]{8}\.css$`).MatchString(url) {
+		t.Errorf("unexpected URL: %s", url)
+	}
+
+	if _, err := m.URL("css/missing.css"); err == nil {
+		t.Error("expected an error for a missing asset")
+	}
+
+	tests := []struct {
+		name             string
+		path             string
+		wantStatus       int
+		wantCacheControl string
+		wantBody         string
+	}{
+		{"fingerprinted", url, http.StatusOK, "public, max-age=31536000, immutable", "body { color: red; }"},
+		{"logical path", "/static/css/app.css", http.StatusOK, "no-cache", "body { color: red; }"},
+		{"stale fingerprint", "/static/css/app.00000000.css", http.StatusNotFound, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("unexpected status: got %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("Cache-Control"); got != tt.wantCacheControl {
+				t.Errorf("unexpected Cache-Control: got %q, want %q", got, tt.wantCacheControl)
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Errorf("unexpected body: got %q, want %q", w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestManifest_ManifestFile(t *testing.T) {
+	files := fstest.MapFS{
+		"manifest.json":      {Data: []byte(`{"css/app.css": "css/app.abc123.css"}`)},
+		"css/app.abc123.css": {Data: []byte("body {}")},
+	}
+
+	m, err := assets.New(files, assets.Options{Prefix: "/assets", ManifestFile: "manifest.json"})
+	if err != nil {
+		t.Fatalf("error creating manifest: %v", err)
+	}
+
+	url, err := m.Funcs()["asset"].(func(string) (string, error))("/css/app.css")
+	if err != nil || url != "/assets/css/app.abc123.css" {
+		t.Fatalf("asset() = %q, %v", url, err)
+	}
+
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+	if w.Code != http.StatusOK || w.Body.String() != "body {}" {
+		t.Errorf("unexpected response: %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	if got := assets.Fingerprint("css/app.min.css", "abc"); got != "css/app.min.abc.css" {
+		t.Errorf("Fingerprint() = %s", got)
+	}
+}
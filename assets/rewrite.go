@@ -0,0 +1,96 @@
+package assets
+
+import (
+	"bytes"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// assetAttr matches src and href attributes, with a double-quoted, single-quoted or unquoted value.
+var assetAttr = regexp.MustCompile(`(?i)\s(?:src|href)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+
+// RewriteHTML rewrites the src and href attributes of html pointing at an asset under the manifest's prefix by its
+// logical path, such as /static/css/app.css, to the fingerprinted URL of the asset. Query strings and fragments are
+// kept, and other URLs are left as is.
+func (m *Manifest) RewriteHTML(html []byte) []byte {
+	matches := assetAttr.FindAllSubmatchIndex(html, -1)
+	if matches == nil {
+		return html
+	}
+
+	var b bytes.Buffer
+	last := 0
+	for _, match := range matches {
+		// The value is in the first group that matched: double-quoted, single-quoted or unquoted
+		for group := 1; group <= 3; group++ {
+			start, end := match[2*group], match[2*group+1]
+			if start < 0 {
+				continue
+			}
+			if url, ok := m.rewriteURL(string(html[start:end])); ok {
+				b.Write(html[last:start])
+				b.WriteString(url)
+				last = end
+			}
+			break
+		}
+	}
+	b.Write(html[last:])
+
+	return b.Bytes()
+}
+
+// rewriteURL returns the fingerprinted URL of an asset URL by its logical path, if it is one.
+func (m *Manifest) rewriteURL(url string) (string, bool) {
+	if !strings.HasPrefix(url, m.prefix) {
+		return "", false
+	}
+
+	name, suffix := url[len(m.prefix):], ""
+	if i := strings.IndexAny(name, "?#"); i != -1 {
+		name, suffix = name[:i], name[i:]
+	}
+
+	fingerprinted, ok := m.paths[name]
+	if !ok {
+		return "", false
+	}
+	return m.prefix + fingerprinted + suffix, true
+}
+
+// Rewrite returns a middleware rewriting the asset URLs of HTML responses to their fingerprinted URLs (see
+// RewriteHTML), so hand-written asset references in templates benefit from cache busting without calling asset.
+func (m *Manifest) Rewrite(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf := &bufferedWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(buf, r)
+
+		body := buf.body.Bytes()
+		if strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") {
+			body = m.RewriteHTML(body)
+			w.Header().Del("Content-Length")
+		}
+
+		w.WriteHeader(buf.status)
+		_, _ = w.Write(body)
+	})
+}
+
+// bufferedWriter buffers a response, so its asset URLs can be rewritten before it is written.
+type bufferedWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedWriter) WriteHeader(status int) {
+	b.status = status
+}
+
+func (b *bufferedWriter) Write(p []byte) (int, error) {
+	if b.Header().Get("Content-Type") == "" {
+		b.Header().Set("Content-Type", http.DetectContentType(p))
+	}
+	return b.body.Write(p)
+}
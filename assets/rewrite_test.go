@@ -0,0 +1,93 @@
+package assets_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview/assets"
+)
+
+func newRewriteTestManifest(t *testing.T) *assets.Manifest {
+	t.Helper()
+
+	files := fstest.MapFS{
+		"manifest.json": {Data: []byte(`{"css/app.css": "css/app.abc123.css", "js/app.js": "js/app.def456.js"}`)},
+	}
+	m, err := assets.New(files, assets.Options{ManifestFile: "manifest.json"})
+	if err != nil {
+		t.Fatalf("error creating manifest: %v", err)
+	}
+	return m
+}
+
+func TestManifest_RewriteHTML(t *testing.T) {
+	m := newRewriteTestManifest(t)
+
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{
+			name: "double quoted",
+			html: `<link rel="stylesheet" href="/static/css/app.css">`,
+			want: `<link rel="stylesheet" href="/static/css/app.abc123.css">`,
+		},
+		{
+			name: "single quoted and unquoted",
+			html: `<script src='/static/js/app.js'></script><script SRC=/static/js/app.js></script>`,
+			want: `<script src='/static/js/app.def456.js'></script><script SRC=/static/js/app.def456.js></script>`,
+		},
+		{
+			name: "query and fragment",
+			html: `<a href="/static/css/app.css?v=1#top">css</a>`,
+			want: `<a href="/static/css/app.abc123.css?v=1#top">css</a>`,
+		},
+		{
+			name: "unknown assets and other URLs",
+			html: `<img src="/static/img/logo.png"><a href="/css/app.css">css/app.css</a><a href="https://example.com/static/css/app.css">`,
+			want: `<img src="/static/img/logo.png"><a href="/css/app.css">css/app.css</a><a href="https://example.com/static/css/app.css">`,
+		},
+		{
+			name: "already fingerprinted",
+			html: `<link href="/static/css/app.abc123.css">`,
+			want: `<link href="/static/css/app.abc123.css">`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(m.RewriteHTML([]byte(tt.html))); got != tt.want {
+				t.Errorf("unexpected HTML:\ngot  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestManifest_Rewrite(t *testing.T) {
+	m := newRewriteTestManifest(t)
+
+	handler := m.Rewrite(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/data.json" {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"href": "/static/css/app.css"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`<link href="/static/css/app.css">`))
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusCreated || w.Body.String() != `<link href="/static/css/app.abc123.css">` {
+		t.Errorf("unexpected HTML response: %d %q", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/data.json", nil))
+	if w.Body.String() != `{"href": "/static/css/app.css"}` {
+		t.Errorf("expected non-HTML responses to be left as is, got %q", w.Body.String())
+	}
+}
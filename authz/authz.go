@@ -0,0 +1,99 @@
+// Package authz exposes authorization checks to templates, so the links and buttons of a page are shown or hidden
+// declaratively rather than with a flag per permission passed by each handler:
+//
+//	{{if can "edit" .Post}}<a href="/posts/{{.Post.ID}}/edit">Edit</a>{{end}}
+//	{{if cannot "delete" .Post}}<p>Only the author can delete this post.</p>{{end}}
+//
+// The checks are delegated to an Authorizer added to the request by Middleware. Without one, everything is denied.
+package authz
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"net/http"
+
+	"github.com/hypergopher/hyperview"
+)
+
+// Authorizer decides whether the user of a request may perform actions on resources.
+type Authorizer interface {
+	// Can reports whether the user of ctx may perform action on resource. Resource is nil for actions on no resource
+	// in particular, e.g. "create-post".
+	Can(ctx context.Context, action string, resource any) bool
+}
+
+// AuthorizerFunc is a function implementing Authorizer.
+type AuthorizerFunc func(ctx context.Context, action string, resource any) bool
+
+// Can calls f(ctx, action, resource).
+func (f AuthorizerFunc) Can(ctx context.Context, action string, resource any) bool {
+	return f(ctx, action, resource)
+}
+
+type contextKey struct{}
+
+// ContextWithAuthorizer returns a copy of ctx carrying the authorizer.
+func ContextWithAuthorizer(ctx context.Context, authorizer Authorizer) context.Context {
+	return context.WithValue(ctx, contextKey{}, authorizer)
+}
+
+// AuthorizerFromContext returns the authorizer carried by ctx, or nil if there is none.
+func AuthorizerFromContext(ctx context.Context) Authorizer {
+	if ctx == nil {
+		return nil
+	}
+	authorizer, _ := ctx.Value(contextKey{}).(Authorizer)
+	return authorizer
+}
+
+// Can reports whether the user of ctx may perform action on resource, according to the authorizer of ctx. It denies
+// everything without an authorizer, so a missing Middleware fails closed.
+func Can(ctx context.Context, action string, resource any) bool {
+	authorizer := AuthorizerFromContext(ctx)
+	if authorizer == nil {
+		return false
+	}
+	return authorizer.Can(ctx, action, resource)
+}
+
+// Middleware returns a middleware adding the authorizer to the request context, along with the template functions
+// bound to the request (see Funcs). The authorizer reads the user from the context, so the middleware is installed
+// inside the one authenticating the user.
+func Middleware(authorizer Authorizer) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := ContextWithAuthorizer(r.Context(), authorizer)
+			ctx = hyperview.ContextWithFuncs(ctx, Funcs(ctx))
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// Funcs returns the template functions checking the authorizations of the given context:
+//
+//   - can: reports whether an action is allowed on an optional resource, e.g. {{if can "edit" .Post}}
+//   - cannot: the negation of can, e.g. {{if cannot "create-post"}}
+//
+// Pass Funcs(nil) to the template adapter's RequestFuncs option, which denies everything, and use Middleware to bind
+// them to each request.
+func Funcs(ctx context.Context) template.FuncMap {
+	can := func(action string, resource ...any) (bool, error) {
+		switch len(resource) {
+		case 0:
+			return Can(ctx, action, nil), nil
+		case 1:
+			return Can(ctx, action, resource[0]), nil
+		}
+		return false, fmt.Errorf("can %s: expected at most one resource, got %d", action, len(resource))
+	}
+
+	return template.FuncMap{
+		"can": can,
+		"cannot": func(action string, resource ...any) (bool, error) {
+			allowed, err := can(action, resource...)
+			return !allowed && err == nil, err
+		},
+	}
+}
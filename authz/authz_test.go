@@ -0,0 +1,96 @@
+package authz_test
+
+import (
+	"context"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/authz"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/response"
+)
+
+type post struct {
+	Author string
+}
+
+type userKey struct{}
+
+// authorOnly allows the authors of posts to edit them, and anyone signed in to create posts.
+var authorOnly = authz.AuthorizerFunc(func(ctx context.Context, action string, resource any) bool {
+	user, _ := ctx.Value(userKey{}).(string)
+	switch action {
+	case "create-post":
+		return user != ""
+	case "edit":
+		p, ok := resource.(post)
+		return ok && user != "" && p.Author == user
+	}
+	return false
+})
+
+func TestFuncs(t *testing.T) {
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{
+			"layouts/base.html": {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+			"views/post.html": {Data: []byte(`{{define "page:main"}}{{if can "edit" .Post}}edit{{end}}|` +
+				`{{if cannot "edit" .Post}}readonly{{end}}|{{if can "create-post"}}create{{end}}{{end}}`)},
+		}},
+		RequestFuncs: authz.Funcs(nil),
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		authorizer authz.Authorizer
+		user       string
+		want       string
+	}{
+		{name: "author", authorizer: authorOnly, user: "ada", want: "edit||create"},
+		{name: "other user", authorizer: authorOnly, user: "grace", want: "|readonly|create"},
+		{name: "anonymous", authorizer: authorOnly, want: "|readonly|"},
+		{name: "no authorizer denies everything", user: "ada", want: "|readonly|"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				adapter.Render(w, r, response.NewResponse().Layout("base").Path("post").
+					Data(map[string]any{"Post": post{Author: "ada"}}))
+			})
+			if tt.authorizer != nil {
+				handler = authz.Middleware(tt.authorizer)(handler)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req = req.WithContext(context.WithValue(req.Context(), userKey{}, tt.user))
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("expected body %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCan(t *testing.T) {
+	ctx := context.WithValue(context.Background(), userKey{}, "ada")
+	if authz.Can(ctx, "create-post", nil) {
+		t.Error("expected a context without an authorizer to be denied")
+	}
+	if !authz.Can(authz.ContextWithAuthorizer(ctx, authorOnly), "create-post", nil) {
+		t.Error("expected the authorizer of the context to allow the action")
+	}
+
+	can := authz.Funcs(ctx)["can"].(func(string, ...any) (bool, error))
+	if _, err := can("edit", post{}, post{}); err == nil {
+		t.Error("expected an error for several resources")
+	}
+}
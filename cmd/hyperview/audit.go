@@ -0,0 +1,72 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/hypergopher/hyperview/analysis"
+)
+
+// runAudit checks the templates of a directory for patterns prone to XSS. It fails if any issue has the severity given
+// by -fail-on or above.
+func runAudit(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("audit", flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	dir := flags.String("dir", ".", "directory containing the layouts, partials and views directories")
+	ext := flags.String("ext", ".html", "template file extension")
+	unsafeFuncs := flags.String("unsafe-funcs", strings.Join(analysis.DefaultUnsafeFuncs, ","),
+		"comma-separated functions casting strings to trusted content without escaping them")
+	helpers := flags.String("helpers", "", "comma-separated helpers returning trusted content built from their arguments")
+	severities := flags.String("severity", "", "comma-separated rule=severity overrides, e.g. urlquery=error")
+	failOn := flags.String("fail-on", "error", "minimum severity that fails the command: info, warning or error")
+	flags.Usage = func() {
+		fmt.Fprintln(stdout, "Usage: hyperview audit [flags]")
+		fmt.Fprintln(stdout)
+		fmt.Fprintln(stdout, "Rules:")
+		for _, rule := range []analysis.Rule{analysis.RuleUnsafeCast, analysis.RuleTrustedHelper, analysis.RuleUnescapedAttr, analysis.RuleURLQuery} {
+			fmt.Fprintf(stdout, "  %-16s  default severity %s\n", rule, analysis.AuditRules[rule])
+		}
+		fmt.Fprintln(stdout)
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	overrides, err := parseSeverities(*severities, analysis.AuditRules)
+	if err != nil {
+		return err
+	}
+	opts := analysis.AuditOptions{
+		UnsafeFuncs: splitList(*unsafeFuncs),
+		Helpers:     splitList(*helpers),
+		Severities:  overrides,
+	}
+
+	threshold, err := analysis.ParseSeverity(*failOn)
+	if err != nil {
+		return err
+	}
+
+	set, err := analysis.Load(os.DirFS(*dir), *ext)
+	if err != nil {
+		return err
+	}
+
+	return printIssues(stdout, set.Audit(opts), threshold)
+}
+
+// splitList splits a comma-separated list, never returning nil.
+func splitList(s string) []string {
+	list := []string{}
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunAudit(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "views"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	src := `<p>{{safeHTML .Body}}</p><div>{{icon .Name}}</div><a href="{{.Next | urlquery}}">Next</a>`
+	if err := os.WriteFile(filepath.Join(dir, "views", "home.html"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := run([]string{"audit", "-dir", dir}, &out); err == nil {
+		t.Error("expected the unsafe cast to fail by default")
+	}
+	if !strings.Contains(out.String(), "views/home.html:1: error: data is passed to safeHTML, which does not escape it (unsafe-cast)") ||
+		!strings.Contains(out.String(), "views/home.html:1: warning: urlquery is used in the href attribute of <a>") {
+		t.Errorf("unexpected output: %s", out.String())
+	}
+
+	out.Reset()
+	if err := run([]string{"audit", "-dir", dir, "-unsafe-funcs", "", "-helpers", "icon", "-severity", "urlquery=off"}, &out); err != nil {
+		t.Errorf("expected the helper warning not to fail: %v", err)
+	}
+	if got := strings.TrimSpace(out.String()); got != "views/home.html:1: warning: data is passed to icon, which returns trusted content (trusted-helper)" {
+		t.Errorf("unexpected output: %s", got)
+	}
+
+	if err := run([]string{"audit", "-dir", dir, "-severity", "nested-range=error"}, &out); err == nil {
+		t.Error("expected an error for a lint rule")
+	}
+}
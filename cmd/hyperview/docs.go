@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hypergopher/hyperview/analysis"
+	"github.com/hypergopher/hyperview/docsite"
+)
+
+// runDocs generates a static documentation site of the templates of a directory. Gallery examples are registered in
+// code, so they are only included when generating the site with the docsite package.
+func runDocs(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("docs", flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	dir := flags.String("dir", ".", "directory containing the layouts, partials and views directories")
+	ext := flags.String("ext", ".html", "template file extension")
+	out := flags.String("out", "docs", "directory to write the site to")
+	title := flags.String("title", "", "title of the site (default \"View reference\")")
+	flags.Usage = func() {
+		fmt.Fprintln(stdout, "Usage: hyperview docs [flags]")
+		fmt.Fprintln(stdout)
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	set, err := analysis.Load(os.DirFS(*dir), *ext)
+	if err != nil {
+		return err
+	}
+
+	if err := docsite.Generate(set, *out, docsite.Options{Title: *title}); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Documented %d templates in %s\n", len(set.Templates()), *out)
+	return nil
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunDocs(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "views"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "views", "home.html"), []byte("{{/* The landing page. */}}{{.Title}}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := filepath.Join(dir, "site")
+	var stdout bytes.Buffer
+	if err := run([]string{"docs", "-dir", dir, "-out", out}, &stdout); err != nil {
+		t.Fatalf("error running docs: %v", err)
+	}
+
+	index, err := os.ReadFile(filepath.Join(out, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(index), `<a href="views/home.html">views/home.html</a> - The landing page.`) {
+		t.Errorf("unexpected index:\n%s", index)
+	}
+	if _, err := os.Stat(filepath.Join(out, "views", "home.html")); err != nil {
+		t.Errorf("expected the view page to be written: %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/hypergopher/hyperview/analysis"
+	"github.com/hypergopher/hyperview/constants"
+)
+
+// runFixtures generates a fixture skeleton of the data accessed by a view and the templates it calls, to jump-start
+// tests and document the data contract of existing templates.
+func runFixtures(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("fixtures", flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	dir := flags.String("dir", ".", "directory containing the layouts, partials and views directories")
+	ext := flags.String("ext", ".html", "template file extension")
+	format := flags.String("format", "map", "output format: map, struct or json")
+	typeName := flags.String("type", "", "type name for the struct format (default derived from the view)")
+	flags.Usage = func() {
+		fmt.Fprintln(stdout, "Usage: hyperview fixtures [flags] <view>")
+		fmt.Fprintln(stdout)
+		fmt.Fprintln(stdout, "Example: hyperview fixtures -dir templates -format struct home/index")
+		fmt.Fprintln(stdout)
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("fixtures expects a single view")
+	}
+
+	view := strings.TrimSuffix(flags.Arg(0), *ext)
+	if !strings.HasPrefix(view, constants.ViewsDir+"/") {
+		view = constants.ViewsDir + "/" + view
+	}
+
+	set, err := analysis.Load(os.DirFS(*dir), *ext)
+	if err != nil {
+		return err
+	}
+
+	root, err := set.InferView(view)
+	if err != nil {
+		return err
+	}
+
+	switch *format {
+	case "map":
+		fmt.Fprintln(stdout, root.GoMap())
+	case "struct":
+		if *typeName == "" {
+			*typeName = fixtureTypeName(view)
+		}
+		fmt.Fprint(stdout, root.GoStruct(*typeName))
+	case "json":
+		out, err := root.JSON()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(stdout, out)
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+
+	return nil
+}
+
+// fixtureTypeName derives a type name from a view, e.g. views/home/index -> HomeIndexData.
+func fixtureTypeName(view string) string {
+	var b strings.Builder
+	words := strings.FieldsFunc(strings.TrimPrefix(view, constants.ViewsDir+"/"), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		runes := []rune(word)
+		b.WriteString(string(unicode.ToUpper(runes[0])) + string(runes[1:]))
+	}
+	b.WriteString("Data")
+	return b.String()
+}
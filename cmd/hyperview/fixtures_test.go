@@ -0,0 +1,32 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunFixtures(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "views", "users"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "views", "users", "show.html"), []byte(`{{.User.Name}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := run([]string{"fixtures", "-dir", dir, "-format", "struct", "users/show"}, &out); err != nil {
+		t.Fatalf("error running fixtures: %v", err)
+	}
+
+	want := "type UsersShowData struct {\n\tUser struct {\n\t\tName string\n\t}\n}\n"
+	if out.String() != want {
+		t.Errorf("unexpected output:\ngot\n%s\nwant\n%s", out.String(), want)
+	}
+
+	if err := run([]string{"fixtures", "-dir", dir, "users/missing"}, &out); err == nil {
+		t.Error("expected an error for a missing view")
+	}
+}
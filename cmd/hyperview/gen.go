@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/hypergopher/hyperview/analysis"
+	"github.com/hypergopher/hyperview/constants"
+)
+
+// runGen generates typed render functions for the views of a directory, with data structs derived from the fields
+// the views access, so that template names and data shapes are checked at compile time. It is meant to be run by
+// go generate.
+func runGen(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("gen", flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	dir := flags.String("dir", ".", "directory containing the layouts, partials and views directories")
+	ext := flags.String("ext", ".html", "template file extension")
+	pkg := flags.String("pkg", "views", "package name of the generated file")
+	out := flags.String("out", "", "file to write the generated code to (default standard output)")
+	flags.Usage = func() {
+		fmt.Fprintln(stdout, "Usage: hyperview gen [flags]")
+		fmt.Fprintln(stdout)
+		fmt.Fprintln(stdout, "Example: //go:generate go run github.com/hypergopher/hyperview/cmd/hyperview gen -dir templates -out views_gen.go")
+		fmt.Fprintln(stdout)
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return fmt.Errorf("gen expects no arguments")
+	}
+
+	set, err := analysis.Load(os.DirFS(*dir), *ext)
+	if err != nil {
+		return err
+	}
+
+	src, err := generateRenderers(set, *pkg)
+	if err != nil {
+		return err
+	}
+
+	if *out == "" {
+		_, err = stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(*out, src, 0o644)
+}
+
+// generateRenderers returns the formatted source of the render functions for the views of set. Localized and
+// experiment variants of a view (e.g. views/home.fr and views/home@b) are rendered through the view itself, so they
+// are left out.
+func generateRenderers(set *analysis.Set, pkg string) ([]byte, error) {
+	views := make([]string, 0, len(set.Views))
+	for view := range set.Views {
+		if !strings.ContainsAny(path.Base(view), ".@") {
+			views = append(views, view)
+		}
+	}
+	sort.Strings(views)
+
+	var b bytes.Buffer
+	b.WriteString("// Code generated by hyperview gen. DO NOT EDIT.\n\n")
+	b.WriteString("package " + pkg + "\n\n")
+	b.WriteString("import (\n\t\"net/http\"\n\n\t\"github.com/hypergopher/hyperview/response\"\n)\n\n")
+	b.WriteString("// Renderer renders responses, like *hyperview.HyperView.\n")
+	b.WriteString("type Renderer interface {\n\tRender(w http.ResponseWriter, r *http.Request, resp *response.Response)\n}\n")
+
+	generated := make(map[string]string, len(views))
+	for _, view := range views {
+		typeName := fixtureTypeName(view)
+		if other, ok := generated[typeName]; ok {
+			return nil, fmt.Errorf("views %s and %s both generate %s", other, view, typeName)
+		}
+		generated[typeName] = view
+
+		root, err := set.InferView(view)
+		if err != nil {
+			return nil, err
+		}
+		if err := writeRenderer(&b, view, typeName, root); err != nil {
+			return nil, err
+		}
+	}
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("error formatting generated code: %w", err)
+	}
+	return src, nil
+}
+
+// writeRenderer writes the data struct, response constructor and render function of a view. The top-level fields
+// of the data are map keys of the view data, so they are exported in the struct and mapped back to their key.
+func writeRenderer(b *bytes.Buffer, view, typeName string, root *analysis.Field) error {
+	name := strings.TrimPrefix(view, constants.ViewsDir+"/")
+	funcName := strings.TrimSuffix(typeName, "Data")
+
+	fields := root.SortedFields()
+	names := make(map[string]string, len(fields))
+	for _, field := range fields {
+		if other, ok := names[exportedName(field.Name)]; ok {
+			return fmt.Errorf("%s: fields %s and %s both generate %s", view, other, field.Name, exportedName(field.Name))
+		}
+		names[exportedName(field.Name)] = field.Name
+		if err := checkExported(view, field.Name, field); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(b, "\n// %s is the data of the %s view.\n", typeName, name)
+	fmt.Fprintf(b, "type %s struct {\n", typeName)
+	for _, field := range fields {
+		fmt.Fprintf(b, "%s %s\n", exportedName(field.Name), field.GoType())
+	}
+	b.WriteString("}\n")
+
+	fmt.Fprintf(b, "\n// %sResponse returns a response rendering the %s view with data.\n", funcName, name)
+	fmt.Fprintf(b, "func %sResponse(data %s) *response.Response {\n", funcName, typeName)
+	fmt.Fprintf(b, "return response.NewResponse().Path(%s).Data(map[string]any{\n", strconv.Quote(name))
+	for _, field := range fields {
+		fmt.Fprintf(b, "%s: data.%s,\n", strconv.Quote(field.Name), exportedName(field.Name))
+	}
+	b.WriteString("})\n}\n")
+
+	fmt.Fprintf(b, "\n// Render%s renders the %s view with data.\n", funcName, name)
+	fmt.Fprintf(b, "func Render%s(hv Renderer, w http.ResponseWriter, r *http.Request, data %s) {\n", funcName, typeName)
+	fmt.Fprintf(b, "hv.Render(w, r, %sResponse(data))\n}\n", funcName)
+
+	return nil
+}
+
+// checkExported returns an error if a field nested in f is not exported, as templates could not access it on the
+// generated struct.
+func checkExported(view, fieldPath string, f *analysis.Field) error {
+	if f.IsList() {
+		return checkExported(view, fieldPath+"[]", f.Elem)
+	}
+	for _, field := range f.SortedFields() {
+		nested := fieldPath + "." + field.Name
+		if !isExported(field.Name) {
+			return fmt.Errorf("%s: field %s is not exported, so it cannot be generated as a struct field", view, nested)
+		}
+		if err := checkExported(view, nested, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isExported reports whether name is an exported Go identifier.
+func isExported(name string) bool {
+	return name != "" && unicode.IsUpper([]rune(name)[0])
+}
+
+// exportedName returns name with its first letter in upper case, e.g. user -> User.
+func exportedName(name string) string {
+	runes := []rune(name)
+	return string(unicode.ToUpper(runes[0])) + string(runes[1:])
+}
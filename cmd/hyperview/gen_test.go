@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunGen(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"views/users/show.html":    `{{.User.Name}}{{range .user_roles}}{{.Title}}{{end}}{{if .Admin}}admin{{end}}`,
+		"views/users/show.fr.html": `{{.User.Name}}`,
+		"views/home.html":          `home`,
+	}
+	for name, src := range files {
+		if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out := filepath.Join(dir, "views_gen.go")
+	var stdout bytes.Buffer
+	if err := run([]string{"gen", "-dir", dir, "-pkg", "pages", "-out", out}, &stdout); err != nil {
+		t.Fatalf("error running gen: %v", err)
+	}
+
+	src, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(src)
+	for _, want := range []string{
+		"// Code generated by hyperview gen. DO NOT EDIT.",
+		"package pages",
+		"type HomeData struct {\n}",
+		"type UsersShowData struct {\n\tAdmin bool\n\tUser  struct {\n\t\tName any\n\t}\n\tUser_roles []struct {\n\t\tTitle any\n\t}\n}",
+		`return response.NewResponse().Path("users/show").Data(map[string]any{`,
+		`"user_roles": data.User_roles,`,
+		"func RenderUsersShow(hv Renderer, w http.ResponseWriter, r *http.Request, data UsersShowData) {",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected generated code to contain %q, got:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "UsersShowFr") {
+		t.Errorf("expected localized variants to be left out, got:\n%s", got)
+	}
+}
+
+func TestRunGen_UnexportedNestedField(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "views"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "views", "home.html"), []byte(`{{.User.name}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout bytes.Buffer
+	err := run([]string{"gen", "-dir", dir}, &stdout)
+	if err == nil || !strings.Contains(err.Error(), "field User.name is not exported") {
+		t.Errorf("expected an error for the unexported field, got %v", err)
+	}
+}
+
+// genProgram renders the stats view through the generated code, with data of the types handlers would pass.
+const genProgram = `package main
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+)
+
+func main() {
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: os.DirFS("templates")},
+	})
+	if err := adapter.Init(); err != nil {
+		log.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	RenderStats(adapter, w, httptest.NewRequest(http.MethodGet, "/", nil), StatsData{
+		Count:  3,
+		Meta:   map[string]string{"title": "Stats"},
+		Ratio:  0.25,
+		Score:  4.5,
+		Status: "open",
+		Tags:   []string{"go", "html"},
+		User:   struct{ Name any }{Name: "Ada"},
+	})
+	fmt.Print(w.Body.String())
+}
+`
+
+func TestRunGen_Execute(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping building the generated code in short mode")
+	}
+	goCmd, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("skipping building the generated code without the go command")
+	}
+
+	// The program is built in the module, so it uses this version of hyperview; go ignores directories starting
+	// with an underscore in patterns such as ./...
+	dir, err := os.MkdirTemp(".", "_gen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	files := map[string]string{
+		"templates/layouts/base.html": `{{define "layout:base"}}{{template "page:main" .}}{{end}}`,
+		"templates/views/stats.html": `<!-- layout: base -->{{define "page:main"}}{{index .Meta "title"}}|{{len .Tags}}|` +
+			`{{if gt .Count 0}}{{.Count}}{{end}}|{{if lt .Ratio 0.5}}low{{end}}|{{if eq .Status "open"}}open{{end}}|` +
+			`{{.User.Name}}|{{printf "%.1f" .Score}}{{end}}`,
+		"main.go": genProgram,
+	}
+	for name, src := range files {
+		if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var stdout bytes.Buffer
+	out := filepath.Join(dir, "views_gen.go")
+	if err := run([]string{"gen", "-dir", filepath.Join(dir, "templates"), "-pkg", "main", "-out", out}, &stdout); err != nil {
+		t.Fatalf("error running gen: %v", err)
+	}
+
+	cmd := exec.Command(goCmd, "run", ".")
+	cmd.Dir = dir
+	got, err := cmd.CombinedOutput()
+	if err != nil {
+		src, _ := os.ReadFile(out)
+		t.Fatalf("error running the generated code: %v\n%s\ngenerated code:\n%s", err, got, src)
+	}
+	if want := "Stats|2|3|low|open|Ada|4.5"; string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/hypergopher/hyperview/analysis"
+)
+
+// runLint checks the templates of a directory against the lint rules. It fails if any issue has the severity given
+// by -fail-on or above.
+func runLint(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("lint", flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	dir := flags.String("dir", ".", "directory containing the layouts, partials and views directories")
+	ext := flags.String("ext", ".html", "template file extension")
+	maxRangeDepth := flags.Int("max-range-depth", 2, "maximum nesting depth of ranges")
+	maxLines := flags.Int("max-lines", 500, "maximum number of lines of a template file")
+	severities := flags.String("severity", "", "comma-separated rule=severity overrides, e.g. include-in-loop=off,nested-range=error")
+	failOn := flags.String("fail-on", "error", "minimum severity that fails the command: info, warning or error")
+	flags.Usage = func() {
+		fmt.Fprintln(stdout, "Usage: hyperview lint [flags]")
+		fmt.Fprintln(stdout)
+		fmt.Fprintln(stdout, "Rules:")
+		for _, rule := range []analysis.Rule{analysis.RuleNestedRange, analysis.RuleIncludeInLoop, analysis.RulePrintfInLoop, analysis.RuleLargeTemplate} {
+			fmt.Fprintf(stdout, "  %-16s  default severity %s\n", rule, analysis.Rules[rule])
+		}
+		fmt.Fprintln(stdout)
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	overrides, err := parseSeverities(*severities, analysis.Rules)
+	if err != nil {
+		return err
+	}
+	opts := analysis.LintOptions{
+		MaxRangeDepth: *maxRangeDepth,
+		MaxLines:      *maxLines,
+		Severities:    overrides,
+	}
+
+	threshold, err := analysis.ParseSeverity(*failOn)
+	if err != nil {
+		return err
+	}
+
+	set, err := analysis.Load(os.DirFS(*dir), *ext)
+	if err != nil {
+		return err
+	}
+
+	return printIssues(stdout, set.Lint(opts), threshold)
+}
+
+// parseSeverities parses comma-separated rule=severity overrides of the rules.
+func parseSeverities(s string, rules map[analysis.Rule]analysis.Severity) (map[analysis.Rule]analysis.Severity, error) {
+	overrides := make(map[analysis.Rule]analysis.Severity)
+	if s == "" {
+		return overrides, nil
+	}
+
+	for _, override := range strings.Split(s, ",") {
+		rule, name, ok := strings.Cut(strings.TrimSpace(override), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid severity override %q, expected rule=severity", override)
+		}
+		if _, ok := rules[analysis.Rule(rule)]; !ok {
+			return nil, fmt.Errorf("unknown rule %q", rule)
+		}
+		severity, err := analysis.ParseSeverity(name)
+		if err != nil {
+			return nil, err
+		}
+		overrides[analysis.Rule(rule)] = severity
+	}
+	return overrides, nil
+}
+
+// printIssues prints the issues, and fails if any has the threshold severity or above.
+func printIssues(stdout io.Writer, issues []analysis.Issue, threshold analysis.Severity) error {
+	failed := 0
+	for _, issue := range issues {
+		fmt.Fprintln(stdout, issue)
+		if threshold != analysis.SeverityOff && issue.Severity >= threshold {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d issue(s) with severity %s or above", failed, threshold)
+	}
+
+	return nil
+}
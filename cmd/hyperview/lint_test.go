@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunLint(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "views"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "views", "home.html"), []byte(`{{range .Items}}{{printf "%v" .}}{{end}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := run([]string{"lint", "-dir", dir}, &out); err != nil {
+		t.Fatalf("expected warnings not to fail by default: %v", err)
+	}
+	if !strings.Contains(out.String(), "views/home.html:1: warning: printf is called inside a range") {
+		t.Errorf("unexpected output: %s", out.String())
+	}
+
+	if err := run([]string{"lint", "-dir", dir, "-fail-on", "warning"}, &out); err == nil {
+		t.Error("expected an error with -fail-on warning")
+	}
+
+	out.Reset()
+	if err := run([]string{"lint", "-dir", dir, "-severity", "printf-in-loop=off", "-fail-on", "info"}, &out); err != nil || out.Len() != 0 {
+		t.Errorf("expected no issues with the rule disabled, got %v: %s", err, out.String())
+	}
+
+	if err := run([]string{"lint", "-dir", dir, "-severity", "unknown=error"}, &out); err == nil {
+		t.Error("expected an error for an unknown rule")
+	}
+}
@@ -0,0 +1,68 @@
+// Command hyperview provides development tools for HyperView templates.
+//
+// Usage:
+//
+//	hyperview <command> [flags] [arguments]
+//
+// The commands are:
+//
+//	audit       check templates for patterns prone to XSS
+//	docs        generate a static documentation site of the templates
+//	fixtures    generate a test fixture skeleton of the data used by a view
+//	gen         generate typed render functions for the views
+//	lint        check templates for render performance smells
+//	search      find the templates defining, calling or accessing a name
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(args []string, stdout io.Writer) error
+}
+
+var commands = []command{
+	{name: "audit", summary: "check templates for patterns prone to XSS", run: runAudit},
+	{name: "docs", summary: "generate a static documentation site of the templates", run: runDocs},
+	{name: "fixtures", summary: "generate a test fixture skeleton of the data used by a view", run: runFixtures},
+	{name: "gen", summary: "generate typed render functions for the views", run: runGen},
+	{name: "lint", summary: "check templates for render performance smells", run: runLint},
+	{name: "search", summary: "find the templates defining, calling or accessing a name", run: runSearch},
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "hyperview:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout io.Writer) error {
+	if len(args) == 0 {
+		usage(stdout)
+		return fmt.Errorf("no command given")
+	}
+
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return cmd.run(args[1:], stdout)
+		}
+	}
+
+	usage(stdout)
+	return fmt.Errorf("unknown command %q", args[0])
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: hyperview <command> [flags] [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-10s  %s\n", cmd.name, cmd.summary)
+	}
+}
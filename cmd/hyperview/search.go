@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hypergopher/hyperview/analysis"
+)
+
+// runSearch searches the templates of a directory for definitions, template calls, function calls, field accesses
+// or text.
+func runSearch(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("search", flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	dir := flags.String("dir", ".", "directory containing the layouts, partials and views directories")
+	ext := flags.String("ext", ".html", "template file extension")
+	asJSON := flags.Bool("json", false, "print the references as JSON, for editors")
+	queries := map[analysis.RefKind]*string{
+		analysis.RefField:    flags.String("field", "", "find field accesses ending with the path, e.g. .Price or Product.Price"),
+		analysis.RefFunc:     flags.String("func", "", "find calls of the function, e.g. formatDate"),
+		analysis.RefTemplate: flags.String("template", "", "find calls of the template or component, e.g. @card"),
+		analysis.RefDefine:   flags.String("define", "", "find definitions of the template"),
+		analysis.RefText:     flags.String("text", "", "find text content containing the string, ignoring case"),
+	}
+	flags.Usage = func() {
+		fmt.Fprintln(stdout, "Usage: hyperview search [flags]")
+		fmt.Fprintln(stdout)
+		fmt.Fprintln(stdout, "Exactly one of -field, -func, -template, -define or -text is required.")
+		fmt.Fprintln(stdout)
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var kind analysis.RefKind
+	var name string
+	for k, q := range queries {
+		if *q == "" {
+			continue
+		}
+		if kind != "" {
+			return fmt.Errorf("only one of -field, -func, -template, -define or -text can be given")
+		}
+		kind, name = k, *q
+	}
+	if kind == "" {
+		flags.Usage()
+		return fmt.Errorf("no query given")
+	}
+
+	set, err := analysis.Load(os.DirFS(*dir), *ext)
+	if err != nil {
+		return err
+	}
+
+	refs := set.Index().Search(kind, name)
+	if *asJSON {
+		if refs == nil {
+			refs = []analysis.Reference{}
+		}
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(refs)
+	}
+
+	for _, ref := range refs {
+		fmt.Fprintf(stdout, "%s:%d: %s %s (in %s)\n", ref.Path, ref.Line, ref.Kind, ref.Name, ref.Template)
+	}
+	return nil
+}
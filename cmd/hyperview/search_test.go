@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunSearch(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "views"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "views", "home.html"), []byte("{{define \"page:main\"}}\n{{formatDate .Post.Date}}{{end}}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := run([]string{"search", "-dir", dir, "-func", "formatDate"}, &out); err != nil {
+		t.Fatalf("error searching: %v", err)
+	}
+	if want := "views/home.html:2: func formatDate (in page:main)\n"; out.String() != want {
+		t.Errorf("unexpected output:\ngot  %q\nwant %q", out.String(), want)
+	}
+
+	out.Reset()
+	if err := run([]string{"search", "-dir", dir, "-json", "-field", "Date"}, &out); err != nil {
+		t.Fatalf("error searching: %v", err)
+	}
+	var refs []map[string]any
+	if err := json.Unmarshal(out.Bytes(), &refs); err != nil || len(refs) != 1 || refs[0]["name"] != ".Post.Date" {
+		t.Errorf("unexpected JSON output %s: %v", out.String(), err)
+	}
+
+	if err := run([]string{"search", "-dir", dir}, &out); err == nil {
+		t.Error("expected an error without a query")
+	}
+	if err := run([]string{"search", "-dir", dir, "-func", "a", "-text", "b"}, &out); err == nil {
+		t.Error("expected an error with several queries")
+	}
+}
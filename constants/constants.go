@@ -3,13 +3,18 @@ package constants
 type ContextKey string
 
 const (
-	NonceContextKey ContextKey = "HyperViewNonce"
+	NonceContextKey  ContextKey = "HyperViewNonce"
+	LocaleContextKey ContextKey = "HyperViewLocale"
+	TenantContextKey ContextKey = "HyperViewTenant"
 )
 
 const (
-	RootFSID    = "__ROOT__"
-	ViewsDir    = "views"
-	PartialsDir = "partials"
-	LayoutsDir  = "layouts"
-	SystemDir   = "system"
+	RootFSID        = "__ROOT__"
+	ViewsDir        = "views"
+	PartialsDir     = "partials"
+	LayoutsDir      = "layouts"
+	SystemDir       = "system"
+	LocalesDir      = "locales"
+	EnvironmentsDir = "environments"
+	StaticDir       = "static"
 )
@@ -0,0 +1,99 @@
+package hyperview
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/hypergopher/hyperview/response"
+)
+
+type rendererKey struct{}
+
+// contextRenderer is the renderer stored in a request context, along with the request it was stored for.
+type contextRenderer struct {
+	hv *HyperView
+	r  *http.Request
+}
+
+// Middleware stores the view service and the request in the request context, so handlers and libraries deep in the
+// call stack can render with the package-level Render functions without the view service being passed to them.
+func (s *HyperView) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		entry := &contextRenderer{hv: s}
+		r = r.WithContext(context.WithValue(r.Context(), rendererKey{}, entry))
+		entry.r = r
+		next.ServeHTTP(w, r)
+	})
+}
+
+// FromContext returns the view service stored in ctx by HyperView.Middleware, if any.
+func FromContext(ctx context.Context) (*HyperView, bool) {
+	entry, ok := ctx.Value(rendererKey{}).(*contextRenderer)
+	if !ok {
+		return nil, false
+	}
+	return entry.hv, true
+}
+
+// requestFromContext returns the view service and the request stored in ctx, with ctx as the context of the request
+// so values added to ctx since, such as the locale, are used. Without a view service in ctx, it answers with a 500
+// error and returns false.
+func requestFromContext(ctx context.Context, w http.ResponseWriter) (*HyperView, *http.Request, bool) {
+	entry, ok := ctx.Value(rendererKey{}).(*contextRenderer)
+	if !ok {
+		http.Error(w, "hyperview: no renderer in the request context", http.StatusInternalServerError)
+		return nil, nil, false
+	}
+	return entry.hv, entry.r.WithContext(ctx), true
+}
+
+// Render renders the named view with data, using the view service stored in ctx by HyperView.Middleware. The name is
+// a view path, as given to Response.Path, and can have an extension to render with another adapter, e.g.
+// "invoices/show.pdf".
+func Render(ctx context.Context, w http.ResponseWriter, name string, data map[string]any) {
+	RenderResponse(ctx, w, response.NewResponse().Path(name).Data(data))
+}
+
+// RenderResponse renders the response, using the view service stored in ctx by HyperView.Middleware.
+func RenderResponse(ctx context.Context, w http.ResponseWriter, resp *response.Response) {
+	if hv, r, ok := requestFromContext(ctx, w); ok {
+		hv.Render(w, r, resp)
+	}
+}
+
+// Redirect sends a redirect response, an HX-Redirect header to HTMX requests, using the view service stored in ctx by
+// HyperView.Middleware.
+func Redirect(ctx context.Context, w http.ResponseWriter, url string) {
+	if hv, r, ok := requestFromContext(ctx, w); ok {
+		hv.Redirect(w, r, url)
+	}
+}
+
+// RenderNotFound renders the 404 not found page, using the view service stored in ctx by HyperView.Middleware.
+func RenderNotFound(ctx context.Context, w http.ResponseWriter) {
+	if hv, r, ok := requestFromContext(ctx, w); ok {
+		hv.RenderNotFound(w, r)
+	}
+}
+
+// RenderForbidden renders the 403 forbidden page, using the view service stored in ctx by HyperView.Middleware.
+func RenderForbidden(ctx context.Context, w http.ResponseWriter) {
+	if hv, r, ok := requestFromContext(ctx, w); ok {
+		hv.RenderForbidden(w, r)
+	}
+}
+
+// RenderUnauthorized renders the 401 unauthorized page, using the view service stored in ctx by HyperView.Middleware.
+func RenderUnauthorized(ctx context.Context, w http.ResponseWriter) {
+	if hv, r, ok := requestFromContext(ctx, w); ok {
+		hv.RenderUnauthorized(w, r)
+	}
+}
+
+// RenderSystemError logs err and renders the 500 system error page, using the view service stored in ctx by
+// HyperView.Middleware.
+func RenderSystemError(ctx context.Context, w http.ResponseWriter, err error) {
+	if hv, r, ok := requestFromContext(ctx, w); ok {
+		hv.RenderSystemError(w, r, err)
+	}
+}
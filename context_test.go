@@ -0,0 +1,74 @@
+package hyperview_test
+
+import (
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+)
+
+func TestMiddleware(t *testing.T) {
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{
+			"layouts/base.html":   {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+			"views/greeting.html": {Data: []byte(`{{define "page:main"}}Hello, {{.Name}}{{end}}`)},
+		}},
+	})
+	hv, err := hyperview.NewHyperView(hyperview.WithViewAdapter("html", adapter))
+	if err != nil {
+		t.Fatalf("error creating HyperView: %v", err)
+	}
+
+	// A handler that only has the request context, as in a library
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if got, ok := hyperview.FromContext(r.Context()); !ok || got != hv {
+			t.Error("FromContext() did not return the view service")
+		}
+
+		switch r.URL.Path {
+		case "/greeting":
+			hyperview.Render(r.Context(), w, "greeting", map[string]any{"Name": "Ada"})
+		case "/old":
+			hyperview.Redirect(r.Context(), w, "/greeting")
+		default:
+			hyperview.RenderNotFound(r.Context(), w)
+		}
+	}
+	server := hv.Middleware(http.HandlerFunc(handler))
+
+	tests := []struct {
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{"/greeting", http.StatusOK, "Hello, Ada"},
+		{"/old", http.StatusFound, ""},
+		{"/missing", http.StatusNotFound, "Not Found\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("unexpected status: got %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Errorf("unexpected body: got %q, want %q", w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestRender_WithoutMiddleware(t *testing.T) {
+	w := httptest.NewRecorder()
+	hyperview.Render(httptest.NewRequest(http.MethodGet, "/", nil).Context(), w, "greeting", nil)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("unexpected status: got %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,41 @@
+// Package hyperbrotli compresses the bodies rendered by HyperView templates with Brotli, in a module of its own so
+// applications not using it do not depend on a Brotli library.
+//
+// Encoders returns the Brotli encoders for the Encoders option of the compression of the template adapter:
+//
+//	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+//		Compression: hyperview.CompressionOptions{
+//			Encodings: []string{hyperview.EncodingBrotli, hyperview.EncodingGzip},
+//			Encoders:  hyperbrotli.Encoders(hyperbrotli.DefaultCompression),
+//		},
+//	})
+package hyperbrotli
+
+import (
+	"github.com/andybalholm/brotli"
+
+	"github.com/hypergopher/hyperview"
+)
+
+const (
+	// BestSpeed is the fastest Brotli compression level.
+	BestSpeed = brotli.BestSpeed
+	// BestCompression is the Brotli compression level producing the smallest bodies.
+	BestCompression = brotli.BestCompression
+	// DefaultCompression is the Brotli compression level balancing speed and size.
+	DefaultCompression = brotli.DefaultCompression
+)
+
+// Encoder returns a function creating the Brotli encoders of the compression level, from BestSpeed to
+// BestCompression.
+func Encoder(level int) func() hyperview.Encoder {
+	return func() hyperview.Encoder {
+		return brotli.NewWriterLevel(nil, level)
+	}
+}
+
+// Encoders returns the Encoders option of the compression of the template adapter with the Brotli encoders of the
+// compression level, under EncodingBrotli.
+func Encoders(level int) map[string]func() hyperview.Encoder {
+	return map[string]func() hyperview.Encoder{hyperview.EncodingBrotli: Encoder(level)}
+}
@@ -0,0 +1,61 @@
+package hyperbrotli_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/contrib/brotli"
+	"github.com/hypergopher/hyperview/hyperviewtest"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func TestEncoders(t *testing.T) {
+	page := strings.Repeat("<p>compressible</p>", 100)
+	adapter := hyperviewtest.NewAdapter(t, map[string]string{
+		"layouts/base.html": `{{define "layout:base"}}{{template "page:main" .}}{{end}}`,
+		"views/home.html":   `{{define "page:main"}}` + page + `{{end}}`,
+	}, hyperview.TemplateViewAdapterOptions{
+		Compression: hyperview.CompressionOptions{
+			Encodings: []string{hyperview.EncodingBrotli, hyperview.EncodingGzip},
+			Encoders:  hyperbrotli.Encoders(hyperbrotli.BestSpeed),
+		},
+	})
+
+	tests := []struct {
+		name         string
+		accept       string
+		wantEncoding string
+	}{
+		{"brotli preferred", "gzip, br", hyperview.EncodingBrotli},
+		{"gzip", "gzip, br;q=0", hyperview.EncodingGzip},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Accept-Encoding", tt.accept)
+			w := httptest.NewRecorder()
+			adapter.Render(w, r, response.NewResponse().Layout("base").Path("home"))
+
+			if got := w.Header().Get("Content-Encoding"); got != tt.wantEncoding {
+				t.Fatalf("expected encoding %q, got %q", tt.wantEncoding, got)
+			}
+			if tt.wantEncoding != hyperview.EncodingBrotli {
+				return
+			}
+			body, err := io.ReadAll(brotli.NewReader(w.Body))
+			if err != nil {
+				t.Fatalf("error decoding body: %v", err)
+			}
+			if string(body) != page {
+				t.Errorf("expected body %q, got %q", page, body)
+			}
+		})
+	}
+}
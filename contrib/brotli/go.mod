@@ -0,0 +1,12 @@
+module github.com/hypergopher/hyperview/contrib/brotli
+
+go 1.23.0
+
+replace github.com/hypergopher/hyperview => ../..
+
+require (
+	github.com/andybalholm/brotli v1.0.5
+	github.com/hypergopher/hyperview v0.0.0-00010101000000-000000000000
+)
+
+require golang.org/x/net v0.38.0 // indirect
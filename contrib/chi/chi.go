@@ -0,0 +1,31 @@
+// Package hyperchi integrates HyperView with the chi router.
+//
+// chi handlers are net/http handlers, so they render with the view service as usual. Use installs the middleware
+// storing the view service in the request context, for the package-level render functions of hyperview, and renders
+// the not found and method not allowed pages of the view service:
+//
+//	r := chi.NewRouter()
+//	hyperchi.Use(r, hv)
+//	r.Get("/posts/{slug}", func(w http.ResponseWriter, r *http.Request) {
+//		hyperview.Render(r.Context(), w, "posts/show", map[string]any{"Slug": chi.URLParam(r, "slug")})
+//	})
+package hyperchi
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/hypergopher/hyperview"
+)
+
+// Use adds the middleware of the view service to the router, and renders its not found and method not allowed pages
+// for the requests the router does not handle. It must be called before the routes are defined, like chi's Use.
+func Use(r chi.Router, hv *hyperview.HyperView) {
+	r.Use(hv.Middleware)
+	r.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		hv.RenderNotFound(w, r)
+	})
+	r.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		hv.RenderMethodNotAllowed(w, r)
+	})
+}
@@ -0,0 +1,55 @@
+package hyperchi_test
+
+import (
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	hyperchi "github.com/hypergopher/hyperview/contrib/chi"
+)
+
+func TestUse(t *testing.T) {
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{
+			"layouts/base.html":     {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+			"views/post.html":       {Data: []byte(`{{define "page:main"}}Post {{.Slug}}{{end}}`)},
+			"views/system/404.html": {Data: []byte(`{{define "page:main"}}Nothing here{{end}}`)},
+		}},
+	})
+	hv, err := hyperview.NewHyperView(hyperview.WithViewAdapter("html", adapter))
+	if err != nil {
+		t.Fatalf("error creating HyperView: %v", err)
+	}
+
+	r := chi.NewRouter()
+	hyperchi.Use(r, hv)
+	r.Get("/posts/{slug}", func(w http.ResponseWriter, r *http.Request) {
+		hyperview.Render(r.Context(), w, "post", map[string]any{"Slug": chi.URLParam(r, "slug")})
+	})
+
+	tests := []struct {
+		method     string
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{http.MethodGet, "/posts/hello", http.StatusOK, "Post hello"},
+		{http.MethodGet, "/missing", http.StatusNotFound, "Nothing here"},
+		{http.MethodPost, "/posts/hello", http.StatusMethodNotAllowed, "Method Not Allowed\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if w.Code != tt.wantStatus || w.Body.String() != tt.wantBody {
+				t.Errorf("got %d %q, want %d %q", w.Code, w.Body.String(), tt.wantStatus, tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,12 @@
+module github.com/hypergopher/hyperview/contrib/chi
+
+go 1.23.0
+
+replace github.com/hypergopher/hyperview => ../..
+
+require (
+	github.com/go-chi/chi/v5 v5.3.2
+	github.com/hypergopher/hyperview v0.0.0-00010101000000-000000000000
+)
+
+require golang.org/x/net v0.38.0 // indirect
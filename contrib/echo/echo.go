@@ -0,0 +1,42 @@
+// Package hyperecho integrates HyperView with the echo framework, as an echo.Renderer:
+//
+//	e := echo.New()
+//	e.Renderer = hyperecho.NewRenderer(hv)
+//	e.Use(hyperecho.Middleware(hv))
+//	e.GET("/posts", func(c echo.Context) error {
+//		return c.Render(http.StatusOK, "posts/index", echo.Map{"Posts": posts})
+//	})
+//
+// The name given to c.Render is a view path, and the data can be a map or a *response.Response, e.g. to set the layout
+// or title. The status code is the one given to c.Render.
+package hyperecho
+
+import (
+	"io"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/response"
+	"github.com/labstack/echo/v4"
+)
+
+// Renderer is an echo.Renderer rendering views with a HyperView renderer.
+type Renderer struct {
+	renderer response.Renderer
+}
+
+// NewRenderer creates a new Renderer rendering with renderer, such as a HyperView or a TemplateAdapter.
+func NewRenderer(renderer response.Renderer) *Renderer {
+	return &Renderer{renderer: renderer}
+}
+
+// Render renders the view name with data to w, with the request of c. The headers set by the render, such as HTMX
+// triggers, are added to the response of c.
+func (e *Renderer) Render(w io.Writer, name string, data any, c echo.Context) error {
+	return hyperview.RenderTo(w, c.Response().Header(), c.Request(), e.renderer, response.ForView(name, data))
+}
+
+// Middleware returns the middleware of the view service, storing it in the request context for the package-level
+// render functions of hyperview.
+func Middleware(hv *hyperview.HyperView) echo.MiddlewareFunc {
+	return echo.WrapMiddleware(hv.Middleware)
+}
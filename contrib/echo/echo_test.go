@@ -0,0 +1,70 @@
+package hyperecho_test
+
+import (
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	hyperecho "github.com/hypergopher/hyperview/contrib/echo"
+	"github.com/hypergopher/hyperview/response"
+	"github.com/labstack/echo/v4"
+)
+
+func TestRenderer(t *testing.T) {
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{
+			"layouts/base.html":  {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+			"layouts/admin.html": {Data: []byte(`{{define "layout:admin"}}Admin: {{template "page:main" .}}{{end}}`)},
+			"views/posts.html":   {Data: []byte(`{{define "page:main"}}{{.View.Title}} {{.Count}}{{end}}`)},
+		}},
+	})
+	hv, err := hyperview.NewHyperView(hyperview.WithViewAdapter("html", adapter))
+	if err != nil {
+		t.Fatalf("error creating HyperView: %v", err)
+	}
+
+	e := echo.New()
+	e.Renderer = hyperecho.NewRenderer(hv)
+	e.Use(hyperecho.Middleware(hv))
+	e.GET("/posts", func(c echo.Context) error {
+		return c.Render(http.StatusOK, "posts", echo.Map{"Count": 3})
+	})
+	e.GET("/admin/posts", func(c echo.Context) error {
+		resp := response.NewResponse().Layout("admin").Title("Posts").Header("HX-Trigger", "loaded").AddDataItem("Count", 4)
+		return c.Render(http.StatusAccepted, "posts", resp)
+	})
+	e.GET("/missing", func(c echo.Context) error {
+		return c.Render(http.StatusOK, "missing", nil)
+	})
+
+	tests := []struct {
+		path        string
+		wantStatus  int
+		wantBody    string
+		wantTrigger string
+	}{
+		{"/posts", http.StatusOK, " 3", ""},
+		{"/admin/posts", http.StatusAccepted, "Admin: Posts 4", "loaded"},
+		{"/missing", http.StatusInternalServerError, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("unexpected status: got %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Errorf("unexpected body: got %q, want %q", w.Body.String(), tt.wantBody)
+			}
+			if got := w.Header().Get("HX-Trigger"); got != tt.wantTrigger {
+				t.Errorf("unexpected HX-Trigger header: got %q, want %q", got, tt.wantTrigger)
+			}
+		})
+	}
+}
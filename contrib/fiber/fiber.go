@@ -0,0 +1,51 @@
+// Package hyperfiber integrates HyperView with the fiber framework, as a fiber views engine:
+//
+//	app := fiber.New(fiber.Config{Views: hyperfiber.New(hv)})
+//	app.Get("/posts", func(c *fiber.Ctx) error {
+//		return c.Render("posts/index", fiber.Map{"Posts": posts}, "dashboard")
+//	})
+//
+// The name is a view path, the data can be a map or a *response.Response, and the optional layout is the layout of
+// the view, instead of the default layout.
+//
+// fiber is not based on net/http, so views are rendered without the request context, such as the locale or the CSP
+// nonce, and the headers set by the render, such as HTMX triggers, are not sent.
+package hyperfiber
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/response"
+)
+
+// Engine is a fiber views engine rendering views with a HyperView renderer.
+type Engine struct {
+	renderer response.Renderer
+}
+
+// New creates a new Engine rendering with renderer, such as a HyperView or a TemplateAdapter.
+func New(renderer response.Renderer) *Engine {
+	return &Engine{renderer: renderer}
+}
+
+// Load does nothing, as the templates are loaded by the adapters of the renderer.
+func (e *Engine) Load() error {
+	return nil
+}
+
+// Render renders the view name with data to w, in the given layout if there is one.
+func (e *Engine) Render(w io.Writer, name string, data any, layout ...string) error {
+	resp := response.ForView(name, data)
+	if len(layout) > 0 && layout[0] != "" {
+		resp.Layout(layout[0])
+	}
+
+	r, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "/", nil)
+	if err != nil {
+		return err
+	}
+	return hyperview.RenderTo(w, nil, r, e.renderer, resp)
+}
@@ -0,0 +1,58 @@
+package hyperfiber_test
+
+import (
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	hyperfiber "github.com/hypergopher/hyperview/contrib/fiber"
+)
+
+func TestEngine(t *testing.T) {
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{
+			"layouts/base.html":  {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+			"layouts/admin.html": {Data: []byte(`{{define "layout:admin"}}Admin: {{template "page:main" .}}{{end}}`)},
+			"views/posts.html":   {Data: []byte(`{{define "page:main"}}{{.Count}} posts{{end}}`)},
+		}},
+	})
+	hv, err := hyperview.NewHyperView(hyperview.WithViewAdapter("html", adapter))
+	if err != nil {
+		t.Fatalf("error creating HyperView: %v", err)
+	}
+
+	app := fiber.New(fiber.Config{Views: hyperfiber.New(hv)})
+	app.Get("/posts", func(c *fiber.Ctx) error {
+		return c.Render("posts", fiber.Map{"Count": 2})
+	})
+	app.Get("/admin/posts", func(c *fiber.Ctx) error {
+		return c.Render("posts", fiber.Map{"Count": 3}, "admin")
+	})
+
+	tests := []struct {
+		path     string
+		wantBody string
+	}{
+		{"/posts", "2 posts"},
+		{"/admin/posts", "Admin: 3 posts"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if err != nil {
+				t.Fatalf("error serving %s: %v", tt.path, err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+
+			if resp.StatusCode != http.StatusOK || string(body) != tt.wantBody {
+				t.Errorf("got %d %q, want 200 %q", resp.StatusCode, body, tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+// Package hypergin integrates HyperView with the gin framework, as a gin HTMLRender:
+//
+//	router := gin.New()
+//	router.HTMLRender = hypergin.New(hv)
+//	router.Use(hypergin.Middleware(hv))
+//	router.GET("/posts", func(c *gin.Context) {
+//		hypergin.Render(c, http.StatusOK, "posts/index", gin.H{"Posts": posts})
+//	})
+//
+// The name is a view path, and the data can be a map or a *response.Response, e.g. to set the layout or title. The
+// status code is the one given to the render.
+//
+// gin does not pass the request to its HTMLRender, so views rendered with c.HTML are rendered without the request
+// context, such as the locale or the CSP nonce. Render renders with the request of the gin context.
+package hypergin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/response"
+)
+
+var errNoRenderer = errors.New("hypergin: no renderer in the request context, add the Middleware")
+
+// HTMLRender is a gin HTMLRender rendering views with a HyperView renderer.
+type HTMLRender struct {
+	renderer response.Renderer
+}
+
+// New creates a new HTMLRender rendering with renderer, such as a HyperView or a TemplateAdapter.
+func New(renderer response.Renderer) *HTMLRender {
+	return &HTMLRender{renderer: renderer}
+}
+
+// Instance returns the render of the view name with data, without a request context (see Render).
+func (h *HTMLRender) Instance(name string, data any) render.Render {
+	r, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, "/", nil)
+	return &View{Renderer: h.renderer, Request: r, Response: response.ForView(name, data)}
+}
+
+// View is the gin render of a view.
+type View struct {
+	// Renderer renders the view.
+	Renderer response.Renderer
+	// Request is the request the view is rendered for.
+	Request *http.Request
+	// Response is the response rendering the view.
+	Response *response.Response
+}
+
+// Render renders the view to w, adding the headers set by the render, such as HTMX triggers.
+func (v *View) Render(w http.ResponseWriter) error {
+	v.WriteContentType(w)
+	return hyperview.RenderTo(w, w.Header(), v.Request, v.Renderer, v.Response)
+}
+
+// WriteContentType sets the Content-Type header of HTML pages.
+func (v *View) WriteContentType(w http.ResponseWriter) {
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	}
+}
+
+// Middleware returns the middleware of the view service, storing it in the request context for Render and the
+// package-level render functions of hyperview.
+func Middleware(hv *hyperview.HyperView) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		hv.Middleware(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			c.Request = r
+			c.Next()
+		})).ServeHTTP(c.Writer, c.Request)
+	}
+}
+
+// Render renders the view name with data and the request of c, with the view service stored in the request context
+// by Middleware. Without the middleware, it aborts with a 500 error.
+func Render(c *gin.Context, code int, name string, data any) {
+	hv, ok := hyperview.FromContext(c.Request.Context())
+	if !ok {
+		_ = c.AbortWithError(http.StatusInternalServerError, errNoRenderer)
+		return
+	}
+	c.Render(code, &View{Renderer: hv, Request: c.Request, Response: response.ForView(name, data)})
+}
@@ -0,0 +1,66 @@
+package hypergin_test
+
+import (
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	hypergin "github.com/hypergopher/hyperview/contrib/gin"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func TestHTMLRender(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{
+			"layouts/base.html": {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+			"views/posts.html":  {Data: []byte(`{{define "page:main"}}{{.View.RequestPath}} {{.Count}}{{end}}`)},
+		}},
+	})
+	hv, err := hyperview.NewHyperView(hyperview.WithViewAdapter("html", adapter))
+	if err != nil {
+		t.Fatalf("error creating HyperView: %v", err)
+	}
+
+	router := gin.New()
+	router.HTMLRender = hypergin.New(hv)
+	router.Use(hypergin.Middleware(hv))
+	router.GET("/html", func(c *gin.Context) {
+		c.HTML(http.StatusOK, "posts", gin.H{"Count": 1})
+	})
+	router.GET("/render", func(c *gin.Context) {
+		hypergin.Render(c, http.StatusCreated, "posts", response.NewResponse().AddDataItem("Count", 2).Header("HX-Trigger", "created"))
+	})
+
+	tests := []struct {
+		path        string
+		wantStatus  int
+		wantBody    string
+		wantTrigger string
+	}{
+		{"/html", http.StatusOK, "/ 1", ""},
+		{"/render", http.StatusCreated, "/render 2", "created"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if w.Code != tt.wantStatus || w.Body.String() != tt.wantBody {
+				t.Errorf("got %d %q, want %d %q", w.Code, w.Body.String(), tt.wantStatus, tt.wantBody)
+			}
+			if got := w.Header().Get("Content-Type"); got != "text/html; charset=utf-8" {
+				t.Errorf("unexpected Content-Type: %q", got)
+			}
+			if got := w.Header().Get("HX-Trigger"); got != tt.wantTrigger {
+				t.Errorf("unexpected HX-Trigger header: got %q, want %q", got, tt.wantTrigger)
+			}
+		})
+	}
+}
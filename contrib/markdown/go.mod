@@ -0,0 +1,12 @@
+module github.com/hypergopher/hyperview/contrib/markdown
+
+go 1.23.0
+
+replace github.com/hypergopher/hyperview => ../..
+
+require (
+	github.com/hypergopher/hyperview v0.0.0-00010101000000-000000000000
+	github.com/yuin/goldmark v1.8.6
+)
+
+require golang.org/x/net v0.38.0 // indirect
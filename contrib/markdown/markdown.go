@@ -0,0 +1,245 @@
+// Package hypermarkdown renders Markdown files in the layouts of HyperView templates, in a module of its own so
+// applications not using it do not depend on goldmark.
+package hypermarkdown
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/response"
+)
+
+// Adapter is an adapter rendering Markdown files from the views directories, such as docs and changelog
+// pages, in the layouts of a TemplateAdapter. Files can start with a front matter block of key: value lines, where
+// title sets the page title and layout the layout:
+//
+//	---
+//	title: Changelog
+//	layout: docs
+//	---
+//	# Changelog
+//
+// The other keys are available to the layouts as .Meta. Register the adapter under the "md" key, so paths with a .md
+// extension are rendered with it, e.g. hv.Render(w, r, response.NewResponse().Path("docs/intro.md")).
+type Adapter struct {
+	extension     string
+	fileSystemMap map[string]fs.FS
+	templates     *hyperview.TemplateAdapter
+	markdown      goldmark.Markdown
+	pages         map[string]markdownPage
+	mu            sync.RWMutex // protects pages, replaced by Init
+}
+
+// Options are the options for the Adapter.
+type Options struct {
+	// Extension is the file extension of the Markdown files. Default is ".md".
+	Extension string
+	// FileSystemMap is a map of file systems holding the Markdown files in their views directories.
+	FileSystemMap map[string]fs.FS
+	// Templates is the template adapter providing the layouts, partials and system pages. Required.
+	Templates *hyperview.TemplateAdapter
+	// Markdown is the Markdown converter. Default is goldmark with the GitHub Flavored Markdown extensions, which omits
+	// raw HTML.
+	Markdown goldmark.Markdown
+}
+
+// markdownPage is a Markdown file converted to HTML.
+type markdownPage struct {
+	html   template.HTML
+	title  string
+	layout string
+	meta   map[string]string
+}
+
+// NewAdapter creates a new Adapter.
+func NewAdapter(opts Options) *Adapter {
+	if opts.Extension == "" {
+		opts.Extension = ".md"
+	}
+	if opts.Markdown == nil {
+		opts.Markdown = goldmark.New(goldmark.WithExtensions(extension.GFM))
+	}
+
+	return &Adapter{
+		extension:     opts.Extension,
+		fileSystemMap: opts.FileSystemMap,
+		templates:     opts.Templates,
+		markdown:      opts.Markdown,
+		pages:         make(map[string]markdownPage),
+	}
+}
+
+// Init converts the Markdown files of the views directories to HTML.
+func (a *Adapter) Init() error {
+	if a.templates == nil {
+		return errors.New("markdown adapter: no template adapter to render the layouts with")
+	}
+
+	pages := make(map[string]markdownPage)
+	for fsID, fsys := range a.fileSystemMap {
+		if _, err := fs.Stat(fsys, constants.ViewsDir); err != nil {
+			continue
+		}
+
+		err := fs.WalkDir(fsys, constants.ViewsDir, func(filePath string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || path.Ext(filePath) != a.extension {
+				return err
+			}
+
+			src, err := fs.ReadFile(fsys, filePath)
+			if err != nil {
+				return err
+			}
+
+			page, err := a.convert(src)
+			if err != nil {
+				return fmt.Errorf("error converting %s: %w", filePath, err)
+			}
+
+			// Pages are named like the views of the template adapter, following its case policy
+			pages[a.templates.ViewName(fsID, filePath)] = page
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	a.mu.Lock()
+	a.pages = pages
+	a.mu.Unlock()
+	return nil
+}
+
+// page returns the converted Markdown file at the given path.
+func (a *Adapter) page(path string) (markdownPage, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	page, ok := a.pages[path]
+	return page, ok
+}
+
+// convert parses the front matter of a Markdown file and converts the rest to HTML.
+func (a *Adapter) convert(src []byte) (markdownPage, error) {
+	meta, body, err := parseFrontMatter(src)
+	if err != nil {
+		return markdownPage{}, err
+	}
+
+	var buf bytes.Buffer
+	if err := a.markdown.Convert(body, &buf); err != nil {
+		return markdownPage{}, err
+	}
+
+	page := markdownPage{html: template.HTML(buf.String()), title: meta["title"], layout: meta["layout"], meta: meta}
+	delete(meta, "title")
+	delete(meta, "layout")
+	return page, nil
+}
+
+// parseFrontMatter splits a front matter block of key: value lines, delimited by --- lines, from the Markdown body.
+// Values can be quoted. Sources without front matter are returned as is.
+func parseFrontMatter(src []byte) (map[string]string, []byte, error) {
+	meta := make(map[string]string)
+
+	rest, ok := bytes.CutPrefix(src, []byte("---\n"))
+	if !ok {
+		if rest, ok = bytes.CutPrefix(src, []byte("---\r\n")); !ok {
+			return meta, src, nil
+		}
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(rest))
+	consumed := len(src) - len(rest)
+	for scanner.Scan() {
+		line := scanner.Text()
+		consumed += len(line) + 1
+		line = strings.TrimSuffix(line, "\r")
+
+		if line == "---" {
+			if consumed > len(src) {
+				consumed = len(src)
+			}
+			return meta, src[consumed:], nil
+		}
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			return nil, nil, fmt.Errorf("invalid front matter line %q", line)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		meta[strings.TrimSpace(key)] = value
+	}
+
+	return nil, nil, errors.New("unterminated front matter")
+}
+
+// DeclaredLayout returns the layout set by the front matter of the Markdown file at the given path, if any.
+func (a *Adapter) DeclaredLayout(path string) (string, bool) {
+	page, ok := a.page(path)
+	if !ok || page.layout == "" {
+		return "", false
+	}
+	return page.layout, true
+}
+
+func (a *Adapter) Render(w http.ResponseWriter, r *http.Request, resp *response.Response) {
+	page, ok := a.page(a.templates.NormalizeName(resp.TemplatePath()))
+	if !ok {
+		http.Error(w, fmt.Sprintf("markdown view not found: %s", resp.TemplatePath()), http.StatusInternalServerError)
+		return
+	}
+
+	if resp.TemplateLayout() == "" && page.layout != "" {
+		resp.Layout(page.layout)
+	}
+	if resp.PageTitle() == "" && page.title != "" {
+		resp.Title(page.title)
+	}
+	resp.AddDataItem("Meta", page.meta)
+
+	a.templates.RenderContent(w, r, resp, page.html)
+}
+
+func (a *Adapter) RenderForbidden(w http.ResponseWriter, r *http.Request, resp *response.Response) {
+	a.templates.RenderForbidden(w, r, resp)
+}
+
+func (a *Adapter) RenderMaintenance(w http.ResponseWriter, r *http.Request, resp *response.Response) {
+	a.templates.RenderMaintenance(w, r, resp)
+}
+
+func (a *Adapter) RenderMethodNotAllowed(w http.ResponseWriter, r *http.Request, resp *response.Response) {
+	a.templates.RenderMethodNotAllowed(w, r, resp)
+}
+
+func (a *Adapter) RenderNotFound(w http.ResponseWriter, r *http.Request, resp *response.Response) {
+	a.templates.RenderNotFound(w, r, resp)
+}
+
+func (a *Adapter) RenderSystemError(w http.ResponseWriter, r *http.Request, err error, resp *response.Response) {
+	a.templates.RenderSystemError(w, r, err, resp)
+}
+
+func (a *Adapter) RenderUnauthorized(w http.ResponseWriter, r *http.Request, resp *response.Response) {
+	a.templates.RenderUnauthorized(w, r, resp)
+}
@@ -0,0 +1,112 @@
+package hypermarkdown_test
+
+import (
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/contrib/markdown"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func TestAdapter_Render(t *testing.T) {
+	templates := fstest.MapFS{
+		"layouts/base.html": {Data: []byte(`{{define "layout:base"}}<title>{{.View.Title}}</title>{{template "page:main" .}}{{end}}`)},
+		"layouts/docs.html": {Data: []byte(`{{define "layout:docs"}}<nav>{{.Meta.section}}</nav>{{template "page:main" .}}{{end}}`)},
+		"views/home.html":   {Data: []byte(`{{define "page:main"}}home{{end}}`)},
+	}
+	docs := fstest.MapFS{
+		"views/docs/intro.md":  {Data: []byte("---\ntitle: \"Introduction\"\nlayout: docs\nsection: guides\n---\n# Intro\n\nHello *world*\n")},
+		"views/changelog.md":   {Data: []byte("---\ntitle: Changelog\n---\n- fixed <script>\n")},
+		"views/plain.md":       {Data: []byte("Plain `code`\n")},
+		"views/broken.md.html": {Data: []byte("not markdown")},
+	}
+
+	templateAdapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: templates},
+	})
+	if err := templateAdapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+	markdownAdapter := hypermarkdown.NewAdapter(hypermarkdown.Options{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: docs},
+		Templates:     templateAdapter,
+	})
+	if err := markdownAdapter.Init(); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	hv, err := hyperview.NewHyperView()
+	if err != nil {
+		t.Fatalf("error creating HyperView: %v", err)
+	}
+	if err := hv.RegisterAdapter("html", templateAdapter); err != nil {
+		t.Fatalf("error registering adapter: %v", err)
+	}
+	if err := hv.RegisterAdapter("md", markdownAdapter); err != nil {
+		t.Fatalf("error registering adapter: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		resp       *response.Response
+		wantStatus int
+		want       string
+	}{
+		{"front matter layout and meta", response.NewResponse().Path("docs/intro.md"), http.StatusOK,
+			"<nav>guides</nav><h1>Intro</h1>\n<p>Hello <em>world</em></p>\n"},
+		{"front matter title", response.NewResponse().Path("changelog.md"), http.StatusOK,
+			"<title>Changelog</title><ul>\n<li>fixed <!-- raw HTML omitted --></li>\n</ul>\n"},
+		{"explicit title wins", response.NewResponse().Title("News").Path("changelog.md"), http.StatusOK,
+			"<title>News</title><ul>\n<li>fixed <!-- raw HTML omitted --></li>\n</ul>\n"},
+		{"explicit layout wins", response.NewResponse().Layout("base").Path("docs/intro.md"), http.StatusOK,
+			"<title>Introduction</title><h1>Intro</h1>\n<p>Hello <em>world</em></p>\n"},
+		{"no front matter", response.NewResponse().Path("plain.md"), http.StatusOK,
+			"<title></title><p>Plain <code>code</code></p>\n"},
+		{"templates still render", response.NewResponse().Path("home"), http.StatusOK, "<title></title>home"},
+		{"missing file", response.NewResponse().Path("missing.md"), http.StatusInternalServerError, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			hv.Render(w, httptest.NewRequest(http.MethodGet, "/", nil), tt.resp)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("unexpected status: got %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.want != "" && w.Body.String() != tt.want {
+				t.Errorf("unexpected body:\ngot  %q\nwant %q", w.Body.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestAdapter_FrontMatterErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		wantErr string
+	}{
+		{"unterminated", "---\ntitle: Docs\n# Docs\n", "unterminated front matter"},
+		{"invalid line", "---\ntitle Docs\n---\n", "invalid front matter line"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := hypermarkdown.NewAdapter(hypermarkdown.Options{
+				FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{"views/docs.md": {Data: []byte(tt.src)}}},
+				Templates:     hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{}),
+			})
+
+			err := adapter.Init()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Init() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,14 @@
+module github.com/hypergopher/hyperview/contrib/sanitize
+
+go 1.23.0
+
+require (
+	github.com/microcosm-cc/bluemonday v1.0.27
+	github.com/yuin/goldmark v1.8.6
+)
+
+require (
+	github.com/aymerick/douceur v0.2.0 // indirect
+	github.com/gorilla/css v1.0.1 // indirect
+	golang.org/x/net v0.38.0 // indirect
+)
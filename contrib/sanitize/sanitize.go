@@ -0,0 +1,172 @@
+// Package hypersanitize renders user-generated HTML and Markdown safely in templates, through named bluemonday
+// policies, in a module of its own so applications not using it do not depend on bluemonday and goldmark.
+//
+// Default returns a Sanitizer with the strict and ugc policies; applications with their own policies create one with
+// New. A Sanitizer implements the hyperview.Sanitizer interface, so the Sanitizer option of the adapter adds its
+// sanitize, markdownSafe and rawIfTrusted functions:
+//
+//	{{sanitize .Comment.Body}}           {{/* ugc policy */}}
+//	{{sanitize .Comment.Body "strict"}}  {{/* text only */}}
+//	{{markdownSafe .Post.Body}}
+//	{{rawIfTrusted .Page.Body}}          {{/* as is if TrustedHTML */}}
+package hypersanitize
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+const (
+	// Strict is the policy removing all elements, leaving the text.
+	Strict = "strict"
+	// UGC is the policy for user-generated content, allowing formatting, links, images and tables but no scripts,
+	// styles or forms.
+	UGC = "ugc"
+)
+
+// Options are the options of a Sanitizer.
+type Options struct {
+	// Policies are custom policies, keyed by name, added to the strict and ugc policies. Policies with those names
+	// replace them.
+	Policies map[string]*bluemonday.Policy
+	// Default is the name of the policy used when a template names none. Default is UGC.
+	Default string
+	// Markdown is the Markdown converter of markdownSafe. Default is goldmark with the GitHub Flavored Markdown
+	// extensions, keeping raw HTML for the policy to sanitize.
+	Markdown goldmark.Markdown
+}
+
+// Sanitizer sanitizes HTML with named policies. It is safe for concurrent use.
+type Sanitizer struct {
+	policies map[string]*bluemonday.Policy
+	fallback string
+	markdown goldmark.Markdown
+}
+
+// New creates a new Sanitizer.
+func New(opts Options) *Sanitizer {
+	policies := map[string]*bluemonday.Policy{
+		Strict: bluemonday.StrictPolicy(),
+		UGC:    bluemonday.UGCPolicy(),
+	}
+	for name, policy := range opts.Policies {
+		policies[name] = policy
+	}
+
+	if opts.Default == "" {
+		opts.Default = UGC
+	}
+	if opts.Markdown == nil {
+		opts.Markdown = goldmark.New(goldmark.WithExtensions(extension.GFM), goldmark.WithRendererOptions(html.WithUnsafe()))
+	}
+
+	return &Sanitizer{
+		policies: policies,
+		fallback: opts.Default,
+		markdown: opts.Markdown,
+	}
+}
+
+// defaultSanitizer is the Sanitizer returned by Default.
+var defaultSanitizer = New(Options{})
+
+// Default returns a Sanitizer with the strict and ugc policies, the ugc policy by default.
+func Default() *Sanitizer {
+	return defaultSanitizer
+}
+
+// Funcs returns the sanitize, markdownSafe and rawIfTrusted template functions of the sanitizer, for template sets
+// not rendered by an adapter.
+func (s *Sanitizer) Funcs() template.FuncMap {
+	return template.FuncMap{
+		"sanitize":     s.Sanitize,
+		"markdownSafe": s.MarkdownSafe,
+		"rawIfTrusted": s.RawIfTrusted,
+	}
+}
+
+// TrustedHTML is HTML the application vouches for, such as pages edited by administrators, which rawIfTrusted renders
+// as is. Converting to it in Go code, where the origin of the HTML is known, keeps the trust decision out of the
+// templates.
+type TrustedHTML string
+
+// RawIfTrusted returns TrustedHTML as is, and sanitizes any other input with the named policy, or the default policy,
+// so a template rendering a field which is only sometimes trusted is safe either way:
+//
+//	{{rawIfTrusted .Page.Body}}
+func (s *Sanitizer) RawIfTrusted(input any, policy ...string) (template.HTML, error) {
+	if trusted, ok := input.(TrustedHTML); ok {
+		return template.HTML(trusted), nil
+	}
+	return s.Sanitize(input, policy...)
+}
+
+// Sanitize sanitizes HTML with the named policy, or the default policy, and returns it as trusted HTML. The input
+// can be a string, template.HTML, []byte or fmt.Stringer.
+func (s *Sanitizer) Sanitize(input any, policy ...string) (template.HTML, error) {
+	p, err := s.policy(policy)
+	if err != nil {
+		return "", err
+	}
+
+	src, err := toBytes(input)
+	if err != nil {
+		return "", err
+	}
+	return template.HTML(p.SanitizeBytes(src)), nil
+}
+
+// MarkdownSafe converts Markdown to HTML and sanitizes it with the named policy, or the default policy.
+func (s *Sanitizer) MarkdownSafe(input any, policy ...string) (template.HTML, error) {
+	p, err := s.policy(policy)
+	if err != nil {
+		return "", err
+	}
+
+	src, err := toBytes(input)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := s.markdown.Convert(src, &buf); err != nil {
+		return "", fmt.Errorf("error converting markdown: %w", err)
+	}
+	return template.HTML(p.SanitizeBytes(buf.Bytes())), nil
+}
+
+// policy returns the named policy, or the default policy if there is no name.
+func (s *Sanitizer) policy(names []string) (*bluemonday.Policy, error) {
+	name := s.fallback
+	if len(names) > 0 {
+		name = names[0]
+	}
+
+	p, ok := s.policies[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown sanitize policy %q", name)
+	}
+	return p, nil
+}
+
+func toBytes(input any) ([]byte, error) {
+	switch v := input.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []byte(v), nil
+	case template.HTML:
+		return []byte(v), nil
+	case []byte:
+		return v, nil
+	case fmt.Stringer:
+		return []byte(v.String()), nil
+	}
+	return nil, fmt.Errorf("unable to sanitize type %T", input)
+}
@@ -0,0 +1,158 @@
+package hypersanitize_test
+
+import (
+	"html/template"
+	"strings"
+	"testing"
+
+	"github.com/microcosm-cc/bluemonday"
+
+	"github.com/hypergopher/hyperview/contrib/sanitize"
+)
+
+func TestSanitizer_Sanitize(t *testing.T) {
+	s := hypersanitize.New(hypersanitize.Options{
+		Policies: map[string]*bluemonday.Policy{
+			"bold": bluemonday.NewPolicy().AllowElements("b"),
+		},
+	})
+
+	input := `<p onclick="steal()">Hi <b>there</b> <a href="javascript:alert(1)">x</a><script>alert(1)</script></p>`
+	tests := []struct {
+		name   string
+		input  any
+		policy []string
+		want   string
+	}{
+		{"default ugc", input, nil, `<p>Hi <b>there</b> x</p>`},
+		{"strict", input, []string{hypersanitize.Strict}, `Hi there x`},
+		{"custom", input, []string{"bold"}, `Hi <b>there</b> x`},
+		{"trusted HTML is sanitized too", template.HTML(`<img src="/a.png" onerror="x()">`), nil, `<img src="/a.png">`},
+		{"nil", nil, nil, ``},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.Sanitize(tt.input, tt.policy...)
+			if err != nil {
+				t.Fatalf("Sanitize() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Sanitize() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := s.Sanitize("x", "missing"); err == nil || !strings.Contains(err.Error(), "unknown sanitize policy") {
+		t.Errorf("Sanitize() with an unknown policy: error = %v", err)
+	}
+	if _, err := s.Sanitize(42); err == nil {
+		t.Error("Sanitize() of an int: expected an error")
+	}
+}
+
+func TestSanitizer_MarkdownSafe(t *testing.T) {
+	s := hypersanitize.Default()
+
+	got, err := s.MarkdownSafe("# Title\n\nSome *text* <script>alert(1)</script> [link](javascript:alert(1))\n")
+	if err != nil {
+		t.Fatalf("MarkdownSafe() error = %v", err)
+	}
+	for _, want := range []string{"<h1>Title</h1>", "<em>text</em>"} {
+		if !strings.Contains(string(got), want) {
+			t.Errorf("MarkdownSafe() = %q, want it to contain %q", got, want)
+		}
+	}
+	for _, unwanted := range []string{"<script", "javascript:"} {
+		if strings.Contains(string(got), unwanted) {
+			t.Errorf("MarkdownSafe() = %q, want it not to contain %q", got, unwanted)
+		}
+	}
+}
+
+func TestSanitizer_Funcs(t *testing.T) {
+	tmpl := template.Must(template.New("test").Funcs(hypersanitize.Default().Funcs()).
+		Parse(`{{sanitize .}}|{{sanitize . "strict"}}`))
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, `<b>bold</b><script>x</script>`); err != nil {
+		t.Fatalf("error executing template: %v", err)
+	}
+	if got, want := b.String(), `<b>bold</b>|bold`; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestSanitizer_RawIfTrusted(t *testing.T) {
+	s := hypersanitize.Default()
+
+	tests := []struct {
+		name   string
+		input  any
+		policy []string
+		want   string
+	}{
+		{"trusted HTML is kept", hypersanitize.TrustedHTML(`<iframe src="/embed"></iframe>`), nil, `<iframe src="/embed"></iframe>`},
+		{"other HTML is sanitized", `<iframe src="/embed"></iframe><b>hi</b>`, nil, `<b>hi</b>`},
+		{"template.HTML is sanitized", template.HTML(`<script>alert(1)</script>hi`), nil, `hi`},
+		{"policy of untrusted HTML", `<b>hi</b>`, []string{hypersanitize.Strict}, `hi`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.RawIfTrusted(tt.input, tt.policy...)
+			if err != nil {
+				t.Fatalf("RawIfTrusted() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("RawIfTrusted() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSanitizer_RawIfTrustedContexts checks the trusted HTML rendered by rawIfTrusted is only trusted in HTML contexts:
+// in script, style and URL contexts, html/template escapes it like any other value.
+func TestSanitizer_RawIfTrustedContexts(t *testing.T) {
+	payload := hypersanitize.TrustedHTML(`</script><script>alert(1)</script>`)
+
+	tests := []struct {
+		name string
+		tmpl string
+		want string
+	}{
+		{
+			name: "HTML context",
+			tmpl: `<div>{{rawIfTrusted .}}</div>`,
+			want: `<div></script><script>alert(1)</script></div>`,
+		},
+		{
+			name: "script context",
+			tmpl: `<script>var html = {{rawIfTrusted .}};</script>`,
+			want: `<script>var html = "\u003c/script\u003e\u003cscript\u003ealert(1)\u003c/script\u003e";</script>`,
+		},
+		{
+			name: "style context",
+			tmpl: `<p style="color: {{rawIfTrusted .}}"></p>`,
+			want: `<p style="color: ZgotmplZ"></p>`,
+		},
+		{
+			name: "URL context",
+			tmpl: `<a href="{{rawIfTrusted .}}"></a>`,
+			want: `<a href="%3c/script%3e%3cscript%3ealert%281%29%3c/script%3e"></a>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl := template.Must(template.New("test").Funcs(hypersanitize.Default().Funcs()).Parse(tt.tmpl))
+			var b strings.Builder
+			if err := tmpl.Execute(&b, payload); err != nil {
+				t.Fatalf("error executing template: %v", err)
+			}
+			if got := b.String(); got != tt.want {
+				t.Errorf("unexpected output:\ngot  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
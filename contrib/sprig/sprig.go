@@ -0,0 +1,48 @@
+// Package hypersprig adds the Sprig template functions to HyperView templates, in a module of its own so applications
+// not using them do not depend on Sprig.
+//
+// Funcs returns the functions, without those reading the environment or the network, for the LibraryFuncs option of
+// the template adapter, beneath the built-in functions:
+//
+//	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+//		FileSystemMap: fsMap,
+//		LibraryFuncs:  hypersprig.Funcs(),
+//	})
+package hypersprig
+
+import (
+	"html/template"
+
+	"github.com/Masterminds/sprig/v3"
+)
+
+// excludedFuncs are the Sprig functions left out of templates: they read the environment of the process or resolve
+// host names, which templates rendering for users must not do.
+var excludedFuncs = map[string]bool{
+	"env":           true,
+	"expandenv":     true,
+	"getHostByName": true,
+}
+
+// Funcs returns the Sprig template functions, without the functions reading the environment or the network. Their
+// results are escaped by html/template like the results of other functions.
+func Funcs() template.FuncMap {
+	funcMap := make(template.FuncMap)
+	for name, fn := range sprig.TxtFuncMap() {
+		if !excludedFuncs[name] {
+			funcMap[name] = fn
+		}
+	}
+	return funcMap
+}
+
+// With returns a copy of funcMap with the Sprig functions added (see Funcs). The functions of funcMap take precedence
+// over the Sprig functions of the same name, such as join and contains, whose arguments are in a different order in
+// Sprig.
+func With(funcMap template.FuncMap) template.FuncMap {
+	merged := Funcs()
+	for name, fn := range funcMap {
+		merged[name] = fn
+	}
+	return merged
+}
@@ -0,0 +1,72 @@
+package hypersprig_test
+
+import (
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/contrib/sprig"
+	"github.com/hypergopher/hyperview/funcs"
+	"github.com/hypergopher/hyperview/hyperviewtest"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func TestFuncs(t *testing.T) {
+	sprig := hypersprig.Funcs()
+	for _, name := range []string{"env", "expandenv", "getHostByName"} {
+		if _, ok := sprig[name]; ok {
+			t.Errorf("Funcs() includes %s", name)
+		}
+	}
+	for _, name := range []string{"camelcase", "default", "b64enc", "ternary"} {
+		if _, ok := sprig[name]; !ok {
+			t.Errorf("Funcs() is missing %s", name)
+		}
+	}
+}
+
+func TestWith(t *testing.T) {
+	tests := []struct {
+		name string
+		tmpl string
+		want string
+	}{
+		{"sprig function", `{{"hello world" | camelcase}}`, "HelloWorld"},
+		{"sprig default", `{{default "none" ""}}`, "none"},
+		{"built-in wins", `{{join (split "a,b" ",") "-"}}`, "a-b"},
+		{"built-in contains", `{{contains "hyperview" "view"}}`, "true"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := template.New("test").Funcs(hypersprig.With(funcs.FuncMap)).Parse(tt.tmpl)
+			if err != nil {
+				t.Fatalf("error parsing template: %v", err)
+			}
+			var b strings.Builder
+			if err := tmpl.Execute(&b, nil); err != nil {
+				t.Fatalf("error executing template: %v", err)
+			}
+			if b.String() != tt.want {
+				t.Errorf("got %q, want %q", b.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestFuncs_LibraryFuncs(t *testing.T) {
+	adapter := hyperviewtest.NewAdapter(t, map[string]string{
+		"layouts/base.html": `{{define "layout:base"}}{{template "page:main" .}}{{end}}`,
+		"views/home.html":   `{{define "page:main"}}{{.name | camelcase}} {{default "<none>" .missing}}{{end}}`,
+	}, hyperview.TemplateViewAdapterOptions{LibraryFuncs: hypersprig.Funcs()})
+
+	w := httptest.NewRecorder()
+	adapter.Render(w, httptest.NewRequest(http.MethodGet, "/", nil),
+		response.NewResponse().Layout("base").Path("home").Data(map[string]any{"name": "hyper view"}))
+	if got, want := w.Body.String(), "HyperView &lt;none&gt;"; got != want {
+		t.Errorf("unexpected body: got %q, want %q", got, want)
+	}
+}
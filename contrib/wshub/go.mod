@@ -0,0 +1,5 @@
+module github.com/hypergopher/hyperview/contrib/wshub
+
+go 1.23.0
+
+require golang.org/x/net v0.38.0
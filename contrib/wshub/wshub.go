@@ -0,0 +1,301 @@
+// Package wshub pushes rendered fragments to the browsers subscribed to a topic over WebSocket, such as the
+// notifications of a user, for live updates rendered by the same templates as the pages.
+//
+// Pages connect with the ws extension of HTMX, which swaps the elements of each message into the elements of the page
+// with the same IDs, like out-of-band swaps:
+//
+//	<div hx-ext="ws" ws-connect="/ws"><ul id="notifications"></ul></div>
+//
+// The handler subscribes each connection to the topics the server chooses for its request, so clients cannot listen
+// to the topics of others:
+//
+//	hub := wshub.NewHub(wshub.Options{})
+//	mux.Handle("/ws", hub.Handler(func(r *http.Request) ([]string, error) {
+//		return []string{"notifications:" + userID(r)}, nil
+//	}))
+//	_ = hub.PublishPartial(adapter, "notifications:42", "@notification", n)
+package wshub
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+const (
+	// DefaultSendBuffer is the number of messages queued for a client before it is considered too slow and
+	// disconnected.
+	DefaultSendBuffer = 16
+	// DefaultWriteTimeout is the time a message has to be written to a client before it is disconnected.
+	DefaultWriteTimeout = 10 * time.Second
+)
+
+// ErrClosed is returned by Publish once the hub is shut down.
+var ErrClosed = errors.New("wshub: hub closed")
+
+// Options are the options of a Hub.
+type Options struct {
+	// AllowedOrigins are the origins, e.g. https://example.com, allowed to connect besides the origin of the server.
+	// Browsers send the cookies of the site with WebSocket requests from any origin, so connections from other origins
+	// are refused unless listed. "*" allows all origins.
+	AllowedOrigins []string
+	// SendBuffer is the number of messages queued for a client. Default is DefaultSendBuffer.
+	SendBuffer int
+	// WriteTimeout is the time a message has to be written to a client. Default is DefaultWriteTimeout.
+	WriteTimeout time.Duration
+	// OnMessage is called with the messages clients send, such as the JSON of the forms sent by the ws-send attribute
+	// of HTMX, with the request of the connection. Messages are ignored when nil.
+	OnMessage func(r *http.Request, msg []byte)
+	// Logger is the logger to use for errors. Default is slog.Default().
+	Logger *slog.Logger
+}
+
+// Renderer renders named partials, like the template adapter.
+type Renderer interface {
+	RenderPartial(w io.Writer, name string, data any) error
+}
+
+// Hub tracks the WebSocket clients subscribed to each topic and pushes messages to them. It is safe for concurrent
+// use.
+type Hub struct {
+	opts   Options
+	mu     sync.Mutex
+	topics map[string]map[*client]struct{}
+	closed bool
+}
+
+// client is a connection subscribed to topics, with the queue of its messages.
+type client struct {
+	send      chan string
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (c *client) close() {
+	c.closeOnce.Do(func() { close(c.done) })
+}
+
+// NewHub creates a hub without clients.
+func NewHub(opts Options) *Hub {
+	if opts.SendBuffer <= 0 {
+		opts.SendBuffer = DefaultSendBuffer
+	}
+	if opts.WriteTimeout <= 0 {
+		opts.WriteTimeout = DefaultWriteTimeout
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+
+	return &Hub{opts: opts, topics: make(map[string]map[*client]struct{})}
+}
+
+// Handler returns the handler upgrading requests to WebSocket connections subscribed to the topics returned by topics
+// for the request. Requests for which topics fails are answered with 403 Forbidden, and requests whose response
+// writer cannot hijack the connection, such as that of a middleware buffering the response, with 500 Internal Server
+// Error. Response writers wrapping a hijackable one are upgraded through their Unwrap method, like with
+// http.ResponseController.
+func (h *Hub) Handler(topics func(r *http.Request) ([]string, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hijackable := hijacker(w)
+		if hijackable == nil {
+			h.opts.Logger.Error("wshub: the response writer cannot hijack the connection", slog.String("type", fmt.Sprintf("%T", w)))
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		names, err := topics(r)
+		if err != nil {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+
+		server := websocket.Server{
+			Handshake: func(_ *websocket.Config, r *http.Request) error { return h.checkOrigin(r) },
+			Handler:   func(conn *websocket.Conn) { h.serve(conn, r, names) },
+		}
+		server.ServeHTTP(hijackable, r)
+	})
+}
+
+// hijackableWriter is a response writer wrapping another, whose Hijack method is that of the writer it unwraps to.
+type hijackableWriter struct {
+	http.ResponseWriter
+	http.Hijacker
+}
+
+// hijacker returns the response writer if it hijacks the connection, the response writer with the Hijack method of
+// the writer it unwraps to if one does, or nil. The WebSocket server requires an http.Hijacker, which wrapping writers
+// without a Hijack method, such as those of middlewares, would hide, and panics if hijacking fails.
+func hijacker(w http.ResponseWriter) http.ResponseWriter {
+	for inner := w; inner != nil; {
+		if hijacks(inner) {
+			if inner == w {
+				return w
+			}
+			return hijackableWriter{ResponseWriter: w, Hijacker: inner.(http.Hijacker)}
+		}
+		unwrapper, ok := inner.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return nil
+		}
+		inner = unwrapper.Unwrap()
+	}
+	return nil
+}
+
+// hijacks reports whether the writer implements http.Hijacker and, if it wraps another writer, whether that one
+// hijacks, as the Hijack methods of wrapping writers delegate to those of the writers they wrap.
+func hijacks(w http.ResponseWriter) bool {
+	if _, ok := w.(http.Hijacker); !ok {
+		return false
+	}
+	if unwrapper, ok := w.(interface{ Unwrap() http.ResponseWriter }); ok {
+		return hijacks(unwrapper.Unwrap())
+	}
+	return true
+}
+
+// checkOrigin accepts the requests from the origin of the server and the allowed origins, and the requests without
+// an origin, which do not come from browsers.
+func (h *Hub) checkOrigin(r *http.Request) error {
+	origin := r.Header.Get("Origin")
+	if origin == "" || slices.Contains(h.opts.AllowedOrigins, "*") || slices.Contains(h.opts.AllowedOrigins, origin) {
+		return nil
+	}
+	if u, err := url.Parse(origin); err == nil && u.Host == r.Host {
+		return nil
+	}
+	return fmt.Errorf("wshub: origin %s not allowed", origin)
+}
+
+// serve pushes the messages of the topics to the connection until it is closed, by either side.
+func (h *Hub) serve(conn *websocket.Conn, r *http.Request, topics []string) {
+	defer conn.Close()
+
+	c := &client{send: make(chan string, h.opts.SendBuffer), done: make(chan struct{})}
+	if !h.subscribe(c, topics) {
+		return
+	}
+	defer h.unsubscribe(c, topics)
+
+	go func() {
+		defer c.close()
+		for {
+			var msg []byte
+			if err := websocket.Message.Receive(conn, &msg); err != nil {
+				return
+			}
+			if h.opts.OnMessage != nil {
+				h.opts.OnMessage(r, msg)
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-r.Context().Done():
+			return
+		case msg := <-c.send:
+			_ = conn.SetWriteDeadline(time.Now().Add(h.opts.WriteTimeout))
+			if err := websocket.Message.Send(conn, msg); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (h *Hub) subscribe(c *client, topics []string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return false
+	}
+	for _, topic := range topics {
+		if h.topics[topic] == nil {
+			h.topics[topic] = make(map[*client]struct{})
+		}
+		h.topics[topic][c] = struct{}{}
+	}
+	return true
+}
+
+func (h *Hub) unsubscribe(c *client, topics []string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, topic := range topics {
+		delete(h.topics[topic], c)
+		if len(h.topics[topic]) == 0 {
+			delete(h.topics, topic)
+		}
+	}
+}
+
+// Publish pushes the HTML to the clients subscribed to the topic and returns the number of clients it was queued for.
+// Clients whose queue is full are too slow to keep up, and are disconnected.
+func (h *Hub) Publish(topic, html string) (int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return 0, ErrClosed
+	}
+
+	sent := 0
+	for c := range h.topics[topic] {
+		select {
+		case c.send <- html:
+			sent++
+		default:
+			h.opts.Logger.Warn("WebSocket client too slow, disconnecting", slog.String("topic", topic))
+			c.close()
+		}
+	}
+	return sent, nil
+}
+
+// PublishPartial renders the named partial with data, e.g. with the template adapter, and pushes it to the clients
+// subscribed to the topic. The partial is rendered once, whatever the number of clients.
+func (h *Hub) PublishPartial(renderer Renderer, topic, name string, data any) error {
+	var buf bytes.Buffer
+	if err := renderer.RenderPartial(&buf, name, data); err != nil {
+		return fmt.Errorf("error rendering partial %s: %w", name, err)
+	}
+	_, err := h.Publish(topic, buf.String())
+	return err
+}
+
+// Subscribers returns the number of clients subscribed to the topic.
+func (h *Hub) Subscribers(topic string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.topics[topic])
+}
+
+// Shutdown disconnects the clients and refuses new connections. It can be registered with HyperView.OnShutdown.
+func (h *Hub) Shutdown(context.Context) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for _, clients := range h.topics {
+		for c := range clients {
+			c.close()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package wshub_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/hypergopher/hyperview/contrib/wshub"
+)
+
+type partialRenderer struct{}
+
+func (partialRenderer) RenderPartial(w io.Writer, name string, data any) error {
+	if name != "@notification" {
+		return errors.New("no such partial")
+	}
+	_, err := fmt.Fprintf(w, `<ul id="notifications" hx-swap-oob="beforeend"><li>%v</li></ul>`, data)
+	return err
+}
+
+func newTestHub(t *testing.T, opts wshub.Options) (*wshub.Hub, *httptest.Server) {
+	t.Helper()
+
+	opts.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	hub := wshub.NewHub(opts)
+	server := httptest.NewServer(hub.Handler(func(r *http.Request) ([]string, error) {
+		user := r.URL.Query().Get("user")
+		if user == "" {
+			return nil, errors.New("anonymous")
+		}
+		return []string{"notifications:" + user, "broadcast"}, nil
+	}))
+	t.Cleanup(server.Close)
+	return hub, server
+}
+
+func dial(t *testing.T, server *httptest.Server, query, origin string) (*websocket.Conn, error) {
+	t.Helper()
+	return websocket.Dial(strings.Replace(server.URL, "http", "ws", 1)+"/?"+query, "", origin)
+}
+
+// waitSubscribers waits for the connections to be subscribed, as the handshake completes before the subscription.
+func waitSubscribers(t *testing.T, hub *wshub.Hub, topic string, n int) {
+	t.Helper()
+	for deadline := time.Now().Add(2 * time.Second); hub.Subscribers(topic) != n; {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d subscribers of %s, got %d", n, topic, hub.Subscribers(topic))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func receive(t *testing.T, conn *websocket.Conn) string {
+	t.Helper()
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var msg string
+	if err := websocket.Message.Receive(conn, &msg); err != nil {
+		t.Fatalf("error receiving message: %v", err)
+	}
+	return msg
+}
+
+func TestHub_Publish(t *testing.T) {
+	hub, server := newTestHub(t, wshub.Options{})
+
+	ann, err := dial(t, server, "user=ann", server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ann.Close()
+	bob, err := dial(t, server, "user=bob", server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bob.Close()
+	waitSubscribers(t, hub, "broadcast", 2)
+
+	// Each client only receives the messages of its topics
+	if err := hub.PublishPartial(partialRenderer{}, "notifications:ann", "@notification", "Hello Ann"); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := hub.Publish("broadcast", `<div id="banner">Maintenance</div>`); err != nil || n != 2 {
+		t.Fatalf("expected the broadcast to reach 2 clients, got %d, %v", n, err)
+	}
+
+	if got, want := receive(t, ann), `<ul id="notifications" hx-swap-oob="beforeend"><li>Hello Ann</li></ul>`; got != want {
+		t.Errorf("unexpected message: %s", got)
+	}
+	if got := receive(t, ann); got != `<div id="banner">Maintenance</div>` {
+		t.Errorf("unexpected message: %s", got)
+	}
+	if got := receive(t, bob); got != `<div id="banner">Maintenance</div>` {
+		t.Errorf("expected bob to skip the notification of ann, got %s", got)
+	}
+
+	if err := hub.PublishPartial(partialRenderer{}, "broadcast", "missing", nil); err == nil {
+		t.Error("expected an error for a missing partial")
+	}
+
+	// Closed connections are unsubscribed
+	bob.Close()
+	waitSubscribers(t, hub, "broadcast", 1)
+
+	if err := hub.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := hub.Publish("broadcast", "late"); !errors.Is(err, wshub.ErrClosed) {
+		t.Errorf("expected ErrClosed after shutdown, got %v", err)
+	}
+}
+
+func TestHub_Handler(t *testing.T) {
+	received := make(chan string, 1)
+	_, server := newTestHub(t, wshub.Options{
+		AllowedOrigins: []string{"https://app.example.com"},
+		OnMessage: func(r *http.Request, msg []byte) {
+			received <- r.URL.Query().Get("user") + ": " + string(msg)
+		},
+	})
+
+	if _, err := dial(t, server, "", server.URL); err == nil {
+		t.Error("expected requests without topics to be refused")
+	}
+	if _, err := dial(t, server, "user=ann", "https://evil.example.com"); err == nil {
+		t.Error("expected other origins to be refused")
+	}
+
+	conn, err := dial(t, server, "user=ann", "https://app.example.com")
+	if err != nil {
+		t.Fatalf("expected the allowed origin to connect: %v", err)
+	}
+	defer conn.Close()
+
+	if err := websocket.Message.Send(conn, `{"message":"hi"}`); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case got := <-received:
+		if got != `ann: {"message":"hi"}` {
+			t.Errorf("unexpected message: %s", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("expected the message to reach OnMessage")
+	}
+}
+
+// wrappedWriter is a response writer of a middleware, which neither hijacks nor flushes itself.
+type wrappedWriter struct {
+	w http.ResponseWriter
+}
+
+func (w wrappedWriter) Header() http.Header         { return w.w.Header() }
+func (w wrappedWriter) Write(b []byte) (int, error) { return w.w.Write(b) }
+func (w wrappedWriter) WriteHeader(status int)      { w.w.WriteHeader(status) }
+
+type unwrappingWriter struct {
+	wrappedWriter
+}
+
+func (w unwrappingWriter) Unwrap() http.ResponseWriter { return w.w }
+
+func TestHub_Handler_WrappedWriter(t *testing.T) {
+	hub := wshub.NewHub(wshub.Options{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+	handler := hub.Handler(func(r *http.Request) ([]string, error) { return []string{"broadcast"}, nil })
+
+	tests := []struct {
+		name    string
+		wrap    func(w http.ResponseWriter) http.ResponseWriter
+		connect bool
+	}{
+		{name: "unwrapping writer", wrap: func(w http.ResponseWriter) http.ResponseWriter { return unwrappingWriter{wrappedWriter{w}} }, connect: true},
+		{name: "opaque writer", wrap: func(w http.ResponseWriter) http.ResponseWriter { return wrappedWriter{w} }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				handler.ServeHTTP(tt.wrap(w), r)
+			}))
+			defer server.Close()
+
+			conn, err := dial(t, server, "", server.URL)
+			if !tt.connect {
+				if err == nil {
+					t.Fatal("expected the connection to be refused")
+				}
+				resp, err := http.Get(server.URL)
+				if err != nil {
+					t.Fatal(err)
+				}
+				_ = resp.Body.Close()
+				if resp.StatusCode != http.StatusInternalServerError {
+					t.Errorf("expected status 500, got %d", resp.StatusCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected the connection to be upgraded: %v", err)
+			}
+			defer conn.Close()
+			waitSubscribers(t, hub, "broadcast", 1)
+		})
+	}
+}
@@ -0,0 +1,182 @@
+// Package csp provides per-request Content-Security-Policy nonces for HyperView applications.
+//
+// The middleware generates a nonce for each request, sets the Content-Security-Policy header with it, and makes it
+// available to templates through the cspNonce, scriptTag and styleTag functions, and as .View.Nonce. For clients
+// which cannot use nonces, HashMiddleware sets a policy allowing the inline scripts and styles of each page by their
+// hashes instead.
+package csp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+)
+
+// NoncePlaceholder is replaced by the request's nonce in the policy.
+const NoncePlaceholder = "{nonce}"
+
+// DefaultPolicy is a strict policy allowing only same-origin resources, and scripts and styles carrying the nonce.
+const DefaultPolicy = "default-src 'self'; script-src 'self' 'nonce-{nonce}'; style-src 'self' 'nonce-{nonce}'; " +
+	"object-src 'none'; base-uri 'self'"
+
+// Options are the options for the CSP middleware.
+type Options struct {
+	// Policy is the Content-Security-Policy, where NoncePlaceholder is replaced by the request's nonce.
+	// Default is DefaultPolicy.
+	Policy string
+	// ReportOnly sets the Content-Security-Policy-Report-Only header instead, so violations are reported but not
+	// blocked. This is useful when rolling out a new policy.
+	ReportOnly bool
+	// SkipHeader generates the nonce without setting the header, when the policy is set on the rendered pages with
+	// the nonce of the request instead, such as by the secure package.
+	SkipHeader bool
+}
+
+// NewNonce returns a new random nonce, base64url encoded so it needs no escaping in HTML attributes.
+func NewNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// ContextWithNonce returns a copy of ctx carrying the given nonce.
+func ContextWithNonce(ctx context.Context, nonce string) context.Context {
+	return context.WithValue(ctx, constants.NonceContextKey, nonce)
+}
+
+// NonceFromContext returns the nonce carried by ctx, or an empty string if there is none.
+func NonceFromContext(ctx context.Context) string {
+	nonce, _ := ctx.Value(constants.NonceContextKey).(string)
+	return nonce
+}
+
+// Middleware returns a middleware generating a nonce for each request. The nonce is added to the request context,
+// along with the template functions bound to it (see Funcs), and the Content-Security-Policy header is set.
+func Middleware(opts Options) func(http.Handler) http.Handler {
+	if opts.Policy == "" {
+		opts.Policy = DefaultPolicy
+	}
+
+	header := "Content-Security-Policy"
+	if opts.ReportOnly {
+		header = "Content-Security-Policy-Report-Only"
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			nonce, err := NewNonce()
+			if err != nil {
+				http.Error(w, fmt.Errorf("error generating CSP nonce: %w", err).Error(), http.StatusInternalServerError)
+				return
+			}
+
+			if !opts.SkipHeader {
+				w.Header().Set(header, strings.ReplaceAll(opts.Policy, NoncePlaceholder, nonce))
+			}
+
+			ctx := ContextWithNonce(r.Context(), nonce)
+			ctx = hyperview.ContextWithFuncs(ctx, Funcs(nonce))
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// Funcs returns the template functions bound to the given nonce:
+//
+//   - cspNonce: returns the nonce, e.g. <script nonce="{{cspNonce}}">...</script>
+//   - scriptTag: returns a script element loading src, e.g. {{scriptTag "/js/app.js"}}
+//   - styleTag: returns a stylesheet link element for href, e.g. {{styleTag "/css/app.css"}}
+//
+// Additional arguments of scriptTag and styleTag are attribute name/value pairs, e.g.
+// {{scriptTag "/js/app.js" "type" "module"}}. As the elements carry the nonce, the functions refuse what would run
+// other scripts with it: attribute names other than letters, digits and dashes, event handler attributes such as
+// onload, and URLs other than http, https and relative ones, such as javascript: and data: URLs.
+//
+// Pass Funcs("") to the template adapter's RequestFuncs option, and use Middleware to bind them to the nonce of each
+// request.
+func Funcs(nonce string) template.FuncMap {
+	return template.FuncMap{
+		"cspNonce": func() string {
+			return nonce
+		},
+		"scriptTag": func(src string, attrs ...string) (template.HTML, error) {
+			if err := checkURL("src", src); err != nil {
+				return "", err
+			}
+			return tag(`<script src="`+template.HTMLEscapeString(src)+`"`, "></script>", nonce, attrs)
+		},
+		"styleTag": func(href string, attrs ...string) (template.HTML, error) {
+			if err := checkURL("href", href); err != nil {
+				return "", err
+			}
+			return tag(`<link rel="stylesheet" href="`+template.HTMLEscapeString(href)+`"`, ">", nonce, attrs)
+		},
+	}
+}
+
+func tag(open, closing, nonce string, attrs []string) (template.HTML, error) {
+	if len(attrs)%2 != 0 {
+		return "", fmt.Errorf("attributes must be name/value pairs")
+	}
+
+	var b strings.Builder
+	b.WriteString(open)
+	for i := 0; i < len(attrs); i += 2 {
+		if err := checkAttr(attrs[i], attrs[i+1]); err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, ` %s="%s"`, template.HTMLEscapeString(attrs[i]), template.HTMLEscapeString(attrs[i+1]))
+	}
+	if nonce != "" {
+		fmt.Fprintf(&b, ` nonce="%s"`, template.HTMLEscapeString(nonce))
+	}
+	b.WriteString(closing)
+
+	return template.HTML(b.String()), nil
+}
+
+// attrName matches the attribute names the tag functions accept.
+var attrName = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9-]*$`)
+
+// urlAttrs are the attributes of the elements of the tag functions holding URLs.
+var urlAttrs = map[string]bool{"src": true, "href": true}
+
+// checkAttr returns an error if the attribute could run scripts other than those of the element with its nonce: a name
+// that is not a plain attribute name, an event handler, the nonce itself or an unsafe URL.
+func checkAttr(name, value string) error {
+	if !attrName.MatchString(name) {
+		return fmt.Errorf("invalid attribute name %q", name)
+	}
+	lower := strings.ToLower(name)
+	if strings.HasPrefix(lower, "on") || lower == "nonce" {
+		return fmt.Errorf("attribute %s is not allowed", name)
+	}
+	if urlAttrs[lower] {
+		return checkURL(lower, value)
+	}
+	return nil
+}
+
+// checkURL returns an error if the URL has a scheme other than http and https, like html/template refuses in URL
+// attributes.
+func checkURL(attr, value string) error {
+	scheme, _, ok := strings.Cut(value, ":")
+	if !ok || strings.ContainsAny(scheme, "/?#") {
+		return nil
+	}
+	if scheme = strings.ToLower(strings.TrimSpace(scheme)); scheme != "http" && scheme != "https" {
+		return fmt.Errorf("unsafe %s URL %q", attr, value)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package csp_test
+
+import (
+	"html/template"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/csp"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func TestMiddleware(t *testing.T) {
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{
+			"layouts/base.html": {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+			"views/home.html": {Data: []byte(`{{define "page:main"}}{{scriptTag "/js/app.js" "type" "module"}}` +
+				`{{styleTag "/css/app.css"}}<script nonce="{{cspNonce}}"></script>|{{.View.Nonce}}{{end}}`)},
+		}},
+		RequestFuncs: csp.Funcs(""),
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+
+	var nonce string
+	handler := csp.Middleware(csp.Options{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nonce = csp.NonceFromContext(r.Context())
+		adapter.Render(w, r, response.NewResponse().Layout("base").Path("home"))
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if nonce == "" {
+		t.Fatal("expected a nonce in the request context")
+	}
+
+	policy := w.Header().Get("Content-Security-Policy")
+	if !strings.Contains(policy, "script-src 'self' 'nonce-"+nonce+"'") {
+		t.Errorf("unexpected policy: %s", policy)
+	}
+
+	escaped := template.HTMLEscapeString(nonce)
+	want := `<script src="/js/app.js" type="module" nonce="` + escaped + `"></script>` +
+		`<link rel="stylesheet" href="/css/app.css" nonce="` + escaped + `">` +
+		`<script nonce="` + escaped + `"></script>|` + escaped
+	if got := w.Body.String(); got != want {
+		t.Errorf("unexpected body:\ngot  %s\nwant %s", got, want)
+	}
+}
+
+func TestMiddleware_ReportOnly(t *testing.T) {
+	handler := csp.Middleware(csp.Options{Policy: "script-src 'nonce-{nonce}'", ReportOnly: true})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if got := w.Header().Get("Content-Security-Policy"); got != "" {
+		t.Errorf("expected no enforced policy, got %s", got)
+	}
+	if got := w.Header().Get("Content-Security-Policy-Report-Only"); !strings.HasPrefix(got, "script-src 'nonce-") {
+		t.Errorf("unexpected report-only policy: %s", got)
+	}
+}
+
+func TestNewNonce(t *testing.T) {
+	a, err := csp.NewNonce()
+	if err != nil {
+		t.Fatalf("error generating nonce: %v", err)
+	}
+	b, _ := csp.NewNonce()
+	if a == b || len(a) != 22 {
+		t.Errorf("expected distinct 22 character nonces, got %q and %q", a, b)
+	}
+}
+
+func TestFuncs_InvalidAttributes(t *testing.T) {
+	funcs := csp.Funcs("abc")
+	scriptTag := funcs["scriptTag"].(func(string, ...string) (template.HTML, error))
+	styleTag := funcs["styleTag"].(func(string, ...string) (template.HTML, error))
+
+	tests := []struct {
+		name  string
+		tag   func(string, ...string) (template.HTML, error)
+		url   string
+		attrs []string
+	}{
+		{name: "unpaired attributes", tag: scriptTag, url: "/js/app.js", attrs: []string{"defer"}},
+		{name: "attribute name with a space", tag: scriptTag, url: "/js/app.js", attrs: []string{"x onerror", "alert(1)"}},
+		{name: "attribute name with an equals sign", tag: scriptTag, url: "/js/app.js", attrs: []string{"x=1", ""}},
+		{name: "event handler", tag: scriptTag, url: "/js/app.js", attrs: []string{"onload", "alert(1)"}},
+		{name: "event handler in capitals", tag: styleTag, url: "/css/app.css", attrs: []string{"ONLOAD", "alert(1)"}},
+		{name: "nonce override", tag: scriptTag, url: "/js/app.js", attrs: []string{"nonce", "other"}},
+		{name: "javascript URL", tag: scriptTag, url: "javascript:alert(1)"},
+		{name: "data URL", tag: scriptTag, url: " Data:text/javascript,alert(1)"},
+		{name: "javascript URL attribute", tag: styleTag, url: "/css/app.css", attrs: []string{"href", "javascript:alert(1)"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := tt.tag(tt.url, tt.attrs...); err == nil {
+				t.Errorf("expected an error, got %s", got)
+			}
+		})
+	}
+
+	got, err := scriptTag("https://cdn.example.com/app.js?v=1", "type", "module", "data-turbo", "false")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `<script src="https://cdn.example.com/app.js?v=1" type="module" data-turbo="false" nonce="abc"></script>`; string(got) != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func TestMiddleware_SkipHeader(t *testing.T) {
+	var nonce string
+	handler := csp.Middleware(csp.Options{SkipHeader: true})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nonce = csp.NonceFromContext(r.Context())
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if nonce == "" {
+		t.Error("expected a nonce in the request context")
+	}
+	if got := w.Header().Get("Content-Security-Policy"); got != "" {
+		t.Errorf("expected no policy header, got %q", got)
+	}
+}
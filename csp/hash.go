@@ -0,0 +1,194 @@
+package csp
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	xhtml "golang.org/x/net/html"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/response"
+)
+
+const (
+	// ScriptHashesPlaceholder is replaced by the hashes of the inline scripts of the page in the policy.
+	ScriptHashesPlaceholder = "{script-hashes}"
+	// StyleHashesPlaceholder is replaced by the hashes of the inline styles of the page in the policy.
+	StyleHashesPlaceholder = "{style-hashes}"
+)
+
+// DefaultHashPolicy is a strict policy allowing only same-origin resources, and the inline scripts and styles of the
+// page by their hashes.
+const DefaultHashPolicy = "default-src 'self'; script-src 'self' {script-hashes}; style-src 'self' {style-hashes}; " +
+	"object-src 'none'; base-uri 'self'"
+
+// HashOptions are the options of the hash middleware.
+type HashOptions struct {
+	// Policy is the Content-Security-Policy, where ScriptHashesPlaceholder and StyleHashesPlaceholder are replaced by
+	// the hashes of the inline scripts and styles of the page. Default is DefaultHashPolicy.
+	Policy string
+	// ReportOnly sets the Content-Security-Policy-Report-Only header instead.
+	ReportOnly bool
+}
+
+// Hashes are the hashes of the inline scripts and styles of a page, as CSP source expressions, e.g.
+// 'sha256-...', in the order of the page without duplicates.
+type Hashes struct {
+	Scripts []string
+	Styles  []string
+}
+
+// Hash returns the CSP source expression allowing the inline script or style with the content, e.g. 'sha256-...'.
+// The content is hashed as is, so it must be the exact text between the tags, whitespace included.
+func Hash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return "'sha256-" + base64.StdEncoding.EncodeToString(sum[:]) + "'"
+}
+
+// CollectHashes returns the hashes of the inline script and style elements of the HTML document. Scripts loaded from a
+// src and data blocks, such as JSON scripts, need no hash and are skipped.
+//
+// All the inline elements of the document are hashed, including those inserted by the data of a render, so only
+// collect the hashes of trusted documents. HashMiddleware only hashes the elements of the templates instead.
+func CollectHashes(body []byte) (Hashes, error) {
+	return collectHashes(body, nil)
+}
+
+// collectHashes is like CollectHashes, only hashing the elements whose tag and content are allowed by allow, unless
+// it is nil.
+func collectHashes(body []byte, allow func(tag, content string) bool) (Hashes, error) {
+	var hashes Hashes
+	z := xhtml.NewTokenizer(bytes.NewReader(body))
+	for {
+		switch z.Next() {
+		case xhtml.ErrorToken:
+			if errors.Is(z.Err(), io.EOF) {
+				return hashes, nil
+			}
+			return Hashes{}, z.Err()
+		case xhtml.StartTagToken:
+			name, hasAttr := z.TagName()
+			tag := string(name)
+			if tag != "script" && tag != "style" {
+				continue
+			}
+			if tag == "script" && hasAttr && !isInlineScript(z) {
+				continue
+			}
+
+			// Raw text elements hold a single text token, missing when they are empty. Browsers hash the content with
+			// the line endings the HTML parser normalized.
+			content := ""
+			if z.Next() == xhtml.TextToken {
+				content = newlines.Replace(string(z.Raw()))
+			}
+			if allow != nil && !allow(tag, content) {
+				continue
+			}
+			if tag == "script" {
+				hashes.Scripts = appendHash(hashes.Scripts, Hash(content))
+			} else {
+				hashes.Styles = appendHash(hashes.Styles, Hash(content))
+			}
+		}
+	}
+}
+
+var newlines = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+// isInlineScript reports whether the script the tokenizer is at is executed from its content: scripts without a src
+// and of an executable type.
+func isInlineScript(z *xhtml.Tokenizer) bool {
+	for {
+		key, val, more := z.TagAttr()
+		switch string(key) {
+		case "src":
+			return false
+		case "type":
+			switch strings.ToLower(strings.TrimSpace(string(val))) {
+			case "", "module", "text/javascript", "application/javascript":
+			default:
+				return false
+			}
+		}
+		if !more {
+			return true
+		}
+	}
+}
+
+// appendHash appends the hash unless the hashes have it already.
+func appendHash(hashes []string, hash string) []string {
+	if slices.Contains(hashes, hash) {
+		return hashes
+	}
+	return append(hashes, hash)
+}
+
+// HashMiddleware returns a render middleware setting the Content-Security-Policy header of the pages rendered as HTML
+// with the hashes of their inline scripts and styles, for clients which cannot use nonces. The hashes are collected
+// from the body the inner middleware returns, so it must come before the middleware rewriting the body, such as the
+// rewrite package's, in the RenderMiddleware option. Policies set by the handler are kept.
+//
+// Only the elements written in the templates as text, without actions, are hashed (see hyperview.InlineContents).
+// Scripts and styles whose content holds an action, or inserted by the data of a render, e.g. user content passed to
+// safeHTML, are left out of the policy, so browsers block them: pass their data to a script of the templates, e.g.
+// with jsonScript, instead.
+//
+// The middleware also runs on the bodies served from the render cache, which are cached before the middleware, so the
+// policy of cached pages is computed from the cached body.
+func HashMiddleware(opts HashOptions) hyperview.RenderMiddleware {
+	if opts.Policy == "" {
+		opts.Policy = DefaultHashPolicy
+	}
+
+	header := "Content-Security-Policy"
+	if opts.ReportOnly {
+		header = "Content-Security-Policy-Report-Only"
+	}
+
+	return func(next hyperview.RenderFunc) hyperview.RenderFunc {
+		return func(r *http.Request, resp *response.Response) ([]byte, error) {
+			_, set := resp.Headers()[header]
+			body, err := next(r, resp)
+			if err != nil || set || !resp.IsHTML() {
+				return body, err
+			}
+
+			contents := hyperview.InlineContentsFromContext(r.Context())
+			hashes, err := collectHashes(body, func(tag, content string) bool {
+				if tag == "script" {
+					return contents.Script(content)
+				}
+				return contents.Style(content)
+			})
+			if err != nil {
+				return nil, fmt.Errorf("error hashing the inline scripts of %s: %w", resp.TemplatePath(), err)
+			}
+			resp.Header(header, hashes.Policy(opts.Policy))
+			return body, nil
+		}
+	}
+}
+
+// Policy returns the policy with ScriptHashesPlaceholder and StyleHashesPlaceholder replaced by the hashes, and the
+// whitespace left by missing hashes removed.
+func (h Hashes) Policy(policy string) string {
+	policy = strings.ReplaceAll(policy, ScriptHashesPlaceholder, strings.Join(h.Scripts, " "))
+	policy = strings.ReplaceAll(policy, StyleHashesPlaceholder, strings.Join(h.Styles, " "))
+
+	var directives []string
+	for _, directive := range strings.Split(policy, ";") {
+		if fields := strings.Fields(directive); len(fields) > 0 {
+			directives = append(directives, strings.Join(fields, " "))
+		}
+	}
+	return strings.Join(directives, "; ")
+}
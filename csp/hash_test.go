@@ -0,0 +1,199 @@
+package csp_test
+
+import (
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/csp"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func TestHash(t *testing.T) {
+	// The example of the CSP specification
+	if got, want := csp.Hash("alert('Hello, world.');"), "'sha256-qznLcsROx4GACP2dm0UCKCzCG+HiZ1guq6ZZDob/Tng='"; got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func TestCollectHashes(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		scripts []string
+		styles  []string
+	}{
+		{
+			name:    "inline script and style",
+			body:    `<style>p{}</style><p>text</p><script>a()</script>`,
+			scripts: []string{csp.Hash("a()")},
+			styles:  []string{csp.Hash("p{}")},
+		},
+		{
+			name:    "external and data scripts",
+			body:    `<script src="/app.js"></script><script type="application/json">{}</script><script type="module">b()</script>`,
+			scripts: []string{csp.Hash("b()")},
+		},
+		{
+			name:    "duplicates",
+			body:    `<script>a()</script><script>a()</script>`,
+			scripts: []string{csp.Hash("a()")},
+		},
+		{
+			name:    "empty script",
+			body:    `<script></script>`,
+			scripts: []string{csp.Hash("")},
+		},
+		{
+			name:    "normalized line endings",
+			body:    "<script>a()\r\nb()</script>",
+			scripts: []string{csp.Hash("a()\nb()")},
+		},
+		{
+			name:    "markup in scripts",
+			body:    `<script>if (a < b) { c("</p>") }</script>`,
+			scripts: []string{csp.Hash(`if (a < b) { c("</p>") }`)},
+		},
+		{
+			name: "no inline content",
+			body: `<p>text</p>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hashes, err := csp.CollectHashes([]byte(tt.body))
+			if err != nil {
+				t.Fatalf("error collecting hashes: %v", err)
+			}
+			if !slices.Equal(hashes.Scripts, tt.scripts) {
+				t.Errorf("expected scripts %v, got %v", tt.scripts, hashes.Scripts)
+			}
+			if !slices.Equal(hashes.Styles, tt.styles) {
+				t.Errorf("expected styles %v, got %v", tt.styles, hashes.Styles)
+			}
+		})
+	}
+}
+
+func TestHashes_Policy(t *testing.T) {
+	hashes := csp.Hashes{Scripts: []string{"'sha256-a'", "'sha256-b'"}}
+	want := "default-src 'self'; script-src 'self' 'sha256-a' 'sha256-b'; style-src 'self'; object-src 'none'; base-uri 'self'"
+	if got := hashes.Policy(csp.DefaultHashPolicy); got != want {
+		t.Errorf("unexpected policy:\ngot  %s\nwant %s", got, want)
+	}
+}
+
+func TestHashMiddleware(t *testing.T) {
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{
+			"layouts/base.html": {Data: []byte(`{{define "layout:base"}}<style>body{}</style>{{template "page:main" .}}{{end}}`)},
+			"views/home.html":   {Data: []byte(`{{define "page:main"}}<script>init()</script>{{end}}`)},
+		}},
+		RenderMiddleware: []hyperview.RenderMiddleware{csp.HashMiddleware(csp.HashOptions{})},
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		resp   *response.Response
+		header string
+		want   string
+	}{
+		{
+			name:   "hashed policy",
+			resp:   response.NewResponse().Layout("base").Path("home"),
+			header: "Content-Security-Policy",
+			want: "default-src 'self'; script-src 'self' " + csp.Hash("init()") + "; style-src 'self' " +
+				csp.Hash("body{}") + "; object-src 'none'; base-uri 'self'",
+		},
+		{
+			name:   "handler policy",
+			resp:   response.NewResponse().Layout("base").Path("home").Header("Content-Security-Policy", "default-src 'none'"),
+			header: "Content-Security-Policy",
+			want:   "default-src 'none'",
+		},
+		{
+			name:   "not HTML",
+			resp:   response.NewResponse().Layout("base").Path("home").Header("Content-Type", "text/plain"),
+			header: "Content-Security-Policy",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			adapter.Render(w, httptest.NewRequest(http.MethodGet, "/", nil), tt.resp)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+			if got := w.Header().Get(tt.header); got != tt.want {
+				t.Errorf("unexpected policy:\ngot  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHashMiddleware_ReportOnly(t *testing.T) {
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{
+			"layouts/base.html": {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+			"views/home.html":   {Data: []byte(`{{define "page:main"}}<script>a()</script>{{end}}`)},
+		}},
+		RenderMiddleware: []hyperview.RenderMiddleware{
+			csp.HashMiddleware(csp.HashOptions{Policy: "script-src {script-hashes}", ReportOnly: true}),
+		},
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	adapter.Render(w, httptest.NewRequest(http.MethodGet, "/", nil), response.NewResponse().Layout("base").Path("home"))
+	if _, ok := w.Header()["Content-Security-Policy"]; ok {
+		t.Error("expected no enforced policy")
+	}
+	if got, want := w.Header().Get("Content-Security-Policy-Report-Only"), "script-src "+csp.Hash("a()"); got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+// TestHashMiddleware_Injected checks the scripts and styles inserted by the data of a render, or holding actions, are
+// left out of the policy, so browsers block them.
+func TestHashMiddleware_Injected(t *testing.T) {
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{
+			"layouts/base.html": {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+			"views/home.html": {Data: []byte(`{{define "page:main"}}<script>/* setup */init()</script>` +
+				`<script>greet({{.Name}})</script><style>p{}</style><div>{{safeHTML .Comment}}</div>{{end}}`)},
+		}},
+		RenderMiddleware: []hyperview.RenderMiddleware{csp.HashMiddleware(csp.HashOptions{Policy: "script-src {script-hashes}; style-src {style-hashes}"})},
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	adapter.Render(w, httptest.NewRequest(http.MethodGet, "/", nil), response.NewResponse().Layout("base").Path("home").Data(map[string]any{
+		"Name":    "ann",
+		"Comment": `<script>alert(document.cookie)</script><style>body{display:none}</style>`,
+	}))
+	if !strings.Contains(w.Body.String(), "<script>alert(document.cookie)</script>") {
+		t.Fatalf("expected the injected script in the body, got %s", w.Body.String())
+	}
+
+	// html/template replaces the comments of scripts by a space, so the script of the template is hashed as rendered
+	want := "script-src " + csp.Hash(" init()") + "; style-src " + csp.Hash("p{}")
+	if got := w.Header().Get("Content-Security-Policy"); got != want {
+		t.Errorf("expected the policy to only allow the elements of the template:\ngot  %s\nwant %s", got, want)
+	}
+}
@@ -0,0 +1,186 @@
+// Package csrf protects forms rendered by HyperView against cross-site request forgery, using the double-submit
+// cookie pattern.
+//
+// The middleware issues a random token in a cookie and makes it available to templates through the csrfField and
+// csrfToken functions, and as the CSRFToken slot of the view data. Requests with unsafe methods (POST, PUT, PATCH,
+// DELETE) must echo the token in a form field or a request header, which a cross-site attacker cannot read.
+package csrf
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/response"
+)
+
+const (
+	// DefaultCookieName is the default name of the cookie holding the token.
+	DefaultCookieName = "csrf_token"
+	// DefaultFieldName is the default name of the form field holding the token.
+	DefaultFieldName = "csrf_token"
+	// DefaultHeaderName is the default name of the request header holding the token, for requests that are not form
+	// submissions (e.g. HTMX requests with an hx-headers attribute).
+	DefaultHeaderName = "X-CSRF-Token"
+)
+
+var (
+	// ErrMissingToken is returned when an unsafe request does not include a token.
+	ErrMissingToken = errors.New("csrf: missing token")
+	// ErrInvalidToken is returned when the token of an unsafe request does not match the cookie.
+	ErrInvalidToken = errors.New("csrf: invalid token")
+)
+
+// Options are the options for the CSRF middleware.
+type Options struct {
+	// CookieName is the name of the cookie holding the token. Default is DefaultCookieName.
+	CookieName string
+	// FieldName is the name of the form field holding the token. Default is DefaultFieldName.
+	FieldName string
+	// HeaderName is the name of the request header holding the token. Default is DefaultHeaderName.
+	HeaderName string
+	// Secure sets the Secure attribute of the cookie. It should be enabled in production.
+	Secure bool
+	// MaxAge is the lifetime of the cookie in seconds. Default is a session cookie.
+	MaxAge int
+	// ErrorHandler is called when the token is missing or invalid. The error is available with Error.
+	// Default responds with 403 Forbidden.
+	ErrorHandler http.Handler
+}
+
+type contextKey int
+
+const (
+	tokenKey contextKey = iota
+	errorKey
+)
+
+// Token returns the CSRF token of the request, or an empty string if the middleware did not run.
+func Token(r *http.Request) string {
+	token, _ := r.Context().Value(tokenKey).(string)
+	return token
+}
+
+// Error returns the reason the request was rejected, for use in a custom ErrorHandler.
+func Error(r *http.Request) error {
+	err, _ := r.Context().Value(errorKey).(error)
+	return err
+}
+
+// Middleware returns a middleware issuing and validating CSRF tokens. The token is added to the request context,
+// along with the template functions bound to it (see Funcs).
+func Middleware(opts Options) func(http.Handler) http.Handler {
+	if opts.CookieName == "" {
+		opts.CookieName = DefaultCookieName
+	}
+	if opts.FieldName == "" {
+		opts.FieldName = DefaultFieldName
+	}
+	if opts.HeaderName == "" {
+		opts.HeaderName = DefaultHeaderName
+	}
+	if opts.ErrorHandler == nil {
+		opts.ErrorHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, Error(r).Error(), http.StatusForbidden)
+		})
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var token string
+			if cookie, err := r.Cookie(opts.CookieName); err == nil && cookie.Value != "" {
+				token = cookie.Value
+			} else {
+				token, err = newToken()
+				if err != nil {
+					http.Error(w, fmt.Errorf("error generating CSRF token: %w", err).Error(), http.StatusInternalServerError)
+					return
+				}
+
+				http.SetCookie(w, &http.Cookie{
+					Name:     opts.CookieName,
+					Value:    token,
+					Path:     "/",
+					MaxAge:   opts.MaxAge,
+					Secure:   opts.Secure,
+					HttpOnly: true,
+					SameSite: http.SameSiteLaxMode,
+				})
+			}
+
+			if !isSafeMethod(r.Method) {
+				if err := verify(r, token, opts); err != nil {
+					opts.ErrorHandler.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), errorKey, err)))
+					return
+				}
+			}
+
+			ctx := context.WithValue(r.Context(), tokenKey, token)
+			ctx = hyperview.ContextWithFuncs(ctx, Funcs(token, opts.FieldName))
+			ctx = response.ContextWithCSRFToken(ctx, token)
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// Funcs returns the template functions bound to the given token:
+//
+//   - csrfToken: returns the token, e.g. <meta name="csrf-token" content="{{csrfToken}}">
+//   - csrfField: returns a hidden form field holding the token, e.g. <form method="post">{{csrfField}}...</form>
+//
+// Pass Funcs("", "") to the template adapter's RequestFuncs option, and use Middleware to bind them to the token of
+// each request. An empty field name defaults to DefaultFieldName.
+func Funcs(token, fieldName string) template.FuncMap {
+	if fieldName == "" {
+		fieldName = DefaultFieldName
+	}
+
+	return template.FuncMap{
+		"csrfToken": func() string {
+			return token
+		},
+		"csrfField": func() template.HTML {
+			return template.HTML(fmt.Sprintf(`<input type="hidden" name="%s" value="%s">`,
+				template.HTMLEscapeString(fieldName), template.HTMLEscapeString(token)))
+		},
+	}
+}
+
+func verify(r *http.Request, token string, opts Options) error {
+	submitted := r.Header.Get(opts.HeaderName)
+	if submitted == "" {
+		submitted = r.PostFormValue(opts.FieldName)
+	}
+
+	if submitted == "" {
+		return ErrMissingToken
+	}
+	if subtle.ConstantTimeCompare([]byte(submitted), []byte(token)) != 1 {
+		return ErrInvalidToken
+	}
+
+	return nil
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
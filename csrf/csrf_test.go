@@ -0,0 +1,113 @@
+package csrf_test
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/csrf"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func TestMiddleware_RendersField(t *testing.T) {
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{
+			"layouts/base.html": {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+			"views/form.html":   {Data: []byte(`{{define "page:main"}}<form>{{csrfField}}</form>{{csrfToken}}|{{.View.CSRFToken}}{{end}}`)},
+		}},
+		RequestFuncs: csrf.Funcs("", ""),
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+
+	handler := csrf.Middleware(csrf.Options{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		adapter.Render(w, r, response.NewResponse().Layout("base").Path("form"))
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != csrf.DefaultCookieName || !cookies[0].HttpOnly {
+		t.Fatalf("expected an HttpOnly token cookie, got %v", cookies)
+	}
+
+	token := cookies[0].Value
+	want := `<form><input type="hidden" name="csrf_token" value="` + token + `"></form>` + token + "|" + token
+	if got := w.Body.String(); got != want {
+		t.Errorf("unexpected body:\ngot  %s\nwant %s", got, want)
+	}
+}
+
+func TestMiddleware_Validation(t *testing.T) {
+	const token = "known-token"
+
+	tests := []struct {
+		name    string
+		method  string
+		field   string
+		header  string
+		cookie  bool
+		wantErr error
+	}{
+		{"safe method without token", http.MethodGet, "", "", true, nil},
+		{"valid form field", http.MethodPost, token, "", true, nil},
+		{"valid header", http.MethodDelete, "", token, true, nil},
+		{"missing token", http.MethodPost, "", "", true, csrf.ErrMissingToken},
+		{"invalid token", http.MethodPost, "forged", "", true, csrf.ErrInvalidToken},
+		{"no cookie", http.MethodPost, token, "", false, csrf.ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rejected error
+			handler := csrf.Middleware(csrf.Options{
+				ErrorHandler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					rejected = csrf.Error(r)
+					w.WriteHeader(http.StatusForbidden)
+				}),
+			})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+			form := url.Values{}
+			if tt.field != "" {
+				form.Set(csrf.DefaultFieldName, tt.field)
+			}
+			r := httptest.NewRequest(tt.method, "/", strings.NewReader(form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if tt.header != "" {
+				r.Header.Set(csrf.DefaultHeaderName, tt.header)
+			}
+			if tt.cookie {
+				r.AddCookie(&http.Cookie{Name: csrf.DefaultCookieName, Value: token})
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if !errors.Is(rejected, tt.wantErr) {
+				t.Errorf("rejected with %v, want %v", rejected, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMiddleware_DefaultErrorHandler(t *testing.T) {
+	handler := csrf.Middleware(csrf.Options{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("handler should not be called")
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", nil))
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("unexpected status: got %d, want %d", w.Code, http.StatusForbidden)
+	}
+}
@@ -0,0 +1,255 @@
+// Package datastar streams the server-sent events of the Datastar hypermedia library (https://data-star.dev): events
+// patching the elements of the page with rendered fragments, and events patching the signals of the page.
+//
+//	sse := datastar.NewSSE(w, r)
+//	_ = sse.PatchPartial(adapter, "@cart", cart, datastar.ElementOptions{})
+//	_ = sse.PatchSignals(map[string]any{"count": cart.Count()}, datastar.SignalOptions{})
+package datastar
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// ContentType is the content type of Datastar event streams.
+	ContentType = "text/event-stream"
+	// RequestHeader is the header Datastar sets on the requests it sends.
+	RequestHeader = "Datastar-Request"
+	// SignalsParam is the query parameter holding the signals of GET requests.
+	SignalsParam = "datastar"
+
+	// EventPatchElements is the event patching elements of the page, formerly datastar-merge-fragments.
+	EventPatchElements = "datastar-patch-elements"
+	// EventPatchSignals is the event patching the signals of the page, formerly datastar-merge-signals.
+	EventPatchSignals = "datastar-patch-signals"
+
+	// DefaultRetry is the reconnection delay Datastar uses unless an event sets another one.
+	DefaultRetry = time.Second
+)
+
+// Mode is the way patched elements are merged into the page.
+type Mode string
+
+const (
+	// ModeOuter morphs the elements matching the patched elements by ID, or the selector, with them. It is the
+	// default.
+	ModeOuter Mode = "outer"
+	// ModeInner morphs the content of the target with the patched elements.
+	ModeInner Mode = "inner"
+	// ModeReplace replaces the target with the patched elements, without morphing.
+	ModeReplace Mode = "replace"
+	// ModePrepend inserts the patched elements at the start of the target.
+	ModePrepend Mode = "prepend"
+	// ModeAppend inserts the patched elements at the end of the target.
+	ModeAppend Mode = "append"
+	// ModeBefore inserts the patched elements before the target.
+	ModeBefore Mode = "before"
+	// ModeAfter inserts the patched elements after the target.
+	ModeAfter Mode = "after"
+	// ModeRemove removes the target.
+	ModeRemove Mode = "remove"
+)
+
+// ElementOptions are the options of an event patching elements.
+type ElementOptions struct {
+	// Selector is the CSS selector of the target. Default targets the elements with the IDs of the patched elements.
+	Selector string
+	// Mode is the way the elements are merged. Default is ModeOuter.
+	Mode Mode
+	// UseViewTransition patches the elements within a view transition, where the browser supports them.
+	UseViewTransition bool
+	// EventID is the ID of the event, if any.
+	EventID string
+	// Retry is the reconnection delay sent with the event. Default is DefaultRetry, which is not sent.
+	Retry time.Duration
+}
+
+// SignalOptions are the options of an event patching signals.
+type SignalOptions struct {
+	// OnlyIfMissing only sets the signals the page does not have yet, e.g. to initialize them.
+	OnlyIfMissing bool
+	// EventID is the ID of the event, if any.
+	EventID string
+	// Retry is the reconnection delay sent with the event. Default is DefaultRetry, which is not sent.
+	Retry time.Duration
+}
+
+// Renderer renders named partials, like the template adapter.
+type Renderer interface {
+	RenderPartial(w io.Writer, name string, data any) error
+}
+
+// SSE writes Datastar events to the response of a request. It is safe for concurrent use, so several goroutines can
+// send events to the same stream.
+type SSE struct {
+	mu sync.Mutex
+	w  http.ResponseWriter
+	rc *http.ResponseController
+	r  *http.Request
+}
+
+// NewSSE starts the event stream of the response: it writes the headers of event streams and flushes them, so the
+// browser starts processing events before the first one is sent.
+func NewSSE(w http.ResponseWriter, r *http.Request) *SSE {
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("Cache-Control", "no-cache")
+	if r.ProtoMajor == 1 {
+		w.Header().Set("Connection", "keep-alive")
+	}
+	w.WriteHeader(http.StatusOK)
+
+	sse := &SSE{w: w, rc: http.NewResponseController(w), r: r}
+	_ = sse.flush()
+	return sse
+}
+
+// Done returns a channel closed when the client disconnects, so long-lived streams know when to stop.
+func (s *SSE) Done() <-chan struct{} {
+	return s.r.Context().Done()
+}
+
+// PatchElements sends an event patching the page with the HTML elements. It returns an error if the selector holds a
+// line break, which would end its line of the event.
+func (s *SSE) PatchElements(elements string, opts ElementOptions) error {
+	if strings.ContainsAny(opts.Selector, "\r\n") {
+		return errors.New("datastar: the selector holds a line break")
+	}
+
+	var data []string
+	if opts.Selector != "" {
+		data = append(data, "selector "+opts.Selector)
+	}
+	if opts.Mode != "" && opts.Mode != ModeOuter {
+		data = append(data, "mode "+string(opts.Mode))
+	}
+	if opts.UseViewTransition {
+		data = append(data, "useViewTransition true")
+	}
+	data = appendLines(data, "elements ", elements)
+
+	return s.send(EventPatchElements, opts.EventID, opts.Retry, data)
+}
+
+// PatchPartial renders the named partial with data, e.g. with the template adapter, and sends an event patching the
+// page with the rendered elements.
+func (s *SSE) PatchPartial(renderer Renderer, name string, data any, opts ElementOptions) error {
+	var buf bytes.Buffer
+	if err := renderer.RenderPartial(&buf, name, data); err != nil {
+		return fmt.Errorf("error rendering partial %s: %w", name, err)
+	}
+	return s.PatchElements(buf.String(), opts)
+}
+
+// RemoveElements sends an event removing the elements matching the selector.
+func (s *SSE) RemoveElements(selector string) error {
+	return s.PatchElements("", ElementOptions{Selector: selector, Mode: ModeRemove})
+}
+
+// PatchSignals sends an event patching the signals of the page, merged into them like a JSON merge patch: null
+// values remove signals. The signals are marshaled to JSON, unless they are JSON already as a json.RawMessage.
+func (s *SSE) PatchSignals(signals any, opts SignalOptions) error {
+	raw, ok := signals.(json.RawMessage)
+	if !ok {
+		var err error
+		if raw, err = json.Marshal(signals); err != nil {
+			return fmt.Errorf("error encoding signals: %w", err)
+		}
+	}
+
+	var data []string
+	if opts.OnlyIfMissing {
+		data = append(data, "onlyIfMissing true")
+	}
+	data = appendLines(data, "signals ", string(raw))
+
+	return s.send(EventPatchSignals, opts.EventID, opts.Retry, data)
+}
+
+// send writes and flushes an event with its data lines. It returns an error if the event ID holds a line break, which
+// would end its line of the event.
+func (s *SSE) send(event, id string, retry time.Duration, data []string) error {
+	if strings.ContainsAny(id, "\r\n") {
+		return errors.New("datastar: the event ID holds a line break")
+	}
+
+	var b strings.Builder
+	b.WriteString("event: " + event + "\n")
+	if id != "" {
+		b.WriteString("id: " + id + "\n")
+	}
+	if retry > 0 && retry != DefaultRetry {
+		b.WriteString("retry: " + strconv.FormatInt(retry.Milliseconds(), 10) + "\n")
+	}
+	for _, line := range data {
+		b.WriteString("data: " + line + "\n")
+	}
+	b.WriteString("\n")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := io.WriteString(s.w, b.String()); err != nil {
+		return err
+	}
+	return s.flush()
+}
+
+// flush flushes the response, if the writer supports it.
+func (s *SSE) flush() error {
+	if err := s.rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	return nil
+}
+
+// lineBreaks normalizes the line breaks of event streams, \r\n, \r and \n, to \n.
+var lineBreaks = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+// appendLines appends a data line per line of value, each prefixed with the name of the field, as events end at the
+// first empty line. Lines end at any line break of event streams, including a bare \r, which html/template does not
+// escape, so the content of a value cannot end the event early or add fields to it.
+func appendLines(data []string, prefix, value string) []string {
+	if value == "" {
+		return data
+	}
+	for _, line := range strings.Split(lineBreaks.Replace(value), "\n") {
+		data = append(data, prefix+line)
+	}
+	return data
+}
+
+// IsDatastarRequest reports whether the request was sent by Datastar.
+func IsDatastarRequest(r *http.Request) bool {
+	return r.Header.Get(RequestHeader) == "true"
+}
+
+// ReadSignals decodes the signals Datastar sends with the request into v: the datastar query parameter of GET
+// requests, and the JSON body of the others.
+func ReadSignals(r *http.Request, v any) error {
+	var src io.Reader
+	if r.Method == http.MethodGet {
+		param := r.URL.Query().Get(SignalsParam)
+		if param == "" {
+			return errors.New("datastar: no signals in the request")
+		}
+		src = strings.NewReader(param)
+	} else {
+		if r.Body == nil {
+			return errors.New("datastar: no signals in the request")
+		}
+		src = r.Body
+	}
+
+	if err := json.NewDecoder(src).Decode(v); err != nil {
+		return fmt.Errorf("datastar: error decoding signals: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,251 @@
+package datastar_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hypergopher/hyperview/datastar"
+)
+
+func TestNewSSE(t *testing.T) {
+	w := httptest.NewRecorder()
+	datastar.NewSSE(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if got := w.Header().Get("Content-Type"); got != datastar.ContentType {
+		t.Errorf("unexpected content type: %s", got)
+	}
+	if got := w.Header().Get("Cache-Control"); got != "no-cache" {
+		t.Errorf("unexpected cache control: %s", got)
+	}
+	if !w.Flushed {
+		t.Error("expected the headers to be flushed")
+	}
+}
+
+func TestSSE_PatchElements(t *testing.T) {
+	tests := []struct {
+		name     string
+		elements string
+		opts     datastar.ElementOptions
+		want     string
+	}{
+		{
+			name:     "defaults",
+			elements: `<div id="cart">2 items</div>`,
+			want:     "event: datastar-patch-elements\ndata: elements <div id=\"cart\">2 items</div>\n\n",
+		},
+		{
+			name:     "multiple lines",
+			elements: "<ul id=\"list\">\n\n<li>a</li>\r\n</ul>",
+			want:     "event: datastar-patch-elements\ndata: elements <ul id=\"list\">\ndata: elements \ndata: elements <li>a</li>\ndata: elements </ul>\n\n",
+		},
+		{
+			name:     "bare carriage returns",
+			elements: "<p>hi\r\rdata: selector body\rdata: mode remove</p>",
+			want: "event: datastar-patch-elements\ndata: elements <p>hi\ndata: elements \ndata: elements data: selector body\n" +
+				"data: elements data: mode remove</p>\n\n",
+		},
+		{
+			name:     "options",
+			elements: "<li>new</li>",
+			opts: datastar.ElementOptions{
+				Selector:          "#list",
+				Mode:              datastar.ModeAppend,
+				UseViewTransition: true,
+				EventID:           "42",
+				Retry:             5 * time.Second,
+			},
+			want: "event: datastar-patch-elements\nid: 42\nretry: 5000\ndata: selector #list\ndata: mode append\ndata: useViewTransition true\ndata: elements <li>new</li>\n\n",
+		},
+		{
+			name:     "default mode and retry",
+			elements: "<p id=\"p\"></p>",
+			opts:     datastar.ElementOptions{Mode: datastar.ModeOuter, Retry: datastar.DefaultRetry},
+			want:     "event: datastar-patch-elements\ndata: elements <p id=\"p\"></p>\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			sse := datastar.NewSSE(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			if err := sse.PatchElements(tt.elements, tt.opts); err != nil {
+				t.Fatalf("error patching elements: %v", err)
+			}
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("unexpected event:\ngot  %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSSE_LineBreaksInFields(t *testing.T) {
+	tests := []struct {
+		name string
+		opts datastar.ElementOptions
+	}{
+		{name: "selector", opts: datastar.ElementOptions{Selector: "#list\rdata: mode remove"}},
+		{name: "event ID", opts: datastar.ElementOptions{EventID: "1\nevent: other"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			sse := datastar.NewSSE(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			if err := sse.PatchElements("<p></p>", tt.opts); err == nil {
+				t.Error("expected an error for the line break")
+			}
+			if got := w.Body.String(); got != "" {
+				t.Errorf("expected no event to be sent, got %q", got)
+			}
+		})
+	}
+}
+
+func TestSSE_RemoveElements(t *testing.T) {
+	w := httptest.NewRecorder()
+	sse := datastar.NewSSE(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if err := sse.RemoveElements("#toast"); err != nil {
+		t.Fatalf("error removing elements: %v", err)
+	}
+
+	if got, want := w.Body.String(), "event: datastar-patch-elements\ndata: selector #toast\ndata: mode remove\n\n"; got != want {
+		t.Errorf("unexpected event:\ngot  %q\nwant %q", got, want)
+	}
+}
+
+type rendererFunc func(w io.Writer, name string, data any) error
+
+func (f rendererFunc) RenderPartial(w io.Writer, name string, data any) error {
+	return f(w, name, data)
+}
+
+func TestSSE_PatchPartial(t *testing.T) {
+	renderer := rendererFunc(func(w io.Writer, name string, data any) error {
+		if name != "@cart" {
+			return errors.New("partial not found: " + name)
+		}
+		_, err := fmt.Fprintf(w, `<div id="cart">%v items</div>`, data)
+		return err
+	})
+
+	w := httptest.NewRecorder()
+	sse := datastar.NewSSE(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if err := sse.PatchPartial(renderer, "@cart", 3, datastar.ElementOptions{}); err != nil {
+		t.Fatalf("error patching partial: %v", err)
+	}
+	if got, want := w.Body.String(), "event: datastar-patch-elements\ndata: elements <div id=\"cart\">3 items</div>\n\n"; got != want {
+		t.Errorf("unexpected event:\ngot  %q\nwant %q", got, want)
+	}
+
+	// Render errors send no event
+	if err := sse.PatchPartial(renderer, "@missing", nil, datastar.ElementOptions{}); err == nil {
+		t.Error("expected an error")
+	}
+	if strings.Count(w.Body.String(), "event:") != 1 {
+		t.Errorf("unexpected events: %q", w.Body.String())
+	}
+}
+
+func TestSSE_PatchSignals(t *testing.T) {
+	tests := []struct {
+		name    string
+		signals any
+		opts    datastar.SignalOptions
+		want    string
+	}{
+		{
+			name:    "value",
+			signals: map[string]any{"count": 2, "user": map[string]any{"name": "Ann"}},
+			want:    "event: datastar-patch-signals\ndata: signals {\"count\":2,\"user\":{\"name\":\"Ann\"}}\n\n",
+		},
+		{
+			name:    "raw JSON",
+			signals: json.RawMessage("{\n  \"open\": true\n}"),
+			opts:    datastar.SignalOptions{OnlyIfMissing: true, EventID: "1"},
+			want:    "event: datastar-patch-signals\nid: 1\ndata: onlyIfMissing true\ndata: signals {\ndata: signals   \"open\": true\ndata: signals }\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			sse := datastar.NewSSE(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			if err := sse.PatchSignals(tt.signals, tt.opts); err != nil {
+				t.Fatalf("error patching signals: %v", err)
+			}
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("unexpected event:\ngot  %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+
+	w := httptest.NewRecorder()
+	if err := datastar.NewSSE(w, httptest.NewRequest(http.MethodGet, "/", nil)).PatchSignals(func() {}, datastar.SignalOptions{}); err == nil {
+		t.Error("expected an error encoding a function")
+	}
+}
+
+func TestReadSignals(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     *http.Request
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "query of GET requests",
+			req:  httptest.NewRequest(http.MethodGet, "/?datastar="+url.QueryEscape(`{"search":"go"}`), nil),
+			want: "go",
+		},
+		{
+			name: "body of other requests",
+			req:  httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"search":"templates"}`)),
+			want: "templates",
+		},
+		{
+			name:    "missing signals",
+			req:     httptest.NewRequest(http.MethodGet, "/", nil),
+			wantErr: true,
+		},
+		{
+			name:    "invalid JSON",
+			req:     httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{`)),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var signals struct {
+				Search string `json:"search"`
+			}
+			err := datastar.ReadSignals(tt.req, &signals)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if signals.Search != tt.want {
+				t.Errorf("unexpected signals: got %q, want %q", signals.Search, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsDatastarRequest(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	if datastar.IsDatastarRequest(r) {
+		t.Error("expected a plain request")
+	}
+	r.Header.Set(datastar.RequestHeader, "true")
+	if !datastar.IsDatastarRequest(r) {
+		t.Error("expected a Datastar request")
+	}
+}
@@ -0,0 +1,362 @@
+// Package docsite generates a static documentation site of the view layer of an application: a page for each layout,
+// partial and view, with its doc comment, the templates it defines, the data it accesses, the templates it uses and
+// is used by, the functions it calls and the gallery examples of its partials. The site is rendered by a HyperView
+// template adapter, like the application itself.
+//
+// A doc comment is a comment at the start of a template file:
+//
+//	{{/* Card shows a product summary, with an optional footer slot. */}}
+//	{{define "@card"}}...{{end}}
+package docsite
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/analysis"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/gallery"
+	"github.com/hypergopher/hyperview/response"
+)
+
+//go:embed templates
+var templatesFS embed.FS
+
+// Options are the options for Generate.
+type Options struct {
+	// Title is the title of the site. Default is "View reference".
+	Title string
+	// Gallery holds the examples rendered on the pages of the partials they belong to. Optional.
+	Gallery *gallery.Gallery
+}
+
+// Link is a link to another page of the site.
+type Link struct {
+	Name string
+	Href string // empty for templates defined outside the site, e.g. by the application's code
+}
+
+// Example is a gallery example rendered on the page of a partial.
+type Example struct {
+	Partial string
+	Name    string
+	HTML    template.HTML
+	Err     string // the error rendering the example, if any
+}
+
+// Page documents a template file.
+type Page struct {
+	// Path is the path of the template file, e.g. partials/card.html.
+	Path string
+	// Kind is "layout", "partial" or "view".
+	Kind string
+	// Doc is the doc comment of the file.
+	Doc string
+	// Defines are the templates defined by the file.
+	Defines []string
+	// Data is a Go struct skeleton of the data accessed by a view, as inferred by the analysis package.
+	Data string
+	// Uses are the templates the file calls, linking to the files defining them.
+	Uses []Link
+	// UsedBy are the files calling the templates defined by the file.
+	UsedBy []Link
+	// Funcs are the functions the file calls.
+	Funcs []string
+	// Examples are the gallery examples of the templates defined by the file.
+	Examples []Example
+
+	href string // path of the page in the site
+}
+
+// Generate writes the documentation site of the templates of set to dir: index.html, a page for each template file
+// under the same path with a .html extension, and graph.dot, the dependency graph of the files in Graphviz format.
+func Generate(set *analysis.Set, dir string, opts Options) error {
+	if opts.Title == "" {
+		opts.Title = "View reference"
+	}
+
+	pages := Pages(set)
+	if opts.Gallery != nil {
+		for _, page := range pages {
+			page.Examples = renderExamples(opts.Gallery, page.Defines)
+		}
+	}
+
+	sub, err := fs.Sub(templatesFS, "templates")
+	if err != nil {
+		return err
+	}
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: sub},
+	})
+	if err := adapter.Init(); err != nil {
+		return fmt.Errorf("error loading docs templates: %w", err)
+	}
+
+	for _, page := range pages {
+		root := relativeRoot(page.href)
+		data := map[string]any{
+			"SiteTitle": opts.Title,
+			"Heading":   page.Path,
+			"Root":      root,
+			"Page":      withRoot(page, root),
+		}
+		if err := writePage(adapter, filepath.Join(dir, filepath.FromSlash(page.href)), "template", data); err != nil {
+			return err
+		}
+	}
+
+	index := map[string]any{
+		"SiteTitle": opts.Title,
+		"Heading":   opts.Title,
+		"Root":      "",
+		"Sections":  sections(pages),
+	}
+	if err := writePage(adapter, filepath.Join(dir, "index.html"), "index", index); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, "graph.dot"), []byte(Graph(pages)), 0o644)
+}
+
+// Pages returns the pages documenting the template files of set, in the order of Set.Templates, with the
+// dependencies between the files resolved. Examples are not rendered.
+func Pages(set *analysis.Set) []*Page {
+	templates := set.Templates()
+
+	// Views all define the same page templates, so only the common templates are linked to
+	definedBy := make(map[string]string)
+	for _, tmpl := range set.Common {
+		for _, name := range definedNames(tmpl) {
+			definedBy[name] = tmpl.Path
+		}
+	}
+
+	refs := make(map[string][]analysis.Reference)
+	for _, ref := range set.Index().References() {
+		refs[ref.Path] = append(refs[ref.Path], ref)
+	}
+
+	pages := make([]*Page, 0, len(templates))
+	byPath := make(map[string]*Page, len(templates))
+	for _, tmpl := range templates {
+		page := &Page{
+			Path:    tmpl.Path,
+			Kind:    kind(tmpl.Path),
+			Doc:     tmpl.Doc(),
+			Defines: definedNames(tmpl),
+			href:    strings.TrimSuffix(tmpl.Path, path.Ext(tmpl.Path)) + ".html",
+		}
+		if page.Kind == "view" {
+			if root, err := set.InferView(strings.TrimSuffix(tmpl.Path, path.Ext(tmpl.Path))); err == nil {
+				page.Data = root.GoStruct("Data")
+			}
+		}
+		pages = append(pages, page)
+		byPath[page.Path] = page
+	}
+
+	usedBy := make(map[string]map[string]bool)
+	for _, page := range pages {
+		uses := make(map[string]bool)
+		funcs := make(map[string]bool)
+		for _, ref := range refs[page.Path] {
+			switch {
+			case ref.Kind == analysis.RefFunc && ref.Name != "renderComponent":
+				funcs[ref.Name] = true
+			case ref.Kind == analysis.RefTemplate && !analysis.IsHoisted(ref.Name):
+				if definedBy[ref.Name] == page.Path {
+					continue
+				}
+				uses[ref.Name] = true
+				if target, ok := definedBy[ref.Name]; ok {
+					if usedBy[target] == nil {
+						usedBy[target] = make(map[string]bool)
+					}
+					usedBy[target][page.Path] = true
+				}
+			}
+		}
+
+		for _, name := range sortedSet(uses) {
+			link := Link{Name: name}
+			if target, ok := definedBy[name]; ok {
+				link.Href = byPath[target].href
+			}
+			page.Uses = append(page.Uses, link)
+		}
+		page.Funcs = sortedSet(funcs)
+	}
+
+	for _, page := range pages {
+		for _, caller := range sortedSet(usedBy[page.Path]) {
+			page.UsedBy = append(page.UsedBy, Link{Name: caller, Href: byPath[caller].href})
+		}
+	}
+
+	return pages
+}
+
+// Graph returns the dependency graph of the pages in Graphviz DOT format, with an edge from each file to the files
+// defining the templates it uses.
+func Graph(pages []*Page) string {
+	var b strings.Builder
+	b.WriteString("digraph views {\n")
+	for _, page := range pages {
+		for _, caller := range page.UsedBy {
+			fmt.Fprintf(&b, "\t%q -> %q;\n", caller.Name, page.Path)
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// definedNames returns the templates defined by a file, leaving out the file itself and component slots.
+func definedNames(tmpl *analysis.Template) []string {
+	var names []string
+	for name := range tmpl.Trees {
+		if name != path.Base(tmpl.Path) && !analysis.IsHoisted(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func kind(filePath string) string {
+	switch {
+	case strings.HasPrefix(filePath, constants.LayoutsDir+"/"):
+		return "layout"
+	case strings.HasPrefix(filePath, constants.PartialsDir+"/"):
+		return "partial"
+	}
+	return "view"
+}
+
+// renderExamples renders the gallery examples of the named templates.
+func renderExamples(g *gallery.Gallery, names []string) []Example {
+	var examples []Example
+	for _, name := range names {
+		for i, example := range g.Examples(name) {
+			buf := new(bytes.Buffer)
+			rendered := Example{Partial: name, Name: example.Name}
+			if err := g.RenderExample(buf, name, i); err != nil {
+				rendered.Err = err.Error()
+			} else {
+				rendered.HTML = template.HTML(buf.String())
+			}
+			examples = append(examples, rendered)
+		}
+	}
+	return examples
+}
+
+// section is a group of pages listed on the index.
+type section struct {
+	Title string
+	Pages []indexEntry
+}
+
+type indexEntry struct {
+	Name    string
+	Href    string
+	Summary string
+}
+
+func sections(pages []*Page) []section {
+	groups := []section{{Title: "Layouts"}, {Title: "Partials"}, {Title: "Views"}}
+	for _, page := range pages {
+		i := 2
+		switch page.Kind {
+		case "layout":
+			i = 0
+		case "partial":
+			i = 1
+		}
+		summary, _, _ := strings.Cut(page.Doc, "\n")
+		groups[i].Pages = append(groups[i].Pages, indexEntry{Name: page.Path, Href: page.href, Summary: summary})
+	}
+	return groups
+}
+
+// relativeRoot returns the relative path from a page of the site to the root of the site, e.g. "../" for
+// views/home.html.
+func relativeRoot(href string) string {
+	return strings.Repeat("../", strings.Count(href, "/"))
+}
+
+// withRoot returns a copy of the page with its links relative to root.
+func withRoot(page *Page, root string) *Page {
+	p := *page
+	p.Uses = relativeLinks(page.Uses, root)
+	p.UsedBy = relativeLinks(page.UsedBy, root)
+	return &p
+}
+
+func relativeLinks(links []Link, root string) []Link {
+	relative := make([]Link, len(links))
+	for i, link := range links {
+		relative[i] = link
+		if link.Href != "" {
+			relative[i].Href = root + link.Href
+		}
+	}
+	return relative
+}
+
+// writePage renders the docs view with data and writes it to file.
+func writePage(adapter *hyperview.TemplateAdapter, file, view string, data map[string]any) error {
+	r, err := http.NewRequest(http.MethodGet, "/", nil)
+	if err != nil {
+		return err
+	}
+
+	w := &pageWriter{header: make(http.Header), status: http.StatusOK}
+	adapter.Render(w, r, response.NewResponse().Layout("base").Path(view).Data(data))
+	if w.status != http.StatusOK {
+		return fmt.Errorf("error rendering %s: %s", file, strings.TrimSpace(w.body.String()))
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(file, w.body.Bytes(), 0o644)
+}
+
+// pageWriter is an http.ResponseWriter capturing a rendered page.
+type pageWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *pageWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *pageWriter) WriteHeader(status int) {
+	w.status = status
+}
+
+func (w *pageWriter) Write(p []byte) (int, error) {
+	return w.body.Write(p)
+}
+
+func sortedSet(set map[string]bool) []string {
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,129 @@
+package docsite_test
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/analysis"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/docsite"
+	"github.com/hypergopher/hyperview/gallery"
+)
+
+var docsTestFiles = fstest.MapFS{
+	"layouts/base.html": {Data: []byte(`{{define "layout:base"}}<main>{{template "page:main" .}}</main>{{end}}`)},
+	"partials/card.html": {Data: []byte("{{/* Card shows a product summary. */}}\n" +
+		`{{define "@card"}}<div class="card">{{.Prop "Title"}}</div>{{end}}`)},
+	"views/products/show.html": {Data: []byte(`{{define "page:main"}}{{upper .Product.Name}}` +
+		`{{component "@card" (dict "Title" .Product.Name)}}{{end}}{{end}}`)},
+}
+
+func TestPages(t *testing.T) {
+	set, err := analysis.Load(docsTestFiles, ".html")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pages := docsite.Pages(set)
+	byPath := make(map[string]*docsite.Page)
+	for _, page := range pages {
+		byPath[page.Path] = page
+	}
+
+	card := byPath["partials/card.html"]
+	if card.Kind != "partial" || card.Doc != "Card shows a product summary." || strings.Join(card.Defines, ",") != "@card" {
+		t.Errorf("unexpected partial page: %+v", card)
+	}
+	if len(card.UsedBy) != 1 || card.UsedBy[0] != (docsite.Link{Name: "views/products/show.html", Href: "views/products/show.html"}) {
+		t.Errorf("unexpected callers of the partial: %+v", card.UsedBy)
+	}
+
+	show := byPath["views/products/show.html"]
+	if len(show.Uses) != 1 || show.Uses[0] != (docsite.Link{Name: "@card", Href: "partials/card.html"}) {
+		t.Errorf("unexpected uses of the view: %+v", show.Uses)
+	}
+	if strings.Join(show.Funcs, ",") != "dict,upper" {
+		t.Errorf("unexpected functions of the view: %v", show.Funcs)
+	}
+	if !strings.Contains(show.Data, "Product struct {\n\t\tName string\n\t}") {
+		t.Errorf("unexpected data of the view:\n%s", show.Data)
+	}
+
+	want := "digraph views {\n\t\"views/products/show.html\" -> \"partials/card.html\";\n}\n"
+	if got := docsite.Graph(pages); got != want {
+		t.Errorf("unexpected graph:\ngot\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	set, err := analysis.Load(docsTestFiles, ".html")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: docsTestFiles},
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+	g := gallery.New(adapter, gallery.Options{}).
+		Add("@card", gallery.Example{Name: "Default", Props: map[string]any{"Title": "Lamp"}})
+
+	dir := t.TempDir()
+	if err := docsite.Generate(set, dir, docsite.Options{Title: "Shop views", Gallery: g}); err != nil {
+		t.Fatalf("error generating docs: %v", err)
+	}
+
+	tests := []struct {
+		file string
+		want []string
+	}{
+		{
+			file: "index.html",
+			want: []string{
+				"<title>Shop views - Shop views</title>",
+				`<li><a href="partials/card.html">partials/card.html</a> - Card shows a product summary.</li>`,
+				`<li><a href="views/products/show.html">views/products/show.html</a></li>`,
+			},
+		},
+		{
+			file: "partials/card.html",
+			want: []string{
+				`<nav><a href="../index.html">Shop views</a></nav>`,
+				"<p>Card shows a product summary.</p>",
+				`<li><a href="../views/products/show.html">views/products/show.html</a></li>`,
+				`<div class="example"><div class="card">Lamp</div></div>`,
+			},
+		},
+		{
+			file: "views/products/show.html",
+			want: []string{
+				`<li><a href="../../partials/card.html">@card</a></li>`,
+				"<li><code>upper</code></li>",
+			},
+		},
+		{
+			file: "graph.dot",
+			want: []string{`"views/products/show.html" -> "partials/card.html";`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			b, err := os.ReadFile(filepath.Join(dir, tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(b), want) {
+					t.Errorf("expected %s to contain %q, got:\n%s", tt.file, want, b)
+				}
+			}
+		})
+	}
+}
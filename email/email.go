@@ -0,0 +1,263 @@
+// Package email renders transactional emails with the templates of the application: the HTML body is rendered by a
+// HyperView template adapter, with its layouts, partials and functions, and the text body by a sibling .txt
+// template with the same data. The CSS of the HTML body is inlined for email clients, and both bodies can be written
+// as a multipart/alternative message body.
+//
+//	views/emails/welcome.html # HTML body, rendered in layouts/email.html
+//	views/emails/welcome.txt  # text body, rendered in layouts/email.txt if it exists and the view defines page:main
+package email
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/http"
+	"net/textproto"
+	"path"
+	"strings"
+	"text/template"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/funcs"
+	"github.com/hypergopher/hyperview/response"
+)
+
+// DefaultLayout is the layout of emails whose response sets none.
+const DefaultLayout = "email"
+
+// Options are the options for the Renderer.
+type Options struct {
+	// Templates is the template adapter rendering the HTML bodies. Required.
+	Templates *hyperview.TemplateAdapter
+	// FileSystemMap is a map of file systems holding the text templates, keyed by file system ID like the file systems
+	// of the template adapter: views/emails/welcome.txt is the text body of views/emails/welcome.html. The layouts and
+	// partials directories can hold .txt layouts and partials too.
+	FileSystemMap map[string]fs.FS
+	// Funcs is a map of functions to add to the functions of the text templates, which are the built-in template
+	// functions.
+	Funcs template.FuncMap
+	// Layout is the layout of emails whose response sets none. Default is DefaultLayout.
+	Layout string
+}
+
+// Renderer renders emails.
+type Renderer struct {
+	templates     *hyperview.TemplateAdapter
+	fileSystemMap map[string]fs.FS
+	funcMap       template.FuncMap
+	layout        string
+	views         map[string]*template.Template
+}
+
+// Message is a rendered email.
+type Message struct {
+	// Subject is the title of the response.
+	Subject string
+	// HTML is the HTML body, with its CSS inlined.
+	HTML string
+	// Text is the text body, or an empty string if the view has no .txt template.
+	Text string
+}
+
+// NewRenderer creates a new Renderer.
+func NewRenderer(opts Options) *Renderer {
+	if opts.Layout == "" {
+		opts.Layout = DefaultLayout
+	}
+
+	funcMap := make(template.FuncMap, len(funcs.FuncMap)+len(opts.Funcs))
+	for name, fn := range funcs.FuncMap {
+		funcMap[name] = fn
+	}
+	for name, fn := range opts.Funcs {
+		funcMap[name] = fn
+	}
+
+	return &Renderer{
+		templates:     opts.Templates,
+		fileSystemMap: opts.FileSystemMap,
+		funcMap:       funcMap,
+		layout:        opts.Layout,
+		views:         make(map[string]*template.Template),
+	}
+}
+
+// Init parses the text templates. The template adapter is initialized separately.
+func (e *Renderer) Init() error {
+	if e.templates == nil {
+		return errors.New("email renderer: no template adapter to render the HTML bodies with")
+	}
+
+	common := template.New("_common_").Funcs(e.funcMap)
+	type viewFile struct {
+		fsys fs.FS
+		path string
+		name string
+	}
+	var views []viewFile
+
+	for fsID, fsys := range e.fileSystemMap {
+		for _, dir := range []string{constants.LayoutsDir, constants.PartialsDir, constants.ViewsDir} {
+			if _, err := fs.Stat(fsys, dir); err != nil {
+				continue
+			}
+
+			err := fs.WalkDir(fsys, dir, func(filePath string, d fs.DirEntry, err error) error {
+				if err != nil || d.IsDir() || path.Ext(filePath) != ".txt" {
+					return err
+				}
+
+				if dir == constants.ViewsDir {
+					name := strings.TrimSuffix(filePath, ".txt")
+					if fsID != constants.RootFSID {
+						name = fsID + ":" + name
+					}
+					views = append(views, viewFile{fsys: fsys, path: filePath, name: name})
+					return nil
+				}
+				return parseFile(common, fsys, filePath)
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	parsed := make(map[string]*template.Template, len(views))
+	for _, view := range views {
+		tmpl, err := common.Clone()
+		if err != nil {
+			return err
+		}
+		if err := parseFile(tmpl, view.fsys, view.path); err != nil {
+			return err
+		}
+		parsed[view.name] = tmpl.Lookup(view.path)
+	}
+
+	e.views = parsed
+	return nil
+}
+
+// parseFile parses a template file into the set of tmpl, as a template named after its path.
+func parseFile(tmpl *template.Template, fsys fs.FS, filePath string) error {
+	src, err := fs.ReadFile(fsys, filePath)
+	if err != nil {
+		return err
+	}
+	if _, err := tmpl.New(filePath).Parse(string(src)); err != nil {
+		return fmt.Errorf("error parsing %s: %w", filePath, err)
+	}
+	return nil
+}
+
+// Render renders the email of the response: the HTML body with the view of the response, in the layout of the
+// response or the default layout, and the text body with the sibling .txt view. Text views defining a page:main
+// template are rendered in the .txt layout of the same name if there is one, and other text views as is. The
+// request-scoped functions, locale and tenant of the HTML templates are taken from ctx.
+func (e *Renderer) Render(ctx context.Context, resp *response.Response) (*Message, error) {
+	if resp.TemplateLayout() == "" {
+		resp.Layout(e.layout)
+	}
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodGet, "/", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &bodyWriter{header: make(http.Header), status: http.StatusOK}
+	e.templates.Render(w, r, resp)
+	if w.status != http.StatusOK {
+		return nil, fmt.Errorf("error rendering %s: %s", resp.TemplatePath(), strings.TrimSpace(w.body.String()))
+	}
+
+	body, err := InlineCSS(w.body.String())
+	if err != nil {
+		return nil, fmt.Errorf("error inlining the CSS of %s: %w", resp.TemplatePath(), err)
+	}
+	msg := &Message{Subject: resp.PageTitle(), HTML: body}
+
+	tmpl, ok := e.views[resp.TemplatePath()]
+	if !ok {
+		return msg, nil
+	}
+
+	name := tmpl.Name()
+	if layout := "layout:" + resp.TemplateLayout(); tmpl.Lookup(layout) != nil && tmpl.Lookup("page:main") != nil {
+		name = layout
+	}
+
+	var text bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&text, name, resp.ViewData(r).Data()); err != nil {
+		return nil, fmt.Errorf("error rendering the text body of %s: %w", resp.TemplatePath(), err)
+	}
+	msg.Text = text.String()
+
+	return msg, nil
+}
+
+// WriteMultipart writes the bodies of the message to w as a multipart/alternative body, the text part first, both
+// quoted-printable encoded. It returns the Content-Type header of the body, with its boundary. Messages without a
+// text body have a single HTML part.
+func (m *Message) WriteMultipart(w io.Writer) (string, error) {
+	mw := multipart.NewWriter(w)
+
+	parts := []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain; charset=utf-8", m.Text},
+		{"text/html; charset=utf-8", m.HTML},
+	}
+	for _, part := range parts {
+		if part.body == "" {
+			continue
+		}
+
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return "", err
+		}
+
+		qw := quotedprintable.NewWriter(pw)
+		if _, err := io.WriteString(qw, part.body); err != nil {
+			return "", err
+		}
+		if err := qw.Close(); err != nil {
+			return "", err
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		return "", err
+	}
+	return "multipart/alternative; boundary=" + mw.Boundary(), nil
+}
+
+// bodyWriter is an http.ResponseWriter capturing a rendered HTML body.
+type bodyWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bodyWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *bodyWriter) WriteHeader(status int) {
+	w.status = status
+}
+
+func (w *bodyWriter) Write(p []byte) (int, error) {
+	return w.body.Write(p)
+}
@@ -0,0 +1,136 @@
+package email_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/fs"
+	"mime"
+	"mime/multipart"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/email"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func newTestRenderer(t *testing.T) *email.Renderer {
+	t.Helper()
+
+	files := fstest.MapFS{
+		"layouts/email.html": {Data: []byte(`{{define "layout:email"}}<html><head><style>p { color: #333 }</style></head>` +
+			`<body>{{template "page:main" .}}</body></html>{{end}}`)},
+		"layouts/email.txt":         {Data: []byte(`{{define "layout:email"}}{{template "page:main" .}}-- The team{{end}}`)},
+		"partials/greeting.txt":     {Data: []byte(`{{define "greeting"}}Hi {{.}},{{end}}`)},
+		"views/emails/welcome.html": {Data: []byte(`{{define "page:main"}}<p>Welcome {{.Name}}</p>{{end}}`)},
+		"views/emails/welcome.txt":  {Data: []byte(`{{define "page:main"}}{{template "greeting" .Name}} welcome & enjoy {{upper "it"}}` + "\n" + `{{end}}`)},
+		"views/emails/receipt.html": {Data: []byte(`{{define "page:main"}}<p>Paid</p>{{end}}`)},
+		"views/emails/receipt.txt":  {Data: []byte(`Paid {{.Amount}}`)},
+		"views/emails/notice.html":  {Data: []byte(`{{define "page:main"}}<p>Notice</p>{{end}}`)},
+	}
+	fsMap := map[string]fs.FS{constants.RootFSID: files}
+
+	templates := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{FileSystemMap: fsMap})
+	if err := templates.Init(); err != nil {
+		t.Fatalf("error initializing templates: %v", err)
+	}
+
+	renderer := email.NewRenderer(email.Options{Templates: templates, FileSystemMap: fsMap})
+	if err := renderer.Init(); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	return renderer
+}
+
+func TestRenderer_Render(t *testing.T) {
+	renderer := newTestRenderer(t)
+
+	tests := []struct {
+		name     string
+		resp     *response.Response
+		wantHTML string
+		wantText string
+	}{
+		{
+			name:     "text layout",
+			resp:     response.NewResponse().Title("Welcome").Path("emails/welcome").Data(map[string]any{"Name": "Ada <3"}),
+			wantHTML: `<p style="color: #333">Welcome Ada &lt;3</p>`,
+			wantText: "Hi Ada <3, welcome & enjoy IT\n-- The team",
+		},
+		{
+			name:     "text view without layout",
+			resp:     response.NewResponse().Path("emails/receipt").Data(map[string]any{"Amount": "$10"}),
+			wantHTML: `<p style="color: #333">Paid</p>`,
+			wantText: "Paid $10",
+		},
+		{
+			name:     "no text view",
+			resp:     response.NewResponse().Path("emails/notice"),
+			wantHTML: `<p style="color: #333">Notice</p>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := renderer.Render(context.Background(), tt.resp)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if !strings.Contains(msg.HTML, tt.wantHTML) {
+				t.Errorf("unexpected HTML body: got %s, want it to contain %s", msg.HTML, tt.wantHTML)
+			}
+			if strings.Contains(msg.HTML, "<style>") {
+				t.Errorf("HTML body still has a style element: %s", msg.HTML)
+			}
+			if msg.Text != tt.wantText {
+				t.Errorf("unexpected text body: got %q, want %q", msg.Text, tt.wantText)
+			}
+		})
+	}
+
+	if msg, _ := renderer.Render(context.Background(), response.NewResponse().Title("Welcome").Path("emails/welcome")); msg.Subject != "Welcome" {
+		t.Errorf("unexpected subject: got %q, want %q", msg.Subject, "Welcome")
+	}
+
+	if _, err := renderer.Render(context.Background(), response.NewResponse().Path("emails/missing")); err == nil {
+		t.Error("Render() of a missing view: expected an error")
+	}
+}
+
+func TestMessage_WriteMultipart(t *testing.T) {
+	msg := &email.Message{HTML: "<p>Café</p>", Text: "Café"}
+
+	var body bytes.Buffer
+	contentType, err := msg.WriteMultipart(&body)
+	if err != nil {
+		t.Fatalf("WriteMultipart() error = %v", err)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("unexpected content type %q: %v", contentType, err)
+	}
+
+	// The multipart reader decodes quoted-printable parts
+	reader := multipart.NewReader(&body, params["boundary"])
+	want := []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", "Café"},
+		{"text/html; charset=utf-8", "<p>Café</p>"},
+	}
+	for _, w := range want {
+		part, err := reader.NextPart()
+		if err != nil {
+			t.Fatalf("NextPart() error = %v", err)
+		}
+		got, _ := io.ReadAll(part)
+		if part.Header.Get("Content-Type") != w.contentType || string(got) != w.body {
+			t.Errorf("unexpected part: got %s %q, want %s %q", part.Header.Get("Content-Type"), got, w.contentType, w.body)
+		}
+	}
+	if _, err := reader.NextPart(); err != io.EOF {
+		t.Errorf("expected two parts, NextPart() error = %v", err)
+	}
+}
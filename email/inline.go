@@ -0,0 +1,386 @@
+package email
+
+import (
+	"bytes"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	cssComment = regexp.MustCompile(`(?s)/\*.*?\*/`)
+	// simpleSelector matches the selectors that can be inlined: compound type, class and ID selectors, optionally
+	// combined with descendant combinators, e.g. "table.body td".
+	simpleSelector = regexp.MustCompile(`^[a-zA-Z0-9_\-.# ]+$`)
+)
+
+// InlineCSS moves the rules of the style elements of an HTML document to the style attributes of the elements they
+// match, as many email clients ignore style elements. Rules are applied in cascade order: by specificity, then by
+// source order, with the declarations already in style attributes taking precedence unless a rule is !important.
+//
+// Only type, class and ID selectors, optionally combined with descendant combinators, can be inlined. Rules with
+// other selectors, such as :hover, and at-rules, such as @media queries, are kept in a style element in the head,
+// for the clients supporting them. Style elements with a data-inline="false" attribute are left as is.
+func InlineCSS(document string) (string, error) {
+	doc, err := html.Parse(strings.NewReader(document))
+	if err != nil {
+		return "", err
+	}
+
+	var (
+		rules  []cssRule
+		kept   []string
+		styles []*html.Node
+	)
+	for n := range doc.Descendants() {
+		if n.Type != html.ElementNode || n.DataAtom != atom.Style || attr(n, "data-inline") == "false" {
+			continue
+		}
+		styles = append(styles, n)
+
+		var css strings.Builder
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			css.WriteString(c.Data)
+		}
+		parsed, rest := parseCSS(css.String(), len(rules))
+		rules = append(rules, parsed...)
+		kept = append(kept, rest...)
+	}
+	if len(styles) == 0 {
+		return document, nil
+	}
+
+	sort.SliceStable(rules, func(i, j int) bool {
+		return rules[i].less(rules[j])
+	})
+
+	for n := range doc.Descendants() {
+		if n.Type != html.ElementNode || n.DataAtom == atom.Style {
+			continue
+		}
+		inlineRules(n, rules)
+	}
+
+	// The kept rules replace the first style element, and the other style elements are removed
+	for i, n := range styles {
+		if i == 0 && len(kept) > 0 {
+			for c := n.FirstChild; c != nil; c = n.FirstChild {
+				n.RemoveChild(c)
+			}
+			n.AppendChild(&html.Node{Type: html.TextNode, Data: strings.Join(kept, "\n")})
+			continue
+		}
+		n.Parent.RemoveChild(n)
+	}
+
+	var b bytes.Buffer
+	if err := html.Render(&b, doc); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// cssRule is a rule with a single selector that can be inlined.
+type cssRule struct {
+	selector    []simple // compound selectors, from the outermost ancestor to the element
+	specificity [3]int
+	order       int
+	decls       []declaration
+}
+
+// simple is a compound selector, e.g. td.cell#total.
+type simple struct {
+	tag     string
+	id      string
+	classes []string
+}
+
+type declaration struct {
+	property  string
+	value     string
+	important bool
+}
+
+func (r cssRule) less(o cssRule) bool {
+	for i := range r.specificity {
+		if r.specificity[i] != o.specificity[i] {
+			return r.specificity[i] < o.specificity[i]
+		}
+	}
+	return r.order < o.order
+}
+
+// parseCSS splits a style sheet into the rules that can be inlined, numbered from order, and the rules that must be
+// kept in a style element.
+func parseCSS(css string, order int) ([]cssRule, []string) {
+	css = cssComment.ReplaceAllString(css, "")
+
+	var (
+		rules []cssRule
+		kept  []string
+	)
+	for len(strings.TrimSpace(css)) > 0 {
+		css = strings.TrimSpace(css)
+
+		open := strings.IndexByte(css, '{')
+		if open == -1 {
+			break
+		}
+		end := matchingBrace(css, open)
+		prelude, body := strings.TrimSpace(css[:open]), css[open+1:end]
+		block := css[:min(end+1, len(css))]
+		css = css[min(end+1, len(css)):]
+
+		if strings.HasPrefix(prelude, "@") {
+			kept = append(kept, block)
+			continue
+		}
+
+		decls := parseDeclarations(body)
+		var unsupported []string
+		for _, sel := range strings.Split(prelude, ",") {
+			sel = strings.TrimSpace(sel)
+			rule, ok := parseSelector(sel)
+			if !ok {
+				unsupported = append(unsupported, sel)
+				continue
+			}
+			rule.order = order
+			rule.decls = decls
+			rules = append(rules, rule)
+			order++
+		}
+		if len(unsupported) > 0 {
+			kept = append(kept, strings.Join(unsupported, ", ")+" {"+body+"}")
+		}
+	}
+
+	return rules, kept
+}
+
+// matchingBrace returns the index of the brace closing the one at open, or the end of css if there is none.
+func matchingBrace(css string, open int) int {
+	depth := 0
+	for i := open; i < len(css); i++ {
+		switch css[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(css)
+}
+
+// parseSelector parses a selector made of compound type, class and ID selectors and descendant combinators.
+func parseSelector(sel string) (cssRule, bool) {
+	if !simpleSelector.MatchString(sel) {
+		return cssRule{}, false
+	}
+
+	var rule cssRule
+	for _, part := range strings.Fields(sel) {
+		var s simple
+		for _, token := range splitCompound(part) {
+			switch {
+			case strings.HasPrefix(token, "#"):
+				s.id = token[1:]
+				rule.specificity[0]++
+			case strings.HasPrefix(token, "."):
+				s.classes = append(s.classes, token[1:])
+				rule.specificity[1]++
+			default:
+				s.tag = strings.ToLower(token)
+				rule.specificity[2]++
+			}
+		}
+		if s.tag == "" && s.id == "" && len(s.classes) == 0 {
+			return cssRule{}, false
+		}
+		rule.selector = append(rule.selector, s)
+	}
+	return rule, len(rule.selector) > 0
+}
+
+// splitCompound splits a compound selector before each . and #, e.g. td.cell#total -> td, .cell, #total.
+func splitCompound(part string) []string {
+	var tokens []string
+	start := 0
+	for i := 1; i < len(part); i++ {
+		if part[i] == '.' || part[i] == '#' {
+			tokens = append(tokens, part[start:i])
+			start = i
+		}
+	}
+	return append(tokens, part[start:])
+}
+
+// parseDeclarations parses the declarations of a rule or a style attribute, e.g. "color: red; padding: 0 !important".
+func parseDeclarations(body string) []declaration {
+	var decls []declaration
+	for _, entry := range splitDeclarations(body) {
+		property, value, ok := strings.Cut(entry, ":")
+		if !ok {
+			continue
+		}
+		property = strings.ToLower(strings.TrimSpace(property))
+		value = strings.TrimSpace(value)
+		if property == "" || value == "" {
+			continue
+		}
+
+		d := declaration{property: property, value: value}
+		if v, found := strings.CutSuffix(value, "!important"); found {
+			d.value, d.important = strings.TrimSpace(v), true
+		}
+		decls = append(decls, d)
+	}
+	return decls
+}
+
+// splitDeclarations splits declarations on semicolons outside of quotes and parentheses, so values such as
+// url(data:image/png;base64,...) are kept whole.
+func splitDeclarations(body string) []string {
+	var (
+		entries []string
+		depth   int
+		quote   rune
+		start   int
+	)
+	for i, c := range body {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ';' && depth == 0:
+			entries = append(entries, body[start:i])
+			start = i + 1
+		}
+	}
+	return append(entries, body[start:])
+}
+
+// inlineRules sets the style attribute of n to the declarations of the rules matching it, sorted in cascade order,
+// followed by the declarations of its style attribute.
+func inlineRules(n *html.Node, rules []cssRule) {
+	var normal, important []declaration
+	for _, rule := range rules {
+		if !rule.matches(n) {
+			continue
+		}
+		for _, d := range rule.decls {
+			if d.important {
+				important = append(important, d)
+			} else {
+				normal = append(normal, d)
+			}
+		}
+	}
+	if len(normal) == 0 && len(important) == 0 {
+		return
+	}
+
+	inline := parseDeclarations(attr(n, "style"))
+	for _, d := range inline {
+		if d.important {
+			important = append(important, d)
+		} else {
+			normal = append(normal, d)
+		}
+	}
+
+	// Later declarations override earlier ones, but properties keep the position of their first declaration
+	var properties []string
+	values := make(map[string]declaration)
+	for _, d := range append(normal, important...) {
+		if _, ok := values[d.property]; !ok {
+			properties = append(properties, d.property)
+		}
+		values[d.property] = d
+	}
+
+	decls := make([]string, 0, len(properties))
+	for _, property := range properties {
+		d := values[property]
+		value := d.value
+		if d.important {
+			value += " !important"
+		}
+		decls = append(decls, property+": "+value)
+	}
+	setAttr(n, "style", strings.Join(decls, "; "))
+}
+
+// matches reports whether the rule's selector matches n.
+func (r cssRule) matches(n *html.Node) bool {
+	last := len(r.selector) - 1
+	if !r.selector[last].matches(n) {
+		return false
+	}
+
+	// Each ancestor compound selector must match an ancestor of the element, from the closest one outwards
+	i := last - 1
+	for p := n.Parent; p != nil && i >= 0; p = p.Parent {
+		if p.Type == html.ElementNode && r.selector[i].matches(p) {
+			i--
+		}
+	}
+	return i < 0
+}
+
+func (s simple) matches(n *html.Node) bool {
+	if s.tag != "" && n.Data != s.tag {
+		return false
+	}
+	if s.id != "" && attr(n, "id") != s.id {
+		return false
+	}
+	if len(s.classes) > 0 {
+		classes := strings.Fields(attr(n, "class"))
+		for _, class := range s.classes {
+			found := false
+			for _, c := range classes {
+				if c == class {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func setAttr(n *html.Node, key, value string) {
+	for i, a := range n.Attr {
+		if a.Key == key {
+			n.Attr[i].Val = value
+			return
+		}
+	}
+	n.Attr = append(n.Attr, html.Attribute{Key: key, Val: value})
+}
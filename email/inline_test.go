@@ -0,0 +1,74 @@
+package email_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hypergopher/hyperview/email"
+)
+
+func TestInlineCSS(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want []string
+		not  []string
+	}{
+		{
+			name: "type, class and ID selectors",
+			html: `<style>p { color: red } .note { color: blue; margin: 0 } #total { font-weight: bold }</style>` +
+				`<p>a</p><p class="note">b</p><td id="total">c</td>`,
+			want: []string{`<p style="color: red">a</p>`, `<p class="note" style="color: blue; margin: 0">b</p>`},
+			not:  []string{"<style>"},
+		},
+		{
+			name: "specificity beats source order",
+			html: `<style>#lead { color: green } p.intro { color: blue } p { color: red }</style><p id="lead" class="intro">a</p>`,
+			want: []string{`style="color: green"`},
+		},
+		{
+			name: "inline style wins unless important",
+			html: `<style>p { color: red; margin: 0 !important }</style><p style="color: black; margin: 4px">a</p>`,
+			want: []string{`style="color: black; margin: 0 !important"`},
+		},
+		{
+			name: "descendant selector",
+			html: `<style>table.body td { padding: 8px }</style><table class="body"><tr><td>a</td></tr></table><table><tr><td>b</td></tr></table>`,
+			want: []string{`<td style="padding: 8px">a</td>`, `<td>b</td>`},
+		},
+		{
+			name: "media queries and pseudo-classes are kept",
+			html: `<head><style>a { color: red } a:hover { color: blue } @media (max-width: 600px) { p { width: 100% } }</style></head><a href="/">x</a>`,
+			want: []string{`<a href="/" style="color: red">x</a>`, `a:hover { color: blue }`, `@media (max-width: 600px) { p { width: 100% } }`},
+		},
+		{
+			name: "data URLs are kept whole",
+			html: `<style>div { background: url(data:image/png;base64,AAAA) }</style><div>x</div>`,
+			want: []string{`style="background: url(data:image/png;base64,AAAA)"`},
+		},
+		{
+			name: "opted out style elements",
+			html: `<style data-inline="false">p { color: red }</style><p>a</p>`,
+			want: []string{`<style data-inline="false">p { color: red }</style>`, `<p>a</p>`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := email.InlineCSS(tt.html)
+			if err != nil {
+				t.Fatalf("InlineCSS() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("InlineCSS() = %s, want it to contain %s", got, want)
+				}
+			}
+			for _, not := range tt.not {
+				if strings.Contains(got, not) {
+					t.Errorf("InlineCSS() = %s, want it not to contain %s", got, not)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package feeds
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/response"
+)
+
+// DataKey is the key of the view data holding the Feed, Sitemap or SitemapIndex the adapter renders.
+const DataKey = "Feed"
+
+// Format is the format the adapter renders feeds in.
+type Format int
+
+const (
+	// Atom renders feeds as Atom documents. This is the default.
+	Atom Format = iota
+	// RSS renders feeds as RSS 2.0 documents.
+	RSS
+)
+
+// Options are the options of the adapter.
+type Options struct {
+	// Format is the format of the feeds. Sitemaps and sitemap indexes are rendered as such whatever the format.
+	Format Format
+}
+
+// Adapter is a view adapter rendering the Feed, Sitemap or SitemapIndex of the view data, under DataKey, instead of
+// a template. Register it under the extension of the paths it renders, e.g. "atom" or "rss", so Path("feed.atom")
+// renders the feed with it, or render with HyperView.RenderAs.
+type Adapter struct {
+	opts Options
+}
+
+var _ hyperview.Adapter = (*Adapter)(nil)
+
+// NewAdapter creates a feeds adapter, with the default options unless options are given.
+func NewAdapter(opts ...Options) *Adapter {
+	adapter := &Adapter{}
+	if len(opts) > 0 {
+		adapter.opts = opts[0]
+	}
+	return adapter
+}
+
+// Data returns the view data of the feed, sitemap or sitemap index, to set with Response.Data.
+func Data(v any) map[string]any {
+	return map[string]any{DataKey: v}
+}
+
+func (a *Adapter) Init() error {
+	return nil
+}
+
+func (a *Adapter) Render(w http.ResponseWriter, r *http.Request, resp *response.Response) {
+	if err := resp.RunLoaders(r.Context(), response.DefaultLoaderConcurrency); err != nil {
+		a.RenderSystemError(w, r, err, resp)
+		return
+	}
+
+	value, ok := resp.ViewData(r).Data()[DataKey]
+	if !ok {
+		a.RenderSystemError(w, r, fmt.Errorf("feeds: no %s in the view data of %s", DataKey, resp.TemplatePath()), resp)
+		return
+	}
+
+	var buf bytes.Buffer
+	contentType, err := a.write(&buf, value)
+	if err != nil {
+		a.RenderSystemError(w, r, err, resp)
+		return
+	}
+
+	status := resp.StatusCode()
+	if status == 0 {
+		status = http.StatusOK
+	}
+	for key, values := range resp.HTTPHeader() {
+		w.Header()[key] = values
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	_, _ = w.Write(buf.Bytes())
+}
+
+// write writes the feed, sitemap or sitemap index, and returns its content type.
+func (a *Adapter) write(w io.Writer, value any) (string, error) {
+	switch v := value.(type) {
+	case Feed:
+		return a.writeFeed(w, v)
+	case *Feed:
+		return a.writeFeed(w, *v)
+	case Sitemap:
+		return SitemapContentType, WriteSitemap(w, v)
+	case *Sitemap:
+		return SitemapContentType, WriteSitemap(w, *v)
+	case SitemapIndex:
+		return SitemapContentType, WriteSitemapIndex(w, v)
+	case *SitemapIndex:
+		return SitemapContentType, WriteSitemapIndex(w, *v)
+	}
+	return "", fmt.Errorf("feeds: cannot render %T, want a Feed, Sitemap or SitemapIndex", value)
+}
+
+// writeFeed writes the feed in the format of the adapter, and returns its content type.
+func (a *Adapter) writeFeed(w io.Writer, feed Feed) (string, error) {
+	if a.opts.Format == RSS {
+		return RSSContentType, WriteRSS(w, feed)
+	}
+	return AtomContentType, WriteAtom(w, feed)
+}
+
+func (a *Adapter) RenderForbidden(w http.ResponseWriter, _ *http.Request, _ *response.Response) {
+	http.Error(w, "Forbidden", http.StatusForbidden)
+}
+
+func (a *Adapter) RenderMaintenance(w http.ResponseWriter, _ *http.Request, _ *response.Response) {
+	http.Error(w, "Maintenance", http.StatusServiceUnavailable)
+}
+
+func (a *Adapter) RenderMethodNotAllowed(w http.ResponseWriter, _ *http.Request, _ *response.Response) {
+	http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+}
+
+func (a *Adapter) RenderNotFound(w http.ResponseWriter, _ *http.Request, _ *response.Response) {
+	http.Error(w, "Not found", http.StatusNotFound)
+}
+
+func (a *Adapter) RenderSystemError(w http.ResponseWriter, _ *http.Request, err error, _ *response.Response) {
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
+func (a *Adapter) RenderUnauthorized(w http.ResponseWriter, _ *http.Request, _ *response.Response) {
+	http.Error(w, "Unauthorized", http.StatusUnauthorized)
+}
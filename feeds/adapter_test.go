@@ -0,0 +1,91 @@
+package feeds_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/feeds"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func TestAdapter_Render(t *testing.T) {
+	tests := []struct {
+		name            string
+		resp            *response.Response
+		wantStatus      int
+		wantContentType string
+		want            string
+	}{
+		{
+			name:            "atom",
+			resp:            response.NewResponse().Path("feed.atom").Data(feeds.Data(testFeed())),
+			wantStatus:      http.StatusOK,
+			wantContentType: feeds.AtomContentType,
+			want:            `<feed xmlns="http://www.w3.org/2005/Atom">`,
+		},
+		{
+			name:            "rss",
+			resp:            response.NewResponse().Path("feed.rss").Data(feeds.Data(testFeed())),
+			wantStatus:      http.StatusOK,
+			wantContentType: feeds.RSSContentType,
+			want:            `<rss version="2.0"`,
+		},
+		{
+			name: "sitemap",
+			resp: response.NewResponse().Path("sitemap.rss").
+				Data(feeds.Data(&feeds.Sitemap{URLs: []feeds.URL{{Loc: "https://example.com/"}}})),
+			wantStatus:      http.StatusOK,
+			wantContentType: feeds.SitemapContentType,
+			want:            `<loc>https://example.com/</loc>`,
+		},
+		{
+			name:       "no feed",
+			resp:       response.NewResponse().Path("feed.atom"),
+			wantStatus: http.StatusInternalServerError,
+			want:       "feeds: no Feed in the view data",
+		},
+		{
+			name:       "invalid feed",
+			resp:       response.NewResponse().Path("feed.atom").Data(feeds.Data(feeds.Feed{Title: "News"})),
+			wantStatus: http.StatusInternalServerError,
+			want:       "feeds: the feed has no link",
+		},
+		{
+			name:       "other data",
+			resp:       response.NewResponse().Path("feed.atom").Data(feeds.Data("news")),
+			wantStatus: http.StatusInternalServerError,
+			want:       "cannot render string",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hv, err := hyperview.NewHyperView()
+			if err != nil {
+				t.Fatalf("error creating HyperView: %v", err)
+			}
+			if err := hv.RegisterAdapter("atom", feeds.NewAdapter()); err != nil {
+				t.Fatalf("error registering adapter: %v", err)
+			}
+			if err := hv.RegisterAdapter("rss", feeds.NewAdapter(feeds.Options{Format: feeds.RSS})); err != nil {
+				t.Fatalf("error registering adapter: %v", err)
+			}
+
+			w := httptest.NewRecorder()
+			hv.Render(w, httptest.NewRequest(http.MethodGet, "/", nil), tt.resp)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+			if tt.wantContentType != "" && w.Header().Get("Content-Type") != tt.wantContentType {
+				t.Errorf("expected content type %s, got %s", tt.wantContentType, w.Header().Get("Content-Type"))
+			}
+			if !strings.Contains(w.Body.String(), tt.want) {
+				t.Errorf("expected the body to contain %s, got:\n%s", tt.want, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,411 @@
+// Package feeds renders RSS 2.0 and Atom feeds, and sitemaps, from Go values, with the content types, escaping and
+// date formats each format requires, instead of hand-written XML templates:
+//
+//	_ = hv.RegisterAdapter("atom", feeds.NewAdapter())
+//	_ = hv.RegisterAdapter("rss", feeds.NewAdapter(feeds.Options{Format: feeds.RSS}))
+//
+//	hv.Render(w, r, response.NewResponse().Path("blog/feed.atom").Data(feeds.Data(feed)))
+package feeds
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"time"
+)
+
+const (
+	// RSSContentType is the content type of RSS feeds.
+	RSSContentType = "application/rss+xml; charset=utf-8"
+	// AtomContentType is the content type of Atom feeds.
+	AtomContentType = "application/atom+xml; charset=utf-8"
+	// SitemapContentType is the content type of sitemaps and sitemap indexes.
+	SitemapContentType = "application/xml; charset=utf-8"
+)
+
+// Feed is a feed of items, such as the posts of a blog, rendered as RSS or Atom.
+type Feed struct {
+	// Title is the title of the feed. Required.
+	Title string
+	// Link is the URL of the page of the feed, e.g. the home page of the blog. Required.
+	Link string
+	// FeedURL is the URL the feed is served at, announced as its self link.
+	FeedURL string
+	// Description describes the feed. Required by RSS, which gets the title otherwise.
+	Description string
+	// ID is the permanent ID of the feed in Atom, a URI. Default is the link.
+	ID string
+	// Language is the language of the feed, e.g. "en-us".
+	Language string
+	// Author is the author of the feed, if any.
+	Author *Person
+	// Updated is the time the feed last changed. Default is the most recent time of the items.
+	Updated time.Time
+	// Items are the items of the feed, usually the most recent first.
+	Items []Item
+}
+
+// Item is an item of a feed.
+type Item struct {
+	// Title is the title of the item. Required.
+	Title string
+	// Link is the URL of the page of the item.
+	Link string
+	// ID is the permanent ID of the item, a URI in Atom. Default is the link.
+	ID string
+	// Summary is a plain text summary of the item.
+	Summary string
+	// Content is the HTML content of the item.
+	Content string
+	// Author is the author of the item, if any.
+	Author *Person
+	// Published is the time the item was first published.
+	Published time.Time
+	// Updated is the time the item last changed. Default is the publication time.
+	Updated time.Time
+	// Categories are the categories or tags of the item.
+	Categories []string
+	// Enclosure is a file attached to the item, such as the audio file of a podcast episode.
+	Enclosure *Enclosure
+}
+
+// Person is the author of a feed or an item.
+type Person struct {
+	Name  string
+	Email string
+	URI   string
+}
+
+// Enclosure is a file attached to an item.
+type Enclosure struct {
+	// URL is the URL of the file.
+	URL string
+	// Type is the media type of the file, e.g. "audio/mpeg".
+	Type string
+	// Length is the size of the file in bytes.
+	Length int64
+}
+
+// WriteRSS writes the feed as an RSS 2.0 document. The summaries of the items are their descriptions, and their HTML
+// content is added in the content:encoded element of the RSS content module.
+func WriteRSS(w io.Writer, feed Feed) error {
+	if err := feed.validate(); err != nil {
+		return err
+	}
+
+	channel := rssChannel{
+		Title:       feed.Title,
+		Link:        feed.Link,
+		Description: feed.Description,
+		Language:    feed.Language,
+		PubDate:     rssDate(feed.updated()),
+	}
+	if channel.Description == "" {
+		channel.Description = feed.Title
+	}
+	if feed.FeedURL != "" {
+		channel.Self = &atomLink{Href: feed.FeedURL, Rel: "self", Type: "application/rss+xml"}
+	}
+	if feed.Author != nil && feed.Author.Email != "" {
+		channel.ManagingEditor = rssPerson(feed.Author)
+	}
+
+	for _, item := range feed.Items {
+		entry := rssItem{
+			Title:      item.Title,
+			Link:       item.Link,
+			PubDate:    rssDate(item.Published),
+			Categories: item.Categories,
+		}
+		switch {
+		case item.Summary != "":
+			entry.Description = item.Summary
+			if item.Content != "" {
+				entry.Content = &rssContent{Value: item.Content}
+			}
+		default:
+			entry.Description = item.Content
+		}
+		if id := item.id(); id != "" {
+			entry.GUID = &rssGUID{Value: id, IsPermaLink: "false"}
+			if id == item.Link {
+				entry.GUID.IsPermaLink = ""
+			}
+		}
+		if item.Author != nil && item.Author.Email != "" {
+			entry.Author = rssPerson(item.Author)
+		}
+		if e := item.Enclosure; e != nil {
+			entry.Enclosure = &rssEnclosure{URL: e.URL, Type: e.Type, Length: e.Length}
+		}
+		channel.Items = append(channel.Items, entry)
+	}
+
+	return writeXML(w, rssDocument{Version: "2.0", Atom: atomNamespace, ContentNS: contentNamespace, Channel: channel})
+}
+
+// WriteAtom writes the feed as an Atom document. The summaries of the items are their text summaries, and their
+// HTML content is escaped in content elements of the html type.
+func WriteAtom(w io.Writer, feed Feed) error {
+	if err := feed.validate(); err != nil {
+		return err
+	}
+
+	doc := atomFeed{
+		XMLNS:   atomNamespace,
+		Title:   feed.Title,
+		ID:      feed.ID,
+		Updated: atomDate(feed.updated()),
+		Links:   []atomLink{{Href: feed.Link, Rel: "alternate"}},
+		Author:  atomAuthor(feed.Author),
+		Lang:    feed.Language,
+	}
+	if doc.ID == "" {
+		doc.ID = feed.Link
+	}
+	if doc.Updated == "" {
+		return errors.New("feeds: the Atom feed has no updated time nor dated items")
+	}
+	if feed.Description != "" {
+		doc.Subtitle = feed.Description
+	}
+	if feed.FeedURL != "" {
+		doc.Links = append(doc.Links, atomLink{Href: feed.FeedURL, Rel: "self", Type: "application/atom+xml"})
+	}
+
+	for _, item := range feed.Items {
+		entry := atomEntry{
+			Title:     item.Title,
+			ID:        item.id(),
+			Updated:   atomDate(item.updated()),
+			Published: atomDate(item.Published),
+			Author:    atomAuthor(item.Author),
+		}
+		switch {
+		case entry.ID == "":
+			return fmt.Errorf("feeds: item %q has no ID nor link", item.Title)
+		case entry.Updated == "":
+			return fmt.Errorf("feeds: item %q has no updated nor published time", item.Title)
+		}
+		if item.Link != "" {
+			entry.Links = []atomLink{{Href: item.Link, Rel: "alternate"}}
+		}
+		if item.Summary != "" {
+			entry.Summary = &atomText{Type: "text", Value: item.Summary}
+		}
+		if item.Content != "" {
+			entry.Content = &atomText{Type: "html", Value: item.Content}
+		}
+		for _, category := range item.Categories {
+			entry.Categories = append(entry.Categories, atomCategory{Term: category})
+		}
+		if e := item.Enclosure; e != nil {
+			entry.Links = append(entry.Links, atomLink{Href: e.URL, Rel: "enclosure", Type: e.Type, Length: e.Length})
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+
+	return writeXML(w, doc)
+}
+
+// validate checks the fields both formats require.
+func (f Feed) validate() error {
+	switch {
+	case f.Title == "":
+		return errors.New("feeds: the feed has no title")
+	case f.Link == "":
+		return errors.New("feeds: the feed has no link")
+	}
+	for _, item := range f.Items {
+		if item.Title == "" {
+			return fmt.Errorf("feeds: item %q has no title", item.id())
+		}
+	}
+	return nil
+}
+
+// updated returns the time the feed last changed, or else the most recent time of its items.
+func (f Feed) updated() time.Time {
+	if !f.Updated.IsZero() {
+		return f.Updated
+	}
+	var updated time.Time
+	for _, item := range f.Items {
+		if t := item.updated(); t.After(updated) {
+			updated = t
+		}
+	}
+	return updated
+}
+
+// id returns the ID of the item, or else its link.
+func (i Item) id() string {
+	if i.ID != "" {
+		return i.ID
+	}
+	return i.Link
+}
+
+// updated returns the time the item last changed, or else its publication time.
+func (i Item) updated() time.Time {
+	if !i.Updated.IsZero() {
+		return i.Updated
+	}
+	return i.Published
+}
+
+// isAbsoluteURL reports whether s is an absolute URL, as the links of feeds and sitemaps must be.
+func isAbsoluteURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+// writeXML writes the document with the XML declaration, indented.
+func writeXML(w io.Writer, doc any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("feeds: error encoding XML: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+const (
+	atomNamespace    = "http://www.w3.org/2005/Atom"
+	contentNamespace = "http://purl.org/rss/1.0/modules/content/"
+)
+
+// rssDate formats the time as RSS requires, in the RFC 822 format with a four-digit year. Zero times are omitted.
+func rssDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC1123Z)
+}
+
+// atomDate formats the time as Atom requires, in the RFC 3339 format. Zero times are omitted.
+func atomDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// rssPerson formats the person as RSS requires, as an email address followed by the name in parentheses.
+func rssPerson(p *Person) string {
+	if p.Name == "" {
+		return p.Email
+	}
+	return p.Email + " (" + p.Name + ")"
+}
+
+// atomAuthor returns the Atom author element of the person, if any.
+func atomAuthor(p *Person) *atomPerson {
+	if p == nil {
+		return nil
+	}
+	return &atomPerson{Name: p.Name, Email: p.Email, URI: p.URI}
+}
+
+type rssDocument struct {
+	XMLName   xml.Name   `xml:"rss"`
+	Version   string     `xml:"version,attr"`
+	Atom      string     `xml:"xmlns:atom,attr"`
+	ContentNS string     `xml:"xmlns:content,attr"`
+	Channel   rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title          string    `xml:"title"`
+	Link           string    `xml:"link"`
+	Description    string    `xml:"description"`
+	Self           *atomLink `xml:"atom:link,omitempty"`
+	Language       string    `xml:"language,omitempty"`
+	ManagingEditor string    `xml:"managingEditor,omitempty"`
+	PubDate        string    `xml:"lastBuildDate,omitempty"`
+	Items          []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link,omitempty"`
+	Description string        `xml:"description,omitempty"`
+	Content     *rssContent   `xml:"content:encoded,omitempty"`
+	Author      string        `xml:"author,omitempty"`
+	Categories  []string      `xml:"category"`
+	GUID        *rssGUID      `xml:"guid,omitempty"`
+	PubDate     string        `xml:"pubDate,omitempty"`
+	Enclosure   *rssEnclosure `xml:"enclosure,omitempty"`
+}
+
+// rssContent is the HTML content of an item, in a CDATA section as most readers expect.
+type rssContent struct {
+	Value string `xml:",cdata"`
+}
+
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink string `xml:"isPermaLink,attr,omitempty"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Type   string `xml:"type,attr"`
+	Length int64  `xml:"length,attr"`
+}
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"feed"`
+	XMLNS    string      `xml:"xmlns,attr"`
+	Lang     string      `xml:"xml:lang,attr,omitempty"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	ID       string      `xml:"id"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Author   *atomPerson `xml:"author,omitempty"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Updated    string         `xml:"updated"`
+	Published  string         `xml:"published,omitempty"`
+	Links      []atomLink     `xml:"link"`
+	Author     *atomPerson    `xml:"author,omitempty"`
+	Categories []atomCategory `xml:"category"`
+	Summary    *atomText      `xml:"summary,omitempty"`
+	Content    *atomText      `xml:"content,omitempty"`
+}
+
+type atomLink struct {
+	Href   string `xml:"href,attr"`
+	Rel    string `xml:"rel,attr,omitempty"`
+	Type   string `xml:"type,attr,omitempty"`
+	Length int64  `xml:"length,attr,omitempty"`
+}
+
+type atomPerson struct {
+	Name  string `xml:"name"`
+	Email string `xml:"email,omitempty"`
+	URI   string `xml:"uri,omitempty"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
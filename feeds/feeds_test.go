@@ -0,0 +1,142 @@
+package feeds_test
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hypergopher/hyperview/feeds"
+)
+
+var (
+	published = time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	edited    = time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC)
+)
+
+func testFeed() feeds.Feed {
+	return feeds.Feed{
+		Title:       "News & views",
+		Link:        "https://example.com/",
+		FeedURL:     "https://example.com/feed",
+		Description: "The <latest> news",
+		Author:      &feeds.Person{Name: "Ada", Email: "ada@example.com"},
+		Items: []feeds.Item{
+			{
+				Title:      "Hello <world>",
+				Link:       "https://example.com/posts/hello",
+				Summary:    "A & B",
+				Content:    "<p>Hello</p>",
+				Published:  published,
+				Updated:    edited,
+				Categories: []string{"news"},
+			},
+			{
+				Title:     "Episode 1",
+				ID:        "urn:episode:1",
+				Link:      "https://example.com/episodes/1",
+				Content:   "<p>Listen</p>",
+				Published: published,
+				Enclosure: &feeds.Enclosure{URL: "https://example.com/1.mp3", Type: "audio/mpeg", Length: 1024},
+			},
+		},
+	}
+}
+
+func TestWriteRSS(t *testing.T) {
+	var b strings.Builder
+	if err := feeds.WriteRSS(&b, testFeed()); err != nil {
+		t.Fatalf("error writing RSS: %v", err)
+	}
+	got := b.String()
+
+	for _, want := range []string{
+		xml.Header,
+		`<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom" xmlns:content="http://purl.org/rss/1.0/modules/content/">`,
+		`<title>News &amp; views</title>`,
+		`<description>The &lt;latest&gt; news</description>`,
+		`<atom:link href="https://example.com/feed" rel="self" type="application/rss+xml"></atom:link>`,
+		`<managingEditor>ada@example.com (Ada)</managingEditor>`,
+		`<lastBuildDate>Sat, 02 Mar 2024 10:00:00 +0000</lastBuildDate>`,
+		`<title>Hello &lt;world&gt;</title>`,
+		`<description>A &amp; B</description>`,
+		`<content:encoded><![CDATA[<p>Hello</p>]]></content:encoded>`,
+		`<guid>https://example.com/posts/hello</guid>`,
+		`<pubDate>Fri, 01 Mar 2024 09:30:00 +0000</pubDate>`,
+		`<category>news</category>`,
+		`<description>&lt;p&gt;Listen&lt;/p&gt;</description>`,
+		`<guid isPermaLink="false">urn:episode:1</guid>`,
+		`<enclosure url="https://example.com/1.mp3" type="audio/mpeg" length="1024"></enclosure>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected the RSS feed to contain %s, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestWriteAtom(t *testing.T) {
+	var b strings.Builder
+	if err := feeds.WriteAtom(&b, testFeed()); err != nil {
+		t.Fatalf("error writing Atom: %v", err)
+	}
+	got := b.String()
+
+	for _, want := range []string{
+		xml.Header,
+		`<feed xmlns="http://www.w3.org/2005/Atom">`,
+		`<title>News &amp; views</title>`,
+		`<subtitle>The &lt;latest&gt; news</subtitle>`,
+		`<id>https://example.com/</id>`,
+		`<updated>2024-03-02T10:00:00Z</updated>`,
+		`<link href="https://example.com/feed" rel="self" type="application/atom+xml"></link>`,
+		`<name>Ada</name>`,
+		`<id>https://example.com/posts/hello</id>`,
+		`<published>2024-03-01T09:30:00Z</published>`,
+		`<category term="news"></category>`,
+		`<summary type="text">A &amp; B</summary>`,
+		`<content type="html">&lt;p&gt;Hello&lt;/p&gt;</content>`,
+		`<id>urn:episode:1</id>`,
+		`<updated>2024-03-01T09:30:00Z</updated>`,
+		`<link href="https://example.com/1.mp3" rel="enclosure" type="audio/mpeg" length="1024"></link>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected the Atom feed to contain %s, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestWriteAtom_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		feed feeds.Feed
+		want string
+	}{
+		{name: "no title", feed: feeds.Feed{Link: "https://example.com/"}, want: "no title"},
+		{name: "no link", feed: feeds.Feed{Title: "News"}, want: "no link"},
+		{
+			name: "untitled item",
+			feed: feeds.Feed{Title: "News", Link: "https://example.com/", Items: []feeds.Item{{Link: "https://example.com/a"}}},
+			want: "item \"https://example.com/a\" has no title",
+		},
+		{name: "undated", feed: feeds.Feed{Title: "News", Link: "https://example.com/"}, want: "no updated time"},
+		{
+			name: "item without ID",
+			feed: feeds.Feed{Title: "News", Link: "https://example.com/", Updated: edited, Items: []feeds.Item{{Title: "A"}}},
+			want: "no ID nor link",
+		},
+		{
+			name: "undated item",
+			feed: feeds.Feed{Title: "News", Link: "https://example.com/", Updated: edited, Items: []feeds.Item{{Title: "A", ID: "a"}}},
+			want: "no updated nor published time",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := feeds.WriteAtom(&strings.Builder{}, tt.feed)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected an error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package feeds
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// MaxSitemapURLs is the number of URLs a sitemap can hold at most. Larger sites split their URLs into several
+// sitemaps, listed by a sitemap index.
+const MaxSitemapURLs = 50000
+
+// ChangeFreq is how often the page at a URL of a sitemap is likely to change.
+type ChangeFreq string
+
+// The change frequencies of the sitemaps.org protocol.
+const (
+	Always  ChangeFreq = "always"
+	Hourly  ChangeFreq = "hourly"
+	Daily   ChangeFreq = "daily"
+	Weekly  ChangeFreq = "weekly"
+	Monthly ChangeFreq = "monthly"
+	Yearly  ChangeFreq = "yearly"
+	Never   ChangeFreq = "never"
+)
+
+// Sitemap is a sitemap, listing the URLs of a site for search engines.
+type Sitemap struct {
+	// URLs are the URLs of the sitemap, at most MaxSitemapURLs.
+	URLs []URL
+}
+
+// URL is a URL of a sitemap.
+type URL struct {
+	// Loc is the absolute URL of the page. Required.
+	Loc string
+	// LastMod is the time the page last changed, if known.
+	LastMod time.Time
+	// ChangeFreq is how often the page is likely to change, if known.
+	ChangeFreq ChangeFreq
+	// Priority is the priority of the page relative to the other pages of the site, from 0.0 to 1.0. Zero priorities
+	// are omitted, so search engines assume the default of 0.5.
+	Priority float64
+}
+
+// SitemapIndex lists the sitemaps of a site split into several sitemaps.
+type SitemapIndex struct {
+	// Sitemaps are the sitemaps of the index, at most MaxSitemapURLs.
+	Sitemaps []SitemapRef
+}
+
+// SitemapRef is a sitemap listed by a sitemap index.
+type SitemapRef struct {
+	// Loc is the absolute URL of the sitemap. Required.
+	Loc string
+	// LastMod is the time the sitemap last changed, if known.
+	LastMod time.Time
+}
+
+// WriteSitemap writes the sitemap as a sitemap document of the sitemaps.org protocol.
+func WriteSitemap(w io.Writer, sitemap Sitemap) error {
+	if len(sitemap.URLs) > MaxSitemapURLs {
+		return fmt.Errorf("feeds: the sitemap has %d URLs, more than %d", len(sitemap.URLs), MaxSitemapURLs)
+	}
+
+	doc := sitemapURLSet{XMLNS: sitemapNamespace, URLs: make([]sitemapURL, 0, len(sitemap.URLs))}
+	for _, u := range sitemap.URLs {
+		if !isAbsoluteURL(u.Loc) {
+			return fmt.Errorf("feeds: sitemap URL %q is not absolute", u.Loc)
+		}
+		if u.Priority < 0 || u.Priority > 1 {
+			return fmt.Errorf("feeds: sitemap URL %s has priority %v, not between 0.0 and 1.0", u.Loc, u.Priority)
+		}
+
+		entry := sitemapURL{Loc: u.Loc, LastMod: atomDate(u.LastMod), ChangeFreq: string(u.ChangeFreq)}
+		if u.Priority > 0 {
+			entry.Priority = strconv.FormatFloat(u.Priority, 'f', 1, 64)
+		}
+		doc.URLs = append(doc.URLs, entry)
+	}
+
+	return writeXML(w, doc)
+}
+
+// WriteSitemapIndex writes the sitemap index as a sitemap index document of the sitemaps.org protocol.
+func WriteSitemapIndex(w io.Writer, index SitemapIndex) error {
+	if len(index.Sitemaps) > MaxSitemapURLs {
+		return fmt.Errorf("feeds: the sitemap index has %d sitemaps, more than %d", len(index.Sitemaps), MaxSitemapURLs)
+	}
+
+	doc := sitemapIndex{XMLNS: sitemapNamespace, Sitemaps: make([]sitemapRef, 0, len(index.Sitemaps))}
+	for _, ref := range index.Sitemaps {
+		if !isAbsoluteURL(ref.Loc) {
+			return fmt.Errorf("feeds: sitemap URL %q is not absolute", ref.Loc)
+		}
+		doc.Sitemaps = append(doc.Sitemaps, sitemapRef{Loc: ref.Loc, LastMod: atomDate(ref.LastMod)})
+	}
+
+	return writeXML(w, doc)
+}
+
+const sitemapNamespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc        string `xml:"loc"`
+	LastMod    string `xml:"lastmod,omitempty"`
+	ChangeFreq string `xml:"changefreq,omitempty"`
+	Priority   string `xml:"priority,omitempty"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name     `xml:"sitemapindex"`
+	XMLNS    string       `xml:"xmlns,attr"`
+	Sitemaps []sitemapRef `xml:"sitemap"`
+}
+
+type sitemapRef struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
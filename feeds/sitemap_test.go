@@ -0,0 +1,80 @@
+package feeds_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hypergopher/hyperview/feeds"
+)
+
+func TestWriteSitemap(t *testing.T) {
+	tests := []struct {
+		name    string
+		sitemap feeds.Sitemap
+		want    string
+		wantErr string
+	}{
+		{
+			name: "urls",
+			sitemap: feeds.Sitemap{URLs: []feeds.URL{
+				{Loc: "https://example.com/?a=1&b=2", LastMod: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), ChangeFreq: feeds.Daily, Priority: 0.8},
+				{Loc: "https://example.com/about"},
+			}},
+			want: `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url>
+    <loc>https://example.com/?a=1&amp;b=2</loc>
+    <lastmod>2024-03-01T00:00:00Z</lastmod>
+    <changefreq>daily</changefreq>
+    <priority>0.8</priority>
+  </url>
+  <url>
+    <loc>https://example.com/about</loc>
+  </url>
+</urlset>
+`,
+		},
+		{name: "relative URL", sitemap: feeds.Sitemap{URLs: []feeds.URL{{Loc: "/about"}}}, wantErr: "not absolute"},
+		{name: "priority out of range", sitemap: feeds.Sitemap{URLs: []feeds.URL{{Loc: "https://example.com/", Priority: 2}}}, wantErr: "priority 2"},
+		{name: "too many URLs", sitemap: feeds.Sitemap{URLs: make([]feeds.URL, feeds.MaxSitemapURLs+1)}, wantErr: "more than 50000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			err := feeds.WriteSitemap(&b, tt.sitemap)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("expected an error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error writing sitemap: %v", err)
+			}
+			if got := strings.TrimPrefix(b.String(), `<?xml version="1.0" encoding="UTF-8"?>`+"\n"); got != tt.want {
+				t.Errorf("expected:\n%s\ngot:\n%s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWriteSitemapIndex(t *testing.T) {
+	var b strings.Builder
+	err := feeds.WriteSitemapIndex(&b, feeds.SitemapIndex{Sitemaps: []feeds.SitemapRef{
+		{Loc: "https://example.com/sitemap-posts.xml", LastMod: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+	}})
+	if err != nil {
+		t.Fatalf("error writing sitemap index: %v", err)
+	}
+
+	want := `<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap>
+    <loc>https://example.com/sitemap-posts.xml</loc>
+    <lastmod>2024-03-01T00:00:00Z</lastmod>
+  </sitemap>
+</sitemapindex>`
+	if got := b.String(); !strings.Contains(got, want) {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+}
@@ -0,0 +1,193 @@
+// Package fileroutes routes requests to the views of a template adapter after their paths, so the pages of
+// content-heavy sites, which are pure view lookups, need no hand-written handlers.
+//
+// Each view is served at its path in the views directory, with the segments in brackets matching any segment:
+//
+//	views/index.html               GET /{$}
+//	views/about.html               GET /about
+//	views/users/index.html         GET /users
+//	views/users/[id]/edit.html     GET /users/{id}/edit
+//	views/docs/[...path].html      GET /docs/{path...}
+//
+// The values of the segments are available to templates as .Params, e.g. {{.Params.id}}. Routes needing data have a
+// loader, which adds it to the response before it renders:
+//
+//	routes, err := fileroutes.Register(mux, hv, adapter, fileroutes.Options{
+//		Loaders: map[string]fileroutes.Loader{
+//			"users/[id]/edit": func(r *http.Request, resp *response.Response) error {
+//				user, err := users.Find(r.Context(), r.PathValue("id"))
+//				if errors.Is(err, users.ErrNotFound) {
+//					return fileroutes.ErrNotFound
+//				}
+//				resp.AddDataItem("User", user)
+//				return err
+//			},
+//		},
+//	})
+package fileroutes
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/response"
+)
+
+// ErrNotFound is returned by loaders to answer with the not found page.
+var ErrNotFound = errors.New("not found")
+
+// Loader loads the data of a route into the response before it renders, e.g. with Response.AddDataItem or
+// Response.Load. Returning ErrNotFound answers with the not found page, and other errors with the error page.
+type Loader func(r *http.Request, resp *response.Response) error
+
+// Lister lists the views to route. It is implemented by *hyperview.TemplateAdapter.
+type Lister interface {
+	// Views returns the names of the views, e.g. views/home/index.
+	Views() []string
+}
+
+// Renderer renders the views. It is implemented by *hyperview.HyperView.
+type Renderer interface {
+	Render(w http.ResponseWriter, r *http.Request, resp *response.Response)
+	RenderNotFound(w http.ResponseWriter, r *http.Request)
+	RenderSystemError(w http.ResponseWriter, r *http.Request, err error)
+}
+
+// Options are the options for the routes.
+type Options struct {
+	// Prefix is the path the routes are served under, e.g. /docs. Default is the root.
+	Prefix string
+	// Loaders load the data of the routes, keyed by the path of their view without the views directory and extension,
+	// e.g. users/[id]/edit.
+	Loaders map[string]Loader
+	// Include reports whether the view is routed, given the path of the view like Loaders. Default is all the views but
+	// those of the system directory, such as the error pages. Views whose file name has a dot, such as the locale
+	// variants of views (index.de.html), template variants (index@b.html) and the views of file systems other than
+	// the root one are never routed.
+	Include func(view string) bool
+}
+
+// Route is the route of a view.
+type Route struct {
+	// Pattern is the net/http.ServeMux pattern of the route, e.g. GET /users/{id}/edit.
+	Pattern string
+	// View is the path of the view without the views directory and extension, e.g. users/[id]/edit.
+	View string
+	// Params are the names of the path parameters of the route, e.g. id.
+	Params []string
+}
+
+// Routes returns the routes of the views, in the order of the views. It fails if a view has an invalid segment, such
+// as an empty parameter name or a catch-all segment which isn't the last one.
+func Routes(views []string, opts Options) ([]Route, error) {
+	include := opts.Include
+	if include == nil {
+		include = func(view string) bool {
+			return view != constants.SystemDir && !strings.HasPrefix(view, constants.SystemDir+"/")
+		}
+	}
+	prefix := strings.TrimSuffix(opts.Prefix, "/")
+
+	var routes []Route
+	for _, name := range views {
+		view, ok := strings.CutPrefix(name, constants.ViewsDir+"/")
+		if !ok || isVariant(path.Base(view)) || !include(view) {
+			continue
+		}
+
+		route, err := viewRoute(view, prefix)
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, route)
+	}
+	return routes, nil
+}
+
+// isVariant reports whether the file name of a view is the name of a locale or template variant, e.g. index.de or
+// index@b.
+func isVariant(base string) bool {
+	if strings.HasPrefix(base, "[") && strings.HasSuffix(base, "]") {
+		return false
+	}
+	return strings.ContainsAny(base, ".@")
+}
+
+// viewRoute returns the route of the view.
+func viewRoute(view, prefix string) (Route, error) {
+	route := Route{View: view}
+	segments := strings.Split(view, "/")
+	if segments[len(segments)-1] == "index" {
+		segments = segments[:len(segments)-1]
+	}
+
+	for i, segment := range segments {
+		param, ok := strings.CutPrefix(segment, "[")
+		if !ok {
+			continue
+		}
+		param, ok = strings.CutSuffix(param, "]")
+		catchAll := strings.HasPrefix(param, "...")
+		param = strings.TrimPrefix(param, "...")
+		if !ok || param == "" || strings.ContainsAny(param, "[]{}") {
+			return Route{}, fmt.Errorf("invalid segment %s of view %s", segment, view)
+		}
+		if catchAll && i != len(segments)-1 {
+			return Route{}, fmt.Errorf("catch-all segment %s of view %s must be the last one", segment, view)
+		}
+
+		route.Params = append(route.Params, param)
+		if catchAll {
+			segments[i] = "{" + param + "...}"
+		} else {
+			segments[i] = "{" + param + "}"
+		}
+	}
+
+	urlPath := prefix + "/" + strings.Join(segments, "/")
+	if len(segments) == 0 {
+		urlPath += "{$}"
+	}
+	route.Pattern = http.MethodGet + " " + urlPath
+	return route, nil
+}
+
+// Register registers the routes of the views listed by lister on mux, rendered with renderer, and returns them. Like
+// the routes of net/http.ServeMux, conflicting routes, such as users/[id] and users/[name], panic.
+func Register(mux *http.ServeMux, renderer Renderer, lister Lister, opts Options) ([]Route, error) {
+	routes, err := Routes(lister.Views(), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, route := range routes {
+		mux.Handle(route.Pattern, Handler(renderer, route, opts.Loaders[route.View]))
+	}
+	return routes, nil
+}
+
+// Handler returns the handler rendering the view of the route, with the data of the loader, which can be nil.
+func Handler(renderer Renderer, route Route, loader Loader) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		params := make(map[string]string, len(route.Params))
+		for _, param := range route.Params {
+			params[param] = r.PathValue(param)
+		}
+
+		resp := response.NewResponse().Path(route.View).AddDataItem("Params", params)
+		if loader != nil {
+			if err := loader(r, resp); errors.Is(err, ErrNotFound) {
+				renderer.RenderNotFound(w, r)
+				return
+			} else if err != nil {
+				renderer.RenderSystemError(w, r, fmt.Errorf("error loading %s: %w", route.View, err))
+				return
+			}
+		}
+		renderer.Render(w, r, resp)
+	})
+}
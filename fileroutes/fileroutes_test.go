@@ -0,0 +1,144 @@
+package fileroutes_test
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/fileroutes"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func TestRoutes(t *testing.T) {
+	tests := []struct {
+		name    string
+		views   []string
+		opts    fileroutes.Options
+		want    []fileroutes.Route
+		wantErr string
+	}{
+		{
+			name:  "views",
+			views: []string{"views/index", "views/about", "views/users/index", "views/users/[id]/edit", "views/docs/[...path]"},
+			want: []fileroutes.Route{
+				{Pattern: "GET /{$}", View: "index"},
+				{Pattern: "GET /about", View: "about"},
+				{Pattern: "GET /users", View: "users/index"},
+				{Pattern: "GET /users/{id}/edit", View: "users/[id]/edit", Params: []string{"id"}},
+				{Pattern: "GET /docs/{path...}", View: "docs/[...path]", Params: []string{"path"}},
+			},
+		},
+		{
+			name:  "skipped views",
+			views: []string{"views/system/404", "views/home.de", "views/home@b", "docs:views/guide", "views/home"},
+			want:  []fileroutes.Route{{Pattern: "GET /home", View: "home"}},
+		},
+		{
+			name:  "prefix",
+			views: []string{"views/index", "views/[slug]"},
+			opts:  fileroutes.Options{Prefix: "/blog/"},
+			want: []fileroutes.Route{
+				{Pattern: "GET /blog/{$}", View: "index"},
+				{Pattern: "GET /blog/{slug}", View: "[slug]", Params: []string{"slug"}},
+			},
+		},
+		{
+			name:  "include",
+			views: []string{"views/home", "views/admin/users"},
+			opts:  fileroutes.Options{Include: func(view string) bool { return !strings.HasPrefix(view, "admin/") }},
+			want:  []fileroutes.Route{{Pattern: "GET /home", View: "home"}},
+		},
+		{name: "empty parameter", views: []string{"views/users/[]"}, wantErr: "invalid segment"},
+		{name: "catch-all not last", views: []string{"views/[...path]/edit"}, wantErr: "must be the last one"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			routes, err := fileroutes.Routes(tt.views, tt.opts)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error listing routes: %v", err)
+			}
+			if !reflect.DeepEqual(routes, tt.want) {
+				t.Errorf("expected routes %v, got %v", tt.want, routes)
+			}
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{
+			"layouts/base.html":          {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+			"views/index.html":           {Data: []byte(`{{define "page:main"}}home{{end}}`)},
+			"views/users/[id]/edit.html": {Data: []byte(`{{define "page:main"}}edit {{.Params.id}} {{.User}}{{end}}`)},
+			"views/system/404.html":      {Data: []byte(`{{define "page:main"}}not found{{end}}`)},
+		}},
+		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	hv, err := hyperview.NewHyperView(hyperview.WithViewAdapter("html", adapter), hyperview.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	if err != nil {
+		t.Fatalf("error creating HyperView: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	routes, err := fileroutes.Register(mux, hv, adapter, fileroutes.Options{
+		Loaders: map[string]fileroutes.Loader{
+			"users/[id]/edit": func(r *http.Request, resp *response.Response) error {
+				switch r.PathValue("id") {
+				case "0":
+					return fileroutes.ErrNotFound
+				case "1":
+					return errors.New("database down")
+				}
+				resp.AddDataItem("User", "Ann")
+				return nil
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("error registering routes: %v", err)
+	}
+	if len(routes) != 2 {
+		t.Errorf("expected 2 routes, got %v", routes)
+	}
+
+	tests := []struct {
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{path: "/", wantStatus: http.StatusOK, wantBody: "home"},
+		{path: "/users/42/edit", wantStatus: http.StatusOK, wantBody: "edit 42 Ann"},
+		{path: "/users/0/edit", wantStatus: http.StatusNotFound, wantBody: "not found"},
+		{path: "/users/1/edit", wantStatus: http.StatusInternalServerError},
+		{path: "/system/404", wantStatus: http.StatusNotFound, wantBody: "404 page not found\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Errorf("expected body %q, got %q", tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
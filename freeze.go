@@ -0,0 +1,144 @@
+package hyperview
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrFrozen is returned by the Init of a frozen TemplateAdapter, whose templates cannot be reloaded.
+var ErrFrozen = errors.New("hyperview: templates frozen")
+
+// Freeze makes HyperView immutable for production: the adapters are frozen (see Freezer), and registering adapters
+// or reinitializing them panics. In exchange, looking up adapters no longer takes a lock. Call Freeze once the
+// adapters are registered and initialized.
+//
+// The adapters implementing FreezePreparer, such as the template adapter, are prepared first, so an error compiling
+// their templates leaves every adapter unfrozen. The adapters are then frozen in the order of their names. A Freezer
+// failing nonetheless leaves the adapters before it frozen and HyperView unfrozen, which cannot be undone: the
+// application should exit rather than serve.
+func (s *HyperView) Freeze() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	names := make([]string, 0, len(s.adapters))
+	for name := range s.adapters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if preparer, ok := s.adapters[name].(FreezePreparer); ok {
+			if err := preparer.PrepareFreeze(); err != nil {
+				return fmt.Errorf("error freezing adapter %s: %w", name, err)
+			}
+		}
+	}
+	for _, name := range names {
+		if freezer, ok := s.adapters[name].(Freezer); ok {
+			if err := freezer.Freeze(); err != nil {
+				return fmt.Errorf("error freezing adapter %s: %w", name, err)
+			}
+		}
+	}
+
+	s.frozen.Store(true)
+	return nil
+}
+
+// Frozen reports whether Freeze has been called.
+func (s *HyperView) Frozen() bool {
+	return s.frozen.Load()
+}
+
+// mustNotBeFrozen panics if HyperView is frozen, as the operation would mutate it.
+func (s *HyperView) mustNotBeFrozen(operation string) {
+	if s.frozen.Load() {
+		panic("hyperview: " + operation + " called on a frozen HyperView")
+	}
+}
+
+// Freeze makes the adapter immutable for production: the template sets of all pages are compiled ahead of time,
+// including with the layouts that extend another layout and in lazy mode, and Init returns ErrFrozen. The
+// TemplateGC option no longer evicts template sets. In exchange, template lookups no longer take a lock. Errors compiling a page
+// with a layout are returned, rather than surfacing on first render.
+func (a *TemplateAdapter) Freeze() error {
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+
+	if a.frozen.Load() {
+		return nil
+	}
+
+	a.gc.setPaused(true)
+	if err := a.compileAll(); err != nil {
+		a.gc.setPaused(false)
+		return err
+	}
+	a.frozen.Store(true)
+	return nil
+}
+
+// Frozen reports whether Freeze has been called, after which the templates can no longer be reloaded.
+func (a *TemplateAdapter) Frozen() bool {
+	return a.frozen.Load()
+}
+
+// PrepareFreeze compiles the template sets of all pages, as Freeze does, without freezing the adapter, so
+// HyperView.Freeze leaves every adapter unfrozen if one fails to compile. The template sets are kept, so Freeze only
+// compiles those evicted by the TemplateGC option in between.
+func (a *TemplateAdapter) PrepareFreeze() error {
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+
+	if a.frozen.Load() {
+		return nil
+	}
+
+	a.gc.setPaused(true)
+	defer a.gc.setPaused(false)
+	return a.compileAll()
+}
+
+// compileAll compiles the template sets of all pages with all layouts, including the content page. The TemplateGC
+// option must be paused, so the template sets compiled first are not evicted by those compiled last.
+func (a *TemplateAdapter) compileAll() error {
+	pages := make([]string, 0, len(a.pages))
+	for page := range a.pages {
+		if page != contentPage {
+			pages = append(pages, page)
+		}
+	}
+	sort.Strings(pages)
+
+	layouts := make([]string, 0, len(a.layoutChains)+1)
+	for layout := range a.layoutChains {
+		layouts = append(layouts, layout)
+	}
+	if a.lazyPages {
+		// Pages rendered with a root layout share a template set, whatever the layout
+		layouts = append(layouts, "")
+	}
+
+	for _, layout := range layouts {
+		for _, page := range pages {
+			if _, _, err := a.lookupTemplate(page, layout, a.strict); err != nil {
+				return fmt.Errorf("error compiling %s with layout %s: %w", page, layout, err)
+			}
+		}
+	}
+
+	// The content page is always compiled on first use, so it is compiled with every layout, root layouts included
+	if _, ok := a.pages[contentPage]; ok {
+		contentLayouts := append([]string(nil), layouts...)
+		if !a.lazyPages {
+			contentLayouts = append(contentLayouts, "")
+		}
+		for _, layout := range contentLayouts {
+			if _, _, err := a.lookupTemplate(contentPage, layout, a.strict); err != nil {
+				return fmt.Errorf("error compiling content with layout %s: %w", layout, err)
+			}
+		}
+	}
+	return nil
+}
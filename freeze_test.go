@@ -0,0 +1,119 @@
+package hyperview_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func TestTemplateAdapter_Freeze(t *testing.T) {
+	adapter := newTestTemplateAdapter(t, fstest.MapFS{
+		"layouts/base.html":  {Data: []byte(`{{define "layout:base"}}<b>{{block "body" .}}{{template "page:main" .}}{{end}}</b>{{end}}`)},
+		"layouts/admin.html": {Data: []byte(`{{/* extends "base" */}}{{define "body"}}admin:{{template "page:main" .}}{{end}}`)},
+		"views/home.html":    {Data: []byte(`{{define "page:main"}}home{{end}}`)},
+	}, hyperview.TemplateViewAdapterOptions{})
+
+	if err := adapter.Freeze(); err != nil {
+		t.Fatalf("error freezing adapter: %v", err)
+	}
+
+	for layout, want := range map[string]string{"base": "<b>home</b>", "admin": "<b>admin:home</b>"} {
+		w := renderTestTemplate(t, adapter, response.NewResponse().Layout(layout).Path("home"))
+		if got := w.Body.String(); got != want {
+			t.Errorf("unexpected body with layout %s: got %q, want %q", layout, got, want)
+		}
+	}
+
+	if err := adapter.Init(); !errors.Is(err, hyperview.ErrFrozen) {
+		t.Errorf("Init() error = %v, want %v", err, hyperview.ErrFrozen)
+	}
+}
+
+func TestTemplateAdapter_FreezeError(t *testing.T) {
+	// Extending layouts are only parsed when a page is first rendered with them, so Init doesn't catch this error
+	adapter := newTestTemplateAdapter(t, fstest.MapFS{
+		"layouts/base.html":  {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+		"layouts/admin.html": {Data: []byte(`{{/* extends "base" */}}{{define "body"}}{{if}}{{end}}`)},
+		"views/home.html":    {Data: []byte(`{{define "page:main"}}home{{end}}`)},
+	}, hyperview.TemplateViewAdapterOptions{})
+
+	err := adapter.Freeze()
+	if err == nil || !strings.Contains(err.Error(), "error compiling views/home with layout admin") {
+		t.Errorf("Freeze() error = %v, want a compile error", err)
+	}
+}
+
+func TestHyperView_Freeze(t *testing.T) {
+	hv, err := hyperview.NewHyperView()
+	if err != nil {
+		t.Fatalf("error creating HyperView: %v", err)
+	}
+
+	adapter := &mockViewAdapter{}
+	if err := hv.RegisterAdapter("html", adapter); err != nil {
+		t.Fatalf("error registering adapter: %v", err)
+	}
+
+	if err := hv.Freeze(); err != nil {
+		t.Fatalf("error freezing: %v", err)
+	}
+	if !hv.Frozen() {
+		t.Error("expected HyperView to be frozen")
+	}
+
+	if got, ok := hv.Adapter("html"); !ok || got != adapter {
+		t.Error("expected the adapter to be found after freezing")
+	}
+
+	for name, mutate := range map[string]func(){
+		"RegisterAdapter": func() { _ = hv.RegisterAdapter("other", &mockViewAdapter{}) },
+		"Reinit":          func() { _ = hv.Reinit() },
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected %s to panic on a frozen HyperView", name)
+				}
+			}()
+			mutate()
+		})
+	}
+}
+
+// TestHyperView_FreezeError checks an adapter failing to compile its templates leaves every adapter unfrozen, whatever
+// the order the adapters are frozen in.
+func TestHyperView_FreezeError(t *testing.T) {
+	hv, err := hyperview.NewHyperView()
+	if err != nil {
+		t.Fatalf("error creating HyperView: %v", err)
+	}
+
+	valid := newTestTemplateAdapter(t, fstest.MapFS{
+		"layouts/base.html": {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+		"views/home.html":   {Data: []byte(`{{define "page:main"}}home{{end}}`)},
+	}, hyperview.TemplateViewAdapterOptions{})
+	invalid := newTestTemplateAdapter(t, fstest.MapFS{
+		"layouts/base.html":  {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+		"layouts/admin.html": {Data: []byte(`{{/* extends "base" */}}{{define "body"}}{{if}}{{end}}`)},
+		"views/home.html":    {Data: []byte(`{{define "page:main"}}home{{end}}`)},
+	}, hyperview.TemplateViewAdapterOptions{})
+	for name, adapter := range map[string]hyperview.Adapter{"html": valid, "pages": invalid} {
+		if err := hv.RegisterAdapter(name, adapter); err != nil {
+			t.Fatalf("error registering adapter %s: %v", name, err)
+		}
+	}
+
+	if err := hv.Freeze(); err == nil || !strings.Contains(err.Error(), "error freezing adapter pages") {
+		t.Fatalf("Freeze() error = %v, want an error freezing the pages adapter", err)
+	}
+	if hv.Frozen() {
+		t.Error("expected HyperView not to be frozen")
+	}
+	if err := hv.Reinit(); err != nil {
+		t.Errorf("expected the adapters to be reinitialized after a failed Freeze, got %v", err)
+	}
+}
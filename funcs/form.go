@@ -1,6 +1,13 @@
 package funcs
 
-import "fmt"
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"net/url"
+	"sort"
+	"strings"
+)
 
 func attrsToMap(data map[string]any, specialAttrs map[string]string, attrs ...any) (map[string]any, error) {
 	attributes := make(map[string]string)
@@ -82,3 +89,345 @@ func InputAttrs(nameID string, attrs ...any) (map[string]any, error) {
 
 	return data, nil
 }
+
+// SelectOption is an option of a select field.
+type SelectOption struct {
+	Value string
+	Label string
+}
+
+// formField is a field of a form helper, with the attributes given as key/value pairs and the error and previous
+// value of the field found in the render data.
+type formField struct {
+	name   string
+	id     string
+	label  string
+	hint   string
+	class  string
+	value  string
+	hasOld bool
+	old    []string
+	// submitted reports whether the render data has old input, i.e. the form was submitted
+	submitted bool
+	err       string
+	attrs     [][2]string
+	checked   bool
+}
+
+// newFormField binds a field to the Errors and Old values of the render data. data is the data of the page, or the
+// .View accessor.
+func newFormField(data any, name string, attrs []any) (*formField, error) {
+	if len(attrs)%2 != 0 {
+		return nil, fmt.Errorf("form field %s expects attributes as key/value pairs, received odd number of arguments", name)
+	}
+
+	f := &formField{name: name, id: fieldID(name)}
+	f.err = fieldError(lookupData(data, "Errors"), name)
+	old := lookupData(data, "Old")
+	f.old, f.hasOld = fieldValues(old, name)
+	f.submitted = old != nil && old != ""
+
+	for i := 0; i < len(attrs); i += 2 {
+		key, ok := attrs[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("form field %s: attribute key at position %d is not a string", name, i)
+		}
+
+		value := attrs[i+1]
+		switch key {
+		case "label":
+			f.label = fmt.Sprint(value)
+		case "hint":
+			f.hint = fmt.Sprint(value)
+		case "class":
+			f.class = fmt.Sprint(value)
+		case "id":
+			f.id = fmt.Sprint(value)
+		case "value":
+			f.value = fmt.Sprint(value)
+		case "error":
+			// An explicit error takes precedence over the error of the render data
+			f.err = fmt.Sprint(value)
+		case "checked":
+			f.checked, _ = value.(bool)
+		default:
+			switch v := value.(type) {
+			case bool:
+				if v {
+					f.attrs = append(f.attrs, [2]string{key, ""})
+				}
+			default:
+				f.attrs = append(f.attrs, [2]string{key, fmt.Sprint(v)})
+			}
+		}
+	}
+
+	return f, nil
+}
+
+// lookupData returns the value of key in the render data, a map or a value with a Get method such as .View.
+func lookupData(data any, key string) any {
+	switch d := data.(type) {
+	case map[string]any:
+		return d[key]
+	case interface{ Get(string) any }:
+		return d.Get(key)
+	}
+	return nil
+}
+
+// fieldError returns the error of a field in the field errors of the render data.
+func fieldError(errors any, name string) string {
+	switch e := errors.(type) {
+	case map[string]string:
+		return e[name]
+	case map[string][]string:
+		return strings.Join(e[name], " ")
+	case map[string]any:
+		if msg, ok := e[name]; ok && msg != nil {
+			return fmt.Sprint(msg)
+		}
+	}
+	return ""
+}
+
+// fieldValues returns the previously submitted values of a field, and whether the field was submitted.
+func fieldValues(old any, name string) ([]string, bool) {
+	switch o := old.(type) {
+	case url.Values:
+		values, ok := o[name]
+		return values, ok
+	case map[string][]string:
+		values, ok := o[name]
+		return values, ok
+	case map[string]string:
+		value, ok := o[name]
+		return []string{value}, ok
+	}
+	return nil, false
+}
+
+// fieldID returns the ID of a field named name, e.g. address[city] -> address-city.
+func fieldID(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-':
+			b.WriteRune(r)
+		case b.Len() > 0 && !strings.HasSuffix(b.String(), "-"):
+			b.WriteByte('-')
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+// writeLabel writes the label of the field, if it has one.
+func (f *formField) writeLabel(b *strings.Builder) {
+	if f.label != "" {
+		fmt.Fprintf(b, `<label for="%s">%s</label>`, html.EscapeString(f.id), html.EscapeString(f.label))
+	}
+}
+
+// writeAttrs writes the common attributes of the control of the field: id, name, class, aria attributes and the
+// additional attributes.
+func (f *formField) writeAttrs(b *strings.Builder) {
+	fmt.Fprintf(b, ` id="%s" name="%s"`, html.EscapeString(f.id), html.EscapeString(f.name))
+	if f.class != "" {
+		fmt.Fprintf(b, ` class="%s"`, html.EscapeString(f.class))
+	}
+
+	var describedBy []string
+	if f.hint != "" {
+		describedBy = append(describedBy, f.id+"-hint")
+	}
+	if f.err != "" {
+		b.WriteString(` aria-invalid="true"`)
+		describedBy = append(describedBy, f.id+"-error")
+	}
+	if len(describedBy) > 0 {
+		fmt.Fprintf(b, ` aria-describedby="%s"`, html.EscapeString(strings.Join(describedBy, " ")))
+	}
+
+	for _, attr := range f.attrs {
+		if attr[1] == "" {
+			fmt.Fprintf(b, ` %s`, html.EscapeString(attr[0]))
+		} else {
+			fmt.Fprintf(b, ` %s="%s"`, html.EscapeString(attr[0]), html.EscapeString(attr[1]))
+		}
+	}
+}
+
+// writeMessages writes the hint and the error of the field, if any.
+func (f *formField) writeMessages(b *strings.Builder) {
+	if f.hint != "" {
+		fmt.Fprintf(b, `<p id="%s-hint" class="field-hint">%s</p>`, html.EscapeString(f.id), html.EscapeString(f.hint))
+	}
+	b.WriteString(errorMarkup(f.id, f.err))
+}
+
+func errorMarkup(id, msg string) string {
+	if msg == "" {
+		return ""
+	}
+	return fmt.Sprintf(`<p id="%s-error" class="field-error" role="alert">%s</p>`, html.EscapeString(id), html.EscapeString(msg))
+}
+
+// Input renders a labelled input field bound to the render data: the previously submitted value, from Old, is filled
+// back in, except for password fields, and the error of the field, from Errors, is rendered after it and linked with
+// aria attributes. attrs are key/value pairs: label, hint, type (default text), value (the value when the form was
+// not submitted), class, id and error set the field, and the others are added to the input, with true booleans as
+// attributes without a value.
+//
+// Example: {{input . "email" "label" "Email" "type" "email" "required" true}}
+func Input(data any, name string, attrs ...any) (template.HTML, error) {
+	typ := "text"
+	for i := 0; i+1 < len(attrs); i += 2 {
+		if attrs[i] == "type" {
+			typ = fmt.Sprint(attrs[i+1])
+			attrs = append(attrs[:i:i], attrs[i+2:]...)
+			break
+		}
+	}
+
+	f, err := newFormField(data, name, attrs)
+	if err != nil {
+		return "", err
+	}
+
+	value := f.value
+	if f.hasOld && typ != "password" {
+		value = ""
+		if len(f.old) > 0 {
+			value = f.old[0]
+		}
+	}
+
+	var b strings.Builder
+	f.writeLabel(&b)
+	fmt.Fprintf(&b, `<input type="%s"`, html.EscapeString(typ))
+	f.writeAttrs(&b)
+	if value != "" && typ != "password" {
+		fmt.Fprintf(&b, ` value="%s"`, html.EscapeString(value))
+	}
+	b.WriteString(">")
+	f.writeMessages(&b)
+
+	return template.HTML(b.String()), nil
+}
+
+// Select renders a labelled select field bound to the render data like Input, with the previously submitted value
+// selected. options are a []SelectOption, a []string of values that are their own labels, or a map[string]string of
+// labels keyed by value, sorted by label. The value attribute selects an option when the form was not submitted.
+//
+// Example: {{select . "country" .Countries "label" "Country"}}
+func Select(data any, name string, options any, attrs ...any) (template.HTML, error) {
+	f, err := newFormField(data, name, attrs)
+	if err != nil {
+		return "", err
+	}
+
+	opts, err := selectOptions(options)
+	if err != nil {
+		return "", fmt.Errorf("select %s: %w", name, err)
+	}
+
+	selected := map[string]bool{f.value: f.value != ""}
+	if f.hasOld {
+		selected = make(map[string]bool, len(f.old))
+		for _, value := range f.old {
+			selected[value] = true
+		}
+	}
+
+	var b strings.Builder
+	f.writeLabel(&b)
+	b.WriteString("<select")
+	f.writeAttrs(&b)
+	b.WriteString(">")
+	for _, opt := range opts {
+		fmt.Fprintf(&b, `<option value="%s"`, html.EscapeString(opt.Value))
+		if selected[opt.Value] {
+			b.WriteString(" selected")
+		}
+		fmt.Fprintf(&b, ">%s</option>", html.EscapeString(opt.Label))
+	}
+	b.WriteString("</select>")
+	f.writeMessages(&b)
+
+	return template.HTML(b.String()), nil
+}
+
+func selectOptions(options any) ([]SelectOption, error) {
+	switch o := options.(type) {
+	case nil:
+		return nil, nil
+	case []SelectOption:
+		return o, nil
+	case []string:
+		opts := make([]SelectOption, len(o))
+		for i, value := range o {
+			opts[i] = SelectOption{Value: value, Label: value}
+		}
+		return opts, nil
+	case map[string]string:
+		opts := make([]SelectOption, 0, len(o))
+		for value, label := range o {
+			opts = append(opts, SelectOption{Value: value, Label: label})
+		}
+		sort.Slice(opts, func(i, j int) bool {
+			if opts[i].Label != opts[j].Label {
+				return opts[i].Label < opts[j].Label
+			}
+			return opts[i].Value < opts[j].Value
+		})
+		return opts, nil
+	}
+	return nil, fmt.Errorf("unsupported options type %T", options)
+}
+
+// Checkbox renders a checkbox bound to the render data like Input, followed by its label. The checkbox is checked if
+// its value (default "on") was submitted or, when the render data has no old input, if the checked attribute is true.
+// As browsers do not submit unchecked checkboxes, any old input means the form was submitted.
+//
+// Example: {{checkbox . "terms" "label" "I accept the terms" "required" true}}
+func Checkbox(data any, name string, attrs ...any) (template.HTML, error) {
+	f, err := newFormField(data, name, attrs)
+	if err != nil {
+		return "", err
+	}
+
+	value := f.value
+	if value == "" {
+		value = "on"
+	}
+	checked := f.checked
+	if f.submitted {
+		checked = false
+		for _, old := range f.old {
+			if old == value {
+				checked = true
+			}
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(`<input type="checkbox"`)
+	f.writeAttrs(&b)
+	fmt.Fprintf(&b, ` value="%s"`, html.EscapeString(value))
+	if checked {
+		b.WriteString(" checked")
+	}
+	b.WriteString(">")
+	f.writeLabel(&b)
+	f.writeMessages(&b)
+
+	return template.HTML(b.String()), nil
+}
+
+// ErrorsFor renders the error of a field from the Errors of the render data, with the ID the aria attributes of the
+// other helpers refer to, or nothing if the field has no error. It is useful for fields rendered by hand.
+//
+// Example: {{errorsFor . "email"}}
+func ErrorsFor(data any, name string) template.HTML {
+	return template.HTML(errorMarkup(fieldID(name), fieldError(lookupData(data, "Errors"), name)))
+}
@@ -0,0 +1,177 @@
+package funcs_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/hypergopher/hyperview/funcs"
+)
+
+func TestInput(t *testing.T) {
+	submitted := map[string]any{
+		"Errors": map[string]string{"email": "Email is invalid"},
+		"Old":    url.Values{"email": {`ada@example<.com`}, "password": {"secret"}, "name": {""}},
+	}
+
+	tests := []struct {
+		name  string
+		data  any
+		field string
+		attrs []any
+		want  string
+	}{
+		{
+			name:  "error and old input",
+			data:  submitted,
+			field: "email",
+			attrs: []any{"label", "Email", "type", "email", "required", true, "autofocus", false},
+			want: `<label for="email">Email</label><input type="email" id="email" name="email" aria-invalid="true" ` +
+				`aria-describedby="email-error" required value="ada@example&lt;.com">` +
+				`<p id="email-error" class="field-error" role="alert">Email is invalid</p>`,
+		},
+		{
+			name:  "password is not filled back in",
+			data:  submitted,
+			field: "password",
+			attrs: []any{"type", "password"},
+			want:  `<input type="password" id="password" name="password">`,
+		},
+		{
+			name:  "submitted empty value wins over default",
+			data:  submitted,
+			field: "name",
+			attrs: []any{"value", "Ada"},
+			want:  `<input type="text" id="name" name="name">`,
+		},
+		{
+			name:  "default value and hint",
+			data:  map[string]any{},
+			field: "address[city]",
+			attrs: []any{"value", "Paris", "hint", "Where you live", "class", "input"},
+			want: `<input type="text" id="address-city" name="address[city]" class="input" aria-describedby="address-city-hint" value="Paris">` +
+				`<p id="address-city-hint" class="field-hint">Where you live</p>`,
+		},
+		{
+			name:  "field errors as lists",
+			data:  map[string]any{"Errors": map[string][]string{"age": {"Too young.", "Must be a number."}}},
+			field: "age",
+			want: `<input type="text" id="age" name="age" aria-invalid="true" aria-describedby="age-error">` +
+				`<p id="age-error" class="field-error" role="alert">Too young. Must be a number.</p>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := funcs.Input(tt.data, tt.field, tt.attrs...)
+			if err != nil {
+				t.Fatalf("Input() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Input() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := funcs.Input(submitted, "email", "label"); err == nil {
+		t.Error("Input() with an odd number of attributes: expected an error")
+	}
+}
+
+func TestSelect(t *testing.T) {
+	options := []funcs.SelectOption{{Value: "fr", Label: "France"}, {Value: "de", Label: "Germany"}}
+
+	tests := []struct {
+		name    string
+		data    any
+		options any
+		attrs   []any
+		want    string
+	}{
+		{
+			name:    "old input selected",
+			data:    map[string]any{"Old": url.Values{"country": {"de"}}},
+			options: options,
+			attrs:   []any{"label", "Country", "value", "fr"},
+			want: `<label for="country">Country</label><select id="country" name="country">` +
+				`<option value="fr">France</option><option value="de" selected>Germany</option></select>`,
+		},
+		{
+			name:    "default value and map options",
+			data:    map[string]any{},
+			options: map[string]string{"de": "Germany", "fr": "France"},
+			attrs:   []any{"value", "fr"},
+			want: `<select id="country" name="country">` +
+				`<option value="fr" selected>France</option><option value="de">Germany</option></select>`,
+		},
+		{
+			name:    "string options",
+			data:    map[string]any{"Errors": map[string]string{"country": "Required"}},
+			options: []string{"fr"},
+			want: `<select id="country" name="country" aria-invalid="true" aria-describedby="country-error">` +
+				`<option value="fr">fr</option></select><p id="country-error" class="field-error" role="alert">Required</p>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := funcs.Select(tt.data, "country", tt.options, tt.attrs...)
+			if err != nil {
+				t.Fatalf("Select() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Select() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckbox(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  any
+		attrs []any
+		want  string
+	}{
+		{
+			name:  "submitted",
+			data:  map[string]any{"Old": url.Values{"terms": {"on"}}},
+			attrs: []any{"label", "I accept"},
+			want:  `<input type="checkbox" id="terms" name="terms" value="on" checked><label for="terms">I accept</label>`,
+		},
+		{
+			name:  "unchecked on submit",
+			data:  map[string]any{"Old": url.Values{"other": {"x"}}, "Errors": map[string]string{}},
+			attrs: []any{"checked", true},
+			want:  `<input type="checkbox" id="terms" name="terms" value="on">`,
+		},
+		{
+			name:  "checked by default",
+			data:  map[string]any{},
+			attrs: []any{"checked", true, "value", "yes"},
+			want:  `<input type="checkbox" id="terms" name="terms" value="yes" checked>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := funcs.Checkbox(tt.data, "terms", tt.attrs...)
+			if err != nil {
+				t.Fatalf("Checkbox() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Checkbox() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorsFor(t *testing.T) {
+	data := map[string]any{"Errors": map[string]string{"email": "Taken <b>"}}
+
+	if got, want := funcs.ErrorsFor(data, "email"), `<p id="email-error" class="field-error" role="alert">Taken &lt;b&gt;</p>`; string(got) != want {
+		t.Errorf("ErrorsFor() = %s, want %s", got, want)
+	}
+	if got := funcs.ErrorsFor(data, "name"); got != "" {
+		t.Errorf("ErrorsFor() of a field without error = %s", got)
+	}
+}
@@ -11,24 +11,33 @@ var FuncMap = template.FuncMap{
 	"yesno": YesNo,
 
 	// Forms
+	"checkbox":   Checkbox,
+	"errorsFor":  ErrorsFor,
+	"input":      Input,
 	"inputAttrs": InputAttrs,
+	"select":     Select,
 
 	// HTML
-	"safeHTML": safeHTML,
-	"safeAttr": safeAttr,
-	"safeCSS":  safeCSS,
-	"safeJS":   safeJS,
-	"safeURL":  safeURL,
+	"jsonScript": JSONScript,
+	"safeHTML":   safeHTML,
+	"safeAttr":   safeAttr,
+	"safeCSS":    safeCSS,
+	"safeJS":     safeJS,
+	"safeURL":    safeURL,
 
 	// Maps
 	"classMap": ClassMap,
+	"dict":     Dict,
 
 	// Math
 	"isEven": isEven,
 	"isOdd":  isOdd,
 
 	// Numbers
-	"int": toInt64,
+	"comma":         Comma,
+	"currency":      Currency,
+	"humanizeBytes": HumanizeBytes,
+	"int":           toInt64,
 
 	// Slices
 	"slice": slice,
@@ -54,7 +63,9 @@ var FuncMap = template.FuncMap{
 	"upper":      strings.ToUpper,
 
 	// Time
-	"now":   time.Now,
-	"since": time.Since,
-	"until": time.Until,
+	"formatDate": FormatDate,
+	"now":        time.Now,
+	"since":      time.Since,
+	"timeago":    TimeAgo,
+	"until":      time.Until,
 }
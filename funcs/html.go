@@ -1,6 +1,8 @@
 package funcs
 
 import (
+	"encoding/json"
+	"fmt"
 	"html/template"
 )
 
@@ -25,3 +27,15 @@ func safeJS(s string) template.JS {
 func safeURL(s string) template.URL {
 	return template.URL(s)
 }
+
+// JSONScript renders data as JSON in a <script type="application/json"> element with the given ID, for scripts to read
+// with JSON.parse(document.getElementById(id).textContent) instead of data interpolated into JavaScript. The JSON
+// escapes <, > and &, so no value can close the element or open a comment in it.
+func JSONScript(id string, data any) (template.HTML, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return "", fmt.Errorf("error encoding JSON script %s: %w", id, err)
+	}
+	return template.HTML(`<script type="application/json" id="` + template.HTMLEscapeString(id) + `">` + string(b) +
+		`</script>`), nil
+}
@@ -0,0 +1,77 @@
+package funcs_test
+
+import (
+	"html/template"
+	"strings"
+	"testing"
+
+	"github.com/hypergopher/hyperview/funcs"
+)
+
+func TestJSONScript(t *testing.T) {
+	got, err := funcs.JSONScript(`data"x`, map[string]any{"title": "</script><script>alert(1)</script>", "note": "<!-- & -->"})
+	if err != nil {
+		t.Fatalf("JSONScript() error = %v", err)
+	}
+
+	want := `<script type="application/json" id="data&#34;x">` +
+		`{"note":"\u003c!-- \u0026 --\u003e","title":"\u003c/script\u003e\u003cscript\u003ealert(1)\u003c/script\u003e"}</script>`
+	if string(got) != want {
+		t.Errorf("JSONScript() = %s, want %s", got, want)
+	}
+
+	if _, err := funcs.JSONScript("data", func() {}); err == nil {
+		t.Error("JSONScript() of a func: expected an error")
+	}
+}
+
+// TestTrustedHelpers_Contexts checks the HTML returned by the helpers is only trusted in HTML contexts: in script,
+// style and URL contexts, html/template escapes it like any other value.
+func TestTrustedHelpers_Contexts(t *testing.T) {
+	payload := `</script><script>alert(1)</script>`
+
+	tests := []struct {
+		name string
+		tmpl string
+		want string
+	}{
+		{
+			name: "HTML context",
+			tmpl: `<div>{{safeHTML .}}</div>`,
+			want: `<div></script><script>alert(1)</script></div>`,
+		},
+		{
+			name: "script context",
+			tmpl: `<script>var html = {{safeHTML .}};</script>`,
+			want: `<script>var html = "\u003c/script\u003e\u003cscript\u003ealert(1)\u003c/script\u003e";</script>`,
+		},
+		{
+			name: "style context",
+			tmpl: `<p style="color: {{safeHTML .}}"></p>`,
+			want: `<p style="color: ZgotmplZ"></p>`,
+		},
+		{
+			name: "URL context",
+			tmpl: `<a href="{{safeHTML .}}"></a>`,
+			want: `<a href="%3c/script%3e%3cscript%3ealert%281%29%3c/script%3e"></a>`,
+		},
+		{
+			name: "JSON script in an attribute",
+			tmpl: `<div title="{{jsonScript "data" .}}"></div>`,
+			want: `<div title=""></div>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl := template.Must(template.New("test").Funcs(template.FuncMap(funcs.FuncMap)).Parse(tt.tmpl))
+			var b strings.Builder
+			if err := tmpl.Execute(&b, payload); err != nil {
+				t.Fatalf("error executing template: %v", err)
+			}
+			if got := b.String(); got != tt.want {
+				t.Errorf("unexpected output:\ngot  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
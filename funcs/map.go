@@ -32,3 +32,23 @@ func ClassMap(classMap ...any) (string, error) {
 
 	return strings.Join(classes, " "), nil
 }
+
+// Dict creates a map from a list of key/value pairs. This is useful for passing several values to a template or
+// component, which only accept a single data argument.
+// Example: {{template "@card" (dict "Title" .Title "Body" .Body)}}
+func Dict(values ...any) (map[string]any, error) {
+	if len(values)%2 != 0 {
+		return nil, fmt.Errorf("Dict expects an even number of arguments")
+	}
+
+	dict := make(map[string]any, len(values)/2)
+	for i := 0; i < len(values); i += 2 {
+		key, ok := values[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("dict key at position %d is not a string", i)
+		}
+		dict[key] = values[i+1]
+	}
+
+	return dict, nil
+}
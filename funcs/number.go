@@ -3,6 +3,7 @@ package funcs
 import (
 	"fmt"
 	"strconv"
+	"strings"
 )
 
 func toInt64(i any) (int64, error) {
@@ -32,3 +33,167 @@ func toInt64(i any) (int64, error) {
 
 	return 0, fmt.Errorf("unable to convert type %T to int", i)
 }
+
+// ToFloat64 converts a number of any integer or float type, or a string holding one, to a float64, for functions
+// taking numbers of any type from templates.
+func ToFloat64(i any) (float64, error) {
+	switch v := i.(type) {
+	case float32:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	case uint64:
+		return float64(v), nil
+	case string:
+		return strconv.ParseFloat(v, 64)
+	}
+
+	n, err := toInt64(i)
+	if err != nil {
+		return 0, fmt.Errorf("unable to convert type %T to float", i)
+	}
+	return float64(n), nil
+}
+
+// Comma formats a number with commas between groups of thousands, e.g. 1234567.5 -> "1,234,567.5". Integers,
+// including uint64 values, are formatted exactly.
+func Comma(n any) (string, error) {
+	switch v := n.(type) {
+	case uint64:
+		return groupThousands(strconv.FormatUint(v, 10), ","), nil
+	case float32, float64:
+		f, _ := ToFloat64(v)
+		return formatFloat(f, -1, ",", "."), nil
+	case string:
+		if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return groupThousands(strconv.FormatInt(i, 10), ","), nil
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return "", err
+		}
+		return formatFloat(f, -1, ",", "."), nil
+	}
+
+	i, err := toInt64(n)
+	if err != nil {
+		return "", err
+	}
+	return groupThousands(strconv.FormatInt(i, 10), ","), nil
+}
+
+// formatFloat formats f with the given number of decimals (-1 for as many as needed), separating groups of thousands
+// with sep and the decimals with point.
+func formatFloat(f float64, decimals int, sep, point string) string {
+	s := strconv.FormatFloat(f, 'f', decimals, 64)
+	whole, fraction, found := strings.Cut(s, ".")
+	whole = groupThousands(whole, sep)
+	if !found {
+		return whole
+	}
+	return whole + point + fraction
+}
+
+// groupThousands inserts sep between the groups of thousands of an integer, keeping its sign.
+func groupThousands(digits string, sep string) string {
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+	if len(digits) <= 3 {
+		return sign + digits
+	}
+
+	var b strings.Builder
+	b.WriteString(sign)
+	first := len(digits) % 3
+	if first > 0 {
+		b.WriteString(digits[:first])
+	}
+	for i := first; i < len(digits); i += 3 {
+		if i > 0 {
+			b.WriteString(sep)
+		}
+		b.WriteString(digits[i : i+3])
+	}
+	return b.String()
+}
+
+// currencyFormats are the symbols and decimals of common currencies, keyed by ISO 4217 code.
+var currencyFormats = map[string]struct {
+	symbol   string
+	decimals int
+}{
+	"AUD": {"A$", 2},
+	"CAD": {"CA$", 2},
+	"CHF": {"CHF ", 2},
+	"CNY": {"CN¥", 2},
+	"EUR": {"€", 2},
+	"GBP": {"£", 2},
+	"INR": {"₹", 2},
+	"JPY": {"¥", 0},
+	"KRW": {"₩", 0},
+	"USD": {"$", 2},
+}
+
+// Currency formats an amount of the currency with the given ISO 4217 code, e.g. 1234.5 "USD" -> "$1,234.50" and
+// -5 "EUR" -> "-€5.00". Amounts are rounded to the decimals of the currency. Currencies without a known symbol are
+// formatted with their code, e.g. "1,234.50 SEK".
+func Currency(amount any, code string) (string, error) {
+	f, err := ToFloat64(amount)
+	if err != nil {
+		return "", err
+	}
+
+	code = strings.ToUpper(code)
+	format, ok := currencyFormats[code]
+	if !ok {
+		format.decimals = 2
+	}
+
+	sign := ""
+	if f < 0 {
+		sign, f = "-", -f
+	}
+	s := formatFloat(f, format.decimals, ",", ".")
+	// Amounts rounding to zero are not negative
+	if strings.Trim(s, "0.,") == "" {
+		sign = ""
+	}
+
+	if !ok {
+		return sign + s + " " + code, nil
+	}
+	return sign + format.symbol + s, nil
+}
+
+// byteUnits are the SI units of HumanizeBytes.
+var byteUnits = []string{"B", "kB", "MB", "GB", "TB", "PB", "EB"}
+
+// HumanizeBytes formats a number of bytes with SI units, e.g. 1500 -> "1.5 kB" and 82854982 -> "83 MB". Values below
+// 10 in their unit have one decimal.
+func HumanizeBytes(n any) (string, error) {
+	f, err := ToFloat64(n)
+	if err != nil {
+		return "", err
+	}
+
+	sign := ""
+	if f < 0 {
+		sign, f = "-", -f
+	}
+	if f < 1000 {
+		return fmt.Sprintf("%s%d B", sign, int64(f)), nil
+	}
+
+	unit := 0
+	for f >= 999.5 && unit < len(byteUnits)-1 {
+		f /= 1000
+		unit++
+	}
+
+	if f < 9.95 {
+		return fmt.Sprintf("%s%.1f %s", sign, f, byteUnits[unit]), nil
+	}
+	return fmt.Sprintf("%s%.0f %s", sign, f, byteUnits[unit]), nil
+}
@@ -0,0 +1,91 @@
+package funcs_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/hypergopher/hyperview/funcs"
+)
+
+func TestComma(t *testing.T) {
+	tests := []struct {
+		n    any
+		want string
+	}{
+		{0, "0"},
+		{999, "999"},
+		{1000, "1,000"},
+		{-1234567, "-1,234,567"},
+		{int64(math.MinInt64), "-9,223,372,036,854,775,808"},
+		{uint64(math.MaxUint64), "18,446,744,073,709,551,615"},
+		{1234.5, "1,234.5"},
+		{-0.25, "-0.25"},
+		{"1234567", "1,234,567"},
+		{"1234.75", "1,234.75"},
+	}
+
+	for _, tt := range tests {
+		got, err := funcs.Comma(tt.n)
+		if err != nil {
+			t.Errorf("Comma(%v) error = %v", tt.n, err)
+		} else if got != tt.want {
+			t.Errorf("Comma(%v) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+
+	if _, err := funcs.Comma("lots"); err == nil {
+		t.Error("Comma(\"lots\"): expected an error")
+	}
+}
+
+func TestCurrency(t *testing.T) {
+	tests := []struct {
+		amount any
+		code   string
+		want   string
+	}{
+		{1234.5, "USD", "$1,234.50"},
+		{-5, "eur", "-€5.00"},
+		{1234567, "JPY", "¥1,234,567"},
+		{1234.5, "JPY", "¥1,234"},
+		{99.999, "GBP", "£100.00"},
+		{-0.001, "USD", "$0.00"},
+		{1234.5, "SEK", "1,234.50 SEK"},
+		{"19.9", "CHF", "CHF 19.90"},
+	}
+
+	for _, tt := range tests {
+		got, err := funcs.Currency(tt.amount, tt.code)
+		if err != nil {
+			t.Errorf("Currency(%v, %s) error = %v", tt.amount, tt.code, err)
+		} else if got != tt.want {
+			t.Errorf("Currency(%v, %s) = %q, want %q", tt.amount, tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestHumanizeBytes(t *testing.T) {
+	tests := []struct {
+		n    any
+		want string
+	}{
+		{0, "0 B"},
+		{999, "999 B"},
+		{1000, "1.0 kB"},
+		{1500, "1.5 kB"},
+		{82854982, "83 MB"},
+		{999_999, "1.0 MB"},
+		{-2048, "-2.0 kB"},
+		{uint64(math.MaxUint64), "18 EB"},
+		{2.5e21, "2500 EB"},
+	}
+
+	for _, tt := range tests {
+		got, err := funcs.HumanizeBytes(tt.n)
+		if err != nil {
+			t.Errorf("HumanizeBytes(%v) error = %v", tt.n, err)
+		} else if got != tt.want {
+			t.Errorf("HumanizeBytes(%v) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
@@ -6,13 +6,15 @@ import (
 	"unicode"
 )
 
+// Pluralize returns singular if count is 1 or -1, and plural otherwise, including for fractional counts such as 1.5.
+// The count can be any integer or float, or a string holding one.
 func Pluralize(count any, singular string, plural string) (string, error) {
-	n, err := toInt64(count)
+	n, err := ToFloat64(count)
 	if err != nil {
 		return "", err
 	}
 
-	if n == 1 {
+	if n == 1 || n == -1 {
 		return singular, nil
 	}
 
@@ -0,0 +1,32 @@
+package funcs_test
+
+import (
+	"testing"
+
+	"github.com/hypergopher/hyperview/funcs"
+)
+
+func TestPluralize(t *testing.T) {
+	tests := []struct {
+		count any
+		want  string
+	}{
+		{0, "items"},
+		{1, "item"},
+		{-1, "item"},
+		{2, "items"},
+		{1.0, "item"},
+		{1.5, "items"},
+		{uint64(1), "item"},
+		{"1", "item"},
+	}
+
+	for _, tt := range tests {
+		got, err := funcs.Pluralize(tt.count, "item", "items")
+		if err != nil {
+			t.Errorf("Pluralize(%v) error = %v", tt.count, err)
+		} else if got != tt.want {
+			t.Errorf("Pluralize(%v) = %q, want %q", tt.count, got, tt.want)
+		}
+	}
+}
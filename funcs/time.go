@@ -3,6 +3,7 @@ package funcs
 import (
 	"fmt"
 	"math"
+	"strings"
 	"time"
 )
 
@@ -59,3 +60,161 @@ func ApproximateDuration(d time.Duration) string {
 
 	return fmt.Sprintf("%d years", dy)
 }
+
+// TimeAgo describes t relative to now, e.g. "5 minutes ago" or, for times in the future, "in 3 days". Times less
+// than a second away are "just now", and the zero time is an empty string.
+func TimeAgo(t time.Time) string {
+	return timeAgo(t, time.Now())
+}
+
+func timeAgo(t, now time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	d := now.Sub(t)
+	future := d < 0
+	if future {
+		d = -d
+	}
+	if d < time.Second {
+		return "just now"
+	}
+
+	if future {
+		return "in " + ApproximateDuration(d)
+	}
+	return ApproximateDuration(d) + " ago"
+}
+
+// namedLayouts are the layouts of the time package that FormatDate accepts by name.
+var namedLayouts = map[string]string{
+	"ANSIC":       time.ANSIC,
+	"DateOnly":    time.DateOnly,
+	"DateTime":    time.DateTime,
+	"Kitchen":     time.Kitchen,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"RFC850":      time.RFC850,
+	"TimeOnly":    time.TimeOnly,
+}
+
+// dateNames are the month and weekday names of a locale, in the order of time.Month and time.Weekday.
+type dateNames struct {
+	months     [12]string
+	weekdays   [7]string
+	shortMonth [12]string
+	shortDay   [7]string
+}
+
+// localeDateNames are the date names of the locales supported by FormatDate, keyed by language.
+var localeDateNames = map[string]dateNames{
+	"de": {
+		months:     [12]string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"},
+		weekdays:   [7]string{"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"},
+		shortMonth: [12]string{"Jan.", "Feb.", "März", "Apr.", "Mai", "Juni", "Juli", "Aug.", "Sept.", "Okt.", "Nov.", "Dez."},
+		shortDay:   [7]string{"So.", "Mo.", "Di.", "Mi.", "Do.", "Fr.", "Sa."},
+	},
+	"es": {
+		months:     [12]string{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+		weekdays:   [7]string{"domingo", "lunes", "martes", "miércoles", "jueves", "viernes", "sábado"},
+		shortMonth: [12]string{"ene", "feb", "mar", "abr", "may", "jun", "jul", "ago", "sept", "oct", "nov", "dic"},
+		shortDay:   [7]string{"dom", "lun", "mar", "mié", "jue", "vie", "sáb"},
+	},
+	"fr": {
+		months:     [12]string{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+		weekdays:   [7]string{"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi"},
+		shortMonth: [12]string{"janv.", "févr.", "mars", "avr.", "mai", "juin", "juil.", "août", "sept.", "oct.", "nov.", "déc."},
+		shortDay:   [7]string{"dim.", "lun.", "mar.", "mer.", "jeu.", "ven.", "sam."},
+	},
+	"it": {
+		months:     [12]string{"gennaio", "febbraio", "marzo", "aprile", "maggio", "giugno", "luglio", "agosto", "settembre", "ottobre", "novembre", "dicembre"},
+		weekdays:   [7]string{"domenica", "lunedì", "martedì", "mercoledì", "giovedì", "venerdì", "sabato"},
+		shortMonth: [12]string{"gen", "feb", "mar", "apr", "mag", "giu", "lug", "ago", "set", "ott", "nov", "dic"},
+		shortDay:   [7]string{"dom", "lun", "mar", "mer", "gio", "ven", "sab"},
+	},
+	"nl": {
+		months:     [12]string{"januari", "februari", "maart", "april", "mei", "juni", "juli", "augustus", "september", "oktober", "november", "december"},
+		weekdays:   [7]string{"zondag", "maandag", "dinsdag", "woensdag", "donderdag", "vrijdag", "zaterdag"},
+		shortMonth: [12]string{"jan", "feb", "mrt", "apr", "mei", "jun", "jul", "aug", "sep", "okt", "nov", "dec"},
+		shortDay:   [7]string{"zo", "ma", "di", "wo", "do", "vr", "za"},
+	},
+	"pt": {
+		months:     [12]string{"janeiro", "fevereiro", "março", "abril", "maio", "junho", "julho", "agosto", "setembro", "outubro", "novembro", "dezembro"},
+		weekdays:   [7]string{"domingo", "segunda-feira", "terça-feira", "quarta-feira", "quinta-feira", "sexta-feira", "sábado"},
+		shortMonth: [12]string{"jan", "fev", "mar", "abr", "mai", "jun", "jul", "ago", "set", "out", "nov", "dez"},
+		shortDay:   [7]string{"dom", "seg", "ter", "qua", "qui", "sex", "sáb"},
+	},
+}
+
+// FormatDate formats t with a layout of the time package, such as "2 January 2006", or the name of one, such as
+// "RFC3339" or "DateOnly". With a locale, such as "de" or "fr-CA", month and weekday names are in the language of
+// the locale; locales whose language is not supported are formatted in English.
+func FormatDate(t time.Time, layout string, locale ...string) string {
+	if named, ok := namedLayouts[layout]; ok {
+		layout = named
+	}
+
+	var names dateNames
+	found := false
+	if len(locale) > 0 {
+		lang, _, _ := strings.Cut(strings.ToLower(locale[0]), "-")
+		lang, _, _ = strings.Cut(lang, "_")
+		names, found = localeDateNames[lang]
+	}
+	if !found {
+		return t.Format(layout)
+	}
+
+	// Each name element of the layout is replaced with the localized name, and the segments between them are
+	// formatted on their own, as the elements of a layout do not depend on each other
+	var b strings.Builder
+	for layout != "" {
+		i, element := nextNameElement(layout)
+		if i == -1 {
+			b.WriteString(t.Format(layout))
+			break
+		}
+		if i > 0 {
+			b.WriteString(t.Format(layout[:i]))
+		}
+		switch element {
+		case "January":
+			b.WriteString(names.months[t.Month()-1])
+		case "Jan":
+			b.WriteString(names.shortMonth[t.Month()-1])
+		case "Monday":
+			b.WriteString(names.weekdays[t.Weekday()])
+		case "Mon":
+			b.WriteString(names.shortDay[t.Weekday()])
+		}
+		layout = layout[i+len(element):]
+	}
+	return b.String()
+}
+
+// nextNameElement returns the index of the first month or weekday name element of a layout and the element, or -1.
+// Like the time package, Jan and Mon followed by a lowercase letter are literal text, such as in "Month".
+func nextNameElement(layout string) (int, string) {
+	for i := 0; i < len(layout); i++ {
+		for _, element := range []string{"January", "Jan", "Monday", "Mon"} {
+			if !strings.HasPrefix(layout[i:], element) {
+				continue
+			}
+			if len(element) == 3 && startsWithLowerCase(layout[i+3:]) {
+				break
+			}
+			return i, element
+		}
+	}
+	return -1, ""
+}
+
+// startsWithLowerCase reports whether the string starts with a lowercase ASCII letter, as in the time package.
+func startsWithLowerCase(s string) bool {
+	return s != "" && 'a' <= s[0] && s[0] <= 'z'
+}
@@ -0,0 +1,60 @@
+package funcs_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hypergopher/hyperview/funcs"
+)
+
+func TestTimeAgo(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name string
+		t    time.Time
+		want string
+	}{
+		{"zero", time.Time{}, ""},
+		{"just now", now, "just now"},
+		{"past", now.Add(-5*time.Minute - time.Second), "5 minutes ago"},
+		{"future", now.Add(3*24*time.Hour + time.Minute), "in 3 days"},
+		{"years", now.Add(-2 * 365 * 24 * time.Hour), "2 years ago"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := funcs.TimeAgo(tt.t); got != tt.want {
+				t.Errorf("TimeAgo() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatDate(t *testing.T) {
+	date := time.Date(2024, time.March, 3, 14, 5, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		layout string
+		locale []string
+		want   string
+	}{
+		{"layout", "Monday, 2 January 2006", nil, "Sunday, 3 March 2024"},
+		{"named layout", "DateOnly", nil, "2024-03-03"},
+		{"german", "Monday, 2. January 2006", []string{"de"}, "Sonntag, 3. März 2024"},
+		{"french short names", "Mon 2 Jan 2006 15:04", []string{"fr-CA"}, "dim. 3 mars 2024 14:05"},
+		{"unsupported language", "2 January 2006", []string{"sv"}, "3 March 2024"},
+		{"no name elements", "02/01/2006", []string{"es"}, "03/03/2024"},
+		{"literal text", "Month: January", nil, "Month: March"},
+		{"localized literal text", "Month: January, Monday", []string{"fr"}, "Month: mars, dimanche"},
+		{"localized short names and literal text", "Mon Monthly Jan Janet", []string{"fr"}, "dim. Monthly mars Janet"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := funcs.FormatDate(date, tt.layout, tt.locale...); got != tt.want {
+				t.Errorf("FormatDate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
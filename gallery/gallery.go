@@ -0,0 +1,361 @@
+// Package gallery provides a development gallery of the partials and components of a template adapter, in the
+// spirit of Storybook. Each partial with registered examples is rendered in isolation with the example data, and the
+// example values can be tweaked with knobs, so designers can browse and test components without navigating the app.
+//
+// The gallery renders arbitrary partials with arbitrary data, so it should only be mounted in development.
+package gallery
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+
+	"github.com/hypergopher/hyperview"
+)
+
+// Renderer renders partials in isolation. It is implemented by *hyperview.TemplateAdapter.
+type Renderer interface {
+	// Partials returns the names of the partials.
+	Partials() []string
+	// RenderPartial renders the named partial with data.
+	RenderPartial(w io.Writer, name string, data any) error
+}
+
+// Example is a named set of data to render a partial with.
+type Example struct {
+	// Name describes the example, e.g. "Primary" or "With footer".
+	Name string
+	// Data is the data a partial is rendered with. The string, bool, int and float64 values of a map[string]any can
+	// be edited with knobs.
+	Data any
+	// Props are the props a component is rendered with, as if called from a component block. Set Props or Slots
+	// instead of Data for components. The string, bool, int and float64 props can be edited with knobs.
+	Props map[string]any
+	// Slots are the slot contents a component is rendered with, keyed by slot name.
+	Slots map[string]template.HTML
+}
+
+// Options are the options for a Gallery.
+type Options struct {
+	// Title is the title of the gallery pages. Default is "Gallery".
+	Title string
+	// Head is added to the head of the frames the examples are rendered in, e.g. the application's stylesheets.
+	Head template.HTML
+}
+
+// Gallery serves the examples of the partials of a Renderer.
+type Gallery struct {
+	renderer Renderer
+	opts     Options
+	examples map[string][]Example
+}
+
+// New creates a gallery of the partials of renderer.
+func New(renderer Renderer, opts Options) *Gallery {
+	if opts.Title == "" {
+		opts.Title = "Gallery"
+	}
+
+	return &Gallery{
+		renderer: renderer,
+		opts:     opts,
+		examples: make(map[string][]Example),
+	}
+}
+
+// Add registers examples for the named partial (e.g. "@card"). It returns the gallery, so calls can be chained.
+func (g *Gallery) Add(partial string, examples ...Example) *Gallery {
+	g.examples[partial] = append(g.examples[partial], examples...)
+	return g
+}
+
+// Handler returns the handler serving the gallery. The pages link to each other with query strings only, so the
+// handler can be mounted at any path, e.g. mux.Handle("/_gallery", g.Handler()).
+//
+//   - Without a query, it lists the partials, with the number of examples of each.
+//   - With ?partial=name, it shows the examples of the partial, each with its knobs and a frame rendering it.
+//   - With ?partial=name&example=i&frame=1, it renders the example alone, with the knob values in the query.
+func (g *Gallery) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		partial := query.Get("partial")
+
+		switch {
+		case partial == "":
+			g.serveIndex(w)
+		case query.Get("frame") != "":
+			g.serveFrame(w, partial, query)
+		default:
+			g.servePartial(w, partial, query)
+		}
+	})
+}
+
+// entry is a partial listed in the gallery index.
+type entry struct {
+	Name     string
+	Examples int
+}
+
+func (g *Gallery) serveIndex(w http.ResponseWriter) {
+	partials := g.renderer.Partials()
+	entries := make([]entry, 0, len(partials))
+	for _, name := range partials {
+		entries = append(entries, entry{Name: name, Examples: len(g.examples[name])})
+	}
+
+	// Examples may be registered for templates the renderer does not list, e.g. templates defined in layouts
+	known := make(map[string]bool, len(partials))
+	for _, name := range partials {
+		known[name] = true
+	}
+	for name, examples := range g.examples {
+		if !known[name] {
+			entries = append(entries, entry{Name: name, Examples: len(examples)})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+
+	g.servePage(w, indexTemplate, map[string]any{"Title": g.opts.Title, "Entries": entries})
+}
+
+// knob is an editable example value.
+type knob struct {
+	Name  string
+	Kind  string // text, checkbox or number
+	Value any
+}
+
+// view is an example shown on the page of a partial.
+type view struct {
+	Index   int
+	Name    string
+	Knobs   []knob
+	Frame   string
+	Partial string
+}
+
+func (g *Gallery) servePartial(w http.ResponseWriter, partial string, query url.Values) {
+	examples := g.examples[partial]
+	views := make([]view, 0, len(examples))
+
+	for i, example := range examples {
+		values := applyKnobs(knobValues(example), query, i)
+
+		frame := url.Values{"partial": {partial}, "example": {strconv.Itoa(i)}, "frame": {"1"}}
+		var knobs []knob
+		for _, name := range sortedKeys(values) {
+			value := values[name]
+			knobs = append(knobs, knob{Name: name, Kind: knobKind(value), Value: value})
+			frame.Set(knobParam(i, name), fmt.Sprint(value))
+		}
+
+		views = append(views, view{Index: i, Name: example.Name, Knobs: knobs, Frame: "?" + frame.Encode(), Partial: partial})
+	}
+
+	g.servePage(w, partialTemplate, map[string]any{"Title": g.opts.Title, "Partial": partial, "Examples": views})
+}
+
+func (g *Gallery) serveFrame(w http.ResponseWriter, partial string, query url.Values) {
+	index, err := strconv.Atoi(query.Get("example"))
+	if err != nil || index < 0 || index >= len(g.examples[partial]) {
+		http.Error(w, "example not found", http.StatusNotFound)
+		return
+	}
+	example := g.examples[partial][index]
+
+	values := applyKnobs(knobValues(example), query, index)
+
+	buf := new(bytes.Buffer)
+	if err := g.renderer.RenderPartial(buf, partial, exampleData(example, values)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	g.servePage(w, frameTemplate, map[string]any{"Title": partial, "Head": g.opts.Head, "Body": template.HTML(buf.String())})
+}
+
+// Examples returns the examples registered for the named partial.
+func (g *Gallery) Examples(partial string) []Example {
+	return append([]Example(nil), g.examples[partial]...)
+}
+
+// RenderExample renders the example of the named partial at index with its own values, e.g. for static docs.
+func (g *Gallery) RenderExample(w io.Writer, partial string, index int) error {
+	if index < 0 || index >= len(g.examples[partial]) {
+		return fmt.Errorf("example not found: %s #%d", partial, index)
+	}
+	example := g.examples[partial][index]
+
+	return g.renderer.RenderPartial(w, partial, exampleData(example, knobValues(example)))
+}
+
+// exampleData returns the data to render the example with, with its knob values overridden by values. Components are
+// rendered with a *hyperview.ComponentData holding the props and slots of the example.
+func exampleData(example Example, values map[string]any) any {
+	switch {
+	case example.Props != nil || example.Slots != nil:
+		props := copyMap(example.Props)
+		for name, value := range values {
+			props[name] = value
+		}
+		slots := example.Slots
+		if slots == nil {
+			slots = map[string]template.HTML{}
+		}
+		return &hyperview.ComponentData{Props: props, Slots: slots}
+	case len(values) > 0:
+		m := copyMap(example.Data.(map[string]any))
+		for name, value := range values {
+			m[name] = value
+		}
+		return m
+	}
+	return example.Data
+}
+
+func (g *Gallery) servePage(w http.ResponseWriter, tmpl *template.Template, data map[string]any) {
+	buf := new(bytes.Buffer)
+	if err := tmpl.Execute(buf, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(buf.Bytes())
+}
+
+// knobValues returns the values of the example that can be edited with knobs.
+func knobValues(example Example) map[string]any {
+	source := example.Props
+	if source == nil && example.Slots == nil {
+		source, _ = example.Data.(map[string]any)
+	}
+
+	values := make(map[string]any)
+	for name, value := range source {
+		switch value.(type) {
+		case string, bool, int, float64:
+			values[name] = value
+		}
+	}
+	return values
+}
+
+// applyKnobs returns the values overridden with the knob values of the example in the query. Knob values that do not
+// parse as the type of the example value are ignored.
+func applyKnobs(values map[string]any, query url.Values, index int) map[string]any {
+	// Unchecked checkboxes are missing from the query, so booleans are only overridden once the knobs are submitted
+	submitted := query.Get("knobs") == strconv.Itoa(index) || query.Get("frame") != ""
+
+	for name, value := range values {
+		raw, ok := query[knobParam(index, name)]
+		if !ok {
+			if _, isBool := value.(bool); isBool && submitted {
+				values[name] = false
+			}
+			continue
+		}
+
+		switch value.(type) {
+		case string:
+			values[name] = raw[0]
+		case bool:
+			values[name] = raw[0] != "" && raw[0] != "false"
+		case int:
+			if n, err := strconv.Atoi(raw[0]); err == nil {
+				values[name] = n
+			}
+		case float64:
+			if f, err := strconv.ParseFloat(raw[0], 64); err == nil {
+				values[name] = f
+			}
+		}
+	}
+	return values
+}
+
+func knobParam(index int, name string) string {
+	return "knob." + strconv.Itoa(index) + "." + name
+}
+
+func knobKind(value any) string {
+	switch value.(type) {
+	case bool:
+		return "checkbox"
+	case int, float64:
+		return "number"
+	default:
+		return "text"
+	}
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func copyMap(m map[string]any) map[string]any {
+	c := make(map[string]any, len(m))
+	for key, value := range m {
+		c[key] = value
+	}
+	return c
+}
+
+var funcs = template.FuncMap{"knobParam": knobParam}
+
+var indexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>{{.Title}}</title></head>
+<body>
+<h1>{{.Title}}</h1>
+<ul>
+{{- range .Entries}}
+<li>{{if .Examples}}<a href="?partial={{.Name}}">{{.Name}}</a> ({{.Examples}}){{else}}{{.Name}} (no examples){{end}}</li>
+{{- end}}
+</ul>
+</body></html>
+`))
+
+var partialTemplate = template.Must(template.New("partial").Funcs(funcs).Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>{{.Partial}} - {{.Title}}</title></head>
+<body>
+<p><a href="?">{{.Title}}</a></p>
+<h1>{{.Partial}}</h1>
+{{- range .Examples}}
+<section>
+<h2>{{.Name}}</h2>
+{{- if .Knobs}}
+<form method="get">
+<input type="hidden" name="partial" value="{{.Partial}}">
+<input type="hidden" name="knobs" value="{{.Index}}">
+{{- $index := .Index}}
+{{- range .Knobs}}
+<label>{{.Name}} {{if eq .Kind "checkbox"}}<input type="checkbox" name="{{knobParam $index .Name}}"{{if .Value}} checked{{end}}>{{else}}<input type="{{.Kind}}" name="{{knobParam $index .Name}}" value="{{.Value}}"{{if eq .Kind "number"}} step="any"{{end}}>{{end}}</label>
+{{- end}}
+<button type="submit">Apply</button>
+</form>
+{{- end}}
+<iframe src="{{.Frame}}" title="{{.Name}}" style="width:100%;border:1px solid #ccc"></iframe>
+</section>
+{{- else}}
+<p>No examples.</p>
+{{- end}}
+</body></html>
+`))
+
+var frameTemplate = template.Must(template.New("frame").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>{{.Title}}</title>{{.Head}}</head>
+<body>
+{{.Body}}
+</body></html>
+`))
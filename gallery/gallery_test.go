@@ -0,0 +1,110 @@
+package gallery_test
+
+import (
+	"html/template"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/gallery"
+)
+
+func newTestGallery(t *testing.T) http.Handler {
+	t.Helper()
+
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{
+			"layouts/base.html":   {Data: []byte(`{{define "layout:base"}}{{block "title" .}}{{end}}{{end}}`)},
+			"partials/card.html":  {Data: []byte(`{{define "@card"}}<div class="{{if .Prop "Wide"}}wide{{end}}">{{.Prop "Title"}} x{{.Prop "Count"}}{{.Slot "default"}}</div>{{end}}`)},
+			"partials/badge.html": {Data: []byte(`{{define "@badge"}}<span>{{.Label}}</span>{{end}}`)},
+			"partials/nav.html":   {Data: []byte(`{{define "@nav"}}<nav></nav>{{end}}`)},
+		}},
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+
+	return gallery.New(adapter, gallery.Options{Head: `<link rel="stylesheet" href="/app.css">`}).
+		Add("@card", gallery.Example{
+			Name:  "Default",
+			Props: map[string]any{"Title": "Hello", "Count": 2, "Wide": true},
+			Slots: map[string]template.HTML{"default": "<p>body</p>"},
+		}).
+		Add("@badge", gallery.Example{Name: "New", Data: map[string]any{"Label": "new"}}).
+		Handler()
+}
+
+func get(t *testing.T, handler http.Handler, target string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+	return w
+}
+
+func TestGallery_Index(t *testing.T) {
+	body := get(t, newTestGallery(t), "/_gallery").Body.String()
+
+	for _, want := range []string{
+		`<a href="?partial=%40badge">@badge</a> (1)`,
+		`<a href="?partial=%40card">@card</a> (1)`,
+		`@nav (no examples)`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected the index to contain %s, got:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "<li>title") {
+		t.Errorf("expected blocks defined in layouts not to be listed, got:\n%s", body)
+	}
+}
+
+func TestGallery_Frame(t *testing.T) {
+	handler := newTestGallery(t)
+
+	tests := []struct {
+		name   string
+		target string
+		want   string
+	}{
+		{"component", "/?partial=@card&example=0&frame=1", `<div class="">Hello x2<p>body</p></div>`},
+		{"component knobs", "/?partial=@card&example=0&frame=1&knob.0.Title=Hi&knob.0.Count=5&knob.0.Wide=on", `<div class="wide">Hi x5<p>body</p></div>`},
+		{"invalid knob", "/?partial=@card&example=0&frame=1&knob.0.Count=many&knob.0.Wide=true", `<div class="wide">Hello x2<p>body</p></div>`},
+		{"partial knobs", "/?partial=@badge&example=0&frame=1&knob.0.Label=hot", `<span>hot</span>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := get(t, handler, tt.target)
+			if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), tt.want) {
+				t.Errorf("expected %s, got %d:\n%s", tt.want, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), `<link rel="stylesheet" href="/app.css">`) {
+				t.Errorf("expected the frame to include the head, got:\n%s", w.Body.String())
+			}
+		})
+	}
+
+	if w := get(t, handler, "/?partial=@card&example=3&frame=1"); w.Code != http.StatusNotFound {
+		t.Errorf("expected an unknown example to be not found, got %d", w.Code)
+	}
+}
+
+func TestGallery_Partial(t *testing.T) {
+	body := get(t, newTestGallery(t), "/?partial=@card&knobs=0&knob.0.Title=Hi").Body.String()
+
+	for _, want := range []string{
+		`<input type="text" name="knob.0.Title" value="Hi">`,
+		`<input type="number" name="knob.0.Count" value="2" step="any">`,
+		`<input type="checkbox" name="knob.0.Wide">`,
+		`<iframe src="?example=0&amp;frame=1&amp;knob.0.Count=2&amp;knob.0.Title=Hi&amp;knob.0.Wide=false&amp;partial=%40card"`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected the page to contain %s, got:\n%s", want, body)
+		}
+	}
+}
@@ -1,5 +1,7 @@
 module github.com/hypergopher/hyperview
 
-go 1.23
+go 1.23.0
 
 retract v0.0.2 // Invalid version from a previous repository
+
+require golang.org/x/net v0.38.0
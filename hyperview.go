@@ -11,6 +11,7 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/hypergopher/hyperview/constants"
 	"github.com/hypergopher/hyperview/htmx"
@@ -29,7 +30,11 @@ type HyperView struct {
 	filesystemMap map[string]fs.FS   // map of file systems to use for the view adapters
 	funcMap       template.FuncMap   // map of html/template functions to pass to the view
 	logger        *slog.Logger       // logger to use for the view service
-	mu            sync.RWMutex       // protects the adapters map
+	shutdownHooks []ShutdownHook     // hooks called on shutdown
+	renders       renderTracker      // renders in flight, waited for on shutdown
+	composers     []viewComposer     // view composers, in the order they were registered
+	frozen        atomic.Bool        // set by Freeze, after which the adapters map is read without locking
+	mu            sync.RWMutex       // protects the adapters map, the composers and the shutdown hooks
 }
 
 // NewHyperView creates a new view service. It accepts a list of options to configure the view service.
@@ -42,6 +47,7 @@ type HyperView struct {
 //   - WithLogger: sets an initial logger to use for the HyperView instance. If not set, a default logger is created when the HyperView instance is created.
 //   - WithViewAdapter: sets a view adapter to use for the view service. If no view adapters are set, the default adapters are used. Default adapters
 //     use html/template for html templates and json for json templates.
+//   - WithViewComposer: registers a view composer adding shared data to the views matching a pattern.
 func NewHyperView(options ...Option) (*HyperView, error) {
 	hgo := &HyperView{
 		adapters:      make(map[string]Adapter),
@@ -126,7 +132,7 @@ func (s *HyperView) Logger() *slog.Logger {
 }
 
 // MaybeRegisterDefaultAdapters registers the built-in adapters for
-// using html/template for html templates and json for json templates, but only
+// using html/template for html templates, json for json templates and xml for xml templates, but only
 // if they are not already registered. The ext parameter is used to determine the file extension for the html template adapter.
 func (s *HyperView) MaybeRegisterDefaultAdapters() error {
 	// Check if the html adapter is already registered
@@ -151,11 +157,20 @@ func (s *HyperView) MaybeRegisterDefaultAdapters() error {
 		}
 	}
 
+	// Check if the xml adapter is already registered
+	if _, ok := s.adapters["xml"]; !ok {
+		xmlAdapter := NewXMLViewAdapter()
+		if err := s.RegisterAdapter("xml", xmlAdapter); err != nil {
+			return fmt.Errorf("error registering default XML adapter: %w", err)
+		}
+	}
+
 	return nil
 }
 
 // RegisterAdapter registers a new view adapter with the view service
 func (s *HyperView) RegisterAdapter(name string, adapter Adapter) error {
+	s.mustNotBeFrozen("RegisterAdapter")
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.adapters[name] = adapter
@@ -164,6 +179,7 @@ func (s *HyperView) RegisterAdapter(name string, adapter Adapter) error {
 
 // Reinit reinitialize the view service adapters. This is useful for reloading templates after they have changed.
 func (s *HyperView) Reinit() error {
+	s.mustNotBeFrozen("Reinit")
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	for _, adapter := range s.adapters {
@@ -177,13 +193,19 @@ func (s *HyperView) Reinit() error {
 
 // Adapter returns the view adapter with the specified name
 func (s *HyperView) Adapter(name string) (Adapter, bool) {
+	if s.frozen.Load() {
+		adapter, ok := s.adapters[name]
+		return adapter, ok
+	}
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	adapter, ok := s.adapters[name]
 	return adapter, ok
 }
 
-// Render renders the specified opts with the provided adapter key
+// Render renders the specified opts with the provided adapter key. Responses of other kinds than views, such as
+// redirects, are written without an adapter.
 func (s *HyperView) Render(w http.ResponseWriter, r *http.Request, resp *response.Response) {
 	// First, find an extension if there is one
 	ext := ""
@@ -208,17 +230,55 @@ func (s *HyperView) Render(w http.ResponseWriter, r *http.Request, resp *respons
 	s.RenderAs(w, r, ext[1:], resp)
 }
 
-// RenderAs renders the specified opts with the provided adapter key
+// RenderAs renders the specified opts with the provided adapter key, or writes responses of other kinds than views
+// without an adapter.
 func (s *HyperView) RenderAs(w http.ResponseWriter, r *http.Request, adapterKey string, resp *response.Response) {
+	if resp.Kind() != response.KindView {
+		s.writeKind(w, r, resp)
+		return
+	}
+
 	if adapter, ok := s.adapterFor(w, adapterKey); ok {
-		// If there is no layout set, set the base layout
+		defer s.renders.end()
+		// If there is no layout set, use the layout declared by the view, or the base layout
+		if resp.TemplateLayout() == "" {
+			if resolver, ok := adapter.(LayoutResolver); ok {
+				if layout, ok := resolver.DeclaredLayout(resp.TemplatePath()); ok {
+					resp.Layout(layout)
+				}
+			}
+		}
 		if resp.TemplateLayout() == "" {
 			resp.Layout(s.baseLayout)
 		}
+		if err := s.compose(r, resp); err != nil {
+			adapter.RenderSystemError(w, r, err, s.NewSystemResponse())
+			return
+		}
 		adapter.Render(w, r, resp)
 	}
 }
 
+// writeKind writes a response of another kind than a view, such as a redirect or a stream. Redirects answer HTMX
+// and XMLHttpRequest requests like Redirect.
+func (s *HyperView) writeKind(w http.ResponseWriter, r *http.Request, resp *response.Response) {
+	if !s.beginRender(w) {
+		return
+	}
+	defer s.renders.end()
+
+	if resp.Kind() == response.KindRedirect {
+		for key, value := range resp.Headers() {
+			w.Header().Set(key, value)
+		}
+		s.redirect(w, r, resp.RedirectURL(), resp.StatusCode())
+		return
+	}
+	if err := resp.WriteKind(w, r); err != nil {
+		s.logger.Error("Error writing response", slog.String("kind", resp.Kind().String()), slog.String("err", err.Error()))
+	}
+}
+
 // RenderNotFound renders a 404 not found page
 func (s *HyperView) RenderNotFound(w http.ResponseWriter, r *http.Request) {
 	s.RenderNotFoundAs(w, r, "html")
@@ -227,6 +287,7 @@ func (s *HyperView) RenderNotFound(w http.ResponseWriter, r *http.Request) {
 // RenderNotFoundAs renders a 404 not found page as the specified adapter
 func (s *HyperView) RenderNotFoundAs(w http.ResponseWriter, r *http.Request, adapterKey string) {
 	if adapter, ok := s.adapterFor(w, adapterKey); ok {
+		defer s.renders.end()
 		adapter.RenderNotFound(w, r, s.NewSystemResponse().StatusNotFound())
 	}
 }
@@ -240,6 +301,7 @@ func (s *HyperView) RenderSystemError(w http.ResponseWriter, r *http.Request, er
 func (s *HyperView) RenderSystemErrorAs(w http.ResponseWriter, r *http.Request, adapterKey string, err error) {
 	s.logger.Error("Server error", slog.String("err", err.Error()))
 	if adapter, ok := s.adapterFor(w, adapterKey); ok {
+		defer s.renders.end()
 		adapter.RenderSystemError(w, r, err, s.NewSystemResponse().StatusError())
 	}
 }
@@ -252,6 +314,7 @@ func (s *HyperView) RenderMaintenance(w http.ResponseWriter, r *http.Request) {
 // RenderMaintenanceAs renders a maintenance page as the specified adapter
 func (s *HyperView) RenderMaintenanceAs(w http.ResponseWriter, r *http.Request, adapterKey string) {
 	if adapter, ok := s.adapterFor(w, adapterKey); ok {
+		defer s.renders.end()
 		adapter.RenderMaintenance(w, r, s.NewSystemResponse().Status(http.StatusServiceUnavailable))
 	}
 }
@@ -264,6 +327,7 @@ func (s *HyperView) RenderForbidden(w http.ResponseWriter, r *http.Request) {
 // RenderForbiddenAs renders a forbidden page as the specified adapter
 func (s *HyperView) RenderForbiddenAs(w http.ResponseWriter, r *http.Request, adapterKey string) {
 	if adapter, ok := s.adapterFor(w, adapterKey); ok {
+		defer s.renders.end()
 		adapter.RenderForbidden(w, r, s.NewSystemResponse().StatusForbidden())
 	}
 }
@@ -276,6 +340,7 @@ func (s *HyperView) RenderMethodNotAllowed(w http.ResponseWriter, r *http.Reques
 // RenderMethodNotAllowedAs renders a method not allowed page as the specified adapter
 func (s *HyperView) RenderMethodNotAllowedAs(w http.ResponseWriter, r *http.Request, adapterKey string) {
 	if adapter, ok := s.adapterFor(w, adapterKey); ok {
+		defer s.renders.end()
 		adapter.RenderMethodNotAllowed(w, r, s.NewSystemResponse().Status(http.StatusMethodNotAllowed))
 	}
 }
@@ -288,6 +353,7 @@ func (s *HyperView) RenderUnauthorized(w http.ResponseWriter, r *http.Request) {
 // RenderUnauthorizedAs renders an unauthorized page as the specified adapter
 func (s *HyperView) RenderUnauthorizedAs(w http.ResponseWriter, r *http.Request, adapterKey string) {
 	if adapter, ok := s.adapterFor(w, adapterKey); ok {
+		defer s.renders.end()
 		adapter.RenderUnauthorized(w, r, s.NewSystemResponse().StatusUnauthorized())
 	}
 }
@@ -302,6 +368,12 @@ func (s *HyperView) HxRedirect(w http.ResponseWriter, url string) {
 
 // Redirect sends a redirect response to the client
 func (s *HyperView) Redirect(w http.ResponseWriter, r *http.Request, url string) {
+	s.redirect(w, r, url, http.StatusFound)
+}
+
+// redirect sends a redirect response to the client, with the status code unless the request is an HTMX or
+// XMLHttpRequest request.
+func (s *HyperView) redirect(w http.ResponseWriter, r *http.Request, url string, code int) {
 	if htmx.IsHtmxRequest(r) {
 		s.HxRedirect(w, url)
 		return
@@ -319,7 +391,7 @@ func (s *HyperView) Redirect(w http.ResponseWriter, r *http.Request, url string)
 		_, _ = w.Write(jsonBytes)
 		return
 	}
-	http.Redirect(w, r, url, http.StatusFound)
+	http.Redirect(w, r, url, code)
 }
 
 // NewResponse creates a new response with the given layout
@@ -332,7 +404,8 @@ func (s *HyperView) NewSystemResponse() *response.Response {
 	return response.NewResponse().Layout(s.systemLayout)
 }
 
-// adapterFor returns the adapter for the specified key
+// adapterFor returns the adapter for the specified key and records the start of a render with it. When it returns
+// true, the caller must call s.renders.end once the render completes.
 func (s *HyperView) adapterFor(w http.ResponseWriter, key string) (Adapter, bool) {
 	if key == "" {
 		key = "html"
@@ -343,5 +416,9 @@ func (s *HyperView) adapterFor(w http.ResponseWriter, key string) (Adapter, bool
 		http.Error(w, "Adapter not found", http.StatusInternalServerError)
 		return nil, false
 	}
+
+	if !s.beginRender(w) {
+		return nil, false
+	}
 	return adapter, true
 }
@@ -255,3 +255,45 @@ func TestViewService_Render(t *testing.T) {
 		})
 	}
 }
+
+func TestViewService_RenderKinds(t *testing.T) {
+	adapter := &mockViewAdapter{}
+	hgo, err := hyperview.NewHyperView(hyperview.WithViewAdapter("html", adapter))
+	if err != nil {
+		t.Fatalf("error creating HyperView: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		resp       *response.Response
+		htmx       bool
+		wantStatus int
+		wantHeader string
+	}{
+		{name: "redirect", resp: response.NewResponse().SeeOther("/posts"), wantStatus: http.StatusSeeOther, wantHeader: "Location"},
+		{name: "HX redirect", resp: response.NewResponse().SeeOther("/posts"), htmx: true, wantStatus: http.StatusSeeOther, wantHeader: "HX-Redirect"},
+		{name: "no content", resp: response.NewResponse().NoContent(), wantStatus: http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/posts", nil)
+			if tt.htmx {
+				r.Header.Set("HX-Request", "true")
+			}
+
+			w := httptest.NewRecorder()
+			hgo.Render(w, r, tt.resp)
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+			if tt.wantHeader != "" && w.Header().Get(tt.wantHeader) != "/posts" {
+				t.Errorf("expected header %s to be /posts, got %q", tt.wantHeader, w.Header().Get(tt.wantHeader))
+			}
+		})
+	}
+
+	if adapter.renderCalled {
+		t.Error("expected the responses to be written without the adapter")
+	}
+}
@@ -0,0 +1,42 @@
+package hyperviewtest
+
+import (
+	"io/fs"
+	"maps"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+)
+
+// NewAdapter returns an initialized template adapter whose root file system holds the template sources, keyed by
+// path (e.g. "layouts/base.html"), so tests need no template files. Other options, such as Funcs or the other file
+// systems of FileSystemMap, are used as is. The test fails if the templates do not compile.
+//
+// Example:
+//
+//	adapter := hyperviewtest.NewAdapter(t, map[string]string{
+//		"layouts/base.html": `{{define "layout:base"}}<main>{{template "page:main" .}}</main>{{end}}`,
+//		"views/home.html":   `{{define "page:main"}}Hello{{end}}`,
+//	}, hyperview.TemplateViewAdapterOptions{})
+func NewAdapter(t testing.TB, files map[string]string, opts hyperview.TemplateViewAdapterOptions) *hyperview.TemplateAdapter {
+	t.Helper()
+
+	fsys := make(fstest.MapFS, len(files))
+	for path, src := range files {
+		fsys[path] = &fstest.MapFile{Data: []byte(src)}
+	}
+	fileSystems := maps.Clone(opts.FileSystemMap)
+	if fileSystems == nil {
+		fileSystems = make(map[string]fs.FS, 1)
+	}
+	fileSystems[constants.RootFSID] = fsys
+	opts.FileSystemMap = fileSystems
+
+	adapter := hyperview.NewTemplateViewAdapter(opts)
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing the template adapter: %v", err)
+	}
+	return adapter
+}
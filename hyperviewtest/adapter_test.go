@@ -0,0 +1,44 @@
+package hyperviewtest_test
+
+import (
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/hyperviewtest"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func TestNewAdapter(t *testing.T) {
+	adapter := hyperviewtest.NewAdapter(t, map[string]string{
+		"layouts/base.html":  `{{define "layout:base"}}<main>{{template "page:main" .}}</main>{{end}}`,
+		"views/home.html":    `<!-- layout: base -->{{define "page:main"}}{{template "card" .Name}}{{end}}`,
+		"partials/card.html": `<article>{{.}}</article>`,
+	}, hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{"blog": fstest.MapFS{
+			"views/posts.html": {Data: []byte(`{{define "page:main"}}{{template "card" "post"}}{{end}}`)},
+		}},
+	})
+
+	tests := []struct {
+		name string
+		resp *response.Response
+		want string
+	}{
+		{name: "root view", resp: response.NewResponse().Path("home").Data(map[string]any{"Name": "Ann"}), want: "<main><article>Ann</article></main>"},
+		{name: "other file system", resp: response.NewResponse().Layout("base").Path("blog:posts"), want: "<main><article>post</article></main>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			adapter.Render(w, httptest.NewRequest(http.MethodGet, "/", nil), tt.resp)
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("unexpected body: got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+// Package hyperviewtest provides helpers to test the templates of an application with the standard testing package.
+package hyperviewtest
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"testing"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/analysis"
+)
+
+// ContractOptions are the options for CheckLayoutContracts.
+type ContractOptions struct {
+	// Extension is the file extension of the templates. Default is ".html".
+	Extension string
+	// Layout is the layout views not declaring a layout are rendered with, usually the base layout of HyperView.
+	Layout string
+	// Required lists the blocks each view rendered with a layout must define itself, keyed by layout name, e.g.
+	// {"base": {"title", "description"}}. Blocks the layout calls with {{template}} are always required, while
+	// blocks given a default with {{block}} are only required when listed here.
+	Required map[string][]string
+	// SampleData is the view data views are rendered with, keyed by view path (e.g. "views/users/show"). Views
+	// without sample data are rendered with minimal fixture data inferred from the fields they access, as generated
+	// by the fixtures command.
+	SampleData map[string]map[string]any
+}
+
+// Violation is a view breaking the contract of its layout.
+type Violation struct {
+	// View is the path of the view, e.g. "views/home".
+	View string
+	// Layout is the layout the view is rendered with.
+	Layout string
+	// Block is the required block the view does not define, if the view is missing one.
+	Block string
+	// Err is the error rendering the view, if rendering failed.
+	Err error
+}
+
+func (v *Violation) Error() string {
+	if v.Block != "" {
+		return fmt.Sprintf("view %s does not define the block %q required by layout %s", v.View, v.Block, v.Layout)
+	}
+	return fmt.Sprintf("error rendering view %s with layout %s: %v", v.View, v.Layout, v.Err)
+}
+
+// LayoutContracts renders every view of fsys with its layout and returns the violations of the layout contracts,
+// sorted by view: the blocks required by the layout that the view does not define, and any other error rendering
+// the view. The adapter must be initialized with fsys as its root file system.
+func LayoutContracts(adapter *hyperview.TemplateAdapter, fsys fs.FS, opts ContractOptions) ([]*Violation, error) {
+	if opts.Extension == "" {
+		opts.Extension = ".html"
+	}
+
+	set, err := analysis.Load(fsys, opts.Extension)
+	if err != nil {
+		return nil, fmt.Errorf("error loading templates: %w", err)
+	}
+
+	views := make([]string, 0, len(set.Views))
+	for view := range set.Views {
+		views = append(views, view)
+	}
+	sort.Strings(views)
+
+	// Render the views with their sample data, or with fixtures for the fields they access
+	samples := make(map[string]map[string]any, len(views))
+	for _, view := range views {
+		if sample, ok := opts.SampleData[view]; ok {
+			samples[view] = sample
+			continue
+		}
+
+		fields, err := set.InferView(view)
+		if err != nil {
+			return nil, fmt.Errorf("error inferring the data of %s: %w", view, err)
+		}
+		samples[view], _ = fields.Fixture().(map[string]any)
+	}
+
+	failures := make(map[string][]*hyperview.ValidationError)
+	if err := adapter.Validate(hyperview.ValidateOptions{Layout: opts.Layout, SampleData: samples}); err != nil {
+		var errs hyperview.ValidationErrors
+		if !errors.As(err, &errs) {
+			return nil, fmt.Errorf("error validating views: %w", err)
+		}
+		for _, verr := range errs {
+			failures[verr.View] = append(failures[verr.View], verr)
+		}
+	}
+
+	var violations []*Violation
+	for _, view := range views {
+		layout, ok := adapter.DeclaredLayout(view)
+		if !ok {
+			layout = opts.Layout
+		}
+
+		for _, block := range opts.Required[layout] {
+			if _, ok := set.Views[view].Trees[block]; !ok {
+				violations = append(violations, &Violation{View: view, Layout: layout, Block: block})
+			}
+		}
+		for _, verr := range failures[view] {
+			violations = append(violations, &Violation{View: view, Layout: layout, Err: verr.Err})
+		}
+	}
+
+	return violations, nil
+}
+
+// CheckLayoutContracts checks the layout contracts of the views of fsys (see LayoutContracts), reporting the
+// violations of each view in a subtest named after the view.
+//
+// Example:
+//
+//	func TestLayoutContracts(t *testing.T) {
+//		hyperviewtest.CheckLayoutContracts(t, adapter, templatesFS, hyperviewtest.ContractOptions{
+//			Layout:   "base",
+//			Required: map[string][]string{"base": {"title"}},
+//		})
+//	}
+func CheckLayoutContracts(t *testing.T, adapter *hyperview.TemplateAdapter, fsys fs.FS, opts ContractOptions) {
+	t.Helper()
+
+	violations, err := LayoutContracts(adapter, fsys, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byView := make(map[string][]*Violation)
+	var views []string
+	for _, v := range violations {
+		if _, ok := byView[v.View]; !ok {
+			views = append(views, v.View)
+		}
+		byView[v.View] = append(byView[v.View], v)
+	}
+
+	for _, view := range views {
+		t.Run(view, func(t *testing.T) {
+			for _, v := range byView[view] {
+				t.Error(v)
+			}
+		})
+	}
+}
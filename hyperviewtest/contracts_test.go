@@ -0,0 +1,73 @@
+package hyperviewtest_test
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/hyperviewtest"
+)
+
+func newContractAdapter(t *testing.T, files fstest.MapFS) *hyperview.TemplateAdapter {
+	t.Helper()
+
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: files},
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+	return adapter
+}
+
+var contractFiles = fstest.MapFS{
+	"layouts/base.html": {Data: []byte(`{{define "layout:base"}}<title>{{block "title" .}}Site{{end}}</title>{{template "page:main" .}}{{end}}`)},
+	"layouts/bare.html": {Data: []byte(`{{define "layout:bare"}}{{template "page:main" .}}{{template "page:scripts" .}}{{end}}`)},
+	"views/home.html":   {Data: []byte(`{{define "title"}}Home{{end}}{{define "page:main"}}{{range .Items}}{{.Name}}{{end}}{{end}}`)},
+	"views/about.html":  {Data: []byte(`{{define "page:main"}}about{{end}}`)},
+	"views/popup.html":  {Data: []byte(`<!-- layout: bare -->{{define "page:main"}}popup{{end}}`)},
+	"views/user.html":   {Data: []byte(`{{define "title"}}User{{end}}{{define "page:main"}}{{.User.Name}}{{end}}`)},
+}
+
+func TestLayoutContracts(t *testing.T) {
+	adapter := newContractAdapter(t, contractFiles)
+
+	violations, err := hyperviewtest.LayoutContracts(adapter, contractFiles, hyperviewtest.ContractOptions{
+		Layout:     "base",
+		Required:   map[string][]string{"base": {"title"}},
+		SampleData: map[string]map[string]any{"views/user": {"User": "ann"}},
+	})
+	if err != nil {
+		t.Fatalf("error checking contracts: %v", err)
+	}
+
+	want := []string{
+		`view views/about does not define the block "title" required by layout base`,
+		`error rendering view views/popup with layout bare: html/template:bare.html:1:61: no such template "page:scripts"`,
+		`error rendering view views/user with layout base:`,
+	}
+	if len(violations) != len(want) {
+		t.Fatalf("expected %d violations, got %v", len(want), violations)
+	}
+	for i, w := range want {
+		if !strings.HasPrefix(violations[i].Error(), w) {
+			t.Errorf("unexpected violation %d:\ngot  %v\nwant %s", i, violations[i], w)
+		}
+	}
+}
+
+func TestCheckLayoutContracts(t *testing.T) {
+	files := fstest.MapFS{
+		"layouts/base.html": contractFiles["layouts/base.html"],
+		"views/home.html":   contractFiles["views/home.html"],
+	}
+
+	// Fixture data is inferred for views without sample data, so the range over .Items executes
+	hyperviewtest.CheckLayoutContracts(t, newContractAdapter(t, files), files, hyperviewtest.ContractOptions{
+		Layout:   "base",
+		Required: map[string][]string{"base": {"title"}},
+	})
+}
@@ -0,0 +1,164 @@
+package hyperviewtest
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/response"
+)
+
+// Kind is the kind of page an adapter is asked to render.
+type Kind string
+
+const (
+	KindView             Kind = "view"
+	KindForbidden        Kind = "forbidden"
+	KindMaintenance      Kind = "maintenance"
+	KindMethodNotAllowed Kind = "method not allowed"
+	KindNotFound         Kind = "not found"
+	KindSystemError      Kind = "system error"
+	KindUnauthorized     Kind = "unauthorized"
+)
+
+// Render is a render recorded by a RecordingAdapter.
+type Render struct {
+	// Kind is the kind of page requested.
+	Kind Kind
+	// Path is the view template path, e.g. "views/users/show".
+	Path string
+	// Layout is the layout.
+	Layout string
+	// Status is the status code of the response.
+	Status int
+	// Title is the page title.
+	Title string
+	// Data is the view data, without the View entry added by HyperView.
+	Data map[string]any
+	// Headers are the headers of the response, including HTMX triggers.
+	Headers map[string]string
+	// Err is the error passed to RenderSystemError.
+	Err error
+	// Response is the response passed to the adapter.
+	Response *response.Response
+}
+
+// RecordingAdapter is a view adapter recording the renders it is asked for instead of rendering templates, so
+// handler tests can assert which view was rendered with which data without parsing HTML. It writes the headers and
+// status code of the response, with an empty body. It is safe for concurrent use.
+//
+// Example:
+//
+//	rec := hyperviewtest.NewRecordingAdapter()
+//	hv, _ := hyperview.NewHyperView(hyperview.WithViewAdapter("html", rec))
+//	handler(hv).ServeHTTP(w, r)
+//	render, _ := rec.Last()
+//	// assert render.Path == "views/users/show" and render.Data["User"] == user
+type RecordingAdapter struct {
+	mu      sync.Mutex
+	renders []Render
+}
+
+var _ hyperview.Adapter = (*RecordingAdapter)(nil)
+
+// NewRecordingAdapter creates a new RecordingAdapter.
+func NewRecordingAdapter() *RecordingAdapter {
+	return &RecordingAdapter{}
+}
+
+// Renders returns the recorded renders, in order.
+func (ra *RecordingAdapter) Renders() []Render {
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+
+	return append([]Render(nil), ra.renders...)
+}
+
+// Last returns the last recorded render, and false if nothing was rendered.
+func (ra *RecordingAdapter) Last() (Render, bool) {
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+
+	if len(ra.renders) == 0 {
+		return Render{}, false
+	}
+	return ra.renders[len(ra.renders)-1], true
+}
+
+// Reset forgets the recorded renders.
+func (ra *RecordingAdapter) Reset() {
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+
+	ra.renders = nil
+}
+
+func (ra *RecordingAdapter) Init() error { return nil }
+
+func (ra *RecordingAdapter) Render(w http.ResponseWriter, r *http.Request, resp *response.Response) {
+	ra.record(w, r, KindView, resp, nil, http.StatusOK)
+}
+
+func (ra *RecordingAdapter) RenderForbidden(w http.ResponseWriter, r *http.Request, resp *response.Response) {
+	ra.record(w, r, KindForbidden, resp, nil, http.StatusForbidden)
+}
+
+func (ra *RecordingAdapter) RenderMaintenance(w http.ResponseWriter, r *http.Request, resp *response.Response) {
+	ra.record(w, r, KindMaintenance, resp, nil, http.StatusServiceUnavailable)
+}
+
+func (ra *RecordingAdapter) RenderMethodNotAllowed(w http.ResponseWriter, r *http.Request, resp *response.Response) {
+	ra.record(w, r, KindMethodNotAllowed, resp, nil, http.StatusMethodNotAllowed)
+}
+
+func (ra *RecordingAdapter) RenderNotFound(w http.ResponseWriter, r *http.Request, resp *response.Response) {
+	ra.record(w, r, KindNotFound, resp, nil, http.StatusNotFound)
+}
+
+func (ra *RecordingAdapter) RenderSystemError(w http.ResponseWriter, r *http.Request, err error, resp *response.Response) {
+	ra.record(w, r, KindSystemError, resp, err, http.StatusInternalServerError)
+}
+
+func (ra *RecordingAdapter) RenderUnauthorized(w http.ResponseWriter, r *http.Request, resp *response.Response) {
+	ra.record(w, r, KindUnauthorized, resp, nil, http.StatusUnauthorized)
+}
+
+// record records the render and writes the response headers and status. System pages use the status of their kind
+// unless the response sets another one, as the adapters render them with the response's status.
+func (ra *RecordingAdapter) record(w http.ResponseWriter, r *http.Request, kind Kind, resp *response.Response, err error, status int) {
+	if resp == nil {
+		resp = response.NewResponse()
+	}
+	if kind == KindView || resp.StatusCode() != http.StatusOK {
+		status = resp.StatusCode()
+	}
+
+	data := make(map[string]any)
+	for key, value := range resp.ViewData(r).Data() {
+		if key != "View" {
+			data[key] = value
+		}
+	}
+
+	headers := make(map[string]string)
+	for key, value := range resp.Headers() {
+		headers[key] = value
+		w.Header().Set(key, value)
+	}
+
+	ra.mu.Lock()
+	ra.renders = append(ra.renders, Render{
+		Kind:     kind,
+		Path:     resp.TemplatePath(),
+		Layout:   resp.TemplateLayout(),
+		Status:   status,
+		Title:    resp.PageTitle(),
+		Data:     data,
+		Headers:  headers,
+		Err:      err,
+		Response: resp,
+	})
+	ra.mu.Unlock()
+
+	w.WriteHeader(status)
+}
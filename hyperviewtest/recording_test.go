@@ -0,0 +1,79 @@
+package hyperviewtest_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/hyperviewtest"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func TestRecordingAdapter(t *testing.T) {
+	rec := hyperviewtest.NewRecordingAdapter()
+	hv, err := hyperview.NewHyperView(hyperview.WithViewAdapter("html", rec))
+	if err != nil {
+		t.Fatalf("error creating HyperView: %v", err)
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			hv.RenderNotFound(w, r)
+			return
+		}
+		if r.URL.Path == "/broken" {
+			hv.RenderSystemError(w, r, errors.New("boom"))
+			return
+		}
+		hv.Render(w, r, response.NewResponse().Path("users/show").Title("Ann").
+			AddDataItem("User", "ann").Header("X-Test", "1").StatusCreated())
+	})
+
+	if _, ok := rec.Last(); ok {
+		t.Error("expected no render before the first request")
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+
+	render, ok := rec.Last()
+	if !ok {
+		t.Fatal("expected a recorded render")
+	}
+	if render.Kind != hyperviewtest.KindView || render.Path != "views/users/show" || render.Layout != "base" ||
+		render.Title != "Ann" || render.Data["User"] != "ann" || render.Status != http.StatusCreated {
+		t.Errorf("unexpected render: %+v", render)
+	}
+	if _, ok := render.Data["View"]; ok {
+		t.Error("expected the View entry to be omitted from the data")
+	}
+	if w.Code != http.StatusCreated || w.Header().Get("X-Test") != "1" || w.Body.Len() != 0 {
+		t.Errorf("unexpected response: %d %v %q", w.Code, w.Header(), w.Body.String())
+	}
+
+	for _, tt := range []struct {
+		path   string
+		kind   hyperviewtest.Kind
+		status int
+	}{
+		{"/missing", hyperviewtest.KindNotFound, http.StatusNotFound},
+		{"/broken", hyperviewtest.KindSystemError, http.StatusInternalServerError},
+	} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		render, _ := rec.Last()
+		if render.Kind != tt.kind || render.Status != tt.status || w.Code != tt.status {
+			t.Errorf("%s: unexpected render %+v with status %d", tt.path, render, w.Code)
+		}
+	}
+
+	if renders := rec.Renders(); len(renders) != 3 || renders[2].Err == nil {
+		t.Errorf("expected three renders, the last with the error, got %+v", renders)
+	}
+	rec.Reset()
+	if len(rec.Renders()) != 0 {
+		t.Error("expected Reset to forget the renders")
+	}
+}
@@ -0,0 +1,133 @@
+package i18n
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseJSONCatalog parses a JSON message catalog. Nested objects are flattened into dot-separated keys, so
+// {"nav": {"home": "Home"}} defines the "nav.home" message.
+func parseJSONCatalog(data []byte) (map[string]string, error) {
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	messages := make(map[string]string)
+	if err := flatten(messages, "", raw); err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+func flatten(messages map[string]string, prefix string, values map[string]any) error {
+	for key, value := range values {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		switch v := value.(type) {
+		case string:
+			messages[key] = v
+		case map[string]any:
+			if err := flatten(messages, key, v); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("message %s must be a string or an object, got %T", key, value)
+		}
+	}
+
+	return nil
+}
+
+// parseTOMLCatalog parses a TOML message catalog. Only the subset of TOML needed for messages is supported: tables
+// (which prefix the keys they contain, like nested JSON objects), and key/value pairs with basic or literal string
+// values.
+func parseTOMLCatalog(data []byte) (map[string]string, error) {
+	messages := make(map[string]string)
+	prefix := ""
+
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: invalid table header", lineNum)
+			}
+			prefix = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = value", lineNum)
+		}
+
+		key = strings.TrimSpace(key)
+		if unquoted, err := strconv.Unquote(key); err == nil {
+			key = unquoted
+		}
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		message, err := parseTOMLString(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+		messages[key] = message
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+// parseTOMLString parses a basic ("...") or literal ('...') TOML string, followed by an optional comment.
+func parseTOMLString(value string) (string, error) {
+	if strings.HasPrefix(value, "'") {
+		end := strings.IndexByte(value[1:], '\'')
+		if end == -1 {
+			return "", fmt.Errorf("unterminated string")
+		}
+		return value[1 : end+1], checkTrailing(value[end+2:])
+	}
+
+	if !strings.HasPrefix(value, `"`) {
+		return "", fmt.Errorf("value must be a string")
+	}
+
+	for i := 1; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case '"':
+			message, err := strconv.Unquote(value[:i+1])
+			if err != nil {
+				return "", err
+			}
+			return message, checkTrailing(value[i+1:])
+		}
+	}
+
+	return "", fmt.Errorf("unterminated string")
+}
+
+func checkTrailing(rest string) error {
+	rest = strings.TrimSpace(rest)
+	if rest != "" && !strings.HasPrefix(rest, "#") {
+		return fmt.Errorf("unexpected content after value: %s", rest)
+	}
+	return nil
+}
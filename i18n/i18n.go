@@ -0,0 +1,255 @@
+// Package i18n provides message catalogs and localized template functions for HyperView applications.
+//
+// Catalogs are JSON or TOML files named after their locale (e.g. locales/en.json, locales/de.toml). Messages are
+// looked up for the request's locale, falling back to its base language (de-AT -> de) and then the default locale.
+// Plural messages are defined as nested keys for each plural form of the language ("zero", "one", "two", "few",
+// "many" and "other"), chosen with the plural rule of the locale.
+package i18n
+
+import (
+	"fmt"
+	"html/template"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/funcs"
+)
+
+// Bundle holds the message catalogs of all supported locales.
+type Bundle struct {
+	defaultLocale string
+	messages      map[string]map[string]string // locale -> key -> message
+	pluralRules   map[string]PluralRule        // locale -> rule, set with SetPluralRule
+	mu            sync.RWMutex
+}
+
+// NewBundle creates a new Bundle with the given default locale, which is used when a message is not available in
+// the requested locale.
+func NewBundle(defaultLocale string) *Bundle {
+	return &Bundle{
+		defaultLocale: defaultLocale,
+		messages:      make(map[string]map[string]string),
+	}
+}
+
+// DefaultLocale returns the default locale of the bundle.
+func (b *Bundle) DefaultLocale() string {
+	return b.defaultLocale
+}
+
+// LoadFS loads the message catalogs in the given directory of fsys (usually constants.LocalesDir). Each file is
+// named after its locale, with a .json or .toml extension. Messages are merged with any messages already loaded for
+// the locale, so catalogs can be spread over several file systems.
+func (b *Bundle) LoadFS(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		ext := path.Ext(entry.Name())
+		var parse func([]byte) (map[string]string, error)
+		switch ext {
+		case ".json":
+			parse = parseJSONCatalog
+		case ".toml":
+			parse = parseTOMLCatalog
+		default:
+			continue
+		}
+
+		data, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return err
+		}
+
+		messages, err := parse(data)
+		if err != nil {
+			return fmt.Errorf("error parsing catalog %s: %w", entry.Name(), err)
+		}
+
+		b.AddMessages(strings.TrimSuffix(entry.Name(), ext), messages)
+	}
+
+	return nil
+}
+
+// LoadFileSystems loads the message catalogs in the locales directory of each file system in the map, if present.
+func (b *Bundle) LoadFileSystems(fileSystemMap map[string]fs.FS) error {
+	for _, fsys := range fileSystemMap {
+		if _, err := fs.Stat(fsys, constants.LocalesDir); err != nil {
+			continue
+		}
+
+		if err := b.LoadFS(fsys, constants.LocalesDir); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AddMessages adds messages to the catalog of the given locale.
+func (b *Bundle) AddMessages(locale string, messages map[string]string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	catalog, ok := b.messages[locale]
+	if !ok {
+		catalog = make(map[string]string, len(messages))
+		b.messages[locale] = catalog
+	}
+
+	for key, message := range messages {
+		catalog[key] = message
+	}
+}
+
+// Locales returns the locales with a catalog, sorted alphabetically.
+func (b *Bundle) Locales() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	locales := make([]string, 0, len(b.messages))
+	for locale := range b.messages {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+
+	return locales
+}
+
+// Match returns the first supported locale among the given language tags, in order of preference. Tags match a
+// locale exactly or by their base language. If no tag matches, the default locale is returned.
+func (b *Bundle) Match(tags ...string) string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, tag := range tags {
+		for _, candidate := range Fallbacks(tag) {
+			if _, ok := b.messages[candidate]; ok {
+				return candidate
+			}
+		}
+	}
+
+	return b.defaultLocale
+}
+
+// Fallbacks returns the locales to try for the given locale, from most to least specific.
+// Example: Fallbacks("de-AT") returns ["de-AT", "de"].
+func Fallbacks(locale string) []string {
+	locale = strings.ReplaceAll(strings.TrimSpace(locale), "_", "-")
+	if locale == "" {
+		return nil
+	}
+
+	base, _, found := strings.Cut(locale, "-")
+	if !found {
+		return []string{locale}
+	}
+
+	return []string{locale, base}
+}
+
+// message returns the first of the given keys defined in the locale, falling back to the base language and the
+// default locale. A locale is preferred over its fallbacks even if it only defines one of the later keys.
+func (b *Bundle) message(locale string, keys ...string) (string, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, candidate := range append(Fallbacks(locale), b.defaultLocale) {
+		for _, key := range keys {
+			if message, ok := b.messages[candidate][key]; ok {
+				return message, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// Translate returns the message for key in the locale. Arguments are key/value pairs replacing {key} placeholders in
+// the message. If the message does not exist, the key itself is returned, so missing translations are visible.
+//
+// Example: Translate("en", "greeting", "Name", "Ada") with the message "Hello, {Name}!" returns "Hello, Ada!".
+func (b *Bundle) Translate(locale, key string, args ...any) string {
+	message, ok := b.message(locale, key)
+	if !ok {
+		return key
+	}
+
+	return interpolate(message, args...)
+}
+
+// Plural returns the plural form of the message for key in the locale that matches count. The forms are defined as
+// nested keys, such as "one", "few" and "other", and the form of count is chosen with the plural rule of the locale
+// (see SetPluralRule), e.g. "one" for 0 in French and "few" for 3 in Russian. A "zero" form, if defined, is used when
+// count is 0 in any language, and "other" when the form of count is not defined. The {count} placeholder is replaced
+// by count, and additional arguments are key/value pairs as with Translate.
+func (b *Bundle) Plural(locale, key string, count any, args ...any) string {
+	n, err := funcs.ToFloat64(count)
+	if err != nil {
+		return key
+	}
+
+	forms := []string{b.pluralRule(locale)(n), PluralOther}
+	if n == 0 {
+		forms = append([]string{PluralZero}, forms...)
+	}
+
+	keys := make([]string, len(forms))
+	for i, form := range forms {
+		keys[i] = key + "." + form
+	}
+
+	message, ok := b.message(locale, keys...)
+	if !ok {
+		return key
+	}
+
+	return interpolate(message, append([]any{"count", count}, args...)...)
+}
+
+// Funcs returns the template functions bound to the given locale:
+//
+//   - t: translates a message, e.g. {{t "greeting" "Name" .User.Name}}
+//   - plural: translates a plural message, e.g. {{plural "items" (len .Items)}}
+//   - locale: returns the locale, e.g. <html lang="{{locale}}">
+//
+// Pass Funcs(bundle.DefaultLocale()) to the template adapter's RequestFuncs option, and use Middleware to bind them
+// to the locale of each request.
+func (b *Bundle) Funcs(locale string) template.FuncMap {
+	return template.FuncMap{
+		"t": func(key string, args ...any) string {
+			return b.Translate(locale, key, args...)
+		},
+		"plural": func(key string, count any, args ...any) string {
+			return b.Plural(locale, key, count, args...)
+		},
+		"locale": func() string {
+			return locale
+		},
+	}
+}
+
+func interpolate(message string, args ...any) string {
+	if len(args) < 2 {
+		return message
+	}
+
+	pairs := make([]string, 0, len(args))
+	for i := 0; i+1 < len(args); i += 2 {
+		pairs = append(pairs, "{"+fmt.Sprint(args[i])+"}", fmt.Sprint(args[i+1]))
+	}
+
+	return strings.NewReplacer(pairs...).Replace(message)
+}
@@ -0,0 +1,238 @@
+package i18n_test
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/i18n"
+)
+
+func newTestBundle(t *testing.T) *i18n.Bundle {
+	t.Helper()
+
+	files := fstest.MapFS{
+		"locales/en.json": {Data: []byte(`{
+			"greeting": "Hello, {Name}!",
+			"nav": {"home": "Home", "about": "About"},
+			"items": {"zero": "No items", "one": "{count} item", "other": "{count} items"}
+		}`)},
+		"locales/de.toml": {Data: []byte(`
+# German messages
+greeting = "Hallo, {Name}!"
+
+[nav]
+home = 'Startseite' # comment
+
+[items]
+one = "{count} Artikel"
+other = "{count} Artikel"
+`)},
+		"locales/README.md": {Data: []byte(`ignored`)},
+	}
+
+	bundle := i18n.NewBundle("en")
+	if err := bundle.LoadFS(files, "locales"); err != nil {
+		t.Fatalf("error loading catalogs: %v", err)
+	}
+
+	return bundle
+}
+
+func TestBundle_Translate(t *testing.T) {
+	bundle := newTestBundle(t)
+
+	tests := []struct {
+		name   string
+		locale string
+		key    string
+		args   []any
+		want   string
+	}{
+		{"interpolation", "en", "greeting", []any{"Name", "Ada"}, "Hello, Ada!"},
+		{"toml catalog", "de", "greeting", []any{"Name", "Ada"}, "Hallo, Ada!"},
+		{"nested key", "de", "nav.home", nil, "Startseite"},
+		{"base language fallback", "de-AT", "nav.home", nil, "Startseite"},
+		{"default locale fallback", "de", "nav.about", nil, "About"},
+		{"unknown locale", "fr", "nav.home", nil, "Home"},
+		{"missing message", "en", "missing", nil, "missing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bundle.Translate(tt.locale, tt.key, tt.args...); got != tt.want {
+				t.Errorf("Translate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBundle_Plural(t *testing.T) {
+	bundle := newTestBundle(t)
+
+	tests := []struct {
+		name   string
+		locale string
+		count  any
+		want   string
+	}{
+		{"zero form", "en", 0, "No items"},
+		{"one form", "en", 1, "1 item"},
+		{"other form", "en", 3, "3 items"},
+		{"zero falls back to other", "de", 0, "0 Artikel"},
+		{"float count", "en", 2.5, "2.5 items"},
+		{"invalid count", "en", "many", "items"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bundle.Plural(tt.locale, "items", tt.count); got != tt.want {
+				t.Errorf("Plural() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBundle_PluralRules(t *testing.T) {
+	bundle := i18n.NewBundle("en")
+	bundle.AddMessages("fr", map[string]string{"items.one": "{count} article", "items.other": "{count} articles"})
+	bundle.AddMessages("ru", map[string]string{
+		"items.one": "{count} товар", "items.few": "{count} товара", "items.many": "{count} товаров",
+		"items.other": "{count} товара",
+	})
+	bundle.AddMessages("ga", map[string]string{"items.one": "{count} mhír", "items.two": "{count} mhír (2)",
+		"items.other": "{count} míreanna"})
+	bundle.SetPluralRule("ga", func(n float64) string {
+		switch n {
+		case 1:
+			return i18n.PluralOne
+		case 2:
+			return i18n.PluralTwo
+		}
+		return i18n.PluralOther
+	})
+
+	tests := []struct {
+		locale string
+		count  any
+		want   string
+	}{
+		{"fr", 0, "0 article"},
+		{"fr", 1.5, "1.5 article"},
+		{"fr", 2, "2 articles"},
+		{"fr-CA", 0, "0 article"},
+		{"ru", 1, "1 товар"},
+		{"ru", 3, "3 товара"},
+		{"ru", 5, "5 товаров"},
+		{"ru", 12, "12 товаров"},
+		{"ru", 21, "21 товар"},
+		{"ru", 22, "22 товара"},
+		{"ru", 1.5, "1.5 товара"},
+		{"ga", 2, "2 mhír (2)"},
+		{"ga", 3, "3 míreanna"},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s %v", tt.locale, tt.count), func(t *testing.T) {
+			if got := bundle.Plural(tt.locale, "items", tt.count); got != tt.want {
+				t.Errorf("Plural() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBundle_LoadFSErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		data    string
+		wantErr string
+	}{
+		{"invalid json", "en.json", `{"a": 1}`, "message a must be a string"},
+		{"invalid toml value", "en.toml", "a = 1", "line 1: value must be a string"},
+		{"unterminated toml string", "en.toml", "\n a = \"x", "line 2: unterminated string"},
+		{"toml trailing content", "en.toml", `a = "x" y`, "unexpected content"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := fstest.MapFS{"locales/" + tt.file: {Data: []byte(tt.data)}}
+			err := i18n.NewBundle("en").LoadFS(files, "locales")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadFS() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	got := i18n.ParseAcceptLanguage("fr;q=0.5, de-AT, en;q=0.8, *;q=0.1, es;q=0")
+	want := []string{"de-AT", "en", "fr"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseAcceptLanguage() = %v, want %v", got, want)
+	}
+}
+
+func TestBundle_Middleware(t *testing.T) {
+	bundle := newTestBundle(t)
+
+	tests := []struct {
+		name     string
+		target   string
+		cookie   string
+		accept   string
+		want     string
+		wantText string
+	}{
+		{"query parameter", "/?lang=de", "", "en", "de", "Startseite"},
+		{"cookie", "/", "de", "en", "de", "Startseite"},
+		{"accept language", "/", "", "fr, de-AT;q=0.9", "de", "Startseite"},
+		{"default locale", "/", "", "fr", "en", "Home"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var locale, text string
+			handler := bundle.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				locale = i18n.LocaleFromContext(r.Context())
+				text = hyperview.FuncsFromContext(r.Context())["t"].(func(string, ...any) string)("nav.home")
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.cookie != "" {
+				r.AddCookie(&http.Cookie{Name: "lang", Value: tt.cookie})
+			}
+			r.Header.Set("Accept-Language", tt.accept)
+			handler.ServeHTTP(httptest.NewRecorder(), r)
+
+			if locale != tt.want {
+				t.Errorf("locale = %q, want %q", locale, tt.want)
+			}
+			if text != tt.wantText {
+				t.Errorf("t = %q, want %q", text, tt.wantText)
+			}
+		})
+	}
+}
+
+func TestBundle_Funcs(t *testing.T) {
+	bundle := newTestBundle(t)
+
+	tmpl := template.Must(template.New("test").Funcs(bundle.Funcs("de")).
+		Parse(`{{locale}}: {{t "greeting" "Name" "Ada"}} {{plural "items" 2}}`))
+
+	var out strings.Builder
+	if err := tmpl.Execute(&out, nil); err != nil {
+		t.Fatalf("error executing template: %v", err)
+	}
+
+	if want := "de: Hallo, Ada! 2 Artikel"; out.String() != want {
+		t.Errorf("unexpected output: got %q, want %q", out.String(), want)
+	}
+}
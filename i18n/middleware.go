@@ -0,0 +1,94 @@
+package i18n
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+)
+
+// ContextWithLocale returns a copy of ctx carrying the given locale.
+func ContextWithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, constants.LocaleContextKey, locale)
+}
+
+// LocaleFromContext returns the locale carried by ctx, or an empty string if there is none.
+func LocaleFromContext(ctx context.Context) string {
+	locale, _ := ctx.Value(constants.LocaleContextKey).(string)
+	return locale
+}
+
+// Middleware determines the locale of each request and adds it to the request context, along with the localized
+// template functions (see Funcs). The locale is taken from the "lang" query parameter, the "lang" cookie or the
+// Accept-Language header, in that order, and matched against the locales of the bundle.
+func (b *Bundle) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		locale := b.RequestLocale(r)
+
+		ctx := ContextWithLocale(r.Context(), locale)
+		ctx = hyperview.ContextWithFuncs(ctx, b.Funcs(locale))
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// RequestLocale returns the supported locale that best matches the request.
+func (b *Bundle) RequestLocale(r *http.Request) string {
+	var tags []string
+
+	if lang := r.URL.Query().Get("lang"); lang != "" {
+		tags = append(tags, lang)
+	}
+
+	if cookie, err := r.Cookie("lang"); err == nil && cookie.Value != "" {
+		tags = append(tags, cookie.Value)
+	}
+
+	tags = append(tags, ParseAcceptLanguage(r.Header.Get("Accept-Language"))...)
+
+	return b.Match(tags...)
+}
+
+// ParseAcceptLanguage returns the language tags of an Accept-Language header, ordered by their quality value.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+
+	var langs []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q > 0 {
+			langs = append(langs, weighted{tag: tag, q: q})
+		}
+	}
+
+	sort.SliceStable(langs, func(i, j int) bool {
+		return langs[i].q > langs[j].q
+	})
+
+	tags := make([]string, len(langs))
+	for i, lang := range langs {
+		tags[i] = lang.tag
+	}
+
+	return tags
+}
@@ -0,0 +1,178 @@
+package i18n
+
+import (
+	"math"
+	"strings"
+)
+
+// Plural forms of the CLDR plural rules.
+const (
+	PluralZero  = "zero"
+	PluralOne   = "one"
+	PluralTwo   = "two"
+	PluralFew   = "few"
+	PluralMany  = "many"
+	PluralOther = "other"
+)
+
+// PluralRule returns the plural form of a count in a language, one of the PluralZero to PluralOther forms.
+type PluralRule func(n float64) string
+
+// pluralRules are the plural rules of the base languages with built-in rules, after the CLDR plural rules for
+// cardinals. Languages without a rule use the rule of English.
+var pluralRules = map[string]PluralRule{
+	"en": pluralOneOther,
+	"de": pluralOneOther,
+	"nl": pluralOneOther,
+	"sv": pluralOneOther,
+	"da": pluralOneOther,
+	"no": pluralOneOther,
+	"nb": pluralOneOther,
+	"fi": pluralOneOther,
+	"it": pluralOneOther,
+	"es": pluralOneOther,
+	"tr": pluralOneOther,
+	"pt": pluralFrench,
+	"fr": pluralFrench,
+	"ru": pluralEastSlavic,
+	"uk": pluralEastSlavic,
+	"be": pluralEastSlavic,
+	"pl": pluralPolish,
+	"cs": pluralCzech,
+	"sk": pluralCzech,
+	"ar": pluralArabic,
+	"ja": pluralOtherOnly,
+	"ko": pluralOtherOnly,
+	"zh": pluralOtherOnly,
+	"th": pluralOtherOnly,
+	"vi": pluralOtherOnly,
+	"id": pluralOtherOnly,
+}
+
+// SetPluralRule sets the plural rule of the locale, or of a base language, such as "pt-PT" or "ga", replacing the
+// built-in rule, if any.
+func (b *Bundle) SetPluralRule(locale string, rule PluralRule) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.pluralRules == nil {
+		b.pluralRules = make(map[string]PluralRule)
+	}
+	b.pluralRules[locale] = rule
+}
+
+// pluralRule returns the plural rule of the locale: the rule set for the locale or its base language, the built-in
+// rule of its base language, or the rule of English.
+func (b *Bundle) pluralRule(locale string) PluralRule {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	fallbacks := Fallbacks(locale)
+	for _, candidate := range fallbacks {
+		if rule, ok := b.pluralRules[candidate]; ok {
+			return rule
+		}
+	}
+	for _, candidate := range fallbacks {
+		if rule, ok := pluralRules[strings.ToLower(candidate)]; ok {
+			return rule
+		}
+	}
+	return pluralOneOther
+}
+
+// isInt reports whether n has no fractional part, as the forms of most languages differ for fractional counts.
+func isInt(n float64) bool {
+	return n == math.Trunc(n)
+}
+
+// mod returns the absolute value of the integer n modulo m.
+func mod(n float64, m int64) int64 {
+	return int64(math.Abs(n)) % m
+}
+
+// pluralOneOther is the rule of English and most Germanic and Romance languages: one for 1, other otherwise.
+func pluralOneOther(n float64) string {
+	if n == 1 {
+		return PluralOne
+	}
+	return PluralOther
+}
+
+// pluralFrench is the rule of French and Portuguese: one for counts from 0 up to 2, excluded, other otherwise.
+func pluralFrench(n float64) string {
+	if n >= 0 && n < 2 {
+		return PluralOne
+	}
+	return PluralOther
+}
+
+// pluralEastSlavic is the rule of Russian, Ukrainian and Belarusian: one for 1, 21, 31, few for 2-4, 22-24, many for
+// the other integers, and other for fractional counts.
+func pluralEastSlavic(n float64) string {
+	if !isInt(n) {
+		return PluralOther
+	}
+	switch n10, n100 := mod(n, 10), mod(n, 100); {
+	case n10 == 1 && n100 != 11:
+		return PluralOne
+	case n10 >= 2 && n10 <= 4 && (n100 < 12 || n100 > 14):
+		return PluralFew
+	}
+	return PluralMany
+}
+
+// pluralPolish is the rule of Polish: one for 1, few for 2-4, 22-24, many for the other integers, and other for
+// fractional counts.
+func pluralPolish(n float64) string {
+	if !isInt(n) {
+		return PluralOther
+	}
+	switch n10, n100 := mod(n, 10), mod(n, 100); {
+	case n == 1:
+		return PluralOne
+	case n10 >= 2 && n10 <= 4 && (n100 < 12 || n100 > 14):
+		return PluralFew
+	}
+	return PluralMany
+}
+
+// pluralCzech is the rule of Czech and Slovak: one for 1, few for 2-4, many for fractional counts, and other
+// otherwise.
+func pluralCzech(n float64) string {
+	switch {
+	case !isInt(n):
+		return PluralMany
+	case n == 1:
+		return PluralOne
+	case n >= 2 && n <= 4:
+		return PluralFew
+	}
+	return PluralOther
+}
+
+// pluralArabic is the rule of Arabic: zero, one and two for 0, 1 and 2, few for 3-10, 103-110, many for 11-99,
+// 111-199, and other otherwise.
+func pluralArabic(n float64) string {
+	if !isInt(n) {
+		return PluralOther
+	}
+	switch n100 := mod(n, 100); {
+	case n == 0:
+		return PluralZero
+	case n == 1:
+		return PluralOne
+	case n == 2:
+		return PluralTwo
+	case n100 >= 3 && n100 <= 10:
+		return PluralFew
+	case n100 >= 11:
+		return PluralMany
+	}
+	return PluralOther
+}
+
+// pluralOtherOnly is the rule of the languages without plural forms, such as Japanese and Chinese.
+func pluralOtherOnly(float64) string {
+	return PluralOther
+}
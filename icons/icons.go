@@ -0,0 +1,338 @@
+// Package icons renders SVG icons from a file system of icons, such as an icon set copied into the project, so
+// templates inline icons by name instead of pasting SVG markup or casting strings to template.HTML:
+//
+//	<button>{{icon "trash" "class" "text-red" "size" "16"}} Delete</button>
+//	{{icon "solid/bell" "title" "Notifications"}}
+//
+// The icons are read once and cached. With a sprite URL, the icons reference the symbols of a sprite sheet generated
+// from the file system instead, so pages showing many icons stay small and the sheet is cached by browsers.
+package icons
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Options are the options for the icons.
+type Options struct {
+	// Class is the class of every icon, before the class of the source and the class passed to icon, e.g. "icon".
+	Class string
+	// SpriteURL is the URL the sprite sheet is served at, e.g. "/icons.svg", where Handler is mounted. When set, icons
+	// reference their symbol in the sprite sheet instead of being inlined.
+	SpriteURL string
+}
+
+// Icons renders the icons of a file system. Icons are named after the path of their file without the .svg extension,
+// e.g. solid/trash for solid/trash.svg.
+type Icons struct {
+	fsys  fs.FS
+	opts  Options
+	mu    sync.RWMutex
+	cache map[string]*icon
+
+	spriteOnce sync.Once
+	sprite     []byte
+	spriteETag string
+	spriteErr  error
+}
+
+// icon is a parsed SVG icon.
+type icon struct {
+	attrs []attr
+	inner string
+}
+
+// attr is an attribute of an icon.
+type attr struct {
+	name  string
+	value string
+}
+
+// New creates the icons of fsys.
+func New(fsys fs.FS, opts Options) *Icons {
+	return &Icons{fsys: fsys, opts: opts, cache: make(map[string]*icon)}
+}
+
+// Funcs returns the template functions of the icons, to add to the template adapter's Funcs option:
+//
+//   - icon: renders an icon, with optional attribute name and value pairs: "class" adds classes, "size" sets the width
+//     and height, and "title" labels the icon for assistive technologies, which otherwise ignore it, e.g.
+//     {{icon "trash" "class" "danger" "size" "16" "title" "Delete"}}
+func (i *Icons) Funcs() template.FuncMap {
+	return template.FuncMap{
+		"icon": i.Icon,
+	}
+}
+
+// validAttrName matches the attribute names icons accept. Event handler attributes are rejected.
+var validAttrName = regexp.MustCompile(`^[a-zA-Z_:][-a-zA-Z0-9_:.]*$`)
+
+// Icon renders the named icon with the attribute name and value pairs.
+func (i *Icons) Icon(name string, attrs ...string) (template.HTML, error) {
+	if len(attrs)%2 != 0 {
+		return "", fmt.Errorf("icon %s: attributes must be name and value pairs", name)
+	}
+
+	ic, err := i.load(name)
+	if err != nil {
+		return "", err
+	}
+
+	var (
+		out   []attr
+		inner = ic.inner
+	)
+	if i.opts.SpriteURL == "" {
+		out = slices.Clone(ic.attrs)
+	} else {
+		if viewBox, ok := lookup(ic.attrs, "viewBox"); ok {
+			out = []attr{{name: "viewBox", value: viewBox}}
+		}
+		inner = `<use href="` + template.HTMLEscapeString(i.opts.SpriteURL+"#"+symbolID(name)) + `"></use>`
+	}
+
+	class, _ := lookup(ic.attrs, "class")
+	class = joinClasses(class, i.opts.Class)
+	title := ""
+	for n := 0; n < len(attrs); n += 2 {
+		key, value := attrs[n], attrs[n+1]
+		switch {
+		case key == "class":
+			class = joinClasses(class, value)
+		case key == "size":
+			out = setAttr(out, "width", value)
+			out = setAttr(out, "height", value)
+		case key == "title":
+			title = value
+		case !validAttrName.MatchString(key) || strings.HasPrefix(strings.ToLower(key), "on"):
+			return "", fmt.Errorf("icon %s: invalid attribute %q", name, key)
+		default:
+			out = setAttr(out, key, value)
+		}
+	}
+	if class != "" {
+		out = setAttr(out, "class", class)
+	}
+
+	_, labeled := lookup(out, "aria-label")
+	switch {
+	case title != "":
+		out = setAttr(out, "role", "img")
+		inner = "<title>" + template.HTMLEscapeString(title) + "</title>" + inner
+	case labeled:
+		out = setAttr(out, "role", "img")
+	default:
+		out = setAttr(out, "aria-hidden", "true")
+	}
+
+	var b strings.Builder
+	b.WriteString("<svg")
+	writeAttrs(&b, out)
+	b.WriteString(">")
+	b.WriteString(inner)
+	b.WriteString("</svg>")
+	return template.HTML(b.String()), nil
+}
+
+// load returns the named icon, reading and parsing it on first use.
+func (i *Icons) load(name string) (*icon, error) {
+	i.mu.RLock()
+	ic, ok := i.cache[name]
+	i.mu.RUnlock()
+	if ok {
+		return ic, nil
+	}
+
+	file := name + ".svg"
+	if !fs.ValidPath(file) {
+		return nil, fmt.Errorf("invalid icon name %q", name)
+	}
+	src, err := fs.ReadFile(i.fsys, file)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("icon not found: %s", name)
+		}
+		return nil, fmt.Errorf("error reading icon %s: %w", name, err)
+	}
+	ic, err = parseIcon(string(src))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing icon %s: %w", name, err)
+	}
+
+	i.mu.Lock()
+	i.cache[name] = ic
+	i.mu.Unlock()
+	return ic, nil
+}
+
+// Sprite returns the sprite sheet of all the icons of the file system, with a symbol for each, sorted by name. The
+// sheet is generated once.
+func (i *Icons) Sprite() ([]byte, error) {
+	i.spriteOnce.Do(func() {
+		var names []string
+		err := fs.WalkDir(i.fsys, ".", func(file string, d fs.DirEntry, err error) error {
+			if err == nil && !d.IsDir() && path.Ext(file) == ".svg" {
+				names = append(names, strings.TrimSuffix(file, ".svg"))
+			}
+			return err
+		})
+		if err != nil {
+			i.spriteErr = fmt.Errorf("error listing icons: %w", err)
+			return
+		}
+		slices.Sort(names)
+
+		var b bytes.Buffer
+		b.WriteString(`<svg xmlns="http://www.w3.org/2000/svg">`)
+		for _, name := range names {
+			ic, err := i.load(name)
+			if err != nil {
+				i.spriteErr = err
+				return
+			}
+
+			symbol := []attr{{name: "id", value: symbolID(name)}}
+			for _, a := range ic.attrs {
+				switch a.name {
+				case "id", "xmlns", "width", "height", "class":
+				default:
+					symbol = append(symbol, a)
+				}
+			}
+			b.WriteString("<symbol")
+			writeAttrs(&b, symbol)
+			b.WriteString(">")
+			b.WriteString(ic.inner)
+			b.WriteString("</symbol>")
+		}
+		b.WriteString("</svg>")
+
+		sum := sha256.Sum256(b.Bytes())
+		i.sprite, i.spriteETag = b.Bytes(), `"`+hex.EncodeToString(sum[:8])+`"`
+	})
+	return i.sprite, i.spriteErr
+}
+
+// Handler returns an http.Handler serving the sprite sheet, with an ETag so browsers revalidate it cheaply.
+func (i *Icons) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sprite, err := i.Sprite()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "image/svg+xml")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("ETag", i.spriteETag)
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(sprite))
+	})
+}
+
+// parseIcon parses the root svg element of an SVG document.
+func parseIcon(src string) (*icon, error) {
+	start := strings.Index(src, "<svg")
+	end := strings.LastIndex(src, "</svg>")
+	if start < 0 || end < start {
+		return nil, errors.New("no svg element")
+	}
+
+	attrs, rest, err := parseAttrs(src[start+len("<svg") : end])
+	if err != nil {
+		return nil, err
+	}
+	return &icon{attrs: attrs, inner: strings.TrimSpace(rest)}, nil
+}
+
+// parseAttrs parses the attributes of a start tag up to its closing >, returning them and what follows the tag.
+func parseAttrs(s string) ([]attr, string, error) {
+	var attrs []attr
+	for {
+		s = strings.TrimLeft(s, " \t\r\n")
+		switch {
+		case s == "":
+			return nil, "", errors.New("unterminated svg tag")
+		case s[0] == '>':
+			return attrs, s[1:], nil
+		case strings.HasPrefix(s, "/>"):
+			return attrs, "", nil
+		}
+
+		n := strings.IndexAny(s, "= \t\r\n>/")
+		if n <= 0 {
+			return nil, "", fmt.Errorf("invalid attribute in %.20q", s)
+		}
+		a := attr{name: s[:n]}
+		s = strings.TrimLeft(s[n:], " \t\r\n")
+		if strings.HasPrefix(s, "=") {
+			s = strings.TrimLeft(s[1:], " \t\r\n")
+			if s == "" || (s[0] != '"' && s[0] != '\'') {
+				return nil, "", fmt.Errorf("unquoted value of attribute %s", a.name)
+			}
+			closing := strings.IndexByte(s[1:], s[0])
+			if closing < 0 {
+				return nil, "", fmt.Errorf("unterminated value of attribute %s", a.name)
+			}
+			a.value, s = unescapeXML(s[1:closing+1]), s[closing+2:]
+		}
+		attrs = append(attrs, a)
+	}
+}
+
+var xmlUnescaper = strings.NewReplacer("&quot;", `"`, "&apos;", "'", "&#39;", "'", "&lt;", "<", "&gt;", ">", "&amp;", "&")
+
+// unescapeXML replaces the entities of an XML attribute value.
+func unescapeXML(s string) string {
+	return xmlUnescaper.Replace(s)
+}
+
+// writeAttrs writes the attributes, escaping their values.
+func writeAttrs(b io.StringWriter, attrs []attr) {
+	for _, a := range attrs {
+		_, _ = b.WriteString(" " + a.name + `="` + template.HTMLEscapeString(a.value) + `"`)
+	}
+}
+
+// lookup returns the value of the named attribute.
+func lookup(attrs []attr, name string) (string, bool) {
+	for _, a := range attrs {
+		if a.name == name {
+			return a.value, true
+		}
+	}
+	return "", false
+}
+
+// setAttr sets the value of the named attribute, appending it if it is missing.
+func setAttr(attrs []attr, name, value string) []attr {
+	for n := range attrs {
+		if attrs[n].name == name {
+			attrs[n].value = value
+			return attrs
+		}
+	}
+	return append(attrs, attr{name: name, value: value})
+}
+
+// joinClasses joins the non-empty classes.
+func joinClasses(classes ...string) string {
+	return strings.Join(strings.Fields(strings.Join(classes, " ")), " ")
+}
+
+// symbolID returns the ID of the symbol of the named icon in the sprite sheet.
+func symbolID(name string) string {
+	return "icon-" + strings.ReplaceAll(name, "/", "-")
+}
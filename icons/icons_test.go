@@ -0,0 +1,115 @@
+package icons_test
+
+import (
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview/icons"
+)
+
+func iconFiles() fstest.MapFS {
+	return fstest.MapFS{
+		"trash.svg": {Data: []byte(`<?xml version="1.0"?>
+<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 24 24" class="outline" width="24" height="24" fill="none">
+  <path d="M3 6h18"/>
+</svg>`)},
+		"solid/bell.svg": {Data: []byte(`<svg viewBox='0 0 20 20' data-name="a &amp; b"><path d="M10 2"/></svg>`)},
+		"broken.svg":     {Data: []byte(`<svg viewBox="0 0 20 20></svg>`)},
+	}
+}
+
+func TestIcons_Icon(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    icons.Options
+		icon    string
+		attrs   []string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "inline icon",
+			icon: "trash",
+			want: `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 24 24" class="outline" width="24" height="24" ` +
+				`fill="none" aria-hidden="true"><path d="M3 6h18"/></svg>`,
+		},
+		{
+			name:  "classes, size and attributes",
+			opts:  icons.Options{Class: "icon"},
+			icon:  "solid/bell",
+			attrs: []string{"class", "text-red", "size", "16", "stroke-width", `"2"`},
+			want: `<svg viewBox="0 0 20 20" data-name="a &amp; b" width="16" height="16" stroke-width="&#34;2&#34;" ` +
+				`class="icon text-red" aria-hidden="true"><path d="M10 2"/></svg>`,
+		},
+		{
+			name:  "labeled icon",
+			icon:  "solid/bell",
+			attrs: []string{"title", "<Notifications>"},
+			want: `<svg viewBox="0 0 20 20" data-name="a &amp; b" role="img">` +
+				`<title>&lt;Notifications&gt;</title><path d="M10 2"/></svg>`,
+		},
+		{
+			name:  "sprite icon",
+			opts:  icons.Options{SpriteURL: "/icons.svg", Class: "icon"},
+			icon:  "solid/bell",
+			attrs: []string{"aria-label", "Notifications"},
+			want: `<svg viewBox="0 0 20 20" aria-label="Notifications" class="icon" role="img">` +
+				`<use href="/icons.svg#icon-solid-bell"></use></svg>`,
+		},
+		{name: "missing icon", icon: "missing", wantErr: "icon not found: missing"},
+		{name: "invalid name", icon: "../trash", wantErr: "invalid icon name"},
+		{name: "invalid icon", icon: "broken", wantErr: "unterminated value of attribute viewBox"},
+		{name: "unpaired attributes", icon: "trash", attrs: []string{"class"}, wantErr: "name and value pairs"},
+		{name: "event handler attribute", icon: "trash", attrs: []string{"onclick", "alert(1)"}, wantErr: "invalid attribute"},
+		{name: "invalid attribute name", icon: "trash", attrs: []string{`x"y`, "1"}, wantErr: "invalid attribute"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := icons.New(iconFiles(), tt.opts).Icon(tt.icon, tt.attrs...)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected an error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error rendering icon: %v", err)
+			}
+			if got != template.HTML(tt.want) {
+				t.Errorf("unexpected icon:\ngot  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIcons_Sprite(t *testing.T) {
+	files := iconFiles()
+	delete(files, "broken.svg")
+	set := icons.New(files, icons.Options{SpriteURL: "/icons.svg"})
+
+	w := httptest.NewRecorder()
+	set.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/icons.svg", nil))
+
+	want := `<svg xmlns="http://www.w3.org/2000/svg">` +
+		`<symbol id="icon-solid-bell" viewBox="0 0 20 20" data-name="a &amp; b"><path d="M10 2"/></symbol>` +
+		`<symbol id="icon-trash" viewBox="0 0 24 24" fill="none"><path d="M3 6h18"/></symbol></svg>`
+	if got := w.Body.String(); got != want {
+		t.Errorf("unexpected sprite:\ngot  %s\nwant %s", got, want)
+	}
+	if got := w.Header().Get("Content-Type"); got != "image/svg+xml" {
+		t.Errorf("expected an SVG content type, got %q", got)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/icons.svg", nil)
+	req.Header.Set("If-None-Match", w.Header().Get("ETag"))
+	w = httptest.NewRecorder()
+	set.Handler().ServeHTTP(w, req)
+	if w.Code != http.StatusNotModified {
+		t.Errorf("expected 304 for a matching ETag, got %d", w.Code)
+	}
+}
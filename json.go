@@ -95,7 +95,20 @@ func JSONRedirect(w http.ResponseWriter, r *http.Request, url string, headers ..
 // serialization fails, an error is returned. The function accepts optional headers
 // that will be applied to the response.
 func JSONWithHeaders(w http.ResponseWriter, status int, data any, headers ...http.Header) error {
-	js, err := json.MarshalIndent(data, "", "\t")
+	return writeJSON(w, status, data, true, headers...)
+}
+
+// writeJSON writes data as JSON with the status and headers, indented with tabs if indent is true.
+func writeJSON(w http.ResponseWriter, status int, data any, indent bool, headers ...http.Header) error {
+	var (
+		js  []byte
+		err error
+	)
+	if indent {
+		js, err = json.MarshalIndent(data, "", "\t")
+	} else {
+		js, err = json.Marshal(data)
+	}
 	if err != nil {
 		return err
 	}
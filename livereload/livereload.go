@@ -0,0 +1,373 @@
+// Package livereload refreshes the browser when templates change during development.
+//
+// A Reloader watches the template file systems for changes, calls an optional OnChange function (usually
+// HyperView.Reinit, to reload the templates) and tells the connected browsers to refresh through a server-sent events
+// endpoint. Pages connect to the endpoint with the script rendered by the liveReload template function, or injected
+// by the Inject middleware.
+package livereload
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hypergopher/hyperview/constants"
+)
+
+const (
+	// DefaultPath is the default path of the events endpoint. The script is served at the same path with a .js
+	// extension.
+	DefaultPath = "/_livereload"
+	// DefaultInterval is the default interval between two checks of the file systems.
+	DefaultInterval = 500 * time.Millisecond
+)
+
+// script connects to the events endpoint. It reloads the page when told to, and after reconnecting to a restarted
+// server, so changes to the Go code are picked up too.
+const script = `(() => {
+  const source = new EventSource(%q);
+  let disconnected = false;
+  source.addEventListener("reload", () => location.reload());
+  source.addEventListener("shutdown", () => { disconnected = true; });
+  source.onerror = () => { disconnected = true; };
+  source.onopen = () => { if (disconnected) location.reload(); };
+})();
+`
+
+// Options are the options for a Reloader.
+type Options struct {
+	// Path is the path of the events endpoint. Default is DefaultPath.
+	Path string
+	// Interval is the interval between two checks of the file systems. Default is DefaultInterval.
+	Interval time.Duration
+	// OnChange is called when a file changes, before the browsers are told to refresh. If it returns an error, the
+	// error is logged and the browsers are not refreshed.
+	OnChange func() error
+	// Logger is the logger to use for errors. Default is slog.Default().
+	Logger *slog.Logger
+}
+
+// Reloader watches file systems and tells the connected browsers to refresh when they change.
+type Reloader struct {
+	opts        Options
+	fileSystems []fs.FS
+	mu          sync.Mutex
+	clients     map[chan string]struct{}
+	closed      bool
+	stop        chan struct{}
+	done        chan struct{}
+	startOnce   sync.Once
+}
+
+// New creates a new Reloader watching the given file systems. Call Start to start watching.
+func New(opts Options, fileSystems ...fs.FS) *Reloader {
+	if opts.Path == "" {
+		opts.Path = DefaultPath
+	}
+	if opts.Interval == 0 {
+		opts.Interval = DefaultInterval
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+
+	return &Reloader{
+		opts:        opts,
+		fileSystems: fileSystems,
+		clients:     make(map[chan string]struct{}),
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+// Start starts watching the file systems in the background, until Shutdown is called.
+func (r *Reloader) Start() {
+	r.startOnce.Do(func() {
+		go r.watch()
+	})
+}
+
+func (r *Reloader) watch() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.opts.Interval)
+	defer ticker.Stop()
+
+	last := r.snapshot()
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			current := r.snapshot()
+			if current == last {
+				continue
+			}
+			last = current
+
+			if r.opts.OnChange != nil {
+				if err := r.opts.OnChange(); err != nil {
+					r.opts.Logger.Error("Live reload failed", slog.String("err", err.Error()))
+					continue
+				}
+			}
+			r.Reload()
+		}
+	}
+}
+
+// snapshot returns a fingerprint of the paths, sizes and modification times of the files in the file systems.
+func (r *Reloader) snapshot() string {
+	var b strings.Builder
+	for i, fsys := range r.fileSystems {
+		_ = fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			fmt.Fprintf(&b, "%d:%s:%d:%d\n", i, path, info.Size(), info.ModTime().UnixNano())
+			return nil
+		})
+	}
+	return b.String()
+}
+
+// Reload tells the connected browsers to refresh.
+func (r *Reloader) Reload() {
+	r.broadcast("reload")
+}
+
+func (r *Reloader) broadcast(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for client := range r.clients {
+		select {
+		case client <- event:
+		default:
+			// The client has a pending event already
+		}
+	}
+}
+
+// Shutdown stops watching the file systems and closes the connections of the browsers, telling them to reconnect.
+// It can be registered with HyperView.OnShutdown.
+func (r *Reloader) Shutdown(ctx context.Context) error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	for client := range r.clients {
+		close(client)
+	}
+	r.clients = make(map[chan string]struct{})
+	r.mu.Unlock()
+
+	close(r.stop)
+
+	// If the watcher was never started, there is nothing to wait for
+	r.startOnce.Do(func() {
+		close(r.done)
+	})
+
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Handler returns the handler of the events endpoint and the script, to mount at the reloader's path and the same
+// path with a .js extension.
+func (r *Reloader) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case r.opts.Path:
+			r.serveEvents(w, req)
+		case r.opts.Path + ".js":
+			w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+			w.Header().Set("Cache-Control", "no-cache")
+			_, _ = fmt.Fprintf(w, script, r.opts.Path)
+		default:
+			http.NotFound(w, req)
+		}
+	})
+}
+
+func (r *Reloader) serveEvents(w http.ResponseWriter, req *http.Request) {
+	client := make(chan string, 1)
+
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	r.clients[client] = struct{}{}
+	r.mu.Unlock()
+
+	defer func() {
+		r.mu.Lock()
+		delete(r.clients, client)
+		r.mu.Unlock()
+	}()
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	// Ask the browser to reconnect quickly after a restart
+	retry := strconv.FormatInt(r.opts.Interval.Milliseconds()*2, 10)
+	_, _ = fmt.Fprintf(w, "retry: %s\n\n", retry)
+	_ = rc.Flush()
+
+	for {
+		select {
+		case <-req.Context().Done():
+			return
+		case event, ok := <-client:
+			if !ok {
+				_, _ = fmt.Fprintf(w, "event: shutdown\ndata: reconnect\nretry: %s\n\n", retry)
+				_ = rc.Flush()
+				return
+			}
+			_, _ = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, event)
+			_ = rc.Flush()
+		}
+	}
+}
+
+// scriptTag returns the script element loading the live reload script, with the nonce of the Content-Security-Policy
+// of the page, if any, so the policy allows it.
+func (r *Reloader) scriptTag(nonce string) string {
+	tag := `<script src="` + template.HTMLEscapeString(r.opts.Path+".js") + `"`
+	if nonce != "" {
+		tag += ` nonce="` + template.HTMLEscapeString(nonce) + `"`
+	}
+	return tag + `></script>`
+}
+
+// Funcs returns the template functions of the reloader, to add to the template adapter's Funcs option:
+//
+//   - liveReload: returns the script element connecting the page to the reloader, e.g. {{liveReload}} before </body>,
+//     or {{liveReload cspNonce}} for pages whose Content-Security-Policy allows scripts by nonce
+//
+// Funcs can be called on a nil Reloader, in which case liveReload renders nothing. This lets templates call
+// liveReload in every environment, with a Reloader only created in development.
+func (r *Reloader) Funcs() template.FuncMap {
+	return template.FuncMap{
+		"liveReload": func(nonce ...string) template.HTML {
+			if r == nil {
+				return ""
+			}
+			return template.HTML(r.scriptTag(strings.Join(nonce, "")))
+		},
+	}
+}
+
+// Inject returns a middleware injecting the live reload script before the closing </body> tag of HTML responses, as
+// an alternative to calling liveReload in the layouts. The script has the CSP nonce of the request set by the csp
+// middleware, if any.
+//
+// Responses are buffered until the handler returns, unless it flushes them, e.g. for streamed pages or server-sent
+// events: the response is then written through, and the script is injected in the first write holding </body>.
+func (r *Reloader) Inject(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if strings.HasPrefix(req.URL.Path, r.opts.Path) {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		nonce, _ := req.Context().Value(constants.NonceContextKey).(string)
+		buf := &bufferedWriter{ResponseWriter: w, status: http.StatusOK, script: []byte(r.scriptTag(nonce))}
+		next.ServeHTTP(buf, req)
+		if buf.flushed {
+			return
+		}
+
+		body := buf.inject(buf.body.Bytes())
+		w.WriteHeader(buf.status)
+		_, _ = w.Write(body)
+	})
+}
+
+// bufferedWriter buffers a response, so the script can be injected before it is written, until it is flushed.
+type bufferedWriter struct {
+	http.ResponseWriter
+	status   int
+	body     bytes.Buffer
+	script   []byte
+	flushed  bool // the response was flushed, and is written through since
+	injected bool
+}
+
+func (b *bufferedWriter) WriteHeader(status int) {
+	if !b.flushed {
+		b.status = status
+	}
+}
+
+func (b *bufferedWriter) Write(p []byte) (int, error) {
+	if b.Header().Get("Content-Type") == "" {
+		b.Header().Set("Content-Type", http.DetectContentType(p))
+	}
+	if !b.flushed {
+		return b.body.Write(p)
+	}
+	if _, err := b.ResponseWriter.Write(b.inject(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Flush writes the buffered response and flushes it, so streamed responses reach the client as they are written. The
+// rest of the response is written through.
+func (b *bufferedWriter) Flush() {
+	if !b.flushed {
+		body := b.inject(b.body.Bytes())
+		b.flushed = true
+		b.ResponseWriter.WriteHeader(b.status)
+		_, _ = b.ResponseWriter.Write(body)
+		b.body.Reset()
+	}
+	_ = http.NewResponseController(b.ResponseWriter).Flush()
+}
+
+// Unwrap returns the wrapped writer, for http.ResponseController.
+func (b *bufferedWriter) Unwrap() http.ResponseWriter {
+	return b.ResponseWriter
+}
+
+// inject returns the part of an HTML response with the script inserted before its last </body> tag, once per
+// response. Parts written after the headers, whose Content-Length would no longer match, are returned as is.
+func (b *bufferedWriter) inject(p []byte) []byte {
+	if b.injected || !strings.HasPrefix(b.Header().Get("Content-Type"), "text/html") {
+		return p
+	}
+	if b.flushed && b.Header().Get("Content-Length") != "" {
+		return p
+	}
+	idx := bytes.LastIndex(p, []byte("</body>"))
+	if idx == -1 {
+		return p
+	}
+	b.injected = true
+	b.Header().Del("Content-Length")
+	return append(p[:idx:idx], append(b.script, p[idx:]...)...)
+}
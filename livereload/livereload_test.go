@@ -0,0 +1,179 @@
+package livereload_test
+
+import (
+	"bufio"
+	"context"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hypergopher/hyperview/csp"
+	"github.com/hypergopher/hyperview/livereload"
+)
+
+// readEvent reads the next server-sent event name from the stream.
+func readEvent(t *testing.T, reader *bufio.Reader) string {
+	t.Helper()
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("error reading event stream: %v", err)
+		}
+		if event, ok := strings.CutPrefix(strings.TrimSpace(line), "event: "); ok {
+			return event
+		}
+	}
+}
+
+func TestReloader_WatchAndShutdown(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "home.html")
+	if err := os.WriteFile(file, []byte("v1"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var changes atomic.Int32
+	reloader := livereload.New(livereload.Options{
+		Interval: 10 * time.Millisecond,
+		OnChange: func() error {
+			changes.Add(1)
+			return nil
+		},
+	}, os.DirFS(dir))
+	reloader.Start()
+
+	server := httptest.NewServer(reloader.Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + livereload.DefaultPath)
+	if err != nil {
+		t.Fatalf("error connecting to the events endpoint: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("unexpected content type: %s", ct)
+	}
+	reader := bufio.NewReader(resp.Body)
+
+	// Change the file once connected, with a distinct modification time
+	if err := os.WriteFile(file, []byte("v2 changed"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(file, time.Now(), time.Now().Add(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+
+	if event := readEvent(t, reader); event != "reload" {
+		t.Errorf("unexpected event: %s", event)
+	}
+	if changes.Load() == 0 {
+		t.Error("expected OnChange to be called before the reload")
+	}
+
+	if err := reloader.Shutdown(context.Background()); err != nil {
+		t.Fatalf("unexpected shutdown error: %v", err)
+	}
+	if event := readEvent(t, reader); event != "shutdown" {
+		t.Errorf("unexpected event: %s", event)
+	}
+}
+
+func TestReloader_Script(t *testing.T) {
+	reloader := livereload.New(livereload.Options{Path: "/dev/reload"})
+
+	w := httptest.NewRecorder()
+	reloader.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/dev/reload.js", nil))
+
+	if !strings.Contains(w.Body.String(), `new EventSource("/dev/reload")`) {
+		t.Errorf("unexpected script: %s", w.Body.String())
+	}
+}
+
+func TestReloader_Inject(t *testing.T) {
+	reloader := livereload.New(livereload.Options{})
+
+	tests := []struct {
+		name        string
+		contentType string
+		nonce       string
+		body        string
+		want        string
+	}{
+		{"html page", "text/html; charset=utf-8", "", "<body>hi</body>", `<body>hi<script src="/_livereload.js"></script></body>`},
+		{"detected html", "", "", "<html><body>hi</body></html>", `<html><body>hi<script src="/_livereload.js"></script></body></html>`},
+		{"csp nonce", "text/html", "abc", "<body>hi</body>", `<body>hi<script src="/_livereload.js" nonce="abc"></script></body>`},
+		{"json", "application/json", "", `{"body": "</body>"}`, `{"body": "</body>"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := reloader.Inject(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.contentType != "" {
+					w.Header().Set("Content-Type", tt.contentType)
+				}
+				w.WriteHeader(http.StatusTeapot)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.nonce != "" {
+				r = r.WithContext(csp.ContextWithNonce(r.Context(), tt.nonce))
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != http.StatusTeapot {
+				t.Errorf("unexpected status: %d", w.Code)
+			}
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("unexpected body: got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestReloader_InjectFlushed checks flushed responses are written through, with the script injected in the part
+// holding </body>.
+func TestReloader_InjectFlushed(t *testing.T) {
+	reloader := livereload.New(livereload.Options{})
+
+	w := httptest.NewRecorder()
+	handler := reloader.Inject(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", "text/html")
+		_, _ = rw.Write([]byte("<html><body>"))
+		if err := http.NewResponseController(rw).Flush(); err != nil {
+			t.Errorf("Flush() error = %v", err)
+		}
+		if !w.Flushed || w.Body.String() != "<html><body>" {
+			t.Errorf("expected the flushed part to be written, got %q", w.Body.String())
+		}
+		_, _ = rw.Write([]byte("hi</body></html>"))
+	}))
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if got, want := w.Body.String(), `<html><body>hi<script src="/_livereload.js"></script></body></html>`; got != want {
+		t.Errorf("unexpected body: got %q, want %q", got, want)
+	}
+}
+
+func TestReloader_Funcs(t *testing.T) {
+	fn := livereload.New(livereload.Options{}).Funcs()["liveReload"].(func(...string) template.HTML)
+	if got, want := fn("abc"), template.HTML(`<script src="/_livereload.js" nonce="abc"></script>`); got != want {
+		t.Errorf("liveReload(nonce) = %q, want %q", got, want)
+	}
+}
+
+func TestReloader_FuncsWithoutReloader(t *testing.T) {
+	var reloader *livereload.Reloader
+	fn := reloader.Funcs()["liveReload"].(func(...string) template.HTML)
+	if got := fn(); got != "" {
+		t.Errorf("expected no script without a reloader, got %q", got)
+	}
+}
@@ -0,0 +1,31 @@
+package hyperview
+
+import (
+	"net/http"
+	"time"
+)
+
+// RenderEvent describes a completed render. It is passed to the render hook of an adapter, so applications can
+// record metrics about the pages they serve.
+type RenderEvent struct {
+	// Template is the path of the rendered view template (e.g. "views/home").
+	Template string
+	// Layout is the layout the view was rendered with.
+	Layout string
+	// Status is the HTTP status code of the response.
+	Status int
+	// Duration is the time spent loading data, executing the template and writing the response.
+	Duration time.Duration
+	// Size is the number of bytes in the rendered body.
+	Size int
+	// Variants are the experiment variants the page was rendered with, keyed by experiment name.
+	Variants map[string]string
+	// Flags are the feature flags the page was rendered with, those recorded by the handler and those evaluated by the
+	// templates, keyed by flag name.
+	Flags map[string]bool
+	// Err is the error that caused the render to fail, if any.
+	Err error
+}
+
+// RenderHook is a function called after each render with a description of the render.
+type RenderHook func(r *http.Request, event RenderEvent)
@@ -0,0 +1,71 @@
+package hyperview
+
+import (
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hypergopher/hyperview/response"
+)
+
+// negotiatedTypes maps the media types of Accept headers to the keys of the adapters rendering them.
+var negotiatedTypes = map[string]string{
+	"text/html":             "html",
+	"application/xhtml+xml": "html",
+	"application/json":      "json",
+	"application/xml":       "xml",
+	"text/xml":              "xml",
+}
+
+// RenderNegotiated renders the response with the adapter of the media type preferred by the Accept header of the
+// request, among the html, json and xml adapters that are registered, so handlers can serve pages and structured
+// output through one code path. Requests without a matching media type, such as browsers accepting */*, are rendered
+// with the html adapter. The response gets a Vary: Accept header, so caches keep the formats apart.
+func (s *HyperView) RenderNegotiated(w http.ResponseWriter, r *http.Request, resp *response.Response) {
+	w.Header().Add("Vary", "Accept")
+	s.RenderAs(w, r, s.negotiate(r.Header.Get("Accept")), resp)
+}
+
+// negotiate returns the key of the adapter for the media type preferred by an Accept header.
+func (s *HyperView) negotiate(accept string) string {
+	type candidate struct {
+		key     string
+		quality float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		key, ok := negotiatedTypes[mediaType]
+		if !ok {
+			continue
+		}
+		if _, ok := s.Adapter(key); !ok {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		if quality > 0 {
+			candidates = append(candidates, candidate{key: key, quality: quality})
+		}
+	}
+
+	// The preferred media type has the highest quality, then comes first in the header
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].quality > candidates[j].quality
+	})
+	if len(candidates) == 0 {
+		return "html"
+	}
+	return candidates[0].key
+}
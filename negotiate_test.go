@@ -0,0 +1,70 @@
+package hyperview_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func TestHyperView_RenderNegotiated(t *testing.T) {
+	hv, err := hyperview.NewHyperView()
+	if err != nil {
+		t.Fatalf("error creating HyperView: %v", err)
+	}
+	adapters := map[string]hyperview.Adapter{
+		"html": newTestTemplateAdapter(t, fstest.MapFS{
+			"layouts/base.html": {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+			"views/users.html":  {Data: []byte(`{{define "page:main"}}<p>{{.name}}</p>{{end}}`)},
+		}, hyperview.TemplateViewAdapterOptions{}),
+		"json": hyperview.NewJSONViewAdapter(hyperview.JSONAdapterOptions{Compact: true, Bare: true}),
+		"xml":  hyperview.NewXMLViewAdapter(hyperview.XMLAdapterOptions{Compact: true, Bare: true}),
+	}
+	for key, adapter := range adapters {
+		if err := hv.RegisterAdapter(key, adapter); err != nil {
+			t.Fatalf("error registering adapter: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		accept   string
+		wantType string
+	}{
+		{"no header", "", "html"},
+		{"browser", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", "html"},
+		{"json", "application/json", "application/json"},
+		{"xml", "text/xml", "application/xml"},
+		{"by quality", "application/xml;q=0.5, application/json", "application/json"},
+		{"by order", "application/xml, application/json", "application/xml"},
+		{"refused type", "application/json;q=0, application/xml;q=0.1", "application/xml"},
+		{"unknown type", "image/png", "html"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/users", nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+
+			w := httptest.NewRecorder()
+			hv.RenderNegotiated(w, r, response.NewResponse().Path("users").Data(map[string]any{"name": "Ada"}))
+			// The template adapter leaves the content type to the server, which sniffs it
+			if tt.wantType == "html" {
+				if got := w.Body.String(); got != "<p>Ada</p>" {
+					t.Errorf("unexpected body: got %q, want the HTML page", got)
+				}
+			} else if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, tt.wantType) {
+				t.Errorf("unexpected content type: got %q, want %q", got, tt.wantType)
+			}
+			if got := w.Header().Get("Vary"); got != "Accept" {
+				t.Errorf("unexpected Vary header: %q", got)
+			}
+		})
+	}
+}
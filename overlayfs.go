@@ -0,0 +1,153 @@
+package hyperview
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+
+	"github.com/hypergopher/hyperview/constants"
+)
+
+// OverlayFS is a file system layering several file systems on top of each other. A file in a layer shadows the file
+// with the same path in the layers below it, and directories list the files of all layers.
+type OverlayFS struct {
+	layers []fs.FS // from the top layer down to the base layer
+}
+
+// NewOverlayFS creates a new OverlayFS from the given layers, where later layers take precedence.
+//
+// Example: NewOverlayFS(embeddedTemplates, os.DirFS("overrides")) serves the files in the overrides directory
+// instead of the embedded templates with the same path.
+func NewOverlayFS(layers ...fs.FS) *OverlayFS {
+	reversed := make([]fs.FS, len(layers))
+	for i, layer := range layers {
+		reversed[len(layers)-1-i] = layer
+	}
+	return &OverlayFS{layers: reversed}
+}
+
+// EnvironmentFS returns fsys overlaid with its environments/<env> directory, so an environment such as staging or
+// demo can replace specific views, partials or layouts (e.g. environments/staging/partials/banner.html) without code
+// branches. If env is empty or the environment has no directory, fsys is returned as is.
+func EnvironmentFS(fsys fs.FS, env string) fs.FS {
+	if env == "" {
+		return fsys
+	}
+
+	dir := path.Join(constants.EnvironmentsDir, env)
+	if info, err := fs.Stat(fsys, dir); err != nil || !info.IsDir() {
+		return fsys
+	}
+
+	overlay, err := fs.Sub(fsys, dir)
+	if err != nil {
+		return fsys
+	}
+
+	return NewOverlayFS(fsys, overlay)
+}
+
+// Open opens the named file from the topmost layer containing it.
+func (o *OverlayFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	for _, layer := range o.layers {
+		f, err := layer.Open(name)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+
+		info, err := f.Stat()
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		if !info.IsDir() {
+			return f, nil
+		}
+
+		// Directories list the entries of all layers
+		entries, err := o.ReadDir(name)
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		return &overlayDir{File: f, entries: entries}, nil
+	}
+
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// ReadDir reads the named directory in all layers and returns the merged entries sorted by name. Entries in upper
+// layers shadow the entries with the same name in lower layers.
+func (o *OverlayFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+
+	found := false
+	entries := make(map[string]fs.DirEntry)
+	for _, layer := range o.layers {
+		layerEntries, err := fs.ReadDir(layer, name)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+
+		found = true
+		for _, entry := range layerEntries {
+			if _, ok := entries[entry.Name()]; !ok {
+				entries[entry.Name()] = entry
+			}
+		}
+	}
+
+	if !found {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+
+	merged := make([]fs.DirEntry, 0, len(entries))
+	for _, entry := range entries {
+		merged = append(merged, entry)
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].Name() < merged[j].Name()
+	})
+
+	return merged, nil
+}
+
+// overlayDir is a directory opened from an OverlayFS, listing the merged entries of all layers.
+type overlayDir struct {
+	fs.File
+	entries []fs.DirEntry
+	offset  int
+}
+
+// ReadDir reads the merged entries of the directory, following the semantics of fs.ReadDirFile.
+func (d *overlayDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	remaining := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return remaining, nil
+	}
+
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(remaining) {
+		n = len(remaining)
+	}
+	d.offset += n
+
+	return remaining[:n], nil
+}
@@ -0,0 +1,88 @@
+package hyperview_test
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func TestOverlayFS(t *testing.T) {
+	base := fstest.MapFS{
+		"views/home.html":   {Data: []byte("base home")},
+		"views/about.html":  {Data: []byte("base about")},
+		"partials/nav.html": {Data: []byte("base nav")},
+	}
+	overlay := fstest.MapFS{
+		"views/home.html":    {Data: []byte("overlay home")},
+		"views/sandbox.html": {Data: []byte("overlay sandbox")},
+	}
+
+	fsys := hyperview.NewOverlayFS(base, overlay)
+
+	if err := fstest.TestFS(fsys, "views/home.html", "views/about.html", "views/sandbox.html", "partials/nav.html"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"views/home.html", "overlay home"},
+		{"views/about.html", "base about"},
+		{"views/sandbox.html", "overlay sandbox"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := fs.ReadFile(fsys, tt.path)
+			if err != nil {
+				t.Fatalf("error reading file: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("ReadFile() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := fsys.Open("views/missing.html"); err == nil {
+		t.Error("expected an error opening a missing file")
+	}
+}
+
+func TestEnvironmentFS(t *testing.T) {
+	files := fstest.MapFS{
+		"layouts/base.html":                         {Data: []byte(`{{define "layout:base"}}{{template "banner" .}}{{template "page:main" .}}{{end}}`)},
+		"partials/banner.html":                      {Data: []byte(`{{define "banner"}}{{end}}`)},
+		"views/home.html":                           {Data: []byte(`{{define "page:main"}}home{{end}}`)},
+		"environments/staging/partials/banner.html": {Data: []byte(`{{define "banner"}}[staging]{{end}}`)},
+	}
+
+	tests := []struct {
+		env  string
+		want string
+	}{
+		{"", "home"},
+		{"production", "home"},
+		{"staging", "[staging]home"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.env, func(t *testing.T) {
+			adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+				FileSystemMap: map[string]fs.FS{constants.RootFSID: hyperview.EnvironmentFS(files, tt.env)},
+			})
+			if err := adapter.Init(); err != nil {
+				t.Fatalf("error initializing adapter: %v", err)
+			}
+
+			w := renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("home"))
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("unexpected body: got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
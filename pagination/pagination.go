@@ -0,0 +1,226 @@
+// Package pagination computes the pages of paginated lists and the links between them, preserving the query
+// parameters of the request, such as filters and sort orders.
+//
+// A Paginator is typically created from the request in a handler and added to the view data:
+//
+//	p := pagination.FromRequest(r, 20, total)
+//	items, err := store.List(ctx, p.Offset(), p.Limit())
+//	resp := response.NewResponse().Path("posts/index").Data(map[string]any{"Posts": items, "Pagination": p})
+//
+// Views render the links with the built-in @pagination partial, which applications can override by defining a
+// partial with the same name:
+//
+//	{{template "@pagination" .Pagination}}
+package pagination
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const (
+	// DefaultParam is the name of the query parameter holding the page number.
+	DefaultParam = "page"
+	// DefaultWindow is the number of pages linked on each side of the current page.
+	DefaultWindow = 2
+)
+
+// Paginator describes the current page of a paginated list. Its fields can be set after it is created, e.g. to change
+// the window. Pages are numbered from 1.
+type Paginator struct {
+	// Page is the current page, clamped to the range of pages.
+	Page int
+	// PerPage is the number of items per page.
+	PerPage int
+	// Total is the total number of items.
+	Total int
+	// Window is the number of pages linked on each side of the current page. The first and last pages are always
+	// linked. Default is DefaultWindow.
+	Window int
+	// Param is the name of the query parameter holding the page number. Default is DefaultParam.
+	Param string
+	// URL is the URL the links are based on, with the query parameters to preserve. Only the path and query are used.
+	URL *url.URL
+}
+
+// Link is a link of the pagination, to a page or a gap between pages.
+type Link struct {
+	// Page is the page number, or 0 for a gap.
+	Page int
+	// URL is the URL of the page.
+	URL string
+	// Current reports whether the page is the current page.
+	Current bool
+}
+
+// Gap reports whether the link is a gap between pages, rendered as an ellipsis.
+func (l Link) Gap() bool {
+	return l.Page == 0
+}
+
+// New creates a new Paginator for the given page, clamped to the range of pages, with links based on u. A perPage
+// lower than 1 is treated as 1.
+func New(u *url.URL, page, perPage, total int) *Paginator {
+	if perPage < 1 {
+		perPage = 1
+	}
+	if u == nil {
+		u = &url.URL{}
+	}
+
+	p := &Paginator{
+		PerPage: perPage,
+		Total:   max(total, 0),
+		Window:  DefaultWindow,
+		Param:   DefaultParam,
+		URL:     u,
+	}
+	p.Page = min(max(page, 1), p.Pages())
+	return p
+}
+
+// FromRequest creates a new Paginator for the page of the request's page query parameter, defaulting to the first
+// page, with links based on the URL of the request.
+func FromRequest(r *http.Request, perPage, total int) *Paginator {
+	page, err := strconv.Atoi(r.URL.Query().Get(DefaultParam))
+	if err != nil {
+		page = 1
+	}
+	return New(r.URL, page, perPage, total)
+}
+
+// Pages returns the number of pages. Empty lists have a single, empty page.
+func (p *Paginator) Pages() int {
+	if p.Total == 0 {
+		return 1
+	}
+	return (p.Total + p.PerPage - 1) / p.PerPage
+}
+
+// Offset returns the index of the first item of the current page, for queries.
+func (p *Paginator) Offset() int {
+	return (p.Page - 1) * p.PerPage
+}
+
+// Limit returns the number of items per page, for queries.
+func (p *Paginator) Limit() int {
+	return p.PerPage
+}
+
+// First returns the 1-based position of the first item of the current page, or 0 if the list is empty.
+func (p *Paginator) First() int {
+	if p.Total == 0 {
+		return 0
+	}
+	return p.Offset() + 1
+}
+
+// Last returns the 1-based position of the last item of the current page, or 0 if the list is empty.
+func (p *Paginator) Last() int {
+	return min(p.Offset()+p.PerPage, p.Total)
+}
+
+// HasPrev reports whether there is a page before the current page.
+func (p *Paginator) HasPrev() bool {
+	return p.Page > 1
+}
+
+// HasNext reports whether there is a page after the current page.
+func (p *Paginator) HasNext() bool {
+	return p.Page < p.Pages()
+}
+
+// HasPages reports whether there is more than one page, i.e. whether the pagination should be rendered.
+func (p *Paginator) HasPages() bool {
+	return p.Pages() > 1
+}
+
+// PrevURL returns the URL of the previous page, or an empty string on the first page.
+func (p *Paginator) PrevURL() string {
+	if !p.HasPrev() {
+		return ""
+	}
+	return p.PageURL(p.Page - 1)
+}
+
+// NextURL returns the URL of the next page, or an empty string on the last page.
+func (p *Paginator) NextURL() string {
+	if !p.HasNext() {
+		return ""
+	}
+	return p.PageURL(p.Page + 1)
+}
+
+// PageURL returns the URL of the given page, with the query parameters of the paginator's URL. The page parameter is
+// left out for the first page, so it has a single URL.
+func (p *Paginator) PageURL(page int) string {
+	query := p.URL.Query()
+	if page <= 1 {
+		query.Del(p.param())
+	} else {
+		query.Set(p.param(), strconv.Itoa(page))
+	}
+
+	u := url.URL{Path: p.URL.Path, RawQuery: query.Encode()}
+	if u.Path == "" {
+		// Keep the URL relative to the current page rather than the current directory
+		return "?" + u.RawQuery
+	}
+	return u.String()
+}
+
+// Links returns the links to the first and last pages and the pages within the window around the current page, with
+// gaps where pages are skipped. A gap of a single page is replaced by a link to that page.
+func (p *Paginator) Links() []Link {
+	pages := p.Pages()
+	window := max(p.Window, 0)
+	start, end := max(p.Page-window, 1), min(p.Page+window, pages)
+
+	var links []Link
+	add := func(from, to int) {
+		for page := from; page <= to; page++ {
+			links = append(links, Link{Page: page, URL: p.PageURL(page), Current: page == p.Page})
+		}
+	}
+
+	switch {
+	case start > 3:
+		add(1, 1)
+		links = append(links, Link{})
+	default:
+		start = 1
+	}
+	if end < pages-2 {
+		add(start, end)
+		links = append(links, Link{})
+		add(pages, pages)
+	} else {
+		add(start, pages)
+	}
+
+	return links
+}
+
+func (p *Paginator) param() string {
+	if p.Param == "" {
+		return DefaultParam
+	}
+	return p.Param
+}
+
+// Template is the source of the built-in @pagination partial, rendering the links of a Paginator in a nav element
+// with previous and next links. Current pages are marked with aria-current, and unavailable links with aria-disabled.
+const Template = `{{define "@pagination"}}
+{{- if .HasPages -}}
+<nav class="pagination" aria-label="Pagination">
+<ul>
+<li>{{if .HasPrev}}<a href="{{.PrevURL}}" rel="prev">Previous</a>{{else}}<span aria-disabled="true">Previous</span>{{end}}</li>
+{{- range .Links}}
+<li>{{if .Gap}}<span aria-hidden="true">&hellip;</span>{{else if .Current}}<a href="{{.URL}}" aria-current="page">{{.Page}}</a>{{else}}<a href="{{.URL}}" aria-label="Page {{.Page}}">{{.Page}}</a>{{end}}</li>
+{{- end}}
+<li>{{if .HasNext}}<a href="{{.NextURL}}" rel="next">Next</a>{{else}}<span aria-disabled="true">Next</span>{{end}}</li>
+</ul>
+</nav>
+{{- end -}}
+{{end}}`
@@ -0,0 +1,96 @@
+package pagination_test
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/hypergopher/hyperview/pagination"
+)
+
+func TestFromRequest(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		total      int
+		wantPage   int
+		wantOffset int
+		wantPages  int
+	}{
+		{"first page", "/posts", 95, 1, 0, 10},
+		{"middle page", "/posts?page=3", 95, 3, 20, 10},
+		{"past the last page", "/posts?page=42", 95, 10, 90, 10},
+		{"invalid page", "/posts?page=abc", 95, 1, 0, 10},
+		{"negative page", "/posts?page=-2", 95, 1, 0, 10},
+		{"empty list", "/posts?page=2", 0, 1, 0, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := pagination.FromRequest(httptest.NewRequest("GET", tt.target, nil), 10, tt.total)
+			if p.Page != tt.wantPage || p.Offset() != tt.wantOffset || p.Pages() != tt.wantPages {
+				t.Errorf("got page %d, offset %d, pages %d; want %d, %d, %d", p.Page, p.Offset(), p.Pages(), tt.wantPage, tt.wantOffset, tt.wantPages)
+			}
+		})
+	}
+}
+
+func TestPaginator_PageURL(t *testing.T) {
+	p := pagination.FromRequest(httptest.NewRequest("GET", "/posts?q=go+templates&sort=new&page=2", nil), 10, 50)
+
+	tests := []struct {
+		page int
+		want string
+	}{
+		{1, "/posts?q=go+templates&sort=new"},
+		{3, "/posts?page=3&q=go+templates&sort=new"},
+	}
+	for _, tt := range tests {
+		if got := p.PageURL(tt.page); got != tt.want {
+			t.Errorf("PageURL(%d) = %q, want %q", tt.page, got, tt.want)
+		}
+	}
+
+	if got, want := p.PrevURL(), "/posts?q=go+templates&sort=new"; got != want {
+		t.Errorf("PrevURL() = %q, want %q", got, want)
+	}
+	if got := pagination.New(nil, 5, 10, 50).NextURL(); got != "" {
+		t.Errorf("NextURL() on the last page = %q, want empty", got)
+	}
+	if got, want := pagination.New(nil, 1, 10, 50).NextURL(), "?page=2"; got != want {
+		t.Errorf("NextURL() without a URL = %q, want %q", got, want)
+	}
+}
+
+func TestPaginator_Links(t *testing.T) {
+	tests := []struct {
+		name  string
+		page  int
+		pages int
+		want  []int // 0 is a gap
+	}{
+		{"single page", 1, 1, []int{1}},
+		{"few pages", 2, 4, []int{1, 2, 3, 4}},
+		{"start", 1, 20, []int{1, 2, 3, 0, 20}},
+		{"middle", 10, 20, []int{1, 0, 8, 9, 10, 11, 12, 0, 20}},
+		{"end", 20, 20, []int{1, 0, 18, 19, 20}},
+		{"single page gaps are linked", 5, 9, []int{1, 2, 3, 4, 5, 6, 7, 8, 9}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := pagination.New(nil, tt.page, 10, tt.pages*10)
+
+			var got []int
+			for _, link := range p.Links() {
+				got = append(got, link.Page)
+				if link.Current != (link.Page == tt.page) {
+					t.Errorf("link %d: Current = %v", link.Page, link.Current)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Links() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
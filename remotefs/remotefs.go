@@ -0,0 +1,210 @@
+// Package remotefs loads template file systems from remote storage, such as an HTTP endpoint or object storage, so
+// themes can be deployed independently from the application binary.
+//
+// A theme is a zip archive laid out like the template directories (layouts, partials and views). It is fetched at
+// startup, verified against its checksum and kept in a local cache, which is used when the remote is unavailable.
+// Object stores such as S3 are supported through presigned URLs with HTTPFetcher, or by implementing Fetcher with
+// their SDK.
+package remotefs
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultRetries is the default number of retries of a failed fetch.
+const DefaultRetries = 3
+
+// DefaultRetryDelay is the default delay before the first retry of a failed fetch. It doubles with each retry.
+const DefaultRetryDelay = 500 * time.Millisecond
+
+// ErrChecksumMismatch is returned when an archive does not match the expected checksum.
+var ErrChecksumMismatch = errors.New("remotefs: checksum mismatch")
+
+// Fetcher fetches the archive of a theme.
+type Fetcher interface {
+	// Fetch returns the content of the archive.
+	Fetch(ctx context.Context) ([]byte, error)
+}
+
+// HTTPFetcher fetches an archive with an HTTP GET request, e.g. from a CDN or a presigned object storage URL.
+type HTTPFetcher struct {
+	// URL is the URL of the archive.
+	URL string
+	// Header is added to the request, e.g. for authorization.
+	Header http.Header
+	// Client is the HTTP client to use. Default is http.DefaultClient.
+	Client *http.Client
+}
+
+// Fetch fetches the archive at the URL. Responses with a status other than 200 OK are errors.
+func (f *HTTPFetcher) Fetch(ctx context.Context) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range f.Header {
+		req.Header[key] = values
+	}
+
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status fetching %s: %s", f.URL, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// Options are the options for Load.
+type Options struct {
+	// Fetcher fetches the archive. Required.
+	Fetcher Fetcher
+	// Checksum is the expected hex-encoded SHA-256 checksum of the archive, e.g. published along with the theme.
+	// Archives not matching it are rejected, whether fetched or cached. If empty, archives are not verified.
+	Checksum string
+	// Retries is the number of retries of a failed fetch. Default is DefaultRetries; use a negative value to disable
+	// retries.
+	Retries int
+	// RetryDelay is the delay before the first retry, doubling with each retry. Default is DefaultRetryDelay.
+	RetryDelay time.Duration
+	// CacheFile is the path of the local copy of the archive, written after each successful fetch and used when the
+	// remote is unavailable. If empty, archives are not cached.
+	CacheFile string
+	// Logger logs failed fetches and fallbacks to the cache. Default is slog.Default().
+	Logger *slog.Logger
+}
+
+// Load fetches the archive of a theme and returns its file system. Failed fetches are retried, and if the remote is
+// still unavailable, the cached archive is used instead.
+func Load(ctx context.Context, opts Options) (fs.FS, error) {
+	if opts.Fetcher == nil {
+		return nil, errors.New("remotefs: no fetcher")
+	}
+	if opts.Retries == 0 {
+		opts.Retries = DefaultRetries
+	}
+	if opts.RetryDelay <= 0 {
+		opts.RetryDelay = DefaultRetryDelay
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+
+	data, fetchErr := fetch(ctx, opts)
+	if fetchErr == nil {
+		if opts.CacheFile != "" {
+			if err := writeCache(opts.CacheFile, data); err != nil {
+				opts.Logger.Warn("Error caching remote templates", slog.String("file", opts.CacheFile), slog.String("err", err.Error()))
+			}
+		}
+		return open(data)
+	}
+
+	if opts.CacheFile == "" {
+		return nil, fetchErr
+	}
+
+	data, err := os.ReadFile(opts.CacheFile)
+	if err != nil {
+		return nil, fmt.Errorf("%w; no cached copy: %w", fetchErr, err)
+	}
+	if err := verify(data, opts.Checksum); err != nil {
+		return nil, fmt.Errorf("%w; cached copy %s: %w", fetchErr, opts.CacheFile, err)
+	}
+
+	opts.Logger.Warn("Remote templates unavailable, using the cached copy",
+		slog.String("file", opts.CacheFile), slog.String("err", fetchErr.Error()))
+	return open(data)
+}
+
+// fetch fetches and verifies the archive, retrying with exponential backoff.
+func fetch(ctx context.Context, opts Options) ([]byte, error) {
+	delay := opts.RetryDelay
+	for attempt := 0; ; attempt++ {
+		data, err := opts.Fetcher.Fetch(ctx)
+		if err == nil {
+			err = verify(data, opts.Checksum)
+		}
+		if err == nil {
+			return data, nil
+		}
+
+		if attempt >= opts.Retries || ctx.Err() != nil {
+			return nil, fmt.Errorf("error fetching remote templates: %w", err)
+		}
+		opts.Logger.Warn("Error fetching remote templates, retrying",
+			slog.Int("attempt", attempt+1), slog.Duration("delay", delay), slog.String("err", err.Error()))
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("error fetching remote templates: %w", ctx.Err())
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// verify returns ErrChecksumMismatch if data does not match the checksum, if any.
+func verify(data []byte, checksum string) error {
+	if checksum == "" {
+		return nil
+	}
+
+	sum := sha256.Sum256(data)
+	if got := hex.EncodeToString(sum[:]); got != strings.ToLower(checksum) {
+		return fmt.Errorf("%w: got %s, want %s", ErrChecksumMismatch, got, checksum)
+	}
+	return nil
+}
+
+// writeCache writes the archive to the cache file, through a temporary file so readers never see a partial archive.
+func writeCache(file string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(file), filepath.Base(file)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
+
+func open(data []byte) (fs.FS, error) {
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("error opening templates archive: %w", err)
+	}
+	return r, nil
+}
@@ -0,0 +1,168 @@
+package remotefs_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hypergopher/hyperview/remotefs"
+)
+
+func themeArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func readFile(t *testing.T, fsys fs.FS, name string) string {
+	t.Helper()
+
+	b, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		t.Fatalf("error reading %s: %v", name, err)
+	}
+	return string(b)
+}
+
+var quietLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func TestLoad(t *testing.T) {
+	archive := themeArchive(t, map[string]string{"views/home.html": "home"})
+
+	var requests atomic.Int32
+	var down atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		// The first request of each load fails, so loads succeed after a retry while the remote is up
+		if down.Load() || requests.Load()%2 == 1 {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write(archive)
+	}))
+	defer server.Close()
+
+	opts := remotefs.Options{
+		Fetcher:    &remotefs.HTTPFetcher{URL: server.URL, Header: http.Header{"Authorization": {"Bearer token"}}},
+		Checksum:   checksum(archive),
+		RetryDelay: time.Millisecond,
+		CacheFile:  filepath.Join(t.TempDir(), "theme", "theme.zip"),
+		Logger:     quietLogger,
+	}
+
+	fsys, err := remotefs.Load(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("error loading remote templates: %v", err)
+	}
+	if got := readFile(t, fsys, "views/home.html"); got != "home" {
+		t.Errorf("unexpected template: %q", got)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("expected the failed fetch to be retried once, got %d requests", n)
+	}
+
+	// The cached copy is used when the remote is unavailable
+	down.Store(true)
+	fsys, err = remotefs.Load(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("expected the cached copy to be used, got %v", err)
+	}
+	if got := readFile(t, fsys, "views/home.html"); got != "home" {
+		t.Errorf("unexpected cached template: %q", got)
+	}
+	if n := requests.Load(); n != 2+remotefs.DefaultRetries+1 {
+		t.Errorf("expected %d attempts while the remote is down, got %d", remotefs.DefaultRetries+1, n-2)
+	}
+
+	// The cached copy is verified too
+	opts.Checksum = checksum([]byte("another theme"))
+	if _, err := remotefs.Load(context.Background(), opts); !errors.Is(err, remotefs.ErrChecksumMismatch) {
+		t.Errorf("expected a checksum mismatch for the cached copy, got %v", err)
+	}
+}
+
+type fetcherFunc func(ctx context.Context) ([]byte, error)
+
+func (f fetcherFunc) Fetch(ctx context.Context) ([]byte, error) {
+	return f(ctx)
+}
+
+func TestLoad_Errors(t *testing.T) {
+	archive := themeArchive(t, map[string]string{"views/home.html": "home"})
+
+	tests := []struct {
+		name    string
+		opts    remotefs.Options
+		wantErr error
+	}{
+		{
+			name: "checksum mismatch",
+			opts: remotefs.Options{
+				Fetcher:  fetcherFunc(func(context.Context) ([]byte, error) { return archive, nil }),
+				Checksum: checksum([]byte("tampered")),
+				Retries:  -1,
+			},
+			wantErr: remotefs.ErrChecksumMismatch,
+		},
+		{
+			name: "remote unavailable without cache",
+			opts: remotefs.Options{
+				Fetcher: fetcherFunc(func(context.Context) ([]byte, error) { return nil, io.ErrUnexpectedEOF }),
+				Retries: -1,
+			},
+			wantErr: io.ErrUnexpectedEOF,
+		},
+		{
+			name: "remote unavailable with missing cache",
+			opts: remotefs.Options{
+				Fetcher:   fetcherFunc(func(context.Context) ([]byte, error) { return nil, io.ErrUnexpectedEOF }),
+				Retries:   -1,
+				CacheFile: filepath.Join(t.TempDir(), "missing.zip"),
+			},
+			wantErr: fs.ErrNotExist,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Logger = quietLogger
+			if _, err := remotefs.Load(context.Background(), tt.opts); !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package hyperview
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// ErrRenderShed is reported to the render hook when a render is rejected because its render limit is saturated.
+var ErrRenderShed = errors.New("render shed: too many concurrent renders")
+
+// RenderLimit limits the number of concurrent renders of the views matching a pattern, protecting memory from
+// expensive pages during traffic spikes. Renders over the limit wait in a queue for a free slot; renders that cannot
+// be queued or that wait longer than the queue timeout are shed with a 503 Service Unavailable response.
+type RenderLimit struct {
+	// Pattern matches the page names the limit applies to, with the syntax of path.Match (e.g. "views/reports/*").
+	// A trailing "**" matches any suffix, so "views/reports/**" covers nested directories and "admin:**" covers all
+	// views of the admin file system.
+	Pattern string
+	// MaxConcurrent is the maximum number of renders executing at the same time.
+	MaxConcurrent int
+	// MaxQueue is the maximum number of renders waiting for a slot. Zero means unbounded.
+	MaxQueue int
+	// QueueTimeout is how long a render waits for a slot before being shed. Zero means renders are shed immediately
+	// when all slots are taken.
+	QueueTimeout time.Duration
+	// RetryAfter is the value of the Retry-After header of shed responses. Default is one second.
+	RetryAfter time.Duration
+}
+
+// renderLimiter enforces a RenderLimit.
+type renderLimiter struct {
+	RenderLimit
+	slots   chan struct{}
+	waiting atomic.Int64
+}
+
+func newRenderLimiters(limits []RenderLimit) []*renderLimiter {
+	limiters := make([]*renderLimiter, 0, len(limits))
+	for _, limit := range limits {
+		if limit.MaxConcurrent <= 0 {
+			continue
+		}
+		if limit.RetryAfter <= 0 {
+			limit.RetryAfter = time.Second
+		}
+		limiters = append(limiters, &renderLimiter{
+			RenderLimit: limit,
+			slots:       make(chan struct{}, limit.MaxConcurrent),
+		})
+	}
+	return limiters
+}
+
+// matches reports whether the limit applies to the page.
+func (l *renderLimiter) matches(pageName string) bool {
+	return matchPagePattern(l.Pattern, pageName)
+}
+
+// matchPagePattern reports whether the page name matches the pattern, with the syntax of path.Match and a trailing
+// "**" matching any suffix.
+func matchPagePattern(pattern, pageName string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "**"); ok {
+		return strings.HasPrefix(pageName, prefix)
+	}
+	matched, _ := path.Match(pattern, pageName)
+	return matched
+}
+
+// acquire waits for a render slot. It returns false if the render must be shed.
+func (l *renderLimiter) acquire(ctx context.Context) bool {
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	default:
+	}
+
+	if l.QueueTimeout <= 0 {
+		return false
+	}
+	if waiting := l.waiting.Add(1); l.MaxQueue > 0 && waiting > int64(l.MaxQueue) {
+		l.waiting.Add(-1)
+		return false
+	}
+	defer l.waiting.Add(-1)
+
+	timer := time.NewTimer(l.QueueTimeout)
+	defer timer.Stop()
+
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (l *renderLimiter) release() {
+	<-l.slots
+}
+
+// limitRender waits for a slot of the first render limit matching the page, if any. It returns the function releasing
+// the slot, or false if the render was shed, in which case the shed response has been written.
+func (a *TemplateAdapter) limitRender(w http.ResponseWriter, r *http.Request, pageName string) (func(), bool) {
+	for _, limiter := range a.renderLimits {
+		if !limiter.matches(pageName) {
+			continue
+		}
+
+		if !limiter.acquire(r.Context()) {
+			w.Header().Set("Retry-After", strconv.Itoa(int(limiter.RetryAfter.Round(time.Second).Seconds())))
+			http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+			return nil, false
+		}
+		return limiter.release, true
+	}
+
+	return func() {}, true
+}
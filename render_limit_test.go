@@ -0,0 +1,128 @@
+package hyperview_test
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func TestTemplateAdapter_RenderLimits(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 4)
+
+	var mu sync.Mutex
+	var shed []string
+
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{
+			"layouts/base.html":          {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+			"views/reports/yearly.html":  {Data: []byte(`{{define "page:main"}}{{block_}}yearly{{end}}`)},
+			"views/reports/monthly.html": {Data: []byte(`{{define "page:main"}}{{block_}}monthly{{end}}`)},
+			"views/home.html":            {Data: []byte(`{{define "page:main"}}home{{end}}`)},
+		}},
+		Funcs: map[string]any{
+			"block_": func() string {
+				started <- struct{}{}
+				<-release
+				return ""
+			},
+		},
+		RenderLimits: []hyperview.RenderLimit{
+			{Pattern: "views/reports/**", MaxConcurrent: 1, MaxQueue: 1, QueueTimeout: time.Second, RetryAfter: 5 * time.Second},
+		},
+		OnRender: func(r *http.Request, event hyperview.RenderEvent) {
+			if errors.Is(event.Err, hyperview.ErrRenderShed) {
+				mu.Lock()
+				shed = append(shed, event.Template)
+				mu.Unlock()
+			}
+		},
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+
+	render := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		adapter.Render(w, httptest.NewRequest(http.MethodGet, "/", nil), response.NewResponse().Layout("base").Path(path))
+		return w
+	}
+
+	// The first report takes the only slot, and the second one waits in the queue
+	var wg sync.WaitGroup
+	results := make([]*httptest.ResponseRecorder, 2)
+	for i, path := range []string{"reports/yearly", "reports/monthly"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = render(path)
+		}()
+	}
+	<-started
+	time.Sleep(20 * time.Millisecond)
+
+	// The queue is full, so a third report is shed, while views without a limit are unaffected
+	if w := render("reports/yearly"); w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") != "5" {
+		t.Errorf("expected the render to be shed, got %d with Retry-After %q", w.Code, w.Header().Get("Retry-After"))
+	}
+	if w := render("home"); w.Code != http.StatusOK || w.Body.String() != "home" {
+		t.Errorf("unexpected render of an unlimited view: %d %q", w.Code, w.Body.String())
+	}
+
+	close(release)
+	wg.Wait()
+
+	for _, w := range results {
+		if w.Code != http.StatusOK {
+			t.Errorf("expected queued renders to complete, got %d", w.Code)
+		}
+	}
+	if len(shed) != 1 || shed[0] != "views/reports/yearly" {
+		t.Errorf("unexpected shed renders: %v", shed)
+	}
+}
+
+func TestTemplateAdapter_RenderLimitQueueTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan struct{}, 1)
+
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{
+			"layouts/base.html": {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+			"views/slow.html":   {Data: []byte(`{{define "page:main"}}{{wait_}}{{end}}`)},
+		}},
+		Funcs: map[string]any{
+			"wait_": func() string {
+				started <- struct{}{}
+				<-release
+				return ""
+			},
+		},
+		RenderLimits: []hyperview.RenderLimit{{Pattern: "views/slow", MaxConcurrent: 1, QueueTimeout: 10 * time.Millisecond}},
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+
+	go renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("slow"))
+	<-started
+
+	start := time.Now()
+	w := renderTestTemplate(t, adapter, response.NewResponse().Layout("base").Path("slow"))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected the render to be shed after the queue timeout, got %d", w.Code)
+	}
+	if elapsed := time.Since(start); elapsed < 10*time.Millisecond {
+		t.Errorf("expected the render to wait for the queue timeout, waited %s", elapsed)
+	}
+}
@@ -0,0 +1,92 @@
+package hyperview
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/hypergopher/hyperview/response"
+)
+
+// RenderTo renders the response with renderer, such as a HyperView or an adapter, and writes the body to w. It is
+// meant for integrations whose render interfaces write to an io.Writer rather than an http.ResponseWriter, such as
+// router frameworks. The headers set by the render, such as HTMX triggers, are copied to header before the body is
+// written, unless header is nil. Renders failing with a server error return an error with the body of the error
+// response instead of writing it.
+//
+// Conditional request headers and Accept-Encoding are left out, as the caller rather than the renderer writes the
+// response.
+func RenderTo(w io.Writer, header http.Header, r *http.Request, renderer response.Renderer, resp *response.Response) error {
+	r = r.Clone(r.Context())
+	r.Header.Del("If-None-Match")
+	r.Header.Del("If-Modified-Since")
+	r.Header.Del("Accept-Encoding")
+
+	captured := &capturedResponse{header: make(http.Header), status: http.StatusOK}
+	renderer.Render(captured, r, resp)
+
+	if captured.status >= http.StatusInternalServerError {
+		return fmt.Errorf("error rendering %s: %s", resp.TemplatePath(), strings.TrimSpace(captured.body.String()))
+	}
+
+	if header != nil {
+		for key, values := range captured.header {
+			if key != "Content-Type" && key != "Content-Length" && key != "Etag" {
+				header[key] = values
+			}
+		}
+	}
+	_, err := w.Write(captured.body.Bytes())
+	return err
+}
+
+// RenderString renders the view at path with data and returns its body, for the renders outside of the HTTP layer,
+// such as background jobs, email pipelines and tests. Like the integrations' render functions, data can be a map of
+// view data or a *response.Response to set the layout, title or data (see response.ForView). The view is rendered for
+// a GET request to /, without request context such as a locale, and renders failing or answering with an error page
+// or a redirect return an error.
+func (s *HyperView) RenderString(path string, data any) (string, error) {
+	body, err := renderBytes(s, path, data)
+	return string(body), err
+}
+
+// RenderBytes is like RenderString, but returns the body as bytes.
+func (s *HyperView) RenderBytes(path string, data any) ([]byte, error) {
+	return renderBytes(s, path, data)
+}
+
+// RenderString renders the view at path with data and returns its body, like HyperView.RenderString, for the renders
+// outside of the HTTP layer. The view is rendered with its declared layout, if any, as the adapter has no base layout.
+func (a *TemplateAdapter) RenderString(path string, data any) (string, error) {
+	body, err := renderBytes(a, path, data)
+	return string(body), err
+}
+
+// RenderBytes is like RenderString, but returns the body as bytes.
+func (a *TemplateAdapter) RenderBytes(path string, data any) ([]byte, error) {
+	return renderBytes(a, path, data)
+}
+
+// renderBytes renders the view at path with data with renderer for a synthetic request, and returns the body of the
+// render, or an error if the render did not succeed.
+func renderBytes(renderer response.Renderer, path string, data any) ([]byte, error) {
+	r, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "/", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := response.ForView(path, data)
+	captured := &capturedResponse{header: make(http.Header), status: http.StatusOK}
+	renderer.Render(captured, r, resp)
+
+	switch {
+	case captured.status >= http.StatusInternalServerError:
+		return nil, fmt.Errorf("error rendering %s: %s", resp.TemplatePath(), strings.TrimSpace(captured.body.String()))
+	case !captured.ok():
+		return nil, fmt.Errorf("error rendering %s: status %d %s", resp.TemplatePath(), captured.status,
+			http.StatusText(captured.status))
+	}
+	return captured.body.Bytes(), nil
+}
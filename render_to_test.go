@@ -0,0 +1,95 @@
+package hyperview_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func TestRenderTo(t *testing.T) {
+	adapter := newTestTemplateAdapter(t, fstest.MapFS{
+		"layouts/base.html": {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+		"views/posts.html":  {Data: []byte(`{{define "page:main"}}{{.Count}} {{.Data}}{{end}}`)},
+	}, hyperview.TemplateViewAdapterOptions{})
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("If-None-Match", "*")
+
+	type data map[string]any
+	tests := []struct {
+		name     string
+		resp     *response.Response
+		wantBody string
+		wantErr  bool
+	}{
+		{"named map", response.ForView("posts", data{"Count": 2}).Layout("base").Header("HX-Trigger", "loaded"), "2 ", false},
+		{"other value", response.ForView("posts", 42).Layout("base"), " 42", false},
+		{"response", response.ForView("posts", response.NewResponse().Layout("base").AddDataItem("Count", 3)), "3 ", false},
+		{"missing view", response.ForView("missing", nil).Layout("base"), "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			header := make(http.Header)
+			err := hyperview.RenderTo(&buf, header, r, adapter, tt.resp)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RenderTo() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := buf.String(); got != tt.wantBody {
+				t.Errorf("unexpected body: got %q, want %q", got, tt.wantBody)
+			}
+			if got, want := header.Get("HX-Trigger"), tt.resp.Headers()["HX-Trigger"]; got != want {
+				t.Errorf("unexpected HX-Trigger header: got %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestRenderString(t *testing.T) {
+	adapter := newTestTemplateAdapter(t, fstest.MapFS{
+		"layouts/base.html":          {Data: []byte(`{{define "layout:base"}}<main>{{template "page:main" .}}</main>{{end}}`)},
+		"layouts/email.html":         {Data: []byte(`{{define "layout:email"}}<table>{{template "page:main" .}}</table>{{end}}`)},
+		"views/emails/welcome.html":  {Data: []byte(`{{define "layout"}}email{{end}}{{define "page:main"}}Hi {{.Name}}{{end}}`)},
+		"views/reports/monthly.html": {Data: []byte(`{{define "page:main"}}{{.Total}}{{end}}`)},
+	}, hyperview.TemplateViewAdapterOptions{})
+	hv, err := hyperview.NewHyperView(hyperview.WithViewAdapter("html", adapter))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		data    any
+		want    string
+		wantErr bool
+	}{
+		{name: "declared layout", path: "emails/welcome", data: map[string]any{"Name": "Ann"}, want: "<table>Hi Ann</table>"},
+		{name: "base layout", path: "reports/monthly", data: map[string]any{"Total": 3}, want: "<main>3</main>"},
+		{name: "response", path: "reports/monthly", data: response.NewResponse().Layout("email").AddDataItem("Total", 4), want: "<table>4</table>"},
+		{name: "missing view", path: "missing", wantErr: true},
+		{name: "redirect", data: response.NewResponse().Redirect("/login", http.StatusSeeOther), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := hv.RenderString(tt.path, tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RenderString() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("unexpected body: got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// The adapter renders without the base layout of the HyperView
+	body, err := adapter.RenderBytes("emails/welcome", map[string]any{"Name": "Bob"})
+	if err != nil || string(body) != "<table>Hi Bob</table>" {
+		t.Errorf("unexpected RenderBytes() result: %q, %v", body, err)
+	}
+}
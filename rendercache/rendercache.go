@@ -0,0 +1,137 @@
+// Package rendercache provides the stores backing the render cache of the template adapter, which caches the output
+// of expensive pages and fragments by key.
+//
+// The in-memory LRU store is suitable for a single process. Stores shared by several processes, such as Redis, are
+// implemented by satisfying the Store interface.
+package rendercache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// Store stores rendered output by key.
+type Store interface {
+	// Get returns the value stored for key, and false if there is no value or it has expired.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores the value for key, expiring after ttl. A ttl of zero or less means the value does not expire.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes the values stored for the keys, invalidating them.
+	Delete(ctx context.Context, keys ...string) error
+	// Clear removes all values.
+	Clear(ctx context.Context) error
+}
+
+// DefaultMaxEntries is the maximum number of entries of an LRU store created with a max of zero or less.
+const DefaultMaxEntries = 1000
+
+// LRU is an in-memory Store evicting the least recently used entries once it holds its maximum number of entries.
+// It is safe for concurrent use.
+type LRU struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	order      *list.List // front is the most recently used
+}
+
+type lruEntry struct {
+	key     string
+	value   []byte
+	expires time.Time // zero if the entry does not expire
+}
+
+// NewLRU creates an in-memory store holding at most maxEntries entries. Default is DefaultMaxEntries.
+func NewLRU(maxEntries int) *LRU {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxEntries
+	}
+
+	return &LRU{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// Get returns the value stored for key, and false if there is no value or it has expired.
+func (c *LRU) Get(_ context.Context, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	entry := elem.Value.(*lruEntry)
+	if !entry.expires.IsZero() && !time.Now().Before(entry.expires) {
+		c.remove(elem)
+		return nil, false, nil
+	}
+
+	c.order.MoveToFront(elem)
+	return entry.value, true, nil
+}
+
+// Set stores the value for key, expiring after ttl, and evicts the least recently used entry if the store is full.
+func (c *LRU) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expires time.Time
+	if ttl > 0 {
+		expires = time.Now().Add(ttl)
+	}
+
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*lruEntry)
+		entry.value, entry.expires = value, expires
+		c.order.MoveToFront(elem)
+		return nil
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, value: value, expires: expires})
+	for c.order.Len() > c.maxEntries {
+		c.remove(c.order.Back())
+	}
+
+	return nil
+}
+
+// Delete removes the values stored for the keys.
+func (c *LRU) Delete(_ context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		if elem, ok := c.entries[key]; ok {
+			c.remove(elem)
+		}
+	}
+	return nil
+}
+
+// Clear removes all values.
+func (c *LRU) Clear(_ context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[string]*list.Element)
+	c.order.Init()
+	return nil
+}
+
+// Len returns the number of entries in the store, including expired entries not yet evicted.
+func (c *LRU) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+func (c *LRU) remove(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.entries, elem.Value.(*lruEntry).key)
+}
@@ -0,0 +1,70 @@
+package rendercache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hypergopher/hyperview/rendercache"
+)
+
+func TestLRU(t *testing.T) {
+	ctx := context.Background()
+	store := rendercache.NewLRU(2)
+
+	_ = store.Set(ctx, "a", []byte("A"), 0)
+	_ = store.Set(ctx, "b", []byte("B"), 0)
+
+	// Reading "a" makes "b" the least recently used entry, which is evicted by "c"
+	if value, ok, _ := store.Get(ctx, "a"); !ok || string(value) != "A" {
+		t.Errorf("expected a to be cached, got %q, %v", value, ok)
+	}
+	_ = store.Set(ctx, "c", []byte("C"), 0)
+
+	tests := []struct {
+		key  string
+		want string
+		ok   bool
+	}{
+		{"a", "A", true},
+		{"b", "", false},
+		{"c", "C", true},
+	}
+	for _, tt := range tests {
+		value, ok, err := store.Get(ctx, tt.key)
+		if err != nil || ok != tt.ok || string(value) != tt.want {
+			t.Errorf("Get(%q) = %q, %v, %v, want %q, %v", tt.key, value, ok, err, tt.want, tt.ok)
+		}
+	}
+
+	if err := store.Delete(ctx, "a"); err != nil {
+		t.Fatalf("error deleting: %v", err)
+	}
+	if _, ok, _ := store.Get(ctx, "a"); ok {
+		t.Error("expected a to be invalidated")
+	}
+
+	if err := store.Clear(ctx); err != nil || store.Len() != 0 {
+		t.Errorf("expected an empty store after Clear, got %d entries, %v", store.Len(), err)
+	}
+}
+
+func TestLRU_TTL(t *testing.T) {
+	ctx := context.Background()
+	store := rendercache.NewLRU(0)
+
+	_ = store.Set(ctx, "short", []byte("x"), 10*time.Millisecond)
+	_ = store.Set(ctx, "forever", []byte("y"), 0)
+
+	time.Sleep(20 * time.Millisecond)
+
+	if _, ok, _ := store.Get(ctx, "short"); ok {
+		t.Error("expected the entry to expire")
+	}
+	if _, ok, _ := store.Get(ctx, "forever"); !ok {
+		t.Error("expected the entry without ttl to be kept")
+	}
+	if store.Len() != 1 {
+		t.Errorf("expected expired entries to be evicted on read, got %d entries", store.Len())
+	}
+}
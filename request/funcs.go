@@ -0,0 +1,67 @@
+package request
+
+import (
+	"html/template"
+	"net/http"
+	"strings"
+)
+
+// Funcs returns the template functions describing the request, for navigation and links:
+//
+//   - currentPath: returns the path of the request, e.g. /admin/users
+//   - queryParam: returns the first value of a query parameter, e.g. {{queryParam "page"}}
+//   - isActive: reports whether the request is for the path or below it (see IsActive), e.g.
+//     <a href="/admin" {{if isActive "/admin"}}aria-current="page"{{end}}>, or only for the path with "exact"
+//   - fullURL: returns the absolute URL of the request (see FullURL), e.g. for canonical links
+//
+// The functions of a nil request return zero values, so Funcs(nil) declares them in the template adapter's
+// RequestFuncs option. The adapter's RequestHelpers option declares them and binds them to each request.
+func Funcs(r *http.Request) template.FuncMap {
+	return template.FuncMap{
+		"currentPath": func() string {
+			if r == nil {
+				return ""
+			}
+			return r.URL.Path
+		},
+		"queryParam": func(name string) string {
+			if r == nil {
+				return ""
+			}
+			return r.URL.Query().Get(name)
+		},
+		"isActive": func(path string, options ...string) bool {
+			if r == nil {
+				return false
+			}
+			if len(options) > 0 && strings.EqualFold(options[0], "exact") {
+				return r.URL.Path == path
+			}
+			return IsActive(r, path)
+		},
+		"fullURL": func() string {
+			if r == nil {
+				return ""
+			}
+			return FullURL(r)
+		},
+	}
+}
+
+// IsActive reports whether the request is for the path or a path below it, segment by segment, so /admin is active
+// for /admin and /admin/users but not for /administrators. The root path / is only active for the home page, so
+// navigation links to it are not active on every page.
+func IsActive(r *http.Request, path string) bool {
+	requestPath := r.URL.Path
+	path = strings.TrimSuffix(path, "/")
+	if path == "" {
+		return requestPath == "/" || requestPath == ""
+	}
+	return requestPath == path || strings.HasPrefix(requestPath, path+"/")
+}
+
+// FullURL returns the absolute URL of the request, with its query string, built with BaseURL so it holds the scheme
+// and host the client used behind a proxy.
+func FullURL(r *http.Request) string {
+	return BaseURL(r) + r.URL.RequestURI()
+}
@@ -0,0 +1,70 @@
+package request_test
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hypergopher/hyperview/request"
+)
+
+func TestIsActive(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		path   string
+		want   bool
+	}{
+		{name: "same path", target: "/admin", path: "/admin", want: true},
+		{name: "below the path", target: "/admin/users", path: "/admin", want: true},
+		{name: "trailing slash", target: "/admin/users", path: "/admin/", want: true},
+		{name: "other segment", target: "/administrators", path: "/admin", want: false},
+		{name: "parent path", target: "/admin", path: "/admin/users", want: false},
+		{name: "root on the home page", target: "/", path: "/", want: true},
+		{name: "root on another page", target: "/admin", path: "/", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertBool(t, tt.want, request.IsActive(httptest.NewRequest(http.MethodGet, tt.target, nil), tt.path))
+		})
+	}
+}
+
+func TestFullURL(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/search?q=a+b&page=2", nil)
+	assertEqual(t, "http://example.com/search?q=a+b&page=2", request.FullURL(req))
+
+	req.Header.Set("X-Forwarded-Proto", "https")
+	req.Header.Set("X-Forwarded-Host", "www.example.com")
+	req.Header.Set("X-Forwarded-Port", "443")
+	assertEqual(t, "https://www.example.com/search?q=a+b&page=2", request.FullURL(req))
+}
+
+func TestFuncs(t *testing.T) {
+	const src = `{{currentPath}}|{{queryParam "page"}}|{{isActive "/admin"}}|{{isActive "/admin" "exact"}}|{{fullURL}}`
+
+	tests := []struct {
+		name string
+		req  *http.Request
+		want string
+	}{
+		{
+			name: "request",
+			req:  httptest.NewRequest(http.MethodGet, "http://example.com/admin/users?page=3", nil),
+			want: "/admin/users|3|true|false|http://example.com/admin/users?page=3",
+		},
+		{name: "nil request", want: "||false|false|"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl := template.Must(template.New("").Funcs(request.Funcs(tt.req)).Parse(src))
+			var buf bytes.Buffer
+			if err := tmpl.Execute(&buf, nil); err != nil {
+				t.Fatalf("error executing template: %v", err)
+			}
+			assertEqual(t, tt.want, buf.String())
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package request
+
+import (
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+)
+
+// URLOptions are the options of a URLBuilder.
+type URLOptions struct {
+	// BaseURL is the scheme and host of the absolute URLs, e.g. https://example.com, used instead of those of the
+	// requests, and for templates rendered without a request, such as emails. Its path, if any, prefixes the paths of
+	// the URLs, e.g. https://example.com/app for an application mounted under /app by its proxy.
+	BaseURL string
+	// TrustedProxies are the IP addresses and CIDR ranges of the proxies, such as load balancers, whose
+	// X-Forwarded-Proto and X-Forwarded-Host headers are trusted. The headers of other clients are ignored, as anyone
+	// can send them to forge the links of a page. "*" trusts every client, for servers only reachable through proxies.
+	TrustedProxies []string
+	// CanonicalQuery are the query parameters kept by canonical URLs, e.g. page for paginated lists. The others, such
+	// as tracking parameters, are dropped.
+	CanonicalQuery []string
+}
+
+// URLBuilder builds the absolute URLs of the pages of a site from the requests, for links that leave the page, such as
+// canonical and Open Graph URLs, and for emails. It is safe for concurrent use.
+type URLBuilder struct {
+	base           *url.URL
+	trustAll       bool
+	trusted        []netip.Prefix
+	canonicalQuery []string
+}
+
+// NewURLBuilder creates a URLBuilder. It fails if BaseURL is not an absolute URL or if a trusted proxy is neither an
+// IP address nor a CIDR range.
+func NewURLBuilder(opts URLOptions) (*URLBuilder, error) {
+	b := &URLBuilder{canonicalQuery: opts.CanonicalQuery}
+
+	if opts.BaseURL != "" {
+		base, err := url.Parse(opts.BaseURL)
+		if err != nil || base.Scheme == "" || base.Host == "" {
+			return nil, fmt.Errorf("request: base URL %q is not an absolute URL", opts.BaseURL)
+		}
+		base.Path = strings.TrimSuffix(base.Path, "/")
+		base.RawQuery, base.Fragment = "", ""
+		b.base = base
+	}
+
+	for _, proxy := range opts.TrustedProxies {
+		if proxy == "*" {
+			b.trustAll = true
+			continue
+		}
+		if !strings.Contains(proxy, "/") {
+			addr, err := netip.ParseAddr(proxy)
+			if err != nil {
+				return nil, fmt.Errorf("request: invalid trusted proxy %q: %w", proxy, err)
+			}
+			b.trusted = append(b.trusted, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("request: invalid trusted proxy %q: %w", proxy, err)
+		}
+		b.trusted = append(b.trusted, prefix.Masked())
+	}
+
+	return b, nil
+}
+
+// Origin returns the scheme and host of the absolute URLs for the request, e.g. https://example.com: those of
+// BaseURL, else those of the X-Forwarded-Proto and X-Forwarded-Host headers of trusted proxies, else those of the
+// request. It returns BaseURL, or an empty string, for a nil request.
+func (b *URLBuilder) Origin(r *http.Request) string {
+	base := b.baseURL(r)
+	if base == nil {
+		return ""
+	}
+	return base.Scheme + "://" + base.Host
+}
+
+// AbsURL returns the absolute URL of the reference, such as /posts/1, resolved against the URL of the request, with
+// the scheme and host of Origin. Absolute URLs are returned as is. Without a request nor BaseURL, the reference is
+// returned as is.
+func (b *URLBuilder) AbsURL(r *http.Request, ref string) string {
+	u, err := url.Parse(ref)
+	if err != nil || u.IsAbs() {
+		return ref
+	}
+
+	base := b.baseURL(r)
+	if base == nil {
+		return ref
+	}
+	if u.Host == "" && strings.HasPrefix(u.Path, "/") {
+		u.Path = base.Path + u.Path
+		if u.RawPath != "" {
+			u.RawPath = base.EscapedPath() + u.RawPath
+		}
+	}
+	if r != nil {
+		base.Path += r.URL.Path
+	}
+	return base.ResolveReference(u).String()
+}
+
+// CanonicalURL returns the canonical URL of the request: its absolute URL with the query parameters of CanonicalQuery
+// only, sorted, and without fragment. It returns BaseURL, or an empty string, for a nil request.
+func (b *URLBuilder) CanonicalURL(r *http.Request) string {
+	if r == nil {
+		return b.AbsURL(nil, "/")
+	}
+
+	query := url.Values{}
+	for _, name := range b.canonicalQuery {
+		if values, ok := r.URL.Query()[name]; ok {
+			query[name] = values
+		}
+	}
+
+	u := b.AbsURL(r, (&url.URL{Path: r.URL.Path, RawPath: r.URL.RawPath}).EscapedPath())
+	if encoded := query.Encode(); encoded != "" {
+		u += "?" + encoded
+	}
+	return u
+}
+
+// Funcs returns the template functions building the absolute URLs for the request:
+//
+//   - absURL: returns the absolute URL of a reference (see AbsURL), e.g. <meta property="og:image" content="{{absURL
+//     "/img/cover.png"}}">
+//   - canonicalURL: returns the canonical URL of the request (see CanonicalURL), e.g. <link rel="canonical"
+//     href="{{canonicalURL}}">
+//
+// Funcs(nil) builds the URLs from BaseURL, e.g. for emails, and declares them in the template adapter's RequestFuncs
+// option. The adapter's URLBuilder option declares them and binds them to each request.
+func (b *URLBuilder) Funcs(r *http.Request) template.FuncMap {
+	return template.FuncMap{
+		"absURL": func(ref string) string {
+			return b.AbsURL(r, ref)
+		},
+		"canonicalURL": func() string {
+			return b.CanonicalURL(r)
+		},
+	}
+}
+
+// baseURL returns a copy of the URL the URLs for the request are resolved against, without the path of the request,
+// or nil if there is neither a request nor a BaseURL.
+func (b *URLBuilder) baseURL(r *http.Request) *url.URL {
+	if b.base != nil {
+		base := *b.base
+		return &base
+	}
+	if r == nil {
+		return nil
+	}
+
+	scheme, host := "http", r.Host
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if b.trustedProxy(r) {
+		if proto := forwarded(r, "X-Forwarded-Proto"); proto == "http" || proto == "https" {
+			scheme = proto
+		}
+		if forwardedHost := forwarded(r, "X-Forwarded-Host"); validHost(forwardedHost) {
+			host = forwardedHost
+		}
+	}
+	return &url.URL{Scheme: scheme, Host: host}
+}
+
+// trustedProxy reports whether the request comes from a trusted proxy.
+func (b *URLBuilder) trustedProxy(r *http.Request) bool {
+	if b.trustAll {
+		return true
+	}
+	if len(b.trusted) == 0 {
+		return false
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range b.trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwarded returns the value of the forwarding header appended by the trusted proxy the request comes from, the last
+// of the comma-separated lists of its lines, lowercased. The values before it were received by the proxy, possibly
+// from the client.
+func forwarded(r *http.Request, header string) string {
+	values := r.Header.Values(header)
+	if len(values) == 0 {
+		return ""
+	}
+	list := values[len(values)-1]
+	value := list[strings.LastIndex(list, ",")+1:]
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
+// validHost reports whether the forwarded host is a host name or an IP address, with an optional port, and nothing
+// that would change the meaning of the URLs it is put in, such as a path, user info, spaces or quotes.
+func validHost(host string) bool {
+	if host == "" {
+		return false
+	}
+	for _, c := range host {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.ContainsRune(".-_:[]", c)) {
+			return false
+		}
+	}
+	return true
+}
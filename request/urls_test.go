@@ -0,0 +1,115 @@
+package request_test
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hypergopher/hyperview/request"
+)
+
+func TestNewURLBuilder_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		opts request.URLOptions
+	}{
+		{name: "relative base URL", opts: request.URLOptions{BaseURL: "/app"}},
+		{name: "invalid proxy", opts: request.URLOptions{TrustedProxies: []string{"proxy.local"}}},
+		{name: "invalid range", opts: request.URLOptions{TrustedProxies: []string{"10.0.0.0/99"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := request.NewURLBuilder(tt.opts); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestURLBuilder_AbsURL(t *testing.T) {
+	newBuilder := func(opts request.URLOptions) *request.URLBuilder {
+		b, err := request.NewURLBuilder(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+	proxied := newBuilder(request.URLOptions{TrustedProxies: []string{"10.0.0.0/8", "::1"}})
+	based := newBuilder(request.URLOptions{BaseURL: "https://example.com/app/", TrustedProxies: []string{"*"}})
+
+	tests := []struct {
+		name       string
+		builder    *request.URLBuilder
+		remoteAddr string
+		ref        string
+		want       string
+	}{
+		{name: "untrusted client", builder: proxied, remoteAddr: "203.0.113.7:4000", ref: "/img/a.png", want: "http://internal:8080/img/a.png"},
+		{name: "trusted proxy", builder: proxied, remoteAddr: "10.1.2.3:4000", ref: "/img/a.png", want: "https://www.example.com/img/a.png"},
+		{name: "trusted IPv6 proxy", builder: proxied, remoteAddr: "[::1]:4000", ref: "/img/a.png", want: "https://www.example.com/img/a.png"},
+		{name: "relative reference", builder: proxied, remoteAddr: "10.1.2.3:4000", ref: "comments?page=2#top", want: "https://www.example.com/posts/comments?page=2#top"},
+		{name: "absolute URL", builder: proxied, remoteAddr: "10.1.2.3:4000", ref: "https://cdn.example.com/a.js", want: "https://cdn.example.com/a.js"},
+		{name: "scheme-relative URL", builder: proxied, remoteAddr: "10.1.2.3:4000", ref: "//cdn.example.com/a.js", want: "https://cdn.example.com/a.js"},
+		{name: "base URL", builder: based, remoteAddr: "10.1.2.3:4000", ref: "/img/a.png", want: "https://example.com/app/img/a.png"},
+		{name: "base URL with relative reference", builder: based, remoteAddr: "10.1.2.3:4000", ref: "comments", want: "https://example.com/app/posts/comments"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "http://internal:8080/posts/1", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("X-Forwarded-Proto", "http, https")
+			req.Header.Set("X-Forwarded-Host", "www.example.com")
+			assertEqual(t, tt.want, tt.builder.AbsURL(req, tt.ref))
+		})
+	}
+
+	// Only the values appended by the trusted proxy are used, not those the client sent it
+	req := httptest.NewRequest(http.MethodGet, "http://internal:8080/posts/1", nil)
+	req.RemoteAddr = "10.1.2.3:4000"
+	req.Header.Set("X-Forwarded-Proto", "http, https")
+	req.Header.Add("X-Forwarded-Host", "evil.com")
+	req.Header.Add("X-Forwarded-Host", "evil.org, www.example.com")
+	assertEqual(t, "https://www.example.com/reset", proxied.AbsURL(req, "/reset"))
+
+	// Forwarded hosts that are not host names are ignored
+	for _, host := range []string{"evil.com/x", "user@evil.com", "evil.com x", `evil.com"`, "evil.com?a"} {
+		req.Header.Set("X-Forwarded-Host", host)
+		assertEqual(t, "https://internal:8080/reset", proxied.AbsURL(req, "/reset"))
+	}
+
+	// Without a request, URLs are built from the base URL, if any
+	assertEqual(t, "https://example.com/app/welcome", based.AbsURL(nil, "/welcome"))
+	assertEqual(t, "/welcome", proxied.AbsURL(nil, "/welcome"))
+	assertEqual(t, "https://example.com", based.Origin(nil))
+}
+
+func TestURLBuilder_CanonicalURL(t *testing.T) {
+	b, err := request.NewURLBuilder(request.URLOptions{CanonicalQuery: []string{"page", "sort"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/blog/caf%C3%A9%2Fs?utm_source=x&sort=new&page=2", nil)
+	assertEqual(t, "http://example.com/blog/caf%C3%A9%2Fs?page=2&sort=new", b.CanonicalURL(req))
+
+	req = httptest.NewRequest(http.MethodGet, "https://example.com/blog?utm_source=x", nil)
+	assertEqual(t, "https://example.com/blog", b.CanonicalURL(req))
+	assertEqual(t, "/", b.CanonicalURL(nil))
+}
+
+func TestURLBuilder_Funcs(t *testing.T) {
+	b, err := request.NewURLBuilder(request.URLOptions{BaseURL: "https://example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := template.Must(template.New("email").Funcs(b.Funcs(nil)).
+		Parse(`<a href="{{absURL "/confirm?token=a b"}}">Confirm</a> {{canonicalURL}}`))
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	assertEqual(t, `<a href="https://example.com/confirm?token=a%20b">Confirm</a> https://example.com/`, buf.String())
+}
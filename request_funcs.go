@@ -0,0 +1,37 @@
+package hyperview
+
+import (
+	"context"
+	"html/template"
+)
+
+type requestFuncsKey struct{}
+
+// ContextWithFuncs returns a copy of ctx carrying request-scoped template functions, so middleware can provide
+// functions such as currentUser or csrfToken for every render of the request. Functions already carried by ctx are
+// kept, unless they have the same name.
+//
+// The functions must be declared in the adapter's RequestFuncs option, as html/template resolves function names when
+// parsing templates.
+func ContextWithFuncs(ctx context.Context, funcs template.FuncMap) context.Context {
+	return context.WithValue(ctx, requestFuncsKey{}, MergeFuncs(FuncsFromContext(ctx), funcs))
+}
+
+// FuncsFromContext returns the request-scoped template functions carried by ctx, if any.
+func FuncsFromContext(ctx context.Context) template.FuncMap {
+	funcs, _ := ctx.Value(requestFuncsKey{}).(template.FuncMap)
+	return funcs
+}
+
+// MergeFuncs merges the function maps into a new map, with later maps taking precedence. This is useful to declare
+// the request-scoped functions of several packages in the RequestFuncs option, e.g.
+// MergeFuncs(csrf.Funcs("", ""), csp.Funcs("")).
+func MergeFuncs(maps ...template.FuncMap) template.FuncMap {
+	merged := make(template.FuncMap)
+	for _, funcs := range maps {
+		for name, fn := range funcs {
+			merged[name] = fn
+		}
+	}
+	return merged
+}
@@ -0,0 +1,70 @@
+package response
+
+import (
+	"encoding/json"
+	"html/template"
+)
+
+// Breadcrumb is an entry of the breadcrumb trail of a page.
+type Breadcrumb struct {
+	// Name is the label of the entry.
+	Name string
+	// URL is the URL of the entry. It is usually empty for the current page, the last entry.
+	URL string
+}
+
+// Breadcrumbs is the breadcrumb trail of a page, from the home page to the current page.
+type Breadcrumbs []Breadcrumb
+
+// IsLast reports whether the entry at index i is the last entry, the current page.
+func (b Breadcrumbs) IsLast(i int) bool {
+	return i == len(b)-1
+}
+
+// JSONLD renders the trail as a BreadcrumbList JSON-LD script, for search engines. Relative URLs are resolved against
+// baseURL, as structured data requires absolute URLs, unless baseURL is empty.
+func (b Breadcrumbs) JSONLD(baseURL string) (template.HTML, error) {
+	if len(b) == 0 {
+		return "", nil
+	}
+
+	type listItem struct {
+		Type     string `json:"@type"`
+		Position int    `json:"position"`
+		Name     string `json:"name"`
+		Item     string `json:"item,omitempty"`
+	}
+	items := make([]listItem, len(b))
+	for i, crumb := range b {
+		items[i] = listItem{Type: "ListItem", Position: i + 1, Name: crumb.Name, Item: absoluteURL(baseURL, crumb.URL)}
+	}
+
+	// encoding/json escapes <, > and &, so the data cannot close the script element
+	src, err := json.Marshal(map[string]any{
+		"@context":        "https://schema.org",
+		"@type":           "BreadcrumbList",
+		"itemListElement": items,
+	})
+	if err != nil {
+		return "", err
+	}
+	return template.HTML(`<script type="application/ld+json">` + string(src) + `</script>`), nil
+}
+
+// BreadcrumbsTemplate is the source of the built-in @breadcrumbs partial, rendering the breadcrumbs of the page in an
+// ordered list, with the current page marked with aria-current, followed by their JSON-LD structured data. It is
+// called with the page's view data:
+//
+//	{{template "@breadcrumbs" .View}}
+const BreadcrumbsTemplate = `{{define "@breadcrumbs"}}
+{{- with .Breadcrumbs -}}
+<nav class="breadcrumbs" aria-label="Breadcrumb">
+<ol>
+{{- range $i, $crumb := .}}
+<li>{{if $.Breadcrumbs.IsLast $i}}<span aria-current="page">{{$crumb.Name}}</span>{{else if $crumb.URL}}<a href="{{$crumb.URL}}">{{$crumb.Name}}</a>{{else}}{{$crumb.Name}}{{end}}</li>
+{{- end}}
+</ol>
+</nav>
+{{.JSONLD $.BaseURL}}
+{{- end -}}
+{{end}}`
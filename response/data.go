@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/url"
+	"sync/atomic"
 	"time"
 
 	"github.com/hypergopher/hyperview/constants"
@@ -14,6 +16,10 @@ import (
 // Data is the struct that all view models must implement. It provides common data for all templates
 // and represents the data that is passed to the template.
 //
+// Layouts and built-in partials rely on its well-known slots, available to templates as .View: Title, Meta,
+// Breadcrumbs, Flashes, CSRFToken, CurrentUser, Session, Error and Errors. The request-derived slots are set by
+// middleware, see SlotsMiddleware, while the other keys of the data map are free for the page.
+//
 // This is a short-lived object that is used to work with data passed to the template. It is not thread-safe.
 //
 // Data should not be used directly. Instead, use the NewData function to create an instance
@@ -27,10 +33,16 @@ import (
 //goland:noinspection GoNameStartsWithPackageName
 type Data struct {
 	title       string
+	meta        Meta
+	breadcrumbs Breadcrumbs
 	request     *http.Request
 	pageData    map[string]any
-	csrfToken   string
 	environment string
+	variants    map[string]string
+	flashes     []Flash
+	currentUser any
+	hints       []Hint
+	shown       atomic.Bool // whether Flashes returned flash messages
 }
 
 // NewData creates a new Data instance.
@@ -48,11 +60,36 @@ func (v *Data) SetTitle(title string) {
 	v.title = title
 }
 
+// SetMeta sets the meta tags of the page.
+func (v *Data) SetMeta(meta Meta) {
+	v.meta = meta
+}
+
+// SetBreadcrumbs sets the breadcrumb trail of the page.
+func (v *Data) SetBreadcrumbs(breadcrumbs Breadcrumbs) {
+	v.breadcrumbs = breadcrumbs
+}
+
 // SetRequest sets the request for the Data instance.
 func (v *Data) SetRequest(r *http.Request) {
 	v.request = r
 }
 
+// SetFlashes sets the flash messages added to the page by the handler, shown after those of the request.
+func (v *Data) SetFlashes(flashes []Flash) {
+	v.flashes = flashes
+}
+
+// SetCurrentUser sets the signed-in user, overriding the user of the request.
+func (v *Data) SetCurrentUser(user any) {
+	v.currentUser = user
+}
+
+// SetVariants sets the experiment variants the page is rendered with.
+func (v *Data) SetVariants(variants map[string]string) {
+	v.variants = variants
+}
+
 func initData(data map[string]any) map[string]any {
 	if data == nil {
 		data = map[string]any{}
@@ -96,6 +133,12 @@ func (v *Data) AddErrors(msg string, fieldErrors map[string]string) {
 	v.pageData["Errors"] = fieldErrors
 }
 
+// AddOldInput adds the values of a submitted form to the view data model, as Old, so the form helpers can fill the
+// fields back in when the form is rendered again with errors.
+func (v *Data) AddOldInput(values url.Values) {
+	v.pageData["Old"] = values
+}
+
 // Get returns the value of the specified key from the view data model.
 func (v *Data) Get(key string) any {
 	val, ok := v.pageData[key]
@@ -121,6 +164,94 @@ func (v *Data) Title() string {
 	return v.title
 }
 
+// Meta returns the meta tags of the page, without the defaults of the adapter.
+func (v *Data) Meta() Meta {
+	return v.meta
+}
+
+// Breadcrumbs returns the breadcrumb trail of the page.
+func (v *Data) Breadcrumbs() Breadcrumbs {
+	return v.breadcrumbs
+}
+
+// Variant returns the variant of the named experiment the page is rendered with, or an empty string if the page is
+// not part of the experiment.
+func (v *Data) Variant(experiment string) string {
+	return v.variants[experiment]
+}
+
+// Variants returns all experiment variants the page is rendered with, keyed by experiment name.
+func (v *Data) Variants() map[string]string {
+	return v.variants
+}
+
+// ------ Slots --------
+
+// slots returns the slots of the request.
+func (v *Data) slots() Slots {
+	if v.request == nil {
+		return Slots{}
+	}
+	return SlotsFromContext(v.request.Context())
+}
+
+// Flashes returns the flash messages of the request, those of its session included, followed by those added by the
+// handler.
+func (v *Data) Flashes() []Flash {
+	slots := v.slots()
+	flashes := slots.Flashes
+	if session, ok := slots.Session.(FlashSession); ok {
+		if popped := session.RequestFlashes(); len(popped) > 0 {
+			flashes = append(append([]Flash(nil), flashes...), popped...)
+		}
+	}
+	if len(v.flashes) > 0 {
+		flashes = append(append([]Flash(nil), flashes...), v.flashes...)
+	}
+	if len(flashes) > 0 {
+		v.shown.Store(true)
+	}
+	return flashes
+}
+
+// FlashesShown reports whether Flashes returned flash messages, e.g. to the @flashes partial, so the page rendered
+// with the view data shows messages specific to the request.
+func (v *Data) FlashesShown() bool {
+	return v.shown.Load()
+}
+
+// HasFlashes reports whether there are flash messages to show.
+func (v *Data) HasFlashes() bool {
+	return len(v.Flashes()) > 0
+}
+
+// CurrentUser returns the signed-in user, set by the handler or the request, or nil.
+func (v *Data) CurrentUser() any {
+	if v.currentUser != nil {
+		return v.currentUser
+	}
+	return v.slots().CurrentUser
+}
+
+// IsAuthenticated reports whether a user is signed in.
+func (v *Data) IsAuthenticated() bool {
+	return v.CurrentUser() != nil
+}
+
+// CSRFToken returns the CSRF token of the request, or an empty string without the csrf middleware.
+func (v *Data) CSRFToken() string {
+	return v.slots().CSRFToken
+}
+
+// Session returns the value stored under key in the session of the request, or nil without a session, e.g.
+// {{.View.Session "theme"}}.
+func (v *Data) Session(key string) any {
+	if session := v.slots().Session; session != nil {
+		return session.Get(key)
+	}
+	return nil
+}
+
 // ------ Error Helpers --------
 
 // HasError returns true if the view data model contains an error message.
@@ -155,6 +286,11 @@ func (v *Data) BaseURL() string {
 	return request.BaseURL(v.request)
 }
 
+// Request returns the request of the page, or nil if it is not set.
+func (v *Data) Request() *http.Request {
+	return v.request
+}
+
 // Context returns the context of the request.
 func (v *Data) Context() context.Context {
 	return v.request.Context()
@@ -175,6 +311,16 @@ func (v *Data) Nonce() string {
 	return ""
 }
 
+// Locale returns the locale from the request context, if available.
+func (v *Data) Locale() string {
+	locale, ok := v.request.Context().Value(constants.LocaleContextKey).(string)
+	if ok {
+		return locale
+	}
+
+	return ""
+}
+
 // HTMXNonce returns the HTMX nonce value from the request context, if available.
 // This adds the inlineScriptNonce key to a JSON object with the nonce value and can be used in an HTMX meta tag.
 func (v *Data) HTMXNonce() string {
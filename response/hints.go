@@ -0,0 +1,85 @@
+package response
+
+import "strings"
+
+// Hint is a resource hint sent as a Link header, so browsers start fetching critical assets or connecting to their
+// origins before parsing the page, e.g. </css/app.css>; rel=preload; as=style.
+type Hint struct {
+	// URL is the URL of the resource, or the origin of a preconnect.
+	URL string
+	// Rel is the relation of the hint, e.g. preload or preconnect.
+	Rel string
+	// As is the destination of a preload, e.g. style, script, font or image.
+	As string
+	// CrossOrigin fetches the resource in CORS mode, as fonts and the resources of other origins require.
+	CrossOrigin bool
+}
+
+// String returns the hint as the value of a Link header.
+func (h Hint) String() string {
+	var b strings.Builder
+	b.WriteString("<" + h.URL + ">; rel=" + h.Rel)
+	if h.As != "" {
+		b.WriteString("; as=" + h.As)
+	}
+	if h.CrossOrigin {
+		b.WriteString("; crossorigin")
+	}
+	return b.String()
+}
+
+// PreloadHint returns the hint preloading the resource with the destination, e.g. style. Fonts are preloaded in CORS
+// mode, as browsers fetch them.
+func PreloadHint(url, as string) Hint {
+	return Hint{URL: url, Rel: "preload", As: as, CrossOrigin: as == "font"}
+}
+
+// PreconnectHint returns the hint connecting to the origin early, e.g. https://fonts.gstatic.com.
+func PreconnectHint(origin string) Hint {
+	return Hint{URL: origin, Rel: "preconnect"}
+}
+
+// Preload registers the hint preloading the resource with the destination, e.g. Preload("/css/app.css", "style").
+func (resp *Response) Preload(url, as string) *Response {
+	resp.data.addHint(PreloadHint(url, as))
+	return resp
+}
+
+// Preconnect registers the hint connecting to the origin early.
+func (resp *Response) Preconnect(origin string) *Response {
+	resp.data.addHint(PreconnectHint(origin))
+	return resp
+}
+
+// Hints returns the resource hints registered by the handler and the templates rendering the response, without
+// duplicates.
+func (resp *Response) Hints() []Hint {
+	return resp.data.hints
+}
+
+// Preload registers the hint preloading the resource with the destination while rendering, so layouts and partials
+// declare their critical assets:
+//
+//	{{.View.Preload "/css/app.css" "style"}}
+//
+// It renders nothing.
+func (v *Data) Preload(url, as string) string {
+	v.addHint(PreloadHint(url, as))
+	return ""
+}
+
+// Preconnect registers the hint connecting to the origin early while rendering. It renders nothing.
+func (v *Data) Preconnect(origin string) string {
+	v.addHint(PreconnectHint(origin))
+	return ""
+}
+
+// addHint adds the hint, unless it was already added.
+func (v *Data) addHint(hint Hint) {
+	for _, h := range v.hints {
+		if h == hint {
+			return
+		}
+	}
+	v.hints = append(v.hints, hint)
+}
@@ -0,0 +1,122 @@
+package response
+
+import (
+	"html/template"
+	"net/url"
+	"strings"
+)
+
+// Meta describes the SEO, OpenGraph and Twitter card tags of a page, rendered in the head of the layout with the
+// renderMeta template function. Empty fields are left out, or take the site-wide defaults of the template adapter.
+type Meta struct {
+	// Title is the title of the page in shares. Default is the page title.
+	Title string
+	// Description is the description of the page, for search results and shares.
+	Description string
+	// Canonical is the canonical URL of the page. Paths are resolved against the base URL of the request.
+	Canonical string
+	// Image is the URL of the image shown in shares. Paths are resolved against the base URL of the request.
+	Image string
+	// ImageAlt is the alternative text of the image.
+	ImageAlt string
+	// Type is the OpenGraph type of the page, such as "article". Default is "website".
+	Type string
+	// SiteName is the name of the site.
+	SiteName string
+	// Locale is the OpenGraph locale of the page, such as "en_US".
+	Locale string
+	// Robots is the content of the robots meta tag, such as "noindex, nofollow".
+	Robots string
+	// TwitterCard is the Twitter card type. Default is "summary_large_image" for pages with an image, and "summary"
+	// for other pages.
+	TwitterCard string
+	// TwitterSite is the Twitter handle of the site, such as "@hypergopher".
+	TwitterSite string
+}
+
+// Merge returns the meta with its empty fields set to the fields of defaults.
+func (m Meta) Merge(defaults Meta) Meta {
+	merge := func(value *string, fallback string) {
+		if *value == "" {
+			*value = fallback
+		}
+	}
+
+	merge(&m.Title, defaults.Title)
+	merge(&m.Description, defaults.Description)
+	merge(&m.Canonical, defaults.Canonical)
+	merge(&m.Image, defaults.Image)
+	merge(&m.ImageAlt, defaults.ImageAlt)
+	merge(&m.Type, defaults.Type)
+	merge(&m.SiteName, defaults.SiteName)
+	merge(&m.Locale, defaults.Locale)
+	merge(&m.Robots, defaults.Robots)
+	merge(&m.TwitterCard, defaults.TwitterCard)
+	merge(&m.TwitterSite, defaults.TwitterSite)
+	return m
+}
+
+// Tags renders the meta tags. Relative canonical and image URLs are resolved against baseURL, as crawlers require
+// absolute URLs, unless baseURL is empty.
+func (m Meta) Tags(baseURL string) template.HTML {
+	if m.Type == "" {
+		m.Type = "website"
+	}
+	if m.TwitterCard == "" {
+		m.TwitterCard = "summary"
+		if m.Image != "" {
+			m.TwitterCard = "summary_large_image"
+		}
+	}
+	m.Canonical = absoluteURL(baseURL, m.Canonical)
+	m.Image = absoluteURL(baseURL, m.Image)
+
+	var b strings.Builder
+	// OpenGraph tags are keyed by property, and the others by name
+	tag := func(attr, key, value string) {
+		if value != "" {
+			b.WriteString(`<meta ` + attr + `="` + key + `" content="` + template.HTMLEscapeString(value) + "\">\n")
+		}
+	}
+
+	tag("name", "description", m.Description)
+	tag("name", "robots", m.Robots)
+	if m.Canonical != "" {
+		b.WriteString(`<link rel="canonical" href="` + template.HTMLEscapeString(m.Canonical) + "\">\n")
+	}
+
+	tag("property", "og:title", m.Title)
+	tag("property", "og:description", m.Description)
+	tag("property", "og:type", m.Type)
+	tag("property", "og:url", m.Canonical)
+	tag("property", "og:site_name", m.SiteName)
+	tag("property", "og:locale", m.Locale)
+	tag("property", "og:image", m.Image)
+	tag("property", "og:image:alt", m.ImageAlt)
+
+	tag("name", "twitter:card", m.TwitterCard)
+	tag("name", "twitter:site", m.TwitterSite)
+	tag("name", "twitter:title", m.Title)
+	tag("name", "twitter:description", m.Description)
+	tag("name", "twitter:image", m.Image)
+	tag("name", "twitter:image:alt", m.ImageAlt)
+
+	return template.HTML(b.String())
+}
+
+// absoluteURL resolves ref against base, leaving it as is if either is empty or invalid.
+func absoluteURL(base, ref string) string {
+	if base == "" || ref == "" {
+		return ref
+	}
+
+	b, err := url.Parse(base)
+	if err != nil {
+		return ref
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return b.ResolveReference(r).String()
+}
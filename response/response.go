@@ -1,8 +1,16 @@
 package response
 
 import (
+	"html/template"
+	"io"
+	"io/fs"
+	"mime"
 	"net/http"
+	"net/url"
+	pathpkg "path"
+	"reflect"
 	"strings"
+	"time"
 
 	"github.com/hypergopher/hyperview/constants"
 	"github.com/hypergopher/hyperview/htmx"
@@ -24,10 +32,45 @@ type Response struct {
 	statusCode int
 	// The title of the page (default: the page name without the extension)
 	title string
+	// The meta tags of the page (default: empty, the adapter's defaults)
+	meta Meta
+	// The breadcrumb trail of the page (default: empty)
+	breadcrumbs Breadcrumbs
+	// The flash messages added by the handler, shown after those of the request (default: empty)
+	flashes []Flash
+	// The signed-in user, overriding the user of the request (default: nil, the user of the request)
+	currentUser any
 	// The triggers to be passed to the response (default: empty)
 	triggers *trigger.Triggers
 	// The view data to be passed to the template (default: ViewData{})
 	data *Data
+	// The experiment variants the response was rendered with, keyed by experiment name (default: empty)
+	variants map[string]string
+	// The feature flags the response was rendered with, keyed by flag name (default: empty)
+	flags map[string]bool
+	// The data loaders to run before rendering (default: empty)
+	loaders []loaderEntry
+	// The data loaders of the sections streamed once loaded (default: empty)
+	deferred []loaderEntry
+	// The request-scoped template functions for this render (default: empty)
+	funcs template.FuncMap
+	// The key the rendered body is cached under by the adapter's render cache (default: empty, not cached)
+	cacheKey string
+	// How long the rendered body is cached (default: 0, no expiry)
+	cacheTTL time.Duration
+	// Whether the adapter skips computing an ETag of the rendered body (default: false)
+	noETag bool
+	// Whether the template is rendered in strict mode, overriding the adapter's mode (default: nil, the adapter's mode)
+	strict *bool
+	// The kind of outcome of the response (default: KindView, rendering the view)
+	kind Kind
+	// The URL a redirect redirects to (default: empty)
+	location string
+	// The file system and name of the file a file response serves (default: nil)
+	fileFS   fs.FS
+	fileName string
+	// The reader a stream response copies its body from (default: nil)
+	stream io.Reader
 }
 
 func NewResponse() *Response {
@@ -46,7 +89,12 @@ func NewResponse() *Response {
 // the request is available in the template and that it is not overwritten until later in the process.
 func (resp *Response) ViewData(r *http.Request) *Data {
 	resp.data.SetTitle(resp.title)
+	resp.data.SetMeta(resp.meta)
+	resp.data.SetBreadcrumbs(resp.breadcrumbs)
+	resp.data.SetFlashes(resp.flashes)
+	resp.data.SetCurrentUser(resp.currentUser)
 	resp.data.SetRequest(r)
+	resp.data.SetVariants(resp.variants)
 	return resp.data
 }
 
@@ -82,6 +130,17 @@ func (resp *Response) Headers() map[string]string {
 	return resp.headers
 }
 
+// IsHTML reports whether the response is rendered as HTML: it sets no Content-Type header, as views are HTML by
+// default, or a text/html one. Render middleware rewriting HTML skips the other responses, such as JSON or feeds.
+func (resp *Response) IsHTML() bool {
+	contentType, ok := resp.headers["Content-Type"]
+	if !ok {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == "text/html"
+}
+
 // HTTPHeader returns a http.Header for the headers map
 func (resp *Response) HTTPHeader() http.Header {
 	if resp.headers == nil {
@@ -116,6 +175,16 @@ func (resp *Response) StatusCode() int {
 	return resp.statusCode
 }
 
+// Flags returns the feature flags the response was rendered with, keyed by flag name.
+func (resp *Response) Flags() map[string]bool {
+	return resp.flags
+}
+
+// Variants returns the experiment variants assigned to the response, keyed by experiment name.
+func (resp *Response) Variants() map[string]string {
+	return resp.variants
+}
+
 // ResetData resets the view data model with an existing model. It returns the modified Response pointer.
 // The view data model contains data that will be passed to the view template for rendering.
 //
@@ -158,34 +227,99 @@ func (resp *Response) Errors(msg string, fieldErrors map[string]string) *Respons
 	return resp
 }
 
+// OldInput adds the values of a submitted form, such as r.PostForm, to the view data model, so the form helpers
+// (input, select and checkbox) fill the fields back in when the form is rendered again with errors.
+func (resp *Response) OldInput(values url.Values) *Response {
+	resp.data.AddOldInput(values)
+	return resp
+}
+
 // Title sets the page title
 func (resp *Response) Title(title string) *Response {
 	resp.title = title
 	return resp
 }
 
-// Path sets the template path
+// Meta sets the SEO, OpenGraph and Twitter card tags of the page, rendered with the renderMeta template function.
+// Empty fields take the site-wide defaults of the template adapter. It returns the modified Response pointer.
+func (resp *Response) Meta(meta Meta) *Response {
+	resp.meta = meta
+	return resp
+}
+
+// PageMeta returns the meta tags set with Meta.
+func (resp *Response) PageMeta() Meta {
+	return resp.meta
+}
+
+// Breadcrumb appends an entry to the breadcrumb trail of the page, rendered with the @breadcrumbs partial. The last
+// entry is the current page, and usually has no URL. It returns the modified Response pointer.
+func (resp *Response) Breadcrumb(name, url string) *Response {
+	resp.breadcrumbs = append(resp.breadcrumbs, Breadcrumb{Name: name, URL: url})
+	return resp
+}
+
+// Breadcrumbs replaces the breadcrumb trail of the page, e.g. with a trail built by a middleware. It returns the
+// modified Response pointer.
+func (resp *Response) Breadcrumbs(breadcrumbs Breadcrumbs) *Response {
+	resp.breadcrumbs = breadcrumbs
+	return resp
+}
+
+// PageBreadcrumbs returns the breadcrumb trail of the page.
+func (resp *Response) PageBreadcrumbs() Breadcrumbs {
+	return resp.breadcrumbs
+}
+
+// Flash adds a flash message to the page, shown after the flash messages of the request with the @flashes partial.
+// It returns the modified Response pointer.
+func (resp *Response) Flash(kind, message string) *Response {
+	resp.flashes = append(resp.flashes, Flash{Kind: kind, Message: message})
+	return resp
+}
+
+// CurrentUser sets the signed-in user of the page, overriding the user set on the request by middleware. It returns
+// the modified Response pointer.
+func (resp *Response) CurrentUser(user any) *Response {
+	resp.currentUser = user
+	return resp
+}
+
+// Path sets the template path. Paths are slash-separated on every platform, so home\index is views/home/index.
 func (resp *Response) Path(path string) *Response {
 	// If the path contains a colon, it's part of a plugin path, so we need to
 	// extract the plugin name from the path first
-	pathParts := strings.SplitN(path, ":", 2)
-
-	if len(pathParts) == 2 {
-		path = pathParts[1]
+	fsID, viewPath, prefixed := strings.Cut(path, ":")
+	if !prefixed {
+		viewPath = fsID
 	}
+	normalized := slashPath(viewPath)
 
-	if !strings.HasPrefix(path, constants.ViewsDir+"/") {
-		path = constants.ViewsDir + "/" + path
+	if !strings.HasPrefix(normalized, constants.ViewsDir+"/") {
+		normalized = constants.ViewsDir + "/" + normalized
 	}
 
-	if len(pathParts) == 2 {
-		path = pathParts[0] + ":" + path
+	// Paths already normalized, such as constants, are kept as they are rather than built again
+	switch {
+	case normalized == viewPath:
+	case prefixed:
+		path = fsID + ":" + normalized
+	default:
+		path = normalized
 	}
 
 	resp.path = path
 	return resp
 }
 
+// slashPath returns the cleaned, slash-separated and unrooted form of a template path.
+func slashPath(p string) string {
+	if p == "" {
+		return p
+	}
+	return strings.TrimLeft(pathpkg.Clean(strings.ReplaceAll(p, `\`, "/")), "/")
+}
+
 // Layout sets the template layout. It updates the layout value in the Response struct.
 // Then it returns the updated Response struct itself for method chaining.
 func (resp *Response) Layout(layout string) *Response {
@@ -208,6 +342,64 @@ func (resp *Response) HxLayout(r *http.Request, hxLayout, layout string) *Respon
 	return resp
 }
 
+// WithFuncs adds request-scoped template functions for this render, such as currentUser or hasPermission, which are
+// bound to a copy of the compiled template. The functions must be declared in the adapter's RequestFuncs option, as
+// html/template resolves function names when parsing templates. It returns the modified Response pointer.
+func (resp *Response) WithFuncs(funcs template.FuncMap) *Response {
+	if resp.funcs == nil {
+		resp.funcs = make(template.FuncMap, len(funcs))
+	}
+
+	for name, fn := range funcs {
+		resp.funcs[name] = fn
+	}
+	return resp
+}
+
+// Funcs returns the request-scoped template functions added with WithFuncs.
+func (resp *Response) Funcs() template.FuncMap {
+	return resp.funcs
+}
+
+// Strict overrides the strict mode of the adapter for this render. In strict mode, references to keys missing from the
+// view data fail the render instead of rendering nothing. It returns the modified Response pointer.
+func (resp *Response) Strict(strict bool) *Response {
+	resp.strict = &strict
+	return resp
+}
+
+// StrictMode returns the strict mode set with Strict, and false if the response does not override the adapter's mode.
+func (resp *Response) StrictMode() (strict bool, ok bool) {
+	if resp.strict == nil {
+		return false, false
+	}
+	return *resp.strict, true
+}
+
+// Variant records the variant (arm) of an experiment that the response is rendered with. The assignment is available
+// to templates via .View.Variant, is annotated on the rendered page and is reported to the adapter's render hook, so
+// client-side analytics and server metrics agree on which variant was shown.
+func (resp *Response) Variant(experiment, variant string) *Response {
+	if resp.variants == nil {
+		resp.variants = make(map[string]string)
+	}
+
+	resp.variants[experiment] = variant
+	return resp
+}
+
+// Flag records the state of a feature flag the response is rendered with. Flags recorded by the handler override the
+// adapter's flag provider for the feature template function, and the flags templates evaluate are recorded too, so
+// all are reported to the adapter's render hook.
+func (resp *Response) Flag(name string, enabled bool) *Response {
+	if resp.flags == nil {
+		resp.flags = make(map[string]bool)
+	}
+
+	resp.flags[name] = enabled
+	return resp
+}
+
 // Header adds/sets a header
 func (resp *Response) Header(key, value string) *Response {
 	if resp.headers == nil {
@@ -297,3 +489,38 @@ func (resp *Response) StatusStopPolling() *Response {
 func (resp *Response) Render(w http.ResponseWriter, r *http.Request, renderer Renderer) {
 	renderer.Render(w, r, resp)
 }
+
+// ForView creates a response rendering the view at path with data, for integrations whose render interfaces take a
+// view name and a data value. Responses are used as is, with path as their view path if they have none. Maps with
+// string keys, such as gin.H or echo.Map, are the view data, and other values are available as .Data.
+func ForView(path string, data any) *Response {
+	if resp, ok := data.(*Response); ok {
+		if resp.TemplatePath() == "" && path != "" {
+			resp.Path(path)
+		}
+		return resp
+	}
+
+	resp := NewResponse()
+	if path != "" {
+		resp.Path(path)
+	}
+
+	switch d := data.(type) {
+	case nil:
+	case map[string]any:
+		resp.Data(d)
+	default:
+		rv := reflect.ValueOf(data)
+		if rv.Kind() != reflect.Map || rv.Type().Key().Kind() != reflect.String {
+			resp.AddDataItem("Data", data)
+			break
+		}
+		m := make(map[string]any, rv.Len())
+		for iter := rv.MapRange(); iter.Next(); {
+			m[iter.Key().String()] = iter.Value().Interface()
+		}
+		resp.Data(m)
+	}
+	return resp
+}
@@ -1,5 +1,7 @@
 package response
 
+import "time"
+
 // NoCacheStrict sets the Cache-Control header to "no-cache, no-store, must-revalidate".
 func (resp *Response) NoCacheStrict() {
 	resp.headers["Cache-Control"] = "no-cache, no-store, must-revalidate"
@@ -15,7 +17,45 @@ func (resp *Response) ETag(etag string) {
 	resp.headers["ETag"] = etag
 }
 
+// NoETag stops the adapter from computing an ETag of the rendered body and answering conditional requests with
+// 304 Not Modified, e.g. for pages that are expensive to render and must not be served from a stale cache. It
+// returns the modified Response pointer.
+func (resp *Response) NoETag() *Response {
+	resp.noETag = true
+	return resp
+}
+
+// ETagDisabled returns true if NoETag was called.
+func (resp *Response) ETagDisabled() bool {
+	return resp.noETag
+}
+
 // LastModified sets the Last-Modified header to the given value.
 func (resp *Response) LastModified(lastModified string) {
 	resp.headers["Last-Modified"] = lastModified
 }
+
+// Cache caches the rendered body in the adapter's render cache under key for ttl, so later renders with the same key
+// are served from the cache without running the data loaders or executing the template. The key must identify
+// everything the body depends on, e.g. "pricing:"+locale. A ttl of zero means the body is cached until it is
+// invalidated. Headers and the status code are not cached. It returns the modified Response pointer.
+//
+// A cached body is served as is to every request with the key, so it must not hold values specific to the request it
+// was rendered for. Bodies holding the CSP nonce of the request, e.g. from cspNonce or scriptTag, or its CSRF token,
+// e.g. from csrfField, or showing its flash messages are not cached, and are logged as a warning: render the pages
+// with such values without Cache, or cache their request-independent fragments with cachedTemplate instead.
+func (resp *Response) Cache(key string, ttl time.Duration) *Response {
+	resp.cacheKey = key
+	resp.cacheTTL = ttl
+	return resp
+}
+
+// CacheKey returns the render cache key set with Cache.
+func (resp *Response) CacheKey() string {
+	return resp.cacheKey
+}
+
+// CacheTTL returns the render cache ttl set with Cache.
+func (resp *Response) CacheTTL() time.Duration {
+	return resp.cacheTTL
+}
@@ -0,0 +1,122 @@
+package response
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+)
+
+// Kind is the kind of outcome of a response: a rendered view, or an outcome needing no view, so handlers return one
+// response value whatever the outcome.
+type Kind int
+
+const (
+	// KindView renders the view of the response. It is the default.
+	KindView Kind = iota
+	// KindRedirect redirects to another URL, see Response.Redirect.
+	KindRedirect
+	// KindNoContent answers with no body, see Response.NoContent.
+	KindNoContent
+	// KindFile serves a file, see Response.File.
+	KindFile
+	// KindStream copies the body from a reader, see Response.Stream.
+	KindStream
+)
+
+// String returns the name of the kind.
+func (k Kind) String() string {
+	switch k {
+	case KindView:
+		return "view"
+	case KindRedirect:
+		return "redirect"
+	case KindNoContent:
+		return "no content"
+	case KindFile:
+		return "file"
+	case KindStream:
+		return "stream"
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// Redirect makes the response redirect to the URL with the status code, such as http.StatusFound, instead of
+// rendering a view. The URL is resolved like with http.Redirect.
+func (resp *Response) Redirect(url string, code int) *Response {
+	resp.kind = KindRedirect
+	resp.location = url
+	resp.statusCode = code
+	return resp
+}
+
+// SeeOther makes the response redirect to the URL with 303 See Other, e.g. after a form post.
+func (resp *Response) SeeOther(url string) *Response {
+	return resp.Redirect(url, http.StatusSeeOther)
+}
+
+// NoContent makes the response answer with 204 No Content instead of rendering a view.
+func (resp *Response) NoContent() *Response {
+	resp.kind = KindNoContent
+	resp.statusCode = http.StatusNoContent
+	return resp
+}
+
+// File makes the response serve the named file of the file system instead of rendering a view, with
+// http.ServeFileFS: the content type is detected from the name, and range and conditional requests are supported.
+// Set a Content-Disposition header to download the file.
+func (resp *Response) File(fsys fs.FS, name string) *Response {
+	resp.kind = KindFile
+	resp.fileFS = fsys
+	resp.fileName = name
+	return resp
+}
+
+// Stream makes the response copy its body from the reader, with the content type, instead of rendering a view. The
+// reader is closed once copied if it is an io.Closer.
+func (resp *Response) Stream(body io.Reader, contentType string) *Response {
+	resp.kind = KindStream
+	resp.stream = body
+	resp.headers["Content-Type"] = contentType
+	return resp
+}
+
+// Kind returns the kind of outcome of the response.
+func (resp *Response) Kind() Kind {
+	return resp.kind
+}
+
+// RedirectURL returns the URL the response redirects to, if it is a redirect.
+func (resp *Response) RedirectURL() string {
+	return resp.location
+}
+
+// WriteKind writes the responses of other kinds than KindView, with their headers and status code. It returns an
+// error if the response renders a view, or if the body of a stream cannot be copied.
+func (resp *Response) WriteKind(w http.ResponseWriter, r *http.Request) error {
+	if resp.kind == KindView {
+		return fmt.Errorf("response renders view %s", resp.TemplatePath())
+	}
+
+	for key, value := range resp.Headers() {
+		w.Header().Set(key, value)
+	}
+
+	switch resp.kind {
+	case KindRedirect:
+		http.Redirect(w, r, resp.location, resp.statusCode)
+	case KindNoContent:
+		w.WriteHeader(resp.statusCode)
+	case KindFile:
+		http.ServeFileFS(w, r, resp.fileFS, resp.fileName)
+	case KindStream:
+		if closer, ok := resp.stream.(io.Closer); ok {
+			defer closer.Close()
+		}
+		w.WriteHeader(resp.statusCode)
+		if _, err := io.Copy(w, resp.stream); err != nil {
+			return fmt.Errorf("error streaming response: %w", err)
+		}
+	}
+	return nil
+}
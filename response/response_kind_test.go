@@ -0,0 +1,88 @@
+package response_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview/response"
+)
+
+type closingReader struct {
+	io.Reader
+	closed bool
+}
+
+func (r *closingReader) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestResponse_WriteKind(t *testing.T) {
+	files := fstest.MapFS{"report.csv": {Data: []byte("a,b\n1,2\n")}}
+	stream := &closingReader{Reader: strings.NewReader("data: hello\n\n")}
+
+	tests := []struct {
+		name       string
+		resp       *response.Response
+		wantKind   response.Kind
+		wantStatus int
+		wantBody   string
+		wantHeader map[string]string
+		wantErr    bool
+	}{
+		{name: "redirect", resp: response.NewResponse().Redirect("/login", http.StatusFound), wantKind: response.KindRedirect,
+			wantStatus: http.StatusFound, wantHeader: map[string]string{"Location": "/login"}},
+		{name: "see other", resp: response.NewResponse().SeeOther("/posts/1").Header("X-Saved", "1"), wantKind: response.KindRedirect,
+			wantStatus: http.StatusSeeOther, wantHeader: map[string]string{"Location": "/posts/1", "X-Saved": "1"}},
+		{name: "no content", resp: response.NewResponse().NoContent(), wantKind: response.KindNoContent,
+			wantStatus: http.StatusNoContent},
+		{name: "file", resp: response.NewResponse().File(files, "report.csv").Header("Content-Disposition", "attachment"),
+			wantKind: response.KindFile, wantStatus: http.StatusOK, wantBody: "a,b\n1,2\n",
+			wantHeader: map[string]string{"Content-Type": "text/csv; charset=utf-8", "Content-Disposition": "attachment"}},
+		{name: "missing file", resp: response.NewResponse().File(files, "missing.csv"), wantKind: response.KindFile,
+			wantStatus: http.StatusNotFound},
+		{name: "stream", resp: response.NewResponse().Stream(stream, "text/event-stream"), wantKind: response.KindStream,
+			wantStatus: http.StatusOK, wantBody: "data: hello\n\n", wantHeader: map[string]string{"Content-Type": "text/event-stream"}},
+		{name: "view", resp: response.NewResponse().Path("home"), wantKind: response.KindView, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.resp.Kind() != tt.wantKind {
+				t.Fatalf("expected kind %s, got %s", tt.wantKind, tt.resp.Kind())
+			}
+
+			w := httptest.NewRecorder()
+			err := tt.resp.WriteKind(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error writing response: %v", err)
+			}
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Errorf("expected body %q, got %q", tt.wantBody, w.Body.String())
+			}
+			for key, value := range tt.wantHeader {
+				if got := w.Header().Get(key); got != value {
+					t.Errorf("expected header %s %q, got %q", key, value, got)
+				}
+			}
+		})
+	}
+
+	if !stream.closed {
+		t.Error("expected the stream to be closed")
+	}
+}
@@ -0,0 +1,196 @@
+package response
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"slices"
+	"sync"
+)
+
+// DefaultLoaderConcurrency is the maximum number of data loaders run at the same time for a response, unless the
+// adapter is configured otherwise.
+const DefaultLoaderConcurrency = 8
+
+// Loader loads the data for a part of a page, such as an independent fragment backed by a slow data source.
+// It should stop and return the context's error when the context is cancelled.
+type Loader func(ctx context.Context) (any, error)
+
+// loaderEntry is a data loader registered on a response.
+type loaderEntry struct {
+	key    string
+	loader Loader
+}
+
+// PanicError is the error of a data loader that panicked, carrying the value passed to panic and the stack of the
+// loader. The panics of loaders are recovered, as they run in goroutines of their own, which the recovery of the
+// net/http handlers does not cover, so a panicking loader fails its render rather than the process.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v\n\n%s", e.Value, e.Stack)
+}
+
+// load runs the loader, turning a panic into a PanicError.
+func (entry loaderEntry) load(ctx context.Context) (value any, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			value, err = nil, &PanicError{Value: recovered, Stack: debug.Stack()}
+		}
+	}()
+	return entry.loader(ctx)
+}
+
+// Load registers a data loader whose result is added to the view data under the given key. Loaders are run
+// concurrently before the template is executed, so a page composed of several slow data sources only waits for the
+// slowest one. It returns the modified Response pointer.
+func (resp *Response) Load(key string, loader Loader) *Response {
+	resp.loaders = append(resp.loaders, loaderEntry{key: key, loader: loader})
+	return resp
+}
+
+// Defer registers the data loader of a deferred section of the page, the template named deferred:<key>. Pages
+// streamed by the template adapter are flushed without waiting for deferred loaders, and each section is streamed
+// once its loader returns, with its result added to the view data under the given key. Pages rendered without
+// streaming wait for deferred loaders like for the others. It returns the modified Response pointer.
+func (resp *Response) Defer(key string, loader Loader) *Response {
+	resp.deferred = append(resp.deferred, loaderEntry{key: key, loader: loader})
+	return resp
+}
+
+// DeferredKeys returns the keys of the deferred loaders not run yet, in registration order.
+func (resp *Response) DeferredKeys() []string {
+	keys := make([]string, len(resp.deferred))
+	for i, entry := range resp.deferred {
+		keys[i] = entry.key
+	}
+	return keys
+}
+
+// DeferredResult is the result of a deferred loader.
+type DeferredResult struct {
+	Key   string
+	Value any
+	Err   error
+}
+
+// RunDeferred starts the deferred loaders concurrently, with at most limit loaders running at the same time (or
+// DefaultLoaderConcurrency if limit is less than one), and returns a channel receiving their results as they
+// complete, closed once all are done. A failing loader does not cancel the others. Deferred loaders only run once,
+// so RunLoaders no longer runs them.
+func (resp *Response) RunDeferred(ctx context.Context, limit int) <-chan DeferredResult {
+	if limit < 1 {
+		limit = DefaultLoaderConcurrency
+	}
+
+	entries := resp.deferred
+	resp.deferred = nil
+
+	// The channel holds all results, so loaders never block on a reader that gave up
+	results := make(chan DeferredResult, len(entries))
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for _, entry := range entries {
+		wg.Add(1)
+		go func(entry loaderEntry) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				results <- DeferredResult{Key: entry.key, Err: fmt.Errorf("error loading %s: %w", entry.key, ctx.Err())}
+				return
+			}
+			defer func() { <-sem }()
+
+			value, err := entry.load(ctx)
+			if err != nil {
+				err = fmt.Errorf("error loading %s: %w", entry.key, err)
+			}
+			results <- DeferredResult{Key: entry.key, Value: value, Err: err}
+		}(entry)
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+	return results
+}
+
+// RunLoaders runs the registered data loaders concurrently, with at most limit loaders running at the same time
+// (or DefaultLoaderConcurrency if limit is less than one), and adds their results to the view data. It blocks until
+// all loaders are done. If a loader fails, the context passed to the other loaders is cancelled and the first error
+// is returned. Loaders only run once, so calling RunLoaders again is a no-op. The deferred loaders not started by
+// RunDeferred run along with the others.
+func (resp *Response) RunLoaders(ctx context.Context, limit int) error {
+	loaders := slices.Concat(resp.loaders, resp.deferred)
+	if len(loaders) == 0 {
+		return nil
+	}
+
+	if limit < 1 {
+		limit = DefaultLoaderConcurrency
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+		results  = make(map[string]any, len(loaders))
+		sem      = make(chan struct{}, limit)
+	)
+
+	fail := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+
+	for _, entry := range loaders {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		// select picks among ready cases at random, so a done context may still hand out a slot
+		if err := ctx.Err(); err != nil {
+			fail(err)
+			break
+		}
+
+		wg.Add(1)
+		go func(entry loaderEntry) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			value, err := entry.load(ctx)
+			if err != nil {
+				fail(fmt.Errorf("error loading %s: %w", entry.key, err))
+				return
+			}
+
+			mu.Lock()
+			results[entry.key] = value
+			mu.Unlock()
+		}(entry)
+	}
+
+	wg.Wait()
+	resp.loaders = nil
+	resp.deferred = nil
+
+	if firstErr != nil {
+		return firstErr
+	}
+
+	resp.data.AddData(results)
+	return nil
+}
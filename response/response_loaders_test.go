@@ -0,0 +1,169 @@
+package response_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hypergopher/hyperview/response"
+)
+
+func TestResponse_RunLoaders(t *testing.T) {
+	var running, maxRunning int32
+	loader := func(value string) response.Loader {
+		return func(ctx context.Context) (any, error) {
+			n := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+			for {
+				m := atomic.LoadInt32(&maxRunning)
+				if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			return value, nil
+		}
+	}
+
+	resp := response.NewResponse().
+		Load("a", loader("A")).
+		Load("b", loader("B")).
+		Load("c", loader("C"))
+
+	if err := resp.RunLoaders(context.Background(), 2); err != nil {
+		t.Fatalf("RunLoaders() error = %v", err)
+	}
+
+	data := resp.ViewData(nil)
+	for key, want := range map[string]string{"a": "A", "b": "B", "c": "C"} {
+		if got := data.GetString(key); got != want {
+			t.Errorf("data[%s] = %q, want %q", key, got, want)
+		}
+	}
+
+	if maxRunning > 2 {
+		t.Errorf("ran %d loaders concurrently, want at most 2", maxRunning)
+	}
+}
+
+func TestResponse_RunLoadersError(t *testing.T) {
+	errBoom := errors.New("boom")
+	cancelled := make(chan struct{})
+
+	resp := response.NewResponse().
+		Load("slow", func(ctx context.Context) (any, error) {
+			<-ctx.Done()
+			close(cancelled)
+			return nil, ctx.Err()
+		}).
+		Load("broken", func(ctx context.Context) (any, error) {
+			return nil, errBoom
+		})
+
+	err := resp.RunLoaders(context.Background(), 0)
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("RunLoaders() error = %v, want %v", err, errBoom)
+	}
+
+	select {
+	case <-cancelled:
+	default:
+		t.Error("expected the remaining loaders to be cancelled")
+	}
+
+	if got := resp.ViewData(nil).Get("slow"); got != "" {
+		t.Errorf("expected no data from failed loaders, got %v", got)
+	}
+}
+
+func TestResponse_RunDeferred(t *testing.T) {
+	errBoom := errors.New("boom")
+	release := make(chan struct{})
+
+	resp := response.NewResponse().
+		Defer("slow", func(ctx context.Context) (any, error) {
+			<-release
+			return "S", nil
+		}).
+		Defer("fast", func(ctx context.Context) (any, error) {
+			return "F", nil
+		}).
+		Defer("broken", func(ctx context.Context) (any, error) {
+			return nil, errBoom
+		})
+
+	if got := resp.DeferredKeys(); len(got) != 3 || got[0] != "slow" {
+		t.Fatalf("DeferredKeys() = %v", got)
+	}
+
+	results := resp.RunDeferred(context.Background(), 0)
+	if len(resp.DeferredKeys()) != 0 {
+		t.Error("expected the deferred loaders to run once")
+	}
+
+	// The failing loader does not cancel the others, and results arrive as they complete
+	got := map[string]response.DeferredResult{}
+	for len(got) < 2 {
+		result := <-results
+		got[result.Key] = result
+	}
+	if got["fast"].Value != "F" || !errors.Is(got["broken"].Err, errBoom) {
+		t.Errorf("unexpected results: %+v", got)
+	}
+
+	close(release)
+	if result := <-results; result.Key != "slow" || result.Value != "S" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if _, ok := <-results; ok {
+		t.Error("expected the results to be closed")
+	}
+}
+
+func TestResponse_RunLoadersRunsDeferred(t *testing.T) {
+	resp := response.NewResponse().
+		Load("a", func(ctx context.Context) (any, error) { return "A", nil }).
+		Defer("b", func(ctx context.Context) (any, error) { return "B", nil })
+
+	if err := resp.RunLoaders(context.Background(), 0); err != nil {
+		t.Fatalf("RunLoaders() error = %v", err)
+	}
+
+	data := resp.ViewData(nil)
+	if data.GetString("a") != "A" || data.GetString("b") != "B" {
+		t.Errorf("expected the deferred loaders to run with the others, got %v", data.Data())
+	}
+}
+
+func TestResponse_RunLoadersPanic(t *testing.T) {
+	panicking := func(ctx context.Context) (any, error) {
+		panic("nil map")
+	}
+
+	err := response.NewResponse().Load("broken", panicking).RunLoaders(context.Background(), 0)
+	var panicErr *response.PanicError
+	if !errors.As(err, &panicErr) || panicErr.Value != "nil map" || !strings.Contains(string(panicErr.Stack), "TestResponse_RunLoadersPanic") {
+		t.Fatalf("RunLoaders() error = %v, want the panic of the loader with its stack", err)
+	}
+
+	result := <-response.NewResponse().Defer("broken", panicking).RunDeferred(context.Background(), 0)
+	if !errors.As(result.Err, &panicErr) {
+		t.Errorf("RunDeferred() error = %v, want the panic of the loader", result.Err)
+	}
+}
+
+func TestResponse_RunLoadersCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Run repeatedly, as the free semaphore slots compete with the done context in the select of RunLoaders
+	for range 50 {
+		resp := response.NewResponse().Load("a", func(ctx context.Context) (any, error) { return "A", nil })
+		if err := resp.RunLoaders(ctx, 4); !errors.Is(err, context.Canceled) {
+			t.Fatalf("RunLoaders() error = %v, want %v", err, context.Canceled)
+		}
+	}
+}
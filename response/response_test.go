@@ -0,0 +1,32 @@
+package response_test
+
+import (
+	"testing"
+
+	"github.com/hypergopher/hyperview/response"
+)
+
+func TestResponse_IsHTML(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		want        bool
+	}{
+		{name: "default", want: true},
+		{name: "html", contentType: "text/html; charset=utf-8", want: true},
+		{name: "json", contentType: "application/json", want: false},
+		{name: "invalid", contentType: "text/html;;", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := response.NewResponse()
+			if tt.contentType != "" {
+				resp.Header("Content-Type", tt.contentType)
+			}
+			if got := resp.IsHTML(); got != tt.want {
+				t.Errorf("IsHTML() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package response
+
+import (
+	"context"
+	"net/http"
+)
+
+// Flash is a one-time message shown to the user, such as a confirmation after a form was submitted.
+type Flash struct {
+	// Kind is the kind of the message, e.g. "success", "info", "warning" or "error". The @flashes partial adds it to
+	// the class of the message.
+	Kind string
+	// Message is the text of the message.
+	Message string
+}
+
+// Session reads the values of the session of a request, such as the sessions of the session package.
+type Session interface {
+	// Get returns the value stored under key, or nil.
+	Get(key string) any
+}
+
+// Slots are the request-derived slots of the view data, set by middleware so every render of the request has them,
+// and read by layouts as .View.CurrentUser, .View.Flashes, .View.CSRFToken and .View.Session.
+type Slots struct {
+	// CurrentUser is the signed-in user, or nil.
+	CurrentUser any
+	// Flashes are the flash messages to show, e.g. popped from the session.
+	Flashes []Flash
+	// CSRFToken is the CSRF token of the request, set by the csrf middleware.
+	CSRFToken string
+	// Session is the session of the request, set by the session middleware.
+	Session Session
+}
+
+// FlashSession is a Session holding flash messages, such as the sessions of the session package. The view data reads
+// them along with the Flashes slot, so they are only popped by the requests rendering them.
+type FlashSession interface {
+	Session
+	// RequestFlashes returns the flash messages shown by the request, popping them from the session on the first call.
+	RequestFlashes() []Flash
+}
+
+type slotsKey struct{}
+
+// SlotsFromContext returns the slots carried by ctx, if any.
+func SlotsFromContext(ctx context.Context) Slots {
+	slots, _ := ctx.Value(slotsKey{}).(Slots)
+	return slots
+}
+
+// ContextWithSlots returns a copy of ctx carrying the slots. The non-zero fields of slots replace those already
+// carried by ctx, but flashes, which are appended.
+func ContextWithSlots(ctx context.Context, slots Slots) context.Context {
+	merged := SlotsFromContext(ctx)
+	if slots.CurrentUser != nil {
+		merged.CurrentUser = slots.CurrentUser
+	}
+	if len(slots.Flashes) > 0 {
+		merged.Flashes = append(append([]Flash(nil), merged.Flashes...), slots.Flashes...)
+	}
+	if slots.CSRFToken != "" {
+		merged.CSRFToken = slots.CSRFToken
+	}
+	if slots.Session != nil {
+		merged.Session = slots.Session
+	}
+	return context.WithValue(ctx, slotsKey{}, merged)
+}
+
+// ContextWithCurrentUser returns a copy of ctx carrying the signed-in user.
+func ContextWithCurrentUser(ctx context.Context, user any) context.Context {
+	return ContextWithSlots(ctx, Slots{CurrentUser: user})
+}
+
+// ContextWithFlashes returns a copy of ctx carrying the flash messages, after those ctx already carries.
+func ContextWithFlashes(ctx context.Context, flashes ...Flash) context.Context {
+	return ContextWithSlots(ctx, Slots{Flashes: flashes})
+}
+
+// ContextWithCSRFToken returns a copy of ctx carrying the CSRF token of the request.
+func ContextWithCSRFToken(ctx context.Context, token string) context.Context {
+	return ContextWithSlots(ctx, Slots{CSRFToken: token})
+}
+
+// ContextWithSession returns a copy of ctx carrying the session of the request.
+func ContextWithSession(ctx context.Context, session Session) context.Context {
+	return ContextWithSlots(ctx, Slots{Session: session})
+}
+
+// SlotsMiddleware returns middleware populating the slots of the view data of every request with those returned by
+// populate, e.g. the user of the session:
+//
+//	mux = response.SlotsMiddleware(func(r *http.Request) response.Slots {
+//		return response.Slots{CurrentUser: sessions.User(r), Flashes: sessions.PopFlashes(r)}
+//	})(mux)
+func SlotsMiddleware(populate func(r *http.Request) Slots) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(ContextWithSlots(r.Context(), populate(r))))
+		})
+	}
+}
+
+// FlashesTemplate is the source of the built-in @flashes partial, rendering the flash messages of the page. It is
+// called with the page's view data:
+//
+//	{{template "@flashes" .View}}
+const FlashesTemplate = `{{define "@flashes"}}
+{{- with .Flashes -}}
+<div class="flashes" role="status">
+{{- range .}}
+<p class="flash flash-{{.Kind}}">{{.Message}}</p>
+{{- end}}
+</div>
+{{- end -}}
+{{end}}`
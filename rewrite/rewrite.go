@@ -0,0 +1,254 @@
+// Package rewrite rewrites rendered HTML with an HTML tokenizer, so transforms can change the attributes of elements
+// or insert markup around them without matching the rendered string with regular expressions.
+//
+// A Rewriter streams the document through the tokenizer, calling the handlers registered for the tags of the start
+// tags it meets. The tokens no handler changed are written as they were, byte for byte. The Middleware method of a
+// Rewriter is a render middleware of the template adapter:
+//
+//	rewriter := rewrite.New(
+//		rewrite.On("img", rewrite.LazyLoad),
+//		rewrite.On("*", rewrite.CDN("https://cdn.example.com", "/assets/")),
+//		rewrite.On("script", rewrite.Nonce(csp.NonceFromContext)),
+//	)
+//	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+//		RenderMiddleware: []hyperview.RenderMiddleware{rewriter.Middleware},
+//	})
+package rewrite
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"strings"
+
+	xhtml "golang.org/x/net/html"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/response"
+)
+
+// Handler rewrites an element. An error aborts the rewrite.
+type Handler func(el *Element) error
+
+// Rule is a handler for the elements of a tag.
+type Rule struct {
+	// Tag is the lowercase name of the tag, "*" for all the start tags, or the name prefixed with a slash, such as
+	// "/body", for the end tags.
+	Tag string
+	// Handler rewrites the elements.
+	Handler Handler
+}
+
+// On returns the rule calling the handler for the elements of the tag, see Rule.
+func On(tag string, handler Handler) Rule {
+	return Rule{Tag: tag, Handler: handler}
+}
+
+// Rewriter rewrites HTML documents with rules. It is safe for concurrent use.
+type Rewriter struct {
+	rules map[string][]Handler
+}
+
+// New creates a Rewriter applying the rules, in order.
+func New(rules ...Rule) *Rewriter {
+	rw := &Rewriter{rules: make(map[string][]Handler)}
+	for _, rule := range rules {
+		rw.rules[rule.Tag] = append(rw.rules[rule.Tag], rule.Handler)
+	}
+	return rw
+}
+
+// Rewrite rewrites the HTML read from src to dst. The context is available to handlers, e.g. to read the nonce of
+// the request.
+func (rw *Rewriter) Rewrite(ctx context.Context, dst io.Writer, src io.Reader) error {
+	z := xhtml.NewTokenizer(src)
+	var raw []byte
+	for {
+		tt := z.Next()
+		switch tt {
+		case xhtml.ErrorToken:
+			if errors.Is(z.Err(), io.EOF) {
+				return nil
+			}
+			return z.Err()
+		case xhtml.StartTagToken, xhtml.SelfClosingTagToken, xhtml.EndTagToken:
+			// The tokenizer lowercases the names of the tags and attributes it returns in place
+			raw = append(raw[:0], z.Raw()...)
+			if err := rw.rewriteTag(ctx, dst, z, tt, raw); err != nil {
+				return err
+			}
+		default:
+			if _, err := dst.Write(z.Raw()); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// RewriteBytes rewrites the HTML of body.
+func (rw *Rewriter) RewriteBytes(ctx context.Context, body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Grow(len(body))
+	if err := rw.Rewrite(ctx, &buf, bytes.NewReader(body)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Middleware is a render middleware rewriting the bodies rendered as HTML, the responses without a Content-Type
+// header or with a text/html one, with the context of the request.
+func (rw *Rewriter) Middleware(next hyperview.RenderFunc) hyperview.RenderFunc {
+	return func(r *http.Request, resp *response.Response) ([]byte, error) {
+		body, err := next(r, resp)
+		if err != nil || !resp.IsHTML() {
+			return body, err
+		}
+
+		rewritten, err := rw.RewriteBytes(r.Context(), body)
+		if err != nil {
+			return nil, fmt.Errorf("error rewriting %s: %w", resp.TemplatePath(), err)
+		}
+		return rewritten, nil
+	}
+}
+
+// rewriteTag calls the handlers of the tag the tokenizer is at, and writes it.
+func (rw *Rewriter) rewriteTag(ctx context.Context, dst io.Writer, z *xhtml.Tokenizer, tt xhtml.TokenType, raw []byte) error {
+	name, _ := z.TagName()
+	tag := string(name)
+	if tt == xhtml.EndTagToken {
+		tag = "/" + tag
+	}
+
+	// The handlers for all the tags only see start tags
+	handlers := rw.rules[tag]
+	if tt != xhtml.EndTagToken {
+		handlers = append(handlers[:len(handlers):len(handlers)], rw.rules["*"]...)
+	}
+	if len(handlers) == 0 {
+		_, err := dst.Write(raw)
+		return err
+	}
+
+	el := &Element{ctx: ctx, tag: strings.TrimPrefix(tag, "/"), end: tt == xhtml.EndTagToken, selfClosing: tt == xhtml.SelfClosingTagToken}
+	for {
+		key, val, more := z.TagAttr()
+		if key != nil {
+			el.attrs = append(el.attrs, xhtml.Attribute{Key: string(key), Val: string(val)})
+		}
+		if !more {
+			break
+		}
+	}
+	for _, handler := range handlers {
+		if err := handler(el); err != nil {
+			return fmt.Errorf("error rewriting <%s>: %w", tag, err)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(el.before.String())
+	if el.changed {
+		el.writeTag(&b)
+	} else {
+		b.Write(raw)
+	}
+	b.WriteString(el.after.String())
+	_, err := io.WriteString(dst, b.String())
+	return err
+}
+
+// Element is the start or end tag of an element being rewritten.
+type Element struct {
+	ctx         context.Context
+	tag         string
+	attrs       []xhtml.Attribute
+	end         bool
+	selfClosing bool
+	changed     bool
+	before      strings.Builder
+	after       strings.Builder
+}
+
+// Context returns the context of the rewrite.
+func (el *Element) Context() context.Context {
+	return el.ctx
+}
+
+// Tag returns the lowercase name of the tag, e.g. img.
+func (el *Element) Tag() string {
+	return el.tag
+}
+
+// IsEndTag reports whether the tag is an end tag.
+func (el *Element) IsEndTag() bool {
+	return el.end
+}
+
+// Attr returns the value of the attribute, and whether the tag has it.
+func (el *Element) Attr(name string) (string, bool) {
+	for _, attr := range el.attrs {
+		if attr.Key == name {
+			return attr.Val, true
+		}
+	}
+	return "", false
+}
+
+// SetAttr sets the attribute, adding it if the tag doesn't have it.
+func (el *Element) SetAttr(name, value string) {
+	el.changed = true
+	for i, attr := range el.attrs {
+		if attr.Key == name {
+			el.attrs[i].Val = value
+			return
+		}
+	}
+	el.attrs = append(el.attrs, xhtml.Attribute{Key: name, Val: value})
+}
+
+// RemoveAttr removes the attribute.
+func (el *Element) RemoveAttr(name string) {
+	for i, attr := range el.attrs {
+		if attr.Key == name {
+			el.changed = true
+			el.attrs = append(el.attrs[:i], el.attrs[i+1:]...)
+			return
+		}
+	}
+}
+
+// Before inserts HTML before the tag. The HTML is inserted as is, so it must be escaped.
+func (el *Element) Before(html string) {
+	el.before.WriteString(html)
+}
+
+// After inserts HTML after the tag, i.e. at the start of the content of the element for a start tag. The HTML is
+// inserted as is, so it must be escaped.
+func (el *Element) After(html string) {
+	el.after.WriteString(html)
+}
+
+// writeTag writes the tag with its attributes.
+func (el *Element) writeTag(b *strings.Builder) {
+	b.WriteByte('<')
+	if el.end {
+		b.WriteByte('/')
+	}
+	b.WriteString(el.tag)
+	for _, attr := range el.attrs {
+		b.WriteByte(' ')
+		b.WriteString(attr.Key)
+		b.WriteString(`="`)
+		b.WriteString(html.EscapeString(attr.Val))
+		b.WriteByte('"')
+	}
+	if el.selfClosing {
+		b.WriteString(" /")
+	}
+	b.WriteByte('>')
+}
@@ -0,0 +1,150 @@
+package rewrite_test
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/response"
+	"github.com/hypergopher/hyperview/rewrite"
+)
+
+type nonceKey struct{}
+
+func nonce(ctx context.Context) string {
+	n, _ := ctx.Value(nonceKey{}).(string)
+	return n
+}
+
+func TestRewriter_RewriteBytes(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []rewrite.Rule
+		ctx   context.Context
+		input string
+		want  string
+	}{
+		{
+			name:  "no rules",
+			input: `<DIV Class=x><img src=a.png></DIV>`,
+			want:  `<DIV Class=x><img src=a.png></DIV>`,
+		},
+		{
+			name:  "lazy images",
+			rules: []rewrite.Rule{rewrite.On("img", rewrite.LazyLoad)},
+			input: `<p>Hi</p><img src="a.png" alt="A &amp; B"><img src="b.png" loading="eager"/>`,
+			want:  `<p>Hi</p><img src="a.png" alt="A &amp; B" loading="lazy"><img src="b.png" loading="eager"/>`,
+		},
+		{
+			name:  "leaves raw text alone",
+			rules: []rewrite.Rule{rewrite.On("img", rewrite.LazyLoad)},
+			input: `<script>let s = "<img src=x>";</script><textarea><img></textarea><!-- <img> -->`,
+			want:  `<script>let s = "<img src=x>";</script><textarea><img></textarea><!-- <img> -->`,
+		},
+		{
+			name:  "CDN",
+			rules: []rewrite.Rule{rewrite.On("*", rewrite.CDN("https://cdn.example.com/", "/assets/"))},
+			input: `<link href="/assets/app.css"><a href="/about">About</a><script src="//other.com/x.js"></script>` +
+				`<img srcset="/assets/a.png 1x, /b.png 2x">`,
+			want: `<link href="https://cdn.example.com/assets/app.css"><a href="/about">About</a><script src="//other.com/x.js"></script>` +
+				`<img srcset="https://cdn.example.com/assets/a.png 1x, /b.png 2x">`,
+		},
+		{
+			name:  "nonce",
+			rules: []rewrite.Rule{rewrite.On("script", rewrite.Nonce(nonce))},
+			ctx:   context.WithValue(context.Background(), nonceKey{}, "abc"),
+			input: `<script src="/app.js"></script><script nonce="set"></script><style></style>`,
+			want:  `<script src="/app.js" nonce="abc"></script><script nonce="set"></script><style></style>`,
+		},
+		{
+			name:  "no nonce",
+			rules: []rewrite.Rule{rewrite.On("script", rewrite.Nonce(nonce))},
+			input: `<script src="/app.js"></script>`,
+			want:  `<script src="/app.js"></script>`,
+		},
+		{
+			name: "inserts around tags",
+			rules: []rewrite.Rule{
+				rewrite.On("head", func(el *rewrite.Element) error { el.After(`<meta charset="utf-8">`); return nil }),
+				rewrite.On("/body", func(el *rewrite.Element) error { el.Before(`<script src="/app.js"></script>`); return nil }),
+			},
+			input: `<html><head></head><body><p>Hi</p></body></html>`,
+			want:  `<html><head><meta charset="utf-8"></head><body><p>Hi</p><script src="/app.js"></script></body></html>`,
+		},
+		{
+			name: "removes attributes",
+			rules: []rewrite.Rule{rewrite.On("*", func(el *rewrite.Element) error {
+				el.RemoveAttr("data-test")
+				return nil
+			})},
+			input: `<button data-test="save" disabled>Save</button>`,
+			want:  `<button disabled="">Save</button>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := tt.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			got, err := rewrite.New(tt.rules...).RewriteBytes(ctx, []byte(tt.input))
+			if err != nil {
+				t.Fatalf("error rewriting: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRewriter_Middleware(t *testing.T) {
+	rewriter := rewrite.New(
+		rewrite.On("img", rewrite.LazyLoad),
+		rewrite.On("video", func(el *rewrite.Element) error { return errors.New("no videos") }),
+	)
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{
+			"layouts/base.html": {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+			"views/home.html":   {Data: []byte(`{{define "page:main"}}<img src="{{.Src}}">{{end}}`)},
+			"views/video.html":  {Data: []byte(`{{define "page:main"}}<video></video>{{end}}`)},
+		}},
+		RenderMiddleware: []hyperview.RenderMiddleware{rewriter.Middleware},
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		resp       *response.Response
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "HTML", resp: response.NewResponse().Path("home").AddDataItem("Src", "/a.png"),
+			wantStatus: http.StatusOK, wantBody: `<img src="/a.png" loading="lazy">`},
+		{name: "other content types", resp: response.NewResponse().Path("home").AddDataItem("Src", "/a.png").Header("Content-Type", "text/plain"),
+			wantStatus: http.StatusOK, wantBody: `<img src="/a.png">`},
+		{name: "failed rewrite", resp: response.NewResponse().Path("video"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			adapter.Render(w, httptest.NewRequest(http.MethodGet, "/", nil), tt.resp.Layout("base"))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Errorf("expected body %s, got %s", tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package rewrite
+
+import (
+	"context"
+	"strings"
+)
+
+// LazyLoad defers the loading of images and iframes without a loading attribute until they near the viewport, with
+// loading="lazy".
+//
+//	rewrite.On("img", rewrite.LazyLoad)
+func LazyLoad(el *Element) error {
+	if _, ok := el.Attr("loading"); !ok {
+		el.SetAttr("loading", "lazy")
+	}
+	return nil
+}
+
+// CDN returns a handler serving assets from a CDN, prefixing the root-relative URLs of the src, href, poster and
+// srcset attributes starting with one of the prefixes, such as "/assets/", with the base URL of the CDN. Without
+// prefixes, all the root-relative URLs are rewritten. Protocol-relative URLs, such as //example.com/app.js, are left
+// as is.
+//
+//	rewrite.On("*", rewrite.CDN("https://cdn.example.com", "/assets/", "/images/"))
+func CDN(base string, prefixes ...string) Handler {
+	base = strings.TrimSuffix(base, "/")
+	rewriteURL := func(u string) string {
+		if !strings.HasPrefix(u, "/") || strings.HasPrefix(u, "//") {
+			return u
+		}
+		if len(prefixes) == 0 {
+			return base + u
+		}
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(u, prefix) {
+				return base + u
+			}
+		}
+		return u
+	}
+
+	return func(el *Element) error {
+		for _, name := range []string{"src", "href", "poster"} {
+			if u, ok := el.Attr(name); ok {
+				if rewritten := rewriteURL(u); rewritten != u {
+					el.SetAttr(name, rewritten)
+				}
+			}
+		}
+		if srcset, ok := el.Attr("srcset"); ok {
+			candidates, changed := strings.Split(srcset, ","), false
+			for i, candidate := range candidates {
+				fields := strings.Fields(candidate)
+				if len(fields) > 0 && rewriteURL(fields[0]) != fields[0] {
+					fields[0], changed = rewriteURL(fields[0]), true
+					candidates[i] = strings.Join(fields, " ")
+				}
+			}
+			if changed {
+				el.SetAttr("srcset", strings.Join(candidates, ","))
+			}
+		}
+		return nil
+	}
+}
+
+// Nonce returns a handler adding the nonce of the context, such as the nonce of the csp package, to the elements
+// without a nonce attribute. Elements are left as is when the context has no nonce.
+//
+//	rewrite.On("script", rewrite.Nonce(csp.NonceFromContext)),
+//	rewrite.On("style", rewrite.Nonce(csp.NonceFromContext)),
+func Nonce(nonce func(ctx context.Context) string) Handler {
+	return func(el *Element) error {
+		if _, ok := el.Attr("nonce"); ok {
+			return nil
+		}
+		if value := nonce(el.Context()); value != "" {
+			el.SetAttr("nonce", value)
+		}
+		return nil
+	}
+}
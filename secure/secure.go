@@ -0,0 +1,125 @@
+// Package secure sets security headers on the pages rendered by a template adapter: X-Frame-Options,
+// Referrer-Policy, X-Content-Type-Options, Permissions-Policy and Cross-Origin-Opener-Policy, with sane defaults,
+// and optionally Strict-Transport-Security and a Content-Security-Policy using the nonce of the csp package.
+//
+// The headers are set by a render middleware, so they apply to the HTML pages rendered through the adapter, including
+// the error pages, rather than to every response:
+//
+//	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+//		RenderMiddleware: []hyperview.RenderMiddleware{secure.Headers(secure.Options{
+//			ContentSecurityPolicy: csp.DefaultPolicy,
+//		})},
+//		RequestFuncs: csp.Funcs(""),
+//	})
+//	http.ListenAndServe(":8080", csp.Middleware(csp.Options{SkipHeader: true})(mux))
+package secure
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/csp"
+	"github.com/hypergopher/hyperview/response"
+)
+
+// Omit omits a header which has a default value.
+const Omit = "-"
+
+const (
+	// DefaultFrameOptions forbids framing the pages, protecting them from clickjacking.
+	DefaultFrameOptions = "DENY"
+	// DefaultReferrerPolicy sends the origin only to other origins, and nothing over downgraded connections.
+	DefaultReferrerPolicy = "strict-origin-when-cross-origin"
+	// DefaultPermissionsPolicy disables the powerful features pages rarely use.
+	DefaultPermissionsPolicy = "camera=(), microphone=(), geolocation=(), payment=(), usb=(), interest-cohort=()"
+	// DefaultCrossOriginOpenerPolicy isolates the pages from the windows of other origins.
+	DefaultCrossOriginOpenerPolicy = "same-origin"
+)
+
+// Options are the options of the security headers. Empty values are the defaults, and Omit omits a header.
+type Options struct {
+	// FrameOptions is the X-Frame-Options header. Default is DefaultFrameOptions.
+	FrameOptions string
+	// ReferrerPolicy is the Referrer-Policy header. Default is DefaultReferrerPolicy.
+	ReferrerPolicy string
+	// PermissionsPolicy is the Permissions-Policy header. Default is DefaultPermissionsPolicy.
+	PermissionsPolicy string
+	// CrossOriginOpenerPolicy is the Cross-Origin-Opener-Policy header. Default is DefaultCrossOriginOpenerPolicy.
+	CrossOriginOpenerPolicy string
+	// StrictTransportSecurity is the Strict-Transport-Security header, e.g. "max-age=63072000; includeSubDomains".
+	// Default is none, as it must only be sent by sites served over HTTPS.
+	StrictTransportSecurity string
+	// ContentSecurityPolicy is the Content-Security-Policy header, where csp.NoncePlaceholder is replaced by the nonce
+	// of the request, generated by csp.Middleware, so the policy allows the scripts and styles the templates tag with
+	// the same nonce. Default is none, leaving the policy to csp.Middleware.
+	ContentSecurityPolicy string
+	// ReportOnly sets the policy as Content-Security-Policy-Report-Only instead.
+	ReportOnly bool
+}
+
+// header is a security header and its value.
+type header struct {
+	name  string
+	value string
+}
+
+// Headers returns the render middleware setting the security headers on the pages rendered as HTML, the responses
+// without a Content-Type header or with a text/html one. X-Content-Type-Options is always nosniff. Headers set by the
+// handler, e.g. to allow framing a widget, are kept.
+//
+// A policy with csp.NoncePlaceholder fails the renders of requests without a nonce, so a missing csp.Middleware is
+// caught rather than blocking the scripts of the pages.
+func Headers(opts Options) hyperview.RenderMiddleware {
+	headers := []header{{name: "X-Content-Type-Options", value: "nosniff"}}
+	for _, h := range []struct{ name, value, fallback string }{
+		{"X-Frame-Options", opts.FrameOptions, DefaultFrameOptions},
+		{"Referrer-Policy", opts.ReferrerPolicy, DefaultReferrerPolicy},
+		{"Permissions-Policy", opts.PermissionsPolicy, DefaultPermissionsPolicy},
+		{"Cross-Origin-Opener-Policy", opts.CrossOriginOpenerPolicy, DefaultCrossOriginOpenerPolicy},
+		{"Strict-Transport-Security", opts.StrictTransportSecurity, Omit},
+	} {
+		if h.value == "" {
+			h.value = h.fallback
+		}
+		if h.value != Omit {
+			headers = append(headers, header{name: h.name, value: h.value})
+		}
+	}
+
+	policyHeader := "Content-Security-Policy"
+	if opts.ReportOnly {
+		policyHeader = "Content-Security-Policy-Report-Only"
+	}
+
+	return func(next hyperview.RenderFunc) hyperview.RenderFunc {
+		return func(r *http.Request, resp *response.Response) ([]byte, error) {
+			if !resp.IsHTML() {
+				return next(r, resp)
+			}
+
+			set := resp.Headers()
+			for _, h := range headers {
+				if _, ok := set[h.name]; !ok {
+					resp.Header(h.name, h.value)
+				}
+			}
+
+			if policy := opts.ContentSecurityPolicy; policy != "" {
+				if _, ok := set[policyHeader]; !ok {
+					if strings.Contains(policy, csp.NoncePlaceholder) {
+						nonce := csp.NonceFromContext(r.Context())
+						if nonce == "" {
+							return nil, fmt.Errorf("content security policy of %s needs a nonce: use csp.Middleware", resp.TemplatePath())
+						}
+						policy = strings.ReplaceAll(policy, csp.NoncePlaceholder, nonce)
+					}
+					resp.Header(policyHeader, policy)
+				}
+			}
+
+			return next(r, resp)
+		}
+	}
+}
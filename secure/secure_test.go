@@ -0,0 +1,172 @@
+package secure_test
+
+import (
+	"html/template"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/csp"
+	"github.com/hypergopher/hyperview/rendercache"
+	"github.com/hypergopher/hyperview/response"
+	"github.com/hypergopher/hyperview/secure"
+)
+
+func newSecureTestAdapter(t *testing.T, opts secure.Options) *hyperview.TemplateAdapter {
+	t.Helper()
+	return newSecureTestAdapterWithCache(t, opts, nil)
+}
+
+func newSecureTestAdapterWithCache(t *testing.T, opts secure.Options, cache rendercache.Store) *hyperview.TemplateAdapter {
+	t.Helper()
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{
+			"layouts/base.html": {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+			"views/home.html":   {Data: []byte(`{{define "page:main"}}<script nonce="{{cspNonce}}"></script>{{end}}`)},
+		}},
+		RequestFuncs:     csp.Funcs(""),
+		RenderMiddleware: []hyperview.RenderMiddleware{secure.Headers(opts)},
+		RenderCache:      cache,
+		Logger:           slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+	return adapter
+}
+
+func TestHeaders(t *testing.T) {
+	tests := []struct {
+		name string
+		opts secure.Options
+		resp *response.Response
+		want map[string]string
+	}{
+		{
+			name: "defaults",
+			resp: response.NewResponse(),
+			want: map[string]string{
+				"X-Content-Type-Options":     "nosniff",
+				"X-Frame-Options":            secure.DefaultFrameOptions,
+				"Referrer-Policy":            secure.DefaultReferrerPolicy,
+				"Permissions-Policy":         secure.DefaultPermissionsPolicy,
+				"Cross-Origin-Opener-Policy": secure.DefaultCrossOriginOpenerPolicy,
+				"Strict-Transport-Security":  "",
+			},
+		},
+		{
+			name: "custom and omitted headers",
+			opts: secure.Options{
+				FrameOptions:            "SAMEORIGIN",
+				PermissionsPolicy:       secure.Omit,
+				StrictTransportSecurity: "max-age=63072000",
+			},
+			resp: response.NewResponse(),
+			want: map[string]string{
+				"X-Frame-Options":           "SAMEORIGIN",
+				"Permissions-Policy":        "",
+				"Strict-Transport-Security": "max-age=63072000",
+			},
+		},
+		{
+			name: "headers set by the handler are kept",
+			resp: response.NewResponse().Header("X-Frame-Options", "SAMEORIGIN"),
+			want: map[string]string{"X-Frame-Options": "SAMEORIGIN", "X-Content-Type-Options": "nosniff"},
+		},
+		{
+			name: "responses other than HTML are left alone",
+			resp: response.NewResponse().Header("Content-Type", "text/plain"),
+			want: map[string]string{"X-Frame-Options": "", "X-Content-Type-Options": ""},
+		},
+		{
+			name: "HTML content types with parameters",
+			resp: response.NewResponse().Header("Content-Type", "text/html; charset=utf-8"),
+			want: map[string]string{"X-Frame-Options": secure.DefaultFrameOptions},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := newSecureTestAdapter(t, tt.opts)
+			w := httptest.NewRecorder()
+			adapter.Render(w, httptest.NewRequest(http.MethodGet, "/", nil), tt.resp.Layout("base").Path("home"))
+
+			for name, want := range tt.want {
+				if got := w.Header().Get(name); got != want {
+					t.Errorf("expected %s %q, got %q", name, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestHeaders_RenderCache(t *testing.T) {
+	adapter := newSecureTestAdapterWithCache(t, secure.Options{}, rendercache.NewLRU(10))
+
+	for i := range 2 {
+		w := httptest.NewRecorder()
+		adapter.Render(w, httptest.NewRequest(http.MethodGet, "/", nil), response.NewResponse().Layout("base").Path("home").Cache("k", 0))
+
+		for _, name := range []string{"X-Content-Type-Options", "X-Frame-Options", "Referrer-Policy"} {
+			if w.Header().Get(name) == "" {
+				t.Errorf("render %d: expected the %s header", i+1, name)
+			}
+		}
+	}
+}
+
+func TestHeaders_ContentSecurityPolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       secure.Options
+		wantHeader string
+	}{
+		{name: "enforced", opts: secure.Options{ContentSecurityPolicy: csp.DefaultPolicy}, wantHeader: "Content-Security-Policy"},
+		{name: "report only", opts: secure.Options{ContentSecurityPolicy: csp.DefaultPolicy, ReportOnly: true},
+			wantHeader: "Content-Security-Policy-Report-Only"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := newSecureTestAdapter(t, tt.opts)
+
+			var nonce string
+			handler := csp.Middleware(csp.Options{SkipHeader: true})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				nonce = csp.NonceFromContext(r.Context())
+				adapter.Render(w, r, response.NewResponse().Layout("base").Path("home"))
+			}))
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			policy := w.Header().Get(tt.wantHeader)
+			if !strings.Contains(policy, "'nonce-"+nonce+"'") {
+				t.Errorf("expected the policy to allow the nonce %q, got %q", nonce, policy)
+			}
+			if want := `<script nonce="` + template.HTMLEscapeString(nonce) + `"></script>`; w.Body.String() != want {
+				t.Errorf("expected body %q, got %q", want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHeaders_MissingNonce(t *testing.T) {
+	adapter := newSecureTestAdapter(t, secure.Options{ContentSecurityPolicy: csp.DefaultPolicy})
+
+	w := httptest.NewRecorder()
+	adapter.Render(w, httptest.NewRequest(http.MethodGet, "/", nil), response.NewResponse().Layout("base").Path("home"))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500 without a nonce, got %d", w.Code)
+	}
+	if got := w.Header().Get("Content-Security-Policy"); got != "" {
+		t.Errorf("expected no policy without a nonce, got %q", got)
+	}
+}
@@ -0,0 +1,126 @@
+package session
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// maxCookieSize is the size of the largest cookie browsers are guaranteed to keep.
+const maxCookieSize = 4096
+
+// ErrCookieTooLarge is returned when the values of a session do not fit in a cookie.
+var ErrCookieTooLarge = errors.New("session: values too large for a cookie")
+
+// CookieStoreOptions are the options of a CookieStore.
+type CookieStoreOptions struct {
+	CookieOptions
+	// Keys are the AES keys of 16, 24 or 32 bytes encrypting the cookies. The first key encrypts the cookies saved,
+	// and all decrypt the cookies loaded, so keys can be rotated by prepending the new one. At least one is required.
+	Keys [][]byte
+}
+
+// CookieStore is a Store keeping the values of the sessions in their cookie, encrypted and authenticated with
+// AES-GCM, so they can be neither read nor forged by the client. Sessions are limited to the 4 KB of a cookie, and
+// cannot be revoked before they expire, as the server keeps no state.
+type CookieStore struct {
+	opts  CookieOptions
+	aeads []cipher.AEAD
+}
+
+// cookiePayload is the content of a session cookie.
+type cookiePayload struct {
+	Values  Values `json:"v"`
+	Expires int64  `json:"e"`
+}
+
+// NewCookieStore creates a new CookieStore. It returns an error without keys or with a key of an invalid size.
+func NewCookieStore(opts CookieStoreOptions) (*CookieStore, error) {
+	if len(opts.Keys) == 0 {
+		return nil, errors.New("session: CookieStore requires a key")
+	}
+
+	store := &CookieStore{opts: opts.CookieOptions.withDefaults()}
+	for i, key := range opts.Keys {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("session: invalid key %d: %w", i, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("session: invalid key %d: %w", i, err)
+		}
+		store.aeads = append(store.aeads, aead)
+	}
+	return store, nil
+}
+
+// Load returns the values of the session cookie. Cookies which are missing, expired, tampered with or encrypted with
+// an unknown key are empty sessions.
+func (s *CookieStore) Load(r *http.Request) (Values, error) {
+	cookie, err := r.Cookie(s.opts.Name)
+	if err != nil || cookie.Value == "" {
+		return Values{}, nil
+	}
+
+	sealed, err := base64.RawURLEncoding.DecodeString(cookie.Value)
+	if err != nil {
+		return Values{}, nil
+	}
+
+	for _, aead := range s.aeads {
+		if len(sealed) < aead.NonceSize() {
+			continue
+		}
+		nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+		plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(s.opts.Name))
+		if err != nil {
+			continue
+		}
+
+		var payload cookiePayload
+		if err := json.Unmarshal(plaintext, &payload); err != nil {
+			return Values{}, fmt.Errorf("error decoding session: %w", err)
+		}
+		if time.Now().Unix() >= payload.Expires {
+			return Values{}, nil
+		}
+		return payload.Values, nil
+	}
+	return Values{}, nil
+}
+
+// Save sets the session cookie holding the values, or deletes it with empty values. It returns ErrCookieTooLarge when
+// the values do not fit in a cookie.
+func (s *CookieStore) Save(w http.ResponseWriter, r *http.Request, values Values) error {
+	if values.IsEmpty() {
+		if _, err := r.Cookie(s.opts.Name); err == nil {
+			http.SetCookie(w, s.opts.cookie(""))
+		}
+		return nil
+	}
+
+	plaintext, err := json.Marshal(cookiePayload{Values: values, Expires: time.Now().Add(s.opts.MaxAge).Unix()})
+	if err != nil {
+		return fmt.Errorf("error encoding session: %w", err)
+	}
+
+	aead := s.aeads[0]
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("error generating session nonce: %w", err)
+	}
+
+	cookie := s.opts.cookie(base64.RawURLEncoding.EncodeToString(aead.Seal(nonce, nonce, plaintext, []byte(s.opts.Name))))
+	if len(cookie.String()) > maxCookieSize {
+		return ErrCookieTooLarge
+	}
+	http.SetCookie(w, cookie)
+	return nil
+}
@@ -0,0 +1,92 @@
+package session
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// MemoryStore is a Store keeping the values of the sessions in memory, behind a random ID held by their cookie.
+// Sessions are lost when the process exits and are not shared between processes, so it is meant for development and
+// tests, and as an example of server-side stores.
+type MemoryStore struct {
+	opts     CookieOptions
+	mu       sync.Mutex
+	sessions map[string]memorySession
+}
+
+// memorySession is a session kept by a MemoryStore.
+type memorySession struct {
+	values  Values
+	expires time.Time
+}
+
+// NewMemoryStore creates a new MemoryStore.
+func NewMemoryStore(opts CookieOptions) *MemoryStore {
+	return &MemoryStore{opts: opts.withDefaults(), sessions: make(map[string]memorySession)}
+}
+
+// Load returns the values of the session whose ID the cookie holds. Unknown and expired sessions are empty.
+func (s *MemoryStore) Load(r *http.Request) (Values, error) {
+	cookie, err := r.Cookie(s.opts.Name)
+	if err != nil {
+		return Values{}, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[cookie.Value]
+	if !ok {
+		return Values{}, nil
+	}
+	if !time.Now().Before(session.expires) {
+		delete(s.sessions, cookie.Value)
+		return Values{}, nil
+	}
+	return session.values.clone(), nil
+}
+
+// Save keeps the values under the ID of the session cookie, issuing a new ID for new sessions and those renewing their
+// ID, whose old ID is forgotten, and forgets the session with empty values. Expired sessions are swept on the way.
+func (s *MemoryStore) Save(w http.ResponseWriter, r *http.Request, values Values) error {
+	var id string
+	if cookie, err := r.Cookie(s.opts.Name); err == nil {
+		id = cookie.Value
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for key, session := range s.sessions {
+		if !now.Before(session.expires) {
+			delete(s.sessions, key)
+		}
+	}
+
+	if values.IsEmpty() {
+		if id != "" {
+			delete(s.sessions, id)
+			http.SetCookie(w, s.opts.cookie(""))
+		}
+		return nil
+	}
+
+	if _, ok := s.sessions[id]; !ok || values.RenewID {
+		delete(s.sessions, id)
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			return fmt.Errorf("error generating session ID: %w", err)
+		}
+		id = base64.RawURLEncoding.EncodeToString(b)
+	}
+
+	values = values.clone()
+	values.RenewID = false
+	s.sessions[id] = memorySession{values: values, expires: now.Add(s.opts.MaxAge)}
+	http.SetCookie(w, s.opts.cookie(id))
+	return nil
+}
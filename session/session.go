@@ -0,0 +1,410 @@
+// Package session keeps per-visitor values, such as the signed-in user or a cart, and the flash messages shown on the
+// next page, across requests.
+//
+// The middleware loads the session of each request from a Store, makes it available to handlers with FromContext and
+// to templates with the session function and the .View.Session slot, and saves it when it changed. Two stores are
+// included: CookieStore, keeping the values encrypted in the cookie itself, and MemoryStore, keeping them in memory
+// behind a random ID, for development and tests. Other stores, e.g. backed by a database, implement Store.
+//
+//	<p>Theme: {{session "theme"}}</p>
+//	{{template "@flashes" .View}}
+package session
+
+import (
+	"bufio"
+	"context"
+	"html/template"
+	"log/slog"
+	"maps"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/response"
+)
+
+const (
+	// DefaultCookieName is the default name of the session cookie.
+	DefaultCookieName = "session"
+	// DefaultMaxAge is the default lifetime of a session.
+	DefaultMaxAge = 7 * 24 * time.Hour
+)
+
+// Values are the values and pending flash messages of a session, as loaded and saved by a Store.
+type Values struct {
+	// Data are the values of the session, by key.
+	Data map[string]any `json:"data,omitempty"`
+	// Flashes are the flash messages to show on the next page.
+	Flashes []response.Flash `json:"flashes,omitempty"`
+	// RenewID asks the store to save the session under a new ID and to forget the old one, set by Session.RenewID.
+	// Stores keeping the values in the cookie, such as CookieStore, have no ID to renew and ignore it.
+	RenewID bool `json:"-"`
+}
+
+// IsEmpty reports whether there are no values nor flash messages, in which case stores forget the session.
+func (v Values) IsEmpty() bool {
+	return len(v.Data) == 0 && len(v.Flashes) == 0
+}
+
+// clone returns a copy of the values.
+func (v Values) clone() Values {
+	return Values{Data: maps.Clone(v.Data), Flashes: append([]response.Flash(nil), v.Flashes...), RenewID: v.RenewID}
+}
+
+// Store loads and saves sessions.
+type Store interface {
+	// Load returns the values of the session of the request, empty values if it has none or it expired.
+	Load(r *http.Request) (Values, error)
+	// Save saves the values of the session of the request, setting its cookie on w. Saving empty values forgets the
+	// session.
+	Save(w http.ResponseWriter, r *http.Request, values Values) error
+}
+
+// CookieOptions are the options of the session cookie.
+type CookieOptions struct {
+	// Name is the name of the cookie. Default is DefaultCookieName.
+	Name string
+	// Path is the path of the cookie. Default is "/".
+	Path string
+	// Domain is the domain of the cookie. Default is the host of the request.
+	Domain string
+	// MaxAge is the lifetime of the session. Default is DefaultMaxAge.
+	MaxAge time.Duration
+	// Secure sets the Secure attribute of the cookie. It should be enabled in production.
+	Secure bool
+	// SameSite is the SameSite attribute of the cookie. Default is http.SameSiteLaxMode.
+	SameSite http.SameSite
+}
+
+// withDefaults returns the options with the defaults of the empty fields.
+func (o CookieOptions) withDefaults() CookieOptions {
+	if o.Name == "" {
+		o.Name = DefaultCookieName
+	}
+	if o.Path == "" {
+		o.Path = "/"
+	}
+	if o.MaxAge <= 0 {
+		o.MaxAge = DefaultMaxAge
+	}
+	if o.SameSite == 0 {
+		o.SameSite = http.SameSiteLaxMode
+	}
+	return o
+}
+
+// cookie returns the session cookie holding value, or deleting the cookie with an empty value.
+func (o CookieOptions) cookie(value string) *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     o.Name,
+		Value:    value,
+		Path:     o.Path,
+		Domain:   o.Domain,
+		MaxAge:   int(o.MaxAge / time.Second),
+		Secure:   o.Secure,
+		HttpOnly: true,
+		SameSite: o.SameSite,
+	}
+	if value == "" {
+		cookie.MaxAge = -1
+	}
+	return cookie
+}
+
+// Session is the session of a request. It is safe for concurrent use. Calling the methods reading a nil Session
+// reports it as empty.
+type Session struct {
+	mu      sync.Mutex
+	values  Values
+	changed bool
+
+	shown  []response.Flash // flash messages popped for the pages of the request
+	popped bool
+}
+
+// New creates a session holding values.
+func New(values Values) *Session {
+	return &Session{values: values.clone()}
+}
+
+type contextKey struct{}
+
+// FromContext returns the session carried by ctx, or nil if there is none.
+func FromContext(ctx context.Context) *Session {
+	s, _ := ctx.Value(contextKey{}).(*Session)
+	return s
+}
+
+// Get returns the value stored under key, or nil.
+func (s *Session) Get(key string) any {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.values.Data[key]
+}
+
+// Set stores value under key. Values must survive the encoding of the store, JSON for the stores of this package, so
+// they are best kept to strings, numbers, booleans and maps and slices of them.
+func (s *Session) Set(key string, value any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.values.Data == nil {
+		s.values.Data = make(map[string]any)
+	}
+	s.values.Data[key] = value
+	s.changed = true
+}
+
+// Delete deletes the value stored under key.
+func (s *Session) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.values.Data[key]; ok {
+		delete(s.values.Data, key)
+		s.changed = true
+	}
+}
+
+// Clear deletes the values and flash messages of the session, e.g. when the user signs out.
+func (s *Session) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values = Values{}
+	s.changed = true
+}
+
+// RenewID saves the session under a new ID, forgetting the old one, keeping its values. Call it when the privileges
+// of the session change, such as when the user signs in or out, so an ID planted by an attacker before, e.g. with a
+// crafted link or cookie, does not become a signed-in session:
+//
+//	s.RenewID()
+//	s.Set("user", user.ID)
+func (s *Session) RenewID() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values.RenewID = true
+	s.changed = true
+}
+
+// AddFlash adds a flash message shown on the next page rendered for the session, e.g. after redirecting a form post.
+func (s *Session) AddFlash(kind, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values.Flashes = append(s.values.Flashes, response.Flash{Kind: kind, Message: message})
+	s.changed = true
+}
+
+// PopFlashes returns the pending flash messages and removes them from the session.
+func (s *Session) PopFlashes() []response.Flash {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	flashes := s.values.Flashes
+	if len(flashes) > 0 {
+		s.values.Flashes = nil
+		s.changed = true
+	}
+	return flashes
+}
+
+// RequestFlashes returns the flash messages shown by the request, popping the pending ones from the session on the
+// first call, so every render of the request shows the same messages. The view data calls it when its Flashes slot is
+// read, so requests rendering no flashes, such as those of assets or fetch calls, leave them for the next page.
+func (s *Session) RequestFlashes() []response.Flash {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.popLocked()
+	return s.shown
+}
+
+// popLocked pops the pending flash messages into those shown by the request, once. s.mu must be held.
+func (s *Session) popLocked() {
+	if s.popped {
+		return
+	}
+	s.popped = true
+	if len(s.values.Flashes) > 0 {
+		s.shown = s.values.Flashes
+		s.values.Flashes = nil
+		s.changed = true
+	}
+}
+
+// Values returns a copy of the values of the session.
+func (s *Session) Values() Values {
+	if s == nil {
+		return Values{}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.values.clone()
+}
+
+// save saves the session with store if it changed since it was loaded or last saved.
+func (s *Session) save(w http.ResponseWriter, r *http.Request, store Store) error {
+	s.mu.Lock()
+	if !s.changed {
+		s.mu.Unlock()
+		return nil
+	}
+	values := s.values.clone()
+	s.changed = false
+	s.values.RenewID = false
+	s.mu.Unlock()
+
+	return store.Save(w, r, values)
+}
+
+// Options are the options for the session middleware.
+type Options struct {
+	// Store loads and saves the sessions. It is required.
+	Store Store
+	// ErrorHandler is called with the errors loading and saving sessions, which do not fail the request: a session
+	// failing to load is empty. Default logs the errors with slog.
+	ErrorHandler func(r *http.Request, err error)
+}
+
+// Middleware returns a middleware loading the session of each request and saving it, when it changed, before the
+// response is written. The session is added to the request context, along with the template functions bound to it
+// (see Funcs), and is the Session slot of the view data.
+//
+// The flash messages of the session are the Flashes slot of the view data, so the @flashes partial shows them on the
+// page a form post redirects to. They are popped when the slot is first read, and by the GET requests answered with a
+// page, HTML other than the fragments of HTMX requests, whose session is saved before they are read, such as streamed
+// pages showing them after the first flush.
+//
+// Changes made to the session after the response started are not saved.
+func Middleware(opts Options) func(http.Handler) http.Handler {
+	if opts.Store == nil {
+		panic("session: Middleware requires a Store")
+	}
+	if opts.ErrorHandler == nil {
+		opts.ErrorHandler = func(r *http.Request, err error) {
+			slog.ErrorContext(r.Context(), "session error", slog.String("path", r.URL.Path), slog.Any("error", err))
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			values, err := opts.Store.Load(r)
+			if err != nil {
+				opts.ErrorHandler(r, err)
+				values = Values{}
+			}
+			s := New(values)
+
+			ctx := context.WithValue(r.Context(), contextKey{}, s)
+			ctx = hyperview.ContextWithFuncs(ctx, Funcs(s))
+			ctx = response.ContextWithSession(ctx, s)
+			r = r.WithContext(ctx)
+
+			sw := &sessionWriter{ResponseWriter: w}
+			sw.save = func() {
+				if showsPage(w, r, sw.status) {
+					s.mu.Lock()
+					s.popLocked()
+					s.mu.Unlock()
+				}
+				if err := s.save(w, r, opts.Store); err != nil {
+					opts.ErrorHandler(r, err)
+				}
+			}
+
+			next.ServeHTTP(sw, r)
+			sw.commit()
+		})
+	}
+}
+
+// showsPage reports whether the response to the request is a page, which shows the flash messages: the HTML answering
+// a GET request with a status other than a redirect, excluding the fragments of HTMX requests, boosted navigations
+// being pages.
+func showsPage(w http.ResponseWriter, r *http.Request, status int) bool {
+	if r.Method != http.MethodGet || status >= http.StatusMultipleChoices ||
+		!strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") {
+		return false
+	}
+	return r.Header.Get("HX-Request") == "" || r.Header.Get("HX-Boosted") != ""
+}
+
+// Funcs returns the template functions reading the given session:
+//
+//   - session: returns the value stored under a key, or nil, e.g. {{with session "theme"}}{{.}}{{end}}
+//
+// Pass Funcs(nil) to the template adapter's RequestFuncs option, and use Middleware to bind them to the session of
+// each request.
+func Funcs(s *Session) template.FuncMap {
+	return template.FuncMap{
+		"session": func(key string) any {
+			return s.Get(key)
+		},
+	}
+}
+
+// sessionWriter saves the session before the response is written.
+type sessionWriter struct {
+	http.ResponseWriter
+	save      func()
+	status    int // status of the response, once its header is written
+	committed bool
+}
+
+// commit saves the session, once.
+func (w *sessionWriter) commit() {
+	if !w.committed {
+		w.committed = true
+		w.save()
+	}
+}
+
+// WriteHeader saves the session, then writes the header. Informational responses, such as 103 Early Hints, are
+// written as is.
+func (w *sessionWriter) WriteHeader(code int) {
+	if code >= http.StatusOK {
+		if !w.committed {
+			w.status = code
+		}
+		w.commit()
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write saves the session, then writes the body.
+func (w *sessionWriter) Write(b []byte) (int, error) {
+	w.commit()
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush saves the session, then flushes the response.
+func (w *sessionWriter) Flush() {
+	w.commit()
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack saves the session, then hijacks the connection, e.g. to upgrade it to a WebSocket. The cookie of the session
+// is only sent if the new owner of the connection writes the header.
+func (w *sessionWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.commit()
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+// Unwrap returns the wrapped writer, for http.ResponseController.
+func (w *sessionWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,298 @@
+package session_test
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/response"
+	"github.com/hypergopher/hyperview/session"
+)
+
+var testKey = bytes.Repeat([]byte("k"), 32)
+
+func newCookieStore(t *testing.T, keys ...[]byte) *session.CookieStore {
+	t.Helper()
+	store, err := session.NewCookieStore(session.CookieStoreOptions{Keys: keys})
+	if err != nil {
+		t.Fatalf("error creating cookie store: %v", err)
+	}
+	return store
+}
+
+// newSessionTestHandler returns a handler saving a theme and a flash on POST, then redirecting to the page showing
+// them.
+func newSessionTestHandler(t *testing.T, store session.Store) http.Handler {
+	t.Helper()
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{
+			"layouts/base.html": {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+			"views/home.html": {Data: []byte(`{{define "page:main"}}{{session "theme"}}|{{.View.Session "theme"}}|` +
+				`{{range .View.Flashes}}{{.Kind}}:{{.Message}}{{end}}{{end}}`)},
+		}},
+		RequestFuncs: session.Funcs(nil),
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /theme", func(w http.ResponseWriter, r *http.Request) {
+		s := session.FromContext(r.Context())
+		s.Set("theme", r.FormValue("theme"))
+		s.AddFlash("success", "Saved")
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+	})
+	mux.HandleFunc("POST /signin", func(w http.ResponseWriter, r *http.Request) {
+		s := session.FromContext(r.Context())
+		s.RenewID()
+		s.Set("user", "ann")
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("GET /api/status", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	})
+	mux.HandleFunc("GET /fragment", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<p>fragment</p>`))
+	})
+	mux.HandleFunc("POST /signout", func(w http.ResponseWriter, r *http.Request) {
+		session.FromContext(r.Context()).Clear()
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("GET /", func(w http.ResponseWriter, r *http.Request) {
+		adapter.Render(w, r, response.NewResponse().Layout("base").Path("home"))
+	})
+	return session.Middleware(session.Options{Store: store})(mux)
+}
+
+// serve serves the request with the cookies, returning the response and the cookies after it.
+func serve(handler http.Handler, req *http.Request, cookies []*http.Cookie) (*httptest.ResponseRecorder, []*http.Cookie) {
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	jar := make(map[string]*http.Cookie)
+	for _, c := range cookies {
+		jar[c.Name] = c
+	}
+	for _, c := range w.Result().Cookies() {
+		if c.MaxAge < 0 {
+			delete(jar, c.Name)
+		} else {
+			jar[c.Name] = c
+		}
+	}
+	cookies = nil
+	for _, c := range jar {
+		cookies = append(cookies, c)
+	}
+	return w, cookies
+}
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name  string
+		store session.Store
+	}{
+		{name: "cookie store", store: newCookieStore(t, testKey)},
+		{name: "memory store", store: session.NewMemoryStore(session.CookieOptions{})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := newSessionTestHandler(t, tt.store)
+
+			w, cookies := serve(handler, httptest.NewRequest(http.MethodGet, "/", nil), nil)
+			if got := w.Body.String(); got != "||" {
+				t.Errorf("expected an empty session, got %q", got)
+			}
+			if len(cookies) != 0 {
+				t.Errorf("expected no cookie for an unchanged session, got %v", cookies)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/theme", strings.NewReader("theme=dark"))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			_, cookies = serve(handler, req, cookies)
+			if len(cookies) != 1 {
+				t.Fatalf("expected a session cookie, got %v", cookies)
+			}
+			if !cookies[0].HttpOnly || cookies[0].SameSite != http.SameSiteLaxMode {
+				t.Errorf("expected an HttpOnly, SameSite=Lax cookie, got %v", cookies[0])
+			}
+
+			// Requests rendering no page leave the flash for the next page
+			_, cookies = serve(handler, httptest.NewRequest(http.MethodGet, "/api/status", nil), cookies)
+			req = httptest.NewRequest(http.MethodGet, "/fragment", nil)
+			req.Header.Set("HX-Request", "true")
+			_, cookies = serve(handler, req, cookies)
+
+			w, cookies = serve(handler, httptest.NewRequest(http.MethodGet, "/", nil), cookies)
+			if got, want := w.Body.String(), "dark|dark|success:Saved"; got != want {
+				t.Errorf("expected body %q, got %q", want, got)
+			}
+
+			w, cookies = serve(handler, httptest.NewRequest(http.MethodGet, "/", nil), cookies)
+			if got, want := w.Body.String(), "dark|dark|"; got != want {
+				t.Errorf("expected the flash to be shown once, got %q", got)
+			}
+
+			_, cookies = serve(handler, httptest.NewRequest(http.MethodPost, "/signout", nil), cookies)
+			if len(cookies) != 0 {
+				t.Errorf("expected the cleared session cookie to be deleted, got %v", cookies)
+			}
+		})
+	}
+}
+
+func TestMiddleware_LoadError(t *testing.T) {
+	var handled error
+	handler := session.Middleware(session.Options{
+		Store:        failingStore{},
+		ErrorHandler: func(r *http.Request, err error) { handled = err },
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := session.FromContext(r.Context()).Get("theme"); got != nil {
+			t.Errorf("expected an empty session, got %v", got)
+		}
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if !errors.Is(handled, errStore) {
+		t.Errorf("expected the load error to be handled, got %v", handled)
+	}
+}
+
+func TestMemoryStore_RenewID(t *testing.T) {
+	store := session.NewMemoryStore(session.CookieOptions{})
+	handler := newSessionTestHandler(t, store)
+
+	req := httptest.NewRequest(http.MethodPost, "/theme", strings.NewReader("theme=dark"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	_, before := serve(handler, req, nil)
+	_, after := serve(handler, httptest.NewRequest(http.MethodPost, "/signin", nil), before)
+	if len(before) != 1 || len(after) != 1 || before[0].Value == after[0].Value {
+		t.Fatalf("expected the session ID to change on sign in, got %v then %v", before, after)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(after[0])
+	if values, _ := store.Load(req); values.Data["user"] != "ann" || values.Data["theme"] != "dark" {
+		t.Errorf("expected the values under the new ID, got %v", values)
+	}
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(before[0])
+	if values, _ := store.Load(req); !values.IsEmpty() {
+		t.Errorf("expected the old ID to be forgotten, got %v", values)
+	}
+}
+
+func TestMiddleware_Hijack(t *testing.T) {
+	server := httptest.NewServer(session.Middleware(session.Options{Store: session.NewMemoryStore(session.CookieOptions{})})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hijacker, ok := w.(http.Hijacker)
+			if !ok {
+				t.Error("expected the session writer to implement http.Hijacker")
+				return
+			}
+			conn, buf, err := hijacker.Hijack()
+			if err != nil {
+				t.Errorf("error hijacking the connection: %v", err)
+				return
+			}
+			defer conn.Close()
+			_, _ = buf.WriteString("HTTP/1.1 204 No Content\r\nConnection: close\r\n\r\n")
+			_ = buf.Flush()
+		})))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("expected the response written over the hijacked connection, got %d", resp.StatusCode)
+	}
+}
+
+var errStore = errors.New("store unavailable")
+
+type failingStore struct{}
+
+func (failingStore) Load(*http.Request) (session.Values, error) { return session.Values{}, errStore }
+
+func (failingStore) Save(http.ResponseWriter, *http.Request, session.Values) error { return errStore }
+
+func TestCookieStore(t *testing.T) {
+	saved := func(t *testing.T, store *session.CookieStore, values session.Values) *http.Cookie {
+		t.Helper()
+		w := httptest.NewRecorder()
+		if err := store.Save(w, httptest.NewRequest(http.MethodGet, "/", nil), values); err != nil {
+			t.Fatalf("error saving session: %v", err)
+		}
+		return w.Result().Cookies()[0]
+	}
+	values := session.Values{Data: map[string]any{"theme": "dark"}}
+	oldKey := bytes.Repeat([]byte("o"), 32)
+
+	tests := []struct {
+		name   string
+		cookie func(t *testing.T) *http.Cookie
+		keys   [][]byte
+		want   any
+	}{
+		{name: "saved cookie", cookie: func(t *testing.T) *http.Cookie { return saved(t, newCookieStore(t, testKey), values) },
+			keys: [][]byte{testKey}, want: "dark"},
+		{name: "cookie of a rotated key", cookie: func(t *testing.T) *http.Cookie { return saved(t, newCookieStore(t, oldKey), values) },
+			keys: [][]byte{testKey, oldKey}, want: "dark"},
+		{name: "cookie of an unknown key", cookie: func(t *testing.T) *http.Cookie { return saved(t, newCookieStore(t, oldKey), values) },
+			keys: [][]byte{testKey}, want: nil},
+		{name: "tampered cookie", cookie: func(t *testing.T) *http.Cookie {
+			c := saved(t, newCookieStore(t, testKey), values)
+			c.Value = c.Value[:len(c.Value)-2] + "AA"
+			return c
+		}, keys: [][]byte{testKey}, want: nil},
+		{name: "garbage cookie", cookie: func(t *testing.T) *http.Cookie { return &http.Cookie{Name: "session", Value: "!!"} },
+			keys: [][]byte{testKey}, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.AddCookie(tt.cookie(t))
+
+			loaded, err := newCookieStore(t, tt.keys...).Load(req)
+			if err != nil {
+				t.Fatalf("error loading session: %v", err)
+			}
+			if got := loaded.Data["theme"]; got != tt.want {
+				t.Errorf("expected theme %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCookieStore_Errors(t *testing.T) {
+	if _, err := session.NewCookieStore(session.CookieStoreOptions{}); err == nil {
+		t.Error("expected an error without keys")
+	}
+	if _, err := session.NewCookieStore(session.CookieStoreOptions{Keys: [][]byte{[]byte("short")}}); err == nil {
+		t.Error("expected an error for a key of an invalid size")
+	}
+
+	large := session.Values{Data: map[string]any{"blob": strings.Repeat("x", 5000)}}
+	err := newCookieStore(t, testKey).Save(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil), large)
+	if !errors.Is(err, session.ErrCookieTooLarge) {
+		t.Errorf("expected ErrCookieTooLarge, got %v", err)
+	}
+}
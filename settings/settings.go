@@ -0,0 +1,230 @@
+// Package settings exposes application settings, such as CMS-style editable site settings, to templates.
+//
+// Settings are read from a Provider and cached for the duration of a request, so a setting used by many partials is
+// only looked up once. Templates read settings with the setting function and its typed variants:
+//
+//	<p>{{setting "site.tagline"}}</p>
+//	{{if settingBool "features.banner"}}...{{end}}
+//	{{$limit := settingInt "home.items" 3}}
+package settings
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/hypergopher/hyperview"
+)
+
+// Provider is the source of the settings.
+type Provider interface {
+	// Setting returns the value of the setting with the given key. It returns false if the setting does not exist.
+	Setting(ctx context.Context, key string) (any, bool, error)
+}
+
+// Map is a Provider backed by a map, useful for static settings and tests.
+type Map map[string]any
+
+// Setting returns the value of the setting with the given key.
+func (m Map) Setting(_ context.Context, key string) (any, bool, error) {
+	value, ok := m[key]
+	return value, ok, nil
+}
+
+type contextKey struct{}
+
+// Settings reads settings from a Provider, caching them for the lifetime of the Settings, usually a single request.
+type Settings struct {
+	ctx      context.Context
+	provider Provider
+	mu       sync.Mutex
+	cache    map[string]entry
+}
+
+type entry struct {
+	value any
+	ok    bool
+}
+
+// New creates a new Settings reading from provider with the given context.
+func New(ctx context.Context, provider Provider) *Settings {
+	return &Settings{
+		ctx:      ctx,
+		provider: provider,
+		cache:    make(map[string]entry),
+	}
+}
+
+// FromContext returns the Settings carried by ctx, or nil if there are none.
+func FromContext(ctx context.Context) *Settings {
+	s, _ := ctx.Value(contextKey{}).(*Settings)
+	return s
+}
+
+// Middleware adds the Settings of each request to the request context, along with the template functions bound to
+// them (see Funcs).
+func Middleware(provider Provider) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			s := New(r.Context(), provider)
+
+			ctx := context.WithValue(r.Context(), contextKey{}, s)
+			ctx = hyperview.ContextWithFuncs(ctx, Funcs(s))
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// Get returns the value of the setting with the given key. It returns false if the setting does not exist. Calling
+// Get on nil Settings reports every setting as missing.
+func (s *Settings) Get(key string) (any, bool, error) {
+	if s == nil {
+		return nil, false, nil
+	}
+
+	s.mu.Lock()
+	cached, ok := s.cache[key]
+	s.mu.Unlock()
+	if ok {
+		return cached.value, cached.ok, nil
+	}
+
+	value, ok, err := s.provider.Setting(s.ctx, key)
+	if err != nil {
+		return nil, false, fmt.Errorf("error reading setting %s: %w", key, err)
+	}
+
+	s.mu.Lock()
+	s.cache[key] = entry{value: value, ok: ok}
+	s.mu.Unlock()
+
+	return value, ok, nil
+}
+
+// String returns the setting with the given key as a string, or def if it does not exist.
+func (s *Settings) String(key string, def string) (string, error) {
+	value, ok, err := s.Get(key)
+	if err != nil || !ok {
+		return def, err
+	}
+
+	if str, ok := value.(string); ok {
+		return str, nil
+	}
+	return fmt.Sprint(value), nil
+}
+
+// Int returns the setting with the given key as an int, or def if it does not exist.
+func (s *Settings) Int(key string, def int) (int, error) {
+	value, ok, err := s.Get(key)
+	if err != nil || !ok {
+		return def, err
+	}
+
+	switch v := value.(type) {
+	case int:
+		return v, nil
+	case int64:
+		return int(v), nil
+	case int32:
+		return int(v), nil
+	case float64:
+		return int(v), nil
+	case string:
+		i, err := strconv.Atoi(v)
+		if err != nil {
+			return def, fmt.Errorf("setting %s is not an int: %w", key, err)
+		}
+		return i, nil
+	}
+
+	return def, fmt.Errorf("setting %s is not an int, got %T", key, value)
+}
+
+// Bool returns the setting with the given key as a bool, or def if it does not exist.
+func (s *Settings) Bool(key string, def bool) (bool, error) {
+	value, ok, err := s.Get(key)
+	if err != nil || !ok {
+		return def, err
+	}
+
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case string:
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return def, fmt.Errorf("setting %s is not a bool: %w", key, err)
+		}
+		return b, nil
+	}
+
+	return def, fmt.Errorf("setting %s is not a bool, got %T", key, value)
+}
+
+// Float returns the setting with the given key as a float64, or def if it does not exist.
+func (s *Settings) Float(key string, def float64) (float64, error) {
+	value, ok, err := s.Get(key)
+	if err != nil || !ok {
+		return def, err
+	}
+
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return def, fmt.Errorf("setting %s is not a number: %w", key, err)
+		}
+		return f, nil
+	}
+
+	return def, fmt.Errorf("setting %s is not a number, got %T", key, value)
+}
+
+// Funcs returns the template functions reading from the given Settings:
+//
+//   - setting: returns the raw value of a setting, or nil if it does not exist
+//   - settingString, settingInt, settingBool, settingFloat: return a setting converted to the type, with an optional
+//     default value used when the setting does not exist, e.g. {{settingInt "home.items" 3}}
+//
+// Pass Funcs(nil) to the template adapter's RequestFuncs option, and use Middleware to bind them to each request.
+func Funcs(s *Settings) template.FuncMap {
+	return template.FuncMap{
+		"setting": func(key string) (any, error) {
+			value, _, err := s.Get(key)
+			return value, err
+		},
+		"settingString": func(key string, def ...string) (string, error) {
+			return s.String(key, first(def))
+		},
+		"settingInt": func(key string, def ...int) (int, error) {
+			return s.Int(key, first(def))
+		},
+		"settingBool": func(key string, def ...bool) (bool, error) {
+			return s.Bool(key, first(def))
+		},
+		"settingFloat": func(key string, def ...float64) (float64, error) {
+			return s.Float(key, first(def))
+		},
+	}
+}
+
+func first[T any](values []T) T {
+	var zero T
+	if len(values) == 0 {
+		return zero
+	}
+	return values[0]
+}
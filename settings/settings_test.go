@@ -0,0 +1,114 @@
+package settings_test
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/response"
+	"github.com/hypergopher/hyperview/settings"
+)
+
+type countingProvider struct {
+	settings.Map
+	calls map[string]int
+}
+
+func (p *countingProvider) Setting(ctx context.Context, key string) (any, bool, error) {
+	p.calls[key]++
+	if key == "broken" {
+		return nil, false, errors.New("store unavailable")
+	}
+	return p.Map.Setting(ctx, key)
+}
+
+func TestSettings_TypedGetters(t *testing.T) {
+	s := settings.New(context.Background(), settings.Map{
+		"name":    "Renderfish",
+		"count":   "12",
+		"limit":   5,
+		"enabled": "true",
+		"ratio":   0.5,
+		"bad":     []string{"x"},
+	})
+
+	if got, _ := s.String("name", ""); got != "Renderfish" {
+		t.Errorf("String() = %q", got)
+	}
+	if got, _ := s.String("missing", "default"); got != "default" {
+		t.Errorf("String() missing = %q", got)
+	}
+	if got, _ := s.Int("count", 0); got != 12 {
+		t.Errorf("Int() string = %d", got)
+	}
+	if got, _ := s.Int("limit", 0); got != 5 {
+		t.Errorf("Int() = %d", got)
+	}
+	if got, _ := s.Bool("enabled", false); !got {
+		t.Errorf("Bool() = %v", got)
+	}
+	if got, _ := s.Float("ratio", 0); got != 0.5 {
+		t.Errorf("Float() = %v", got)
+	}
+	if _, err := s.Int("bad", 0); err == nil {
+		t.Error("expected an error converting a slice to an int")
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	provider := &countingProvider{
+		Map:   settings.Map{"site.tagline": "Fast <templates>", "home.items": 2, "banner": true},
+		calls: make(map[string]int),
+	}
+
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{
+			"layouts/base.html": {Data: []byte(`{{define "layout:base"}}{{setting "site.tagline"}}|{{template "page:main" .}}{{end}}`)},
+			"views/home.html": {Data: []byte(`{{define "page:main"}}{{settingString "site.tagline"}}|` +
+				`{{settingInt "home.items" 3}}|{{settingInt "missing" 3}}|{{if settingBool "banner"}}banner{{end}}{{end}}`)},
+			"views/broken.html": {Data: []byte(`{{define "page:main"}}{{setting "broken"}}{{end}}`)},
+		}},
+		RequestFuncs: settings.Funcs(nil),
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+
+	var path string
+	handler := settings.Middleware(provider)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		adapter.Render(w, r, response.NewResponse().Layout("base").Path(path))
+	}))
+
+	path = "home"
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	want := "Fast &lt;templates&gt;|Fast &lt;templates&gt;|2|3|banner"
+	if got := w.Body.String(); got != want {
+		t.Errorf("unexpected body: got %q, want %q", got, want)
+	}
+	if provider.calls["site.tagline"] != 1 {
+		t.Errorf("expected the setting to be read once per request, got %d reads", provider.calls["site.tagline"])
+	}
+
+	path = "broken"
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if !strings.Contains(w.Body.String(), "store unavailable") {
+		t.Errorf("expected the provider error, got %q", w.Body.String())
+	}
+}
+
+func TestFuncs_WithoutSettings(t *testing.T) {
+	fn := settings.Funcs(nil)["settingString"].(func(string, ...string) (string, error))
+	if got, err := fn("site.tagline", "default"); err != nil || got != "default" {
+		t.Errorf("settingString() = %q, %v", got, err)
+	}
+}
@@ -0,0 +1,143 @@
+package hyperview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// ShutdownHook is called when HyperView shuts down, for example to close live-update connections or flush a cache.
+type ShutdownHook func(ctx context.Context) error
+
+// renderTracker counts the renders in flight, so shutdown can wait for them to complete.
+type renderTracker struct {
+	mu      sync.Mutex
+	active  int
+	closing bool
+	idle    chan struct{}
+}
+
+// begin records the start of a render. It returns false once shutdown has started.
+func (t *renderTracker) begin() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closing {
+		return false
+	}
+	t.active++
+	return true
+}
+
+// end records the end of a render.
+func (t *renderTracker) end() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.active--
+	if t.active == 0 && t.idle != nil {
+		close(t.idle)
+		t.idle = nil
+	}
+}
+
+// drain stops new renders and waits for the renders in flight to complete, or for ctx to be done.
+func (t *renderTracker) drain(ctx context.Context) error {
+	t.mu.Lock()
+	t.closing = true
+	if t.active == 0 {
+		t.mu.Unlock()
+		return nil
+	}
+	if t.idle == nil {
+		t.idle = make(chan struct{})
+	}
+	idle := t.idle
+	t.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("error waiting for in-flight renders: %w", ctx.Err())
+	}
+}
+
+// OnShutdown registers a hook called by Shutdown, after the renders in flight have completed. Hooks are called in
+// the order they were registered.
+func (s *HyperView) OnShutdown(hook ShutdownHook) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.shutdownHooks = append(s.shutdownHooks, hook)
+}
+
+// Shutdown gracefully shuts down HyperView, for clean rolling deploys. New renders are rejected with 503 Service
+// Unavailable and a Retry-After header, so load balancers retry them on another instance. Once the renders in flight
+// have completed, the shutdown hooks are called, and then the Shutdown method of the adapters implementing Shutdowner,
+// once per adapter in the order of their names, such as the template adapter stopping the watchers of WatchLoaders.
+//
+// Call Shutdown after http.Server.Shutdown has stopped accepting new connections. If ctx is done before the shutdown
+// completes, Shutdown still runs the hooks and adapter shutdowns, and returns the context's error along with any
+// errors they return.
+func (s *HyperView) Shutdown(ctx context.Context) error {
+	var errs []error
+	if err := s.renders.drain(ctx); err != nil {
+		errs = append(errs, err)
+	}
+
+	s.mu.RLock()
+	hooks := append([]ShutdownHook(nil), s.shutdownHooks...)
+	names := make([]string, 0, len(s.adapters))
+	for name := range s.adapters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// An adapter registered for several extensions is shut down once
+	adapters := make([]Adapter, 0, len(names))
+	seen := make(map[Adapter]bool, len(names))
+	for _, name := range names {
+		adapter := s.adapters[name]
+		if reflect.TypeOf(adapter).Comparable() {
+			if seen[adapter] {
+				continue
+			}
+			seen[adapter] = true
+		}
+		adapters = append(adapters, adapter)
+	}
+	s.mu.RUnlock()
+
+	for _, hook := range hooks {
+		if err := hook(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, adapter := range adapters {
+		if shutdowner, ok := adapter.(Shutdowner); ok {
+			if err := shutdowner.Shutdown(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("error shutting down adapter %T: %w", adapter, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// beginRender records the start of a render, responding with 503 Service Unavailable and returning false once
+// shutdown has started. Callers must call s.renders.end when the render completes.
+func (s *HyperView) beginRender(w http.ResponseWriter) bool {
+	if s.renders.begin() {
+		return true
+	}
+
+	w.Header().Set("Retry-After", "1")
+	w.Header().Set("Connection", "close")
+	http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+	return false
+}
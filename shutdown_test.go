@@ -0,0 +1,155 @@
+package hyperview_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/response"
+)
+
+// blockingAdapter blocks renders until released, and records its shutdown.
+type blockingAdapter struct {
+	mockViewAdapter
+	started  chan struct{}
+	release  chan struct{}
+	shutdown []string
+}
+
+func (ba *blockingAdapter) Render(w http.ResponseWriter, r *http.Request, resp *response.Response) {
+	close(ba.started)
+	<-ba.release
+	w.WriteHeader(http.StatusOK)
+}
+
+func (ba *blockingAdapter) Shutdown(ctx context.Context) error {
+	ba.shutdown = append(ba.shutdown, "adapter")
+	return nil
+}
+
+func TestHyperView_Shutdown(t *testing.T) {
+	hv, err := hyperview.NewHyperView()
+	if err != nil {
+		t.Fatalf("error creating HyperView: %v", err)
+	}
+
+	adapter := &blockingAdapter{started: make(chan struct{}), release: make(chan struct{})}
+	if err := hv.RegisterAdapter("html", adapter); err != nil {
+		t.Fatalf("error registering adapter: %v", err)
+	}
+	hv.OnShutdown(func(ctx context.Context) error {
+		adapter.shutdown = append(adapter.shutdown, "hook")
+		return nil
+	})
+
+	inflight := httptest.NewRecorder()
+	rendered := make(chan struct{})
+	go func() {
+		hv.Render(inflight, httptest.NewRequest(http.MethodGet, "/", nil), response.NewResponse().Path("home"))
+		close(rendered)
+	}()
+	<-adapter.started
+
+	shutdown := make(chan error)
+	go func() {
+		shutdown <- hv.Shutdown(context.Background())
+	}()
+
+	// Wait for the shutdown to start rejecting renders
+	var rejected *httptest.ResponseRecorder
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		rejected = httptest.NewRecorder()
+		hv.RenderNotFound(rejected, httptest.NewRequest(http.MethodGet, "/", nil))
+		if rejected.Code == http.StatusServiceUnavailable {
+			break
+		}
+	}
+	if rejected.Code != http.StatusServiceUnavailable || rejected.Header().Get("Retry-After") == "" {
+		t.Fatalf("expected new renders to be rejected during shutdown, got %d", rejected.Code)
+	}
+
+	select {
+	case err := <-shutdown:
+		t.Fatalf("shutdown completed before the in-flight render: %v", err)
+	default:
+	}
+
+	close(adapter.release)
+	<-rendered
+	if err := <-shutdown; err != nil {
+		t.Fatalf("unexpected shutdown error: %v", err)
+	}
+
+	if inflight.Code != http.StatusOK {
+		t.Errorf("expected the in-flight render to complete, got %d", inflight.Code)
+	}
+	if got := strings.Join(adapter.shutdown, ","); got != "hook,adapter" {
+		t.Errorf("unexpected shutdown order: %s", got)
+	}
+}
+
+func TestHyperView_ShutdownTimeout(t *testing.T) {
+	hv, err := hyperview.NewHyperView()
+	if err != nil {
+		t.Fatalf("error creating HyperView: %v", err)
+	}
+
+	adapter := &blockingAdapter{started: make(chan struct{}), release: make(chan struct{})}
+	if err := hv.RegisterAdapter("html", adapter); err != nil {
+		t.Fatalf("error registering adapter: %v", err)
+	}
+	defer close(adapter.release)
+
+	go hv.Render(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil), response.NewResponse().Path("home"))
+	<-adapter.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := hv.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected a deadline error, got %v", err)
+	}
+	if len(adapter.shutdown) != 1 {
+		t.Error("expected the adapter to be shut down despite the timeout")
+	}
+}
+
+// namedAdapter records its shutdown under its name.
+type namedAdapter struct {
+	mockViewAdapter
+	name     string
+	shutdown *[]string
+}
+
+func (na *namedAdapter) Shutdown(ctx context.Context) error {
+	*na.shutdown = append(*na.shutdown, na.name)
+	return nil
+}
+
+func TestHyperView_ShutdownOncePerAdapter(t *testing.T) {
+	hv, err := hyperview.NewHyperView()
+	if err != nil {
+		t.Fatalf("error creating HyperView: %v", err)
+	}
+
+	var shutdown []string
+	templates := &namedAdapter{name: "templates", shutdown: &shutdown}
+	data := &namedAdapter{name: "data", shutdown: &shutdown}
+	for name, adapter := range map[string]hyperview.Adapter{"html": templates, "htm": templates, "json": data, "xml": templates} {
+		if err := hv.RegisterAdapter(name, adapter); err != nil {
+			t.Fatalf("error registering adapter: %v", err)
+		}
+	}
+
+	if err := hv.Shutdown(context.Background()); err != nil {
+		t.Fatalf("unexpected shutdown error: %v", err)
+	}
+	if got := strings.Join(shutdown, ","); got != "templates,data" {
+		t.Errorf("expected each adapter to be shut down once, in the order of their names, got %s", got)
+	}
+}
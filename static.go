@@ -0,0 +1,240 @@
+package hyperview
+
+import (
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hypergopher/hyperview/constants"
+)
+
+const (
+	// DefaultStaticPrefix is the default URL prefix of the StaticHandler.
+	DefaultStaticPrefix = "/static/"
+	// DefaultStaticCacheControl is the default Cache-Control header of static files that are not fingerprinted, which
+	// browsers revalidate with the Last-Modified header before each use.
+	DefaultStaticCacheControl = "no-cache"
+	// ImmutableCacheControl is the Cache-Control header of fingerprinted static files, whose content never changes.
+	ImmutableCacheControl = "public, max-age=31536000, immutable"
+)
+
+// fingerprinted matches file names with a content hash of at least 8 hexadecimal characters before their extension,
+// such as app.3f2a1b9c.css or chunk-3f2a1b9c.js.
+var fingerprinted = regexp.MustCompile(`[.-][0-9a-f]{8,}\.[^./]+$`)
+
+// StaticHandlerOptions are the options for the StaticHandler.
+type StaticHandlerOptions struct {
+	// FileSystemMap is the map of file systems holding the static directories, usually the map of the template
+	// adapter. The files of a file system other than the root file system are served under its ID, e.g.
+	// /static/blog/css/blog.css for static/css/blog.css in the file system with ID "blog".
+	FileSystemMap map[string]fs.FS
+	// Prefix is the URL prefix the files are served under. Default is DefaultStaticPrefix.
+	Prefix string
+	// Dir is the directory of the static files in each file system. Default is constants.StaticDir.
+	Dir string
+	// CacheControl is the Cache-Control header of files that are not fingerprinted. Default is
+	// DefaultStaticCacheControl.
+	CacheControl string
+	// Fingerprinted reports whether a file, given its path in the static directory, is fingerprinted, and served with
+	// ImmutableCacheControl. Default matches file names with a hash of at least 8 hexadecimal characters before their
+	// extension, such as css/app.3f2a1b9c.css, including the files fingerprinted by the assets package.
+	Fingerprinted func(name string) bool
+	// Precompressed serves the Brotli (.br) or gzip (.gz) variant of a file, if there is one next to it and the client
+	// accepts it, e.g. css/app.css.br for css/app.css.
+	Precompressed bool
+}
+
+// StaticHandler serves the static directories of a map of file systems, so assets live next to the templates using
+// them. Directories are not listed.
+type StaticHandler struct {
+	fileSystems   map[string]fs.FS
+	prefix        string
+	cacheControl  string
+	fingerprinted func(name string) bool
+	precompressed bool
+}
+
+// NewStaticHandler creates a new StaticHandler. File systems without a static directory are skipped.
+func NewStaticHandler(opts StaticHandlerOptions) *StaticHandler {
+	if opts.Prefix == "" {
+		opts.Prefix = DefaultStaticPrefix
+	}
+	if opts.Dir == "" {
+		opts.Dir = constants.StaticDir
+	}
+	if opts.CacheControl == "" {
+		opts.CacheControl = DefaultStaticCacheControl
+	}
+	if opts.Fingerprinted == nil {
+		opts.Fingerprinted = fingerprinted.MatchString
+	}
+
+	fileSystems := make(map[string]fs.FS, len(opts.FileSystemMap))
+	for fsID, fsys := range opts.FileSystemMap {
+		sub, err := fs.Sub(fsys, opts.Dir)
+		if err != nil {
+			continue
+		}
+		if _, err := fs.Stat(sub, "."); err != nil {
+			continue
+		}
+		fileSystems[fsID] = sub
+	}
+
+	return &StaticHandler{
+		fileSystems:   fileSystems,
+		prefix:        "/" + strings.Trim(opts.Prefix, "/") + "/",
+		cacheControl:  opts.CacheControl,
+		fingerprinted: opts.Fingerprinted,
+		precompressed: opts.Precompressed,
+	}
+}
+
+func (h *StaticHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name, ok := strings.CutPrefix(r.URL.Path, h.prefix)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+
+	fsys, name := h.resolve(name)
+	if fsys == nil || !fs.ValidPath(name) {
+		http.NotFound(w, r)
+		return
+	}
+	info, err := fs.Stat(fsys, name)
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+
+	if h.fingerprinted(name) {
+		w.Header().Set("Cache-Control", ImmutableCacheControl)
+	} else {
+		w.Header().Set("Cache-Control", h.cacheControl)
+	}
+
+	file := name
+	if h.precompressed {
+		variant, encoding, hasVariants := h.compressedVariant(r, fsys, name)
+		if hasVariants {
+			w.Header().Add("Vary", "Accept-Encoding")
+		}
+		if encoding != "" {
+			w.Header().Set("Content-Encoding", encoding)
+			if contentType := mime.TypeByExtension(path.Ext(name)); contentType != "" {
+				w.Header().Set("Content-Type", contentType)
+			}
+			file = variant
+		}
+	}
+
+	serveFile(w, r, fsys, file, name)
+}
+
+// resolve returns the file system serving the file at name, and the path of the file in its static directory. Names
+// starting with the ID of a file system other than the root file system are served from that file system.
+func (h *StaticHandler) resolve(name string) (fs.FS, string) {
+	if fsID, rest, ok := strings.Cut(name, "/"); ok && fsID != constants.RootFSID {
+		if fsys, ok := h.fileSystems[fsID]; ok {
+			return fsys, rest
+		}
+	}
+	return h.fileSystems[constants.RootFSID], name
+}
+
+// compressedVariant returns the path and encoding of the precompressed variant of the file at name accepted by the
+// client, preferring Brotli, or an empty encoding if none is accepted. It also reports whether the file has
+// variants, in which case the response varies on Accept-Encoding.
+func (h *StaticHandler) compressedVariant(r *http.Request, fsys fs.FS, name string) (string, string, bool) {
+	accepted := r.Header.Get("Accept-Encoding")
+	hasVariants := false
+	for _, variant := range []struct{ ext, encoding string }{{".br", "br"}, {".gz", "gzip"}} {
+		if _, err := fs.Stat(fsys, name+variant.ext); err != nil {
+			continue
+		}
+		if acceptsEncoding(accepted, variant.encoding) {
+			return name + variant.ext, variant.encoding, true
+		}
+		hasVariants = true
+	}
+	return "", "", hasVariants
+}
+
+// acceptsEncoding reports whether the Accept-Encoding header accepts the encoding, with a quality value above zero.
+// Codings are compared case-insensitively, and the quality of the encoding itself takes precedence over that of the *
+// wildcard, so "*;q=0, gzip" accepts gzip only.
+func acceptsEncoding(header, encoding string) bool {
+	exact, wildcard := -1.0, -1.0
+	for _, entry := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(entry, ";")
+		coding = strings.TrimSpace(coding)
+		q, ok := qValue(params)
+		if !ok {
+			continue
+		}
+		switch {
+		case strings.EqualFold(coding, encoding):
+			exact = max(exact, q)
+		case coding == "*":
+			wildcard = max(wildcard, q)
+		}
+	}
+	if exact >= 0 {
+		return exact > 0
+	}
+	return wildcard > 0
+}
+
+// qValue returns the quality value of the parameters of an Accept-Encoding entry, 1 without a q parameter. It reports
+// false for invalid quality values, whose entries are ignored.
+func qValue(params string) (float64, bool) {
+	for _, param := range strings.Split(params, ";") {
+		name, value, _ := strings.Cut(param, "=")
+		if !strings.EqualFold(strings.TrimSpace(name), "q") {
+			continue
+		}
+		q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || q < 0 || q > 1 {
+			return 0, false
+		}
+		return q, true
+	}
+	return 1, true
+}
+
+// serveFile serves the file at filePath, with the content type of the file at name when it is not set. Unlike
+// http.ServeFileFS, it does not redirect requests for index.html files.
+func serveFile(w http.ResponseWriter, r *http.Request, fsys fs.FS, filePath, name string) {
+	f, err := fsys.Open(filePath)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		http.Error(w, "static file is not seekable", http.StatusInternalServerError)
+		return
+	}
+	http.ServeContent(w, r, path.Base(name), info.ModTime(), content)
+}
@@ -0,0 +1,89 @@
+package hyperview_test
+
+import (
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+)
+
+func TestStaticHandler(t *testing.T) {
+	handler := hyperview.NewStaticHandler(hyperview.StaticHandlerOptions{
+		FileSystemMap: map[string]fs.FS{
+			constants.RootFSID: fstest.MapFS{
+				"static/css/app.css":              {Data: []byte("body{}")},
+				"static/css/app.css.br":           {Data: []byte("brotli")},
+				"static/css/app.css.gz":           {Data: []byte("gzip")},
+				"static/js/app.3f2a1b9c.js":       {Data: []byte("app()")},
+				"static/index.html":               {Data: []byte("<p>index</p>")},
+				"views/home.html":                 {Data: []byte("secret")},
+				"static/downloads/archive.tar.gz": {Data: []byte("archive")},
+			},
+			"blog":  fstest.MapFS{"static/css/blog.css": {Data: []byte("blog{}")}},
+			"admin": fstest.MapFS{"views/index.html": {Data: []byte("admin")}},
+		},
+		Precompressed: true,
+	})
+
+	tests := []struct {
+		name             string
+		method           string
+		path             string
+		acceptEncoding   string
+		wantStatus       int
+		wantBody         string
+		wantCacheControl string
+		wantEncoding     string
+		wantVary         string
+	}{
+		{"file", http.MethodGet, "/static/css/app.css", "", http.StatusOK, "body{}", "no-cache", "", "Accept-Encoding"},
+		{"brotli", http.MethodGet, "/static/css/app.css", "gzip, br", http.StatusOK, "brotli", "no-cache", "br", "Accept-Encoding"},
+		{"gzip", http.MethodGet, "/static/css/app.css", "gzip, br;q=0", http.StatusOK, "gzip", "no-cache", "gzip", "Accept-Encoding"},
+		{"wildcard refused", http.MethodGet, "/static/css/app.css", "*;q=0, gzip", http.StatusOK, "gzip", "no-cache", "gzip", "Accept-Encoding"},
+		{"wildcard", http.MethodGet, "/static/css/app.css", "gzip;q=0.5, *", http.StatusOK, "brotli", "no-cache", "br", "Accept-Encoding"},
+		{"zero quality values", http.MethodGet, "/static/css/app.css", "br;q=0.000, GZIP;Q=0.0", http.StatusOK, "body{}", "no-cache", "", "Accept-Encoding"},
+		{"coding case", http.MethodGet, "/static/css/app.css", "GZip", http.StatusOK, "gzip", "no-cache", "gzip", "Accept-Encoding"},
+		{"fingerprinted", http.MethodGet, "/static/js/app.3f2a1b9c.js", "br", http.StatusOK, "app()", hyperview.ImmutableCacheControl, "", ""},
+		{"index file", http.MethodGet, "/static/index.html", "", http.StatusOK, "<p>index</p>", "no-cache", "", ""},
+		{"gzip file", http.MethodGet, "/static/downloads/archive.tar.gz", "gzip", http.StatusOK, "archive", "no-cache", "", ""},
+		{"file system", http.MethodGet, "/static/blog/css/blog.css", "", http.StatusOK, "blog{}", "no-cache", "", ""},
+		{"directory", http.MethodGet, "/static/css/", "", http.StatusNotFound, "", "", "", ""},
+		{"outside the static directory", http.MethodGet, "/static/../views/home.html", "", http.StatusNotFound, "", "", "", ""},
+		{"file system without a static directory", http.MethodGet, "/static/admin/index.html", "", http.StatusNotFound, "", "", "", ""},
+		{"method", http.MethodPost, "/static/css/app.css", "", http.StatusMethodNotAllowed, "", "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.path, nil)
+			r.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("unexpected status: got %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if got := w.Body.String(); got != tt.wantBody {
+				t.Errorf("unexpected body: got %q, want %q", got, tt.wantBody)
+			}
+			if got := w.Header().Get("Cache-Control"); got != tt.wantCacheControl {
+				t.Errorf("unexpected Cache-Control: got %q, want %q", got, tt.wantCacheControl)
+			}
+			if got := w.Header().Get("Content-Encoding"); got != tt.wantEncoding {
+				t.Errorf("unexpected Content-Encoding: got %q, want %q", got, tt.wantEncoding)
+			}
+			if got := w.Header().Get("Vary"); got != tt.wantVary {
+				t.Errorf("unexpected Vary: got %q, want %q", got, tt.wantVary)
+			}
+			if tt.wantEncoding != "" && w.Header().Get("Content-Type") != "text/css; charset=utf-8" {
+				t.Errorf("unexpected Content-Type: %q", w.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
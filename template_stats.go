@@ -0,0 +1,205 @@
+package hyperview
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"sort"
+	"strings"
+	"text/template/parse"
+
+	"github.com/hypergopher/hyperview/constants"
+)
+
+// StatsReporter is implemented by adapters that can report the approximate memory footprint of their compiled
+// templates, to inform the tuning of lazy compilation and template eviction in large deployments.
+type StatsReporter interface {
+	// TemplateStats returns the footprint of the compiled templates.
+	TemplateStats() TemplateStats
+}
+
+// TemplateStats describes the approximate memory footprint of the compiled templates of an adapter.
+type TemplateStats struct {
+	// Sets are the compiled template sets, sorted by page and layout.
+	Sets []TemplateSetStats `json:"sets"`
+	// Namespaces aggregate the template sets by file system ID (see TemplateViewAdapterOptions.FileSystemMap).
+	Namespaces map[string]NamespaceStats `json:"namespaces"`
+	// Nodes is the total number of parse tree nodes.
+	Nodes int `json:"nodes"`
+	// SourceBytes is the total size of the template sources compiled into the template sets. Sources shared by
+	// several template sets, such as partials, are counted once per set, as each set holds its own copy.
+	SourceBytes int64 `json:"sourceBytes"`
+	// Usage is the number of renders of each view, sorted by view, including the views never rendered.
+	Usage []TemplateUsage `json:"usage"`
+}
+
+// TemplateSetStats describes a compiled template set, which holds a page along with the layouts and partials it can
+// use.
+type TemplateSetStats struct {
+	// Page is the name of the page, e.g. "views/home" or "admin:views/users".
+	Page string `json:"page"`
+	// Layout is the extending layout the page is compiled with, if any.
+	Layout string `json:"layout,omitempty"`
+	// Namespace is the file system ID the page belongs to.
+	Namespace string `json:"namespace"`
+	// Templates is the number of templates in the set.
+	Templates int `json:"templates"`
+	// Nodes is the number of parse tree nodes in the set.
+	Nodes int `json:"nodes"`
+	// SourceBytes is the size of the template sources compiled into the set.
+	SourceBytes int64 `json:"sourceBytes"`
+}
+
+// NamespaceStats aggregates the template sets of a namespace.
+type NamespaceStats struct {
+	// Sets is the number of compiled template sets.
+	Sets int `json:"sets"`
+	// Nodes is the number of parse tree nodes.
+	Nodes int `json:"nodes"`
+	// SourceBytes is the size of the template sources.
+	SourceBytes int64 `json:"sourceBytes"`
+}
+
+// TemplateStats returns the approximate memory footprint of the template sets compiled so far, along with the usage
+// of the views. In lazy mode, pages that have not been rendered yet, or that were evicted, are not included in the
+// template sets.
+func (a *TemplateAdapter) TemplateStats() TemplateStats {
+	stats := TemplateStats{Namespaces: make(map[string]NamespaceStats)}
+
+	if !a.frozen.Load() {
+		a.initMu.RLock()
+		defer a.initMu.RUnlock()
+		a.mu.RLock()
+		defer a.mu.RUnlock()
+	}
+
+	for page, tmpl := range a.templates {
+		stats.add(a.templateSetStats(page, "", tmpl))
+	}
+	for key, tmpl := range a.layered {
+		parts := strings.Split(key, "|")
+		stats.add(a.templateSetStats(parts[1], parts[0], tmpl))
+	}
+
+	stats.Usage = a.viewUsage()
+
+	sort.Slice(stats.Sets, func(i, j int) bool {
+		if stats.Sets[i].Page != stats.Sets[j].Page {
+			return stats.Sets[i].Page < stats.Sets[j].Page
+		}
+		return stats.Sets[i].Layout < stats.Sets[j].Layout
+	})
+
+	return stats
+}
+
+func (a *TemplateAdapter) templateSetStats(page, layout string, tmpl *template.Template) TemplateSetStats {
+	set := TemplateSetStats{
+		Page:        page,
+		Layout:      layout,
+		Namespace:   constants.RootFSID,
+		SourceBytes: a.commonBytes + a.pages[page].size,
+	}
+
+	if fsID, _, found := strings.Cut(page, ":"); found {
+		set.Namespace = fsID
+	}
+
+	if chain, ok := a.layoutChains[layout]; ok {
+		for _, name := range chain[:len(chain)-1] {
+			set.SourceBytes += a.layouts[name].size
+		}
+	}
+
+	for _, t := range tmpl.Templates() {
+		set.Templates++
+		if t.Tree != nil {
+			set.Nodes += countNodes(t.Tree.Root)
+		}
+	}
+
+	return set
+}
+
+func (s *TemplateStats) add(set TemplateSetStats) {
+	s.Sets = append(s.Sets, set)
+	s.Nodes += set.Nodes
+	s.SourceBytes += set.SourceBytes
+
+	ns := s.Namespaces[set.Namespace]
+	ns.Sets++
+	ns.Nodes += set.Nodes
+	ns.SourceBytes += set.SourceBytes
+	s.Namespaces[set.Namespace] = ns
+}
+
+// countNodes returns the number of nodes in the parse tree rooted at node.
+func countNodes(node parse.Node) int {
+	switch n := node.(type) {
+	case nil:
+		return 0
+	case *parse.ListNode:
+		if n == nil {
+			return 0
+		}
+		count := 1
+		for _, child := range n.Nodes {
+			count += countNodes(child)
+		}
+		return count
+	case *parse.ActionNode:
+		return 1 + countNodes(n.Pipe)
+	case *parse.PipeNode:
+		if n == nil {
+			return 0
+		}
+		count := 1 + len(n.Decl)
+		for _, cmd := range n.Cmds {
+			count += countNodes(cmd)
+		}
+		return count
+	case *parse.CommandNode:
+		count := 1
+		for _, arg := range n.Args {
+			count += countNodes(arg)
+		}
+		return count
+	case *parse.IfNode:
+		return 1 + countNodes(n.Pipe) + countNodes(n.List) + countNodes(n.ElseList)
+	case *parse.RangeNode:
+		return 1 + countNodes(n.Pipe) + countNodes(n.List) + countNodes(n.ElseList)
+	case *parse.WithNode:
+		return 1 + countNodes(n.Pipe) + countNodes(n.List) + countNodes(n.ElseList)
+	case *parse.TemplateNode:
+		return 1 + countNodes(n.Pipe)
+	case *parse.ChainNode:
+		return 1 + countNodes(n.Node)
+	default:
+		return 1
+	}
+}
+
+// TemplateStats returns the template footprint of the adapters implementing StatsReporter, keyed by adapter name.
+func (s *HyperView) TemplateStats() map[string]TemplateStats {
+	if !s.frozen.Load() {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+	}
+
+	stats := make(map[string]TemplateStats)
+	for name, adapter := range s.adapters {
+		if reporter, ok := adapter.(StatsReporter); ok {
+			stats[name] = reporter.TemplateStats()
+		}
+	}
+	return stats
+}
+
+// StatsHandler returns a handler serving the template footprint of the adapters as JSON, for admin endpoints and
+// metrics scrapers. It exposes the names of all views, so it should not be publicly reachable.
+func (s *HyperView) StatsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(s.TemplateStats())
+	})
+}
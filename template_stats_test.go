@@ -0,0 +1,93 @@
+package hyperview_test
+
+import (
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func TestTemplateAdapter_TemplateStats(t *testing.T) {
+	base := `{{define "layout:base"}}{{template "page:main" .}}{{end}}`
+	admin := `{{/* extends "base" */}}{{define "page:main"}}{{block "content" .}}{{end}}{{end}}`
+	home := `{{define "page:main"}}{{if .Title}}{{.Title}}{{end}}{{end}}`
+	users := `{{define "content"}}users{{end}}`
+
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{
+			constants.RootFSID: fstest.MapFS{
+				"layouts/base.html":  {Data: []byte(base)},
+				"layouts/admin.html": {Data: []byte(admin)},
+				"views/home.html":    {Data: []byte(home)},
+			},
+			"admin": fstest.MapFS{
+				"views/users.html": {Data: []byte(users)},
+			},
+		},
+	})
+	if err := adapter.Init(); err != nil {
+		t.Fatalf("error initializing adapter: %v", err)
+	}
+	renderTestTemplate(t, adapter, response.NewResponse().Layout("admin").Path("admin:views/users"))
+
+	stats := adapter.TemplateStats()
+
+	want := []struct {
+		page, layout, namespace string
+		sourceBytes             int
+	}{
+		{"admin:views/users", "", "admin", len(base) + len(users)},
+		{"admin:views/users", "admin", "admin", len(base) + len(admin) + len(users)},
+		{"views/home", "", constants.RootFSID, len(base) + len(home)},
+	}
+	if len(stats.Sets) != len(want) {
+		t.Fatalf("expected %d template sets, got %+v", len(want), stats.Sets)
+	}
+	for i, w := range want {
+		set := stats.Sets[i]
+		if set.Page != w.page || set.Layout != w.layout || set.Namespace != w.namespace || set.SourceBytes != int64(w.sourceBytes) {
+			t.Errorf("unexpected template set %d: %+v, want %+v", i, set, w)
+		}
+		if set.Nodes == 0 || set.Templates == 0 {
+			t.Errorf("expected template set %d to count its templates and nodes: %+v", i, set)
+		}
+	}
+
+	if ns := stats.Namespaces["admin"]; ns.Sets != 2 || ns.SourceBytes != stats.Sets[0].SourceBytes+stats.Sets[1].SourceBytes {
+		t.Errorf("unexpected admin namespace stats: %+v", ns)
+	}
+	if stats.Nodes != stats.Sets[0].Nodes+stats.Sets[1].Nodes+stats.Sets[2].Nodes {
+		t.Errorf("expected the total to sum the template sets, got %d", stats.Nodes)
+	}
+}
+
+func TestHyperView_StatsHandler(t *testing.T) {
+	hv, err := hyperview.NewHyperView()
+	if err != nil {
+		t.Fatalf("error creating HyperView: %v", err)
+	}
+	adapter := newTestTemplateAdapter(t, fstest.MapFS{
+		"layouts/base.html": {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+		"views/home.html":   {Data: []byte(`{{define "page:main"}}home{{end}}`)},
+	}, hyperview.TemplateViewAdapterOptions{})
+	if err := hv.RegisterAdapter("html", adapter); err != nil {
+		t.Fatalf("error registering adapter: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	hv.StatsHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	var stats map[string]hyperview.TemplateStats
+	if err := json.NewDecoder(w.Body).Decode(&stats); err != nil {
+		t.Fatalf("error decoding stats: %v", err)
+	}
+	if html := stats["html"]; len(html.Sets) != 1 || html.Sets[0].Page != "views/home" {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
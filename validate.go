@@ -0,0 +1,189 @@
+package hyperview
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/hypergopher/hyperview/response"
+)
+
+// ValidationKind classifies the problems found by validation.
+type ValidationKind string
+
+const (
+	// UndefinedTemplate is a reference to a template or block that is not defined, e.g. a page missing a block its
+	// layout requires.
+	UndefinedTemplate ValidationKind = "undefined template"
+	// FuncCall is a function called with bad arguments, or returning an error.
+	FuncCall ValidationKind = "func call"
+	// DataError is any other execution error, usually caused by the view data.
+	DataError ValidationKind = "data"
+)
+
+// ValidateOptions are the options for validating the templates of an adapter.
+type ValidateOptions struct {
+	// Layout is the layout views not declaring a layout are validated with. HyperView.Validate defaults it to the
+	// base layout. Without a layout, such views are executed on their own.
+	Layout string
+	// SampleData is the view data views are executed with, keyed by view path (e.g. "views/users/show"). Views
+	// without sample data are executed with empty view data, which only satisfies views that handle zero values, so
+	// data errors are only reported for views with sample data.
+	SampleData map[string]map[string]any
+}
+
+// ValidationError is a problem found in a view by validation.
+type ValidationError struct {
+	// View is the path of the view, e.g. "views/home".
+	View string
+	// Layout is the layout the view was executed with, if any.
+	Layout string
+	// Kind classifies the problem.
+	Kind ValidationKind
+	// Err is the error executing the view.
+	Err error
+}
+
+func (e *ValidationError) Error() string {
+	if e.Layout == "" {
+		return fmt.Sprintf("%s (%s): %v", e.View, e.Kind, e.Err)
+	}
+	return fmt.Sprintf("%s with layout %s (%s): %v", e.View, e.Layout, e.Kind, e.Err)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationErrors are all the problems found by validation, sorted by view.
+type ValidationErrors []*ValidationError
+
+func (errs ValidationErrors) Error() string {
+	messages := make([]string, 0, len(errs))
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+	return fmt.Sprintf("%d invalid views:\n%s", len(errs), strings.Join(messages, "\n"))
+}
+
+// validationKind classifies an error executing a template.
+func validationKind(err error) ValidationKind {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "no such template"), strings.Contains(msg, "is undefined"):
+		return UndefinedTemplate
+	case strings.Contains(msg, "error calling"), strings.Contains(msg, "wrong number of args"),
+		strings.Contains(msg, "wrong type for value"), strings.Contains(msg, "can't call"):
+		return FuncCall
+	default:
+		return DataError
+	}
+}
+
+// Validate executes every view with its declared layout, or the layout of the options, and reports the views
+// referencing undefined templates or blocks, calling functions with bad arguments or, for views with sample data,
+// failing with the data. This catches the broken views that parsing alone cannot, before users hit their routes.
+// The returned error is a ValidationErrors.
+//
+// Views are compiled separately for validation, so it can be called at any time, including on a frozen adapter.
+func (a *TemplateAdapter) Validate(opts ValidateOptions) error {
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+
+	pages := make([]string, 0, len(a.pages))
+	for page := range a.pages {
+		if page != contentPage {
+			pages = append(pages, page)
+		}
+	}
+	sort.Strings(pages)
+
+	r, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "/", nil)
+	if err != nil {
+		return err
+	}
+
+	var errs ValidationErrors
+	for _, page := range pages {
+		layout, ok := a.pageLayouts[page]
+		if !ok {
+			layout = opts.Layout
+		}
+
+		sample, hasSample := opts.SampleData[page]
+		if err := a.validatePage(r, page, layout, sample); err != nil {
+			verr := &ValidationError{View: page, Layout: layout, Kind: validationKind(err), Err: err}
+			if verr.Kind != DataError || hasSample {
+				errs = append(errs, verr)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validatePage compiles the page with the layout and executes it with the sample data.
+func (a *TemplateAdapter) validatePage(r *http.Request, page, layout string, sample map[string]any) error {
+	name := path.Base(a.pages[page].path)
+	var chain []string
+	if layout != "" {
+		name = "layout:" + layout
+		if c, ok := a.layoutChains[layout]; ok {
+			chain = c
+			name = "layout:" + c[len(c)-1]
+		}
+	}
+
+	tmpl, err := a.compilePage(page, chain, a.strict)
+	if err != nil {
+		return err
+	}
+
+	data := make(map[string]any, len(sample))
+	for key, value := range sample {
+		data[key] = value
+	}
+	viewData := response.NewResponse().Path(page).Data(data).ViewData(r).Data()
+
+	if err := tmpl.ExecuteTemplate(new(bytes.Buffer), name, viewData); err != nil {
+		return missingKeyError(err)
+	}
+	return nil
+}
+
+// Validate validates the templates of the adapters implementing Validator, defaulting the layout of the options to
+// the base layout. The errors of the adapters are joined, each prefixed with the adapter name.
+func (s *HyperView) Validate(opts ValidateOptions) error {
+	if opts.Layout == "" {
+		opts.Layout = s.baseLayout
+	}
+
+	if !s.frozen.Load() {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+	}
+
+	names := make([]string, 0, len(s.adapters))
+	for name := range s.adapters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		if validator, ok := s.adapters[name].(Validator); ok {
+			if err := validator.Validate(opts); err != nil {
+				errs = append(errs, fmt.Errorf("adapter %s: %w", name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
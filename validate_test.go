@@ -0,0 +1,82 @@
+package hyperview_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview"
+)
+
+func validateTestFiles() fstest.MapFS {
+	return fstest.MapFS{
+		"layouts/base.html":      {Data: []byte(`{{define "layout:base"}}<title>{{template "title" .}}</title>{{template "page:main" .}}{{end}}`)},
+		"layouts/bare.html":      {Data: []byte(`{{define "layout:bare"}}{{template "page:main" .}}{{end}}`)},
+		"views/ok.html":          {Data: []byte(`{{define "title"}}OK{{end}}{{define "page:main"}}{{with .User}}{{.Name}}{{end}}{{end}}`)},
+		"views/no-title.html":    {Data: []byte(`{{define "page:main"}}no title{{end}}`)},
+		"views/declared.html":    {Data: []byte(`<!-- layout: bare -->{{define "page:main"}}bare{{end}}`)},
+		"views/bad-func.html":    {Data: []byte(`{{define "title"}}{{fail_}}{{end}}{{define "page:main"}}{{end}}`)},
+		"views/user.html":        {Data: []byte(`{{define "title"}}User{{end}}{{define "page:main"}}{{.User.Name}}{{end}}`)},
+		"views/user-sample.html": {Data: []byte(`{{define "title"}}User{{end}}{{define "page:main"}}{{.User.Name}}{{end}}`)},
+	}
+}
+
+// validateTestOptions are the options of the adapters validating validateTestFiles, with the function failing.
+var validateTestOptions = hyperview.TemplateViewAdapterOptions{
+	Funcs: map[string]any{
+		"fail_": func() (string, error) { return "", errors.New("boom") },
+	},
+}
+
+func TestTemplateAdapter_Validate(t *testing.T) {
+	adapter := newTestTemplateAdapter(t, validateTestFiles(), validateTestOptions)
+
+	err := adapter.Validate(hyperview.ValidateOptions{
+		Layout: "base",
+		SampleData: map[string]map[string]any{
+			"views/user-sample": {"User": "ann"},
+		},
+	})
+
+	var errs hyperview.ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected ValidationErrors, got %v", err)
+	}
+
+	want := []struct {
+		view string
+		kind hyperview.ValidationKind
+	}{
+		{"views/bad-func", hyperview.FuncCall},
+		{"views/no-title", hyperview.UndefinedTemplate},
+		{"views/user-sample", hyperview.DataError},
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("expected %d errors, got:\n%v", len(want), err)
+	}
+	for i, w := range want {
+		if errs[i].View != w.view || errs[i].Kind != w.kind || errs[i].Layout != "base" {
+			t.Errorf("unexpected error %d: %v, want %s (%s)", i, errs[i], w.view, w.kind)
+		}
+	}
+	if !strings.Contains(err.Error(), `views/no-title with layout base (undefined template)`) {
+		t.Errorf("expected the error to name the view, layout and kind, got:\n%v", err)
+	}
+}
+
+func TestHyperView_Validate(t *testing.T) {
+	hv, err := hyperview.NewHyperView()
+	if err != nil {
+		t.Fatalf("error creating HyperView: %v", err)
+	}
+	if err := hv.RegisterAdapter("html", newTestTemplateAdapter(t, validateTestFiles(), validateTestOptions)); err != nil {
+		t.Fatalf("error registering adapter: %v", err)
+	}
+
+	// The base layout is used for views that don't declare a layout
+	err = hv.Validate(hyperview.ValidateOptions{})
+	if err == nil || !strings.Contains(err.Error(), "adapter html:") || !strings.Contains(err.Error(), "views/no-title with layout base") {
+		t.Errorf("expected the errors of the html adapter, got %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package hyperview
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/response"
+)
+
+// ViewComposer adds data shared by many views to a response before it renders, such as navigation items, feature
+// flags or unread counts, so the handlers of the views don't each pass them. Composers can add data loaders to the
+// response, to load the data concurrently with the loaders of the handler. An error fails the render with a server
+// error.
+type ViewComposer func(r *http.Request, resp *response.Response) error
+
+// viewComposer is a composer registered for the views matching a pattern.
+type viewComposer struct {
+	pattern  string
+	composer ViewComposer
+}
+
+// WithViewComposer registers a view composer for the views matching the pattern, see HyperView.Compose.
+func WithViewComposer(pattern string, composer ViewComposer) Option {
+	return func(hgo *HyperView) error {
+		hgo.Compose(pattern, composer)
+		return nil
+	}
+}
+
+// Compose registers a view composer for the views matching the pattern. Patterns match the view name without the
+// views directory and the file system prefix, e.g. admin/users, with path.Match, such as "admin/*", and a trailing
+// "**" matching any suffix, so "admin/**" covers nested directories. "*" and "**" cover all views. Composers run
+// before every render through HyperView matching their pattern, in the order they were registered, after the
+// handler set the data of the response, so they can override it.
+func (s *HyperView) Compose(pattern string, composer ViewComposer) {
+	s.mustNotBeFrozen("Compose")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.composers = append(s.composers, viewComposer{pattern: pattern, composer: composer})
+}
+
+// compose runs the composers matching the view of the response. They run without holding the lock of the HyperView,
+// so a slow composer does not block registrations, and composers can call its methods.
+func (s *HyperView) compose(r *http.Request, resp *response.Response) error {
+	var composers []viewComposer
+	if s.frozen.Load() {
+		composers = s.composers
+	} else {
+		s.mu.RLock()
+		composers = slices.Clone(s.composers)
+		s.mu.RUnlock()
+	}
+
+	view := composedView(resp)
+	for _, c := range composers {
+		if c.pattern != "*" && !matchPagePattern(c.pattern, view) {
+			continue
+		}
+		if err := c.composer(r, resp); err != nil {
+			return fmt.Errorf("error composing view %s with %s: %w", resp.TemplatePath(), c.pattern, err)
+		}
+	}
+	return nil
+}
+
+// composedView returns the name of the view of the response the patterns of composers match, without the file system
+// prefix and the views directory, e.g. admin/users for admin:views/admin/users.
+func composedView(resp *response.Response) string {
+	_, viewPath, _ := cutFSID(resp.TemplatePath())
+	return strings.TrimPrefix(viewPath, constants.ViewsDir+"/")
+}
@@ -0,0 +1,126 @@
+package hyperview_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/hypergopher/hyperview"
+	"github.com/hypergopher/hyperview/constants"
+	"github.com/hypergopher/hyperview/response"
+)
+
+func TestHyperView_Compose(t *testing.T) {
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{
+			"layouts/base.html":              {Data: []byte(`{{define "layout:base"}}{{.Site}}|{{.Nav}}|{{.Unread}}|{{template "page:main" .}}{{end}}`)},
+			"views/home.html":                {Data: []byte(`{{define "page:main"}}home{{end}}`)},
+			"views/admin/users.html":         {Data: []byte(`{{define "page:main"}}users{{end}}`)},
+			"views/admin/broken.html":        {Data: []byte(`{{define "page:main"}}broken{{end}}`)},
+			"views/admin/reports/daily.html": {Data: []byte(`{{define "page:main"}}daily{{end}}`)},
+		}},
+		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+
+	hv, err := hyperview.NewHyperView(
+		hyperview.WithViewAdapter("html", adapter),
+		hyperview.WithViewComposer("*", func(r *http.Request, resp *response.Response) error {
+			resp.AddDataItem("Site", "Acme")
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("error creating HyperView: %v", err)
+	}
+	hv.Compose("admin/*", func(r *http.Request, resp *response.Response) error {
+		resp.AddDataItem("Nav", "admin nav")
+		resp.Load("Unread", func(ctx context.Context) (any, error) { return 3, nil })
+		return nil
+	})
+	hv.Compose("admin/broken", func(r *http.Request, resp *response.Response) error {
+		return errors.New("no nav")
+	})
+
+	tests := []struct {
+		name       string
+		path       string
+		data       map[string]any
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "all views", path: "home", wantStatus: http.StatusOK, wantBody: "Acme|||home"},
+		{name: "matching views", path: "admin/users", wantStatus: http.StatusOK, wantBody: "Acme|admin nav|3|users"},
+		{name: "nested views", path: "admin/reports/daily", wantStatus: http.StatusOK, wantBody: "Acme|||daily"},
+		{name: "composers override the handler", path: "home", data: map[string]any{"Site": "Other", "Nav": "handler nav"},
+			wantStatus: http.StatusOK, wantBody: "Acme|handler nav||home"},
+		{name: "failing composer", path: "admin/broken", wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			hv.Render(w, httptest.NewRequest(http.MethodGet, "/", nil), response.NewResponse().Path(tt.path).Data(tt.data))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Errorf("expected body %q, got %q", tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHyperView_ComposeWithoutLock(t *testing.T) {
+	adapter := hyperview.NewTemplateViewAdapter(hyperview.TemplateViewAdapterOptions{
+		FileSystemMap: map[string]fs.FS{constants.RootFSID: fstest.MapFS{
+			"layouts/base.html": {Data: []byte(`{{define "layout:base"}}{{template "page:main" .}}{{end}}`)},
+			"views/home.html":   {Data: []byte(`{{define "page:main"}}{{.Registered}}{{end}}`)},
+		}},
+	})
+	hv, err := hyperview.NewHyperView(hyperview.WithViewAdapter("html", adapter))
+	if err != nil {
+		t.Fatalf("error creating HyperView: %v", err)
+	}
+
+	// A composer calling the methods of the HyperView, including those taking its write lock, must not deadlock
+	var once sync.Once
+	hv.Compose("*", func(r *http.Request, resp *response.Response) error {
+		if _, ok := hv.Adapter("html"); !ok {
+			return errors.New("no adapter")
+		}
+		once.Do(func() {
+			hv.Compose("home", func(r *http.Request, resp *response.Response) error {
+				resp.AddDataItem("Registered", "yes")
+				return nil
+			})
+		})
+		return nil
+	})
+
+	for _, want := range []string{"", "yes"} {
+		done := make(chan *httptest.ResponseRecorder)
+		go func() {
+			w := httptest.NewRecorder()
+			hv.Render(w, httptest.NewRequest(http.MethodGet, "/", nil), response.NewResponse().Path("home"))
+			done <- w
+		}()
+
+		select {
+		case w := <-done:
+			if w.Body.String() != want {
+				t.Errorf("expected body %q, got %q", want, w.Body.String())
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("render blocked by a composer calling the HyperView")
+		}
+	}
+}
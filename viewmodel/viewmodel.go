@@ -0,0 +1,216 @@
+// Package viewmodel maps domain types to the presentation types (view models) that templates are rendered with, so
+// formatting and other presentation logic stays out of handlers and templates.
+package viewmodel
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// ErrUnmappedType is returned in strict mode when the data passed to a template contains a struct type that is
+// neither a view model nor explicitly allowed.
+var ErrUnmappedType = errors.New("type is not a view model")
+
+// Option is a function that configures a Registry.
+type Option func(*Registry)
+
+// Strict returns an Option that makes the registry reject data containing struct types that are not view models,
+// such as raw domain types without a registered mapper. The output types of registered mappers, time.Time and the
+// types passed to Allow are accepted.
+func Strict() Option {
+	return func(reg *Registry) {
+		reg.strict = true
+	}
+}
+
+// Allow returns an Option that accepts the types of the given values in strict mode, for example presentation types
+// built directly by handlers.
+func Allow(values ...any) Option {
+	return func(reg *Registry) {
+		for _, value := range values {
+			reg.allowed[indirectType(reflect.TypeOf(value))] = true
+		}
+	}
+}
+
+// mapper converts a domain value into its view model.
+type mapper func(any) (any, error)
+
+// Registry holds the mappers from domain types to view models.
+type Registry struct {
+	mappers map[reflect.Type]mapper
+	outputs map[reflect.Type]reflect.Type
+	allowed map[reflect.Type]bool
+	strict  bool
+}
+
+// NewRegistry creates a new Registry.
+func NewRegistry(opts ...Option) *Registry {
+	reg := &Registry{
+		mappers: make(map[reflect.Type]mapper),
+		outputs: make(map[reflect.Type]reflect.Type),
+		allowed: map[reflect.Type]bool{reflect.TypeOf(time.Time{}): true},
+	}
+
+	for _, opt := range opts {
+		opt(reg)
+	}
+
+	return reg
+}
+
+// Register registers a mapper from the domain type D to the view model V. Values of type D, pointers to D and
+// slices of either are mapped before the template is rendered.
+//
+// Example:
+//
+//	viewmodel.Register(reg, func(u User) UserView {
+//		return UserView{Name: u.FirstName + " " + u.LastName, Joined: u.CreatedAt.Format("Jan 2006")}
+//	})
+func Register[D, V any](reg *Registry, fn func(D) V) {
+	RegisterErr(reg, func(d D) (V, error) {
+		return fn(d), nil
+	})
+}
+
+// RegisterErr registers a mapper from the domain type D to the view model V that can fail.
+func RegisterErr[D, V any](reg *Registry, fn func(D) (V, error)) {
+	domainType := reflect.TypeOf((*D)(nil)).Elem()
+	viewType := reflect.TypeOf((*V)(nil)).Elem()
+
+	reg.mappers[domainType] = func(value any) (any, error) {
+		return fn(value.(D))
+	}
+	reg.outputs[domainType] = viewType
+	reg.allowed[indirectType(viewType)] = true
+}
+
+// Map converts value into its view model if a mapper is registered for its type. Pointers to and slices of mapped
+// types are converted too. Other values are returned as is, after checking them in strict mode.
+func (reg *Registry) Map(value any) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	v := reflect.ValueOf(value)
+	if mapped, ok, err := reg.mapValue(v); ok || err != nil {
+		return mapped, err
+	}
+
+	if reg.strict {
+		if err := reg.check(v); err != nil {
+			return nil, err
+		}
+	}
+
+	return value, nil
+}
+
+// mapValue converts v if it is a mapped type, a pointer to one, or a slice of either. It returns false if v is not
+// a mapped value.
+func (reg *Registry) mapValue(v reflect.Value) (any, bool, error) {
+	t := v.Type()
+
+	if m, ok := reg.mappers[t]; ok {
+		mapped, err := m(v.Interface())
+		return mapped, true, err
+	}
+
+	if t.Kind() == reflect.Pointer {
+		if m, ok := reg.mappers[t.Elem()]; ok {
+			if v.IsNil() {
+				return nil, true, nil
+			}
+			mapped, err := m(v.Elem().Interface())
+			return mapped, true, err
+		}
+		return nil, false, nil
+	}
+
+	if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+		return nil, false, nil
+	}
+
+	// Slices of a mapped type are converted to slices of the view model type
+	elemType := t.Elem()
+	outType, ok := reg.outputs[elemType]
+	if !ok && elemType.Kind() == reflect.Pointer {
+		outType, ok = reg.outputs[elemType.Elem()]
+	}
+	if !ok && elemType.Kind() == reflect.Interface {
+		outType, ok = elemType, true
+	}
+	if !ok {
+		return nil, false, nil
+	}
+
+	out := reflect.MakeSlice(reflect.SliceOf(outType), v.Len(), v.Len())
+	for i := 0; i < v.Len(); i++ {
+		elem := v.Index(i)
+		if elem.Kind() == reflect.Interface {
+			if elem.IsNil() {
+				continue
+			}
+			elem = elem.Elem()
+		}
+
+		mapped, isMapped, err := reg.mapValue(elem)
+		if err != nil {
+			return nil, true, fmt.Errorf("index %d: %w", i, err)
+		}
+		if !isMapped {
+			if reg.strict {
+				if err := reg.check(elem); err != nil {
+					return nil, true, fmt.Errorf("index %d: %w", i, err)
+				}
+			}
+			mapped = elem.Interface()
+		}
+		if mapped != nil {
+			out.Index(i).Set(reflect.ValueOf(mapped))
+		}
+	}
+
+	return out.Interface(), true, nil
+}
+
+// check returns an error if v contains a struct type that is not a view model or an allowed type.
+func (reg *Registry) check(v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return reg.check(v.Elem())
+	case reflect.Struct:
+		if !reg.allowed[v.Type()] {
+			return fmt.Errorf("%w: %s", ErrUnmappedType, v.Type())
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := reg.check(v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if err := reg.check(iter.Value()); err != nil {
+				return err
+			}
+		}
+	default:
+	}
+
+	return nil
+}
+
+// indirectType returns the type pointed to by t, if t is a pointer type.
+func indirectType(t reflect.Type) reflect.Type {
+	if t != nil && t.Kind() == reflect.Pointer {
+		return t.Elem()
+	}
+	return t
+}
@@ -0,0 +1,93 @@
+package viewmodel_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hypergopher/hyperview/viewmodel"
+)
+
+type user struct {
+	First, Last string
+}
+
+type userView struct {
+	Name string
+}
+
+type invoice struct {
+	Total int
+}
+
+func newRegistry(opts ...viewmodel.Option) *viewmodel.Registry {
+	reg := viewmodel.NewRegistry(opts...)
+	viewmodel.Register(reg, func(u user) userView {
+		return userView{Name: u.First + " " + u.Last}
+	})
+	return reg
+}
+
+func TestRegistry_Map(t *testing.T) {
+	reg := newRegistry()
+
+	tests := []struct {
+		name  string
+		value any
+		want  any
+	}{
+		{"value", user{"Ada", "Lovelace"}, userView{"Ada Lovelace"}},
+		{"pointer", &user{"Ada", "Lovelace"}, userView{"Ada Lovelace"}},
+		{"slice", []user{{"Ada", "Lovelace"}, {"Alan", "Turing"}}, []userView{{"Ada Lovelace"}, {"Alan Turing"}}},
+		{"slice of pointers", []*user{{"Ada", "Lovelace"}}, []userView{{"Ada Lovelace"}}},
+		{"slice of any", []any{user{"Ada", "Lovelace"}, "text"}, []any{userView{"Ada Lovelace"}, "text"}},
+		{"unmapped type", invoice{Total: 1}, invoice{Total: 1}},
+		{"string", "text", "text"},
+		{"nil", nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := reg.Map(tt.value)
+			if err != nil {
+				t.Fatalf("Map() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Map() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistry_MapStrict(t *testing.T) {
+	reg := newRegistry(viewmodel.Strict(), viewmodel.Allow(struct{ OK bool }{}))
+
+	tests := []struct {
+		name    string
+		value   any
+		wantErr bool
+	}{
+		{"mapped type", user{}, false},
+		{"view model", userView{}, false},
+		{"allowed type", struct{ OK bool }{}, false},
+		{"time", time.Now(), false},
+		{"scalar", 42, false},
+		{"raw domain type", invoice{}, true},
+		{"raw domain pointer", &invoice{}, true},
+		{"raw domain type in map", map[string]any{"invoice": invoice{}}, true},
+		{"raw domain type in slice", []any{"text", invoice{}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := reg.Map(tt.value)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("Map() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, viewmodel.ErrUnmappedType) {
+				t.Errorf("Map() error = %v, want ErrUnmappedType", err)
+			}
+		})
+	}
+}
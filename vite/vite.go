@@ -0,0 +1,240 @@
+// Package vite integrates HyperView templates with the Vite frontend build tool.
+//
+// In production, the viteScripts and viteCSS functions read Vite's manifest.json to link to the built entry points,
+// their stylesheets and their preloaded imports. In development, they point at the Vite dev server instead, along
+// with its client for hot module replacement.
+package vite
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+const (
+	// DefaultManifestPath is the default path of the manifest in the build output, as of Vite 5.
+	DefaultManifestPath = ".vite/manifest.json"
+	// DefaultDevServerURL is the default URL of the Vite dev server.
+	DefaultDevServerURL = "http://localhost:5173"
+)
+
+// Options are the options for the Vite integration.
+type Options struct {
+	// Dev points the functions at the dev server instead of the manifest.
+	Dev bool
+	// DevServerURL is the URL of the dev server. Default is DefaultDevServerURL.
+	DevServerURL string
+	// FS is the build output directory (Vite's build.outDir), holding the manifest. Required unless Dev is set.
+	FS fs.FS
+	// ManifestPath is the path of the manifest in FS. Default is DefaultManifestPath.
+	ManifestPath string
+	// Base is the URL path the build output is served under (Vite's base option). Default is "/".
+	Base string
+}
+
+// chunk is an entry of Vite's manifest.
+type chunk struct {
+	File    string   `json:"file"`
+	Src     string   `json:"src"`
+	IsEntry bool     `json:"isEntry"`
+	CSS     []string `json:"css"`
+	Imports []string `json:"imports"`
+}
+
+// Vite renders the tags loading Vite entry points.
+type Vite struct {
+	dev      bool
+	devURL   string
+	base     string
+	manifest map[string]chunk
+}
+
+// New creates a new Vite integration, reading the manifest unless in development.
+func New(opts Options) (*Vite, error) {
+	if opts.DevServerURL == "" {
+		opts.DevServerURL = DefaultDevServerURL
+	}
+	if opts.ManifestPath == "" {
+		opts.ManifestPath = DefaultManifestPath
+	}
+	if opts.Base == "" {
+		opts.Base = "/"
+	}
+
+	v := &Vite{
+		dev:    opts.Dev,
+		devURL: strings.TrimSuffix(opts.DevServerURL, "/"),
+		base:   "/" + strings.Trim(opts.Base, "/") + "/",
+	}
+	if v.base == "//" {
+		v.base = "/"
+	}
+
+	if v.dev {
+		return v, nil
+	}
+
+	if opts.FS == nil {
+		return nil, fmt.Errorf("vite: the build output file system is required outside of development")
+	}
+
+	data, err := fs.ReadFile(opts.FS, opts.ManifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("vite: error reading manifest: %w", err)
+	}
+	if err := json.Unmarshal(data, &v.manifest); err != nil {
+		return nil, fmt.Errorf("vite: error parsing manifest: %w", err)
+	}
+
+	return v, nil
+}
+
+// Funcs returns the template functions of the integration, to add to the template adapter's Funcs option:
+//
+//   - viteScripts: returns the script tags of the given entry points, e.g. {{viteScripts "src/main.ts"}}. In
+//     production, the imports of the entries are preloaded. In development, the Vite client is loaded first.
+//   - viteCSS: returns the stylesheet links of the given entry points, including the stylesheets of their imports,
+//     e.g. {{viteCSS "src/main.ts"}}. In development, Vite injects the stylesheets of script entries itself, so only
+//     stylesheet entries are linked.
+//   - viteAsset: returns the URL of a file processed by Vite, e.g. {{viteAsset "src/images/logo.svg"}}.
+func (v *Vite) Funcs() template.FuncMap {
+	return template.FuncMap{
+		"viteScripts": v.Scripts,
+		"viteCSS":     v.CSS,
+		"viteAsset":   v.Asset,
+	}
+}
+
+// Scripts returns the script tags of the given entry points.
+func (v *Vite) Scripts(entries ...string) (template.HTML, error) {
+	var b strings.Builder
+
+	if v.dev {
+		writeScript(&b, v.devURL+"/@vite/client")
+		for _, entry := range entries {
+			writeScript(&b, v.devURL+"/"+strings.TrimPrefix(entry, "/"))
+		}
+		return template.HTML(b.String()), nil
+	}
+
+	preloaded := make(map[string]bool)
+	for _, entry := range entries {
+		c, ok := v.manifest[entry]
+		if !ok {
+			return "", fmt.Errorf("vite: entry not found in manifest: %s", entry)
+		}
+
+		writeScript(&b, v.base+c.File)
+		for _, file := range v.imports(c, make(map[string]bool)) {
+			if !preloaded[file] {
+				preloaded[file] = true
+				fmt.Fprintf(&b, `<link rel="modulepreload" href="%s">`, template.HTMLEscapeString(v.base+file))
+			}
+		}
+	}
+
+	return template.HTML(b.String()), nil
+}
+
+// CSS returns the stylesheet links of the given entry points.
+func (v *Vite) CSS(entries ...string) (template.HTML, error) {
+	var b strings.Builder
+
+	if v.dev {
+		for _, entry := range entries {
+			if isStylesheet(entry) {
+				writeStylesheet(&b, v.devURL+"/"+strings.TrimPrefix(entry, "/"))
+			}
+		}
+		return template.HTML(b.String()), nil
+	}
+
+	linked := make(map[string]bool)
+	for _, entry := range entries {
+		c, ok := v.manifest[entry]
+		if !ok {
+			return "", fmt.Errorf("vite: entry not found in manifest: %s", entry)
+		}
+
+		for _, file := range v.stylesheets(c, make(map[string]bool)) {
+			if !linked[file] {
+				linked[file] = true
+				writeStylesheet(&b, v.base+file)
+			}
+		}
+	}
+
+	return template.HTML(b.String()), nil
+}
+
+// Asset returns the URL of a file processed by Vite.
+func (v *Vite) Asset(name string) (string, error) {
+	if v.dev {
+		return v.devURL + "/" + strings.TrimPrefix(name, "/"), nil
+	}
+
+	c, ok := v.manifest[name]
+	if !ok {
+		return "", fmt.Errorf("vite: asset not found in manifest: %s", name)
+	}
+	return v.base + c.File, nil
+}
+
+// imports returns the files imported by c, recursively.
+func (v *Vite) imports(c chunk, seen map[string]bool) []string {
+	var files []string
+	for _, key := range c.Imports {
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		imported, ok := v.manifest[key]
+		if !ok {
+			continue
+		}
+		files = append(files, imported.File)
+		files = append(files, v.imports(imported, seen)...)
+	}
+	return files
+}
+
+// stylesheets returns the stylesheets of c and its imports, recursively.
+func (v *Vite) stylesheets(c chunk, seen map[string]bool) []string {
+	var files []string
+	if isStylesheet(c.File) {
+		files = append(files, c.File)
+	}
+	files = append(files, c.CSS...)
+
+	for _, key := range c.Imports {
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		if imported, ok := v.manifest[key]; ok {
+			files = append(files, v.stylesheets(imported, seen)...)
+		}
+	}
+	return files
+}
+
+func isStylesheet(name string) bool {
+	switch path.Ext(name) {
+	case ".css", ".scss", ".sass", ".less", ".styl", ".stylus", ".pcss", ".postcss":
+		return true
+	}
+	return false
+}
+
+func writeScript(b *strings.Builder, src string) {
+	fmt.Fprintf(b, `<script type="module" src="%s"></script>`, template.HTMLEscapeString(src))
+}
+
+func writeStylesheet(b *strings.Builder, href string) {
+	fmt.Fprintf(b, `<link rel="stylesheet" href="%s">`, template.HTMLEscapeString(href))
+}
@@ -0,0 +1,116 @@
+package vite_test
+
+import (
+	"html/template"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hypergopher/hyperview/vite"
+)
+
+const manifest = `{
+  "src/main.ts": {
+    "file": "assets/main-4gvz.js",
+    "src": "src/main.ts",
+    "isEntry": true,
+    "css": ["assets/main-b1c2.css"],
+    "imports": ["_shared-9d8e.js"]
+  },
+  "src/admin.ts": {
+    "file": "assets/admin-7f6a.js",
+    "src": "src/admin.ts",
+    "isEntry": true,
+    "imports": ["_shared-9d8e.js"]
+  },
+  "_shared-9d8e.js": {
+    "file": "assets/shared-9d8e.js",
+    "css": ["assets/shared-5a4b.css"]
+  },
+  "src/print.css": {
+    "file": "assets/print-3c2d.css",
+    "src": "src/print.css",
+    "isEntry": true
+  },
+  "src/images/logo.svg": {
+    "file": "assets/logo-1a2b.svg",
+    "src": "src/images/logo.svg"
+  }
+}`
+
+func TestVite_Production(t *testing.T) {
+	v, err := vite.New(vite.Options{
+		FS:   fstest.MapFS{".vite/manifest.json": {Data: []byte(manifest)}},
+		Base: "/build",
+	})
+	if err != nil {
+		t.Fatalf("error creating Vite integration: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		fn   func() (template.HTML, error)
+		want template.HTML
+	}{
+		{
+			name: "scripts with shared imports preloaded once",
+			fn:   func() (template.HTML, error) { return v.Scripts("src/main.ts", "src/admin.ts") },
+			want: `<script type="module" src="/build/assets/main-4gvz.js"></script>` +
+				`<link rel="modulepreload" href="/build/assets/shared-9d8e.js">` +
+				`<script type="module" src="/build/assets/admin-7f6a.js"></script>`,
+		},
+		{
+			name: "css of entry and imports",
+			fn:   func() (template.HTML, error) { return v.CSS("src/main.ts", "src/print.css") },
+			want: `<link rel="stylesheet" href="/build/assets/main-b1c2.css">` +
+				`<link rel="stylesheet" href="/build/assets/shared-5a4b.css">` +
+				`<link rel="stylesheet" href="/build/assets/print-3c2d.css">`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.fn()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("unexpected tags:\ngot  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+
+	if url, err := v.Asset("src/images/logo.svg"); err != nil || url != "/build/assets/logo-1a2b.svg" {
+		t.Errorf("Asset() = %q, %v", url, err)
+	}
+	if _, err := v.Scripts("src/missing.ts"); err == nil {
+		t.Error("expected an error for a missing entry")
+	}
+}
+
+func TestVite_Dev(t *testing.T) {
+	v, err := vite.New(vite.Options{Dev: true})
+	if err != nil {
+		t.Fatalf("error creating Vite integration: %v", err)
+	}
+
+	scripts, _ := v.Scripts("src/main.ts")
+	want := template.HTML(`<script type="module" src="http://localhost:5173/@vite/client"></script>` +
+		`<script type="module" src="http://localhost:5173/src/main.ts"></script>`)
+	if scripts != want {
+		t.Errorf("unexpected scripts:\ngot  %s\nwant %s", scripts, want)
+	}
+
+	css, _ := v.CSS("src/main.ts", "src/print.css")
+	if want := template.HTML(`<link rel="stylesheet" href="http://localhost:5173/src/print.css">`); css != want {
+		t.Errorf("unexpected css:\ngot  %s\nwant %s", css, want)
+	}
+}
+
+func TestNew_MissingManifest(t *testing.T) {
+	if _, err := vite.New(vite.Options{FS: fstest.MapFS{}}); err == nil {
+		t.Error("expected an error for a missing manifest")
+	}
+	if _, err := vite.New(vite.Options{}); err == nil {
+		t.Error("expected an error without a file system in production")
+	}
+}